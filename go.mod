@@ -4,8 +4,11 @@ go 1.12
 
 require (
 	bou.ke/monkey v1.0.1
+	github.com/DATA-DOG/go-sqlmock v1.3.3
 	github.com/blang/semver v3.6.1+incompatible
+	github.com/go-sql-driver/mysql v1.4.1
 	github.com/gorilla/mux v1.7.2
+	github.com/lib/pq v1.1.1
 	github.com/mattermost/mattermost-server v5.12.0+incompatible
 	github.com/nicksnyder/go-i18n/v2 v2.0.2
 	github.com/pkg/errors v0.8.1
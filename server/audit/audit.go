@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// Entry records a single action that a user took on a poll
+type Entry struct {
+	ID     string
+	PollID string
+	// UserID is the user that took the action. It's empty if the action was anonymous or taken by Matterpoll itself.
+	UserID string `json:",omitempty"`
+	Action Action
+	// Details describes the action further, e.g. the answer option a user voted for.
+	Details string `json:",omitempty"`
+	// CreatedAt is the time in milliseconds at which the action was taken.
+	CreatedAt int64
+}
+
+// Action defines what a user did
+type Action string
+
+const (
+	// ActionPollCreated means that a poll got posted.
+	ActionPollCreated Action = "poll_created"
+	// ActionVoted means that a user voted for the first time.
+	ActionVoted Action = "voted"
+	// ActionVoteChanged means that a user changed or removed their vote.
+	ActionVoteChanged Action = "vote_changed"
+	// ActionOptionAdded means that a user added an answer option.
+	ActionOptionAdded Action = "option_added"
+	// ActionPollEnded means that a poll got ended by a user or by its deadline.
+	ActionPollEnded Action = "poll_ended"
+	// ActionPollDeleted means that a poll got deleted.
+	ActionPollDeleted Action = "poll_deleted"
+	// ActionPollRestored means that a deleted poll got restored.
+	ActionPollRestored Action = "poll_restored"
+	// ActionPollReopened means that an ended poll got reopened.
+	ActionPollReopened Action = "poll_reopened"
+	// ActionDeadlineExtended means that the deadline of a poll got pushed back. The details are the new deadline.
+	ActionDeadlineExtended Action = "deadline_extended"
+	// ActionVotingPaused means that voting in a poll got paused.
+	ActionVotingPaused Action = "voting_paused"
+	// ActionVotingResumed means that voting in a paused poll got resumed.
+	ActionVotingResumed Action = "voting_resumed"
+	// ActionOwnershipTransferred means that a poll got handed over to another user.
+	ActionOwnershipTransferred Action = "ownership_transferred"
+	// ActionVoteDelegated means that a user delegated their vote to another user.
+	ActionVoteDelegated Action = "vote_delegated"
+)
+
+// NewEntry creates a new entry for an action that a user takes on a poll right now.
+func NewEntry(pollID, userID string, action Action, details string) *Entry {
+	return &Entry{
+		ID:        model.NewId(),
+		PollID:    pollID,
+		UserID:    userID,
+		Action:    action,
+		Details:   details,
+		CreatedAt: model.GetMillis(),
+	}
+}
+
+// EncodeToByte returns an entry as a byte array
+func (e *Entry) EncodeToByte() []byte {
+	b, _ := json.Marshal(e)
+	return b
+}
+
+// DecodeEntryFromByte tries to create an entry from a byte array
+func DecodeEntryFromByte(b []byte) *Entry {
+	e := Entry{}
+	err := json.Unmarshal(b, &e)
+	if err != nil {
+		return nil
+	}
+	return &e
+}
@@ -0,0 +1,36 @@
+package audit_test
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry(t *testing.T) {
+	assert := assert.New(t)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	e := audit.NewEntry("pollID1", "userID1", audit.ActionVoted, "Answer 1")
+
+	assert.True(model.IsValidId(e.ID))
+	assert.Equal("pollID1", e.PollID)
+	assert.Equal("userID1", e.UserID)
+	assert.Equal(audit.ActionVoted, e.Action)
+	assert.Equal("Answer 1", e.Details)
+	assert.Equal(int64(1234567890), e.CreatedAt)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	e1 := audit.NewEntry("pollID1", "", audit.ActionPollEnded, "")
+	e2 := audit.DecodeEntryFromByte(e1.EncodeToByte())
+	assert.Equal(t, e1, e2)
+}
+
+func TestDecode(t *testing.T) {
+	e := audit.DecodeEntryFromByte([]byte{})
+	assert.Nil(t, e)
+}
@@ -0,0 +1,133 @@
+// Package chart renders horizontal bar charts as PNG images. Texts are drawn with a built-in dot matrix font,
+// which only supports digits and a few punctuation characters.
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	width     = 480
+	padding   = 12
+	rowHeight = 36
+	barHeight = 24
+
+	// scale is the size in pixels of a single dot of a glyph
+	scale       = 3
+	glyphWidth  = 3
+	glyphHeight = 5
+	// charWidth is the horizontal space in pixels a character takes, including the gap to the next one
+	charWidth = (glyphWidth + 1) * scale
+
+	// labelWidth leaves room for three characters and a gap in front of the bars
+	labelWidth = 4 * charWidth
+	// captionWidth leaves room for captions like "999 (100%)" and the gap behind the bars
+	captionWidth = 10*charWidth + 2*scale
+)
+
+var (
+	backgroundColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	barColor        = color.RGBA{R: 0x16, G: 0x6d, B: 0xe0, A: 0xff}
+	highlightColor  = color.RGBA{R: 0xff, G: 0xbc, B: 0x1f, A: 0xff}
+	textColor       = color.RGBA{R: 0x3d, G: 0x3c, B: 0x40, A: 0xff}
+)
+
+// glyphs are the dot matrices of all characters that can be drawn
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+	'(': {"..#", ".#.", ".#.", ".#.", "..#"},
+	')': {"#..", ".#.", ".#.", ".#.", "#.."},
+	'.': {"...", "...", "...", "...", ".#."},
+}
+
+// Bar is a single bar of a chart
+type Bar struct {
+	// Label is drawn left of the bar
+	Label string
+	// Value determines the length of the bar relative to the bar with the highest value
+	Value int
+	// Caption is drawn right of the bar
+	Caption string
+	// Highlight draws the bar in a distinct color, e.g. to mark the winner
+	Highlight bool
+}
+
+// RenderBarChart returns a PNG image of a chart with one horizontal bar per given bar.
+// Characters of labels and captions that can't be drawn are left blank.
+func RenderBarChart(bars []Bar) ([]byte, error) {
+	if len(bars) == 0 {
+		return nil, errors.New("a chart needs at least one bar")
+	}
+	max := 0
+	for _, b := range bars {
+		if b.Value < 0 {
+			return nil, errors.Errorf("invalid bar value %d", b.Value)
+		}
+		if b.Value > max {
+			max = b.Value
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, 2*padding+len(bars)*rowHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: backgroundColor}, image.Point{}, draw.Src)
+
+	maxBarWidth := width - 2*padding - labelWidth - captionWidth
+	barLeft := padding + labelWidth
+	for i, b := range bars {
+		top := padding + i*rowHeight
+		textTop := top + (rowHeight-glyphHeight*scale)/2
+		drawText(img, padding, textTop, b.Label)
+
+		barWidth := 0
+		if max > 0 {
+			barWidth = b.Value * maxBarWidth / max
+		}
+		barTop := top + (rowHeight-barHeight)/2
+		fill := barColor
+		if b.Highlight {
+			fill = highlightColor
+		}
+		draw.Draw(img, image.Rect(barLeft, barTop, barLeft+barWidth, barTop+barHeight), &image.Uniform{C: fill}, image.Point{}, draw.Src)
+		drawText(img, barLeft+barWidth+2*scale, textTop, b.Caption)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.Wrap(err, "failed to encode chart")
+	}
+	return buf.Bytes(), nil
+}
+
+// drawText draws a given text with its top left corner at x and y
+func drawText(img draw.Image, x, y int, text string) {
+	for _, r := range text {
+		if glyph, ok := glyphs[r]; ok {
+			for row, line := range glyph {
+				for col, dot := range line {
+					if dot != '#' {
+						continue
+					}
+					rect := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+					draw.Draw(img, rect, &image.Uniform{C: textColor}, image.Point{}, draw.Src)
+				}
+			}
+		}
+		x += charWidth
+	}
+}
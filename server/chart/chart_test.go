@@ -0,0 +1,82 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBarChart(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		data, err := RenderBarChart([]Bar{
+			{Label: "1", Value: 3, Caption: "3 (75%)"},
+			{Label: "2", Value: 1, Caption: "1 (25%)"},
+			{Label: "3", Value: 0, Caption: "0 (0%)"},
+		})
+		require.Nil(t, err)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		require.Nil(t, err)
+		assert.Equal(t, width, img.Bounds().Dx())
+		assert.Equal(t, 2*padding+3*rowHeight, img.Bounds().Dy())
+
+		// The longest bar spans the full width, shorter bars are scaled
+		maxBarWidth := width - 2*padding - labelWidth - captionWidth
+		center := padding + rowHeight/2
+		assert.Equal(t, barColor, img.At(padding+labelWidth+maxBarWidth-1, center))
+		assert.Equal(t, barColor, img.At(padding+labelWidth+maxBarWidth/3-1, center+rowHeight))
+		assert.Equal(t, backgroundColor, img.At(padding+labelWidth+maxBarWidth/3+1, center+rowHeight))
+		assert.Equal(t, backgroundColor, img.At(padding+labelWidth, center+2*rowHeight))
+
+		// The label "1" is drawn with its top dot in the middle column
+		textTop := padding + (rowHeight-glyphHeight*scale)/2
+		assert.Equal(t, textColor, img.At(padding+scale, textTop))
+		assert.Equal(t, backgroundColor, img.At(padding, textTop))
+	})
+
+	t.Run("highlighted bar", func(t *testing.T) {
+		data, err := RenderBarChart([]Bar{
+			{Label: "1", Value: 2, Caption: "2 (50%)", Highlight: true},
+			{Label: "2", Value: 2, Caption: "2 (50%)"},
+		})
+		require.Nil(t, err)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		require.Nil(t, err)
+		center := padding + rowHeight/2
+		assert.Equal(t, highlightColor, img.At(padding+labelWidth, center))
+		assert.Equal(t, barColor, img.At(padding+labelWidth, center+rowHeight))
+	})
+
+	t.Run("no votes", func(t *testing.T) {
+		data, err := RenderBarChart([]Bar{{Label: "1"}, {Label: "2"}})
+		require.Nil(t, err)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		require.Nil(t, err)
+		assert.Equal(t, backgroundColor, img.At(padding+labelWidth, padding+rowHeight/2))
+	})
+
+	t.Run("unsupported characters are left blank", func(t *testing.T) {
+		_, err := RenderBarChart([]Bar{{Label: "A", Value: 1, Caption: "ü"}})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("no bars", func(t *testing.T) {
+		data, err := RenderBarChart([]Bar{})
+
+		assert.NotNil(t, err)
+		assert.Nil(t, data)
+	})
+
+	t.Run("negative value", func(t *testing.T) {
+		data, err := RenderBarChart([]Bar{{Label: "1", Value: -1}})
+
+		assert.NotNil(t, err)
+		assert.Nil(t, data)
+	})
+}
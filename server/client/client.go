@@ -0,0 +1,226 @@
+// Package client allows Go programs to script against the HTTP API of Matterpoll.
+// It follows the OpenAPI document in assets/openapi.json, which the plugin serves at /api/v1/openapi.json.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// PluginID is the ID of the Matterpoll plugin, which is part of its URL
+const PluginID = "com.github.matterpoll.matterpoll"
+
+const (
+	apiTokenHeader      = "Matterpoll-Token"
+	authorizationHeader = "Authorization"
+)
+
+// Client sends requests to the Matterpoll plugin of a Mattermost server
+type Client struct {
+	// URL is the URL of the plugin, e.g. https://mattermost.example.com/plugins/com.github.matterpoll.matterpoll
+	URL string
+	// APIToken authenticates requests of external tools, which create and end polls. It's the API token from the plugin settings.
+	APIToken string
+	// AccessToken authenticates requests on behalf of a user, e.g. to vote. It's a session or personal access token of the user.
+	AccessToken string
+	HTTPClient  *http.Client
+}
+
+// New creates a client for the Matterpoll plugin of the Mattermost server with a given site URL
+func New(siteURL string) *Client {
+	return &Client{
+		URL:        strings.TrimSuffix(siteURL, "/") + "/plugins/" + PluginID,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Error is returned if the plugin rejects a request
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the status code together with the message of the plugin
+func (e *Error) Error() string {
+	return fmt.Sprintf("matterpoll: %d %s", e.StatusCode, e.Message)
+}
+
+// CreatePollRequest describes a poll to create
+type CreatePollRequest struct {
+	ChannelID string `json:"channel_id"`
+	// RootID posts the poll as a reply to this post
+	RootID string `json:"root_id,omitempty"`
+	// UserID is the creator of the poll. The Matterpoll bot creates the poll if it's empty.
+	UserID   string `json:"user_id,omitempty"`
+	Question string `json:"question"`
+	// AnswerOptions default to Yes and No
+	AnswerOptions []string `json:"answer_options,omitempty"`
+	// Settings are Poll Settings without the leading dashes, e.g. "anonymous" or "votes=2"
+	Settings []string `json:"settings,omitempty"`
+}
+
+// CreatePollResponse identifies a created poll. PostID is empty for scheduled polls.
+type CreatePollResponse struct {
+	PollID string `json:"poll_id"`
+	PostID string `json:"post_id"`
+}
+
+// ActionRequest describes the user that takes an action on a poll, like pressing a button of the poll post
+type ActionRequest struct {
+	UserID    string `json:"user_id"`
+	PostID    string `json:"post_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	TeamID    string `json:"team_id,omitempty"`
+}
+
+// ActionResponse is the outcome of an action
+type ActionResponse struct {
+	// EphemeralText is the message for the user in their language
+	EphemeralText string `json:"ephemeral_text"`
+	// Update is the updated poll post, if the action changed it
+	Update json.RawMessage `json:"update,omitempty"`
+}
+
+// VerifyReceiptResponse tells whether the vote of a receipt got counted
+type VerifyReceiptResponse struct {
+	Counted bool `json:"counted"`
+	// Answer is the answer option the vote was cast for. It's empty if the vote wasn't counted.
+	Answer string `json:"answer,omitempty"`
+}
+
+// Stats are the statistics of all polls
+type Stats struct {
+	Polls                int             `json:"polls"`
+	AverageParticipation float64         `json:"average_participation"`
+	PollsPerTeam         []StatsCount    `json:"polls_per_team"`
+	PollsPerChannel      []StatsCount    `json:"polls_per_channel"`
+	TopCreators          []StatsCount    `json:"top_creators"`
+	VotesPerDay          []StatsDayVotes `json:"votes_per_day"`
+}
+
+// StatsCount is the number of polls of a team, channel or creator
+type StatsCount struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Polls int    `json:"polls"`
+}
+
+// StatsDayVotes is the number of votes cast on a day in UTC
+type StatsDayVotes struct {
+	Day   string `json:"day"`
+	Votes int    `json:"votes"`
+}
+
+// CreatePoll creates a poll. It requires the API token.
+func (c *Client) CreatePoll(request *CreatePollRequest) (*CreatePollResponse, error) {
+	response := &CreatePollResponse{}
+	if err := c.do(http.MethodPost, "/api/v1/polls", c.withAPIToken, request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// EndPoll ends a running poll. It requires the API token.
+func (c *Client) EndPoll(pollID string) error {
+	return c.do(http.MethodPost, "/api/v1/polls/"+pollID+"/end", c.withAPIToken, nil, nil)
+}
+
+// Vote votes for the answer option with a given zero-based index on behalf of a user. It requires an access token.
+func (c *Client) Vote(pollID string, optionNumber int, request *ActionRequest) (*ActionResponse, error) {
+	return c.doAction(fmt.Sprintf("/api/v1/polls/%s/vote/%d", pollID, optionNumber), request)
+}
+
+// ResetVote removes all votes of a user. It requires an access token.
+func (c *Client) ResetVote(pollID string, request *ActionRequest) (*ActionResponse, error) {
+	return c.doAction("/api/v1/polls/"+pollID+"/vote/reset", request)
+}
+
+// DeletePoll deletes a poll on behalf of a user. It requires an access token.
+func (c *Client) DeletePoll(pollID string, request *ActionRequest) (*ActionResponse, error) {
+	return c.doAction("/api/v1/polls/"+pollID+"/delete", request)
+}
+
+// ShowResults sends the current results of a poll with vote-to-see to a user who has voted. It requires an access token.
+func (c *Client) ShowResults(pollID string, request *ActionRequest) (*ActionResponse, error) {
+	return c.doAction("/api/v1/polls/"+pollID+"/results", request)
+}
+
+// VerifyReceipt checks if the vote of a given receipt got counted. It requires an access token.
+func (c *Client) VerifyReceipt(pollID, receipt string) (*VerifyReceiptResponse, error) {
+	response := &VerifyReceiptResponse{}
+	body := map[string]string{"receipt": receipt}
+	if err := c.do(http.MethodPost, "/api/v1/polls/"+pollID+"/receipts/verify", c.withAccessToken, body, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetStats returns the statistics of all polls. It requires the access token of a System Admin.
+func (c *Client) GetStats() (*Stats, error) {
+	response := &Stats{}
+	if err := c.do(http.MethodGet, "/api/v1/admin/stats", c.withAccessToken, nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) doAction(path string, request *ActionRequest) (*ActionResponse, error) {
+	response := &ActionResponse{}
+	if err := c.do(http.MethodPost, path, c.withAccessToken, request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) withAPIToken(r *http.Request) {
+	r.Header.Set(apiTokenHeader, c.APIToken)
+}
+
+func (c *Client) withAccessToken(r *http.Request) {
+	r.Header.Set(authorizationHeader, "Bearer "+c.AccessToken)
+}
+
+// do sends a request with a given body as JSON and decodes the JSON response into out. Both body and out may be nil.
+func (c *Client) do(method, path string, authenticate func(*http.Request), body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	request, err := http.NewRequest(method, c.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	authenticate(request)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(response.Body)
+		return &Error{StatusCode: response.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
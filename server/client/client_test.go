@@ -0,0 +1,192 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, "https://example.com/plugins/com.github.matterpoll.matterpoll", New("https://example.com/").URL)
+	assert.Equal(t, "https://example.com/plugins/com.github.matterpoll.matterpoll", New("https://example.com").URL)
+}
+
+func TestClient(t *testing.T) {
+	for name, test := range map[string]struct {
+		Call           func(*Client) (interface{}, error)
+		StatusCode     int
+		ResponseBody   string
+		ExpectedMethod string
+		ExpectedPath   string
+		ExpectedHeader string
+		ExpectedValue  string
+		ExpectedBody   string
+		Expected       interface{}
+		ShouldError    bool
+	}{
+		"CreatePoll": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.CreatePoll(&CreatePollRequest{ChannelID: "channelID1", Question: "Question", Settings: []string{"progress"}})
+			},
+			StatusCode:     http.StatusCreated,
+			ResponseBody:   `{"poll_id": "pollID1", "post_id": "postID1"}`,
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls",
+			ExpectedHeader: apiTokenHeader,
+			ExpectedValue:  "token",
+			ExpectedBody:   `{"channel_id":"channelID1","question":"Question","settings":["progress"]}`,
+			Expected:       &CreatePollResponse{PollID: "pollID1", PostID: "postID1"},
+		},
+		"CreatePoll fails": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.CreatePoll(&CreatePollRequest{ChannelID: "channelID1"})
+			},
+			StatusCode:     http.StatusBadRequest,
+			ResponseBody:   "channel_id and question are required\n",
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls",
+			ExpectedHeader: apiTokenHeader,
+			ExpectedValue:  "token",
+			ExpectedBody:   `{"channel_id":"channelID1","question":""}`,
+			Expected:       &Error{StatusCode: http.StatusBadRequest, Message: "channel_id and question are required"},
+			ShouldError:    true,
+		},
+		"EndPoll": {
+			Call: func(c *Client) (interface{}, error) {
+				return nil, c.EndPoll("pollID1")
+			},
+			StatusCode:     http.StatusOK,
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/pollID1/end",
+			ExpectedHeader: apiTokenHeader,
+			ExpectedValue:  "token",
+		},
+		"Vote": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.Vote("pollID1", 2, &ActionRequest{UserID: "userID1", PostID: "postID1"})
+			},
+			StatusCode:     http.StatusOK,
+			ResponseBody:   `{"ephemeral_text": "Your vote has been counted."}`,
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/pollID1/vote/2",
+			ExpectedHeader: authorizationHeader,
+			ExpectedValue:  "Bearer access",
+			ExpectedBody:   `{"user_id":"userID1","post_id":"postID1"}`,
+			Expected:       &ActionResponse{EphemeralText: "Your vote has been counted."},
+		},
+		"ResetVote": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.ResetVote("pollID1", &ActionRequest{UserID: "userID1"})
+			},
+			StatusCode:     http.StatusOK,
+			ResponseBody:   `{"ephemeral_text": "Your votes have been removed."}`,
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/pollID1/vote/reset",
+			ExpectedHeader: authorizationHeader,
+			ExpectedValue:  "Bearer access",
+			ExpectedBody:   `{"user_id":"userID1"}`,
+			Expected:       &ActionResponse{EphemeralText: "Your votes have been removed."},
+		},
+		"DeletePoll": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.DeletePoll("pollID1", &ActionRequest{UserID: "userID1", PostID: "postID1"})
+			},
+			StatusCode:     http.StatusOK,
+			ResponseBody:   `{"ephemeral_text": "Successfully deleted the poll."}`,
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/pollID1/delete",
+			ExpectedHeader: authorizationHeader,
+			ExpectedValue:  "Bearer access",
+			ExpectedBody:   `{"user_id":"userID1","post_id":"postID1"}`,
+			Expected:       &ActionResponse{EphemeralText: "Successfully deleted the poll."},
+		},
+		"ShowResults": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.ShowResults("pollID1", &ActionRequest{UserID: "userID1"})
+			},
+			StatusCode:     http.StatusOK,
+			ResponseBody:   `{}`,
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/pollID1/results",
+			ExpectedHeader: authorizationHeader,
+			ExpectedValue:  "Bearer access",
+			ExpectedBody:   `{"user_id":"userID1"}`,
+			Expected:       &ActionResponse{},
+		},
+		"VerifyReceipt": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.VerifyReceipt("pollID1", "receipt1")
+			},
+			StatusCode:     http.StatusOK,
+			ResponseBody:   `{"counted": true, "answer": "Answer 1"}`,
+			ExpectedMethod: http.MethodPost,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/polls/pollID1/receipts/verify",
+			ExpectedHeader: authorizationHeader,
+			ExpectedValue:  "Bearer access",
+			ExpectedBody:   `{"receipt":"receipt1"}`,
+			Expected:       &VerifyReceiptResponse{Counted: true, Answer: "Answer 1"},
+		},
+		"GetStats": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.GetStats()
+			},
+			StatusCode:     http.StatusOK,
+			ResponseBody:   `{"polls": 2, "votes_per_day": [{"day": "2019-01-01", "votes": 3}]}`,
+			ExpectedMethod: http.MethodGet,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/admin/stats",
+			ExpectedHeader: authorizationHeader,
+			ExpectedValue:  "Bearer access",
+			Expected:       &Stats{Polls: 2, VotesPerDay: []StatsDayVotes{{Day: "2019-01-01", Votes: 3}}},
+		},
+		"GetStats, not a System Admin": {
+			Call: func(c *Client) (interface{}, error) {
+				return c.GetStats()
+			},
+			StatusCode:     http.StatusForbidden,
+			ResponseBody:   "not authorized\n",
+			ExpectedMethod: http.MethodGet,
+			ExpectedPath:   "/plugins/com.github.matterpoll.matterpoll/api/v1/admin/stats",
+			ExpectedHeader: authorizationHeader,
+			ExpectedValue:  "Bearer access",
+			Expected:       &Error{StatusCode: http.StatusForbidden, Message: "not authorized"},
+			ShouldError:    true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, test.ExpectedMethod, r.Method)
+				assert.Equal(t, test.ExpectedPath, r.URL.Path)
+				assert.Equal(t, test.ExpectedValue, r.Header.Get(test.ExpectedHeader))
+				body, err := ioutil.ReadAll(r.Body)
+				require.Nil(t, err)
+				assert.Equal(t, test.ExpectedBody, string(body))
+
+				w.WriteHeader(test.StatusCode)
+				_, _ = w.Write([]byte(test.ResponseBody))
+			}))
+			defer server.Close()
+
+			c := New(server.URL)
+			c.APIToken = "token"
+			c.AccessToken = "access"
+
+			result, err := test.Call(c)
+			if test.ShouldError {
+				assert.Equal(t, test.Expected, err)
+				return
+			}
+			require.Nil(t, err)
+			if test.Expected == nil {
+				return
+			}
+			expected, _ := json.Marshal(test.Expected)
+			actual, _ := json.Marshal(result)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package importer reads the CSV exports of poll tools for Slack, so their polls can be recreated in Mattermost.
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FormatPolly reads the CSV exports of Polly
+	FormatPolly = "polly"
+	// FormatSimplePoll reads the CSV exports of Simple Poll
+	FormatSimplePoll = "simplepoll"
+)
+
+// Formats are all formats that can be imported
+var Formats = []string{FormatPolly, FormatSimplePoll}
+
+// columns holds the header names a format may use for each column, in the order of preference.
+// The header names are compared case-insensitively.
+type columns struct {
+	question []string
+	answer   []string
+	voter    []string
+	votes    []string
+}
+
+var formatColumns = map[string]columns{
+	FormatPolly: {
+		question: []string{"question"},
+		answer:   []string{"response", "answer"},
+		voter:    []string{"respondent", "user", "email"},
+		votes:    []string{"count", "votes"},
+	},
+	FormatSimplePoll: {
+		question: []string{"poll", "question"},
+		answer:   []string{"option", "answer"},
+		voter:    []string{"voter", "user", "email"},
+		votes:    []string{"votes", "count"},
+	},
+}
+
+// Poll is a poll read from an export
+type Poll struct {
+	Question      string
+	AnswerOptions []*AnswerOption
+}
+
+// AnswerOption is an answer option of an imported poll together with its votes
+type AnswerOption struct {
+	Answer string
+	// Voters are the user names or email addresses of the voters as the export lists them
+	Voters []string
+	// AnonymousVotes counts the votes whose voters the export doesn't list, e.g. of anonymous polls
+	AnonymousVotes int
+}
+
+// Parse reads the polls of an export in a given format, in the order they appear.
+// Every row holds an answer option of a poll and either a voter or the number of votes. Rows of the same question belong to the same poll.
+func Parse(format string, r io.Reader) ([]*Poll, error) {
+	c, ok := formatColumns[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q. Use %s", format, strings.Join(Formats, ", "))
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("export is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	questionColumn, answerColumn := findColumn(header, c.question), findColumn(header, c.answer)
+	voterColumn, votesColumn := findColumn(header, c.voter), findColumn(header, c.votes)
+	if questionColumn == -1 || answerColumn == -1 {
+		return nil, fmt.Errorf("export has no %s or %s column", c.question[0], c.answer[0])
+	}
+
+	polls := []*Poll{}
+	byQuestion := map[string]*Poll{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+
+		question, answer := field(record, questionColumn), field(record, answerColumn)
+		if question == "" || answer == "" {
+			continue
+		}
+		poll, ok := byQuestion[question]
+		if !ok {
+			poll = &Poll{Question: question}
+			byQuestion[question] = poll
+			polls = append(polls, poll)
+		}
+		o := poll.answerOption(answer)
+
+		if voter := field(record, voterColumn); voter != "" {
+			o.Voters = append(o.Voters, voter)
+		} else if votes := field(record, votesColumn); votes != "" {
+			n, err := strconv.Atoi(votes)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid number of votes %q in row %d", votes, row)
+			}
+			o.AnonymousVotes += n
+		}
+	}
+	if len(polls) == 0 {
+		return nil, fmt.Errorf("export contains no polls")
+	}
+	return polls, nil
+}
+
+// findColumn returns the index of the first column of a header that has one of the given names. It's -1 if there is none.
+func findColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, h := range header {
+			// Spreadsheet applications may start the file with a byte order mark
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// field returns the trimmed value of the column with a given index. It's empty if the column doesn't exist.
+func field(record []string, column int) string {
+	if column < 0 || column >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[column])
+}
+
+// answerOption returns the answer option with a given answer and adds it if the poll doesn't have it yet
+func (p *Poll) answerOption(answer string) *AnswerOption {
+	for _, o := range p.AnswerOptions {
+		if o.Answer == answer {
+			return o
+		}
+	}
+	o := &AnswerOption{Answer: answer}
+	p.AnswerOptions = append(p.AnswerOptions, o)
+	return o
+}
+
+// MaxVotesPerVoter returns the highest number of answer options a single listed voter voted for
+func (p *Poll) MaxVotesPerVoter() int {
+	votes := map[string]int{}
+	max := 0
+	for _, o := range p.AnswerOptions {
+		for _, voter := range o.Voters {
+			votes[voter]++
+			if votes[voter] > max {
+				max = votes[voter]
+			}
+		}
+	}
+	return max
+}
@@ -0,0 +1,94 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/importer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for name, test := range map[string]struct {
+		Format        string
+		Export        string
+		ExpectedPolls []*importer.Poll
+		ShouldError   bool
+	}{
+		"Polly with voters": {
+			Format: importer.FormatPolly,
+			Export: "Question,Response,Respondent\n" +
+				"Lunch?,Pizza,alice\n" +
+				"Lunch?,Sushi,bob@example.com\n" +
+				"Lunch?,Pizza,carol\n" +
+				"Venue?,Office,alice\n",
+			ExpectedPolls: []*importer.Poll{{
+				Question: "Lunch?",
+				AnswerOptions: []*importer.AnswerOption{
+					{Answer: "Pizza", Voters: []string{"alice", "carol"}},
+					{Answer: "Sushi", Voters: []string{"bob@example.com"}},
+				},
+			}, {
+				Question: "Venue?",
+				AnswerOptions: []*importer.AnswerOption{
+					{Answer: "Office", Voters: []string{"alice"}},
+				},
+			}},
+		},
+		"Simple Poll with number of votes": {
+			Format: importer.FormatSimplePoll,
+			Export: "\ufeffPoll,Option,Votes\n" +
+				"Lunch?,Pizza,3\n" +
+				"Lunch?,Sushi,0\n",
+			ExpectedPolls: []*importer.Poll{{
+				Question: "Lunch?",
+				AnswerOptions: []*importer.AnswerOption{
+					{Answer: "Pizza", AnonymousVotes: 3},
+					{Answer: "Sushi"},
+				},
+			}},
+		},
+		"Header names are case-insensitive and rows without answer are skipped": {
+			Format: importer.FormatSimplePoll,
+			Export: "QUESTION , answer,User\n" +
+				"Lunch?,,alice\n" +
+				"Lunch?, Pizza ,alice\n",
+			ExpectedPolls: []*importer.Poll{{
+				Question: "Lunch?",
+				AnswerOptions: []*importer.AnswerOption{
+					{Answer: "Pizza", Voters: []string{"alice"}},
+				},
+			}},
+		},
+		"Unknown format":          {Format: "doodle", Export: "Question,Response\nLunch?,Pizza\n", ShouldError: true},
+		"Missing answer column":   {Format: importer.FormatPolly, Export: "Question,Respondent\nLunch?,alice\n", ShouldError: true},
+		"Invalid number of votes": {Format: importer.FormatSimplePoll, Export: "Poll,Option,Votes\nLunch?,Pizza,many\n", ShouldError: true},
+		"Empty export":            {Format: importer.FormatPolly, Export: "", ShouldError: true},
+		"Export without polls":    {Format: importer.FormatPolly, Export: "Question,Response\n", ShouldError: true},
+		"Invalid CSV":             {Format: importer.FormatPolly, Export: "Question,Response\n\"Lunch?,Pizza\n", ShouldError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			polls, err := importer.Parse(test.Format, strings.NewReader(test.Export))
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+				assert.Nil(t, polls)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.ExpectedPolls, polls)
+			}
+		})
+	}
+}
+
+func TestPollMaxVotesPerVoter(t *testing.T) {
+	p := &importer.Poll{
+		Question: "Lunch?",
+		AnswerOptions: []*importer.AnswerOption{
+			{Answer: "Pizza", Voters: []string{"alice", "bob"}},
+			{Answer: "Sushi", Voters: []string{"alice"}, AnonymousVotes: 4},
+		},
+	}
+	assert.Equal(t, 2, p.MaxVotesPerVoter())
+	assert.Equal(t, 0, (&importer.Poll{}).MaxVotesPerVoter())
+}
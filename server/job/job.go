@@ -0,0 +1,90 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job stores a task that is scheduled to run at a given time
+type Job struct {
+	ID     string
+	Type   Type
+	PollID string
+	// RunAt is the time in milliseconds at which the job becomes due.
+	RunAt int64
+	// ClaimedAt is the time in milliseconds at which a plugin instance started to run the job.
+	// Zero means the job isn't running.
+	ClaimedAt int64 `json:",omitempty"`
+}
+
+// ClaimTimeout is the time after which a claimed job may be claimed again.
+// It allows to run jobs whose plugin instance went away while running them.
+const ClaimTimeout = 10 * time.Minute
+
+// Type defines what a job does once it's due
+type Type string
+
+const (
+	// TypeEndPoll ends a poll, updates the poll post and announces the results.
+	TypeEndPoll Type = "end_poll"
+	// TypePostPoll posts a scheduled poll into its channel.
+	TypePostPoll Type = "post_poll"
+	// TypeRepeatPoll ends a recurring poll and posts its next instance.
+	TypeRepeatPoll Type = "repeat_poll"
+	// TypeSendDigest sends the creator of a running poll the current standings.
+	TypeSendDigest Type = "send_digest"
+	// TypeRemindDeadline reminds the channel of a poll that the poll ends soon.
+	TypeRemindDeadline Type = "remind_deadline"
+	// TypeArchivePolls archives the polls that ended long ago. It isn't bound to a poll.
+	TypeArchivePolls Type = "archive_polls"
+	// TypeSendTelemetry sends the anonymized usage statistics. It isn't bound to a poll.
+	TypeSendTelemetry Type = "send_telemetry"
+	// TypePurgePoll removes a deleted poll for good once it can no longer be restored.
+	TypePurgePoll Type = "purge_poll"
+	// TypeEndArchivedPolls ends the running polls of archived channels. It isn't bound to a poll.
+	TypeEndArchivedPolls Type = "end_archived_polls"
+	// TypeRefreshCountdown updates the post of a poll with a deadline whenever its countdown changes.
+	TypeRefreshCountdown Type = "refresh_countdown"
+)
+
+// NewJob creates a new job of a given type for a poll.
+// There is at most one job per type and poll, hence scheduling a job again overrides the previous one.
+// Jobs that aren't bound to a poll have an empty pollID.
+func NewJob(jobType Type, pollID string, runAt int64) *Job {
+	id := string(jobType)
+	if pollID != "" {
+		id += "_" + pollID
+	}
+	return &Job{
+		ID:     id,
+		Type:   jobType,
+		PollID: pollID,
+		RunAt:  runAt,
+	}
+}
+
+// IsDue returns true if the job should run at a given time in milliseconds
+func (j *Job) IsDue(now int64) bool {
+	return j.RunAt <= now
+}
+
+// IsClaimed returns true if a plugin instance runs the job at a given time in milliseconds
+func (j *Job) IsClaimed(now int64) bool {
+	return j.ClaimedAt != 0 && now-j.ClaimedAt < int64(ClaimTimeout/time.Millisecond)
+}
+
+// EncodeToByte returns a job as a byte array
+func (j *Job) EncodeToByte() []byte {
+	b, _ := json.Marshal(j)
+	return b
+}
+
+// DecodeJobFromByte tries to create a job from a byte array
+func DecodeJobFromByte(b []byte) *Job {
+	j := Job{}
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return nil
+	}
+	return &j
+}
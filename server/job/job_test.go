@@ -0,0 +1,53 @@
+package job_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJob(t *testing.T) {
+	assert := assert.New(t)
+
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	assert.Equal("end_poll_pollID1", j.ID)
+	assert.Equal(job.TypeEndPoll, j.Type)
+	assert.Equal("pollID1", j.PollID)
+	assert.Equal(int64(1234567890), j.RunAt)
+
+	j = job.NewJob(job.TypeArchivePolls, "", 1234567890)
+	assert.Equal("archive_polls", j.ID)
+	assert.Equal("", j.PollID)
+}
+
+func TestIsClaimed(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+	assert.False(t, j.IsClaimed(1234567890))
+
+	j.ClaimedAt = 1234567890
+	assert.True(t, j.IsClaimed(1234567890))
+	assert.True(t, j.IsClaimed(1234567890+int64(job.ClaimTimeout/time.Millisecond)-1))
+	assert.False(t, j.IsClaimed(1234567890+int64(job.ClaimTimeout/time.Millisecond)))
+}
+
+func TestIsDue(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	assert.False(t, j.IsDue(1234567889))
+	assert.True(t, j.IsDue(1234567890))
+	assert.True(t, j.IsDue(1234567891))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	j1 := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+	j2 := job.DecodeJobFromByte(j1.EncodeToByte())
+	assert.Equal(t, j1, j2)
+}
+
+func TestDecode(t *testing.T) {
+	j := job.DecodeJobFromByte([]byte{})
+	assert.Nil(t, j)
+}
@@ -0,0 +1,158 @@
+// Package metrics collects usage and performance metrics of the plugin and writes them in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// storeDurationBuckets are the upper bounds in seconds of the store latency histogram buckets
+var storeDurationBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// Metrics stores the metrics of the plugin. All methods are safe for concurrent use.
+// A nil Metrics doesn't record anything.
+type Metrics struct {
+	mu sync.Mutex
+
+	pollsCreated   uint64
+	votesCast      uint64
+	requests       map[string]uint64
+	requestErrors  map[string]uint64
+	storeDurations map[string]*histogram
+}
+
+// histogram counts observations in cumulative buckets
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// New returns an empty Metrics
+func New() *Metrics {
+	return &Metrics{
+		requests:       map[string]uint64{},
+		requestErrors:  map[string]uint64{},
+		storeDurations: map[string]*histogram{},
+	}
+}
+
+// IncPollsCreated counts a created poll
+func (m *Metrics) IncPollsCreated() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pollsCreated++
+}
+
+// IncVotesCast counts a cast vote
+func (m *Metrics) IncVotesCast() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.votesCast++
+}
+
+// ObserveRequest counts a request of a given handler. failed is true if the handler returned an error.
+func (m *Metrics) ObserveRequest(handler string, failed bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[handler]++
+	if failed {
+		m.requestErrors[handler]++
+	}
+}
+
+// ObserveStoreDuration records how long a given store operation took
+func (m *Metrics) ObserveStoreDuration(operation string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.storeDurations[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(storeDurationBuckets))}
+		m.storeDurations[operation] = h
+	}
+	seconds := d.Seconds()
+	for i, bound := range storeDurationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WriteTo writes all metrics in the Prometheus text format.
+// activePolls is the number of running polls, which is read from the store on every scrape.
+func (m *Metrics) WriteTo(w io.Writer, activePolls int) error {
+	if m == nil {
+		m = New()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	writeHeader(&b, "matterpoll_polls_created_total", "counter", "Number of polls created since the plugin started.")
+	fmt.Fprintf(&b, "matterpoll_polls_created_total %d\n", m.pollsCreated)
+	writeHeader(&b, "matterpoll_votes_cast_total", "counter", "Number of votes cast since the plugin started.")
+	fmt.Fprintf(&b, "matterpoll_votes_cast_total %d\n", m.votesCast)
+	writeHeader(&b, "matterpoll_active_polls", "gauge", "Number of polls that are posted and not ended.")
+	fmt.Fprintf(&b, "matterpoll_active_polls %d\n", activePolls)
+
+	writeHeader(&b, "matterpoll_handler_requests_total", "counter", "Number of handled requests per handler.")
+	for _, handler := range sortedKeys(m.requests) {
+		fmt.Fprintf(&b, "matterpoll_handler_requests_total{handler=%q} %d\n", handler, m.requests[handler])
+	}
+	writeHeader(&b, "matterpoll_handler_errors_total", "counter", "Number of requests per handler that failed with an error.")
+	for _, handler := range sortedKeys(m.requests) {
+		fmt.Fprintf(&b, "matterpoll_handler_errors_total{handler=%q} %d\n", handler, m.requestErrors[handler])
+	}
+
+	writeHeader(&b, "matterpoll_store_duration_seconds", "histogram", "Latency of store operations.")
+	operations := make([]string, 0, len(m.storeDurations))
+	for operation := range m.storeDurations {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		h := m.storeDurations[operation]
+		for i, bound := range storeDurationBuckets {
+			fmt.Fprintf(&b, "matterpoll_store_duration_seconds_bucket{operation=%q,le=\"%g\"} %d\n", operation, bound, h.counts[i])
+		}
+		fmt.Fprintf(&b, "matterpoll_store_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", operation, h.count)
+		fmt.Fprintf(&b, "matterpoll_store_duration_seconds_sum{operation=%q} %g\n", operation, h.sum)
+		fmt.Fprintf(&b, "matterpoll_store_duration_seconds_count{operation=%q} %d\n", operation, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric
+func writeHeader(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// sortedKeys returns the keys of a map in ascending order
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
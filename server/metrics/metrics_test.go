@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsWriteTo(t *testing.T) {
+	t.Run("no observations", func(t *testing.T) {
+		var b bytes.Buffer
+		err := New().WriteTo(&b, 0)
+
+		require.Nil(t, err)
+		assert.Equal(t, "# HELP matterpoll_polls_created_total Number of polls created since the plugin started.\n"+
+			"# TYPE matterpoll_polls_created_total counter\n"+
+			"matterpoll_polls_created_total 0\n"+
+			"# HELP matterpoll_votes_cast_total Number of votes cast since the plugin started.\n"+
+			"# TYPE matterpoll_votes_cast_total counter\n"+
+			"matterpoll_votes_cast_total 0\n"+
+			"# HELP matterpoll_active_polls Number of polls that are posted and not ended.\n"+
+			"# TYPE matterpoll_active_polls gauge\n"+
+			"matterpoll_active_polls 0\n"+
+			"# HELP matterpoll_handler_requests_total Number of handled requests per handler.\n"+
+			"# TYPE matterpoll_handler_requests_total counter\n"+
+			"# HELP matterpoll_handler_errors_total Number of requests per handler that failed with an error.\n"+
+			"# TYPE matterpoll_handler_errors_total counter\n"+
+			"# HELP matterpoll_store_duration_seconds Latency of store operations.\n"+
+			"# TYPE matterpoll_store_duration_seconds histogram\n", b.String())
+	})
+
+	t.Run("with observations", func(t *testing.T) {
+		m := New()
+		m.IncPollsCreated()
+		m.IncVotesCast()
+		m.IncVotesCast()
+		m.ObserveRequest("vote", false)
+		m.ObserveRequest("vote", true)
+		m.ObserveRequest("end", false)
+		m.ObserveStoreDuration("poll_get", 3*time.Millisecond)
+		m.ObserveStoreDuration("poll_get", 2*time.Second)
+
+		var b bytes.Buffer
+		err := m.WriteTo(&b, 2)
+
+		require.Nil(t, err)
+		out := b.String()
+		assert.Contains(t, out, "matterpoll_polls_created_total 1\n")
+		assert.Contains(t, out, "matterpoll_votes_cast_total 2\n")
+		assert.Contains(t, out, "matterpoll_active_polls 2\n")
+		assert.Contains(t, out, "matterpoll_handler_requests_total{handler=\"end\"} 1\n"+
+			"matterpoll_handler_requests_total{handler=\"vote\"} 2\n")
+		assert.Contains(t, out, "matterpoll_handler_errors_total{handler=\"end\"} 0\n"+
+			"matterpoll_handler_errors_total{handler=\"vote\"} 1\n")
+		assert.Contains(t, out, "matterpoll_store_duration_seconds_bucket{operation=\"poll_get\",le=\"0.001\"} 0\n"+
+			"matterpoll_store_duration_seconds_bucket{operation=\"poll_get\",le=\"0.005\"} 1\n")
+		assert.Contains(t, out, "matterpoll_store_duration_seconds_bucket{operation=\"poll_get\",le=\"1\"} 1\n"+
+			"matterpoll_store_duration_seconds_bucket{operation=\"poll_get\",le=\"5\"} 2\n"+
+			"matterpoll_store_duration_seconds_bucket{operation=\"poll_get\",le=\"+Inf\"} 2\n"+
+			"matterpoll_store_duration_seconds_sum{operation=\"poll_get\"} 2.003\n"+
+			"matterpoll_store_duration_seconds_count{operation=\"poll_get\"} 2\n")
+	})
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.IncPollsCreated()
+		m.IncVotesCast()
+		m.ObserveRequest("vote", true)
+		m.ObserveStoreDuration("poll_get", time.Second)
+	})
+}
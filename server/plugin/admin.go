@@ -0,0 +1,315 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+const (
+	// adminListPerPage is the number of polls per page of /poll admin list
+	adminListPerPage = 20
+)
+
+var (
+	commandHelpTextAdmin = &i18n.Message{
+		ID:    "command.help.text.admin",
+		Other: "System admins can see all polls on this server, newest first, by typing `/{{.Trigger}} admin list [page]`",
+	}
+	commandHelpTextAdminErase = &i18n.Message{
+		ID:    "command.help.text.admin.erase",
+		Other: "System admins can erase the votes and poll authorship of a user from all polls by typing `/{{.Trigger}} admin erase <username or user ID>`",
+	}
+	commandErrorAdminUsage = &i18n.Message{
+		ID:    "command.error.admin.usage",
+		Other: "Usage: `/{{.Trigger}} admin list [page]`, `/{{.Trigger}} admin erase <username or user ID>`, `/{{.Trigger}} admin export`, `/{{.Trigger}} admin usage` or `/{{.Trigger}} admin diagnose`",
+	}
+	commandErrorAdminInvalidPermission = &i18n.Message{
+		ID:    "command.error.admin.invalidPermission",
+		Other: "Only system admins can use admin commands.",
+	}
+
+	adminListNone = &i18n.Message{
+		ID:    "admin.list.none",
+		Other: "There are no polls on this server.",
+	}
+	adminListPageEmpty = &i18n.Message{
+		ID:    "admin.list.pageEmpty",
+		Other: "Page {{.Page}} is empty. There are only {{.Pages}} pages.",
+	}
+	adminListHeading = &i18n.Message{
+		ID:    "admin.list.heading",
+		One:   "All polls, newest first (page {{.Page}} of {{.Pages}}, {{.Count}} poll in total):",
+		Other: "All polls, newest first (page {{.Page}} of {{.Pages}}, {{.Count}} polls in total):",
+	}
+	adminListEntry = &i18n.Message{
+		ID:    "admin.list.entry",
+		One:   "- `{{.ID}}` **{{.Question}}** by {{.Creator}} in {{.Channel}}, created {{.Age}} ago, {{.Status}}: {{.Count}} vote",
+		Other: "- `{{.ID}}` **{{.Question}}** by {{.Creator}} in {{.Channel}}, created {{.Age}} ago, {{.Status}}: {{.Count}} votes",
+	}
+	adminListAnonymousCreator = &i18n.Message{
+		ID:    "admin.list.anonymousCreator",
+		Other: "an anonymous creator",
+	}
+	adminListStatusRunning = &i18n.Message{
+		ID:    "admin.list.status.running",
+		Other: "running",
+	}
+	adminListStatusScheduled = &i18n.Message{
+		ID:    "admin.list.status.scheduled",
+		Other: "scheduled",
+	}
+	adminListStatusEnded = &i18n.Message{
+		ID:    "admin.list.status.ended",
+		Other: "ended",
+	}
+	adminListStatusDeleted = &i18n.Message{
+		ID:    "admin.list.status.deleted",
+		Other: "deleted",
+	}
+	adminListNextPage = &i18n.Message{
+		ID:    "admin.list.nextPage",
+		Other: "Type `/{{.Trigger}} admin list {{.Next}}` to see the next page.",
+	}
+
+	adminEraseUnknownUser = &i18n.Message{
+		ID:    "admin.erase.unknownUser",
+		Other: "There is no user {{.User}}.",
+	}
+	adminEraseSuccess = &i18n.Message{
+		ID:    "admin.erase.success",
+		One:   "Erased the data of {{.User}} from {{.Count}} poll.",
+		Other: "Erased the data of {{.User}} from {{.Count}} polls.",
+	}
+)
+
+// executeAdminCommand runs the admin command given in params. Only system admins may use admin commands.
+func (p *MatterpollPlugin) executeAdminCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	page := 1
+	valid := false
+	if len(params) >= 1 {
+		switch params[0] {
+		case "list":
+			valid = len(params) <= 2
+			if valid && len(params) == 2 {
+				var err error
+				page, err = strconv.Atoi(params[1])
+				valid = err == nil && page >= 1
+			}
+		case "erase":
+			valid = len(params) == 2
+		case "export", "usage", "diagnose":
+			valid = len(params) == 1
+		}
+	}
+	if !valid {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAdminUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	isAdmin, appErr := p.isSystemAdmin(args.UserId)
+	if appErr != nil {
+		p.API.LogError("failed to check permission", "err", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	if !isAdmin {
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorAdminInvalidPermission), nil
+	}
+
+	switch params[0] {
+	case "erase":
+		return p.executeAdminEraseCommand(params[1], userLocalizer), nil
+	case "export":
+		return p.executeAdminExportCommand(args.UserId, userLocalizer), nil
+	case "usage":
+		return p.executeAdminUsageCommand(userLocalizer), nil
+	case "diagnose":
+		return p.executeAdminDiagnoseCommand(userLocalizer), nil
+	}
+
+	msg, err := p.listAllPolls(page, userLocalizer, trigger)
+	if err != nil {
+		p.API.LogError("failed to list polls", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	return msg, nil
+}
+
+// executeAdminEraseCommand erases the data of a user given by username or user ID from all polls.
+// A user ID is accepted even if the user doesn't exist anymore, so the data of deleted users can be erased.
+func (p *MatterpollPlugin) executeAdminEraseCommand(name string, userLocalizer *i18n.Localizer) string {
+	userID := ""
+	displayName := name
+	if user, appErr := p.API.GetUserByUsername(strings.TrimPrefix(name, "@")); appErr == nil {
+		userID = user.Id
+		displayName = "@" + user.Username
+	} else if model.IsValidId(name) {
+		userID = name
+	} else {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminEraseUnknownUser,
+			TemplateData:   map[string]interface{}{"User": name},
+		})
+	}
+
+	count, err := p.eraseUser(userID)
+	if err != nil {
+		p.API.LogError("failed to erase user", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: adminEraseSuccess,
+		TemplateData:   map[string]interface{}{"User": displayName, "Count": count},
+		PluralCount:    count,
+	})
+}
+
+// eraseUser removes all votes of a given user from all stored polls, including archived ones, and anonymizes the polls the user created and their audit log entries.
+// The posts of the affected polls are updated to show the recomputed results. It returns the number of affected polls.
+// Erasing is idempotent, so it can be repeated after a failure.
+func (p *MatterpollPlugin) eraseUser(userID string) (int, error) {
+	polls, err := p.Store.Poll().List()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list polls")
+	}
+	archived, err := p.Store.Poll().ListArchived()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list archived polls")
+	}
+	polls = append(polls, archived...)
+
+	count := 0
+	for _, listedPoll := range polls {
+		if !listedPoll.EraseUser(userID) {
+			continue
+		}
+		erasedPoll, err := p.Store.Poll().Update(listedPoll.ID, func(latest *poll.Poll) error {
+			latest.EraseUser(userID)
+			return nil
+		})
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to erase user from poll %s", listedPoll.ID)
+		}
+		count++
+		if erasedPoll.IsScheduled() {
+			continue
+		}
+
+		// The data is already erased, so a stale post is only logged
+		if appErr := p.updatePollPost(erasedPoll); appErr != nil {
+			p.API.LogWarn("failed to update post of erased poll", "pollID", erasedPoll.ID, "error", appErr.Error())
+		}
+		p.publishPollEvent(websocketEventPollUpdated, erasedPoll)
+	}
+	if err := p.Store.Vote().Delete(userID); err != nil {
+		return count, errors.Wrap(err, "failed to erase voted polls")
+	}
+	if err := p.Store.Audit().AnonymizeUser(userID); err != nil {
+		return count, errors.Wrap(err, "failed to anonymize audit log")
+	}
+	return count, nil
+}
+
+// listAllPolls returns a message that lists a given page of all stored polls, newest first. Pages start at one.
+func (p *MatterpollPlugin) listAllPolls(page int, userLocalizer *i18n.Localizer, trigger string) (string, error) {
+	polls, total, err := p.Store.Poll().ListPage(page-1, adminListPerPage)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list polls")
+	}
+	if total == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, adminListNone), nil
+	}
+	pages := (total + adminListPerPage - 1) / adminListPerPage
+	if len(polls) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminListPageEmpty,
+			TemplateData:   map[string]interface{}{"Page": page, "Pages": pages},
+		}), nil
+	}
+
+	lines := []string{p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: adminListHeading,
+		TemplateData:   map[string]interface{}{"Page": page, "Pages": pages, "Count": total},
+		PluralCount:    total,
+	})}
+	now := time.Unix(0, model.GetMillis()*int64(time.Millisecond))
+	for _, listedPoll := range polls {
+		line, err := p.formatAdminListEntry(listedPoll, now, userLocalizer)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	if page < pages {
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminListNextPage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger, "Next": page + 1},
+		}))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// formatAdminListEntry returns the line of a given poll in the list of all polls
+func (p *MatterpollPlugin) formatAdminListEntry(listedPoll *poll.Poll, now time.Time, userLocalizer *i18n.Localizer) (string, error) {
+	creator := p.LocalizeDefaultMessage(userLocalizer, adminListAnonymousCreator)
+	if !listedPoll.Settings.AnonymousCreator {
+		displayName, appErr := p.ConvertCreatorIDToDisplayName(listedPoll.Creator)
+		if appErr != nil {
+			return "", errors.Wrap(appErr, "failed to get display name for creator")
+		}
+		creator = displayName
+	}
+
+	channel, appErr := p.API.GetChannel(listedPoll.ChannelID)
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to get channel")
+	}
+
+	status := adminListStatusRunning
+	if listedPoll.IsDeleted() {
+		status = adminListStatusDeleted
+	} else if listedPoll.IsEnded() {
+		status = adminListStatusEnded
+	} else if listedPoll.IsScheduled() {
+		status = adminListStatusScheduled
+	}
+
+	count := listedPoll.NumberOfVoters()
+	created := time.Unix(0, listedPoll.CreatedAt*int64(time.Millisecond))
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: adminListEntry,
+		TemplateData: map[string]interface{}{
+			"ID":       listedPoll.ID,
+			"Question": listedPoll.Question,
+			"Creator":  creator,
+			"Channel":  "~" + channel.Name,
+			"Age":      formatAge(now.Sub(created)),
+			"Status":   p.LocalizeDefaultMessage(userLocalizer, status),
+			"Count":    count,
+		},
+		PluralCount: count,
+	}), nil
+}
+
+// formatAge returns a given duration in the largest whole unit, e.g. 3d, 5h or 12m
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
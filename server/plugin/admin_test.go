@@ -0,0 +1,483 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExecuteAdminCommand(t *testing.T) {
+	systemAdmin := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	endedPoll := testutils.GetPollWithSettings(poll.Settings{AnonymousCreator: true})
+	endedPoll.ID = "pollID2"
+	endedPoll.ChannelID = "channelID2"
+	endedPoll.EndedAt = 1234567890
+	runningPoll := testutils.GetPollWithVotes()
+	runningPoll.ChannelID = "channelID1"
+	usage := "Usage: `/poll admin list [page]`, `/poll admin erase <username or user ID>`, `/poll admin export`, `/poll admin usage` or `/poll admin diagnose`"
+	postedPoll := func() *poll.Poll {
+		p := testutils.GetPollWithVotes()
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI     func(*plugintest.API) *plugintest.API
+		SetupStore   func(*mockstore.Store) *mockstore.Store
+		Params       []string
+		ExpectedText string
+	}{
+		"First page": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Name: "town-square"}, nil)
+				api.On("GetChannel", "channelID2").Return(&model.Channel{Name: "off-topic"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListPage", 0, adminListPerPage).Return([]*poll.Poll{runningPoll, endedPoll}, 21, nil)
+				return store
+			},
+			Params: []string{"list"},
+			ExpectedText: "All polls, newest first (page 1 of 2, 21 polls in total):\n" +
+				"- `" + testutils.GetPollID() + "` **Question** by user1 in ~town-square, created 3d ago, running: 4 votes\n" +
+				"- `pollID2` **Question** by an anonymous creator in ~off-topic, created 3d ago, ended: 0 votes\n" +
+				"Type `/poll admin list 2` to see the next page.",
+		},
+		"Last page": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetChannel", "channelID2").Return(&model.Channel{Name: "off-topic"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListPage", 1, adminListPerPage).Return([]*poll.Poll{endedPoll}, 21, nil)
+				return store
+			},
+			Params: []string{"list", "2"},
+			ExpectedText: "All polls, newest first (page 2 of 2, 21 polls in total):\n" +
+				"- `pollID2` **Question** by an anonymous creator in ~off-topic, created 3d ago, ended: 0 votes",
+		},
+		"No polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListPage", 0, adminListPerPage).Return([]*poll.Poll{}, 0, nil)
+				return store
+			},
+			Params:       []string{"list"},
+			ExpectedText: adminListNone.Other,
+		},
+		"Page past the end": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListPage", 4, adminListPerPage).Return([]*poll.Poll{}, 21, nil)
+				return store
+			},
+			Params:       []string{"list", "5"},
+			ExpectedText: "Page 5 is empty. There are only 2 pages.",
+		},
+		"PollStore.ListPage fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListPage", 0, adminListPerPage).Return(nil, 0, errors.New(""))
+				return store
+			},
+			Params:       []string{"list"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"GetChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetChannel", "channelID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListPage", 0, adminListPerPage).Return([]*poll.Poll{runningPoll}, 1, nil)
+				return store
+			},
+			Params:       []string{"list"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Erase user by username": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				otherPoll := testutils.GetPoll()
+				otherPoll.ID = "pollID2"
+				store.PollStore.On("List").Return([]*poll.Poll{otherPoll}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{postedPoll()}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(postedPoll()))
+				store.VoteStore.On("Delete", "userID2").Return(nil)
+				store.AuditStore.On("AnonymizeUser", "userID2").Return(nil)
+				return store
+			},
+			Params:       []string{"erase", "@user2"},
+			ExpectedText: "Erased the data of @user2 from 1 poll.",
+		},
+		"Erase deleted user by user ID": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "abcdefghijklmnopqrstuvwxyz").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{testutils.GetPoll()}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.VoteStore.On("Delete", "abcdefghijklmnopqrstuvwxyz").Return(nil)
+				store.AuditStore.On("AnonymizeUser", "abcdefghijklmnopqrstuvwxyz").Return(nil)
+				return store
+			},
+			Params:       []string{"erase", "abcdefghijklmnopqrstuvwxyz"},
+			ExpectedText: "Erased the data of abcdefghijklmnopqrstuvwxyz from 0 polls.",
+		},
+		"Erase scheduled poll of creator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				scheduledPoll := testutils.GetPollWithSettings(poll.Settings{PostAt: 1234567890})
+				store.PollStore.On("List").Return([]*poll.Poll{scheduledPoll}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithSettings(poll.Settings{PostAt: 1234567890})))
+				store.VoteStore.On("Delete", "userID1").Return(nil)
+				store.AuditStore.On("AnonymizeUser", "userID1").Return(nil)
+				return store
+			},
+			Params:       []string{"erase", "user1"},
+			ExpectedText: "Erased the data of @user1 from 1 poll.",
+		},
+		"Erase user, UpdatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{postedPoll()}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(postedPoll()))
+				store.VoteStore.On("Delete", "userID2").Return(nil)
+				store.AuditStore.On("AnonymizeUser", "userID2").Return(nil)
+				return store
+			},
+			Params:       []string{"erase", "user2"},
+			ExpectedText: "Erased the data of @user2 from 1 poll.",
+		},
+		"Erase user, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{postedPoll()}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			Params:       []string{"erase", "user2"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Erase user, VoteStore.Delete fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{testutils.GetPoll()}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.VoteStore.On("Delete", "userID2").Return(errors.New(""))
+				return store
+			},
+			Params:       []string{"erase", "user2"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Erase user, AuditStore.AnonymizeUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{testutils.GetPoll()}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.VoteStore.On("Delete", "userID2").Return(nil)
+				store.AuditStore.On("AnonymizeUser", "userID2").Return(errors.New(""))
+				return store
+			},
+			Params:       []string{"erase", "user2"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Erase user, PollStore.List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			Params:       []string{"erase", "user2"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Erase user, PollStore.ListArchived fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{postedPoll()}, nil)
+				store.PollStore.On("ListArchived").Return(nil, errors.New(""))
+				return store
+			},
+			Params:       []string{"erase", "user2"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Export": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("UploadFile", mock.AnythingOfType("[]uint8"), "channelID1", "matterpoll-backup-1970-01-18-075607.json").Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					Message:   "Here is the backup of 2 polls. Send it to `https://example.org/plugins/com.github.matterpoll.matterpoll/api/v1/admin/backup` to restore it.",
+					FileIds:   []string{"fileID1"},
+				}).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{runningPoll}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{endedPoll}, nil)
+				store.JobStore.On("List").Return([]*job.Job{}, nil)
+				store.AuditStore.On("List").Return([]*audit.Entry{}, nil)
+				return store
+			},
+			Params:       []string{"export"},
+			ExpectedText: adminExportSuccess.Other,
+		},
+		"Export, UploadFile fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("UploadFile", mock.AnythingOfType("[]uint8"), "channelID1", "matterpoll-backup-1970-01-18-075607.json").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.JobStore.On("List").Return([]*job.Job{}, nil)
+				store.AuditStore.On("List").Return([]*audit.Entry{}, nil)
+				return store
+			},
+			Params:       []string{"export"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Export, PollStore.List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			Params:       []string{"export"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Export with argument": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"export", "all"},
+			ExpectedText: usage,
+		},
+		"Usage": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				anonymousPoll := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, Progress: true})
+				store.PollStore.On("List").Return([]*poll.Poll{runningPoll, anonymousPoll}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{endedPoll}, nil)
+				store.StatsStore.On("Get").Return(&stats.Stats{VotesPerDay: map[string]int{"1970-01-15": 3, "1969-11-01": 5}}, nil)
+				return store
+			},
+			Params: []string{"usage"},
+			ExpectedText: "#### Poll usage\n" +
+				"**Polls**: 3 (2 running, 0 surveys)\n" +
+				"**Last 30 days**: 3 polls created, 3 votes cast\n" +
+				"**Voters in all polls**: 4\n" +
+				"**Poll Settings**: `anonymous` (1), `anonymous-creator` (1), `progress` (1)\n" +
+				"\n" +
+				adminUsageTelemetryDisabled.Other,
+		},
+		"Usage without polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.StatsStore.On("Get").Return(nil, nil)
+				return store
+			},
+			Params: []string{"usage"},
+			ExpectedText: "#### Poll usage\n" +
+				"**Polls**: 0 (0 running, 0 surveys)\n" +
+				"**Last 30 days**: 0 polls created, 0 votes cast\n" +
+				"**Voters in all polls**: 0\n" +
+				"**Poll Settings**: none\n" +
+				"\n" +
+				adminUsageTelemetryDisabled.Other,
+		},
+		"Usage, StatsStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.StatsStore.On("Get").Return(nil, errors.New(""))
+				return store
+			},
+			Params:       []string{"usage"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Usage with argument": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"usage", "all"},
+			ExpectedText: usage,
+		},
+		"Erase unknown user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUserByUsername", "nobody").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"erase", "nobody"},
+			ExpectedText: "There is no user nobody.",
+		},
+		"Erase without user": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"erase"},
+			ExpectedText: usage,
+		},
+		"Not a system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"list"},
+			ExpectedText: commandErrorAdminInvalidPermission.Other,
+		},
+		"No sub command": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{},
+			ExpectedText: usage,
+		},
+		"Unknown sub command": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"delete"},
+			ExpectedText: usage,
+		},
+		"Invalid page": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"list", "0"},
+			ExpectedText: usage,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 + int64(73*time.Hour/time.Millisecond) })
+			defer patch.Unpatch()
+
+			msg, appErr := p.executeAdminCommand(&model.CommandArgs{UserId: "userID1"}, test.Params)
+
+			assert.Nil(t, appErr)
+			assert.Equal(t, test.ExpectedText, msg)
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	for _, test := range []struct {
+		Duration time.Duration
+		Expected string
+	}{
+		{Duration: 0, Expected: "0m"},
+		{Duration: 59 * time.Second, Expected: "0m"},
+		{Duration: 59 * time.Minute, Expected: "59m"},
+		{Duration: time.Hour, Expected: "1h"},
+		{Duration: 23*time.Hour + 59*time.Minute, Expected: "23h"},
+		{Duration: 24 * time.Hour, Expected: "1d"},
+		{Duration: 400 * 24 * time.Hour, Expected: "400d"},
+	} {
+		t.Run(test.Expected, func(t *testing.T) {
+			assert.Equal(t, test.Expected, formatAge(test.Duration))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+// statsFastestVoters is the number of fastest voters the statistics of a poll list
+const statsFastestVoters = 3
+
+var (
+	commandHelpTextStats = &i18n.Message{
+		ID:    "command.help.text.stats",
+		Other: "To see how users took part in a poll, type `/{{.Trigger}} stats <poll ID>`",
+	}
+	commandErrorStatsUsage = &i18n.Message{
+		ID:    "command.error.stats.usage",
+		Other: "Usage: `/{{.Trigger}} stats <poll ID>`",
+	}
+	commandErrorStatsInvalidPermission = &i18n.Message{
+		ID:    "command.error.stats.invalidPermission",
+		Other: "Only the creator of a poll, its moderators and System Admins can see its statistics.",
+	}
+
+	statsHeading = &i18n.Message{
+		ID:    "stats.heading",
+		Other: "#### Statistics of \"{{.Question}}\"",
+	}
+	statsParticipation = &i18n.Message{
+		ID:    "stats.participation",
+		Other: "**Participation**: {{.Voters}} of {{.Members}} channel members voted ({{.Rate}}%)",
+	}
+	statsVoteChanges = &i18n.Message{
+		ID:    "stats.voteChanges",
+		Other: "**Changed votes**: {{.Count}}",
+	}
+	statsVoteTimesHeading = &i18n.Message{
+		ID:    "stats.voteTimes.heading",
+		Other: "**First votes after the start of the poll**",
+	}
+	statsVoteTimesHeader = &i18n.Message{
+		ID:    "stats.voteTimes.header",
+		Other: "| Time | Voters |",
+	}
+	statsVoteTimesWithin = &i18n.Message{
+		ID:    "stats.voteTimes.within",
+		Other: "Within {{.To}}",
+	}
+	statsVoteTimesBetween = &i18n.Message{
+		ID:    "stats.voteTimes.between",
+		Other: "{{.From}} to {{.To}}",
+	}
+	statsVoteTimesAfter = &i18n.Message{
+		ID:    "stats.voteTimes.after",
+		Other: "After {{.From}}",
+	}
+	statsVoteTimesUnknown = &i18n.Message{
+		ID:    "stats.voteTimes.unknown",
+		Other: "Unknown",
+	}
+	statsFastestVotersList = &i18n.Message{
+		ID:    "stats.fastestVoters",
+		Other: "**Fastest voters**: {{.Voters}}",
+	}
+)
+
+// executeStatsCommand shows how users took part in the poll with the ID given in params
+func (p *MatterpollPlugin) executeStatsCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	if len(params) != 1 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorStatsUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	msg, err := p.makePollStats(params[0], args.UserId, userLocalizer)
+	if err != nil {
+		p.API.LogError("failed to get poll statistics", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	return msg, nil
+}
+
+// makePollStats returns the statistics of a given poll for a given user.
+// Participation is measured against the current members of the channel the poll was posted in.
+func (p *MatterpollPlugin) makePollStats(pollID, userID string, userLocalizer *i18n.Localizer) (string, error) {
+	statsPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(statsPoll, userID)
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorStatsInvalidPermission), nil
+	}
+	if statsPoll.IsScheduled() {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorNotPosted,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	channelStats, appErr := p.API.GetChannelStats(statsPoll.ChannelID)
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to get channel stats")
+	}
+	analytics := statsPoll.Analytics(statsFastestVoters)
+	rate := 0
+	if channelStats.MemberCount > 0 {
+		rate = int(int64(analytics.Voters) * 100 / channelStats.MemberCount)
+	}
+
+	lines := []string{
+		p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: statsHeading,
+			TemplateData:   map[string]interface{}{"Question": statsPoll.Question},
+		}),
+		p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: statsParticipation,
+			TemplateData: map[string]interface{}{
+				"Voters":  analytics.Voters,
+				"Members": channelStats.MemberCount,
+				"Rate":    rate,
+			},
+		}),
+		p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: statsVoteChanges,
+			TemplateData:   map[string]interface{}{"Count": analytics.VoteChanges},
+		}),
+		"",
+		p.LocalizeDefaultMessage(userLocalizer, statsVoteTimesHeading),
+		"",
+		p.LocalizeDefaultMessage(userLocalizer, statsVoteTimesHeader),
+		"|:--|--:|",
+	}
+	for i, count := range analytics.VotersPerBucket {
+		lines = append(lines, "| "+p.makeVoteTimeBucketLabel(i, userLocalizer)+" | "+strconv.Itoa(count)+" |")
+	}
+	if analytics.UnknownVoteTimes > 0 {
+		lines = append(lines, "| "+p.LocalizeDefaultMessage(userLocalizer, statsVoteTimesUnknown)+" | "+strconv.Itoa(analytics.UnknownVoteTimes)+" |")
+	}
+
+	// Anonymous polls must not reveal who voted when
+	if !statsPoll.Settings.Anonymous && len(analytics.FastestVoters) > 0 {
+		voters := []string{}
+		for _, r := range analytics.FastestVoters {
+			user, appErr := p.API.GetUser(r.UserID)
+			if appErr != nil {
+				return "", errors.Wrap(appErr, "failed to get user")
+			}
+			voters = append(voters, "@"+user.Username+" ("+formatAge(r.After)+")")
+		}
+		lines = append(lines, "", p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: statsFastestVotersList,
+			TemplateData:   map[string]interface{}{"Voters": strings.Join(voters, ", ")},
+		}))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// makeVoteTimeBucketLabel returns the label of the time range with a given index of poll.VoteTimeBuckets
+func (p *MatterpollPlugin) makeVoteTimeBucketLabel(index int, userLocalizer *i18n.Localizer) string {
+	bounds := poll.VoteTimeBuckets
+	switch {
+	case index == 0:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: statsVoteTimesWithin,
+			TemplateData:   map[string]interface{}{"To": formatAge(bounds[0])},
+		})
+	case index == len(bounds):
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: statsVoteTimesAfter,
+			TemplateData:   map[string]interface{}{"From": formatAge(bounds[index-1])},
+		})
+	default:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: statsVoteTimesBetween,
+			TemplateData:   map[string]interface{}{"From": formatAge(bounds[index-1]), "To": formatAge(bounds[index])},
+		})
+	}
+}
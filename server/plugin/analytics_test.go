@@ -0,0 +1,151 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteStatsCommand(t *testing.T) {
+	statsPoll := func(settings poll.Settings) *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(settings)
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		p.VotedAt = map[string]int64{
+			"userID1": p.CreatedAt + 2*60*1000,
+			"userID2": p.CreatedAt + 30*60*1000,
+			"userID3": p.CreatedAt + 3*60*60*1000,
+		}
+		p.VoteChanges = 2
+		return p
+	}
+	stats := "#### Statistics of \"Question\"\n" +
+		"**Participation**: 4 of 10 channel members voted (40%)\n" +
+		"**Changed votes**: 2\n" +
+		"\n" +
+		"**First votes after the start of the poll**\n" +
+		"\n" +
+		"| Time | Voters |\n" +
+		"|:--|--:|\n" +
+		"| Within 5m | 1 |\n" +
+		"| 5m to 1h | 1 |\n" +
+		"| 1h to 1d | 1 |\n" +
+		"| 1d to 7d | 0 |\n" +
+		"| After 7d | 0 |\n" +
+		"| Unknown | 1 |"
+
+	for name, test := range map[string]struct {
+		SetupAPI     func(*plugintest.API) *plugintest.API
+		SetupStore   func(*mockstore.Store) *mockstore.Store
+		UserID       string
+		Params       []string
+		ExpectedText string
+	}{
+		"All fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelStats", "channelID1").Return(&model.ChannelStats{ChannelId: "channelID1", MemberCount: 10}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(statsPoll(poll.Settings{}), nil)
+				return store
+			},
+			UserID:       "userID1",
+			Params:       []string{testutils.GetPollID()},
+			ExpectedText: stats + "\n\n**Fastest voters**: @user1 (2m), @user2 (30m), @user3 (3h)",
+		},
+		"All fine, anonymous poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelStats", "channelID1").Return(&model.ChannelStats{ChannelId: "channelID1", MemberCount: 10}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(statsPoll(poll.Settings{Anonymous: true}), nil)
+				return store
+			},
+			UserID:       "userID1",
+			Params:       []string{testutils.GetPollID()},
+			ExpectedText: stats,
+		},
+		"Not the creator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(statsPoll(poll.Settings{}), nil)
+				return store
+			},
+			UserID:       "userID2",
+			Params:       []string{testutils.GetPollID()},
+			ExpectedText: commandErrorStatsInvalidPermission.Other,
+		},
+		"Scheduled poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				scheduledPoll := testutils.GetPollWithSettings(poll.Settings{PostAt: 1714554000000})
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduledPoll, nil)
+				return store
+			},
+			UserID:       "userID1",
+			Params:       []string{testutils.GetPollID()},
+			ExpectedText: "This poll hasn't been posted yet. Type `/poll scheduled` to see and cancel your scheduled polls.",
+		},
+		"GetChannelStats fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelStats", "channelID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(statsPoll(poll.Settings{}), nil)
+				return store
+			},
+			UserID:       "userID1",
+			Params:       []string{testutils.GetPollID()},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, errors.New(""))
+				return store
+			},
+			UserID:       "userID1",
+			Params:       []string{testutils.GetPollID()},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"No poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:       "userID1",
+			Params:       []string{},
+			ExpectedText: "Usage: `/poll stats <poll ID>`",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, appErr := p.executeStatsCommand(&model.CommandArgs{UserId: test.UserID, ChannelId: "channelID1"}, test.Params)
+
+			assert.Nil(t, appErr)
+			assert.Equal(t, test.ExpectedText, msg)
+		})
+	}
+}
@@ -1,23 +1,56 @@
 package plugin
 
 import (
+	"crypto/subtle"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
 )
 
 const (
 	iconFilename = "logo_dark.png"
+	// openAPIFilename is the asset that describes the HTTP API of the plugin
+	openAPIFilename = "openapi.json"
 
 	addOptionKey = "answerOption"
+	writeInKey   = "answer"
+	// transferPollKey is the element of the transfer poll dialog that holds the user ID of the new owner
+	transferPollKey = "newOwner"
+	// rankOptionKeyPrefix is followed by the zero-based rank of an element in the rank options dialog
+	rankOptionKeyPrefix = "rank"
+	// rateOptionKeyPrefix is followed by the index of an answer option in the rate options dialog
+	rateOptionKeyPrefix = "rate"
+	// estimateKey is the element of the estimate dialog that holds the number a voter submits
+	estimateKey = "estimate"
+
+	// Element names of the create poll dialog
+	createPollQuestionKey = "question"
+	createPollOptionsKey  = "options"
+	createPollVoteModeKey = "votemode"
+	createPollSettingsKey = "settings"
+
+	// apiTokenHeader is the request header that carries the API token of external tools
+	apiTokenHeader = "Matterpoll-Token"
+	// pluginIDHeader is the request header in which Mattermost passes the ID of the plugin that sent an inter-plugin request
+	pluginIDHeader = "Mattermost-Plugin-ID"
+	// interPluginRequestsServerVersion is the first server version that supports inter-plugin requests.
+	// Older servers don't strip pluginIDHeader from the requests of other clients, hence it can't be trusted.
+	interPluginRequestsServerVersion = "5.18.0"
+
+	// channelMembersPerPage is the number of channel members fetched per GetChannelMembers call
+	channelMembersPerPage = 100
 )
 
 type (
@@ -25,9 +58,31 @@ type (
 	submitDialogHandler func(map[string]string, *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error)
 )
 
+// createPollRequest is the payload that external tools send to create a poll
+type createPollRequest struct {
+	ChannelID string `json:"channel_id"`
+	RootID    string `json:"root_id"`
+	// UserID is the creator of the poll. The bot becomes the creator if it's empty.
+	UserID        string   `json:"user_id"`
+	Question      string   `json:"question"`
+	AnswerOptions []string `json:"answer_options"`
+	Settings      []string `json:"settings"`
+}
+
+// createPollResponse is the response to a createPollRequest
+type createPollResponse struct {
+	PollID string `json:"poll_id"`
+	PostID string `json:"post_id"`
+}
+
 var (
 	infoMessage = "Thanks for using Matterpoll v" + manifest.ID + "\n"
 
+	responseCreatePollScheduled = &i18n.Message{
+		ID:    "response.createPoll.scheduled",
+		Other: "Your poll has been scheduled and will be posted at the chosen time.",
+	}
+
 	responseVoteCounted = &i18n.Message{
 		ID:    "response.vote.counted",
 		Other: "Your vote has been counted.",
@@ -36,6 +91,111 @@ var (
 		ID:    "response.vote.updated",
 		Other: "Your vote has been updated.",
 	}
+	responseVoteRemoved = &i18n.Message{
+		ID:    "response.vote.removed",
+		Other: "Your vote has been removed.",
+	}
+	responseResetVoteSuccess = &i18n.Message{
+		ID:    "response.resetVote.success",
+		Other: "All your votes have been removed. You can vote again as long as the poll is running.",
+	}
+	responseResetVoteNotVoted = &i18n.Message{
+		ID:    "response.resetVote.notVoted",
+		Other: "You haven't voted in this poll.",
+	}
+	responseVotePollEnded = &i18n.Message{
+		ID:    "response.vote.pollEnded",
+		Other: "This poll has already ended.",
+	}
+	responseVotePaused = &i18n.Message{
+		ID:    "response.vote.paused",
+		Other: "Voting in this poll is paused. Please try again once it has been resumed.",
+	}
+	responseVoteNotMember = &i18n.Message{
+		ID:    "response.vote.notMember",
+		Other: "Only members of the channel this poll was posted in can vote.",
+	}
+	responseVoteLocked = &i18n.Message{
+		ID:    "response.vote.locked",
+		Other: "You have already voted in this poll. Votes can't be changed.",
+	}
+	responseVoteLimitReached = &i18n.Message{
+		ID:    "response.vote.limitReached",
+		Other: "You have already used all of your votes. Remove one of your votes to pick another option.",
+	}
+	responseVoteOptionFull = &i18n.Message{
+		ID:    "response.vote.optionFull",
+		Other: "This option is full. Please pick another one.",
+	}
+	responseVoteVotesUsed = &i18n.Message{
+		ID:    "response.vote.votesUsed",
+		Other: "{{.Used}} of {{.Max}} votes used.",
+	}
+
+	dialogConfirmVoteTitle = &i18n.Message{
+		ID:    "dialog.confirmVote.title",
+		Other: "Confirm Vote",
+	}
+	dialogConfirmVoteIntroductionText = &i18n.Message{
+		ID:    "dialog.confirmVote.introductionText",
+		Other: "You are about to vote for **{{.Answer}}**. Your vote is final and can't be changed afterwards.",
+	}
+	dialogConfirmVoteSubmitLabel = &i18n.Message{
+		ID:    "dialog.confirmVote.submitLabel",
+		Other: "Vote",
+	}
+
+	dialogConfirmEndPollTitle = &i18n.Message{
+		ID:    "dialog.confirmEndPoll.title",
+		Other: "End Poll",
+	}
+	dialogConfirmEndPollIntroductionText = &i18n.Message{
+		ID:    "dialog.confirmEndPoll.introductionText",
+		One:   "Do you really want to end the poll **{{.Question}}**? It has {{.Count}} vote so far. Nobody can vote anymore once it has ended.",
+		Other: "Do you really want to end the poll **{{.Question}}**? It has {{.Count}} votes so far. Nobody can vote anymore once it has ended.",
+	}
+	dialogConfirmEndPollIntroductionTextSecret = &i18n.Message{
+		ID:    "dialog.confirmEndPoll.introductionText.secret",
+		Other: "Do you really want to end the poll **{{.Question}}**? Nobody can vote anymore once it has ended.",
+	}
+	dialogConfirmEndPollSubmitLabel = &i18n.Message{
+		ID:    "dialog.confirmEndPoll.submitLabel",
+		Other: "End",
+	}
+	dialogConfirmDeletePollTitle = &i18n.Message{
+		ID:    "dialog.confirmDeletePoll.title",
+		Other: "Delete Poll",
+	}
+	dialogConfirmDeletePollIntroductionText = &i18n.Message{
+		ID:    "dialog.confirmDeletePoll.introductionText",
+		One:   "Do you really want to delete the poll **{{.Question}}**? Its {{.Count}} vote will be lost. This can't be undone.",
+		Other: "Do you really want to delete the poll **{{.Question}}**? Its {{.Count}} votes will be lost. This can't be undone.",
+	}
+	dialogConfirmDeletePollIntroductionTextSecret = &i18n.Message{
+		ID:    "dialog.confirmDeletePoll.introductionText.secret",
+		Other: "Do you really want to delete the poll **{{.Question}}**? All votes will be lost. This can't be undone.",
+	}
+	dialogConfirmDeletePollSubmitLabel = &i18n.Message{
+		ID:    "dialog.confirmDeletePoll.submitLabel",
+		Other: "Delete",
+	}
+
+	dialogTransferPollTitle = &i18n.Message{
+		ID:    "dialog.transferPoll.title",
+		Other: "Transfer Poll",
+	}
+	dialogTransferPollSubmitLabel = &i18n.Message{
+		ID:    "dialog.transferPoll.submitLabel",
+		Other: "Transfer",
+	}
+	dialogTransferPollElementDisplayName = &i18n.Message{
+		ID:    "dialog.transferPoll.element.displayName",
+		Other: "New Owner",
+	}
+	dialogTransferPollElementHelpText = &i18n.Message{
+		ID:    "dialog.transferPoll.element.helpText",
+		Other: "The new owner can end and delete the poll. You lose these rights unless you are a moderator of the poll or a System Admin.",
+	}
 
 	responseAddOptionSuccess = &i18n.Message{
 		ID:    "response.addOption.success",
@@ -58,11 +218,103 @@ var (
 		ID:    "dialog.addOption.element.displayName",
 		Other: "Option",
 	}
+	dialogAddOptionElementHelpText = &i18n.Message{
+		ID:    "dialog.addOption.element.helpText",
+		Other: "To describe the option or show an image next to it, add a description or image URL, e.g. \"Logo A|Our current logo|https://example.com/a.png\".",
+	}
+
+	dialogWriteInTitle = &i18n.Message{
+		ID:    "dialog.writeIn.title",
+		Other: "Other Answer",
+	}
+	dialogWriteInSubmitLabel = &i18n.Message{
+		ID:    "dialog.writeIn.submitLabel",
+		Other: "Vote",
+	}
+	dialogWriteInElementDisplayName = &i18n.Message{
+		ID:    "dialog.writeIn.element.displayName",
+		Other: "Answer",
+	}
+	dialogWriteInElementHelpText = &i18n.Message{
+		ID:    "dialog.writeIn.element.helpText",
+		Other: "Answers that only differ in case or spacing are counted together.",
+	}
+	dialogWriteInIntroductionTextLocked = &i18n.Message{
+		ID:    "dialog.writeIn.introductionText.locked",
+		Other: "Your answer is final and can't be changed afterwards.",
+	}
+
+	dialogRankOptionsTitle = &i18n.Message{
+		ID:    "dialog.rankOptions.title",
+		Other: "Rank Options",
+	}
+	dialogRankOptionsSubmitLabel = &i18n.Message{
+		ID:    "dialog.rankOptions.submitLabel",
+		Other: "Vote",
+	}
+	dialogRankOptionsElementDisplayName = &i18n.Message{
+		ID:    "dialog.rankOptions.element.displayName",
+		Other: "Choice {{.Rank}}",
+	}
+	dialogRankOptionsIntroductionTextLocked = &i18n.Message{
+		ID:    "dialog.rankOptions.introductionText.locked",
+		Other: "Your ranking is final and can't be changed afterwards.",
+	}
+	dialogRankOptionsErrorDuplicate = &i18n.Message{
+		ID:    "dialog.rankOptions.error.duplicate",
+		Other: "This option has already been ranked.",
+	}
+
+	dialogRateOptionsTitle = &i18n.Message{
+		ID:    "dialog.rateOptions.title",
+		Other: "Rate Options",
+	}
+	dialogRateOptionsSubmitLabel = &i18n.Message{
+		ID:    "dialog.rateOptions.submitLabel",
+		Other: "Vote",
+	}
+	dialogRateOptionsIntroductionText = &i18n.Message{
+		ID:    "dialog.rateOptions.introductionText",
+		Other: "Rate the answer options from one to five stars. You can leave out options you don't want to rate.",
+	}
+	dialogRateOptionsIntroductionTextLocked = &i18n.Message{
+		ID:    "dialog.rateOptions.introductionText.locked",
+		Other: "Your ratings are final and can't be changed afterwards.",
+	}
+	dialogRateOptionsErrorEmpty = &i18n.Message{
+		ID:    "dialog.rateOptions.error.empty",
+		Other: "Rate at least one option.",
+	}
+
+	dialogEstimateTitle = &i18n.Message{
+		ID:    "dialog.estimate.title",
+		Other: "Submit Estimate",
+	}
+	dialogEstimateSubmitLabel = &i18n.Message{
+		ID:    "dialog.estimate.submitLabel",
+		Other: "Vote",
+	}
+	dialogEstimateElementDisplayName = &i18n.Message{
+		ID:    "dialog.estimate.element.displayName",
+		Other: "Estimate",
+	}
+	dialogEstimateElementHelpText = &i18n.Message{
+		ID:    "dialog.estimate.element.helpText",
+		Other: "Enter a number, e.g. 5 or 2.5.",
+	}
+	dialogEstimateIntroductionTextLocked = &i18n.Message{
+		ID:    "dialog.estimate.introductionText.locked",
+		Other: "Your estimate is final and can't be changed afterwards.",
+	}
 
 	responseEndPollSuccessfully = &i18n.Message{
 		ID:    "response.endPoll.successfully",
 		Other: "The poll **{{.Question}}** has ended and the original post have been updated. You can jump to it by pressing [here]({{.Link}}).",
 	}
+	responseEndPollSuccessfullyNoLink = &i18n.Message{
+		ID:    "response.endPoll.successfullyNoLink",
+		Other: "The poll **{{.Question}}** has ended and the original post has been updated.",
+	}
 	responseEndPollInvalidPermission = &i18n.Message{
 		ID:    "response.endPoll.invalidPermission",
 		Other: "Only the creator of a poll and System Admins are allowed to end it.",
@@ -72,10 +324,91 @@ var (
 		ID:    "response.deletePoll.success",
 		Other: "Successfully deleted the poll.",
 	}
+	responseDeletePollSuccessRestorable = &i18n.Message{
+		ID:    "response.deletePoll.successRestorable",
+		Other: "Successfully deleted the poll. Until it gets removed for good, you can restore it by typing `/{{.Trigger}} restore {{.ID}}`.",
+	}
+	responseDeletePollAlreadyDeleted = &i18n.Message{
+		ID:    "response.deletePoll.alreadyDeleted",
+		Other: "The poll has already been deleted.",
+	}
 	responseDeletePollInvalidPermission = &i18n.Message{
 		ID:    "response.deletePoll.invalidPermission",
 		Other: "Only the creator of a poll and System Admins are allowed to delete it.",
 	}
+
+	responseTransferPollSuccess = &i18n.Message{
+		ID:    "response.transferPoll.success",
+		Other: "Successfully transferred the poll.",
+	}
+	responseTransferPollInvalidPermission = &i18n.Message{
+		ID:    "response.transferPoll.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to transfer it.",
+	}
+	responseTransferPollUnknownUser = &i18n.Message{
+		ID:    "response.transferPoll.unknownUser",
+		Other: "The new owner couldn't be found. Please check the username.",
+	}
+	responseTransferPollBot = &i18n.Message{
+		ID:    "response.transferPoll.bot",
+		Other: "Polls can't be transferred to bots.",
+	}
+	responseTransferPollAlreadyOwner = &i18n.Message{
+		ID:    "response.transferPoll.alreadyOwner",
+		Other: "This user already owns the poll.",
+	}
+
+	responseExportPollSuccess = &i18n.Message{
+		ID:    "response.exportPoll.success",
+		Other: "The results have been sent to you as a direct message.",
+	}
+	responseExportPollInvalidPermission = &i18n.Message{
+		ID:    "response.exportPoll.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to export it.",
+	}
+	responseExportPollNotEnded = &i18n.Message{
+		ID:    "response.exportPoll.notEnded",
+		Other: "Only ended polls can be exported.",
+	}
+	exportPollPostMessage = &i18n.Message{
+		ID:    "exportPoll.post.message",
+		Other: "Here are the results of the poll **{{.Question}}**.",
+	}
+
+	responsePermalink = &i18n.Message{
+		ID:    "response.permalink",
+		Other: "[Jump to the poll]({{.Link}})",
+	}
+
+	responseRemindNonVotersSuccess = &i18n.Message{
+		ID:    "response.remindNonVoters.success",
+		Other: "Everyone in this channel who hasn't voted yet has been reminded.",
+	}
+	responseRemindNonVotersNone = &i18n.Message{
+		ID:    "response.remindNonVoters.none",
+		Other: "Everyone in this channel has already voted.",
+	}
+	responseRemindNonVotersInvalidPermission = &i18n.Message{
+		ID:    "response.remindNonVoters.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to remind non-voters.",
+	}
+	responseShowNonVotersInvalidPermission = &i18n.Message{
+		ID:    "response.showNonVoters.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to see who hasn't voted yet.",
+	}
+	showNonVotersMessage = &i18n.Message{
+		ID:    "showNonVoters.message",
+		One:   "{{.Count}} member of this channel hasn't voted yet: {{.Users}}",
+		Other: "{{.Count}} members of this channel haven't voted yet: {{.Users}}",
+	}
+	remindNonVotersPostMessage = &i18n.Message{
+		ID:    "remindNonVoters.post.message",
+		Other: "You haven't voted in the poll **{{.Question}}** yet. [Jump to the poll]({{.Link}}) to cast your vote.",
+	}
+	remindNonVotersPostMessageNoLink = &i18n.Message{
+		ID:    "remindNonVoters.post.messageNoLink",
+		Other: "You haven't voted in the poll **{{.Question}}** yet. Go to the conversation it was posted in to cast your vote.",
+	}
 )
 
 // InitAPI initializes the REST API
@@ -83,16 +416,60 @@ func (p *MatterpollPlugin) InitAPI() *mux.Router {
 	r := mux.NewRouter()
 	r.HandleFunc("/", p.handleInfo).Methods(http.MethodGet)
 	r.HandleFunc("/"+iconFilename, p.handleLogo).Methods(http.MethodGet)
+	r.HandleFunc("/metrics", p.handleMetrics).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/openapi.json", p.handleOpenAPI).Methods(http.MethodGet)
+
+	r.Handle("/api/v1/polls", p.checkAPIToken(http.HandlerFunc(p.handleCreatePollRequest))).Methods(http.MethodPost)
+	// The end button of poll posts uses the same path, hence the route only matches requests of external tools and other plugins
+	r.Handle("/api/v1/polls/{id:[a-z0-9]+}/end", p.checkAPIToken(http.HandlerFunc(p.handleEndPollRequest))).Methods(http.MethodPost).MatcherFunc(isAPIRequest)
 
 	apiV1 := r.PathPrefix("/api/v1").Subrouter()
 	apiV1.Use(checkAuthenticity)
+	apiV1.Handle("/admin/backup", p.checkSystemAdmin(http.HandlerFunc(p.handleDownloadBackup))).Methods(http.MethodGet)
+	apiV1.Handle("/admin/backup", p.checkSystemAdmin(http.HandlerFunc(p.handleRestoreBackup))).Methods(http.MethodPost)
+	apiV1.Handle("/admin/stats", p.checkSystemAdmin(http.HandlerFunc(p.handleStats))).Methods(http.MethodGet)
+	apiV1.Handle("/health", p.checkSystemAdmin(http.HandlerFunc(p.handleHealth))).Methods(http.MethodGet)
+	apiV1.Handle("/admin/import", p.checkSystemAdmin(http.HandlerFunc(p.handleImport))).Methods(http.MethodPost)
+	apiV1.HandleFunc("/polls/create", p.handleSubmitDialogRequest("createPoll", p.handleCreatePoll)).Methods(http.MethodPost)
+	apiV1.HandleFunc("/drafts/channel", p.handlePostActionIntegrationRequest("pickDraftChannel", p.handlePickDraftChannel)).Methods(http.MethodPost)
 
 	pollRouter := apiV1.PathPrefix("/polls/{id:[a-z0-9]+}").Subrouter()
-	pollRouter.HandleFunc("/vote/{optionNumber:[0-9]+}", p.handlePostActionIntegrationRequest(p.handleVote)).Methods(http.MethodPost)
-	pollRouter.HandleFunc("/option/add", p.handleSubmitDialogRequest(p.handleAddOption)).Methods(http.MethodPost)
-	pollRouter.HandleFunc("/option/add/request", p.handlePostActionIntegrationRequest(p.handleAddOptionDialogRequest)).Methods(http.MethodPost)
-	pollRouter.HandleFunc("/end", p.handlePostActionIntegrationRequest(p.handleEndPoll)).Methods(http.MethodPost)
-	pollRouter.HandleFunc("/delete", p.handlePostActionIntegrationRequest(p.handleDeletePoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/{optionNumber:[0-9]+}", p.handlePostActionIntegrationRequest("vote", p.handleVote)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/select", p.handlePostActionIntegrationRequest("selectVote", withSelectedOption(p.handleVote))).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/select/confirm/request", p.handlePostActionIntegrationRequest("selectVoteDialogRequest", withSelectedOption(p.handleConfirmVoteDialogRequest))).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/reset", p.handlePostActionIntegrationRequest("resetVote", p.handleResetVote)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/receipts/verify", p.handleVerifyReceipt).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/{optionNumber:[0-9]+}/confirm", p.handleSubmitDialogRequest("confirmVote", p.handleConfirmVote)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/vote/{optionNumber:[0-9]+}/confirm/request", p.handlePostActionIntegrationRequest("confirmVoteDialogRequest", p.handleConfirmVoteDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/survey/{questionNumber:[0-9]+}/vote/{optionNumber:[0-9]+}", p.handlePostActionIntegrationRequest("surveyVote", p.handleSurveyVote)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/option/add", p.handleSubmitDialogRequest("addOption", p.handleAddOption)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/option/add/request", p.handlePostActionIntegrationRequest("addOptionDialogRequest", p.handleAddOptionDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/other", p.handleSubmitDialogRequest("writeIn", p.handleWriteIn)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/other/request", p.handlePostActionIntegrationRequest("writeInDialogRequest", p.handleWriteInDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/rank", p.handleSubmitDialogRequest("rankOptions", p.handleRankOptions)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/rank/request", p.handlePostActionIntegrationRequest("rankOptionsDialogRequest", p.handleRankOptionsDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/rate", p.handleSubmitDialogRequest("rateOptions", p.handleRateOptions)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/rate/request", p.handlePostActionIntegrationRequest("rateOptionsDialogRequest", p.handleRateOptionsDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/estimate", p.handleSubmitDialogRequest("estimate", p.handleEstimate)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/estimate/request", p.handlePostActionIntegrationRequest("estimateDialogRequest", p.handleEstimateDialogRequest)).Methods(http.MethodPost)
+	// Posts created before ending and deleting required a confirmation still use the /end and /delete routes
+	pollRouter.HandleFunc("/end", p.handlePostActionIntegrationRequest("endPoll", p.handleEndPoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/pause", p.handlePostActionIntegrationRequest("pausePoll", p.handlePausePoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/resume", p.handlePostActionIntegrationRequest("resumePoll", p.handleResumePoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/end/confirm", p.handleSubmitDialogRequest("confirmEndPoll", p.handleConfirmEndPoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/end/confirm/request", p.handlePostActionIntegrationRequest("confirmEndPollDialogRequest", p.handleConfirmEndPollDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/delete", p.handlePostActionIntegrationRequest("deletePoll", p.handleDeletePoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/delete/confirm", p.handleSubmitDialogRequest("confirmDeletePoll", p.handleConfirmDeletePoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/delete/confirm/request", p.handlePostActionIntegrationRequest("confirmDeletePollDialogRequest", p.handleConfirmDeletePollDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/transfer", p.handleSubmitDialogRequest("transferPoll", p.handleTransferPoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/transfer/request", p.handlePostActionIntegrationRequest("transferPollDialogRequest", p.handleTransferPollDialogRequest)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/export", p.handlePostActionIntegrationRequest("exportPoll", p.handleExportPoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/reopen", p.handlePostActionIntegrationRequest("reopenPoll", p.handleReopenPoll)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/results", p.handlePostActionIntegrationRequest("showResults", p.handleShowResults)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/nonvoters", p.handlePostActionIntegrationRequest("showNonVoters", p.handleShowNonVoters)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/remind", p.handlePostActionIntegrationRequest("remindNonVoters", p.handleRemindNonVoters)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/voters", p.handlePostActionIntegrationRequest("showVoters", p.handleShowVoters)).Methods(http.MethodPost)
+	pollRouter.HandleFunc("/page/{page:[0-9]+}", p.handlePostActionIntegrationRequest("changePage", p.handleChangePage)).Methods(http.MethodPost)
 	return r
 }
 
@@ -117,6 +494,19 @@ func (p *MatterpollPlugin) handleLogo(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, filepath.Join(bundlePath, "assets", iconFilename))
 }
 
+// handleOpenAPI serves the OpenAPI document of the HTTP API. It's public, so integrators can generate clients from it.
+func (p *MatterpollPlugin) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	bundlePath, err := p.API.GetBundlePath()
+	if err != nil {
+		p.API.LogWarn("failed to get bundle path", "error", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeFile(w, r, filepath.Join(bundlePath, "assets", openAPIFilename))
+}
+
 func checkAuthenticity(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("Mattermost-User-ID") == "" {
@@ -128,7 +518,187 @@ func checkAuthenticity(next http.Handler) http.Handler {
 	})
 }
 
-func (p *MatterpollPlugin) handlePostActionIntegrationRequest(handler postActionHandler) http.HandlerFunc {
+// isAPIRequest checks if a request was sent by an external tool or another plugin
+func isAPIRequest(r *http.Request, _ *mux.RouteMatch) bool {
+	return r.Header.Get(apiTokenHeader) != "" || r.Header.Get(pluginIDHeader) != ""
+}
+
+// checkAPIToken ensures that requests of external tools carry the API token from the plugin settings.
+// Requests of trusted plugins don't need the token.
+func (p *MatterpollPlugin) checkAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pluginID := r.Header.Get(pluginIDHeader); pluginID != "" {
+			if !p.getConfiguration().isTrustedPlugin(pluginID) || !p.supportsInterPluginRequests() {
+				http.Error(w, "not authorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := p.getConfiguration().APIToken
+		if token == "" {
+			http.Error(w, "api is disabled", http.StatusForbidden)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(apiTokenHeader)), []byte(token)) != 1 {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleCreatePollRequest creates a poll on behalf of an external tool
+func (p *MatterpollPlugin) handleCreatePollRequest(w http.ResponseWriter, r *http.Request) {
+	var request createPollRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if request.ChannelID == "" || request.Question == "" {
+		http.Error(w, "channel_id and question are required", http.StatusBadRequest)
+		return
+	}
+
+	channel, appErr := p.API.GetChannel(request.ChannelID)
+	if appErr != nil {
+		http.Error(w, "channel not found", http.StatusNotFound)
+		return
+	}
+
+	creatorID := p.botUserID
+	if request.UserID != "" {
+		if _, appErr := p.API.GetUser(request.UserID); appErr != nil {
+			http.Error(w, "user not found", http.StatusBadRequest)
+			return
+		}
+		creatorID = request.UserID
+	}
+
+	publicLocalizer := p.getPublicLocalizer()
+	answerOptions := request.AnswerOptions
+	switch len(answerOptions) {
+	case 0:
+		answerOptions = []string{
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes),
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo),
+		}
+	case 1:
+		http.Error(w, commandErrorinvalidNumberOfOptions.Other, http.StatusBadRequest)
+		return
+	}
+
+	settings := []string{}
+	for _, s := range request.Settings {
+		settings = append(settings, strings.TrimPrefix(s, "--"))
+	}
+
+	configuration := p.getTeamConfiguration(channel.TeamId)
+	newPoll, err := poll.NewPoll(creatorID, request.Question, answerOptions, configuration.applyDefaultSettings(settings))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The same limits apply as for polls created in Mattermost, including the rate limit of the creator
+	reason, err := p.createPoll(newPoll, configuration, channel.TeamId, request.ChannelID, request.RootID)
+	if _, ok := err.(*invalidPollError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		p.API.LogWarn("failed to create poll", "error", err.Error())
+		http.Error(w, "failed to create poll", http.StatusInternalServerError)
+		return
+	}
+	switch reason {
+	case responseCreatePollChannelDisabled:
+		http.Error(w, "polls are disabled in this channel", http.StatusForbidden)
+		return
+	case responseCreatePollRestricted:
+		http.Error(w, "user isn't allowed to create polls in this channel", http.StatusForbidden)
+		return
+	case responseCreatePollRateLimited:
+		http.Error(w, "user created too many polls recently", http.StatusTooManyRequests)
+		return
+	}
+
+	b, _ := json.Marshal(createPollResponse{PollID: newPoll.ID, PostID: newPoll.PostID})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write createPollResponse", "error", err.Error())
+	}
+}
+
+// handleEndPollRequest ends a poll on behalf of an external tool
+func (p *MatterpollPlugin) handleEndPollRequest(w http.ResponseWriter, r *http.Request) {
+	runningPoll, err := p.Store.Poll().Get(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "poll not found", http.StatusNotFound)
+		return
+	}
+	if runningPoll.IsScheduled() {
+		http.Error(w, "poll hasn't been posted yet", http.StatusConflict)
+		return
+	}
+	if runningPoll.IsDeleted() {
+		http.Error(w, "poll has been deleted", http.StatusConflict)
+		return
+	}
+	if runningPoll.IsEnded() {
+		http.Error(w, "poll has already ended", http.StatusConflict)
+		return
+	}
+
+	if err := p.endPoll(runningPoll.ID, ""); err != nil {
+		p.API.LogWarn("failed to end poll", "error", err.Error())
+		http.Error(w, "failed to end poll", http.StatusInternalServerError)
+		return
+	}
+	if err := p.unscheduleEnd(runningPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll end", "error", err.Error())
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMetrics writes the plugin metrics in the Prometheus text format.
+// The metrics are only exposed if they are enabled. If an API token is set, it must be sent as bearer token.
+func (p *MatterpollPlugin) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	configuration := p.getConfiguration()
+	if !configuration.EnableMetrics {
+		http.NotFound(w, r)
+		return
+	}
+	if configuration.APIToken != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+configuration.APIToken)) != 1 {
+		http.Error(w, "not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	polls, err := p.Store.Poll().List()
+	if err != nil {
+		p.API.LogWarn("failed to list polls", "error", err.Error())
+		http.Error(w, "failed to list polls", http.StatusInternalServerError)
+		return
+	}
+	activePolls := 0
+	for _, listedPoll := range polls {
+		if !listedPoll.IsEnded() && !listedPoll.IsScheduled() && !listedPoll.IsDeleted() {
+			activePolls++
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := p.metrics.WriteTo(w, activePolls); err != nil {
+		p.API.LogWarn("failed to write metrics", "error", err.Error())
+	}
+}
+
+// handlePostActionIntegrationRequest decodes the request of a button and passes it to a given handler.
+// name identifies the handler in the metrics.
+func (p *MatterpollPlugin) handlePostActionIntegrationRequest(name string, handler postActionHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		request := model.PostActionIntegrationRequestFromJson(r.Body)
 		if request == nil {
@@ -138,14 +708,29 @@ func (p *MatterpollPlugin) handlePostActionIntegrationRequest(handler postAction
 		}
 		userLocalizer := p.getUserLocalizer(request.UserId)
 
-		msg, update, err := handler(mux.Vars(r), request)
+		vars := mux.Vars(r)
+		msg, update, err := handler(vars, request)
+		p.metrics.ObserveRequest(name, err != nil)
 		if err != nil {
 			p.API.LogWarn("failed to handle PostActionIntegrationRequest", "error", err.Error())
 		}
 
 		response := &model.PostActionIntegrationResponse{}
 		if msg != nil {
-			response.EphemeralText = p.LocalizeDefaultMessage(userLocalizer, msg)
+			response.EphemeralText = p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: msg,
+				TemplateData:   p.responseTemplateData(vars),
+			})
+			// The poll may have scrolled out of sight by the time the user reads the message.
+			// A deleted poll has no post left to link to.
+			if msg != responseDeletePollSuccess && msg != responseDeletePollSuccessRestorable {
+				if link := p.getRequestPermalink(request); link != "" {
+					response.EphemeralText += " " + p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+						DefaultMessage: responsePermalink,
+						TemplateData:   map[string]interface{}{"Link": link},
+					})
+				}
+			}
 		}
 		if update != nil {
 			response.Update = update
@@ -159,7 +744,28 @@ func (p *MatterpollPlugin) handlePostActionIntegrationRequest(handler postAction
 	}
 }
 
-func (p *MatterpollPlugin) handleSubmitDialogRequest(handler submitDialogHandler) http.HandlerFunc {
+// getRequestPermalink returns the permalink to the post a post action was triggered on.
+// It's empty for posts in direct and group messages, because permalinks require a team. Failures are only logged.
+func (p *MatterpollPlugin) getRequestPermalink(request *model.PostActionIntegrationRequest) string {
+	if request.TeamId == "" || request.PostId == "" {
+		return ""
+	}
+	team, appErr := p.API.GetTeam(request.TeamId)
+	if appErr != nil {
+		p.API.LogWarn("failed to get team for permalink", "error", appErr.Error())
+		return ""
+	}
+	return p.makePermalink(team.Name, request.PostId)
+}
+
+// responseTemplateData returns the data that responses to requests about a poll may refer to, e.g. to name a command for the poll
+func (p *MatterpollPlugin) responseTemplateData(vars map[string]string) map[string]interface{} {
+	return map[string]interface{}{"Trigger": p.getConfiguration().Trigger, "ID": vars["id"]}
+}
+
+// handleSubmitDialogRequest decodes the submission of a dialog and passes it to a given handler.
+// name identifies the handler in the metrics.
+func (p *MatterpollPlugin) handleSubmitDialogRequest(name string, handler submitDialogHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		request := model.SubmitDialogRequestFromJson(r.Body)
 		if request == nil {
@@ -168,14 +774,19 @@ func (p *MatterpollPlugin) handleSubmitDialogRequest(handler submitDialogHandler
 			return
 		}
 
-		msg, response, err := handler(mux.Vars(r), request)
+		vars := mux.Vars(r)
+		msg, response, err := handler(vars, request)
+		p.metrics.ObserveRequest(name, err != nil)
 		if err != nil {
 			p.API.LogWarn("failed to handle SubmitDialogRequest", "error", err.Error())
 		}
 
 		if msg != nil {
 			userLocalizer := p.getUserLocalizer(request.UserId)
-			p.SendEphemeralPost(request.ChannelId, request.UserId, p.LocalizeDefaultMessage(userLocalizer, msg))
+			p.SendEphemeralPost(request.ChannelId, request.UserId, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: msg,
+				TemplateData:   p.responseTemplateData(vars),
+			}))
 		}
 
 		if response != nil {
@@ -188,204 +799,1726 @@ func (p *MatterpollPlugin) handleSubmitDialogRequest(handler submitDialogHandler
 	}
 }
 
-func (p *MatterpollPlugin) handleVote(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
-	pollID := vars["id"]
-	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
-	userID := request.UserId
+func (p *MatterpollPlugin) handleCreatePoll(_ map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	userLocalizer := p.getUserLocalizer(request.UserId)
+	publicLocalizer := p.getPublicLocalizer()
 
-	poll, err := p.Store.Poll().Get(pollID)
-	if err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
-	}
+	question, _ := request.Submission[createPollQuestionKey].(string)
+	options, _ := request.Submission[createPollOptionsKey].(string)
+	voteMode, _ := request.Submission[createPollVoteModeKey].(string)
+	settingsText, _ := request.Submission[createPollSettingsKey].(string)
 
-	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
-	if appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	settings := []string{}
+	for _, s := range strings.Fields(settingsText) {
+		settings = append(settings, strings.TrimPrefix(s, "--"))
 	}
-
-	hasVoted := poll.HasVoted(userID)
-	if err = poll.UpdateVote(userID, optionNumber); err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	if voteMode != "" {
+		settings = append(settings, "votemode="+voteMode)
 	}
+	configuration := p.getTeamConfiguration(request.TeamId)
+	settings = configuration.applyDefaultSettings(settings)
 
-	if err = p.Store.Poll().Save(poll); err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to save poll")
+	answerOptions := []string{}
+	for _, o := range strings.Split(options, "\n") {
+		if o = strings.TrimSpace(o); o != "" {
+			answerOptions = append(answerOptions, o)
+		}
 	}
-
-	post := &model.Post{}
-	publicLocalizer := p.getServerLocalizer()
-	model.ParseSlackAttachment(post, poll.ToPostActions(publicLocalizer, *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName))
-
-	if hasVoted {
-		return responseVoteUpdated, post, nil
+	switch {
+	case len(answerOptions) == 0 && poll.NeedsAnswerOptions(settings):
+		answerOptions = []string{
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes),
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo),
+		}
+	case len(answerOptions) == 1:
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				createPollOptionsKey: p.LocalizeDefaultMessage(userLocalizer, commandErrorinvalidNumberOfOptions),
+			},
+		}
+		return nil, response, nil
 	}
-	return responseVoteCounted, post, nil
-}
 
-func (p *MatterpollPlugin) handleAddOption(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
-	pollID := vars["id"]
+	// Validate the answer options on their own to show errors next to the matching element
+	if _, err := poll.NewPoll(request.UserId, question, answerOptions, nil); err != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				createPollOptionsKey: err.Error(),
+			},
+		}
+		return nil, response, nil
+	}
 
-	poll, err := p.Store.Poll().Get(pollID)
+	newPoll, err := poll.NewPoll(request.UserId, question, answerOptions, settings)
 	if err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				createPollSettingsKey: p.localizeError(userLocalizer, err),
+			},
+		}
+		return nil, response, nil
 	}
-
-	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
-	if appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	if err = configuration.checkQuestionLength(newPoll.Question); err == nil {
+		newPoll.Question, err = configuration.filterBlockedWords(newPoll.Question)
 	}
-
-	post, appErr := p.API.GetPost(request.CallbackId)
-	if appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	if err != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				createPollQuestionKey: p.localizeError(userLocalizer, err),
+			},
+		}
+		return nil, response, nil
 	}
-
-	answerOption, ok := request.Submission[addOptionKey].(string)
-	if !ok {
-		return commandErrorGeneric, nil, errors.Wrapf(appErr, "failed to get submission key %s", addOptionKey)
+	if err = configuration.checkNumberOfAnswerOptions(len(newPoll.AnswerOptions)); err == nil {
+		err = configuration.checkAnswerOptionLengths(newPoll.AnswerOptions)
 	}
-
-	if err = poll.AddAnswerOption(answerOption); err != nil {
+	if err == nil {
+		err = configuration.filterBlockedAnswerOptions(newPoll.AnswerOptions)
+	}
+	if err != nil {
 		response := &model.SubmitDialogResponse{
 			Errors: map[string]string{
-				addOptionKey: err.Error(),
+				createPollOptionsKey: p.localizeError(userLocalizer, err),
 			},
 		}
 		return nil, response, nil
 	}
 
-	publicLocalizer := p.getServerLocalizer()
-	model.ParseSlackAttachment(post, poll.ToPostActions(publicLocalizer, *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName))
-	if _, appErr = p.API.UpdatePost(post); appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	// The question and answer options are valid at this point, so the remaining errors are caused by the settings
+	reason, err := p.createPoll(newPoll, configuration, request.TeamId, request.ChannelId, request.CallbackId)
+	if invalidErr, ok := err.(*invalidPollError); ok {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				createPollSettingsKey: p.localizeError(userLocalizer, invalidErr.err),
+			},
+		}
+		return nil, response, nil
 	}
-
-	if err = p.Store.Poll().Save(poll); err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get save poll")
-
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to create poll")
 	}
+	if reason != nil {
+		return reason, nil, nil
+	}
+	if newPoll.IsScheduled() {
+		return responseCreatePollScheduled, nil, nil
+	}
+	return nil, nil, nil
+}
+
+// withSelectedOption passes the answer option picked in the select menu of a compact poll to a given handler, as if its button was clicked
+func withSelectedOption(handler postActionHandler) postActionHandler {
+	return func(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+		selected, _ := request.Context["selected_option"].(string)
+		if _, err := strconv.Atoi(selected); err != nil {
+			return commandErrorGeneric, nil, errors.Errorf("invalid selected option %q", selected)
+		}
+		vars["optionNumber"] = selected
+		return handler(vars, request)
+	}
+}
+
+func (p *MatterpollPlugin) handleVote(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
+
+	msg, attachments, err := p.vote(vars["id"], request.ChannelId, request.UserId, optionNumber)
+	if attachments == nil {
+		return msg, nil, err
+	}
+
+	post := &model.Post{}
+	model.ParseSlackAttachment(post, attachments)
+	return msg, post, err
+}
+
+func (p *MatterpollPlugin) handleConfirmVote(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
+
+	msg, attachments, err := p.vote(vars["id"], request.ChannelId, request.UserId, optionNumber)
+	if attachments == nil {
+		return msg, nil, err
+	}
+
+	post, appErr := p.API.GetPost(request.CallbackId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+	model.ParseSlackAttachment(post, attachments)
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	return msg, nil, nil
+}
+
+// handleResetVote removes all votes of the user who pressed the button, so the user abstains again
+func (p *MatterpollPlugin) handleResetVote(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	userID := request.UserId
+
+	var ended, paused, notVoted, locked bool
+	resetPoll, err := p.Store.Poll().Update(vars["id"], func(latest *poll.Poll) error {
+		if ended = latest.IsEnded() || latest.IsDeleted() || latest.IsPastDeadline(); ended {
+			return errors.New("poll has already ended")
+		}
+		if paused = latest.IsPaused(); paused {
+			return errors.New("voting is paused")
+		}
+		if notVoted = !latest.HasVoted(userID); notVoted {
+			return errors.New("user hasn't voted")
+		}
+		if locked = latest.IsVoteLocked(userID); locked {
+			return errors.New("vote is locked")
+		}
+		return latest.ResetVote(userID)
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if paused {
+		return responseVotePaused, nil, nil
+	}
+	if notVoted {
+		return responseResetVoteNotVoted, nil, nil
+	}
+	if locked {
+		return responseVoteLocked, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+	p.publishPollEvent(websocketEventPollUpdated, resetPoll)
+	p.updateCrossPosts(resetPoll)
+	p.notifyWebhook(webhookEventVoteCast, resetPoll, userID)
+	p.recordAudit(audit.ActionVoteChanged, resetPoll, userID, "")
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(resetPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	attachments, appErr := p.makePollAttachments(resetPoll, displayName)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get poll attachments")
+	}
+
+	post := &model.Post{}
+	model.ParseSlackAttachment(post, attachments)
+	return responseResetVoteSuccess, post, nil
+}
+
+// vote casts the vote of a user for the answer option with a given index in a given channel, which may be the channel of a cross-post.
+// It returns the message for the user and the updated poll attachments, which are nil if the vote wasn't cast or the post update is debounced.
+func (p *MatterpollPlugin) vote(pollID, channelID, userID string, optionNumber int) (*i18n.Message, []*model.SlackAttachment, error) {
+	// Apply the vote to the latest version of the poll, so simultaneous votes don't get lost
+	// Checking the vote limit on the latest version also enforces it for votes cast in rapid succession
+	var hasVoted, ended, locked, delegated, limitReached, optionFull bool
+	var rejection *i18n.Message
+	var receipt string
+	votedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		// The deadline may have passed before the job that ends the poll has run
+		if ended = latest.IsEnded() || latest.IsDeleted() || latest.IsPastDeadline(); ended {
+			return errors.New("poll has already ended")
+		}
+		reason, err := p.checkVoter(latest, userID)
+		if err != nil {
+			return err
+		}
+		if rejection = reason; rejection != nil {
+			return errors.New("user isn't allowed to vote")
+		}
+		if locked = latest.IsVoteLocked(userID); locked {
+			return errors.New("vote is locked")
+		}
+		if delegated = latest.HasDelegated(userID); delegated {
+			return errors.New("vote is delegated")
+		}
+		if limitReached = latest.IsVoteLimitReached(userID, optionNumber); limitReached {
+			return errors.New("vote limit reached")
+		}
+		if optionFull = latest.IsOptionLimitReached(userID, optionNumber); optionFull {
+			return errors.New("answer option is full")
+		}
+		hasVoted = latest.HasVoted(userID)
+		if latest.Settings.Receipts {
+			receipt, err = latest.CastBallot(userID, optionNumber)
+			return err
+		}
+		return latest.UpdateVote(userID, optionNumber)
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if locked {
+		return responseVoteLocked, nil, nil
+	}
+	if delegated {
+		return responseVoteDelegated, nil, nil
+	}
+	if limitReached {
+		return responseVoteLimitReached, nil, nil
+	}
+	if optionFull {
+		return responseVoteOptionFull, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+	p.metrics.IncVotesCast()
+	p.publishPollEvent(websocketEventPollUpdated, votedPoll)
+	updatePost := p.debouncePostUpdate(votedPoll)
+	p.notifyWebhook(webhookEventVoteCast, votedPoll, userID)
+	p.recordAudit(voteAuditAction(hasVoted), votedPoll, userID, votedAnswers(votedPoll, userID))
+	p.recordVotedPoll(votedPoll, userID)
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(votedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	attachments, appErr := p.makePollAttachments(votedPoll, displayName)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get poll attachments")
+	}
+
+	msg := responseVoteCounted
+	if votedPoll.Settings.Receipts {
+		p.sendVoteReceipt(votedPoll, userID, receipt)
+		// Approval polls and polls with multiple votes toggle the vote for an option
+	} else if !votedPoll.HasVotedFor(userID, optionNumber) {
+		msg = responseVoteRemoved
+	} else if hasVoted {
+		msg = responseVoteUpdated
+	}
+	msg = p.confirmVote(votedPoll, channelID, userID, msg)
+
+	if p.endPollIfAllVoted(votedPoll) {
+		// The poll post already shows the results
+		return msg, nil, nil
+	}
+	p.notifyIfThresholdReached(votedPoll)
+	p.sendResultsIfVoteToSee(votedPoll, userID)
+	if !updatePost {
+		attachments = nil
+	}
+	return msg, attachments, nil
+}
+
+// confirmVote returns the response to a vote of a given user in a given channel. The response can't carry template data,
+// so if the vote counter of a poll with multiple votes or the user's own vote is added, it's sent as ephemeral post to that channel and nil is returned.
+func (p *MatterpollPlugin) confirmVote(votedPoll *poll.Poll, channelID, userID string, msg *i18n.Message) *i18n.Message {
+	showOwnVote := p.getConfiguration().ShowOwnVote
+	if !votedPoll.IsMultiVote() && !showOwnVote {
+		return msg
+	}
+
+	userLocalizer := p.getUserLocalizer(userID)
+	details := []string{}
+	if votedPoll.IsMultiVote() {
+		details = append(details, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: responseVoteVotesUsed,
+			TemplateData: map[string]interface{}{
+				"Used": votedPoll.NumberOfVotes(userID),
+				"Max":  votedPoll.Settings.MaxVotes,
+			},
+		}))
+	}
+	if showOwnVote {
+		if ownVote := p.makeOwnVoteText(votedPoll, userID, userLocalizer); ownVote != "" {
+			details = append(details, ownVote)
+		}
+	}
+	if len(details) == 0 {
+		return msg
+	}
+	p.SendEphemeralPost(channelID, userID, strings.Join(append([]string{p.LocalizeDefaultMessage(userLocalizer, msg)}, details...), " "))
+	return nil
+}
+
+func (p *MatterpollPlugin) handleSurveyVote(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	questionNumber, _ := strconv.Atoi(vars["questionNumber"])
+	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
+	userID := request.UserId
+
+	var hasAnswered, ended bool
+	var rejection *i18n.Message
+	votedPoll, err := p.Store.Poll().Update(vars["id"], func(latest *poll.Poll) error {
+		if ended = latest.IsEnded() || latest.IsDeleted() || latest.IsPastDeadline(); ended {
+			return errors.New("poll has already ended")
+		}
+		reason, err := p.checkVoter(latest, userID)
+		if err != nil {
+			return err
+		}
+		if rejection = reason; rejection != nil {
+			return errors.New("user isn't allowed to vote")
+		}
+		hasAnswered = latest.HasAnswered(userID, questionNumber)
+		return latest.UpdateSurveyVote(userID, questionNumber, optionNumber)
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+	p.metrics.IncVotesCast()
+	p.publishPollEvent(websocketEventPollUpdated, votedPoll)
+	updatePost := p.debouncePostUpdate(votedPoll)
+	p.notifyWebhook(webhookEventVoteCast, votedPoll, userID)
+	question := votedPoll.Questions[questionNumber]
+	p.recordAudit(voteAuditAction(hasAnswered), votedPoll, userID, question.Question+": "+question.AnswerOptions[optionNumber].Answer)
+	p.recordVotedPoll(votedPoll, userID)
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(votedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	msg := responseVoteCounted
+	if hasAnswered {
+		msg = responseVoteUpdated
+	}
+	msg = p.confirmVote(votedPoll, request.ChannelId, userID, msg)
+	if p.endPollIfAllVoted(votedPoll) {
+		// The survey post already shows the results
+		return msg, nil, nil
+	}
+	p.notifyIfThresholdReached(votedPoll)
+	p.sendResultsIfVoteToSee(votedPoll, userID)
+	if !updatePost {
+		return msg, nil, nil
+	}
+
+	post := &model.Post{}
+	model.ParseSlackAttachment(post, votedPoll.ToPostActions(p.getPublicLocalizer(), *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName))
+	return msg, post, nil
+}
+
+func (p *MatterpollPlugin) handleConfirmVoteDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+	optionNumber, _ := strconv.Atoi(vars["optionNumber"])
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+	if currentPoll.IsEnded() || currentPoll.IsDeleted() || currentPoll.IsPastDeadline() {
+		return responseVotePollEnded, nil, nil
+	}
+	rejection, err := p.checkVoter(currentPoll, request.UserId)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if currentPoll.IsVoteLocked(request.UserId) {
+		return responseVoteLocked, nil, nil
+	}
+	if currentPoll.HasDelegated(request.UserId) {
+		return responseVoteDelegated, nil, nil
+	}
+	if optionNumber >= len(currentPoll.AnswerOptions) {
+		return commandErrorGeneric, nil, errors.Errorf("invalid option number %d", optionNumber)
+	}
+	// Don't let the user confirm a vote that would be rejected anyway
+	if currentPoll.IsOptionLimitReached(request.UserId, optionNumber) {
+		return responseVoteOptionFull, nil, nil
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/vote/%d/confirm", siteURL, manifest.ID, pollID, optionNumber),
+		Dialog: model.Dialog{
+			Title: p.LocalizeDefaultMessage(userLocalizer, dialogConfirmVoteTitle),
+			IntroductionText: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: dialogConfirmVoteIntroductionText,
+				TemplateData:   map[string]interface{}{"Answer": currentPoll.AnswerOptions[optionNumber].Answer},
+			}),
+			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:  request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogConfirmVoteSubmitLabel),
+		},
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open confirm vote dialog")
+	}
+	return nil, nil, nil
+}
+
+func (p *MatterpollPlugin) handleAddOption(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	pollID := vars["id"]
+
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	// The dialog might have been submitted by someone who isn't allowed to open it
+	if !currentPoll.Settings.PublicAddOption {
+		hasPermission, appErr := p.HasPermission(currentPoll, request.UserId)
+		if appErr != nil {
+			return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+		}
+		if !hasPermission {
+			return responseAddOptionInvalidPermission, nil, nil
+		}
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(currentPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	post, appErr := p.API.GetPost(request.CallbackId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+
+	answerOption, ok := request.Submission[addOptionKey].(string)
+	if !ok {
+		return commandErrorGeneric, nil, errors.Errorf("failed to get submission key %s", addOptionKey)
+	}
+
+	configuration := p.getConfiguration()
+	answerOption, optionErr := configuration.filterBlockedWords(answerOption)
+	if optionErr != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				addOptionKey: p.localizeError(p.getUserLocalizer(request.UserId), optionErr),
+			},
+		}
+		return nil, response, nil
+	}
+
+	// Apply the change to the latest version of the poll, so concurrent votes or options don't get lost
+	updatedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if optionErr = latest.AddAnswerOption(answerOption); optionErr != nil {
+			return optionErr
+		}
+		if optionErr = configuration.checkNumberOfAnswerOptions(len(latest.AnswerOptions)); optionErr != nil {
+			return optionErr
+		}
+		optionErr = configuration.checkAnswerOptionLengths(latest.AnswerOptions[len(latest.AnswerOptions)-1:])
+		return optionErr
+	})
+	if optionErr != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				addOptionKey: p.localizeError(p.getUserLocalizer(request.UserId), optionErr),
+			},
+		}
+		return nil, response, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to save poll")
+	}
+	p.publishPollEvent(websocketEventPollUpdated, updatedPoll)
+	p.updateCrossPosts(updatedPoll)
+	p.recordAudit(audit.ActionOptionAdded, updatedPoll, request.UserId, answerOption)
+
+	attachments, appErr := p.makePollAttachments(updatedPoll, displayName)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get poll attachments")
+	}
+	model.ParseSlackAttachment(post, attachments)
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+
+	return responseAddOptionSuccess, nil, nil
+}
+
+func (p *MatterpollPlugin) handleAddOptionDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	poll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	if !poll.Settings.PublicAddOption {
+		hasPermission, appErr := p.HasPermission(poll, request.UserId)
+		if appErr != nil {
+			return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+		}
+		if !hasPermission {
+			return responseAddOptionInvalidPermission, nil, nil
+		}
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/option/add", siteURL, manifest.ID, pollID),
+		Dialog: model.Dialog{
+			Title:       p.LocalizeDefaultMessage(userLocalizer, dialogAddOptionTitle),
+			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:  request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogAddOptionSubmitLabel),
+			Elements: []model.DialogElement{{
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogAddOptionElementDisplayName),
+				Name:        addOptionKey,
+				Type:        "text",
+				SubType:     "text",
+				HelpText:    p.LocalizeDefaultMessage(userLocalizer, dialogAddOptionElementHelpText),
+			}},
+		},
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open add option dialog")
+	}
+	return nil, nil, nil
+}
+
+func (p *MatterpollPlugin) handleWriteIn(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	answer, ok := request.Submission[writeInKey].(string)
+	if !ok {
+		return commandErrorGeneric, nil, errors.Errorf("failed to get submission key %s", writeInKey)
+	}
+	answer, err := p.getConfiguration().filterBlockedWords(answer)
+	if err != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				writeInKey: p.localizeError(p.getUserLocalizer(request.UserId), err),
+			},
+		}
+		return nil, response, nil
+	}
+
+	// Apply the write-in to the latest version of the poll, so simultaneous votes don't get lost
+	var hasVoted, ended, locked, delegated bool
+	var rejection *i18n.Message
+	var writeInErr error
+	votedPoll, err := p.Store.Poll().Update(vars["id"], func(latest *poll.Poll) error {
+		if ended = latest.IsEnded() || latest.IsDeleted() || latest.IsPastDeadline(); ended {
+			return errors.New("poll has already ended")
+		}
+		reason, err := p.checkVoter(latest, request.UserId)
+		if err != nil {
+			return err
+		}
+		if rejection = reason; rejection != nil {
+			return errors.New("user isn't allowed to vote")
+		}
+		if locked = latest.IsVoteLocked(request.UserId); locked {
+			return errors.New("vote is locked")
+		}
+		if delegated = latest.HasDelegated(request.UserId); delegated {
+			return errors.New("vote is delegated")
+		}
+		hasVoted = latest.HasVoted(request.UserId)
+		writeInErr = latest.UpdateWriteIn(request.UserId, answer)
+		return writeInErr
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if locked {
+		return responseVoteLocked, nil, nil
+	}
+	if delegated {
+		return responseVoteDelegated, nil, nil
+	}
+	if writeInErr != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				writeInKey: writeInErr.Error(),
+			},
+		}
+		return nil, response, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update poll")
+	}
+	p.metrics.IncVotesCast()
+	p.publishPollEvent(websocketEventPollUpdated, votedPoll)
+	updatePost := p.debouncePostUpdate(votedPoll)
+	p.notifyWebhook(webhookEventVoteCast, votedPoll, request.UserId)
+	p.recordAudit(voteAuditAction(hasVoted), votedPoll, request.UserId, answer)
+	p.recordVotedPoll(votedPoll, request.UserId)
+
+	msg := responseVoteCounted
+	if hasVoted {
+		msg = responseVoteUpdated
+	}
+	msg = p.confirmVote(votedPoll, request.ChannelId, request.UserId, msg)
+	if p.endPollIfAllVoted(votedPoll) {
+		// The poll post already shows the results
+		return msg, nil, nil
+	}
+	p.notifyIfThresholdReached(votedPoll)
+	p.sendResultsIfVoteToSee(votedPoll, request.UserId)
+	if !updatePost {
+		return msg, nil, nil
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(votedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+	post, appErr := p.API.GetPost(request.CallbackId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+	attachments, appErr := p.makePollAttachments(votedPoll, displayName)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get poll attachments")
+	}
+	model.ParseSlackAttachment(post, attachments)
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	return msg, nil, nil
+}
+
+func (p *MatterpollPlugin) handleWriteInDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+	if currentPoll.IsEnded() || currentPoll.IsDeleted() || currentPoll.IsPastDeadline() {
+		return responseVotePollEnded, nil, nil
+	}
+	rejection, err := p.checkVoter(currentPoll, request.UserId)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if currentPoll.IsVoteLocked(request.UserId) {
+		return responseVoteLocked, nil, nil
+	}
+	if currentPoll.HasDelegated(request.UserId) {
+		return responseVoteDelegated, nil, nil
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/other", siteURL, manifest.ID, pollID),
+		Dialog: model.Dialog{
+			Title:       p.LocalizeDefaultMessage(userLocalizer, dialogWriteInTitle),
+			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:  request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogWriteInSubmitLabel),
+			Elements: []model.DialogElement{{
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogWriteInElementDisplayName),
+				Name:        writeInKey,
+				Type:        "text",
+				SubType:     "text",
+				Default:     currentPoll.WriteInOf(request.UserId),
+				MaxLength:   poll.WriteInMaxLength,
+				HelpText:    p.LocalizeDefaultMessage(userLocalizer, dialogWriteInElementHelpText),
+			}},
+		},
+	}
+	if currentPoll.Settings.LockVotes {
+		dialog.Dialog.IntroductionText = p.LocalizeDefaultMessage(userLocalizer, dialogWriteInIntroductionTextLocked)
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open write-in dialog")
+	}
+	return nil, nil, nil
+}
+
+func (p *MatterpollPlugin) handleRankOptions(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	rankedPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(rankedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	post, appErr := p.API.GetPost(request.CallbackId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+
+	ranking := []int{}
+	ranked := map[int]bool{}
+	submissionErrors := map[string]string{}
+	for i := range rankedPoll.AnswerOptions {
+		key := fmt.Sprintf("%s%d", rankOptionKeyPrefix, i)
+		value, ok := request.Submission[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+
+		index, err := strconv.Atoi(value)
+		if err != nil {
+			return commandErrorGeneric, nil, errors.Wrapf(err, "failed to parse submission key %s", key)
+		}
+		if ranked[index] {
+			submissionErrors[key] = p.LocalizeDefaultMessage(userLocalizer, dialogRankOptionsErrorDuplicate)
+			continue
+		}
+		ranked[index] = true
+		ranking = append(ranking, index)
+	}
+	if len(submissionErrors) > 0 {
+		return nil, &model.SubmitDialogResponse{Errors: submissionErrors}, nil
+	}
+
+	// Apply the ranking to the latest version of the poll, so simultaneous votes don't get lost
+	var hasVoted, ended, locked bool
+	var rejection *i18n.Message
+	updatedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if ended = latest.IsEnded() || latest.IsDeleted() || latest.IsPastDeadline(); ended {
+			return errors.New("poll has already ended")
+		}
+		reason, err := p.checkVoter(latest, request.UserId)
+		if err != nil {
+			return err
+		}
+		if rejection = reason; rejection != nil {
+			return errors.New("user isn't allowed to vote")
+		}
+		if locked = latest.IsVoteLocked(request.UserId); locked {
+			return errors.New("vote is locked")
+		}
+		hasVoted = latest.HasVoted(request.UserId)
+		return latest.UpdateRanking(request.UserId, ranking)
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if locked {
+		return responseVoteLocked, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update ranking")
+	}
+	p.metrics.IncVotesCast()
+	p.publishPollEvent(websocketEventPollUpdated, updatedPoll)
+	updatePost := p.debouncePostUpdate(updatedPoll)
+	p.notifyWebhook(webhookEventVoteCast, updatedPoll, request.UserId)
+	p.recordAudit(voteAuditAction(hasVoted), updatedPoll, request.UserId, rankedAnswers(updatedPoll, ranking))
+	p.recordVotedPoll(updatedPoll, request.UserId)
+
+	msg := responseVoteCounted
+	if hasVoted {
+		msg = responseVoteUpdated
+	}
+	msg = p.confirmVote(updatedPoll, request.ChannelId, request.UserId, msg)
+	if p.endPollIfAllVoted(updatedPoll) {
+		// The poll post already shows the results
+		return msg, nil, nil
+	}
+	p.notifyIfThresholdReached(updatedPoll)
+	p.sendResultsIfVoteToSee(updatedPoll, request.UserId)
+	if !updatePost {
+		return msg, nil, nil
+	}
+
+	publicLocalizer := p.getPublicLocalizer()
+	model.ParseSlackAttachment(post, updatedPoll.ToPostActions(publicLocalizer, *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName))
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	return msg, nil, nil
+}
+
+func (p *MatterpollPlugin) handleRankOptionsDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	poll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+	rejection, err := p.checkVoter(poll, request.UserId)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if poll.IsVoteLocked(request.UserId) {
+		return responseVoteLocked, nil, nil
+	}
+
+	options := []*model.PostActionOptions{}
+	for _, i := range poll.DisplayOrder() {
+		options = append(options, &model.PostActionOptions{
+			Text:  poll.AnswerOptions[i].Answer,
+			Value: strconv.Itoa(i),
+		})
+	}
+
+	ranking := poll.Rankings[request.UserId]
+	elements := []model.DialogElement{}
+	for i := range poll.AnswerOptions {
+		element := model.DialogElement{
+			DisplayName: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: dialogRankOptionsElementDisplayName,
+				TemplateData:   map[string]interface{}{"Rank": i + 1},
+			}),
+			Name:     fmt.Sprintf("%s%d", rankOptionKeyPrefix, i),
+			Type:     "select",
+			Options:  options,
+			Optional: i != 0,
+		}
+		if i < len(ranking) {
+			element.Default = strconv.Itoa(ranking[i])
+		}
+		elements = append(elements, element)
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/rank", siteURL, manifest.ID, pollID),
+		Dialog: model.Dialog{
+			Title:       p.LocalizeDefaultMessage(userLocalizer, dialogRankOptionsTitle),
+			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:  request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogRankOptionsSubmitLabel),
+			Elements:    elements,
+		},
+	}
+
+	if poll.Settings.LockVotes {
+		dialog.Dialog.IntroductionText = p.LocalizeDefaultMessage(userLocalizer, dialogRankOptionsIntroductionTextLocked)
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open rank options dialog")
+	}
+	return nil, nil, nil
+}
+
+func (p *MatterpollPlugin) handleRateOptions(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	ratedPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(ratedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	post, appErr := p.API.GetPost(request.CallbackId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+
+	scores := make([]int, len(ratedPoll.AnswerOptions))
+	rated := false
+	for i := range ratedPoll.AnswerOptions {
+		key := fmt.Sprintf("%s%d", rateOptionKeyPrefix, i)
+		value, ok := request.Submission[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+
+		score, err := strconv.Atoi(value)
+		if err != nil {
+			return commandErrorGeneric, nil, errors.Wrapf(err, "failed to parse submission key %s", key)
+		}
+		scores[i] = score
+		rated = true
+	}
+	if !rated {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				fmt.Sprintf("%s%d", rateOptionKeyPrefix, ratedPoll.DisplayOrder()[0]): p.LocalizeDefaultMessage(userLocalizer, dialogRateOptionsErrorEmpty),
+			},
+		}
+		return nil, response, nil
+	}
+
+	// Apply the ratings to the latest version of the poll, so simultaneous votes don't get lost
+	var hasVoted, ended, locked bool
+	var rejection *i18n.Message
+	updatedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if ended = latest.IsEnded() || latest.IsDeleted() || latest.IsPastDeadline(); ended {
+			return errors.New("poll has already ended")
+		}
+		reason, err := p.checkVoter(latest, request.UserId)
+		if err != nil {
+			return err
+		}
+		if rejection = reason; rejection != nil {
+			return errors.New("user isn't allowed to vote")
+		}
+		if locked = latest.IsVoteLocked(request.UserId); locked {
+			return errors.New("vote is locked")
+		}
+		hasVoted = latest.HasVoted(request.UserId)
+		return latest.UpdateRating(request.UserId, scores)
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if locked {
+		return responseVoteLocked, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update ratings")
+	}
+	p.metrics.IncVotesCast()
+	p.publishPollEvent(websocketEventPollUpdated, updatedPoll)
+	updatePost := p.debouncePostUpdate(updatedPoll)
+	p.notifyWebhook(webhookEventVoteCast, updatedPoll, request.UserId)
+	p.recordAudit(voteAuditAction(hasVoted), updatedPoll, request.UserId, ratedAnswers(updatedPoll, scores))
+	p.recordVotedPoll(updatedPoll, request.UserId)
+
+	msg := responseVoteCounted
+	if hasVoted {
+		msg = responseVoteUpdated
+	}
+	msg = p.confirmVote(updatedPoll, request.ChannelId, request.UserId, msg)
+	if p.endPollIfAllVoted(updatedPoll) {
+		// The poll post already shows the results
+		return msg, nil, nil
+	}
+	p.notifyIfThresholdReached(updatedPoll)
+	p.sendResultsIfVoteToSee(updatedPoll, request.UserId)
+	if !updatePost {
+		return msg, nil, nil
+	}
+
+	publicLocalizer := p.getPublicLocalizer()
+	model.ParseSlackAttachment(post, updatedPoll.ToPostActions(publicLocalizer, *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName))
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	return msg, nil, nil
+}
+
+func (p *MatterpollPlugin) handleRateOptionsDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	ratedPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+	rejection, err := p.checkVoter(ratedPoll, request.UserId)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if ratedPoll.IsVoteLocked(request.UserId) {
+		return responseVoteLocked, nil, nil
+	}
+
+	options := []*model.PostActionOptions{}
+	for score := poll.RatingMaxScore; score > 0; score-- {
+		options = append(options, &model.PostActionOptions{
+			Text:  strings.Repeat("★", score),
+			Value: strconv.Itoa(score),
+		})
+	}
+
+	scores := ratedPoll.Ratings[request.UserId]
+	elements := []model.DialogElement{}
+	for _, i := range ratedPoll.DisplayOrder() {
+		element := model.DialogElement{
+			DisplayName: ratedPoll.AnswerOptions[i].Answer,
+			Name:        fmt.Sprintf("%s%d", rateOptionKeyPrefix, i),
+			Type:        "select",
+			Options:     options,
+			Optional:    true,
+		}
+		if i < len(scores) && scores[i] > 0 {
+			element.Default = strconv.Itoa(scores[i])
+		}
+		elements = append(elements, element)
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/rate", siteURL, manifest.ID, pollID),
+		Dialog: model.Dialog{
+			Title:            p.LocalizeDefaultMessage(userLocalizer, dialogRateOptionsTitle),
+			IconURL:          fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:       request.PostId,
+			SubmitLabel:      p.LocalizeDefaultMessage(userLocalizer, dialogRateOptionsSubmitLabel),
+			IntroductionText: p.LocalizeDefaultMessage(userLocalizer, dialogRateOptionsIntroductionText),
+			Elements:         elements,
+		},
+	}
+
+	if ratedPoll.Settings.LockVotes {
+		dialog.Dialog.IntroductionText += " " + p.LocalizeDefaultMessage(userLocalizer, dialogRateOptionsIntroductionTextLocked)
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open rate options dialog")
+	}
+	return nil, nil, nil
+}
+
+func (p *MatterpollPlugin) handleEstimate(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	value, ok := request.Submission[estimateKey].(string)
+	if !ok {
+		return commandErrorGeneric, nil, errors.Errorf("failed to get submission key %s", estimateKey)
+	}
+	estimate, err := poll.ParseEstimate(value)
+	if err != nil {
+		response := &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				estimateKey: err.Error(),
+			},
+		}
+		return nil, response, nil
+	}
+
+	// Apply the estimate to the latest version of the poll, so simultaneous votes don't get lost
+	var hasVoted, ended, locked bool
+	var rejection *i18n.Message
+	estimatedPoll, err := p.Store.Poll().Update(vars["id"], func(latest *poll.Poll) error {
+		if ended = latest.IsEnded() || latest.IsDeleted() || latest.IsPastDeadline(); ended {
+			return errors.New("poll has already ended")
+		}
+		reason, err := p.checkVoter(latest, request.UserId)
+		if err != nil {
+			return err
+		}
+		if rejection = reason; rejection != nil {
+			return errors.New("user isn't allowed to vote")
+		}
+		if locked = latest.IsVoteLocked(request.UserId); locked {
+			return errors.New("vote is locked")
+		}
+		hasVoted = latest.HasVoted(request.UserId)
+		return latest.UpdateEstimate(request.UserId, estimate)
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if locked {
+		return responseVoteLocked, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to update estimate")
+	}
+	p.metrics.IncVotesCast()
+	p.publishPollEvent(websocketEventPollUpdated, estimatedPoll)
+	updatePost := p.debouncePostUpdate(estimatedPoll)
+	p.notifyWebhook(webhookEventVoteCast, estimatedPoll, request.UserId)
+	p.recordAudit(voteAuditAction(hasVoted), estimatedPoll, request.UserId, estimatedPoll.EstimateOf(request.UserId))
+	p.recordVotedPoll(estimatedPoll, request.UserId)
+
+	msg := responseVoteCounted
+	if hasVoted {
+		msg = responseVoteUpdated
+	}
+	msg = p.confirmVote(estimatedPoll, request.ChannelId, request.UserId, msg)
+	if p.endPollIfAllVoted(estimatedPoll) {
+		// The poll post already shows the results
+		return msg, nil, nil
+	}
+	p.notifyIfThresholdReached(estimatedPoll)
+	p.sendResultsIfVoteToSee(estimatedPoll, request.UserId)
+	if !updatePost {
+		return msg, nil, nil
+	}
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(estimatedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+	post, appErr := p.API.GetPost(request.CallbackId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get post")
+	}
+	attachments, appErr := p.makePollAttachments(estimatedPoll, displayName)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get poll attachments")
+	}
+	model.ParseSlackAttachment(post, attachments)
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+	}
+	return msg, nil, nil
+}
+
+func (p *MatterpollPlugin) handleEstimateDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+	if currentPoll.IsEnded() || currentPoll.IsDeleted() || currentPoll.IsPastDeadline() {
+		return responseVotePollEnded, nil, nil
+	}
+	rejection, err := p.checkVoter(currentPoll, request.UserId)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	if rejection != nil {
+		return rejection, nil, nil
+	}
+	if currentPoll.IsVoteLocked(request.UserId) {
+		return responseVoteLocked, nil, nil
+	}
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/estimate", siteURL, manifest.ID, pollID),
+		Dialog: model.Dialog{
+			Title:       p.LocalizeDefaultMessage(userLocalizer, dialogEstimateTitle),
+			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:  request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogEstimateSubmitLabel),
+			Elements: []model.DialogElement{{
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogEstimateElementDisplayName),
+				Name:        estimateKey,
+				Type:        "text",
+				SubType:     "number",
+				Default:     currentPoll.EstimateOf(request.UserId),
+				HelpText:    p.LocalizeDefaultMessage(userLocalizer, dialogEstimateElementHelpText),
+			}},
+		},
+	}
+	if currentPoll.Settings.LockVotes {
+		dialog.Dialog.IntroductionText = p.LocalizeDefaultMessage(userLocalizer, dialogEstimateIntroductionTextLocked)
+	}
+
+	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open estimate dialog")
+	}
+	return nil, nil, nil
+}
+
+func (p *MatterpollPlugin) handleEndPoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(currentPoll, request.UserId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseEndPollInvalidPermission, nil, nil
+	}
+
+	// Ended polls are kept to allow exporting their results.
+	// End the latest version of the poll, so votes cast in the meantime are part of the results.
+	// A poll that ended in the meantime, e.g. by a second click, isn't ended and announced twice.
+	var ended bool
+	endedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if ended = latest.IsEnded(); ended {
+			return errors.New("poll has already ended")
+		}
+		return p.endLatestPoll(latest)
+	})
+	if ended {
+		return commandErrorEndAlreadyEnded, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to end poll")
+	}
+	p.publishPollEvent(websocketEventPollEnded, endedPoll)
+	p.notifyWebhook(webhookEventPollEnded, endedPoll, request.UserId)
+	p.recordAudit(audit.ActionPollEnded, endedPoll, request.UserId, "")
+	p.updateCrossPosts(endedPoll)
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(endedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	post, appErr := endedPoll.ToEndPollPost(p.getPublicLocalizer(), *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName, p.ConvertUserIDToDisplayName)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get convert to end poll post")
+	}
+
+	if err := p.unscheduleEnd(endedPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll end", "error", err.Error())
+	}
+
+	p.postEndPollAnnouncement(request.TeamId, request.PostId, endedPoll)
+
+	// Mattermost keeps the pin of posts updated by the response, so pinned polls update their post right away to unpin it
+	if endedPoll.Pinned {
+		post.Id = request.PostId
+		post.ChannelId = endedPoll.ChannelID
+		if _, appErr = p.API.UpdatePost(post); appErr != nil {
+			return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to update post")
+		}
+		return nil, nil, nil
+	}
+	return nil, post, nil
+}
+
+// handleConfirmEndPollDialogRequest opens a dialog that asks the user to confirm ending a poll
+func (p *MatterpollPlugin) handleConfirmEndPollDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	currentPoll, err := p.Store.Poll().Get(vars["id"])
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(currentPoll, request.UserId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseEndPollInvalidPermission, nil, nil
+	}
+	if currentPoll.IsEnded() {
+		return commandErrorEndAlreadyEnded, nil, nil
+	}
+
+	introductionText := dialogConfirmEndPollIntroductionText
+	if currentPoll.Settings.Secret {
+		introductionText = dialogConfirmEndPollIntroductionTextSecret
+	}
+	if appErr := p.openConfirmPollDialog(currentPoll, request, "end", dialogConfirmEndPollTitle, introductionText, dialogConfirmEndPollSubmitLabel); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open confirm end poll dialog")
+	}
+	return nil, nil, nil
+}
+
+// handleConfirmEndPoll ends a poll after the user confirmed it
+func (p *MatterpollPlugin) handleConfirmEndPoll(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	msg, err := p.endPollByID(vars["id"], request.UserId)
+	return msg, nil, err
+}
+
+// openConfirmPollDialog opens a dialog that asks the user who pressed a given button of a poll post to confirm an action.
+// The introduction text gets the question and the number of voters of the poll. action is the path of the action below the poll.
+func (p *MatterpollPlugin) openConfirmPollDialog(currentPoll *poll.Poll, request *model.PostActionIntegrationRequest, action string, title, introductionText, submitLabel *i18n.Message) *model.AppError {
+	userLocalizer := p.getUserLocalizer(request.UserId)
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	count := currentPoll.NumberOfVoters()
 
-	return responseAddOptionSuccess, nil, nil
-}
-
-func (p *MatterpollPlugin) handleAddOptionDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	return p.API.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: request.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/%s/confirm", siteURL, manifest.ID, currentPoll.ID, action),
+		Dialog: model.Dialog{
+			Title: p.LocalizeDefaultMessage(userLocalizer, title),
+			IntroductionText: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: introductionText,
+				TemplateData:   map[string]interface{}{"Question": currentPoll.Question, "Count": count},
+				PluralCount:    count,
+			}),
+			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:  request.PostId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, submitLabel),
+		},
+	})
+}
+
+// postEndPollAnnouncement replies to the post of an ended poll with a summary of the results,
+// so that everybody following the thread gets notified about the outcome. Polls that ask for it email the results as well.
+// Polls in direct and group messages don't belong to a team, hence teamID is empty and the announcement has no link.
+func (p *MatterpollPlugin) postEndPollAnnouncement(teamID, postID string, endedPoll *poll.Poll) {
+	endPollAnnouncementPostError := "Failed to post the end poll announcement."
+
+	link := ""
+	if teamID != "" {
+		team, err := p.API.GetTeam(teamID)
+		if err != nil {
+			p.API.LogError(endPollAnnouncementPostError, "details", fmt.Sprintf("failed to GetTeam with TeamId: %s", teamID))
+			return
+		}
+		link = p.makePermalink(team.Name, postID)
+	}
+	p.sendEmailSummary(endedPoll, link)
+
+	pollPost, err := p.API.GetPost(postID)
+	if err != nil {
+		p.API.LogError(endPollAnnouncementPostError, "details", fmt.Sprintf("failed to GetPost with PostId: %s", postID))
+		return
+	}
+	channelID := pollPost.ChannelId
+
+	publicLocalizer := p.getPublicLocalizer()
+
+	endPost := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channelID,
+		RootId:    postID,
+		Message:   p.makeResultsMessage(endedPoll, link, publicLocalizer),
+		Type:      model.POST_DEFAULT,
+	}
+	if p.getConfiguration().ResultsChart && !endedPoll.IsSurvey() {
+		if fileID, err := p.uploadResultsChart(endedPoll, channelID); err != nil {
+			p.API.LogWarn("failed to attach results chart", "error", err.Error())
+		} else {
+			endPost.FileIds = []string{fileID}
+		}
+	}
+	if endedPoll.Settings.Invite {
+		if fileID, err := p.uploadInvite(endedPoll, channelID); err != nil {
+			p.API.LogWarn("failed to attach calendar invite", "error", err.Error())
+		} else if fileID != "" {
+			endPost.FileIds = append(endPost.FileIds, fileID)
+		}
+	}
+
+	if _, err = p.API.CreatePost(endPost); err != nil {
+		p.API.LogError(endPollAnnouncementPostError, "details", "failed to CreatePost")
+	}
+}
+
+// makeResultsMessage returns the message that announces the results of a given ended poll with a link to its post.
+// The template of the poll takes precedence over the template of the configuration. Templates that fail fall back to the default message.
+func (p *MatterpollPlugin) makeResultsMessage(endedPoll *poll.Poll, link string, localizer *i18n.Localizer) string {
+	text := endedPoll.Settings.ResultsTemplate
+	if text == "" {
+		text = p.getConfiguration().ResultsTemplate
+	}
+	if text != "" {
+		message, err := endedPoll.ToResultsMessage(localizer, text, link)
+		if err == nil {
+			return message
+		}
+		p.API.LogWarn("failed to apply results template", "error", err.Error())
+	}
+
+	message := responseEndPollSuccessfullyNoLink
+	if link != "" {
+		message = responseEndPollSuccessfully
+	}
+	return p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+		DefaultMessage: message,
+		TemplateData: map[string]interface{}{
+			"Question": endedPoll.Question,
+			"Link":     link,
+		}}) + "\n\n" + endedPoll.ToResultsSummary(localizer)
+}
+
+// uploadResultsChart uploads a bar chart of the results of a given poll to a given channel and returns the ID of the file
+func (p *MatterpollPlugin) uploadResultsChart(endedPoll *poll.Poll, channelID string) (string, error) {
+	data, err := endedPoll.ToResultsChart()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render results chart")
+	}
+	fileInfo, appErr := p.API.UploadFile(data, channelID, fmt.Sprintf("poll-%s.png", endedPoll.ID))
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to upload results chart")
+	}
+	return fileInfo.Id, nil
+}
+
+// uploadInvite uploads a calendar invite for the best time slot of a given scheduling poll to a given channel and returns the ID of the file.
+// Ties are broken by the order of the answer options. The ID is empty if nobody has voted.
+func (p *MatterpollPlugin) uploadInvite(endedPoll *poll.Poll, channelID string) (string, error) {
+	best := endedPoll.BestSlots()
+	if len(best) == 0 {
+		return "", nil
+	}
+	data, err := endedPoll.ToICS(best[0])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create calendar invite")
+	}
+	fileInfo, appErr := p.API.UploadFile(data, channelID, fmt.Sprintf("poll-%s.ics", endedPoll.ID))
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to upload calendar invite")
+	}
+	return fileInfo.Id, nil
+}
+
+func (p *MatterpollPlugin) handleDeletePoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
 	pollID := vars["id"]
-	userLocalizer := p.getUserLocalizer(request.UserId)
 
 	poll, err := p.Store.Poll().Get(pollID)
 	if err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
 	}
 
-	if !poll.Settings.PublicAddOption {
-		hasPermission, appErr := p.HasPermission(poll, request.UserId)
-		if appErr != nil {
-			return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
-		}
-		if !hasPermission {
-			return responseAddOptionInvalidPermission, nil, nil
-		}
+	hasPermission, appErr := p.HasPermission(poll, request.UserId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseDeletePollInvalidPermission, nil, nil
+	}
+
+	if err := p.deletePoll(poll, request.PostId, request.UserId); err != nil {
+		return commandErrorGeneric, nil, err
+	}
+	return p.deletePollSuccessMessage(), nil, nil
+}
+
+// deletePollSuccessMessage returns the response to a successful deletion, which tells how to restore the poll if that's possible
+func (p *MatterpollPlugin) deletePollSuccessMessage() *i18n.Message {
+	if p.getConfiguration().deleteGracePeriodHours > 0 {
+		return responseDeletePollSuccessRestorable
+	}
+	return responseDeletePollSuccess
+}
+
+// handleConfirmDeletePollDialogRequest opens a dialog that asks the user to confirm deleting a poll
+func (p *MatterpollPlugin) handleConfirmDeletePollDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	currentPoll, err := p.Store.Poll().Get(vars["id"])
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(currentPoll, request.UserId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseDeletePollInvalidPermission, nil, nil
+	}
+
+	// The results of ended polls are shown even if the poll is secret
+	introductionText := dialogConfirmDeletePollIntroductionText
+	if currentPoll.Settings.Secret && !currentPoll.IsEnded() {
+		introductionText = dialogConfirmDeletePollIntroductionTextSecret
+	}
+	if appErr := p.openConfirmPollDialog(currentPoll, request, "delete", dialogConfirmDeletePollTitle, introductionText, dialogConfirmDeletePollSubmitLabel); appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open confirm delete poll dialog")
+	}
+	return nil, nil, nil
+}
+
+// handleConfirmDeletePoll deletes a poll after the user confirmed it
+func (p *MatterpollPlugin) handleConfirmDeletePoll(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	msg, err := p.deletePollByID(vars["id"], request.UserId)
+	return msg, nil, err
+}
+
+// handleTransferPollDialogRequest opens a dialog to pick the new owner of a poll
+func (p *MatterpollPlugin) handleTransferPollDialogRequest(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
+	userLocalizer := p.getUserLocalizer(request.UserId)
+
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(currentPoll, request.UserId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseTransferPollInvalidPermission, nil, nil
 	}
 
 	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
 	dialog := model.OpenDialogRequest{
 		TriggerId: request.TriggerId,
-		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/option/add", siteURL, manifest.ID, pollID),
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/transfer", siteURL, manifest.ID, pollID),
 		Dialog: model.Dialog{
-			Title:       p.LocalizeDefaultMessage(userLocalizer, dialogAddOptionTitle),
+			Title:       p.LocalizeDefaultMessage(userLocalizer, dialogTransferPollTitle),
 			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
 			CallbackId:  request.PostId,
-			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogAddOptionSubmitLabel),
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogTransferPollSubmitLabel),
 			Elements: []model.DialogElement{{
-				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogAddOptionElementDisplayName),
-				Name:        addOptionKey,
-				Type:        "text",
-				SubType:     "text",
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogTransferPollElementDisplayName),
+				Name:        transferPollKey,
+				Type:        "select",
+				DataSource:  "users",
+				HelpText:    p.LocalizeDefaultMessage(userLocalizer, dialogTransferPollElementHelpText),
 			}},
 		},
 	}
 
 	if appErr := p.API.OpenInteractiveDialog(dialog); appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open add option dialog")
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to open transfer poll dialog")
 	}
 	return nil, nil, nil
 }
 
-func (p *MatterpollPlugin) handleEndPoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
-	pollID := vars["id"]
+// handleTransferPoll hands a poll over to the user picked in the transfer poll dialog
+func (p *MatterpollPlugin) handleTransferPoll(vars map[string]string, request *model.SubmitDialogRequest) (*i18n.Message, *model.SubmitDialogResponse, error) {
+	newOwnerID, ok := request.Submission[transferPollKey].(string)
+	if !ok {
+		return commandErrorGeneric, nil, errors.Errorf("failed to get submission key %s", transferPollKey)
+	}
+	newOwner, appErr := p.API.GetUser(newOwnerID)
+	if appErr != nil {
+		return responseTransferPollUnknownUser, nil, nil
+	}
+
+	msg, err := p.transferPollByID(vars["id"], request.UserId, newOwner)
+	return msg, nil, err
+}
+
+// deletePoll deletes a poll together with the post that displays it and stops all jobs of the poll.
+// If a grace period is configured, the poll is only marked as deleted and can be restored until the grace period is over.
+func (p *MatterpollPlugin) deletePoll(pollToDelete *poll.Poll, postID, userID string) error {
+	if p.getConfiguration().deleteGracePeriodHours > 0 {
+		if err := p.softDeletePoll(pollToDelete, postID); err != nil {
+			return err
+		}
+	} else {
+		if appErr := p.API.DeletePost(postID); appErr != nil {
+			return errors.Wrap(appErr, "failed to delete post")
+		}
+		for _, crossPostID := range otherPollPosts(pollToDelete, postID) {
+			if appErr := p.API.DeletePost(crossPostID); appErr != nil {
+				p.API.LogWarn("failed to delete cross post", "pollID", pollToDelete.ID, "error", appErr.Error())
+			}
+		}
+		if err := p.Store.Poll().Delete(pollToDelete); err != nil {
+			return errors.Wrap(err, "failed to delete poll")
+		}
+	}
+	p.notifyWebhook(webhookEventPollDeleted, pollToDelete, userID)
+	p.recordAudit(audit.ActionPollDeleted, pollToDelete, userID, "")
+
+	if err := p.unscheduleEnd(pollToDelete); err != nil {
+		p.API.LogWarn("failed to unschedule poll end", "error", err.Error())
+	}
+	// Deleting a recurring poll stops the recurrence
+	if err := p.unscheduleRepeat(pollToDelete); err != nil {
+		p.API.LogWarn("failed to unschedule poll recurrence", "error", err.Error())
+	}
+	if err := p.unscheduleDigest(pollToDelete); err != nil {
+		p.API.LogWarn("failed to unschedule poll digest", "error", err.Error())
+	}
+	return nil
+}
+
+func (p *MatterpollPlugin) handleExportPoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	msg, err := p.exportPoll(vars["id"], request.UserId)
+	return msg, nil, err
+}
 
+// exportPoll sends the results of an ended poll as CSV file to a given user via direct message
+func (p *MatterpollPlugin) exportPoll(pollID, userID string) (*i18n.Message, error) {
 	poll, err := p.Store.Poll().Get(pollID)
 	if err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
 	}
 
-	hasPermission, appErr := p.HasPermission(poll, request.UserId)
+	hasPermission, appErr := p.HasPermission(poll, userID)
 	if appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
 	}
 	if !hasPermission {
-		return responseEndPollInvalidPermission, nil, nil
+		return responseExportPollInvalidPermission, nil
+	}
+	if !poll.IsEnded() {
+		return responseExportPollNotEnded, nil
 	}
 
-	displayName, appErr := p.ConvertCreatorIDToDisplayName(poll.Creator)
+	userLocalizer := p.getUserLocalizer(userID)
+	data, appErr := poll.ToCSV(userLocalizer, p.ConvertUserIDToDisplayName)
 	if appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to convert poll to CSV")
 	}
 
-	post, appErr := poll.ToEndPollPost(p.getServerLocalizer(), displayName, p.ConvertUserIDToDisplayName)
+	channel, appErr := p.API.GetDirectChannel(userID, p.botUserID)
 	if appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get convert to end poll post")
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to get direct channel")
 	}
 
-	if err := p.Store.Poll().Delete(poll); err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to delete poll")
+	fileInfo, appErr := p.API.UploadFile(data, channel.Id, fmt.Sprintf("poll-%s.csv", poll.ID))
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to upload file")
 	}
 
-	p.postEndPollAnnouncement(request, poll.Question)
-	return nil, post, nil
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: exportPollPostMessage,
+			TemplateData:   map[string]interface{}{"Question": poll.Question},
+		}),
+		FileIds: []string{fileInfo.Id},
+	}
+	if _, appErr = p.API.CreatePost(post); appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to create post")
+	}
+
+	return responseExportPollSuccess, nil
 }
 
-func (p *MatterpollPlugin) postEndPollAnnouncement(request *model.PostActionIntegrationRequest, question string) {
-	endPollAnnouncementPostError := "Failed to post the end poll announcement."
+func (p *MatterpollPlugin) handleRemindNonVoters(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	pollID := vars["id"]
 
-	team, err := p.API.GetTeam(request.TeamId)
+	poll, err := p.Store.Poll().Get(pollID)
 	if err != nil {
-		p.API.LogError(endPollAnnouncementPostError, "details", fmt.Sprintf("failed to GetTeam with TeamId: %s", request.TeamId))
-		return
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
 	}
-	link := fmt.Sprintf("%s/%s/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, team.Name, request.PostId)
 
-	pollPost, err := p.API.GetPost(request.PostId)
-	if err != nil {
-		p.API.LogError(endPollAnnouncementPostError, "details", fmt.Sprintf("failed to GetPost with PostId: %s", request.PostId))
-		return
+	hasPermission, appErr := p.HasPermission(poll, request.UserId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseRemindNonVotersInvalidPermission, nil, nil
 	}
-	channelID := pollPost.ChannelId
 
-	publicLocalizer := p.getServerLocalizer()
+	nonVoters, appErr := p.getNonVoters(poll, request.ChannelId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get non-voters")
+	}
+	if len(nonVoters) == 0 {
+		return responseRemindNonVotersNone, nil, nil
+	}
 
-	endPost := &model.Post{
-		UserId:    p.botUserID,
-		ChannelId: channelID,
-		RootId:    request.PostId,
-		Message: p.LocalizeWithConfig(publicLocalizer, &i18n.LocalizeConfig{
-			DefaultMessage: responseEndPollSuccessfully,
-			TemplateData: map[string]interface{}{
-				"Question": question,
-				"Link":     link,
-			}}),
-		Type: model.POST_DEFAULT,
+	// Polls in direct and group messages don't belong to a team, hence the reminders have no link
+	link := ""
+	if request.TeamId != "" {
+		team, appErr := p.API.GetTeam(request.TeamId)
+		if appErr != nil {
+			return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get team")
+		}
+		link = p.makePermalink(team.Name, request.PostId)
 	}
 
-	if _, err = p.API.CreatePost(endPost); err != nil {
-		p.API.LogError(endPollAnnouncementPostError, "details", "failed to CreatePost")
+	for _, user := range nonVoters {
+		if appErr := p.sendReminder(user, poll.Question, link); appErr != nil {
+			// Keep reminding the other users
+			p.API.LogWarn("failed to send reminder", "userID", user.Id, "error", appErr.Error())
+		}
 	}
+	return responseRemindNonVotersSuccess, nil, nil
 }
 
-func (p *MatterpollPlugin) handleDeletePoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
-	pollID := vars["id"]
-
-	poll, err := p.Store.Poll().Get(pollID)
+// handleShowNonVoters sends the channel members that haven't voted in a poll yet as ephemeral post to the poll creator,
+// so they know whom to ask before the poll ends
+func (p *MatterpollPlugin) handleShowNonVoters(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	poll, err := p.Store.Poll().Get(vars["id"])
 	if err != nil {
 		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
 	}
@@ -395,17 +2528,189 @@ func (p *MatterpollPlugin) handleDeletePoll(vars map[string]string, request *mod
 		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to check permission")
 	}
 	if !hasPermission {
-		return responseDeletePollInvalidPermission, nil, nil
+		return responseShowNonVotersInvalidPermission, nil, nil
+	}
+
+	nonVoters, appErr := p.getNonVoters(poll, request.ChannelId)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get non-voters")
+	}
+	if len(nonVoters) == 0 {
+		return responseRemindNonVotersNone, nil, nil
+	}
+
+	usernames := []string{}
+	for _, user := range nonVoters {
+		usernames = append(usernames, "@"+user.Username)
+	}
+	message := p.LocalizeWithConfig(p.getUserLocalizer(request.UserId), &i18n.LocalizeConfig{
+		DefaultMessage: showNonVotersMessage,
+		TemplateData:   map[string]interface{}{"Count": len(nonVoters), "Users": strings.Join(usernames, ", ")},
+		PluralCount:    len(nonVoters),
+	})
+
+	// The ephemeral response can't carry the non-voters, because they aren't part of a localized message
+	p.SendEphemeralPost(request.ChannelId, request.UserId, "#### "+poll.Question+"\n"+message)
+	return nil, nil, nil
+}
+
+// handleChangePage shows another page of answer options on the post of a paginated poll.
+// The page is stored with the poll, so everybody sees the same page and votes keep it.
+func (p *MatterpollPlugin) handleChangePage(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	page, _ := strconv.Atoi(vars["page"])
+
+	var ended bool
+	pagedPoll, err := p.Store.Poll().Update(vars["id"], func(latest *poll.Poll) error {
+		if ended = latest.IsEnded(); ended {
+			return errors.New("poll has already ended")
+		}
+		return latest.SetPage(page)
+	})
+	if ended {
+		return responseVotePollEnded, nil, nil
+	}
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to change page")
 	}
 
-	appErr = p.API.DeletePost(request.PostId)
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(pagedPoll.Creator)
+	if appErr != nil {
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+	attachments, appErr := p.makePollAttachments(pagedPoll, displayName)
 	if appErr != nil {
-		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to delete post")
+		return commandErrorGeneric, nil, errors.Wrap(appErr, "failed to get poll attachments")
+	}
+
+	post := &model.Post{}
+	model.ParseSlackAttachment(post, attachments)
+	return nil, post, nil
+}
+
+// getNonVoters returns all users of a given channel that haven't voted in a poll yet. Bots and deactivated users are left out.
+func (p *MatterpollPlugin) getNonVoters(poll *poll.Poll, channelID string) ([]*model.User, *model.AppError) {
+	nonVoters := []*model.User{}
+	for page := 0; ; page++ {
+		members, appErr := p.API.GetChannelMembers(channelID, page, channelMembersPerPage)
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		for _, member := range *members {
+			if poll.HasVoted(member.UserId) {
+				continue
+			}
+			user, appErr := p.API.GetUser(member.UserId)
+			if appErr != nil {
+				return nil, appErr
+			}
+			if user.IsBot || user.DeleteAt != 0 {
+				continue
+			}
+			nonVoters = append(nonVoters, user)
+		}
+
+		if len(*members) < channelMembersPerPage {
+			return nonVoters, nil
+		}
+	}
+}
+
+// getEligibleVoters returns the IDs of all users of a given channel that may vote in a poll. Bots and deactivated users are left out,
+// and so are guest accounts if excludeGuests is true.
+func (p *MatterpollPlugin) getEligibleVoters(channelID string, excludeGuests bool) ([]string, *model.AppError) {
+	eligibleVoters := []string{}
+	for page := 0; ; page++ {
+		members, appErr := p.API.GetChannelMembers(channelID, page, channelMembersPerPage)
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		for _, member := range *members {
+			user, appErr := p.API.GetUser(member.UserId)
+			if appErr != nil {
+				return nil, appErr
+			}
+			if user.IsBot || user.DeleteAt != 0 || (excludeGuests && user.IsInRole(guestRoleID)) {
+				continue
+			}
+			eligibleVoters = append(eligibleVoters, member.UserId)
+		}
+
+		if len(*members) < channelMembersPerPage {
+			return eligibleVoters, nil
+		}
+	}
+}
+
+// endLatestPoll ends the latest version of a poll. Polls with a quorum record the number of eligible voters,
+// so that the results can be checked against the quorum.
+func (p *MatterpollPlugin) endLatestPoll(latest *poll.Poll) error {
+	if latest.HasQuorum() {
+		eligibleVoters, appErr := p.getPollEligibleVoters(latest)
+		if appErr != nil {
+			return errors.Wrap(appErr, "failed to get eligible voters")
+		}
+		latest.NumberOfEligibleVoters = len(eligibleVoters)
+	}
+	latest.End()
+	return nil
+}
+
+// checkVoter checks if a given user is allowed to vote in a given poll. It returns the message that tells the user why they can't vote,
+// or nil if they can. Nobody can vote while voting is paused. Guest accounts are rejected if the poll or the configuration excludes them,
+// and polls with the members-only setting only accept votes from members of one of the channels they were posted in.
+func (p *MatterpollPlugin) checkVoter(votedPoll *poll.Poll, userID string) (*i18n.Message, error) {
+	if votedPoll.IsPaused() {
+		return responseVotePaused, nil
+	}
+	if p.excludesGuests(votedPoll) {
+		isGuest, appErr := p.isGuest(userID)
+		if appErr != nil {
+			return nil, errors.Wrap(appErr, "failed to get user")
+		}
+		if isGuest {
+			return responseVoteGuest, nil
+		}
+	}
+	if !votedPoll.Settings.MembersOnly {
+		return nil, nil
 	}
+	for _, channelID := range votedPoll.ChannelIDs() {
+		_, appErr := p.API.GetChannelMember(channelID, userID)
+		if appErr == nil {
+			return nil, nil
+		}
+		if appErr.StatusCode != http.StatusNotFound {
+			return nil, errors.Wrap(appErr, "failed to get channel member")
+		}
+	}
+	return responseVoteNotMember, nil
+}
 
-	if err := p.Store.Poll().Delete(poll); err != nil {
-		return commandErrorGeneric, nil, errors.Wrap(err, "failed to delete poll")
+// sendReminder sends a direct message to a user that links to a poll the user hasn't voted in yet.
+// The message has no link if link is empty.
+func (p *MatterpollPlugin) sendReminder(user *model.User, question, link string) *model.AppError {
+	channel, appErr := p.API.GetDirectChannel(user.Id, p.botUserID)
+	if appErr != nil {
+		return appErr
 	}
 
-	return responseDeletePollSuccess, nil, nil
+	message := remindNonVotersPostMessageNoLink
+	if link != "" {
+		message = remindNonVotersPostMessage
+	}
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(p.getLocalizerForUser(user), &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData: map[string]interface{}{
+				"Question": question,
+				"Link":     link,
+			},
+		}),
+	}
+	_, appErr = p.API.CreatePost(post)
+	return appErr
 }
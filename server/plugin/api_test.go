@@ -2,16 +2,22 @@ package plugin
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"bou.ke/monkey"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/metrics"
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -121,6 +127,934 @@ func TestServeFile(t *testing.T) {
 	}
 }
 
+func TestHandleOpenAPI(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		ExpectedStatusCode int
+		ShouldError        bool
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				path, err := filepath.Abs("../..")
+				require.Nil(t, err)
+				api.On("GetBundlePath").Return(path, nil)
+				return api
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		"GetBundlePath fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetBundlePath").Return("", errors.New(""))
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ShouldError:        true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			if test.ShouldError {
+				return
+			}
+			assert.Equal(t, "application/json", result.Header.Get("Content-Type"))
+			document := map[string]interface{}{}
+			require.Nil(t, json.NewDecoder(result.Body).Decode(&document))
+			assert.Equal(t, "3.0.3", document["openapi"])
+		})
+	}
+}
+
+func TestOpenAPIDocumentsRoutes(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("..", "..", "assets", openAPIFilename))
+	require.Nil(t, err)
+	var document struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	require.Nil(t, json.Unmarshal(b, &document))
+	require.NotEmpty(t, document.Paths)
+
+	replacer := strings.NewReplacer("{pollID}", testutils.GetPollID(), "{optionNumber}", "1")
+	for path, operations := range document.Paths {
+		for method := range operations {
+			t.Run(method+" "+path, func(t *testing.T) {
+				api := &plugintest.API{}
+				api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+				api.On("GetBundlePath").Return("", errors.New("")).Maybe()
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+				p := setupTestPlugin(t, api, &mockstore.Store{})
+
+				// Requests without authentication are rejected, but only after they got routed
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(strings.ToUpper(method), replacer.Replace(path), nil)
+				p.ServeHTTP(nil, w, r)
+
+				assert.NotEqual(t, http.StatusNotFound, w.Result().StatusCode)
+				assert.NotEqual(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+			})
+		}
+	}
+}
+
+func TestHandleMetrics(t *testing.T) {
+	endedPoll := testutils.GetPoll()
+	endedPoll.EndedAt = 1234567890
+	scheduledPoll := testutils.GetPollWithSettings(poll.Settings{PostAt: 1234567890})
+	runningPoll := testutils.GetPoll()
+	runningPoll.PostID = "postID1"
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Configuration      *configuration
+		Authorization      string
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{endedPoll, scheduledPoll, runningPoll}, nil)
+				return store
+			},
+			Configuration:      &configuration{EnableMetrics: true},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody:       "matterpoll_active_polls 1\n",
+		},
+		"all fine, with API token": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{}, nil)
+				return store
+			},
+			Configuration:      &configuration{EnableMetrics: true, APIToken: "token"},
+			Authorization:      "Bearer token",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody:       "matterpoll_active_polls 0\n",
+		},
+		"metrics disabled": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Configuration:      &configuration{},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		"invalid API token": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Configuration:      &configuration{EnableMetrics: true, APIToken: "token"},
+			Authorization:      "Bearer wrong",
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		"PollStore.List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, &model.AppError{})
+				return store
+			},
+			Configuration:      &configuration{EnableMetrics: true},
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.setConfiguration(test.Configuration)
+			p.metrics = metrics.New()
+			p.metrics.IncVotesCast()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if test.Authorization != "" {
+				r.Header.Set("Authorization", test.Authorization)
+			}
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			if test.ExpectedStatusCode == http.StatusOK {
+				body, err := ioutil.ReadAll(result.Body)
+				require.Nil(t, err)
+				assert.Contains(t, string(body), "matterpoll_votes_cast_total 1\n")
+				assert.Contains(t, string(body), test.ExpectedBody)
+			}
+		})
+	}
+}
+
+func TestHandleCreatePollRequest(t *testing.T) {
+	posted := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID2"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	expectedPost := func(p *poll.Poll, authorName string) *model.Post {
+		post := &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "channelID1",
+			Type:      model.POST_DEFAULT,
+		}
+		model.ParseSlackAttachment(post, p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, authorName))
+		return post
+	}
+	poll1 := testutils.GetPollWithSettings(poll.Settings{Progress: true})
+	poll2 := testutils.GetPollTwoOptions()
+	poll2.Creator = testutils.GetBotUserID()
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		APIToken           string
+		Token              string
+		Body               string
+		ChannelDisabled    bool
+		ChannelCreators    string
+		MaxPollsPerHour    int
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"Channel disabled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "question": "Question"}`,
+			ChannelDisabled:    true,
+			ExpectedStatusCode: http.StatusForbidden,
+			ExpectedBody:       "polls are disabled in this channel\n",
+		},
+		"Channel restricted to system admins": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "user_id": "userID1", "question": "Question"}`,
+			ChannelCreators:    pollCreatorsSystemAdmins,
+			ExpectedStatusCode: http.StatusForbidden,
+			ExpectedBody:       "user isn't allowed to create polls in this channel\n",
+		},
+		"User rate limited": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Get", "polls_userID1", pollRateLimitWindow).Return(3, nil)
+				return store
+			},
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "user_id": "userID1", "question": "Question"}`,
+			MaxPollsPerHour:    3,
+			ExpectedStatusCode: http.StatusTooManyRequests,
+			ExpectedBody:       "user created too many polls recently\n",
+		},
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", expectedPost(poll1, "John Doe")).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll1).Return(nil)
+				store.PollStore.On("Save", posted(poll1.Copy())).Return(nil)
+				store.RateLimitStore.On("Get", "polls_userID1", pollRateLimitWindow).Return(2, nil)
+				store.RateLimitStore.On("Increment", "polls_userID1", pollRateLimitWindow).Return(3, nil)
+				return store
+			},
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "user_id": "userID1", "question": "Question", "answer_options": ["Answer 1", "Answer 2", "Answer 3"], "settings": ["--progress"]}`,
+			MaxPollsPerHour:    3,
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedBody:       fmt.Sprintf(`{"poll_id":"%s","post_id":"postID2"}`, testutils.GetPollID()),
+		},
+		"Valid request, bot as creator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{Username: "matterpoll"}, nil)
+				api.On("CreatePost", expectedPost(poll2, "matterpoll")).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll2).Return(nil)
+				store.PollStore.On("Save", posted(poll2.Copy())).Return(nil)
+				return store
+			},
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "question": "Question"}`,
+			MaxPollsPerHour:    3,
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedBody:       fmt.Sprintf(`{"poll_id":"%s","post_id":"postID2"}`, testutils.GetPollID()),
+		},
+		"API disabled": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "",
+			Token:              "",
+			Body:               `{"channel_id": "channelID1", "question": "Question"}`,
+			ExpectedStatusCode: http.StatusForbidden,
+			ExpectedBody:       "api is disabled\n",
+		},
+		"Invalid token": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token2",
+			Body:               `{"channel_id": "channelID1", "question": "Question"}`,
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedBody:       "not authorized\n",
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": `,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "invalid request\n",
+		},
+		"Missing question": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "channel_id and question are required\n",
+		},
+		"GetChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "question": "Question"}`,
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedBody:       "channel not found\n",
+		},
+		"GetUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "user_id": "userID1", "question": "Question"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "user not found\n",
+		},
+		"Only one answer option": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "question": "Question", "answer_options": ["Answer 1"]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       commandErrorinvalidNumberOfOptions.Other + "\n",
+		},
+		"Invalid setting": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "question": "Question", "settings": ["unknownOption"]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "Unrecognised poll setting unknownOption\n",
+		},
+		"PollStore.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll2).Return(errors.New(""))
+				return store
+			},
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "question": "Question"}`,
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to create poll\n",
+		},
+		"PollStore.Save fails, creation isn't counted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Get", "polls_userID1", pollRateLimitWindow).Return(2, nil)
+				store.PollStore.On("Save", mock.AnythingOfType("*poll.Poll")).Return(errors.New(""))
+				return store
+			},
+			APIToken:           "token1",
+			Token:              "token1",
+			Body:               `{"channel_id": "channelID1", "user_id": "userID1", "question": "Question"}`,
+			MaxPollsPerHour:    3,
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to create poll\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.ChannelStore.On("IsDisabled", "channelID1").Return(test.ChannelDisabled, nil).Maybe()
+			store.ChannelStore.On("GetCreators", "channelID1").Return(test.ChannelCreators, nil).Maybe()
+			store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.configuration.APIToken = test.APIToken
+			p.configuration.maxPollsPerHour = test.MaxPollsPerHour
+
+			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			patch2 := monkey.Patch(model.NewId, func() string { return testutils.GetPollID() })
+			defer patch1.Unpatch()
+			defer patch2.Unpatch()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", strings.NewReader(test.Body))
+			r.Header.Add(apiTokenHeader, test.Token)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			body, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedBody, string(body))
+		})
+	}
+}
+
+func TestHandleEndPollRequest(t *testing.T) {
+	runningPoll := func() *poll.Poll {
+		p := testutils.GetPollWithVotes()
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	pollWithDeadline := func() *poll.Poll {
+		p := runningPoll()
+		p.Settings.EndAt = 1234567890
+		return p
+	}
+	scheduledPoll := testutils.GetPollWithSettings(poll.Settings{PostAt: 1234567890})
+	endedPoll := runningPoll()
+	endedPoll.EndedAt = 1234567890
+
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + strings.Replace(userID, "userID", "user", 1), nil
+	}
+	expectedPost, appErr := runningPoll().ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe", converter)
+	require.Nil(t, appErr)
+	expectedPost.Id = "postID1"
+	expectedPost.ChannelId = "channelID1"
+	expectedDeadlinePost, appErr := pollWithDeadline().ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe", converter)
+	require.Nil(t, appErr)
+	expectedDeadlinePost.Id = "postID1"
+	expectedDeadlinePost.ChannelId = "channelID1"
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	setupEnd := func(api *plugintest.API, post *model.Post) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		api.On("UpdatePost", post).Return(post, nil)
+		api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+		api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+		api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+		return api
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		APIToken           string
+		TrustedPlugins     string
+		Headers            map[string]string
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return setupEnd(api, expectedPost)
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(runningPoll()))
+				return store
+			},
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusNoContent,
+			ExpectedBody:       "",
+		},
+		"Valid request, poll with deadline": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return setupEnd(api, expectedDeadlinePost)
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollWithDeadline(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithDeadline()))
+				store.JobStore.On("Delete", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890)).Return(nil)
+				store.JobStore.On("Delete", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 0)).Return(nil)
+				return store
+			},
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusNoContent,
+			ExpectedBody:       "",
+		},
+		"Valid request from a trusted plugin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetServerVersion").Return("5.18.0")
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return setupEnd(api, expectedPost)
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(runningPoll()))
+				return store
+			},
+			TrustedPlugins:     "com.example.other, playbooks",
+			Headers:            map[string]string{pluginIDHeader: "playbooks"},
+			ExpectedStatusCode: http.StatusNoContent,
+			ExpectedBody:       "",
+		},
+		"Plugin is not trusted": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			TrustedPlugins:     "com.example.other",
+			Headers:            map[string]string{pluginIDHeader: "playbooks"},
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedBody:       "not authorized\n",
+		},
+		"Trusted plugin, server doesn't support inter-plugin requests": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetServerVersion").Return("5.17.0")
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			TrustedPlugins:     "playbooks",
+			Headers:            map[string]string{pluginIDHeader: "playbooks"},
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedBody:       "not authorized\n",
+		},
+		"API disabled": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusForbidden,
+			ExpectedBody:       "api is disabled\n",
+		},
+		"Invalid token": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token2"},
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedBody:       "not authorized\n",
+		},
+		"Poll not found": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedBody:       "poll not found\n",
+		},
+		"Poll is scheduled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduledPoll.Copy(), nil)
+				return store
+			},
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedBody:       "poll hasn't been posted yet\n",
+		},
+		"Poll has already ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedBody:       "poll has already ended\n",
+		},
+		"Poll has been deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				deletedPoll := testutils.GetPoll()
+				deletedPoll.DeletedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll, nil)
+				return store
+			},
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedBody:       "poll has been deleted\n",
+		},
+		"PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			APIToken:           "token1",
+			Headers:            map[string]string{apiTokenHeader: "token1"},
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to end poll\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.configuration.APIToken = test.APIToken
+			p.configuration.TrustedPlugins = test.TrustedPlugins
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID()), nil)
+			for key, value := range test.Headers {
+				r.Header.Set(key, value)
+			}
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			body, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedBody, string(body))
+		})
+	}
+}
+
+func TestHandleCreatePoll(t *testing.T) {
+	t.Run("not-authorized", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		request := &model.SubmitDialogRequest{UserId: "userID1", ChannelId: "channelID1"}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/polls/create", bytes.NewReader(request.ToJson()))
+		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	})
+
+	posted := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID2"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	expectedPost := func(p *poll.Poll) *model.Post {
+		post := &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "channelID1",
+			RootId:    "postID1",
+			Type:      model.POST_DEFAULT,
+		}
+		model.ParseSlackAttachment(post, p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+		return post
+	}
+	poll1 := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, Progress: true})
+	poll2 := testutils.GetPollTwoOptions()
+	poll2.Settings.VoteMode = poll.VoteModeRanked
+	poll3 := testutils.GetPollTwoOptions()
+	poll3.Settings.PostAt = 1234567890 + 60*60*1000
+	poll3.ChannelID = "channelID1"
+	poll3.RootID = "postID1"
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Submission         map[string]interface{}
+		ChannelDisabled    bool
+		ChannelCreators    string
+		ExpectedStatusCode int
+		ExpectedResponse   *model.SubmitDialogResponse
+	}{
+		"Channel disabled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseCreatePollChannelDisabled.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollOptionsKey:  "Answer 1\nAnswer 2",
+			},
+			ChannelDisabled:    true,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Channel restricted to channel admins": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", Type: model.CHANNEL_OPEN}, nil)
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseCreatePollRestricted.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollOptionsKey:  "Answer 1\nAnswer 2",
+			},
+			ChannelCreators:    pollCreatorsChannelAdmins,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("CreatePost", expectedPost(poll1)).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll1).Return(nil)
+				store.PollStore.On("Save", posted(poll1.Copy())).Return(nil)
+				return store
+			},
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollOptionsKey:  "Answer 1\n Answer 2\n\nAnswer 3\n",
+				createPollVoteModeKey: "single",
+				createPollSettingsKey: "anonymous --progress",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, no answer options": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("CreatePost", expectedPost(poll2)).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll2).Return(nil)
+				store.PollStore.On("Save", posted(poll2.Copy())).Return(nil)
+				return store
+			},
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollVoteModeKey: "ranked",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, scheduled poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseCreatePollScheduled.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll3).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypePostPoll, testutils.GetPollID(), poll3.Settings.PostAt)).Return(nil)
+				return store
+			},
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollSettingsKey: "schedule=1h",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Only one answer option": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollOptionsKey:  "Answer 1",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					createPollOptionsKey: commandErrorinvalidNumberOfOptions.Other,
+				},
+			},
+		},
+		"Duplicate answer options": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollOptionsKey:  "Answer 1\nAnswer 1",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					createPollOptionsKey: "duplicate options: Answer 1",
+				},
+			},
+		},
+		"Invalid setting": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollSettingsKey: "unknownOption",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					createPollSettingsKey: "Unrecognised poll setting unknownOption",
+				},
+			},
+		},
+		"PollStore.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", testutils.GetPoll()).Return(errors.New(""))
+				return store
+			},
+			Submission: map[string]interface{}{
+				createPollQuestionKey: "Question",
+				createPollOptionsKey:  "Answer 1\nAnswer 2\nAnswer 3",
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.ChannelStore.On("IsDisabled", "channelID1").Return(test.ChannelDisabled, nil).Maybe()
+			store.ChannelStore.On("GetCreators", "channelID1").Return(test.ChannelCreators, nil).Maybe()
+			store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			patch2 := monkey.Patch(model.NewId, func() string { return testutils.GetPollID() })
+			defer patch1.Unpatch()
+			defer patch2.Unpatch()
+
+			request := &model.SubmitDialogRequest{
+				UserId:     "userID1",
+				CallbackId: "postID1",
+				ChannelId:  "channelID1",
+				Submission: test.Submission,
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/polls/create", bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", "userID1")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.SubmitDialogResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+			if test.ExpectedResponse != nil {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+			}
+		})
+	}
+}
+
 func TestHandleVote(t *testing.T) {
 	t.Run("not-authorized", func(t *testing.T) {
 		api := &plugintest.API{}
@@ -130,72 +1064,4350 @@ func TestHandleVote(t *testing.T) {
 		request := &model.PostActionIntegrationRequest{UserId: "userID1", TeamId: "teamID1"}
 
 		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/0", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/0", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	})
+
+	localizer := testutils.GetLocalizer()
+
+	poll1In := testutils.GetPoll()
+	poll1Out := poll1In.Copy()
+	err := poll1Out.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	expectedPost1 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost1, poll1Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	poll2In := testutils.GetPoll()
+	err = poll2In.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	poll2Out := poll2In.Copy()
+	err = poll2Out.UpdateVote("userID1", 1)
+	require.Nil(t, err)
+	expectedPost2 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost2, poll2Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	poll3In := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeApproval})
+	err = poll3In.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	poll3Out := poll3In.Copy()
+	err = poll3Out.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	expectedPost3 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost3, poll3Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	// Another user voted after the poll post was rendered
+	poll4In := testutils.GetPoll()
+	err = poll4In.UpdateVote("userID2", 1)
+	require.Nil(t, err)
+	poll4Out := poll4In.Copy()
+	err = poll4Out.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	expectedPost4 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost4, poll4Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	poll5In := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 2})
+	poll5In.ChannelID = "channelID1"
+	poll5Out := poll5In.Copy()
+	err = poll5Out.UpdateVote("userID1", 1)
+	require.Nil(t, err)
+	expectedPost5 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost5, poll5Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	poll6In := testutils.GetPollWithSettings(poll.Settings{PublicVotes: true})
+	poll6Out := poll6In.Copy()
+	err = poll6Out.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	expectedAttachments6 := poll6Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe")
+	expectedAttachments6[0].Fields = []*model.SlackAttachmentField{
+		{Short: true, Title: "Answer 1", Value: "@user1"},
+		{Short: true, Title: "Answer 2", Value: ""},
+		{Short: true, Title: "Answer 3", Value: ""},
+	}
+	expectedPost6 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost6, expectedAttachments6)
+
+	poll7In := testutils.GetPollWithSettings(poll.Settings{MembersOnly: true})
+	poll7In.ChannelID = "channelID1"
+	poll7Out := poll7In.Copy()
+	err = poll7Out.UpdateVote("userID1", 0)
+	require.Nil(t, err)
+	expectedPost7 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost7, poll7Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		VoteIndex          int
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request with no votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll1In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteCounted.Other, Update: expectedPost1},
+		},
+		"Valid request with vote": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll2In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          1,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteUpdated.Other, Update: expectedPost2},
+		},
+		"Valid request, approval poll, withdraw vote": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll3In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteRemoved.Other, Update: expectedPost3},
+		},
+		"Valid request, vote cast concurrently": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll4In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteCounted.Other, Update: expectedPost4},
+		},
+		"Valid request, multiple votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					ChannelId: "channelID1",
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteUpdated.Other + " 2 of 2 votes used.",
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll5In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			VoteIndex:          1,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: "", Update: expectedPost5},
+		},
+		"Valid request, public votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll6In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteCounted.Other, Update: expectedPost6},
+		},
+		"Valid request, vote limit reached": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll5Out.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          2,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteLimitReached.Other},
+		},
+		"Valid request, option full": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID5").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				fullPoll := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxPerOption: 3})
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(fullPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID5", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteOptionFull.Other},
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedPoll := testutils.GetPoll()
+				endedPoll.EndedAt = 1234567890
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, poll has been deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				deletedPoll := testutils.GetPoll()
+				deletedPoll.DeletedAt = 1234567890
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(deletedPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, deadline has passed before the poll got ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithSettings(poll.Settings{EndAt: 1})))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, voting is paused": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pausedPoll := testutils.GetPoll()
+				pausedPoll.PausedAt = 1234567890
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pausedPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePaused.Other},
+		},
+		"Valid request, members only, member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{ChannelId: "channelID1", UserId: "userID1"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll7In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteCounted.Other, Update: expectedPost7},
+		},
+		"Valid request, members only, not a member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll7In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteNotMember.Other},
+		},
+		"Valid request, members only, GetChannelMember fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(nil, &model.AppError{StatusCode: http.StatusInternalServerError})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll7In.Copy()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				lockedPoll := testutils.GetPollWithVotesAndSettings(poll.Settings{LockVotes: true})
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          1,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteLocked.Other},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          1,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid index": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPoll()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          3,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, GetUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPoll()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/%d", testutils.GetPollID(), test.VoteIndex), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			if result.StatusCode == http.StatusOK {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+				require.NotNil(t, response)
+				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
+				if test.ExpectedResponse.Update != nil {
+					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
+				}
+			} else {
+				assert.Equal(test.ExpectedResponse, response)
+			}
+		})
+	}
+}
+
+func TestConfirmVote(t *testing.T) {
+	withChannel := func(p *poll.Poll) *poll.Poll {
+		p.ChannelID = "channelID1"
+		return p
+	}
+	// The vote is cast in a cross-post, so the confirmation goes to its channel instead of the one of the poll
+	ephemeralPost := func(message string) *model.Post {
+		return &model.Post{ChannelId: "channelID2", UserId: testutils.GetBotUserID(), Message: message}
+	}
+
+	for name, test := range map[string]struct {
+		Poll                 *poll.Poll
+		ShowOwnVote          bool
+		ExpectedMessage      *i18n.Message
+		ExpectedEphemeralMsg string
+	}{
+		"Own vote hidden": {
+			Poll:            withChannel(testutils.GetPollWithVotes()),
+			ExpectedMessage: responseVoteCounted,
+		},
+		"Own vote shown": {
+			Poll:                 withChannel(testutils.GetPollWithVotes()),
+			ShowOwnVote:          true,
+			ExpectedEphemeralMsg: "Your vote has been counted. **Your vote**: Answer 1",
+		},
+		"Own vote hidden, multiple votes": {
+			Poll: func() *poll.Poll {
+				p := withChannel(testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 2}))
+				p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+				return p
+			}(),
+			ExpectedEphemeralMsg: "Your vote has been counted. 2 of 2 votes used.",
+		},
+		"Own vote shown, multiple votes": {
+			Poll: func() *poll.Poll {
+				p := withChannel(testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 2}))
+				p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+				return p
+			}(),
+			ShowOwnVote:          true,
+			ExpectedEphemeralMsg: "Your vote has been counted. 2 of 2 votes used. **Your vote**: Answer 1, Answer 2",
+		},
+		"Own vote shown, survey": {
+			Poll:                 withChannel(testutils.GetSurveyWithVotes()),
+			ShowOwnVote:          true,
+			ExpectedEphemeralMsg: "Your vote has been counted. **Your vote**: Question 1: Yes; Question 2: Answer 2",
+		},
+		"Own vote shown, poll with receipts": {
+			Poll:            withChannel(testutils.GetPollWithVotesAndSettings(poll.Settings{Receipts: true})),
+			ShowOwnVote:     true,
+			ExpectedMessage: responseVoteCounted,
+		},
+		"Own vote shown, no vote left": {
+			Poll:            withChannel(testutils.GetPoll()),
+			ShowOwnVote:     true,
+			ExpectedMessage: responseVoteCounted,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("GetUser", "userID1").Return(&model.User{Id: "userID1"}, nil).Maybe()
+			if test.ExpectedEphemeralMsg != "" {
+				api.On("SendEphemeralPost", "userID1", ephemeralPost(test.ExpectedEphemeralMsg)).Return(nil)
+			}
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+			p.setConfiguration(&configuration{ShowOwnVote: test.ShowOwnVote})
+
+			msg := p.confirmVote(test.Poll, "channelID2", "userID1", responseVoteCounted)
+
+			if test.ExpectedEphemeralMsg != "" {
+				assert.Nil(t, msg)
+			} else {
+				assert.Equal(t, test.ExpectedMessage, msg)
+			}
+		})
+	}
+}
+
+func TestHandleSurveyVote(t *testing.T) {
+	localizer := testutils.GetLocalizer()
+
+	survey1Out := testutils.GetSurveyWithVotes()
+	err := survey1Out.UpdateSurveyVote("userID4", 1, 0)
+	require.Nil(t, err)
+	expectedPost1 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost1, survey1Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	survey2Out := testutils.GetSurveyWithVotes()
+	err = survey2Out.UpdateSurveyVote("userID1", 0, 1)
+	require.Nil(t, err)
+	expectedPost2 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost2, survey2Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		QuestionIndex      int
+		VoteIndex          int
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request, first answer": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetSurveyWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID4", PostId: "postID1"},
+			QuestionIndex:      1,
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteCounted.Other, Update: expectedPost1},
+		},
+		"Valid request, changed answer": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetSurveyWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			QuestionIndex:      0,
+			VoteIndex:          1,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteUpdated.Other, Update: expectedPost2},
+		},
+		"Valid request, survey has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedSurvey := testutils.GetSurveyWithVotes()
+				endedSurvey.EndedAt = 1234567890
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedSurvey))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID4", PostId: "postID1"},
+			QuestionIndex:      0,
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, members only, not a member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID4").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				survey := testutils.GetSurveyWithVotes()
+				survey.ChannelID = "channelID1"
+				survey.Settings.MembersOnly = true
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(survey))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID4", PostId: "postID1"},
+			QuestionIndex:      0,
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteNotMember.Other},
+		},
+		"Invalid question index": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetSurveyWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID4", PostId: "postID1"},
+			QuestionIndex:      2,
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID4", PostId: "postID1"},
+			QuestionIndex:      0,
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, GetUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetSurveyWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID4", PostId: "postID1"},
+			QuestionIndex:      0,
+			VoteIndex:          0,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			// The voter is looked up to localize the response
+			api.On("GetUser", "userID4").Return(&model.User{}, nil).Maybe()
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/survey/%d/vote/%d", testutils.GetPollID(), test.QuestionIndex, test.VoteIndex), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			if result.StatusCode == http.StatusOK {
+				require.NotNil(t, response)
+				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
+				if test.ExpectedResponse.Update != nil {
+					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
+				}
+			} else {
+				assert.Equal(test.ExpectedResponse, response)
+			}
+		})
+	}
+}
+
+func TestHandleResetVote(t *testing.T) {
+	pollOut := testutils.GetPollWithVotes()
+	err := pollOut.ResetVote("userID2")
+	require.Nil(t, err)
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "user1"))
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseResetVoteSuccess.Other, Update: expectedPost},
+		},
+		"Valid request, user hasn't voted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID5", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseResetVoteNotVoted.Other},
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotesAndSettings(poll.Settings{LockVotes: true})))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteLocked.Other},
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedPoll := testutils.GetPollWithVotes()
+				endedPoll.EndedAt = 1234567890
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, voting is paused": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pausedPoll := testutils.GetPollWithVotes()
+				pausedPoll.PausedAt = 1234567890
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pausedPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePaused.Other},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil).Maybe()
+			api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil).Maybe()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/reset", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			if result.StatusCode == http.StatusOK {
+				require.NotNil(t, response)
+				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
+				if test.ExpectedResponse.Update != nil {
+					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
+				}
+			} else {
+				assert.Equal(test.ExpectedResponse, response)
+			}
+		})
+	}
+}
+
+func TestHandleConfirmVote(t *testing.T) {
+	userID := "userID5"
+	channelID := model.NewId()
+	postID := model.NewId()
+
+	lockedPoll := testutils.GetPollWithVotesAndSettings(poll.Settings{LockVotes: true})
+	pollOut := lockedPoll.Copy()
+	err := pollOut.UpdateVote(userID, 2)
+	require.Nil(t, err)
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+		UserID     string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteCounted.Other,
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll.Copy()))
+				return store
+			},
+			UserID: userID,
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", "userID2", &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteLocked.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll.Copy()))
+				return store
+			},
+			UserID: "userID2",
+		},
+		"Valid request, GetPost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(nil, &model.AppError{})
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll.Copy()))
+				return store
+			},
+			UserID: userID,
+		},
+		"Valid request, UpdatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost).Return(nil, &model.AppError{})
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll.Copy()))
+				return store
+			},
+			UserID: userID,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", test.UserID).Return(&model.User{Username: "user"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.SubmitDialogRequest{UserId: test.UserID, CallbackId: postID, ChannelId: channelID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/2/confirm", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Nil(model.SubmitDialogResponseFromJson(result.Body))
+		})
+	}
+}
+
+func TestHandleSelectVote(t *testing.T) {
+	localizer := testutils.GetLocalizer()
+
+	compactPollIn := testutils.GetPollWithSettings(poll.Settings{Compact: true})
+	compactPollOut := compactPollIn.Copy()
+	require.Nil(t, compactPollOut.UpdateVote("userID1", 1))
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, compactPollOut.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	triggerID := model.NewId()
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/vote/1/confirm", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:            "Confirm Vote",
+			IntroductionText: "You are about to vote for **Answer 2**. Your vote is final and can't be changed afterwards.",
+			IconURL:          fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+			CallbackId:       "postID1",
+			SubmitLabel:      "Vote",
+		},
+	}
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		Route            string
+		Context          map[string]interface{}
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(compactPollIn.Copy()))
+				return store
+			},
+			Route:            "vote/select",
+			Context:          map[string]interface{}{"selected_option": "1"},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseVoteCounted.Other, Update: expectedPost},
+		},
+		"Valid request, lock votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithSettings(poll.Settings{Compact: true, LockVotes: true}), nil)
+				return store
+			},
+			Route:            "vote/select/confirm/request",
+			Context:          map[string]interface{}{"selected_option": "1"},
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Nothing selected": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore:       func(store *mockstore.Store) *mockstore.Store { return store },
+			Route:            "vote/select",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid selection": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				return api
+			},
+			SetupStore:       func(store *mockstore.Store) *mockstore.Store { return store },
+			Route:            "vote/select",
+			Context:          map[string]interface{}{"selected_option": "Answer 2"},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TriggerId: triggerID, Context: test.Context}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/%s", testutils.GetPollID(), test.Route), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+			require.NotNil(t, response)
+			assert.Equal(t, test.ExpectedResponse.EphemeralText, response.EphemeralText)
+			if test.ExpectedResponse.Update != nil {
+				assert.Equal(t, test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
+			}
+		})
+	}
+}
+
+func TestHandleConfirmVoteDialogRequest(t *testing.T) {
+	userID := "userID5"
+	triggerID := model.NewId()
+	postID := model.NewId()
+
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/vote/1/confirm", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:            "Confirm Vote",
+			IntroductionText: "You are about to vote for **Answer 2**. Your vote is final and can't be changed afterwards.",
+			IconURL:          fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+			CallbackId:       postID,
+			SubmitLabel:      "Vote",
+		},
+	}
+	lockedPoll := func() *poll.Poll {
+		return testutils.GetPollWithVotesAndSettings(poll.Settings{LockVotes: true})
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		UserID           string
+		VoteIndex        int
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll(), nil)
+				return store
+			},
+			UserID:           userID,
+			VoteIndex:        1,
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll(), nil)
+				return store
+			},
+			UserID:           "userID2",
+			VoteIndex:        1,
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseVoteLocked.Other},
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedPoll := lockedPoll()
+				endedPoll.EndedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll, nil)
+				return store
+			},
+			UserID:           userID,
+			VoteIndex:        1,
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Invalid index": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll(), nil)
+				return store
+			},
+			UserID:           userID,
+			VoteIndex:        3,
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			UserID:           userID,
+			VoteIndex:        1,
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll(), nil)
+				return store
+			},
+			UserID:           userID,
+			VoteIndex:        1,
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", test.UserID).Return(&model.User{Username: "user"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, PostId: postID, TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/%d/confirm/request", testutils.GetPollID(), test.VoteIndex), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleConfirmEndPollDialogRequest(t *testing.T) {
+	triggerID := model.NewId()
+	postID := model.NewId()
+
+	dialogRequest := func(introductionText string) model.OpenDialogRequest {
+		return model.OpenDialogRequest{
+			TriggerId: triggerID,
+			URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/end/confirm", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+			Dialog: model.Dialog{
+				Title:            "End Poll",
+				IntroductionText: introductionText,
+				IconURL:          fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+				CallbackId:       postID,
+				SubmitLabel:      "End",
+			},
+		}
+	}
+	endedPoll := testutils.GetPollWithVotes()
+	endedPoll.EndedAt = 1234567890
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		UserID           string
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest("Do you really want to end the poll **Question**? It has 4 votes so far. Nobody can vote anymore once it has ended.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, secret poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest("Do you really want to end the poll **Question**? Nobody can vote anymore once it has ended.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true}), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:           "userID2",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseEndPollInvalidPermission.Other},
+		},
+		"Valid request, poll has already ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorEndAlreadyEnded.Other},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest("Do you really want to end the poll **Question**? It has 4 votes so far. Nobody can vote anymore once it has ended.")).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", test.UserID).Return(&model.User{Username: "user", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, PostId: postID, TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/end/confirm/request", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleConfirmEndPoll(t *testing.T) {
+	channelID := model.NewId()
+
+	endedPoll := testutils.GetPollWithVotes()
+	endedPoll.EndedAt = 1234567890
+
+	for name, test := range map[string]struct {
+		SetupStore      func(*mockstore.Store) *mockstore.Store
+		UserID          string
+		ExpectedMessage string
+	}{
+		"Valid request, Invalid permission": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:          "userID2",
+			ExpectedMessage: responseEndPollInvalidPermission.Other,
+		},
+		"Valid request, poll has already ended": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			UserID:          "userID1",
+			ExpectedMessage: commandErrorEndAlreadyEnded.Other,
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(nil, &model.AppError{})
+				return store
+			},
+			UserID:          "userID1",
+			ExpectedMessage: commandErrorGeneric.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := &plugintest.API{}
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", test.UserID).Return(&model.User{Username: "user", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+			api.On("SendEphemeralPost", test.UserID, &model.Post{
+				ChannelId: channelID,
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMessage,
+			}).Return(nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.SubmitDialogRequest{UserId: test.UserID, CallbackId: model.NewId(), ChannelId: channelID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/end/confirm", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Nil(model.SubmitDialogResponseFromJson(result.Body))
+		})
+	}
+}
+
+func TestHandleConfirmDeletePollDialogRequest(t *testing.T) {
+	triggerID := model.NewId()
+	postID := model.NewId()
+
+	dialogRequest := func(introductionText string) model.OpenDialogRequest {
+		return model.OpenDialogRequest{
+			TriggerId: triggerID,
+			URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/delete/confirm", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+			Dialog: model.Dialog{
+				Title:            "Delete Poll",
+				IntroductionText: introductionText,
+				IconURL:          fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+				CallbackId:       postID,
+				SubmitLabel:      "Delete",
+			},
+		}
+	}
+	endedSecretPoll := testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true})
+	endedSecretPoll.EndedAt = 1234567890
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		UserID           string
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest("Do you really want to delete the poll **Question**? Its 4 votes will be lost. This can't be undone.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, secret poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest("Do you really want to delete the poll **Question**? All votes will be lost. This can't be undone.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true}), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, ended secret poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest("Do you really want to delete the poll **Question**? Its 4 votes will be lost. This can't be undone.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedSecretPoll.Copy(), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:           "userID2",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseDeletePollInvalidPermission.Other},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest("Do you really want to delete the poll **Question**? Its 4 votes will be lost. This can't be undone.")).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", test.UserID).Return(&model.User{Username: "user", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, PostId: postID, TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/delete/confirm/request", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleConfirmDeletePoll(t *testing.T) {
+	channelID := model.NewId()
+
+	for name, test := range map[string]struct {
+		SetupAPI        func(*plugintest.API) *plugintest.API
+		SetupStore      func(*mockstore.Store) *mockstore.Store
+		UserID          string
+		ExpectedMessage string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", "postID1").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pollToDelete := testutils.GetPoll()
+				pollToDelete.PostID = "postID1"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollToDelete, nil)
+				store.PollStore.On("Delete", pollToDelete).Return(nil)
+				return store
+			},
+			UserID:          "userID1",
+			ExpectedMessage: responseDeletePollSuccess.Other,
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			UserID:          "userID2",
+			ExpectedMessage: responseDeletePollInvalidPermission.Other,
+		},
+		"Valid request, DeletePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", "postID1").Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pollToDelete := testutils.GetPoll()
+				pollToDelete.PostID = "postID1"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollToDelete, nil)
+				return store
+			},
+			UserID:          "userID1",
+			ExpectedMessage: commandErrorGeneric.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", test.UserID).Return(&model.User{Username: "user", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+			api.On("SendEphemeralPost", test.UserID, &model.Post{
+				ChannelId: channelID,
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMessage,
+			}).Return(nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.SubmitDialogRequest{UserId: test.UserID, CallbackId: model.NewId(), ChannelId: channelID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/delete/confirm", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Nil(model.SubmitDialogResponseFromJson(result.Body))
+		})
+	}
+}
+
+func TestHandleTransferPollDialogRequest(t *testing.T) {
+	triggerID := model.NewId()
+	postID := model.NewId()
+
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/transfer", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Transfer Poll",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+			CallbackId:  postID,
+			SubmitLabel: "Transfer",
+			Elements: []model.DialogElement{{
+				DisplayName: "New Owner",
+				Name:        transferPollKey,
+				Type:        "select",
+				DataSource:  "users",
+				HelpText:    "The new owner can end and delete the poll. You lose these rights unless you are a moderator of the poll or a System Admin.",
+			}},
+		},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		UserID           string
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+				return api
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, invalid permission": {
+			SetupAPI:         func(api *plugintest.API) *plugintest.API { return api },
+			UserID:           "userID2",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseTransferPollInvalidPermission.Other},
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(&model.AppError{})
+				return api
+			},
+			UserID:           "userID1",
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", test.UserID).Return(&model.User{Username: "user", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: test.UserID, PostId: postID, TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/transfer/request", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			assert.Equal(t, test.ExpectedResponse, model.PostActionIntegrationResponseFromJson(result.Body))
+		})
+	}
+}
+
+func TestHandleTransferPoll(t *testing.T) {
+	channelID := model.NewId()
+
+	for name, test := range map[string]struct {
+		SetupAPI        func(*plugintest.API) *plugintest.API
+		SetupStore      func(*mockstore.Store) *mockstore.Store
+		Submission      map[string]interface{}
+		ExpectedMessage string
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1"
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pollToTransfer := testutils.GetPoll()
+				pollToTransfer.PostID = "postID1"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollToTransfer.Copy(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(GetMockPollUpdate(pollToTransfer))
+				return store
+			},
+			Submission:      map[string]interface{}{transferPollKey: "userID2"},
+			ExpectedMessage: responseTransferPollSuccess.Other,
+		},
+		"Valid request, unknown user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission:      map[string]interface{}{transferPollKey: "userID2"},
+			ExpectedMessage: responseTransferPollUnknownUser.Other,
+		},
+		"Invalid request, missing submission": {
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Submission:      map[string]interface{}{},
+			ExpectedMessage: commandErrorGeneric.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+			api.On("SendEphemeralPost", "userID1", &model.Post{
+				ChannelId: channelID,
+				UserId:    testutils.GetBotUserID(),
+				Message:   test.ExpectedMessage,
+			}).Return(nil)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.SubmitDialogRequest{UserId: "userID1", CallbackId: "postID1", ChannelId: channelID, Submission: test.Submission}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/transfer", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Nil(model.SubmitDialogResponseFromJson(result.Body))
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleAddOption(t *testing.T) {
+	t.Run("not-authorized", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		request := &model.PostActionIntegrationRequest{UserId: "userID1", TeamId: "teamID1"}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	})
+
+	userID := testutils.GetPollWithVotes().Creator
+	channelID := model.NewId()
+	postID := model.NewId()
+
+	responsePost := &model.Post{
+		ChannelId: channelID,
+		UserId:    testutils.GetBotUserID(),
+		Message:   responseAddOptionSuccess.Other,
+	}
+
+	poll1In := testutils.GetPollWithVotes()
+	poll1Out := poll1In.Copy()
+	err := poll1Out.AddAnswerOption("New Option")
+	require.Nil(t, err)
+	expectedPost1 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost1, poll1Out.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	poll2In := testutils.GetPollWithVotes()
+	require.Nil(t, poll2In.AddAnswerOption("Concurrent Option"))
+	poll2Out := poll2In.Copy()
+	require.Nil(t, poll2Out.AddAnswerOption("New Option"))
+	expectedPost2 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost2, poll2Out.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	poll3In := testutils.GetPollWithVotes()
+	poll3In.Settings.PublicAddOption = true
+	poll3Out := poll3In.Copy()
+	require.Nil(t, poll3Out.AddAnswerOption("New Option"))
+	expectedPost3 := &model.Post{}
+	model.ParseSlackAttachment(expectedPost3, poll3Out.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.SubmitDialogRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.SubmitDialogResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost1).Return(expectedPost1, nil)
+				api.On("SendEphemeralPost", userID, responsePost).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					addOptionKey: "New Option",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, option added concurrently": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost2).Return(expectedPost2, nil)
+				api.On("SendEphemeralPost", userID, responsePost).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll2In.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					addOptionKey: "New Option",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, public-add-option": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("UpdatePost", expectedPost3).Return(expectedPost3, nil)
+				api.On("SendEphemeralPost", "userID2", &model.Post{ChannelId: channelID, UserId: testutils.GetBotUserID(), Message: responseAddOptionSuccess.Other}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(poll3In.Copy(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(poll3In.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     "userID2",
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					addOptionKey: "New Option",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, duplicate option": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					addOptionKey: testutils.GetPollWithVotes().AnswerOptions[0].Answer,
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					addOptionKey: fmt.Sprintf("duplicate options: %s", testutils.GetPollWithVotes().AnswerOptions[0].Answer),
+				},
+			},
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				api.On("SendEphemeralPost", "userID2", &model.Post{ChannelId: channelID, UserId: testutils.GetBotUserID(), Message: responseAddOptionInvalidPermission.Other}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     "userID2",
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					addOptionKey: "New Option",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{ChannelId: channelID, UserId: testutils.GetBotUserID(), Message: commandErrorGeneric.Other}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					addOptionKey: "New Option",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.SubmitDialogResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+			if test.ExpectedResponse != nil {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+			}
+		})
+	}
+}
+
+func TestHandleAddOptionDialogRequest(t *testing.T) {
+	userID := testutils.GetPollWithVotes().Creator
+	triggerID := model.NewId()
+	postID := model.NewId()
+
+	t.Run("not-authorized", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		request := &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add/request", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	})
+
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/option/add", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Add Option",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+			CallbackId:  postID,
+			SubmitLabel: "Add",
+			Elements: []model.DialogElement{{
+				DisplayName: "Option",
+				Name:        addOptionKey,
+				Type:        "text",
+				SubType:     "text",
+				HelpText:    "To describe the option or show an image next to it, add a description or image URL, e.g. \"Logo A|Our current logo|https://example.com/a.png\".",
+			},
+			},
+		},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    userID,
+				PostId:    postID,
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Request: &model.PostActionIntegrationRequest{
+				UserId:    userID,
+				PostId:    postID,
+				TriggerId: triggerID,
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add/request", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+			if test.ExpectedResponse != nil {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+			}
+		})
+	}
+}
+
+func TestHandleWriteIn(t *testing.T) {
+	userID := "userID5"
+	channelID := model.NewId()
+	postID := model.NewId()
+
+	pollIn := testutils.GetPollWithVotes()
+	pollIn.Settings.AllowOther = true
+
+	pollOut := pollIn.Copy()
+	require.Nil(t, pollOut.UpdateWriteIn(userID, "New Answer"))
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	updatedPollOut := pollIn.Copy()
+	require.Nil(t, updatedPollOut.UpdateWriteIn("userID4", "New Answer"))
+	expectedUpdatedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedUpdatedPost, updatedPollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	endedPoll := pollIn.Copy()
+	endedPoll.EndedAt = 1234567890
+
+	lockedPoll := pollIn.Copy()
+	lockedPoll.Settings.LockVotes = true
+	require.Nil(t, lockedPoll.UpdateWriteIn(userID, "Old Answer"))
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.SubmitDialogRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.SubmitDialogResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteCounted.Other,
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollIn.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					writeInKey: "New Answer",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, vote updated": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedUpdatedPost).Return(expectedUpdatedPost, nil)
+				api.On("SendEphemeralPost", "userID4", &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteUpdated.Other,
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollIn.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     "userID4",
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					writeInKey: "New Answer",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVotePollEnded.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					writeInKey: "New Answer",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, members only, not a member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", channelID, userID).Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteNotMember.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				membersOnlyPoll := pollIn.Copy()
+				membersOnlyPoll.ChannelID = channelID
+				membersOnlyPoll.Settings.MembersOnly = true
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(membersOnlyPoll))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					writeInKey: "New Answer",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteLocked.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					writeInKey: "New Answer",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Invalid request, empty answer": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollIn.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					writeInKey: "  ",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					writeInKey: "empty answer",
+				},
+			},
+		},
+		"Invalid request, write-ins not allowed": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					writeInKey: "New Answer",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					writeInKey: "poll doesn't allow other answers",
+				},
+			},
+		},
+		"Invalid request, missing submission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", test.Request.UserId).Return(&model.User{Username: "user"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/other", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.SubmitDialogResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+			if test.ExpectedResponse != nil {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+			}
+		})
+	}
+
+	t.Run("Blocked word", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+		api.On("GetUser", userID).Return(&model.User{Username: "user"}, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		blockedWords, err := parseBlockedWords("darn")
+		require.Nil(t, err)
+		p.setConfiguration(&configuration{BlockedWords: "darn", blockedWords: blockedWords})
+
+		request := &model.SubmitDialogRequest{
+			UserId:     userID,
+			CallbackId: postID,
+			ChannelId:  channelID,
+			Submission: map[string]interface{}{
+				writeInKey: "Darn it",
+			},
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/other", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+		r.Header.Add("Mattermost-User-ID", model.NewId())
+		p.ServeHTTP(nil, w, r)
+
+		result := w.Result()
+		require.NotNil(t, result)
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, &model.SubmitDialogResponse{
+			Errors: map[string]string{
+				writeInKey: limitErrorBlockedWords.Other,
+			},
+		}, model.SubmitDialogResponseFromJson(result.Body))
+	})
+}
+
+func TestHandleWriteInDialogRequest(t *testing.T) {
+	userID := "userID5"
+	triggerID := model.NewId()
+	postID := model.NewId()
+	channelID := model.NewId()
+
+	pollIn := testutils.GetPollWithVotes()
+	pollIn.Settings.AllowOther = true
+
+	votedPoll := pollIn.Copy()
+	require.Nil(t, votedPoll.UpdateWriteIn(userID, "Old Answer"))
+
+	lockedPoll := votedPoll.Copy()
+	lockedPoll.Settings.LockVotes = true
+
+	endedPoll := pollIn.Copy()
+	endedPoll.EndedAt = 1234567890
+
+	membersOnlyPoll := pollIn.Copy()
+	membersOnlyPoll.ChannelID = channelID
+	membersOnlyPoll.Settings.MembersOnly = true
+
+	makeDialogRequest := func(defaultAnswer, introductionText string) model.OpenDialogRequest {
+		return model.OpenDialogRequest{
+			TriggerId: triggerID,
+			URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/other", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+			Dialog: model.Dialog{
+				Title:            "Other Answer",
+				IntroductionText: introductionText,
+				IconURL:          fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+				CallbackId:       postID,
+				SubmitLabel:      "Vote",
+				Elements: []model.DialogElement{{
+					DisplayName: "Answer",
+					Name:        writeInKey,
+					Type:        "text",
+					SubType:     "text",
+					Default:     defaultAnswer,
+					MaxLength:   poll.WriteInMaxLength,
+					HelpText:    "Answers that only differ in case or spacing are counted together.",
+				}},
+			},
+		}
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", makeDialogRequest("", "")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, user has written in before": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", makeDialogRequest("Old Answer", "")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(votedPoll.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, lock votes, user hasn't voted yet": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", makeDialogRequest("", "Your answer is final and can't be changed afterwards.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				unvotedLockedPoll := pollIn.Copy()
+				unvotedLockedPoll.Settings.LockVotes = true
+				store.PollStore.On("Get", testutils.GetPollID()).Return(unvotedLockedPoll, nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteLocked.Other},
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, members only, not a member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", channelID, userID).Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(membersOnlyPoll.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteNotMember.Other},
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", makeDialogRequest("", "")).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/other/request", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+			if test.ExpectedResponse != nil {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+			}
+		})
+	}
+}
+
+func TestHandleRankOptions(t *testing.T) {
+	userID := "userID5"
+	channelID := model.NewId()
+	postID := model.NewId()
+
+	pollOut := testutils.GetPollWithRankings()
+	err := pollOut.UpdateRanking(userID, []int{2, 0})
+	require.Nil(t, err)
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	endedPoll := testutils.GetPollWithRankings()
+	endedPoll.EndedAt = 1234567890
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.SubmitDialogRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.SubmitDialogResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteCounted.Other,
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRankings(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithRankings()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rankOptionKeyPrefix + "0": "2",
+					rankOptionKeyPrefix + "1": "0",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVotePollEnded.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRankings(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rankOptionKeyPrefix + "0": "2",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, members only, not a member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("GetChannelMember", channelID, userID).Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteNotMember.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				membersOnlyPoll := testutils.GetPollWithRankings()
+				membersOnlyPoll.ChannelID = channelID
+				membersOnlyPoll.Settings.MembersOnly = true
+				store.PollStore.On("Get", testutils.GetPollID()).Return(membersOnlyPoll, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(membersOnlyPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rankOptionKeyPrefix + "0": "2",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRankings(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rankOptionKeyPrefix + "0": "2",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Duplicate choice": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRankings(), nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rankOptionKeyPrefix + "0": "2",
+					rankOptionKeyPrefix + "1": "2",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					rankOptionKeyPrefix + "1": dialogRankOptionsErrorDuplicate.Other,
+				},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/rank", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.SubmitDialogResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleRankOptionsDialogRequest(t *testing.T) {
+	userID := "userID2"
+	triggerID := model.NewId()
+	postID := model.NewId()
+
+	options := []*model.PostActionOptions{
+		{Text: "Answer 1", Value: "0"},
+		{Text: "Answer 2", Value: "1"},
+		{Text: "Answer 3", Value: "2"},
+	}
+	dialogRequest := model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/rank", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+		Dialog: model.Dialog{
+			Title:       "Rank Options",
+			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+			CallbackId:  postID,
+			SubmitLabel: "Vote",
+			Elements: []model.DialogElement{{
+				DisplayName: "Choice 1",
+				Name:        rankOptionKeyPrefix + "0",
+				Type:        "select",
+				Options:     options,
+				Default:     "1",
+			}, {
+				DisplayName: "Choice 2",
+				Name:        rankOptionKeyPrefix + "1",
+				Type:        "select",
+				Options:     options,
+				Optional:    true,
+				Default:     "0",
+			}, {
+				DisplayName: "Choice 3",
+				Name:        rankOptionKeyPrefix + "2",
+				Type:        "select",
+				Options:     options,
+				Optional:    true,
+			}},
+		},
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRankings(), nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				lockedPoll := testutils.GetPollWithRankings()
+				lockedPoll.Settings.LockVotes = true
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseVoteLocked.Other},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", userID).Return(&model.User{Username: "user2"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/rank/request", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleRateOptions(t *testing.T) {
+	userID := "userID5"
+	channelID := model.NewId()
+	postID := model.NewId()
+
+	pollOut := testutils.GetPollWithRatings()
+	err := pollOut.UpdateRating(userID, []int{4, 0, 2})
+	require.Nil(t, err)
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	endedPoll := testutils.GetPollWithRatings()
+	endedPoll.EndedAt = 1234567890
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.SubmitDialogRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.SubmitDialogResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteCounted.Other,
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRatings(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithRatings()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rateOptionKeyPrefix + "0": "4",
+					rateOptionKeyPrefix + "2": "2",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVotePollEnded.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRatings(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rateOptionKeyPrefix + "0": "4",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteLocked.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				lockedPoll := testutils.GetPollWithRatings()
+				lockedPoll.Settings.LockVotes = true
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     "userID1",
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rateOptionKeyPrefix + "0": "1",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRatings(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rateOptionKeyPrefix + "0": "4",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"No option rated": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRatings(), nil)
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					rateOptionKeyPrefix + "0": "",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					rateOptionKeyPrefix + "0": dialogRateOptionsErrorEmpty.Other,
+				},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", test.Request.UserId).Return(&model.User{Username: "user5"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/rate", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.SubmitDialogResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleRateOptionsDialogRequest(t *testing.T) {
+	userID := "userID2"
+	triggerID := model.NewId()
+	postID := model.NewId()
+
+	options := []*model.PostActionOptions{
+		{Text: "★★★★★", Value: "5"},
+		{Text: "★★★★", Value: "4"},
+		{Text: "★★★", Value: "3"},
+		{Text: "★★", Value: "2"},
+		{Text: "★", Value: "1"},
+	}
+	dialogRequest := func(introductionText string) model.OpenDialogRequest {
+		return model.OpenDialogRequest{
+			TriggerId: triggerID,
+			URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/rate", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+			Dialog: model.Dialog{
+				Title:            "Rate Options",
+				IconURL:          fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+				CallbackId:       postID,
+				SubmitLabel:      "Vote",
+				IntroductionText: introductionText,
+				Elements: []model.DialogElement{{
+					DisplayName: "Answer 1",
+					Name:        rateOptionKeyPrefix + "0",
+					Type:        "select",
+					Options:     options,
+					Optional:    true,
+					Default:     "4",
+				}, {
+					DisplayName: "Answer 2",
+					Name:        rateOptionKeyPrefix + "1",
+					Type:        "select",
+					Options:     options,
+					Optional:    true,
+					Default:     "3",
+				}, {
+					DisplayName: "Answer 3",
+					Name:        rateOptionKeyPrefix + "2",
+					Type:        "select",
+					Options:     options,
+					Optional:    true,
+					Default:     "1",
+				}},
+			},
+		}
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", dialogRequest(dialogRateOptionsIntroductionText.Other)).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithRatings(), nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, votes get locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				request := dialogRequest(dialogRateOptionsIntroductionText.Other + " " + dialogRateOptionsIntroductionTextLocked.Other)
+				for i := range request.Dialog.Elements {
+					request.Dialog.Elements[i].Default = ""
+				}
+				api.On("OpenInteractiveDialog", request).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				lockedPoll := testutils.GetPollWithRatings()
+				lockedPoll.Settings.LockVotes = true
+				delete(lockedPoll.Ratings, userID)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				lockedPoll := testutils.GetPollWithRatings()
+				lockedPoll.Settings.LockVotes = true
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseVoteLocked.Other},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", userID).Return(&model.User{Username: "user2"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/rate/request", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(http.StatusOK, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleEstimate(t *testing.T) {
+	userID := "userID5"
+	channelID := model.NewId()
+	postID := model.NewId()
+
+	pollIn := testutils.GetPollWithEstimates()
+
+	pollOut := pollIn.Copy()
+	require.Nil(t, pollOut.UpdateEstimate(userID, 2.5))
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	updatedPollOut := pollIn.Copy()
+	require.Nil(t, updatedPollOut.UpdateEstimate("userID4", 13))
+	expectedUpdatedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedUpdatedPost, updatedPollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	endedPoll := pollIn.Copy()
+	endedPoll.EndedAt = 1234567890
+
+	lockedPoll := pollIn.Copy()
+	lockedPoll.Settings.LockVotes = true
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.SubmitDialogRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.SubmitDialogResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteCounted.Other,
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollIn.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					estimateKey: "2.5",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, vote updated": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedUpdatedPost).Return(expectedUpdatedPost, nil)
+				api.On("SendEphemeralPost", "userID4", &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteUpdated.Other,
+				}).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollIn.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     "userID4",
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					estimateKey: "13",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVotePollEnded.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					estimateKey: "2.5",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", "userID4", &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   responseVoteLocked.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(lockedPoll.Copy()))
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     "userID4",
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					estimateKey: "13",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Invalid request, not a number": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					estimateKey: "a lot",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					estimateKey: "Invalid estimate a lot. Enter a number, e.g. 5 or 2.5",
+				},
+			},
+		},
+		"Invalid request, missing submission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+		"Invalid request, Store.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("SendEphemeralPost", userID, &model.Post{
+					ChannelId: channelID,
+					UserId:    testutils.GetBotUserID(),
+					Message:   commandErrorGeneric.Other,
+				}).Return(nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request: &model.SubmitDialogRequest{
+				UserId:     userID,
+				CallbackId: postID,
+				ChannelId:  channelID,
+				Submission: map[string]interface{}{
+					estimateKey: "2.5",
+				},
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", test.Request.UserId).Return(&model.User{Username: "user"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/estimate", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.SubmitDialogResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+			if test.ExpectedResponse != nil {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+			}
+		})
+	}
+}
+
+func TestHandleEstimateDialogRequest(t *testing.T) {
+	userID := "userID4"
+	triggerID := model.NewId()
+	postID := model.NewId()
+	channelID := model.NewId()
+
+	pollIn := testutils.GetPollWithEstimates()
+
+	lockedPoll := pollIn.Copy()
+	lockedPoll.Settings.LockVotes = true
+
+	endedPoll := pollIn.Copy()
+	endedPoll.EndedAt = 1234567890
+
+	membersOnlyPoll := pollIn.Copy()
+	membersOnlyPoll.ChannelID = channelID
+	membersOnlyPoll.Settings.MembersOnly = true
+
+	makeDialogRequest := func(defaultEstimate, introductionText string) model.OpenDialogRequest {
+		return model.OpenDialogRequest{
+			TriggerId: triggerID,
+			URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/estimate", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
+			Dialog: model.Dialog{
+				Title:            "Submit Estimate",
+				IntroductionText: introductionText,
+				IconURL:          fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+				CallbackId:       postID,
+				SubmitLabel:      "Vote",
+				Elements: []model.DialogElement{{
+					DisplayName: "Estimate",
+					Name:        estimateKey,
+					Type:        "text",
+					SubType:     "number",
+					Default:     defaultEstimate,
+					HelpText:    "Enter a number, e.g. 5 or 2.5.",
+				}},
+			},
+		}
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request, user has estimated before": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", makeDialogRequest("3", "")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, lock votes, user hasn't voted yet": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", makeDialogRequest("", "Your estimate is final and can't be changed afterwards.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				unvotedLockedPoll := lockedPoll.Copy()
+				delete(unvotedLockedPoll.Estimates, userID)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(unvotedLockedPoll, nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, vote is locked": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(lockedPoll.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteLocked.Other},
+		},
+		"Valid request, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, members only, not a member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", channelID, userID).Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(membersOnlyPoll.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteNotMember.Other},
+		},
+		"Valid request, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", makeDialogRequest("3", "")).Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn.Copy(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			api.On("GetUser", userID).Return(&model.User{Username: "user4"}, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/estimate/request", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+			if test.ExpectedResponse != nil {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+			}
+		})
+	}
+}
+
+func TestHandleEndPoll(t *testing.T) {
+	permalink := " [Jump to the poll](https://example.org/team1/pl/postID1)"
+	t.Run("not-authorized", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		request := &model.PostActionIntegrationRequest{UserId: "userID1", TeamId: "teamID1"}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+		p.ServeHTTP(nil, w, r)
+		result := w.Result()
+
+		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+	})
+
+	converter := func(userID string) (string, *model.AppError) {
+		switch userID {
+		case "userID1":
+			return "@user1", nil
+		case "userID2":
+			return "@user2", nil
+		case "userID3":
+			return "@user3", nil
+		case "userID4":
+			return "@user4", nil
+		default:
+			return "", &model.AppError{}
+		}
+	}
+	expectedPost, err := testutils.GetPollWithVotes().ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe", converter)
+	require.Nil(t, err)
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	pollWithQuorum := func() *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Quorum: 80})
+		p.ChannelID = "channelID1"
+		return p
+	}
+	expectedQuorumPost, err := func() *poll.Poll {
+		p := pollWithQuorum()
+		p.NumberOfEligibleVoters = 5
+		return p
+	}().ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe", converter)
+	require.Nil(t, err)
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request with quorum": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+				api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{IsBot: true}, nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(&model.ChannelMembers{
+					{UserId: "userID1"}, {UserId: "userID2"}, {UserId: "userID3"}, {UserId: "userID4"}, {UserId: "userID5"}, {UserId: testutils.GetBotUserID()},
+				}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollWithQuorum(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithQuorum()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedQuorumPost},
+		},
+		"Valid request with quorum, GetChannelMembers fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pollWithQuorum(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithQuorum()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other + permalink},
+		},
+		"Valid request with votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channel_id"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost},
+		},
+		"Valid request with votes, direct message": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channel_id"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			// Direct messages don't belong to a team
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost},
+		},
+		"Valid request with votes, pinned poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.ChannelId == "channelID1" && !post.IsPinned
+				})).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pinnedPoll := func() *poll.Poll {
+					p := testutils.GetPollWithVotes()
+					p.ChannelID = "channelID1"
+					p.Pinned = true
+					return p
+				}
+				store.PollStore.On("Get", testutils.GetPollID()).Return(pinnedPoll(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pinnedPoll()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request with votes, issuer is system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{
+					Username: "user2",
+					Roles:    model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID,
+				}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channel_id"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1", TeamId: "teamID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, GetUser fails for issuer": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseEndPollInvalidPermission.Other},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, poll ended in the meantime": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedPoll := testutils.GetPollWithVotes()
+				endedPoll.EndedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorEndAlreadyEnded.Other},
+		},
+		"Valid request, GetUser fails for poll creator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, GetUser fails for voter": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(testutils.GetPollWithVotes()))
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil).Maybe()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			defer patch.Unpatch()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			if result.StatusCode == http.StatusOK {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+				require.NotNil(t, response)
+				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
+				if test.ExpectedResponse.Update != nil {
+					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
+				}
+			} else {
+				assert.Equal(test.ExpectedResponse, response)
+			}
+		})
+	}
+}
+
+func TestPostEndPollAnnouncement(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI func(*plugintest.API) *plugintest.API
+		Request  *model.PostActionIntegrationRequest
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Message: testutils.GetLocalizer().MustLocalize(
+						&i18n.LocalizeConfig{
+							DefaultMessage: responseEndPollSuccessfully,
+							TemplateData: map[string]interface{}{
+								"Question": "Question",
+								"Link":     "https://example.org/team1/pl/postID1",
+							}}) + "\n\n" +
+						"**Winner**: Answer 1\n" +
+						"1. Answer 1: 3 votes (75%)\n" +
+						"2. Answer 2: 1 vote (25%)\n" +
+						"3. Answer 3: 0 votes (0%)",
+					Type: model.POST_DEFAULT,
+				}).Return(nil, nil)
+				return api
+			},
+			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+		},
+		"Valid request, GetTeam fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return(nil)
+				return api
+			},
+			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+		},
+		"Valid request, GetPost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetPost", "postID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return(nil)
+				return api
+			},
+			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+		},
+		"Valid request, CreatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return(nil)
+				return api
+			},
+			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := setupTestPlugin(t, test.SetupAPI(&plugintest.API{}), &mockstore.Store{})
+			p.postEndPollAnnouncement(test.Request.TeamId, test.Request.PostId, testutils.GetPollWithVotes())
+		})
+	}
+
+	expectedChart, err := testutils.GetPollWithVotes().ToResultsChart()
+	require.Nil(t, err)
+	for name, test := range map[string]struct {
+		UploadFileInfo  *model.FileInfo
+		UploadFileError *model.AppError
+		ExpectedFileIDs []string
+		ExpectedLogWarn bool
+	}{
+		"Results chart": {
+			UploadFileInfo:  &model.FileInfo{Id: "fileID1"},
+			ExpectedFileIDs: []string{"fileID1"},
+		},
+		"Results chart, UploadFile fails": {
+			UploadFileError: &model.AppError{},
+			ExpectedLogWarn: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+			api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+			api.On("UploadFile", expectedChart, "channelID1", fmt.Sprintf("poll-%s.png", testutils.GetPollID())).Return(test.UploadFileInfo, test.UploadFileError)
+			if test.ExpectedLogWarn {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+			}
+			api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+				return assert.ObjectsAreEqual(test.ExpectedFileIDs, []string(post.FileIds))
+			})).Return(nil, nil)
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+			p.setConfiguration(&configuration{ResultsChart: true})
+
+			p.postEndPollAnnouncement("teamID1", "postID1", testutils.GetPollWithVotes())
+		})
+	}
+
+	// The calendar invite contains the time it was created at
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+	expectedInvite, err := testutils.GetSchedulingPoll().ToICS(1)
+	require.Nil(t, err)
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		UploadFileInfo  *model.FileInfo
+		UploadFileError *model.AppError
+		ExpectedFileIDs []string
+		ExpectedLogWarn bool
+	}{
+		"Calendar invite": {
+			Poll:            testutils.GetSchedulingPoll(),
+			UploadFileInfo:  &model.FileInfo{Id: "fileID1"},
+			ExpectedFileIDs: []string{"fileID1"},
+		},
+		"Calendar invite, UploadFile fails": {
+			Poll:            testutils.GetSchedulingPoll(),
+			UploadFileError: &model.AppError{},
+			ExpectedLogWarn: true,
+		},
+		"Calendar invite, no votes": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetSchedulingPoll()
+				for _, o := range p.AnswerOptions {
+					o.Voter = nil
+				}
+				return p
+			}(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+			api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+			if test.UploadFileInfo != nil || test.UploadFileError != nil {
+				api.On("UploadFile", expectedInvite, "channelID1", fmt.Sprintf("poll-%s.ics", testutils.GetPollID())).Return(test.UploadFileInfo, test.UploadFileError)
+			}
+			if test.ExpectedLogWarn {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+			}
+			api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+				return assert.ObjectsAreEqual(test.ExpectedFileIDs, []string(post.FileIds))
+			})).Return(nil, nil)
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+
+			test.Poll.Settings.Invite = true
+			p.postEndPollAnnouncement("teamID1", "postID1", test.Poll)
+		})
+	}
+
+	for name, test := range map[string]struct {
+		ConfigurationTemplate string
+		PollTemplate          string
+		ExpectedMessage       string
+		ExpectedLogWarn       bool
+	}{
+		"Results template of the configuration": {
+			ConfigurationTemplate: "{{.Question}} has ended. The winner is {{.Winner}} with {{.TotalVotes}} votes: {{.Link}}",
+			ExpectedMessage:       "Question has ended. The winner is Answer 1 with 4 votes: https://example.org/team1/pl/postID1",
+		},
+		"Results template of the poll": {
+			ConfigurationTemplate: "{{.Question}} has ended.",
+			PollTemplate:          "And the winner is... {{.Winner}}!",
+			ExpectedMessage:       "And the winner is... Answer 1!",
+		},
+		"Results template fails": {
+			ConfigurationTemplate: "{{.Unknown}}",
+			ExpectedMessage: testutils.GetLocalizer().MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: responseEndPollSuccessfully,
+				TemplateData: map[string]interface{}{
+					"Question": "Question",
+					"Link":     "https://example.org/team1/pl/postID1",
+				}}) + "\n\n" + testutils.GetPollWithVotes().ToResultsSummary(testutils.GetLocalizer()),
+			ExpectedLogWarn: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+			api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+			if test.ExpectedLogWarn {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+			}
+			api.On("CreatePost", &model.Post{
+				UserId:    testutils.GetBotUserID(),
+				ChannelId: "channelID1",
+				RootId:    "postID1",
+				Message:   test.ExpectedMessage,
+				Type:      model.POST_DEFAULT,
+			}).Return(nil, nil)
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+			p.setConfiguration(&configuration{ResultsTemplate: test.ConfigurationTemplate})
+
+			endedPoll := testutils.GetPollWithVotes()
+			endedPoll.Settings.ResultsTemplate = test.PollTemplate
+			p.postEndPollAnnouncement("teamID1", "postID1", endedPoll)
+		})
+	}
+
+	t.Run("Direct message", func(t *testing.T) {
+		endedPoll := testutils.GetPollWithVotes()
+		api := &plugintest.API{}
+		api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+		api.On("CreatePost", &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "channelID1",
+			RootId:    "postID1",
+			Message: testutils.GetLocalizer().MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: responseEndPollSuccessfullyNoLink,
+				TemplateData:   map[string]interface{}{"Question": "Question"},
+			}) + "\n\n" + endedPoll.ToResultsSummary(testutils.GetLocalizer()),
+			Type: model.POST_DEFAULT,
+		}).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		// Direct and group messages don't belong to a team
+		p.postEndPollAnnouncement("", "postID1", endedPoll)
+	})
+
+	t.Run("Results chart, survey", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+		api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		p.setConfiguration(&configuration{ResultsChart: true})
+
+		p.postEndPollAnnouncement("teamID1", "postID1", testutils.GetSurveyWithVotes())
+	})
+}
+func TestHandleDeletePoll(t *testing.T) {
+	t.Run("not-authorized", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		request := &model.PostActionIntegrationRequest{UserId: "userID1", TeamId: "teamID1"}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/delete", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
 		p.ServeHTTP(nil, w, r)
 		result := w.Result()
 
 		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
 	})
 
-	localizer := testutils.GetLocalizer()
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request with no votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("DeletePost", "postID1").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Delete", testutils.GetPoll()).Return(nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseDeletePollSuccess.Other},
+		},
+		"Valid request with no votes, issuer is system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{
+					Username: "user2",
+					Roles:    model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID,
+				}, nil)
+				api.On("DeletePost", "postID1").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Delete", testutils.GetPoll()).Return(nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1", TeamId: "teamID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseDeletePollSuccess.Other},
+		},
+		"Valid request, Store.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, GetUser fails for issuer": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, Invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseDeletePollInvalidPermission.Other},
+		},
+		"Valid request, DeletePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("DeletePost", "postID1").Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, KVDelete fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("DeletePost", "postID1").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Delete", testutils.GetPoll()).Return(&model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
 
-	poll1In := testutils.GetPoll()
-	poll1Out := poll1In.Copy()
-	err := poll1Out.UpdateVote("userID1", 0)
-	require.Nil(t, err)
-	expectedPost1 := &model.Post{}
-	model.ParseSlackAttachment(expectedPost1, poll1Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
 
-	poll2In := testutils.GetPoll()
-	err = poll2In.UpdateVote("userID1", 0)
-	require.Nil(t, err)
-	poll2Out := poll2In.Copy()
-	err = poll2Out.UpdateVote("userID1", 1)
-	require.Nil(t, err)
-	expectedPost2 := &model.Post{}
-	model.ParseSlackAttachment(expectedPost2, poll2Out.ToPostActions(localizer, testutils.GetSiteURL(), manifest.ID, "John Doe"))
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/delete", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			if result.StatusCode == http.StatusOK {
+				assert.Equal(http.Header{
+					"Content-Type": []string{"application/json"},
+				}, result.Header)
+				require.NotNil(t, response)
+				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
+				if test.ExpectedResponse.Update != nil {
+					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
+				}
+			}
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestHandleExportPoll(t *testing.T) {
+	endedPoll := func() *poll.Poll {
+		p := testutils.GetPollWithVotes()
+		p.EndedAt = 1234567890
+		return p
+	}
+	expectedCSV := []byte("Answer,Votes,Voters\n" +
+		"Answer 1,3,\"@user1, @user2, @user3\"\n" +
+		"Answer 2,1,@user4\n" +
+		"Answer 3,0,\n")
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "channelID1",
+		Message:   "Here are the results of the poll **Question**.",
+		FileIds:   []string{"fileID1"},
+	}
+
+	setupUsers := func(api *plugintest.API) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		return api
+	}
 
 	for name, test := range map[string]struct {
 		SetupAPI           func(*plugintest.API) *plugintest.API
 		SetupStore         func(*mockstore.Store) *mockstore.Store
 		Request            *model.PostActionIntegrationRequest
-		VoteIndex          int
 		ExpectedStatusCode int
 		ExpectedResponse   *model.PostActionIntegrationResponse
 	}{
-		"Valid request with no votes": {
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("UploadFile", expectedCSV, "channelID1", fmt.Sprintf("poll-%s.csv", testutils.GetPollID())).Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", expectedPost).Return(expectedPost, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseExportPollSuccess.Other},
+		},
+		"Valid request, poll has not ended": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(poll1In, nil)
-				store.PollStore.On("Save", poll1Out).Return(nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			VoteIndex:          0,
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteCounted.Other, Update: expectedPost1},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseExportPollNotEnded.Other},
 		},
-		"Valid request with vote": {
+		"Valid request, Invalid permission": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(poll2In, nil)
-				store.PollStore.On("Save", poll2Out).Return(nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			VoteIndex:          1,
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVoteUpdated.Other, Update: expectedPost2},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseExportPollInvalidPermission.Other},
 		},
 		"Valid request, PollStore.Get fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
@@ -203,166 +5415,73 @@ func TestHandleVote(t *testing.T) {
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			VoteIndex:          1,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
 		},
-		"Valid request, PollStore.Save fails": {
+		"Valid request, GetUser fails for voter": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				pollIn := testutils.GetPoll()
-				pollOut := pollIn.Copy()
-				err := pollOut.UpdateVote("userID1", 0)
-				require.Nil(t, err)
-
-				store.PollStore.On("Get", testutils.GetPollID()).Return(pollIn, nil)
-				store.PollStore.On("Save", pollOut).Return(&model.AppError{})
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll(), nil)
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			VoteIndex:          0,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
 		},
-		"Invalid index": {
+		"Valid request, GetDirectChannel fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api = setupUsers(api)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll(), nil)
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			VoteIndex:          3,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
 		},
-		"Invalid request": {
-			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
-			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
-			Request:            nil,
-			VoteIndex:          0,
-			ExpectedStatusCode: http.StatusBadRequest,
-			ExpectedResponse:   nil,
-		},
-		"Valid request, GetUser fails": {
+		"Valid request, UploadFile fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api = setupUsers(api)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("UploadFile", expectedCSV, "channelID1", fmt.Sprintf("poll-%s.csv", testutils.GetPollID())).Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll(), nil)
 				return store
 			},
 			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			VoteIndex:          0,
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
 		},
-	} {
-		t.Run(name, func(t *testing.T) {
-			assert := assert.New(t)
-
-			api := test.SetupAPI(&plugintest.API{})
-			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
-			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
-			defer api.AssertExpectations(t)
-			store := test.SetupStore(&mockstore.Store{})
-			defer store.AssertExpectations(t)
-			p := setupTestPlugin(t, api, store)
-
-			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/%d", testutils.GetPollID(), test.VoteIndex), bytes.NewReader(test.Request.ToJson()))
-			r.Header.Add("Mattermost-User-ID", model.NewId())
-			p.ServeHTTP(nil, w, r)
-
-			result := w.Result()
-			require.NotNil(t, result)
-			response := model.PostActionIntegrationResponseFromJson(result.Body)
-
-			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
-			if result.StatusCode == http.StatusOK {
-				assert.Equal(http.Header{
-					"Content-Type": []string{"application/json"},
-				}, result.Header)
-				require.NotNil(t, response)
-				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
-				if test.ExpectedResponse.Update != nil {
-					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
-				}
-			} else {
-				assert.Equal(test.ExpectedResponse, response)
-			}
-		})
-	}
-}
-
-func TestHandleAddOption(t *testing.T) {
-	t.Run("not-authorized", func(t *testing.T) {
-		api := &plugintest.API{}
-		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
-		defer api.AssertExpectations(t)
-		p := setupTestPlugin(t, api, &mockstore.Store{})
-		request := &model.PostActionIntegrationRequest{UserId: "userID1", TeamId: "teamID1"}
-
-		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
-		p.ServeHTTP(nil, w, r)
-		result := w.Result()
-
-		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
-	})
-
-	userID := testutils.GetPollWithVotes().Creator
-	channelID := model.NewId()
-	postID := model.NewId()
-
-	responsePost := &model.Post{
-		ChannelId: channelID,
-		UserId:    testutils.GetBotUserID(),
-		Message:   responseAddOptionSuccess.Other,
-	}
-
-	poll1In := testutils.GetPollWithVotes()
-	poll1Out := poll1In.Copy()
-	err := poll1Out.AddAnswerOption("New Option")
-	require.Nil(t, err)
-	expectedPost1 := &model.Post{}
-	model.ParseSlackAttachment(expectedPost1, poll1Out.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
-
-	for name, test := range map[string]struct {
-		SetupAPI           func(*plugintest.API) *plugintest.API
-		SetupStore         func(*mockstore.Store) *mockstore.Store
-		Request            *model.SubmitDialogRequest
-		ExpectedStatusCode int
-		ExpectedResponse   *model.SubmitDialogResponse
-	}{
-		"Valid request": {
+		"Valid request, CreatePost fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
-				api.On("GetPost", postID).Return(&model.Post{}, nil)
-				api.On("UpdatePost", expectedPost1).Return(expectedPost1, nil)
-				api.On("SendEphemeralPost", userID, responsePost).Return(nil)
+				api = setupUsers(api)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("UploadFile", expectedCSV, "channelID1", fmt.Sprintf("poll-%s.csv", testutils.GetPollID())).Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", expectedPost).Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
-				store.PollStore.On("Save", poll1Out).Return(nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll(), nil)
 				return store
 			},
-			Request: &model.SubmitDialogRequest{
-				UserId:     userID,
-				CallbackId: postID,
-				ChannelId:  channelID,
-				Submission: map[string]interface{}{
-					addOptionKey: "New Option",
-				},
-			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
 			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
 			ExpectedResponse:   nil,
 		},
 	} {
@@ -371,125 +5490,110 @@ func TestHandleAddOption(t *testing.T) {
 
 			api := test.SetupAPI(&plugintest.API{})
 			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
 			defer api.AssertExpectations(t)
 			store := test.SetupStore(&mockstore.Store{})
 			defer store.AssertExpectations(t)
 			p := setupTestPlugin(t, api, store)
 
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/export", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
 			r.Header.Add("Mattermost-User-ID", model.NewId())
 			p.ServeHTTP(nil, w, r)
 
 			result := w.Result()
 			require.NotNil(t, result)
-			response := model.SubmitDialogResponseFromJson(result.Body)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
 
 			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
 			assert.Equal(test.ExpectedResponse, response)
-			if test.ExpectedResponse != nil {
-				assert.Equal(http.Header{
-					"Content-Type": []string{"application/json"},
-				}, result.Header)
-			}
 		})
 	}
 }
 
-func TestHandleAddOptionDialogRequest(t *testing.T) {
-	userID := testutils.GetPollWithVotes().Creator
-	triggerID := model.NewId()
-	postID := model.NewId()
-
-	t.Run("not-authorized", func(t *testing.T) {
-		api := &plugintest.API{}
-		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
-		defer api.AssertExpectations(t)
-		p := setupTestPlugin(t, api, &mockstore.Store{})
-		request := &model.PostActionIntegrationRequest{UserId: userID, PostId: postID, TriggerId: triggerID}
-
-		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add/request", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
-		p.ServeHTTP(nil, w, r)
-		result := w.Result()
-
-		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
-	})
-
-	dialogRequest := model.OpenDialogRequest{
-		TriggerId: triggerID,
-		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/option/add", testutils.GetSiteURL(), manifest.ID, testutils.GetPollID()),
-		Dialog: model.Dialog{
-			Title:       "Add Option",
-			IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
-			CallbackId:  postID,
-			SubmitLabel: "Add",
-			Elements: []model.DialogElement{{
-				DisplayName: "Option",
-				Name:        addOptionKey,
-				Type:        "text",
-				SubType:     "text",
-			},
-			},
-		},
+func TestHandleChangePage(t *testing.T) {
+	pagedPoll := func() *poll.Poll {
+		p := testutils.GetPollWithVotes()
+		p.PageSize = 2
+		return p
 	}
+	secondPage := pagedPoll()
+	secondPage.Page = 1
+	expectedPost := &model.Post{}
+	model.ParseSlackAttachment(expectedPost, secondPage.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "user1"))
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
 
 	for name, test := range map[string]struct {
-		SetupAPI           func(*plugintest.API) *plugintest.API
 		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Page               int
 		Request            *model.PostActionIntegrationRequest
 		ExpectedStatusCode int
 		ExpectedResponse   *model.PostActionIntegrationResponse
 	}{
 		"Valid request": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("OpenInteractiveDialog", dialogRequest).Return(nil)
-				return api
-			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pagedPoll()))
 				return store
 			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    userID,
-				PostId:    postID,
-				TriggerId: triggerID,
-			},
+			Page:               1,
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost},
 		},
-		"Valid request, OpenInteractiveDialog fails": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("OpenInteractiveDialog", dialogRequest).Return(&model.AppError{})
-				return api
+		"Valid request, page doesn't exist": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pagedPoll()))
+				return store
 			},
+			Page:               2,
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, poll has ended": {
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				endedPoll := pagedPoll()
+				endedPoll.EndedAt = 1234567890
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll))
 				return store
 			},
-			Request: &model.PostActionIntegrationRequest{
-				UserId:    userID,
-				PostId:    postID,
-				TriggerId: triggerID,
+			Page:               1,
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseVotePollEnded.Other},
+		},
+		"Valid request, PollStore.Update fails": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
 			},
+			Page:               1,
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
 		},
+		"Invalid request": {
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Page:               1,
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			assert := assert.New(t)
 
-			api := test.SetupAPI(&plugintest.API{})
+			api := &plugintest.API{}
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
 			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
 			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
-			api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
 			defer api.AssertExpectations(t)
 			store := test.SetupStore(&mockstore.Store{})
 			defer store.AssertExpectations(t)
 			p := setupTestPlugin(t, api, store)
 
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/option/add/request", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/page/%d", testutils.GetPollID(), test.Page), bytes.NewReader(test.Request.ToJson()))
 			r.Header.Add("Mattermost-User-ID", model.NewId())
 			p.ServeHTTP(nil, w, r)
 
@@ -498,48 +5602,43 @@ func TestHandleAddOptionDialogRequest(t *testing.T) {
 			response := model.PostActionIntegrationResponseFromJson(result.Body)
 
 			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
-			assert.Equal(test.ExpectedResponse, response)
-			if test.ExpectedResponse != nil {
-				assert.Equal(http.Header{
-					"Content-Type": []string{"application/json"},
-				}, result.Header)
-			}
-		})
-	}
-}
-
-func TestHandleEndPoll(t *testing.T) {
-	t.Run("not-authorized", func(t *testing.T) {
-		api := &plugintest.API{}
-		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
-		defer api.AssertExpectations(t)
-		p := setupTestPlugin(t, api, &mockstore.Store{})
-		request := &model.PostActionIntegrationRequest{UserId: "userID1", TeamId: "teamID1"}
-
-		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
-		p.ServeHTTP(nil, w, r)
-		result := w.Result()
+			if result.StatusCode == http.StatusOK {
+				require.NotNil(t, response)
+				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
+				if test.ExpectedResponse.Update != nil {
+					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
+				}
+			} else {
+				assert.Equal(test.ExpectedResponse, response)
+			}
+		})
+	}
+}
 
-		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
-	})
+func TestHandleRemindNonVoters(t *testing.T) {
+	permalink := " [Jump to the poll](https://example.org/team1/pl/postID1)"
+	members := &model.ChannelMembers{
+		{UserId: "userID1"},
+		{UserId: "userID2"},
+		{UserId: "userID3"},
+		{UserId: "userID4"},
+		{UserId: "userID5"},
+		{UserId: "userID6"},
+		{UserId: testutils.GetBotUserID()},
+	}
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "channelID2",
+		Message:   "You haven't voted in the poll **Question** yet. [Jump to the poll](https://example.org/team1/pl/postID1) to cast your vote.",
+	}
 
-	converter := func(userID string) (string, *model.AppError) {
-		switch userID {
-		case "userID1":
-			return "@user1", nil
-		case "userID2":
-			return "@user2", nil
-		case "userID3":
-			return "@user3", nil
-		case "userID4":
-			return "@user4", nil
-		default:
-			return "", &model.AppError{}
-		}
+	setupNonVoters := func(api *plugintest.API) *plugintest.API {
+		api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(members, nil)
+		api.On("GetUser", "userID5").Return(&model.User{Id: "userID5", Username: "user5"}, nil)
+		api.On("GetUser", "userID6").Return(&model.User{Id: "userID6", Username: "user6", DeleteAt: 1234567890}, nil)
+		api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{Id: testutils.GetBotUserID(), IsBot: true}, nil)
+		return api
 	}
-	expectedPost, err := testutils.GetPollWithVotes().ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
-	require.Nil(t, err)
 
 	for name, test := range map[string]struct {
 		SetupAPI           func(*plugintest.API) *plugintest.API
@@ -548,132 +5647,130 @@ func TestHandleEndPoll(t *testing.T) {
 		ExpectedStatusCode int
 		ExpectedResponse   *model.PostActionIntegrationResponse
 	}{
-		"Valid request with votes": {
+		"Valid request": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
-				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
-				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
-				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
-				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channel_id"}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api = setupNonVoters(api)
 				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
-				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("GetDirectChannel", "userID5", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", expectedPost).Return(expectedPost, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
-				store.PollStore.On("Delete", testutils.GetPollWithVotes()).Return(nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseRemindNonVotersSuccess.Other + permalink},
 		},
-		"Valid request with votes, issuer is system admin": {
+		"Valid request, group message": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
-				api.On("GetUser", "userID2").Return(&model.User{
-					Username: "user2",
-					Roles:    model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID,
-				}, nil)
-				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
-				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
-				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channel_id"}, nil)
-				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
-				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api = setupNonVoters(api)
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Message:   "You haven't voted in the poll **Question** yet. Go to the conversation it was posted in to cast your vote.",
+				}
+				api.On("GetDirectChannel", "userID5", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", post).Return(post, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
-				store.PollStore.On("Delete", testutils.GetPollWithVotes()).Return(nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1", TeamId: "teamID1"},
+			// Group messages don't belong to a team
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{Update: expectedPost},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseRemindNonVotersSuccess.Other},
 		},
-		"Valid request, PollStore.Get fails": {
+		"Valid request, everyone has voted": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(&model.ChannelMembers{{UserId: "userID1"}, {UserId: "userID4"}}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseRemindNonVotersNone.Other + permalink},
 		},
-		"Valid request, GetUser fails for issuer": {
+		"Valid request, Invalid permission": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
-				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseRemindNonVotersInvalidPermission.Other + permalink},
 		},
-		"Valid request, Invalid permission": {
+		"Valid request, PollStore.Get fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseEndPollInvalidPermission.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other + permalink},
 		},
-		"Valid request, PollStore.Delete fails": {
+		"Valid request, GetChannelMembers fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
-				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
-				api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
-				store.PollStore.On("Delete", testutils.GetPollWithVotes()).Return(&model.AppError{})
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other + permalink},
 		},
-		"Valid request, GetUser fails for poll creator": {
+		"Valid request, GetTeam fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api = setupNonVoters(api)
+				api.On("GetTeam", "teamID1").Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
 		},
-		"Valid request, GetUser fails for voter": {
+		"Valid request, GetDirectChannel fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				api = setupNonVoters(api)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetDirectChannel", "userID5", testutils.GetBotUserID()).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseRemindNonVotersSuccess.Other + permalink},
 		},
 		"Invalid request": {
 			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
@@ -688,6 +5785,7 @@ func TestHandleEndPoll(t *testing.T) {
 
 			api := test.SetupAPI(&plugintest.API{})
 			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil).Maybe()
 			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
 			defer api.AssertExpectations(t)
 			store := test.SetupStore(&mockstore.Store{})
@@ -695,7 +5793,7 @@ func TestHandleEndPoll(t *testing.T) {
 			p := setupTestPlugin(t, api, store)
 
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/end", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/remind", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
 			r.Header.Add("Mattermost-User-ID", model.NewId())
 			p.ServeHTTP(nil, w, r)
 
@@ -704,97 +5802,35 @@ func TestHandleEndPoll(t *testing.T) {
 			response := model.PostActionIntegrationResponseFromJson(result.Body)
 
 			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
-			if result.StatusCode == http.StatusOK {
-				assert.Equal(http.Header{
-					"Content-Type": []string{"application/json"},
-				}, result.Header)
-				require.NotNil(t, response)
-				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
-				if test.ExpectedResponse.Update != nil {
-					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
-				}
-			} else {
-				assert.Equal(test.ExpectedResponse, response)
-			}
+			assert.Equal(test.ExpectedResponse, response)
 		})
 	}
 }
 
-func TestPostEndPollAnnouncement(t *testing.T) {
-	for name, test := range map[string]struct {
-		SetupAPI func(*plugintest.API) *plugintest.API
-		Request  *model.PostActionIntegrationRequest
-	}{
-		"Valid request": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
-				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
-				api.On("CreatePost", &model.Post{
-					UserId:    testutils.GetBotUserID(),
-					ChannelId: "channelID1",
-					RootId:    "postID1",
-					Message: testutils.GetLocalizer().MustLocalize(
-						&i18n.LocalizeConfig{
-							DefaultMessage: responseEndPollSuccessfully,
-							TemplateData: map[string]interface{}{
-								"Question": "Question",
-								"Link":     "https://example.org/team1/pl/postID1",
-							}}),
-					Type: model.POST_DEFAULT,
-				}).Return(nil, nil)
-				return api
-			},
-			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
-		},
-		"Valid request, GetTeam fails": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetTeam", "teamID1").Return(nil, &model.AppError{})
-				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return(nil)
-				return api
-			},
-			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
-		},
-		"Valid request, GetPost fails": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
-				api.On("GetPost", "postID1").Return(nil, &model.AppError{})
-				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return(nil)
-				return api
-			},
-			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
-		},
-		"Valid request, CreatePost fails": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
-				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
-				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
-				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return(nil)
-				return api
-			},
-			Request: &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", TeamId: "teamID1"},
-		},
-	} {
-		t.Run(name, func(t *testing.T) {
-			p := setupTestPlugin(t, test.SetupAPI(&plugintest.API{}), &mockstore.Store{})
-			p.postEndPollAnnouncement(test.Request, "Question")
-		})
+func TestHandleShowNonVoters(t *testing.T) {
+	permalink := " [Jump to the poll](https://example.org/team1/pl/postID1)"
+	members := &model.ChannelMembers{
+		{UserId: "userID1"},
+		{UserId: "userID4"},
+		{UserId: "userID5"},
+		{UserId: "userID6"},
+		{UserId: "userID7"},
+		{UserId: testutils.GetBotUserID()},
+	}
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "channelID1",
+		Message:   "#### Question\n2 members of this channel haven't voted yet: @user5, @user7",
 	}
-}
-func TestHandleDeletePoll(t *testing.T) {
-	t.Run("not-authorized", func(t *testing.T) {
-		api := &plugintest.API{}
-		api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
-		defer api.AssertExpectations(t)
-		p := setupTestPlugin(t, api, &mockstore.Store{})
-		request := &model.PostActionIntegrationRequest{UserId: "userID1", TeamId: "teamID1"}
-
-		w := httptest.NewRecorder()
-		r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/delete", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
-		p.ServeHTTP(nil, w, r)
-		result := w.Result()
 
-		assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
-	})
+	setupNonVoters := func(api *plugintest.API) *plugintest.API {
+		api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(members, nil)
+		api.On("GetUser", "userID5").Return(&model.User{Id: "userID5", Username: "user5"}, nil)
+		api.On("GetUser", "userID6").Return(&model.User{Id: "userID6", Username: "user6", DeleteAt: 1234567890}, nil)
+		api.On("GetUser", "userID7").Return(&model.User{Id: "userID7", Username: "user7"}, nil)
+		api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{Id: testutils.GetBotUserID(), IsBot: true}, nil)
+		return api
+	}
 
 	for name, test := range map[string]struct {
 		SetupAPI           func(*plugintest.API) *plugintest.API
@@ -803,65 +5839,34 @@ func TestHandleDeletePoll(t *testing.T) {
 		ExpectedStatusCode int
 		ExpectedResponse   *model.PostActionIntegrationResponse
 	}{
-		"Valid request with no votes": {
+		"Valid request": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("DeletePost", "postID1").Return(nil)
-				return api
-			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				store.PollStore.On("Delete", testutils.GetPoll()).Return(nil)
-				return store
-			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseDeletePollSuccess.Other},
-		},
-		"Valid request with no votes, issuer is system admin": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID2").Return(&model.User{
-					Username: "user2",
-					Roles:    model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID,
-				}, nil)
-				api.On("DeletePost", "postID1").Return(nil)
+				api = setupNonVoters(api)
+				api.On("SendEphemeralPost", "userID1", expectedPost).Return(nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				store.PollStore.On("Delete", testutils.GetPoll()).Return(nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1", TeamId: "teamID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseDeletePollSuccess.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
 		},
-		"Valid request, Store.Get fails": {
+		"Valid request, everyone has voted": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(&model.ChannelMembers{{UserId: "userID1"}, {UserId: "userID4"}}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
-				return store
-			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
-			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
-		},
-		"Valid request, GetUser fails for issuer": {
-			SetupAPI: func(api *plugintest.API) *plugintest.API {
-				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
-				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
-				return api
-			},
-			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseRemindNonVotersNone.Other + permalink},
 		},
 		"Valid request, Invalid permission": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
@@ -869,41 +5874,39 @@ func TestHandleDeletePoll(t *testing.T) {
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID2", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseDeletePollInvalidPermission.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseShowNonVotersInvalidPermission.Other + permalink},
 		},
-		"Valid request, DeletePost fails": {
+		"Valid request, PollStore.Get fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("DeletePost", "postID1").Return(&model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other + permalink},
 		},
-		"Valid request, KVDelete fails": {
+		"Valid request, GetChannelMembers fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
-				api.On("DeletePost", "postID1").Return(nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(nil, &model.AppError{})
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
-				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
-				store.PollStore.On("Delete", testutils.GetPoll()).Return(&model.AppError{})
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
 				return store
 			},
-			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1", ChannelId: "channelID1", TeamId: "teamID1"},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other + permalink},
 		},
 		"Invalid request": {
 			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
@@ -918,6 +5921,7 @@ func TestHandleDeletePoll(t *testing.T) {
 
 			api := test.SetupAPI(&plugintest.API{})
 			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil).Maybe()
 			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
 			defer api.AssertExpectations(t)
 			store := test.SetupStore(&mockstore.Store{})
@@ -925,7 +5929,7 @@ func TestHandleDeletePoll(t *testing.T) {
 			p := setupTestPlugin(t, api, store)
 
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/delete", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/nonvoters", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
 			r.Header.Add("Mattermost-User-ID", model.NewId())
 			p.ServeHTTP(nil, w, r)
 
@@ -934,16 +5938,6 @@ func TestHandleDeletePoll(t *testing.T) {
 			response := model.PostActionIntegrationResponseFromJson(result.Body)
 
 			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
-			if result.StatusCode == http.StatusOK {
-				assert.Equal(http.Header{
-					"Content-Type": []string{"application/json"},
-				}, result.Header)
-				require.NotNil(t, response)
-				assert.Equal(test.ExpectedResponse.EphemeralText, response.EphemeralText)
-				if test.ExpectedResponse.Update != nil {
-					assert.Equal(test.ExpectedResponse.Update.Attachments(), response.Update.Attachments())
-				}
-			}
 			assert.Equal(test.ExpectedResponse, response)
 		})
 	}
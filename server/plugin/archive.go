@@ -0,0 +1,44 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/pkg/errors"
+)
+
+// archiveInterval is the time between two runs of the job that archives ended polls
+const archiveInterval = 24 * time.Hour
+
+// scheduleArchive stores the job that archives ended polls, which runs right away and then once a day.
+// Every plugin instance schedules it on activation. They all store the same job, hence it exists only once.
+func (p *MatterpollPlugin) scheduleArchive() error {
+	return p.Store.Job().Save(job.NewJob(job.TypeArchivePolls, "", model.GetMillis()))
+}
+
+// archivePolls archives all polls that ended more than the configured number of days ago.
+// It returns the next run of the job, which is scheduled even if archiving is disabled, so enabling it takes effect without a restart.
+func (p *MatterpollPlugin) archivePolls(j *job.Job) (*job.Job, error) {
+	next := job.NewJob(job.TypeArchivePolls, "", model.GetMillis()+int64(archiveInterval/time.Millisecond))
+
+	days := p.getConfiguration().archiveAfterDays
+	if days == 0 {
+		return next, nil
+	}
+	cutoff := model.GetMillis() - int64(time.Duration(days)*24*time.Hour/time.Millisecond)
+
+	polls, err := p.Store.Poll().List()
+	if err != nil {
+		return next, errors.Wrap(err, "failed to list polls")
+	}
+	for _, listedPoll := range polls {
+		if !listedPoll.IsEnded() || listedPoll.EndedAt > cutoff || listedPoll.IsDeleted() {
+			continue
+		}
+		if err := p.Store.Poll().Archive(listedPoll); err != nil {
+			return next, errors.Wrapf(err, "failed to archive poll %s", listedPoll.ID)
+		}
+	}
+	return next, nil
+}
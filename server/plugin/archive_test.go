@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleArchive(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	store := &mockstore.Store{}
+	store.JobStore.On("Save", job.NewJob(job.TypeArchivePolls, "", 1234567890)).Return(nil)
+	defer store.AssertExpectations(t)
+	p := setupTestPlugin(t, &plugintest.API{}, store)
+
+	assert.Nil(t, p.scheduleArchive())
+}
+
+func TestArchivePolls(t *testing.T) {
+	now := int64(1234567890 + 40*millisPerDay)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	next := job.NewJob(job.TypeArchivePolls, "", now+millisPerDay)
+	oldPoll := testutils.GetPollWithVotes()
+	oldPoll.EndedAt = 1234567890
+	recentPoll := testutils.GetPollWithVotes()
+	recentPoll.ID = "pollID2"
+	recentPoll.EndedAt = now - millisPerDay
+	runningPoll := testutils.GetPoll()
+	runningPoll.ID = "pollID3"
+
+	for name, test := range map[string]struct {
+		ArchiveAfterDays string
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		ShouldError      bool
+	}{
+		"Archiving disabled": {
+			ArchiveAfterDays: "",
+			SetupStore:       func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"Only polls that ended long ago get archived": {
+			ArchiveAfterDays: "30",
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{oldPoll, recentPoll, runningPoll}, nil)
+				store.PollStore.On("Archive", oldPoll).Return(nil)
+				return store
+			},
+		},
+		"PollStore.List fails": {
+			ArchiveAfterDays: "30",
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+		"PollStore.Archive fails": {
+			ArchiveAfterDays: "30",
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{oldPoll}, nil)
+				store.PollStore.On("Archive", oldPoll).Return(errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, &plugintest.API{}, store)
+			days, err := parseLimit("days", test.ArchiveAfterDays)
+			require.Nil(t, err)
+			p.setConfiguration(&configuration{Trigger: "poll", archiveAfterDays: days})
+
+			j, err := p.archivePolls(job.NewJob(job.TypeArchivePolls, "", now))
+			assert.Equal(t, next, j)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
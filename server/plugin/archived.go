@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/pkg/errors"
+)
+
+// archivedChannelsInterval is the time between two runs of the job that ends the polls of archived channels
+const archivedChannelsInterval = time.Hour
+
+// scheduleEndArchivedPolls stores the job that ends the running polls of archived channels, which runs right away and then once an hour.
+// The plugin API has no hook for archived channels, hence the job looks for them.
+// Every plugin instance schedules it on activation. They all store the same job, hence it exists only once.
+func (p *MatterpollPlugin) scheduleEndArchivedPolls() error {
+	return p.Store.Job().Save(job.NewJob(job.TypeEndArchivedPolls, "", model.GetMillis()))
+}
+
+// endArchivedPolls ends all running polls whose channel got archived, so they don't stay open forever.
+// A poll that can't be ended doesn't keep the others from ending. The job returns its next run.
+func (p *MatterpollPlugin) endArchivedPolls(j *job.Job) (*job.Job, error) {
+	next := job.NewJob(job.TypeEndArchivedPolls, "", model.GetMillis()+int64(archivedChannelsInterval/time.Millisecond))
+
+	polls, err := p.Store.Poll().List()
+	if err != nil {
+		return next, errors.Wrap(err, "failed to list polls")
+	}
+	archived := map[string]bool{}
+	for _, listedPoll := range polls {
+		if listedPoll.IsEnded() || listedPoll.IsScheduled() || listedPoll.IsDeleted() || listedPoll.ChannelID == "" {
+			continue
+		}
+		isArchived, ok := archived[listedPoll.ChannelID]
+		if !ok {
+			channel, appErr := p.API.GetChannel(listedPoll.ChannelID)
+			if appErr != nil {
+				p.API.LogWarn("failed to get channel of poll", "pollID", listedPoll.ID, "error", appErr.Error())
+				continue
+			}
+			isArchived = channel.DeleteAt != 0
+			archived[listedPoll.ChannelID] = isArchived
+		}
+		if !isArchived {
+			continue
+		}
+		if err := p.endArchivedPoll(listedPoll.ID); err != nil {
+			p.API.LogWarn("failed to end poll of archived channel", "pollID", listedPoll.ID, "error", err.Error())
+		}
+	}
+	return next, nil
+}
+
+// endArchivedPoll ends a poll whose channel got archived and stops its jobs, including its recurrence.
+// Archived channels are read-only, hence the poll post keeps its buttons and the results aren't announced.
+func (p *MatterpollPlugin) endArchivedPoll(pollID string) error {
+	endedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if latest.IsEnded() {
+			return errors.New("poll has already ended")
+		}
+		return p.endLatestPoll(latest)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to end poll")
+	}
+	p.publishPollEvent(websocketEventPollEnded, endedPoll)
+	p.notifyWebhook(webhookEventPollEnded, endedPoll, "")
+	p.recordAudit(audit.ActionPollEnded, endedPoll, "", "")
+
+	if err := p.unscheduleEnd(endedPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll end", "pollID", pollID, "error", err.Error())
+	}
+	if err := p.unscheduleRepeat(endedPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll recurrence", "pollID", pollID, "error", err.Error())
+	}
+	if err := p.unscheduleDigest(endedPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll digest", "pollID", pollID, "error", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScheduleEndArchivedPolls(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	store := &mockstore.Store{}
+	store.JobStore.On("Save", job.NewJob(job.TypeEndArchivedPolls, "", 1234567890)).Return(nil)
+	defer store.AssertExpectations(t)
+	p := setupTestPlugin(t, &plugintest.API{}, store)
+
+	assert.Nil(t, p.scheduleEndArchivedPolls())
+}
+
+func TestEndArchivedPolls(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	next := job.NewJob(job.TypeEndArchivedPolls, "", now+int64(time.Hour/time.Millisecond))
+	inChannel := func(p *poll.Poll, pollID, channelID string) *poll.Poll {
+		p.ID = pollID
+		p.PostID = "postOf" + pollID
+		p.ChannelID = channelID
+		return p
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		ShouldError bool
+	}{
+		"Only running polls of archived channels get ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", DeleteAt: now}, nil).Once()
+				api.On("GetChannel", "channelID2").Return(&model.Channel{Id: "channelID2"}, nil).Once()
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				archivedPoll := inChannel(testutils.GetPoll(), "pollID1", "channelID1")
+				secondArchivedPoll := inChannel(testutils.GetPollWithVotes(), "pollID2", "channelID1")
+				runningPoll := inChannel(testutils.GetPoll(), "pollID3", "channelID2")
+				endedPoll := inChannel(testutils.GetPoll(), "pollID4", "channelID1")
+				endedPoll.EndedAt = now - 1
+				scheduledPoll := testutils.GetPollWithSettings(poll.Settings{PostAt: now + 1})
+				scheduledPoll.ChannelID = "channelID1"
+
+				store.PollStore.On("List").Return([]*poll.Poll{archivedPoll, secondArchivedPoll, runningPoll, endedPoll, scheduledPoll}, nil)
+				store.PollStore.On("Update", "pollID1", updateFunc).Return(GetMockPollUpdate(archivedPoll))
+				store.PollStore.On("Update", "pollID2", updateFunc).Return(GetMockPollUpdate(secondArchivedPoll))
+				return store
+			},
+		},
+		"Failing polls don't keep the others from ending": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID1").Return(nil, &model.AppError{})
+				api.On("GetChannel", "channelID2").Return(&model.Channel{Id: "channelID2", DeleteAt: now}, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				unknownChannelPoll := inChannel(testutils.GetPoll(), "pollID1", "channelID1")
+				failingPoll := inChannel(testutils.GetPoll(), "pollID2", "channelID2")
+				archivedPoll := inChannel(testutils.GetPoll(), "pollID3", "channelID2")
+
+				store.PollStore.On("List").Return([]*poll.Poll{unknownChannelPoll, failingPoll, archivedPoll}, nil)
+				store.PollStore.On("Update", "pollID2", updateFunc).Return(nil, errors.New(""))
+				store.PollStore.On("Update", "pollID3", updateFunc).Return(GetMockPollUpdate(archivedPoll))
+				return store
+			},
+		},
+		"PollStore.List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			j, err := p.endArchivedPolls(job.NewJob(job.TypeEndArchivedPolls, "", now))
+			assert.Equal(t, next, j)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
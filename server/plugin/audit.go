@@ -0,0 +1,290 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxListedAuditEntries is the number of audit entries listed by /poll audit. Older entries are only part of the export.
+	maxListedAuditEntries = 50
+)
+
+var (
+	auditActionPollCreated = &i18n.Message{
+		ID:    "audit.action.pollCreated",
+		Other: "created the poll",
+	}
+	auditActionVoted = &i18n.Message{
+		ID:    "audit.action.voted",
+		Other: "voted",
+	}
+	auditActionVoteChanged = &i18n.Message{
+		ID:    "audit.action.voteChanged",
+		Other: "changed their vote",
+	}
+	auditActionOptionAdded = &i18n.Message{
+		ID:    "audit.action.optionAdded",
+		Other: "added an answer option",
+	}
+	auditActionPollEnded = &i18n.Message{
+		ID:    "audit.action.pollEnded",
+		Other: "ended the poll",
+	}
+	auditActionPollDeleted = &i18n.Message{
+		ID:    "audit.action.pollDeleted",
+		Other: "deleted the poll",
+	}
+	auditActionOwnershipTransferred = &i18n.Message{
+		ID:    "audit.action.ownershipTransferred",
+		Other: "transferred the poll",
+	}
+	auditAnonymousUser = &i18n.Message{
+		ID:    "audit.anonymousUser",
+		Other: "An anonymous user",
+	}
+
+	auditListHeading = &i18n.Message{
+		ID:    "audit.list.heading",
+		Other: "Audit log of poll `{{.ID}}`:",
+	}
+	auditListEntry = &i18n.Message{
+		ID:    "audit.list.entry",
+		Other: "- {{.Time}} UTC: {{.User}} {{.Action}}",
+	}
+	auditListTruncated = &i18n.Message{
+		ID:    "audit.list.truncated",
+		Other: "Only the latest {{.Count}} of {{.Total}} entries are shown. Type `/{{.Trigger}} audit {{.ID}} --export` to get all of them.",
+	}
+	auditListNone = &i18n.Message{
+		ID:    "audit.list.none",
+		Other: "There are no audit entries for this poll. Entries are only recorded while **Enable Audit Log** is turned on in the plugin settings.",
+	}
+
+	auditExportHeaderTime = &i18n.Message{
+		ID:    "audit.export.header.time",
+		Other: "Time",
+	}
+	auditExportHeaderUser = &i18n.Message{
+		ID:    "audit.export.header.user",
+		Other: "User",
+	}
+	auditExportHeaderAction = &i18n.Message{
+		ID:    "audit.export.header.action",
+		Other: "Action",
+	}
+	auditExportHeaderDetails = &i18n.Message{
+		ID:    "audit.export.header.details",
+		Other: "Details",
+	}
+	auditExportPostMessage = &i18n.Message{
+		ID:    "audit.export.post.message",
+		Other: "Here is the audit log of poll `{{.ID}}`.",
+	}
+	auditExportSuccess = &i18n.Message{
+		ID:    "audit.export.success",
+		Other: "The audit log has been sent to you as a direct message.",
+	}
+)
+
+// auditActionMessages maps all actions to their description in audit lists
+var auditActionMessages = map[audit.Action]*i18n.Message{
+	audit.ActionPollCreated:          auditActionPollCreated,
+	audit.ActionVoted:                auditActionVoted,
+	audit.ActionVoteChanged:          auditActionVoteChanged,
+	audit.ActionOptionAdded:          auditActionOptionAdded,
+	audit.ActionPollEnded:            auditActionPollEnded,
+	audit.ActionPollDeleted:          auditActionPollDeleted,
+	audit.ActionOwnershipTransferred: auditActionOwnershipTransferred,
+}
+
+// recordAudit appends an action of a user to the audit trail of a poll, if the audit log is enabled.
+// Actions without a user were taken by Matterpoll itself. Failures are only logged.
+func (p *MatterpollPlugin) recordAudit(action audit.Action, poll *poll.Poll, userID, details string) {
+	if !p.getConfiguration().EnableAuditLog {
+		return
+	}
+
+	if userID == "" {
+		userID = p.botUserID
+	}
+	// The audit log must not reveal what the poll settings hide
+	isVote := action == audit.ActionVoted || action == audit.ActionVoteChanged || action == audit.ActionVoteDelegated
+	if (isVote && poll.Settings.Anonymous) || (!isVote && userID == poll.Creator && poll.Settings.AnonymousCreator) {
+		userID = ""
+	}
+
+	if err := p.Store.Audit().Save(audit.NewEntry(poll.ID, userID, action, details)); err != nil {
+		p.API.LogWarn("failed to save audit entry", "action", string(action), "error", err.Error())
+	}
+}
+
+// voteAuditAction returns the action of a vote, depending on whether the user had voted before
+func voteAuditAction(hasVoted bool) audit.Action {
+	if hasVoted {
+		return audit.ActionVoteChanged
+	}
+	return audit.ActionVoted
+}
+
+// votedAnswers returns the answer options a given user votes for, separated by commas
+func votedAnswers(votedPoll *poll.Poll, userID string) string {
+	answers := []string{}
+	for i, o := range votedPoll.AnswerOptions {
+		if votedPoll.HasVotedFor(userID, i) {
+			answers = append(answers, o.Answer)
+		}
+	}
+	return strings.Join(answers, ", ")
+}
+
+// rankedAnswers returns the answer options of a ranking, most preferred first
+func rankedAnswers(rankedPoll *poll.Poll, ranking []int) string {
+	answers := []string{}
+	for _, index := range ranking {
+		answers = append(answers, rankedPoll.AnswerOptions[index].Answer)
+	}
+	return strings.Join(answers, " > ")
+}
+
+// ratedAnswers returns the scores a voter gave the answer options of a rating poll, leaving out options without a score
+func ratedAnswers(ratedPoll *poll.Poll, scores []int) string {
+	answers := []string{}
+	for i, score := range scores {
+		if score > 0 && i < len(ratedPoll.AnswerOptions) {
+			answers = append(answers, fmt.Sprintf("%s: %d", ratedPoll.AnswerOptions[i].Answer, score))
+		}
+	}
+	return strings.Join(answers, ", ")
+}
+
+// formatAuditUser returns the display name of the user of an audit entry
+func (p *MatterpollPlugin) formatAuditUser(entry *audit.Entry, localizer *i18n.Localizer) (string, *model.AppError) {
+	if entry.UserID == "" {
+		return p.LocalizeDefaultMessage(localizer, auditAnonymousUser), nil
+	}
+	return p.ConvertUserIDToDisplayName(entry.UserID)
+}
+
+// formatAuditTime returns the time of an audit entry in UTC using a given layout
+func formatAuditTime(entry *audit.Entry, layout string) string {
+	return time.Unix(0, entry.CreatedAt*int64(time.Millisecond)).UTC().Format(layout)
+}
+
+// listAuditEntries returns a message that lists the latest audit entries of a given poll, oldest first
+func (p *MatterpollPlugin) listAuditEntries(pollID string, userLocalizer *i18n.Localizer, trigger string) (string, error) {
+	entries, err := p.Store.Audit().ListByPoll(pollID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list audit entries")
+	}
+	if len(entries) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, auditListNone), nil
+	}
+
+	lines := []string{p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: auditListHeading,
+		TemplateData:   map[string]interface{}{"ID": pollID},
+	})}
+	listed := entries
+	if len(listed) > maxListedAuditEntries {
+		listed = listed[len(listed)-maxListedAuditEntries:]
+	}
+	for _, entry := range listed {
+		user, appErr := p.formatAuditUser(entry, userLocalizer)
+		if appErr != nil {
+			return "", errors.Wrap(appErr, "failed to get display name")
+		}
+		line := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: auditListEntry,
+			TemplateData: map[string]interface{}{
+				"Time":   formatAuditTime(entry, poll.TimeLayout),
+				"User":   user,
+				"Action": p.localizeAuditAction(userLocalizer, entry.Action),
+			},
+		})
+		if entry.Details != "" {
+			line += ": " + entry.Details
+		}
+		lines = append(lines, line)
+	}
+	if len(listed) < len(entries) {
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: auditListTruncated,
+			TemplateData: map[string]interface{}{
+				"Count":   len(listed),
+				"Total":   len(entries),
+				"Trigger": trigger,
+				"ID":      pollID,
+			},
+		}))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// localizeAuditAction returns the description of a given action. Unknown actions are returned as they are.
+func (p *MatterpollPlugin) localizeAuditAction(localizer *i18n.Localizer, action audit.Action) string {
+	m, ok := auditActionMessages[action]
+	if !ok {
+		return string(action)
+	}
+	return p.LocalizeDefaultMessage(localizer, m)
+}
+
+// exportAuditEntries sends all audit entries of a given poll as CSV file to a given user via direct message
+func (p *MatterpollPlugin) exportAuditEntries(pollID, userID string, userLocalizer *i18n.Localizer) (*i18n.Message, error) {
+	entries, err := p.Store.Audit().ListByPoll(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to list audit entries")
+	}
+	if len(entries) == 0 {
+		return auditListNone, nil
+	}
+
+	records := [][]string{{
+		p.LocalizeDefaultMessage(userLocalizer, auditExportHeaderTime),
+		p.LocalizeDefaultMessage(userLocalizer, auditExportHeaderUser),
+		p.LocalizeDefaultMessage(userLocalizer, auditExportHeaderAction),
+		p.LocalizeDefaultMessage(userLocalizer, auditExportHeaderDetails),
+	}}
+	for _, entry := range entries {
+		user, appErr := p.formatAuditUser(entry, userLocalizer)
+		if appErr != nil {
+			return commandErrorGeneric, errors.Wrap(appErr, "failed to get display name")
+		}
+		records = append(records, []string{formatAuditTime(entry, time.RFC3339), user, string(entry.Action), entry.Details})
+	}
+	var b bytes.Buffer
+	_ = csv.NewWriter(&b).WriteAll(records)
+
+	channel, appErr := p.API.GetDirectChannel(userID, p.botUserID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to get direct channel")
+	}
+	fileInfo, appErr := p.API.UploadFile(b.Bytes(), channel.Id, fmt.Sprintf("poll-%s-audit.csv", pollID))
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to upload file")
+	}
+
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: auditExportPostMessage,
+			TemplateData:   map[string]interface{}{"ID": pollID},
+		}),
+		FileIds: []string{fileInfo.Id},
+	}
+	if _, appErr = p.API.CreatePost(post); appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to create post")
+	}
+	return auditExportSuccess, nil
+}
@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAudit(t *testing.T) {
+	patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	patch2 := monkey.Patch(model.NewId, func() string { return "entryID1" })
+	defer patch1.Unpatch()
+	defer patch2.Unpatch()
+
+	entry := func(userID string, action audit.Action, details string) *audit.Entry {
+		return &audit.Entry{ID: "entryID1", PollID: testutils.GetPollID(), UserID: userID, Action: action, Details: details, CreatedAt: 1234567890}
+	}
+
+	for name, test := range map[string]struct {
+		Disabled   bool
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		Poll       *poll.Poll
+		Action     audit.Action
+		UserID     string
+		Details    string
+		SaveError  error
+		ExpectSave *audit.Entry
+	}{
+		"Audit log disabled": {
+			Disabled:   true,
+			Poll:       testutils.GetPoll(),
+			Action:     audit.ActionPollCreated,
+			UserID:     "userID1",
+			ExpectSave: nil,
+		},
+		"Vote": {
+			Poll:       testutils.GetPoll(),
+			Action:     audit.ActionVoted,
+			UserID:     "userID2",
+			Details:    "Answer 1",
+			ExpectSave: entry("userID2", audit.ActionVoted, "Answer 1"),
+		},
+		"Vote in anonymous poll": {
+			Poll:       testutils.GetPollWithSettings(poll.Settings{Anonymous: true}),
+			Action:     audit.ActionVoteChanged,
+			UserID:     "userID2",
+			Details:    "Answer 1",
+			ExpectSave: entry("", audit.ActionVoteChanged, "Answer 1"),
+		},
+		"Anonymous poll ended by creator": {
+			Poll:       testutils.GetPollWithSettings(poll.Settings{Anonymous: true}),
+			Action:     audit.ActionPollEnded,
+			UserID:     "userID1",
+			ExpectSave: entry("userID1", audit.ActionPollEnded, ""),
+		},
+		"Poll with anonymous creator ended by creator": {
+			Poll:       testutils.GetPollWithSettings(poll.Settings{AnonymousCreator: true}),
+			Action:     audit.ActionPollEnded,
+			UserID:     "userID1",
+			ExpectSave: entry("", audit.ActionPollEnded, ""),
+		},
+		"Poll with anonymous creator deleted by another user": {
+			Poll:       testutils.GetPollWithSettings(poll.Settings{AnonymousCreator: true}),
+			Action:     audit.ActionPollDeleted,
+			UserID:     "userID2",
+			ExpectSave: entry("userID2", audit.ActionPollDeleted, ""),
+		},
+		"Poll with anonymous creator transferred by creator": {
+			Poll:       testutils.GetPollWithSettings(poll.Settings{AnonymousCreator: true}),
+			Action:     audit.ActionOwnershipTransferred,
+			UserID:     "userID1",
+			Details:    "@user2",
+			ExpectSave: entry("", audit.ActionOwnershipTransferred, "@user2"),
+		},
+		"Poll ended by its deadline": {
+			Poll:       testutils.GetPoll(),
+			Action:     audit.ActionPollEnded,
+			UserID:     "",
+			ExpectSave: entry(testutils.GetBotUserID(), audit.ActionPollEnded, ""),
+		},
+		"AuditStore.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			Poll:       testutils.GetPoll(),
+			Action:     audit.ActionPollCreated,
+			UserID:     "userID1",
+			Details:    "Question",
+			SaveError:  errors.New(""),
+			ExpectSave: entry("userID1", audit.ActionPollCreated, "Question"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			if test.SetupAPI != nil {
+				api = test.SetupAPI(api)
+			}
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			if test.ExpectSave != nil {
+				store.AuditStore.On("Save", test.ExpectSave).Return(test.SaveError)
+			}
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.configuration.EnableAuditLog = !test.Disabled
+
+			p.recordAudit(test.Action, test.Poll, test.UserID, test.Details)
+		})
+	}
+}
+
+func TestListAuditEntriesTruncated(t *testing.T) {
+	entries := []*audit.Entry{}
+	for i := 0; i < maxListedAuditEntries+2; i++ {
+		entries = append(entries, &audit.Entry{ID: fmt.Sprintf("entryID%d", i), PollID: testutils.GetPollID(), Action: audit.ActionVoted, CreatedAt: 1234567890})
+	}
+
+	api := &plugintest.API{}
+	defer api.AssertExpectations(t)
+	store := &mockstore.Store{}
+	store.AuditStore.On("ListByPoll", testutils.GetPollID()).Return(entries, nil)
+	defer store.AssertExpectations(t)
+	p := setupTestPlugin(t, api, store)
+
+	msg, err := p.listAuditEntries(testutils.GetPollID(), testutils.GetLocalizer(), "poll")
+	require.Nil(t, err)
+	assert.Contains(t, msg, "Only the latest 50 of 52 entries are shown. Type `/poll audit "+testutils.GetPollID()+" --export` to get all of them.")
+	assert.Equal(t, 1+maxListedAuditEntries+1, len(strings.Split(msg, "\n")))
+}
+
+func TestAuditDetails(t *testing.T) {
+	t.Run("vote action", func(t *testing.T) {
+		assert.Equal(t, audit.ActionVoted, voteAuditAction(false))
+		assert.Equal(t, audit.ActionVoteChanged, voteAuditAction(true))
+	})
+
+	t.Run("voted answers", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+
+		assert.Equal(t, "Answer 1, Answer 2", votedAnswers(p, "userID1"))
+		assert.Equal(t, "Answer 2", votedAnswers(p, "userID4"))
+		assert.Equal(t, "", votedAnswers(p, "userID5"))
+	})
+
+	t.Run("ranked answers", func(t *testing.T) {
+		assert.Equal(t, "Answer 3 > Answer 1", rankedAnswers(testutils.GetPoll(), []int{2, 0}))
+	})
+
+	t.Run("rated answers", func(t *testing.T) {
+		assert.Equal(t, "Answer 1: 5, Answer 3: 2", ratedAnswers(testutils.GetPoll(), []int{5, 0, 2}))
+	})
+}
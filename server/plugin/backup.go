@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextAdminExport = &i18n.Message{
+		ID:    "command.help.text.admin.export",
+		Other: "System admins can get a backup of all polls, votes and settings as JSON file by typing `/{{.Trigger}} admin export`",
+	}
+
+	adminExportSuccess = &i18n.Message{
+		ID:    "admin.export.success",
+		Other: "The backup was sent to you via direct message.",
+	}
+	adminExportPostMessage = &i18n.Message{
+		ID:    "admin.export.post.message",
+		One:   "Here is the backup of {{.Count}} poll. Send it to `{{.URL}}` to restore it.",
+		Other: "Here is the backup of {{.Count}} polls. Send it to `{{.URL}}` to restore it.",
+	}
+)
+
+// backupFilename returns the name of the backup file created at a given time in milliseconds
+func backupFilename(millis int64) string {
+	return fmt.Sprintf("matterpoll-backup-%s.json", time.Unix(0, millis*int64(time.Millisecond)).UTC().Format("2006-01-02-150405"))
+}
+
+// backupURL returns the URL of the endpoint that downloads and restores backups
+func (p *MatterpollPlugin) backupURL() string {
+	return fmt.Sprintf("%s/plugins/%s/api/v1/admin/backup", *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID)
+}
+
+// executeAdminExportCommand sends a backup of all data to a given user via direct message
+func (p *MatterpollPlugin) executeAdminExportCommand(userID string, userLocalizer *i18n.Localizer) string {
+	if err := p.sendBackup(userID, userLocalizer); err != nil {
+		p.API.LogError("failed to send backup", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, adminExportSuccess)
+}
+
+// sendBackup sends a backup of all polls, jobs and audit entries as JSON file to a given user via direct message
+func (p *MatterpollPlugin) sendBackup(userID string, userLocalizer *i18n.Localizer) error {
+	backup, err := store.NewBackup(p.Store)
+	if err != nil {
+		return errors.Wrap(err, "failed to create backup")
+	}
+	data, err := json.Marshal(backup)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode backup")
+	}
+
+	channel, appErr := p.API.GetDirectChannel(userID, p.botUserID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get direct channel")
+	}
+	fileInfo, appErr := p.API.UploadFile(data, channel.Id, backupFilename(model.GetMillis()))
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to upload file")
+	}
+
+	count := len(backup.Polls) + len(backup.ArchivedPolls)
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminExportPostMessage,
+			TemplateData:   map[string]interface{}{"Count": count, "URL": p.backupURL()},
+			PluralCount:    count,
+		}),
+		FileIds: []string{fileInfo.Id},
+	}
+	if _, appErr = p.API.CreatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to create post")
+	}
+	return nil
+}
+
+// checkSystemAdmin ensures that requests are sent by system admins
+func (p *MatterpollPlugin) checkSystemAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isAdmin, appErr := p.isSystemAdmin(r.Header.Get("Mattermost-User-ID"))
+		if appErr != nil {
+			p.API.LogWarn("failed to check permission", "error", appErr.Error())
+			http.Error(w, "failed to check permission", http.StatusInternalServerError)
+			return
+		}
+		if !isAdmin {
+			http.Error(w, "only system admins can access the admin API", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleDownloadBackup writes a backup of all polls, jobs and audit entries as JSON file
+func (p *MatterpollPlugin) handleDownloadBackup(w http.ResponseWriter, r *http.Request) {
+	backup, err := store.NewBackup(p.Store)
+	if err != nil {
+		p.API.LogWarn("failed to create backup", "error", err.Error())
+		http.Error(w, "failed to create backup", http.StatusInternalServerError)
+		return
+	}
+
+	b, _ := json.Marshal(backup)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", backupFilename(model.GetMillis())))
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write backup", "error", err.Error())
+	}
+}
+
+// restoreBackupResponse is the response to a restored backup. It counts the restored data.
+type restoreBackupResponse struct {
+	Polls         int `json:"polls"`
+	ArchivedPolls int `json:"archived_polls"`
+	Jobs          int `json:"jobs"`
+	AuditEntries  int `json:"audit_entries"`
+}
+
+// handleRestoreBackup restores a backup sent as request body. Existing polls, jobs and audit entries with the same IDs get overwritten.
+// Restoring is idempotent, so it can be repeated after a failure.
+func (p *MatterpollPlugin) handleRestoreBackup(w http.ResponseWriter, r *http.Request) {
+	backup, err := store.DecodeBackup(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := backup.Restore(p.Store); err != nil {
+		p.API.LogWarn("failed to restore backup", "error", err.Error())
+		http.Error(w, "failed to restore backup", http.StatusInternalServerError)
+		return
+	}
+
+	b, _ := json.Marshal(restoreBackupResponse{
+		Polls:         len(backup.Polls),
+		ArchivedPolls: len(backup.ArchivedPolls),
+		Jobs:          len(backup.Jobs),
+		AuditEntries:  len(backup.AuditEntries),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write restoreBackupResponse", "error", err.Error())
+	}
+}
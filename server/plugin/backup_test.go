@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDownloadBackup(t *testing.T) {
+	systemAdmin := &model.User{Id: "userID1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	backup := &store.Backup{
+		Version:       store.BackupVersion,
+		Polls:         []*poll.Poll{testutils.GetPollWithVotes()},
+		ArchivedPolls: []*poll.Poll{},
+		Jobs:          []*job.Job{},
+		AuditEntries:  []*audit.Entry{},
+	}
+	expectedBody, err := json.Marshal(backup)
+	require.Nil(t, err)
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		UserID             string
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(backup.Polls, nil)
+				store.PollStore.On("ListArchived").Return(backup.ArchivedPolls, nil)
+				store.JobStore.On("List").Return(backup.Jobs, nil)
+				store.AuditStore.On("List").Return(backup.AuditEntries, nil)
+				return store
+			},
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody:       string(expectedBody),
+		},
+		"not logged in": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "",
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedBody:       "not authorized\n",
+		},
+		"not a system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusForbidden,
+			ExpectedBody:       "only system admins can access the admin API\n",
+		},
+		"GetUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to check permission\n",
+		},
+		"PollStore.List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to create backup\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			defer patch.Unpatch()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/backup", nil)
+			if test.UserID != "" {
+				r.Header.Set("Mattermost-User-ID", test.UserID)
+			}
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			body, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(t, test.ExpectedBody, string(body))
+			if test.ExpectedStatusCode == http.StatusOK {
+				assert.Equal(t, `attachment; filename="matterpoll-backup-1970-01-15-065607.json"`, result.Header.Get("Content-Disposition"))
+			}
+		})
+	}
+}
+
+func TestHandleRestoreBackup(t *testing.T) {
+	systemAdmin := &model.User{Id: "userID1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	restoredPoll := testutils.GetPollWithVotes()
+	body, err := json.Marshal(&store.Backup{Version: store.BackupVersion, Polls: []*poll.Poll{restoredPoll}})
+	require.Nil(t, err)
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Body               string
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", restoredPoll).Return(nil)
+				return store
+			},
+			Body:               string(body),
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody:       `{"polls":1,"archived_polls":0,"jobs":0,"audit_entries":0}`,
+		},
+		"invalid backup": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Body:               `{"version":2}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "unsupported backup version 2\n",
+		},
+		"PollStore.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", restoredPoll).Return(errors.New(""))
+				return store
+			},
+			Body:               string(body),
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to restore backup\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", "userID1").Return(systemAdmin, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/admin/backup", strings.NewReader(test.Body))
+			r.Header.Set("Mattermost-User-ID", "userID1")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			body, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(t, test.ExpectedBody, string(body))
+		})
+	}
+}
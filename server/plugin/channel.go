@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextChannel = &i18n.Message{
+		ID:    "command.help.text.channel",
+		Other: "Channel admins can disallow polls in the current channel by typing `/{{.Trigger}} channel disable` and allow them again by typing `/{{.Trigger}} channel enable`. They can allow only channel admins or System Admins to create polls by typing `/{{.Trigger}} channel creators channel_admins` or `/{{.Trigger}} channel creators system_admins`, and everyone again by typing `/{{.Trigger}} channel creators everyone`. In direct and group messages, every member can",
+	}
+	commandErrorChannelUsage = &i18n.Message{
+		ID:    "command.error.channel.usage",
+		Other: "Usage: `/{{.Trigger}} channel disable`, `/{{.Trigger}} channel enable` or `/{{.Trigger}} channel creators <everyone|channel_admins|system_admins>`",
+	}
+	commandErrorChannelInvalidPermission = &i18n.Message{
+		ID:    "command.error.channel.invalidPermission",
+		Other: "Only channel admins and System Admins can allow or disallow polls in a channel. In direct and group messages, every member can.",
+	}
+
+	channelDisableSuccess = &i18n.Message{
+		ID:    "channel.disable.success",
+		Other: "Polls can't be created in this channel anymore. Existing polls keep running.",
+	}
+	channelEnableSuccess = &i18n.Message{
+		ID:    "channel.enable.success",
+		Other: "Polls can be created in this channel again.",
+	}
+	channelCreatorsEveryoneSuccess = &i18n.Message{
+		ID:    "channel.creators.everyone.success",
+		Other: "Everyone can create polls in this channel again, unless the System Console restricts it.",
+	}
+	channelCreatorsChannelAdminsSuccess = &i18n.Message{
+		ID:    "channel.creators.channelAdmins.success",
+		Other: "Only channel admins and System Admins can create polls in this channel now.",
+	}
+	channelCreatorsSystemAdminsSuccess = &i18n.Message{
+		ID:    "channel.creators.systemAdmins.success",
+		Other: "Only System Admins can create polls in this channel now.",
+	}
+
+	responseCreatePollChannelDisabled = &i18n.Message{
+		ID:    "response.createPoll.channelDisabled",
+		Other: "Polls are disabled in this channel.",
+	}
+)
+
+// executeChannelCommand allows or disallows the creation of polls in the channel the command was sent in,
+// or restricts who may create them. Only channel admins and system admins may change it.
+func (p *MatterpollPlugin) executeChannelCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	isValid := len(params) == 1 && (params[0] == "disable" || params[0] == "enable")
+	if len(params) == 2 && params[0] == "creators" {
+		_, isValid = channelCreatorsSuccess[params[1]]
+	}
+	if !isValid {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorChannelUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	isAdmin, err := p.isChannelAdmin(args.ChannelId, args.UserId)
+	if err != nil {
+		p.API.LogError("failed to check permission", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	if !isAdmin {
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorChannelInvalidPermission), nil
+	}
+
+	if params[0] == "creators" {
+		creators := params[1]
+		if creators == pollCreatorsEveryone {
+			creators = ""
+		}
+		if err := p.Store.Channel().SetCreators(args.ChannelId, creators); err != nil {
+			p.API.LogError("failed to change channel setting", "err", err.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+		}
+		return p.LocalizeDefaultMessage(userLocalizer, channelCreatorsSuccess[params[1]]), nil
+	}
+
+	disabled := params[0] == "disable"
+	if err := p.Store.Channel().SetDisabled(args.ChannelId, disabled); err != nil {
+		p.API.LogError("failed to change channel setting", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	if disabled {
+		return p.LocalizeDefaultMessage(userLocalizer, channelDisableSuccess), nil
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, channelEnableSuccess), nil
+}
+
+// isChannelAdmin checks if a given user is an admin of a given channel. System admins are admins of every channel.
+// Direct and group messages have no channel admins, so every member of them counts as one.
+func (p *MatterpollPlugin) isChannelAdmin(channelID, userID string) (bool, error) {
+	isSystemAdmin, appErr := p.isSystemAdmin(userID)
+	if appErr != nil {
+		return false, errors.Wrap(appErr, "failed to check if user is system admin")
+	}
+	if isSystemAdmin {
+		return true, nil
+	}
+
+	member, appErr := p.API.GetChannelMember(channelID, userID)
+	if appErr != nil {
+		return false, errors.Wrap(appErr, "failed to get channel member")
+	}
+	if member.SchemeAdmin {
+		return true, nil
+	}
+	for _, role := range strings.Fields(member.Roles) {
+		if role == model.CHANNEL_ADMIN_ROLE_ID {
+			return true, nil
+		}
+	}
+
+	channel, appErr := p.API.GetChannel(channelID)
+	if appErr != nil {
+		return false, errors.Wrap(appErr, "failed to get channel")
+	}
+	return channel.IsGroupOrDirect(), nil
+}
+
+// isChannelDisabled checks if polls can't be created in a given channel.
+// If the setting can't be loaded, the poll is allowed and the failure is logged.
+func (p *MatterpollPlugin) isChannelDisabled(channelID string) bool {
+	disabled, err := p.Store.Channel().IsDisabled(channelID)
+	if err != nil {
+		p.API.LogWarn("failed to check if polls are disabled in channel", "error", err.Error())
+		return false
+	}
+	return disabled
+}
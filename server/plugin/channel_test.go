@@ -0,0 +1,259 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteChannelCommand(t *testing.T) {
+	user := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_USER_ROLE_ID}
+	systemAdmin := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	usage := "Usage: `/poll channel disable`, `/poll channel enable` or `/poll channel creators <everyone|channel_admins|system_admins>`"
+
+	for name, test := range map[string]struct {
+		SetupAPI     func(*plugintest.API) *plugintest.API
+		SetupStore   func(*mockstore.Store) *mockstore.Store
+		Params       []string
+		ExpectedText string
+	}{
+		"Disable as channel admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{SchemeAdmin: true}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetDisabled", "channelID1", true).Return(nil)
+				return store
+			},
+			Params:       []string{"disable"},
+			ExpectedText: channelDisableSuccess.Other,
+		},
+		"Enable as channel admin by role": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID + " " + model.CHANNEL_ADMIN_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetDisabled", "channelID1", false).Return(nil)
+				return store
+			},
+			Params:       []string{"enable"},
+			ExpectedText: channelEnableSuccess.Other,
+		},
+		"Disable as system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetDisabled", "channelID1", true).Return(nil)
+				return store
+			},
+			Params:       []string{"disable"},
+			ExpectedText: channelDisableSuccess.Other,
+		},
+		"Not a channel admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", Type: model.CHANNEL_OPEN}, nil)
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"disable"},
+			ExpectedText: commandErrorChannelInvalidPermission.Other,
+		},
+		"Disable as member of a direct message": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", Type: model.CHANNEL_DIRECT}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetDisabled", "channelID1", true).Return(nil)
+				return store
+			},
+			Params:       []string{"disable"},
+			ExpectedText: channelDisableSuccess.Other,
+		},
+		"Enable as member of a group message": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", Type: model.CHANNEL_GROUP}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetDisabled", "channelID1", false).Return(nil)
+				return store
+			},
+			Params:       []string{"enable"},
+			ExpectedText: channelEnableSuccess.Other,
+		},
+		"GetChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID}, nil)
+				api.On("GetChannel", "channelID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"disable"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"GetChannelMember fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"disable"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"SetDisabled fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetDisabled", "channelID1", true).Return(errors.New(""))
+				return store
+			},
+			Params:       []string{"disable"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Restrict creators as channel admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{SchemeAdmin: true}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetCreators", "channelID1", pollCreatorsChannelAdmins).Return(nil)
+				return store
+			},
+			Params:       []string{"creators", "channel_admins"},
+			ExpectedText: channelCreatorsChannelAdminsSuccess.Other,
+		},
+		"Restrict creators to system admins": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetCreators", "channelID1", pollCreatorsSystemAdmins).Return(nil)
+				return store
+			},
+			Params:       []string{"creators", "system_admins"},
+			ExpectedText: channelCreatorsSystemAdminsSuccess.Other,
+		},
+		"Allow everyone to create polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetCreators", "channelID1", "").Return(nil)
+				return store
+			},
+			Params:       []string{"creators", "everyone"},
+			ExpectedText: channelCreatorsEveryoneSuccess.Other,
+		},
+		"Restrict creators without permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", Type: model.CHANNEL_OPEN}, nil)
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"creators", "everyone"},
+			ExpectedText: commandErrorChannelInvalidPermission.Other,
+		},
+		"SetCreators fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("SetCreators", "channelID1", pollCreatorsSystemAdmins).Return(errors.New(""))
+				return store
+			},
+			Params:       []string{"creators", "system_admins"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Unknown creators": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"creators", "moderators"},
+			ExpectedText: usage,
+		},
+		"Creators without value": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"creators"},
+			ExpectedText: usage,
+		},
+		"No sub command": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{},
+			ExpectedText: usage,
+		},
+		"Unknown sub command": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"off"},
+			ExpectedText: usage,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(user, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, appErr := p.executeChannelCommand(&model.CommandArgs{UserId: "userID1", ChannelId: "channelID1"}, test.Params)
+
+			assert.Nil(t, appErr)
+			assert.Equal(t, test.ExpectedText, msg)
+		})
+	}
+}
+
+func TestIsChannelDisabled(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		store := &mockstore.Store{}
+		store.ChannelStore.On("IsDisabled", "channelID1").Return(true, nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.True(t, p.isChannelDisabled("channelID1"))
+	})
+	t.Run("IsDisabled fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.ChannelStore.On("IsDisabled", "channelID1").Return(false, errors.New(""))
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		assert.False(t, p.isChannelDisabled("channelID1"))
+	})
+}
@@ -1,18 +1,31 @@
 package plugin
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
 	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/utils"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
 )
 
 const (
 	// Parameter: SiteURL, manifest.ID
 	responseIconURL = "%s/plugins/%s/logo_dark.png"
+
+	// resultsTemplatePlaceholders lists the data a results template can refer to, see poll.ResultsTemplateData
+	resultsTemplatePlaceholders = "`{{.Question}}`, `{{.Winner}}`, `{{.TotalVotes}}`, `{{.Voters}}`, `{{.Results}}`, `{{.Link}}`"
+
+	// maxSearchResults is the maximum number of polls /poll search lists
+	maxSearchResults = 20
 )
 
 var (
@@ -42,14 +55,102 @@ var (
 		ID:    "command.help.text.options",
 		Other: "You can customize the options by typing `/{{.Trigger}} \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"`",
 	}
+	commandHelpTextDialog = &i18n.Message{
+		ID:    "command.help.text.dialog",
+		Other: "Type `/{{.Trigger}}` without any arguments to create a poll using a dialog",
+	}
+	commandHelpTextExport = &i18n.Message{
+		ID:    "command.help.text.export",
+		Other: "To export the results of an ended poll as CSV file, type `/{{.Trigger}} export <poll ID>`",
+	}
+	commandHelpTextEndDelete = &i18n.Message{
+		ID:    "command.help.text.endDelete",
+		Other: "To end or delete a poll without going to its post, type `/{{.Trigger}} end <poll ID>` or `/{{.Trigger}} delete <poll ID>`",
+	}
+	commandHelpTextTransfer = &i18n.Message{
+		ID:    "command.help.text.transfer",
+		Other: "To hand a poll over to another user, e.g. before leaving the team, type `/{{.Trigger}} transfer <poll ID> @username`. The new owner can end and delete the poll",
+	}
+	commandHelpTextScheduled = &i18n.Message{
+		ID:    "command.help.text.scheduled",
+		Other: "To see and cancel your scheduled polls, type `/{{.Trigger}} scheduled`",
+	}
+	commandHelpTextList = &i18n.Message{
+		ID:    "command.help.text.list",
+		Other: "To see all running polls in this channel, type `/{{.Trigger}} list`. To see all polls in this channel with a tag, type `/{{.Trigger}} list --tag=TAG`",
+	}
+	commandHelpTextSearch = &i18n.Message{
+		ID:    "command.help.text.search",
+		Other: "To find polls by their question in all channels you are a member of, type `/{{.Trigger}} search <text>`",
+	}
+	commandHelpTextAudit = &i18n.Message{
+		ID:    "command.help.text.audit",
+		Other: "System admins can see the audit log of a poll by typing `/{{.Trigger}} audit <poll ID>` and get it as CSV file by typing `/{{.Trigger}} audit <poll ID> --export`",
+	}
+	commandHelpTextSurvey = &i18n.Message{
+		ID:    "command.help.text.survey",
+		Other: "To create a survey with several questions, type `/{{.Trigger}} survey \"Title\" \"Question 1\" \"Question 2|Answer 1|Answer 2\"`. Questions without answer options get \"{{.Yes}}\" and \"{{.No}}\"",
+	}
+	commandHelpTextNPS = &i18n.Message{
+		ID:    "command.help.text.nps",
+		Other: "To ask for a Net Promoter Score, type `/{{.Trigger}} nps \"How likely are you to recommend us to a friend?\"`. Voters pick a score from 0 to 10 anonymously and the results show the score together with the share of promoters, passives and detractors",
+	}
 	commandHelpTextPollSettingIntroduction = &i18n.Message{
 		ID:    "command.help.text.pollSetting.introduction",
 		Other: "Poll Settings provider further customization, e.g. `/{{.Trigger}} \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --progress --anonymous`. The available Poll Settings are:",
 	}
+	commandHelpTextPollSettingAllowOther = &i18n.Message{
+		ID:    "command.help.text.pollSetting.allow-other",
+		Other: "Add an \"Other…\" button that lets voters write in their own answer",
+	}
 	commandHelpTextPollSettingAnonymous = &i18n.Message{
 		ID:    "command.help.text.pollSetting.anonymous",
 		Other: "Don't show who voted for what",
 	}
+	commandHelpTextPollSettingAnonymousCreator = &i18n.Message{
+		ID:    "command.help.text.pollSetting.anonymous-creator",
+		Other: "Don't show who created the poll",
+	}
+	commandHelpTextPollSettingCompact = &i18n.Message{
+		ID:    "command.help.text.pollSetting.compact",
+		Other: "Vote via a single dropdown menu instead of a button per answer option",
+	}
+	commandHelpTextPollSettingEndWhenAllVoted = &i18n.Message{
+		ID:    "command.help.text.pollSetting.end-when-all-voted",
+		Other: "End the poll as soon as every member of the channel has voted",
+	}
+	commandHelpTextPollSettingMaxPerOption = &i18n.Message{
+		ID:    "command.help.text.pollSetting.max-per-option",
+		Other: "Let at most N users pick the same answer option",
+	}
+	commandHelpTextPollSettingPin = &i18n.Message{
+		ID:    "command.help.text.pollSetting.pin",
+		Other: "Pin the poll to the channel while it's running",
+	}
+	commandHelpTextPollSettingNoGuests = &i18n.Message{
+		ID:    "command.help.text.pollSetting.no-guests",
+		Other: "Don't let guest accounts vote",
+	}
+	commandHelpTextPollSettingMembersOnly = &i18n.Message{
+		ID:    "command.help.text.pollSetting.members-only",
+		Other: "Only accept votes from members of the channel the poll is posted in",
+	}
+	commandHelpTextPollSettingLockVotes = &i18n.Message{
+		ID:    "command.help.text.pollSetting.lock-votes",
+		Other: "Don't allow voters to change their vote once it's cast",
+	}
+	commandHelpTextPollSettingModerators = &i18n.Message{
+		ID:    "command.help.text.pollSetting.moderators",
+		Other: "Let the given users end, delete and export the poll and add options like the creator",
+	}
+	commandHelpTextPollSettingWeights = &i18n.Message{
+		ID:    "command.help.text.pollSetting.weights",
+		Other: "Count the votes of the given users, of the `creator` or of the `moderators` N times, e.g. `--weights=@alice:3,moderators:2`",
+	}
+	commandHelpTextPollSettingChannels = &i18n.Message{
+		ID:    "command.help.text.pollSetting.channels",
+		Other: "Post the poll into the given channels as well. All posts share the votes and show the same results",
+	}
 	commandHelpTextPollSettingProgress = &i18n.Message{
 		ID:    "command.help.text.pollSetting.progress",
 		Other: "During the poll, show how many votes each answer option got",
@@ -58,6 +159,179 @@ var (
 		ID:    "command.help.text.pollSetting.public-add-option",
 		Other: "Allow all users to add additional options",
 	}
+	commandHelpTextPollSettingPublicVotes = &i18n.Message{
+		ID:    "command.help.text.pollSetting.public-votes",
+		Other: "Show who voted for what while the poll is running",
+	}
+	commandHelpTextPollSettingReceipts = &i18n.Message{
+		ID:    "command.help.text.pollSetting.receipts",
+		Other: "Vote anonymously and get a receipt to verify your vote got counted",
+	}
+	commandHelpTextPollSettingSecret = &i18n.Message{
+		ID:    "command.help.text.pollSetting.secret",
+		Other: "Hide the vote counts until the poll ends",
+	}
+	commandHelpTextPollSettingTags = &i18n.Message{
+		ID:    "command.help.text.pollSetting.tags",
+		Other: "Tag the poll, so it can be found with `list --tag=TAG`. Tags may contain letters, digits, - and _",
+	}
+	commandHelpTextPollSettingEmail = &i18n.Message{
+		ID:    "command.help.text.pollSetting.email",
+		Other: "Email the results with a CSV export to you once the poll ended, and to further addresses if given",
+	}
+	commandHelpTextPollSettingSortResults = &i18n.Message{
+		ID:    "command.help.text.pollSetting.sort-results",
+		Other: "Sort the results by votes and mark the leading answer option",
+	}
+	commandHelpTextPollSettingVoteToSee = &i18n.Message{
+		ID:    "command.help.text.pollSetting.vote-to-see",
+		Other: "Hide the vote counts and show voters the current results after they voted",
+	}
+	commandHelpTextPollSettingVoteModeRanked = &i18n.Message{
+		ID:    "command.help.text.pollSetting.votemode.ranked",
+		Other: "Let voters rank the answer options by preference. The winner is determined by instant-runoff voting",
+	}
+	commandHelpTextPollSettingVoteModeApproval = &i18n.Message{
+		ID:    "command.help.text.pollSetting.votemode.approval",
+		Other: "Let voters approve any number of answer options",
+	}
+	commandHelpTextPollSettingVoteModeRating = &i18n.Message{
+		ID:    "command.help.text.pollSetting.votemode.rating",
+		Other: "Let voters rate every answer option from one to five stars. The results show the average rating",
+	}
+	commandHelpTextPollSettingVoteModeScheduling = &i18n.Message{
+		ID:    "command.help.text.pollSetting.votemode.scheduling",
+		Other: "Find a date: every answer option is a date or time like `2024-06-03 10:00` and voters mark when they are available",
+	}
+	commandHelpTextPollSettingVoteModeEstimate = &i18n.Message{
+		ID:    "command.help.text.pollSetting.votemode.estimate",
+		Other: "Let voters submit a number like story points or a budget instead of picking an answer option. The results show the count, mean, median and distribution of the numbers. Leave out the answer options",
+	}
+	commandHelpTextPollSettingDates = &i18n.Message{
+		ID:    "command.help.text.pollSetting.dates",
+		Other: "Add a date for every day of a range to a scheduling poll. Add `--times=10:00,14:00` to get these times of every day instead",
+	}
+	commandHelpTextPollSettingInvite = &i18n.Message{
+		ID:    "command.help.text.pollSetting.invite",
+		Other: "Attach a calendar invite for the best date to the results of a scheduling poll. Set the length of the event with `--duration`, e.g. `--duration=90m`",
+	}
+	commandHelpTextPollSettingVotes = &i18n.Message{
+		ID:    "command.help.text.pollSetting.votes",
+		Other: "Let voters pick up to X answer options",
+	}
+	commandHelpTextPollSettingResultsTemplate = &i18n.Message{
+		ID:    "command.help.text.pollSetting.resultsTemplate",
+		Other: "Announce the results with your own message, which can refer to {{.Placeholders}}",
+	}
+	commandHelpTextPollSettingNotifyAt = &i18n.Message{
+		ID:    "command.help.text.pollSetting.notifyAt",
+		Other: "Send you a direct message once X users have voted, so you can decide whether to end the poll early",
+	}
+	commandHelpTextPollSettingQuorum = &i18n.Message{
+		ID:    "command.help.text.pollSetting.quorum",
+		Other: "Mark the results as invalid unless at least X percent of the channel members voted, e.g. `--quorum=50%`",
+	}
+	commandHelpTextPollSettingEnd = &i18n.Message{
+		ID:    "command.help.text.pollSetting.end",
+		Other: "End the poll automatically after a duration, e.g. `--end=2h`, or at a time in UTC, e.g. `--end=2024-06-01T17:00`",
+	}
+	commandHelpTextPollSettingSchedule = &i18n.Message{
+		ID:    "command.help.text.pollSetting.schedule",
+		Other: "Post the poll later, after a duration, e.g. `--schedule=1h`, or at a time in UTC, e.g. `--schedule=\"2024-05-01 09:00\"`",
+	}
+	commandHelpTextPollSettingRepeat = &i18n.Message{
+		ID:    "command.help.text.pollSetting.repeat",
+		Other: "Post a fresh copy of the poll `daily`, `weekly` or `monthly` and end the previous one, e.g. `--repeat=weekly`. Delete the latest poll to stop it",
+	}
+	commandHelpTextPollSettingDigest = &i18n.Message{
+		ID:    "command.help.text.pollSetting.digest",
+		Other: "Get a direct message with the current standings `daily`, `weekly` or `monthly` while the poll is running. `--digest` alone sends it daily",
+	}
+	commandHelpTextPollSettingShuffle = &i18n.Message{
+		ID:    "command.help.text.pollSetting.shuffle",
+		Other: "Show the answer options in random order to reduce position bias. `--shuffle=always` shuffles them again whenever the poll gets updated",
+	}
+
+	commandScheduledNone = &i18n.Message{
+		ID:    "command.scheduled.none",
+		Other: "You have no scheduled polls.",
+	}
+	commandScheduledHeading = &i18n.Message{
+		ID:    "command.scheduled.heading",
+		Other: "Your scheduled polls:",
+	}
+	commandScheduledEntry = &i18n.Message{
+		ID:    "command.scheduled.entry",
+		Other: "- **{{.Question}}** gets posted at {{.Time}} UTC. Poll ID: `{{.ID}}`",
+	}
+	commandScheduledCancelHint = &i18n.Message{
+		ID:    "command.scheduled.cancelHint",
+		Other: "To cancel a scheduled poll, type `/{{.Trigger}} scheduled cancel <poll ID>`",
+	}
+	commandScheduledCanceled = &i18n.Message{
+		ID:    "command.scheduled.canceled",
+		Other: "The scheduled poll has been canceled.",
+	}
+
+	commandListNone = &i18n.Message{
+		ID:    "command.list.none",
+		Other: "There are no running polls in this channel.",
+	}
+	commandListHeading = &i18n.Message{
+		ID:    "command.list.heading",
+		Other: "Running polls in this channel:",
+	}
+	commandListEntry = &i18n.Message{
+		ID:    "command.list.entry",
+		One:   "- [{{.Question}}]({{.Link}}) by {{.Creator}}: {{.Count}} vote",
+		Other: "- [{{.Question}}]({{.Link}}) by {{.Creator}}: {{.Count}} votes",
+	}
+	commandListEntryDeadline = &i18n.Message{
+		ID:    "command.list.entryDeadline",
+		Other: "(ends {{.EndAt}})",
+	}
+	commandListEntryAnonymousCreator = &i18n.Message{
+		ID:    "command.list.entryAnonymousCreator",
+		One:   "- [{{.Question}}]({{.Link}}): {{.Count}} vote",
+		Other: "- [{{.Question}}]({{.Link}}): {{.Count}} votes",
+	}
+	commandListEntryEnded = &i18n.Message{
+		ID:    "command.list.entryEnded",
+		Other: "(ended)",
+	}
+	commandListTagNone = &i18n.Message{
+		ID:    "command.list.tagNone",
+		Other: "There are no polls tagged \"{{.Tag}}\" in this channel.",
+	}
+	commandListTagHeading = &i18n.Message{
+		ID:    "command.list.tagHeading",
+		Other: "Polls tagged \"{{.Tag}}\" in this channel, newest first:",
+	}
+	commandListTagMore = &i18n.Message{
+		ID:    "command.list.tagMore",
+		Other: "Only the newest {{.Count}} polls are shown.",
+	}
+
+	commandSearchNone = &i18n.Message{
+		ID:    "command.search.none",
+		Other: "No polls match \"{{.Text}}\".",
+	}
+	commandSearchHeading = &i18n.Message{
+		ID:    "command.search.heading",
+		Other: "Polls matching \"{{.Text}}\", newest first:",
+	}
+	commandSearchEntry = &i18n.Message{
+		ID:    "command.search.entry",
+		Other: "- [{{.Question}}]({{.Link}}) in ~{{.Channel}}",
+	}
+	commandSearchEntryEnded = &i18n.Message{
+		ID:    "command.search.entryEnded",
+		Other: "- [{{.Question}}]({{.Link}}) in ~{{.Channel}} (ended)",
+	}
+	commandSearchMore = &i18n.Message{
+		ID:    "command.search.more",
+		Other: "Only the newest {{.Count}} polls are shown. Add more words to narrow down the search.",
+	}
 
 	commandErrorGeneric = &i18n.Message{
 		ID:    "command.error.generic",
@@ -67,10 +341,131 @@ var (
 		ID:    "command.error.invalidNumberOfOptions",
 		Other: "You must provide either no answer or at least two answers.",
 	}
+	commandErrorExportUsage = &i18n.Message{
+		ID:    "command.error.export.usage",
+		Other: "Usage: `/{{.Trigger}} export <poll ID>`",
+	}
+	commandErrorEndUsage = &i18n.Message{
+		ID:    "command.error.end.usage",
+		Other: "Usage: `/{{.Trigger}} end <poll ID>`",
+	}
+	commandErrorDeleteUsage = &i18n.Message{
+		ID:    "command.error.delete.usage",
+		Other: "Usage: `/{{.Trigger}} delete <poll ID>`",
+	}
+	commandErrorScheduledUsage = &i18n.Message{
+		ID:    "command.error.scheduled.usage",
+		Other: "Usage: `/{{.Trigger}} scheduled [cancel <poll ID>]`",
+	}
+	commandErrorSurveyUsage = &i18n.Message{
+		ID:    "command.error.survey.usage",
+		Other: "Usage: `/{{.Trigger}} survey \"Title\" \"Question 1\" \"Question 2|Answer 1|Answer 2\"`",
+	}
+	commandErrorNPSUsage = &i18n.Message{
+		ID:    "command.error.nps.usage",
+		Other: "Usage: `/{{.Trigger}} nps \"Question\"`",
+	}
+	commandErrorListUsage = &i18n.Message{
+		ID:    "command.error.list.usage",
+		Other: "Usage: `/{{.Trigger}} list [--tag=TAG]`",
+	}
+	commandErrorSearchUsage = &i18n.Message{
+		ID:    "command.error.search.usage",
+		Other: "Usage: `/{{.Trigger}} search <text>`",
+	}
+	commandErrorTransferUsage = &i18n.Message{
+		ID:    "command.error.transfer.usage",
+		Other: "Usage: `/{{.Trigger}} transfer <poll ID> @username`",
+	}
+	commandErrorAuditUsage = &i18n.Message{
+		ID:    "command.error.audit.usage",
+		Other: "Usage: `/{{.Trigger}} audit <poll ID> [--export]`",
+	}
+	commandErrorAuditInvalidPermission = &i18n.Message{
+		ID:    "command.error.audit.invalidPermission",
+		Other: "Only system admins can see the audit log of a poll.",
+	}
+	commandErrorScheduledNotFound = &i18n.Message{
+		ID:    "command.error.scheduled.notFound",
+		Other: "This poll is not scheduled.",
+	}
+	commandErrorScheduledInvalidPermission = &i18n.Message{
+		ID:    "command.error.scheduled.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to cancel it.",
+	}
+	commandErrorNotPosted = &i18n.Message{
+		ID:    "command.error.notPosted",
+		Other: "This poll hasn't been posted yet. Type `/{{.Trigger}} scheduled` to see and cancel your scheduled polls.",
+	}
+	commandErrorEndAlreadyEnded = &i18n.Message{
+		ID:    "command.error.end.alreadyEnded",
+		Other: "This poll has already ended.",
+	}
+	commandEndSuccess = &i18n.Message{
+		ID:    "command.end.success",
+		Other: "The poll has ended and its post has been updated.",
+	}
 	commandErrorInvalidInput = &i18n.Message{
 		ID:    "command.error.invalidInput",
 		Other: "Invalid input: {{.Error}}",
 	}
+
+	dialogCreatePollTitle = &i18n.Message{
+		ID:    "dialog.createPoll.title",
+		Other: "Create Poll",
+	}
+	dialogCreatePollSubmitLabel = &i18n.Message{
+		ID:    "dialog.createPoll.submitLabel",
+		Other: "Create",
+	}
+	dialogCreatePollElementQuestionDisplayName = &i18n.Message{
+		ID:    "dialog.createPoll.element.question.displayName",
+		Other: "Question",
+	}
+	dialogCreatePollElementOptionsDisplayName = &i18n.Message{
+		ID:    "dialog.createPoll.element.options.displayName",
+		Other: "Answer Options",
+	}
+	dialogCreatePollElementOptionsHelpText = &i18n.Message{
+		ID:    "dialog.createPoll.element.options.helpText",
+		Other: "One answer option per line. To describe an answer option or show an image next to it, add a description or image URL, e.g. \"Logo A|Our current logo|https://example.com/a.png\". Leave empty to use \"{{.Yes}}\" and \"{{.No}}\".",
+	}
+	dialogCreatePollElementVoteModeDisplayName = &i18n.Message{
+		ID:    "dialog.createPoll.element.voteMode.displayName",
+		Other: "Vote Mode",
+	}
+	dialogCreatePollElementVoteModeSingle = &i18n.Message{
+		ID:    "dialog.createPoll.element.voteMode.single",
+		Other: "Single choice",
+	}
+	dialogCreatePollElementVoteModeRanked = &i18n.Message{
+		ID:    "dialog.createPoll.element.voteMode.ranked",
+		Other: "Ranked choice",
+	}
+	dialogCreatePollElementVoteModeApproval = &i18n.Message{
+		ID:    "dialog.createPoll.element.voteMode.approval",
+		Other: "Approval",
+	}
+	dialogCreatePollElementVoteModeRating = &i18n.Message{
+		ID:    "dialog.createPoll.element.voteMode.rating",
+		Other: "Rating",
+	}
+	dialogCreatePollElementVoteModeScheduling = &i18n.Message{
+		ID:    "dialog.createPoll.element.voteMode.scheduling",
+		Other: "Scheduling",
+	}
+	dialogCreatePollElementVoteModeEstimate = &i18n.Message{
+		ID:    "dialog.createPoll.element.voteMode.estimate",
+		Other: "Estimate",
+	}
+	dialogCreatePollElementSettingsDisplayName = &i18n.Message{
+		ID:    "dialog.createPoll.element.settings.displayName",
+		Other: "Poll Settings",
+	}
+	dialogCreatePollElementSettingsHelpText = &i18n.Message{
+		ID:    "dialog.createPoll.element.settings.helpText",
+		Other: "Space separated list of Poll Settings, e.g. `anonymous progress end=2h`. Type `/{{.Trigger}} help` to see all of them.",
+	}
 )
 
 // ExecuteCommand parses a given input and creates a poll if the input is correct
@@ -87,12 +482,74 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 	configuration := p.getConfiguration()
 
 	userLocalizer := p.getUserLocalizer(creatorID)
-	publicLocalizer := p.getServerLocalizer()
+	publicLocalizer := p.getPublicLocalizer()
 
 	defaultYes := p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes)
 	defaultNo := p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo)
 
+	if fields := strings.Fields(args.Command); len(fields) > 1 {
+		switch fields[1] {
+		case "export":
+			return p.executeExportCommand(args, fields[2:])
+		case "end":
+			return p.executeEndCommand(args, fields[2:])
+		case "delete":
+			return p.executeDeleteCommand(args, fields[2:])
+		case "restore":
+			return p.executeRestoreCommand(args, fields[2:])
+		case "reopen":
+			return p.executeReopenCommand(args, fields[2:])
+		case "extend":
+			return p.executeExtendCommand(args, fields[2:])
+		case "pause":
+			return p.executePauseCommand(args, fields[2:], true)
+		case "resume":
+			return p.executePauseCommand(args, fields[2:], false)
+		case "transfer":
+			return p.executeTransferCommand(args, fields[2:])
+		case "delegate":
+			return p.executeDelegateCommand(args, fields[2:])
+		case "scheduled":
+			return p.executeScheduledCommand(args, fields[2:])
+		case "list":
+			return p.executeListCommand(args, fields[2:])
+		case "search":
+			return p.executeSearchCommand(args, fields[2:])
+		case "myvotes":
+			return p.executeMyVotesCommand(args, fields[2:])
+		case "stats":
+			return p.executeStatsCommand(args, fields[2:])
+		case "voters":
+			return p.executeVotersCommand(args, fields[2:])
+		case "audit":
+			return p.executeAuditCommand(args, fields[2:])
+		case "admin":
+			return p.executeAdminCommand(args, fields[2:])
+		case "channel":
+			return p.executeChannelCommand(args, fields[2:])
+		case "team":
+			return p.executeTeamCommand(args, fields[2:])
+		case "survey":
+			return p.executeSurveyCommand(args, []string{defaultYes, defaultNo})
+		case "nps":
+			return p.executeNPSCommand(args)
+		}
+	}
+
 	q, o, s := utils.ParseInput(args.Command, configuration.Trigger)
+	if q == "" && args.TriggerId != "" {
+		if p.isChannelDisabled(args.ChannelId) {
+			return p.LocalizeDefaultMessage(userLocalizer, responseCreatePollChannelDisabled), nil
+		}
+		if p.isPollCreationRestricted(args.ChannelId, args.UserId) {
+			return p.LocalizeDefaultMessage(userLocalizer, responseCreatePollRestricted), nil
+		}
+		if appErr := p.openCreatePollDialog(args); appErr != nil {
+			p.API.LogError("failed to open create poll dialog", "err", appErr.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+		}
+		return "", nil
+	}
 	if q == "" || q == "help" {
 		msg := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
 			DefaultMessage: commandHelpTextSimple,
@@ -102,13 +559,157 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 			DefaultMessage: commandHelpTextOptions,
 			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
 		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextDialog,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextDraft,
+			TemplateData:   map[string]interface{}{"Bot": botUserName},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextExport,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextEndDelete,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextRestore,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextReopen,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextExtend,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextPause,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextTransfer,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextDelegate,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextScheduled,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextList,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextSearch,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextMyVotes,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextStats,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextVoters,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextAudit,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextAdmin,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextAdminErase,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextAdminExport,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextAdminUsage,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextAdminDiagnose,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextChannel,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextTeam,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextSurvey,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger, "Yes": defaultYes, "No": defaultNo},
+		}) + "\n"
+		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextNPS,
+			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
+		}) + "\n"
 		msg += p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
 			DefaultMessage: commandHelpTextPollSettingIntroduction,
 			TemplateData:   map[string]interface{}{"Trigger": configuration.Trigger},
 		}) + "\n"
+		msg += "- `--allow-other`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingAllowOther) + "\n"
 		msg += "- `--anonymous`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingAnonymous) + "\n"
+		msg += "- `--anonymous-creator`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingAnonymousCreator) + "\n"
+		msg += "- `--compact`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingCompact) + "\n"
+		msg += "- `--end-when-all-voted`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingEndWhenAllVoted) + "\n"
+		msg += "- `--lock-votes`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingLockVotes) + "\n"
+		msg += "- `--members-only`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingMembersOnly) + "\n"
+		msg += "- `--no-guests`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingNoGuests) + "\n"
+		msg += "- `--moderators=@USER,@USER`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingModerators) + "\n"
+		msg += "- `--weights=@USER:N,moderators:N`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingWeights) + "\n"
+		msg += "- `--channels=CHANNEL,CHANNEL`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingChannels) + "\n"
+		msg += "- `--pin`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingPin) + "\n"
 		msg += "- `--progress`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingProgress) + "\n"
-		msg += "- `--public-add-option`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingPublicAddOption)
+		msg += "- `--public-add-option`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingPublicAddOption) + "\n"
+		msg += "- `--public-votes`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingPublicVotes) + "\n"
+		msg += "- `--receipts`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingReceipts) + "\n"
+		msg += "- `--secret`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingSecret) + "\n"
+		msg += "- `--sort-results`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingSortResults) + "\n"
+		msg += "- `--tags=TAG,TAG`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingTags) + "\n"
+		if configuration.EnableEmailSummaries {
+			msg += "- `--email=ADDRESS,ADDRESS`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingEmail) + "\n"
+		}
+		msg += "- `--vote-to-see`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingVoteToSee) + "\n"
+		msg += "- `--votemode=ranked`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingVoteModeRanked) + "\n"
+		msg += "- `--votemode=approval`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingVoteModeApproval) + "\n"
+		msg += "- `--votemode=rating`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingVoteModeRating) + "\n"
+		msg += "- `--votemode=scheduling`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingVoteModeScheduling) + "\n"
+		msg += "- `--votemode=estimate`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingVoteModeEstimate) + "\n"
+		msg += "- `--dates=FROM..TO`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingDates) + "\n"
+		msg += "- `--invite`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingInvite) + "\n"
+		msg += "- `--votes=X`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingVotes) + "\n"
+		msg += "- `--max-per-option=N`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingMaxPerOption) + "\n"
+		msg += "- `--quorum=X%`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingQuorum) + "\n"
+		msg += "- `--notify-at=X`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingNotifyAt) + "\n"
+		msg += "- `--results-template=TEXT`: " + p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandHelpTextPollSettingResultsTemplate,
+			TemplateData:   map[string]interface{}{"Placeholders": resultsTemplatePlaceholders},
+		}) + "\n"
+		msg += "- `--end=TIME`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingEnd) + "\n"
+		msg += "- `--schedule=TIME`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingSchedule) + "\n"
+		msg += "- `--repeat=INTERVAL`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingRepeat) + "\n"
+		msg += "- `--digest=INTERVAL`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingDigest) + "\n"
+		msg += "- `--shuffle`: " + p.LocalizeDefaultMessage(userLocalizer, commandHelpTextPollSettingShuffle)
 
 		return msg, nil
 	}
@@ -122,51 +723,877 @@ func (p *MatterpollPlugin) executeCommand(args *model.CommandArgs) (string, *mod
 
 	var newPoll *poll.Poll
 	var err error
-	if len(o) == 0 {
+	// Team admins may override the defaults and limits of the plugin configuration
+	configuration = p.getTeamConfiguration(args.TeamId)
+	s = configuration.applyDefaultSettings(s)
+	if len(o) == 0 && poll.NeedsAnswerOptions(s) {
 		newPoll, err = poll.NewPoll(creatorID, q, []string{defaultYes, defaultNo}, s)
 	} else {
 		newPoll, err = poll.NewPoll(creatorID, q, o, s)
 	}
 	if err != nil {
-		appErr := &model.AppError{
-			Id: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
-				DefaultMessage: commandErrorInvalidInput,
-				TemplateData: map[string]interface{}{
-					"Error": err.Error(),
-				}}),
-			StatusCode: http.StatusBadRequest,
-			Where:      "ExecuteCommand",
+		return "", p.newInvalidInputError(userLocalizer, err)
+	}
+	return p.createPollFromCommand(args, userLocalizer, configuration, newPoll)
+}
+
+// executeSurveyCommand creates a survey. The first argument is the title, every following argument is a question.
+func (p *MatterpollPlugin) executeSurveyCommand(args *model.CommandArgs, defaultAnswerOptions []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	configuration := p.getConfiguration()
+	trigger := configuration.Trigger
+
+	title, questions, settings := utils.ParseInput(args.Command, trigger+" survey")
+	if title == "" || len(questions) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorSurveyUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	configuration = p.getTeamConfiguration(args.TeamId)
+	survey, err := poll.NewSurvey(args.UserId, title, questions, defaultAnswerOptions, configuration.applyDefaultSettings(settings))
+	if err != nil {
+		return "", p.newInvalidInputError(userLocalizer, err)
+	}
+	return p.createPollFromCommand(args, userLocalizer, configuration, survey)
+}
+
+// executeNPSCommand creates a poll that asks for a Net Promoter Score. The only argument is the question.
+func (p *MatterpollPlugin) executeNPSCommand(args *model.CommandArgs) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	configuration := p.getConfiguration()
+	trigger := configuration.Trigger
+
+	question, answerOptions, settings := utils.ParseInput(args.Command, trigger+" nps")
+	if question == "" || len(answerOptions) > 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorNPSUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	configuration = p.getTeamConfiguration(args.TeamId)
+	newPoll, err := poll.NewNPSPoll(args.UserId, question, configuration.applyDefaultSettings(settings))
+	if err != nil {
+		return "", p.newInvalidInputError(userLocalizer, err)
+	}
+	return p.createPollFromCommand(args, userLocalizer, configuration, newPoll)
+}
+
+// executeExportCommand sends the results of the poll with the ID given in params to the user
+func (p *MatterpollPlugin) executeExportCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	if len(params) != 1 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorExportUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	msg, err := p.exportPoll(params[0], args.UserId)
+	if err != nil {
+		p.API.LogError("failed to export poll", "err", err.Error())
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, msg), nil
+}
+
+// executeEndCommand ends the poll with the ID given in params
+func (p *MatterpollPlugin) executeEndCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	if len(params) != 1 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorEndUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	msg, err := p.endPollByID(params[0], args.UserId)
+	if err != nil {
+		p.API.LogError("failed to end poll", "err", err.Error())
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: msg,
+		TemplateData:   map[string]interface{}{"Trigger": trigger},
+	}), nil
+}
+
+// endPollByID ends a running poll on behalf of a given user
+func (p *MatterpollPlugin) endPollByID(pollID, userID string) (*i18n.Message, error) {
+	runningPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(runningPoll, userID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseEndPollInvalidPermission, nil
+	}
+	if runningPoll.IsDeleted() {
+		return responseDeletePollAlreadyDeleted, nil
+	}
+	if runningPoll.IsScheduled() {
+		return commandErrorNotPosted, nil
+	}
+	if runningPoll.IsEnded() {
+		return commandErrorEndAlreadyEnded, nil
+	}
+
+	if err := p.endPoll(runningPoll.ID, userID); err != nil {
+		return commandErrorGeneric, err
+	}
+	if err := p.unscheduleEnd(runningPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll end", "error", err.Error())
+	}
+	return commandEndSuccess, nil
+}
+
+// executeDeleteCommand deletes the poll with the ID given in params
+func (p *MatterpollPlugin) executeDeleteCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	if len(params) != 1 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorDeleteUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	msg, err := p.deletePollByID(params[0], args.UserId)
+	if err != nil {
+		p.API.LogError("failed to delete poll", "err", err.Error())
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: msg,
+		TemplateData:   map[string]interface{}{"Trigger": trigger, "ID": params[0]},
+	}), nil
+}
+
+// deletePollByID deletes a posted poll on behalf of a given user
+func (p *MatterpollPlugin) deletePollByID(pollID, userID string) (*i18n.Message, error) {
+	pollToDelete, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(pollToDelete, userID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseDeletePollInvalidPermission, nil
+	}
+	if pollToDelete.IsScheduled() {
+		return commandErrorNotPosted, nil
+	}
+	if pollToDelete.IsDeleted() {
+		return responseDeletePollAlreadyDeleted, nil
+	}
+
+	if err := p.deletePoll(pollToDelete, pollToDelete.PostID, userID); err != nil {
+		return commandErrorGeneric, err
+	}
+	return p.deletePollSuccessMessage(), nil
+}
+
+// executeTransferCommand hands the poll with the ID given in params over to the user given in params
+func (p *MatterpollPlugin) executeTransferCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	if len(params) != 2 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorTransferUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	newOwner, appErr := p.API.GetUserByUsername(strings.TrimPrefix(params[1], "@"))
+	if appErr != nil {
+		return p.LocalizeDefaultMessage(userLocalizer, responseTransferPollUnknownUser), nil
+	}
+
+	msg, err := p.transferPollByID(params[0], args.UserId, newOwner)
+	if err != nil {
+		p.API.LogError("failed to transfer poll", "err", err.Error())
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, msg), nil
+}
+
+// transferPollByID makes a given user the owner of a poll on behalf of another user.
+// The new owner takes over the rights of the creator, while the previous one keeps only the rights they have otherwise.
+func (p *MatterpollPlugin) transferPollByID(pollID, issuerID string, newOwner *model.User) (*i18n.Message, error) {
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(currentPoll, issuerID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseTransferPollInvalidPermission, nil
+	}
+	if currentPoll.IsDeleted() {
+		return responseDeletePollAlreadyDeleted, nil
+	}
+	if newOwner.IsBot {
+		return responseTransferPollBot, nil
+	}
+	if newOwner.Id == currentPoll.Creator {
+		return responseTransferPollAlreadyOwner, nil
+	}
+
+	transferredPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		latest.Creator = newOwner.Id
+		return nil
+	})
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to update poll")
+	}
+	// The entry is recorded against the previous state, so an anonymous creator stays anonymous
+	p.recordAudit(audit.ActionOwnershipTransferred, currentPoll, issuerID, "@"+newOwner.Username)
+
+	if appErr := p.updatePollPost(transferredPoll); appErr != nil {
+		p.API.LogWarn("failed to update poll post", "pollID", pollID, "error", appErr.Error())
+	}
+	return responseTransferPollSuccess, nil
+}
+
+// executeScheduledCommand lists the scheduled polls of the user or cancels the poll with the ID given in params
+func (p *MatterpollPlugin) executeScheduledCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	switch {
+	case len(params) == 0:
+		msg, err := p.listScheduledPolls(args.UserId, userLocalizer, trigger)
+		if err != nil {
+			p.API.LogError("failed to list scheduled polls", "err", err.Error())
+			return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+		}
+		return msg, nil
+	case len(params) == 2 && params[0] == "cancel":
+		msg, err := p.cancelScheduledPoll(params[1], args.UserId)
+		if err != nil {
+			p.API.LogError("failed to cancel scheduled poll", "err", err.Error())
 		}
-		return "", appErr
+		return p.LocalizeDefaultMessage(userLocalizer, msg), nil
+	default:
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorScheduledUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
 	}
+}
 
-	if err := p.Store.Poll().Save(newPoll); err != nil {
-		p.API.LogError("failed to save poll", "err", err.Error())
+// listScheduledPolls returns a message that lists all polls a given user has scheduled, ordered by their post time
+func (p *MatterpollPlugin) listScheduledPolls(userID string, userLocalizer *i18n.Localizer, trigger string) (string, error) {
+	jobs, err := p.Store.Job().List()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list jobs")
+	}
+
+	polls := []*poll.Poll{}
+	for _, j := range jobs {
+		if j.Type != job.TypePostPoll {
+			continue
+		}
+		scheduledPoll, err := p.Store.Poll().Get(j.PollID)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get poll")
+		}
+		if scheduledPoll.Creator == userID {
+			polls = append(polls, scheduledPoll)
+		}
+	}
+	if len(polls) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandScheduledNone), nil
+	}
+	sort.Slice(polls, func(i, j int) bool { return polls[i].Settings.PostAt < polls[j].Settings.PostAt })
+
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, commandScheduledHeading)}
+	for _, scheduledPoll := range polls {
+		postAt := time.Unix(0, scheduledPoll.Settings.PostAt*int64(time.Millisecond)).UTC()
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandScheduledEntry,
+			TemplateData: map[string]interface{}{
+				"Question": scheduledPoll.Question,
+				"Time":     postAt.Format(poll.TimeLayout),
+				"ID":       scheduledPoll.ID,
+			},
+		}))
+	}
+	lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandScheduledCancelHint,
+		TemplateData:   map[string]interface{}{"Trigger": trigger},
+	}))
+	return strings.Join(lines, "\n"), nil
+}
+
+// cancelScheduledPoll deletes a scheduled poll before it gets posted
+func (p *MatterpollPlugin) cancelScheduledPoll(pollID, userID string) (*i18n.Message, error) {
+	scheduledPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+	if !scheduledPoll.IsScheduled() {
+		return commandErrorScheduledNotFound, nil
+	}
+
+	hasPermission, appErr := p.HasPermission(scheduledPoll, userID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return commandErrorScheduledInvalidPermission, nil
+	}
+
+	if err := p.unschedulePost(scheduledPoll); err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to delete job")
+	}
+	if err := p.Store.Poll().Delete(scheduledPoll); err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to delete poll")
+	}
+	return commandScheduledCanceled, nil
+}
+
+// executeListCommand lists all running polls in the channel the command was executed in.
+// With --tag=TAG, it lists all polls in the channel with the given tag instead, including ended ones.
+func (p *MatterpollPlugin) executeListCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	tag := ""
+	if len(params) == 1 && strings.HasPrefix(params[0], "--tag=") {
+		tag = poll.NormalizeTag(strings.TrimPrefix(params[0], "--tag="))
+	}
+	if len(params) > 1 || (len(params) == 1 && tag == "") {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorListUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	var msg string
+	var err error
+	if tag == "" {
+		msg, err = p.listChannelPolls(args.ChannelId, args.TeamId, args.UserId, userLocalizer)
+	} else {
+		msg, err = p.listTaggedPolls(args.ChannelId, args.TeamId, args.UserId, tag, userLocalizer)
+	}
+	if err != nil {
+		p.API.LogError("failed to list polls", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	return msg, nil
+}
+
+// listChannelPolls returns a message that lists all running polls in a given channel, oldest first, with links to their posts.
+// Deadlines are shown in the timezone of the user the list is for.
+func (p *MatterpollPlugin) listChannelPolls(channelID, teamID, userID string, userLocalizer *i18n.Localizer) (string, error) {
+	polls, err := p.Store.Poll().ListByChannel(channelID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list polls")
+	}
+
+	running := []*poll.Poll{}
+	for _, channelPoll := range polls {
+		if channelPoll.PostID != "" && !channelPoll.IsEnded() && !channelPoll.IsDeleted() {
+			running = append(running, channelPoll)
+		}
+	}
+	if len(running) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, commandListNone), nil
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].CreatedAt < running[j].CreatedAt })
+
+	team, appErr := p.API.GetTeam(teamID)
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to get team")
+	}
+
+	lines := []string{p.LocalizeDefaultMessage(userLocalizer, commandListHeading)}
+	for _, runningPoll := range running {
+		line, err := p.makeListEntry(runningPoll, team.Name, userID, userLocalizer)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// listTaggedPolls returns a message that lists the posted polls in a given channel that have a given tag, newest first, with links to their posts.
+// Unlike listChannelPolls, it includes ended polls.
+func (p *MatterpollPlugin) listTaggedPolls(channelID, teamID, userID, tag string, userLocalizer *i18n.Localizer) (string, error) {
+	polls, err := p.Store.Poll().ListByTag(tag)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list polls by tag")
+	}
+
+	tagged := []*poll.Poll{}
+	for _, taggedPoll := range polls {
+		if taggedPoll.PostID != "" && taggedPoll.ChannelID == channelID && !taggedPoll.IsDeleted() {
+			tagged = append(tagged, taggedPoll)
+		}
+	}
+	if len(tagged) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandListTagNone,
+			TemplateData:   map[string]interface{}{"Tag": tag},
+		}), nil
+	}
+
+	team, appErr := p.API.GetTeam(teamID)
+	if appErr != nil {
+		return "", errors.Wrap(appErr, "failed to get team")
+	}
+
+	lines := []string{p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandListTagHeading,
+		TemplateData:   map[string]interface{}{"Tag": tag},
+	})}
+	for i, taggedPoll := range tagged {
+		if i == maxSearchResults {
+			lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandListTagMore,
+				TemplateData:   map[string]interface{}{"Count": maxSearchResults},
+			}))
+			break
+		}
+		line, err := p.makeListEntry(taggedPoll, team.Name, userID, userLocalizer)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// makeListEntry returns the line that lists a given poll with a link to its post, its creator and its number of voters.
+// Ended polls are marked as such, deadlines of running polls are shown in the timezone of the user the list is for.
+func (p *MatterpollPlugin) makeListEntry(listedPoll *poll.Poll, teamName, userID string, userLocalizer *i18n.Localizer) (string, error) {
+	count := listedPoll.NumberOfVoters()
+	templateData := map[string]interface{}{
+		"Question": listedPoll.Question,
+		"Link":     p.makePermalink(teamName, listedPoll.PostID),
+		"Count":    count,
+	}
+	entry := commandListEntryAnonymousCreator
+	if !listedPoll.Settings.AnonymousCreator {
+		displayName, appErr := p.ConvertCreatorIDToDisplayName(listedPoll.Creator)
+		if appErr != nil {
+			return "", errors.Wrap(appErr, "failed to get display name for creator")
+		}
+		templateData["Creator"] = displayName
+		entry = commandListEntry
+	}
+	line := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: entry,
+		TemplateData:   templateData,
+		PluralCount:    count,
+	})
+	switch {
+	case listedPoll.IsEnded():
+		line += " " + p.LocalizeDefaultMessage(userLocalizer, commandListEntryEnded)
+	case listedPoll.HasDeadline():
+		line += " " + p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandListEntryDeadline,
+			TemplateData:   map[string]interface{}{"EndAt": p.formatTimeForUser(userID, userLocalizer, listedPoll.Settings.EndAt)},
+		})
+	}
+	return line, nil
+}
+
+// executeSearchCommand lists the polls whose question matches the text given in params.
+func (p *MatterpollPlugin) executeSearchCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	if len(params) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorSearchUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	msg, err := p.searchPolls(strings.Join(params, " "), args.TeamId, args.UserId, userLocalizer)
+	if err != nil {
+		p.API.LogError("failed to search polls", "err", err.Error())
 		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
 	}
+	return msg, nil
+}
+
+// searchPolls returns a message that lists the posted polls whose question matches a given text, newest first, with links to their posts.
+// Only polls in channels the user is a member of are listed. Links to polls in direct messages point to the current team.
+func (p *MatterpollPlugin) searchPolls(text, teamID, userID string, userLocalizer *i18n.Localizer) (string, error) {
+	polls, err := p.Store.Poll().Search(text)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to search polls")
+	}
+
+	channels := map[string]*model.Channel{}
+	teamNames := map[string]string{}
+	lines := []string{}
+	for _, foundPoll := range polls {
+		if foundPoll.PostID == "" || foundPoll.ChannelID == "" || foundPoll.IsDeleted() {
+			continue
+		}
+		channel, ok := channels[foundPoll.ChannelID]
+		if !ok {
+			// Channels the user isn't a member of are remembered as nil, so they are only checked once
+			if _, appErr := p.API.GetChannelMember(foundPoll.ChannelID, userID); appErr == nil {
+				if channel, appErr = p.API.GetChannel(foundPoll.ChannelID); appErr != nil {
+					return "", errors.Wrap(appErr, "failed to get channel")
+				}
+			}
+			channels[foundPoll.ChannelID] = channel
+		}
+		if channel == nil {
+			continue
+		}
 
-	displayName, appErr := p.ConvertCreatorIDToDisplayName(creatorID)
+		channelTeamID := channel.TeamId
+		if channelTeamID == "" {
+			channelTeamID = teamID
+		}
+		teamName, ok := teamNames[channelTeamID]
+		if !ok {
+			team, appErr := p.API.GetTeam(channelTeamID)
+			if appErr != nil {
+				return "", errors.Wrap(appErr, "failed to get team")
+			}
+			teamName = team.Name
+			teamNames[channelTeamID] = teamName
+		}
+
+		if len(lines) == maxSearchResults {
+			lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandSearchMore,
+				TemplateData:   map[string]interface{}{"Count": maxSearchResults},
+			}))
+			break
+		}
+		entry := commandSearchEntry
+		if foundPoll.IsEnded() {
+			entry = commandSearchEntryEnded
+		}
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: entry,
+			TemplateData: map[string]interface{}{
+				"Question": foundPoll.Question,
+				"Link":     p.makePermalink(teamName, foundPoll.PostID),
+				"Channel":  channel.Name,
+			},
+		}))
+	}
+
+	if len(lines) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandSearchNone,
+			TemplateData:   map[string]interface{}{"Text": text},
+		}), nil
+	}
+	heading := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandSearchHeading,
+		TemplateData:   map[string]interface{}{"Text": text},
+	})
+	return strings.Join(append([]string{heading}, lines...), "\n"), nil
+}
+
+// executeAuditCommand lists the audit log of the poll with the ID given in params or exports it as CSV file.
+// Only system admins may see audit logs.
+func (p *MatterpollPlugin) executeAuditCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	export := len(params) == 2 && params[1] == "--export"
+	if len(params) != 1 && !export {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorAuditUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	isAdmin, appErr := p.isSystemAdmin(args.UserId)
 	if appErr != nil {
-		p.API.LogError("failed to ConvertCreatorIDToDisplayName", "err", appErr.Error())
+		p.API.LogError("failed to check permission", "err", appErr.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	if !isAdmin {
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorAuditInvalidPermission), nil
+	}
+
+	if export {
+		msg, err := p.exportAuditEntries(params[0], args.UserId, userLocalizer)
+		if err != nil {
+			p.API.LogError("failed to export audit log", "err", err.Error())
+		}
+		return p.LocalizeDefaultMessage(userLocalizer, msg), nil
+	}
+
+	msg, err := p.listAuditEntries(params[0], userLocalizer, trigger)
+	if err != nil {
+		p.API.LogError("failed to list audit log", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	return msg, nil
+}
+
+// invalidPollError is returned by createPoll if a new poll is invalid or exceeds the limits of the configuration
+type invalidPollError struct {
+	err error
+}
+
+func (e *invalidPollError) Error() string {
+	return e.err.Error()
+}
+
+// checkNewPoll checks a new poll against the limits and blocked words of a given configuration and resolves its users
+func (p *MatterpollPlugin) checkNewPoll(newPoll *poll.Poll, configuration *configuration) error {
+	if err := configuration.checkLimits(newPoll); err != nil {
+		return err
+	}
+	if err := configuration.applyBlockedWords(newPoll); err != nil {
+		return err
+	}
+	return p.resolveUsers(newPoll)
+}
+
+// createPoll checks a new poll with the limits of a given team configuration, resolves the channels it gets cross-posted to
+// and posts it into a given channel, if its creator is allowed to create a poll there.
+// Returns the reason for the creator if the poll isn't posted because of the channel or the rate limit.
+// Returns an invalidPollError if the poll is invalid.
+func (p *MatterpollPlugin) createPoll(newPoll *poll.Poll, configuration *configuration, teamID, channelID, rootID string) (*i18n.Message, error) {
+	err := p.checkNewPoll(newPoll, configuration)
+	if err == nil {
+		err = p.resolveChannels(newPoll, teamID, channelID)
+	}
+	if err != nil {
+		return nil, &invalidPollError{err: err}
+	}
+
+	if p.isChannelDisabled(channelID) {
+		return responseCreatePollChannelDisabled, nil
+	}
+	if p.isPollCreationRestricted(channelID, newPoll.Creator) {
+		return responseCreatePollRestricted, nil
+	}
+	if p.isPollRateLimited(newPoll.Creator) {
+		return responseCreatePollRateLimited, nil
+	}
+
+	if err = p.postPoll(newPoll, channelID, rootID); err != nil {
+		return nil, err
+	}
+	p.countPollCreation(newPoll.Creator)
+	return nil, nil
+}
+
+// createPollFromCommand creates a new poll with createPoll and returns the response to the slash command of its creator
+func (p *MatterpollPlugin) createPollFromCommand(args *model.CommandArgs, userLocalizer *i18n.Localizer, configuration *configuration, newPoll *poll.Poll) (string, *model.AppError) {
+	reason, err := p.createPoll(newPoll, configuration, args.TeamId, args.ChannelId, args.RootId)
+	if invalidErr, ok := err.(*invalidPollError); ok {
+		return "", p.newInvalidInputError(userLocalizer, invalidErr.err)
+	}
+	if err != nil {
+		p.API.LogError("failed to create poll", "err", err.Error())
 		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
 	}
+	if reason != nil {
+		return p.LocalizeDefaultMessage(userLocalizer, reason), nil
+	}
+	if newPoll.IsScheduled() {
+		return p.LocalizeDefaultMessage(userLocalizer, responseCreatePollScheduled), nil
+	}
+	return "", nil
+}
+
+// newInvalidInputError returns the error of a slash command whose input is invalid
+func (p *MatterpollPlugin) newInvalidInputError(userLocalizer *i18n.Localizer, err error) *model.AppError {
+	return &model.AppError{
+		Id: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorInvalidInput,
+			TemplateData: map[string]interface{}{
+				"Error": p.localizeError(userLocalizer, err),
+			}}),
+		StatusCode: http.StatusBadRequest,
+		Where:      "ExecuteCommand",
+	}
+}
+
+// postPoll stores a new poll and posts it into a given channel. Scheduled polls get posted once they are due.
+func (p *MatterpollPlugin) postPoll(newPoll *poll.Poll, channelID, rootID string) error {
+	if newPoll.IsScheduled() {
+		newPoll.ChannelID = channelID
+		newPoll.RootID = rootID
+	}
+	newPoll.PageSize = p.getConfiguration().answerOptionsPerPage
+	newPoll.CompactAbove = p.getConfiguration().compactAbove
+
+	if err := p.Store.Poll().Save(newPoll); err != nil {
+		return errors.Wrap(err, "failed to save poll")
+	}
+
+	if newPoll.IsScheduled() {
+		if err := p.schedulePost(newPoll); err != nil {
+			return errors.Wrap(err, "failed to schedule poll post")
+		}
+		return nil
+	}
+	return p.publishPoll(newPoll, channelID, rootID)
+}
+
+// publishPoll creates the post that displays a stored poll and schedules its end and recurrence
+func (p *MatterpollPlugin) publishPoll(newPoll *poll.Poll, channelID, rootID string) error {
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(newPoll.Creator)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	actions, appErr := p.makePollAttachments(newPoll, displayName)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get poll attachments")
+	}
+	newPoll.ChannelID = channelID
+	if newPoll.Settings.EndWhenAllVoted {
+		eligibleVoters, appErr := p.getPollEligibleVoters(newPoll)
+		if appErr != nil {
+			return errors.Wrap(appErr, "failed to get eligible voters")
+		}
+		newPoll.EligibleVoters = eligibleVoters
+	}
 
-	actions := newPoll.ToPostActions(publicLocalizer, *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName)
 	post := &model.Post{
 		UserId:    p.botUserID,
-		ChannelId: args.ChannelId,
-		RootId:    args.RootId,
+		ChannelId: channelID,
+		RootId:    rootID,
 		Type:      model.POST_DEFAULT,
 	}
 	model.ParseSlackAttachment(post, actions)
 
-	if _, appErr = p.API.CreatePost(post); appErr != nil {
-		p.API.LogError("failed to post poll post", "error", appErr.Error())
-		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	rpost, appErr := p.API.CreatePost(post)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to post poll post")
+	}
+	if newPoll.Settings.Pin {
+		p.pinPollPost(newPoll, rpost)
+	}
+
+	newPoll.PostID = rpost.Id
+	newPoll.ChannelID = rpost.ChannelId
+	p.crossPostPoll(newPoll, actions)
+	if err := p.Store.Poll().Save(newPoll); err != nil {
+		return errors.Wrap(err, "failed to save poll")
 	}
 
+	if err := p.scheduleEnd(newPoll); err != nil {
+		return errors.Wrap(err, "failed to schedule poll end")
+	}
+	if err := p.scheduleRepeat(newPoll); err != nil {
+		return errors.Wrap(err, "failed to schedule poll recurrence")
+	}
+	if err := p.scheduleDigest(newPoll); err != nil {
+		return errors.Wrap(err, "failed to schedule poll digest")
+	}
+
+	p.metrics.IncPollsCreated()
+	p.notifyWebhook(webhookEventPollCreated, newPoll, newPoll.Creator)
+	p.recordAudit(audit.ActionPollCreated, newPoll, newPoll.Creator, newPoll.Question)
 	p.API.LogDebug("Created a new poll", "post", post.ToJson())
-	return "", nil
+	return nil
+}
+
+// pinPollPost pins a given post of a new poll to its channel and marks the poll as pinned.
+// The poll keeps running unpinned if pinning fails, e.g. because the bot lacks the permission.
+func (p *MatterpollPlugin) pinPollPost(newPoll *poll.Poll, post *model.Post) {
+	pinnedPost := post.Clone()
+	pinnedPost.IsPinned = true
+	if _, appErr := p.API.UpdatePost(pinnedPost); appErr != nil {
+		p.API.LogWarn("failed to pin poll post", "pollID", newPoll.ID, "error", appErr.Error())
+		return
+	}
+	newPoll.Pinned = true
+}
+
+// openCreatePollDialog opens a dialog that lets the user create a poll without the command syntax
+func (p *MatterpollPlugin) openCreatePollDialog(args *model.CommandArgs) *model.AppError {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	publicLocalizer := p.getPublicLocalizer()
+
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: args.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/create", siteURL, manifest.ID),
+		Dialog: model.Dialog{
+			Title:       p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollTitle),
+			IconURL:     fmt.Sprintf(responseIconURL, siteURL, manifest.ID),
+			CallbackId:  args.RootId,
+			SubmitLabel: p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollSubmitLabel),
+			Elements: []model.DialogElement{{
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementQuestionDisplayName),
+				Name:        createPollQuestionKey,
+				Type:        "text",
+				SubType:     "text",
+			}, {
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementOptionsDisplayName),
+				Name:        createPollOptionsKey,
+				Type:        "textarea",
+				Optional:    true,
+				HelpText: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+					DefaultMessage: dialogCreatePollElementOptionsHelpText,
+					TemplateData: map[string]interface{}{
+						"Yes": p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes),
+						"No":  p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo),
+					},
+				}),
+			}, {
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementVoteModeDisplayName),
+				Name:        createPollVoteModeKey,
+				Type:        "select",
+				Default:     "single",
+				Options: []*model.PostActionOptions{{
+					Text:  p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementVoteModeSingle),
+					Value: "single",
+				}, {
+					Text:  p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementVoteModeRanked),
+					Value: string(poll.VoteModeRanked),
+				}, {
+					Text:  p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementVoteModeApproval),
+					Value: string(poll.VoteModeApproval),
+				}, {
+					Text:  p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementVoteModeRating),
+					Value: string(poll.VoteModeRating),
+				}, {
+					Text:  p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementVoteModeScheduling),
+					Value: string(poll.VoteModeScheduling),
+				}, {
+					Text:  p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementVoteModeEstimate),
+					Value: string(poll.VoteModeEstimate),
+				}},
+			}, {
+				DisplayName: p.LocalizeDefaultMessage(userLocalizer, dialogCreatePollElementSettingsDisplayName),
+				Name:        createPollSettingsKey,
+				Type:        "text",
+				SubType:     "text",
+				Optional:    true,
+				HelpText: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+					DefaultMessage: dialogCreatePollElementSettingsHelpText,
+					TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+				}),
+			}},
+		},
+	}
+
+	return p.API.OpenInteractiveDialog(dialog)
 }
 
 func (p *MatterpollPlugin) getCommand(trigger string) *model.Command {
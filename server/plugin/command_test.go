@@ -3,34 +3,235 @@ package plugin
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"bou.ke/monkey"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
 	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestPluginExecuteCommand(t *testing.T) {
 	trigger := "poll"
 	helpText := "To create a poll with the answer options \"Yes\" and \"No\" type `/poll \"Question\"`\n" +
 		"You can customize the options by typing `/poll \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"`\n" +
+		"Type `/poll` without any arguments to create a poll using a dialog\n" +
+		"If you prefer not to type commands, send a direct message to @matterpoll and it walks you through creating a poll step by step\n" +
+		"To export the results of an ended poll as CSV file, type `/poll export <poll ID>`\n" +
+		"To end or delete a poll without going to its post, type `/poll end <poll ID>` or `/poll delete <poll ID>`\n" +
+		"To restore a deleted poll before it gets removed for good, type `/poll restore <poll ID>`\n" +
+		"To reopen a poll that was ended by mistake, type `/poll reopen <poll ID>`. To give it a new deadline, add e.g. `--end=24h` or `--end=2024-06-01T17:00`\n" +
+		"To push back the deadline of a running poll, type `/poll extend <poll ID> 24h`. Instead of a duration, you can give the new deadline, e.g. `2024-06-01T17:00`\n" +
+		"To stop voting for a while without ending a poll, e.g. to discuss it in a meeting first, type `/poll pause <poll ID>`. To let users vote again, type `/poll resume <poll ID>`\n" +
+		"To hand a poll over to another user, e.g. before leaving the team, type `/poll transfer <poll ID> @username`. The new owner can end and delete the poll\n" +
+		"To let another user vote for you in a poll, type `/poll delegate <poll ID> @username`. Their votes count for you as well\n" +
+		"To see and cancel your scheduled polls, type `/poll scheduled`\n" +
+		"To see all running polls in this channel, type `/poll list`. To see all polls in this channel with a tag, type `/poll list --tag=TAG`\n" +
+		"To find polls by their question in all channels you are a member of, type `/poll search <text>`\n" +
+		"To see the polls you recently voted in and what you voted for, type `/poll myvotes`\n" +
+		"To see how users took part in a poll, type `/poll stats <poll ID>`\n" +
+		"To see who voted for which answer option in a poll with public votes, type `/poll voters <poll ID> [page]`\n" +
+		"System admins can see the audit log of a poll by typing `/poll audit <poll ID>` and get it as CSV file by typing `/poll audit <poll ID> --export`\n" +
+		"System admins can see all polls on this server, newest first, by typing `/poll admin list [page]`\n" +
+		"System admins can erase the votes and poll authorship of a user from all polls by typing `/poll admin erase <username or user ID>`\n" +
+		"System admins can get a backup of all polls, votes and settings as JSON file by typing `/poll admin export`\n" +
+		"System admins can see how polls are used on this server by typing `/poll admin usage`\n" +
+		"System admins can check the plugin for misconfiguration and failures by typing `/poll admin diagnose`\n" +
+		"Channel admins can disallow polls in the current channel by typing `/poll channel disable` and allow them again by typing `/poll channel enable`. They can allow only channel admins or System Admins to create polls by typing `/poll channel creators channel_admins` or `/poll channel creators system_admins`, and everyone again by typing `/poll channel creators everyone`. In direct and group messages, every member can\n" +
+		"Team admins can override the defaults of the plugin configuration for all polls in the current team by typing `/poll team set anonymous=true progress=true members-only=false max-options=10`. `/poll team` shows the defaults of the team and `/poll team reset` removes them\n" +
+		"To create a survey with several questions, type `/poll survey \"Title\" \"Question 1\" \"Question 2|Answer 1|Answer 2\"`. Questions without answer options get \"Yes\" and \"No\"\n" +
+		"To ask for a Net Promoter Score, type `/poll nps \"How likely are you to recommend us to a friend?\"`. Voters pick a score from 0 to 10 anonymously and the results show the score together with the share of promoters, passives and detractors\n" +
 		"Poll Settings provider further customization, e.g. `/poll \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --progress --anonymous`. The available Poll Settings are:\n" +
+		"- `--allow-other`: Add an \"Other…\" button that lets voters write in their own answer\n" +
 		"- `--anonymous`: Don't show who voted for what\n" +
+		"- `--anonymous-creator`: Don't show who created the poll\n" +
+		"- `--compact`: Vote via a single dropdown menu instead of a button per answer option\n" +
+		"- `--end-when-all-voted`: End the poll as soon as every member of the channel has voted\n" +
+		"- `--lock-votes`: Don't allow voters to change their vote once it's cast\n" +
+		"- `--members-only`: Only accept votes from members of the channel the poll is posted in\n" +
+		"- `--no-guests`: Don't let guest accounts vote\n" +
+		"- `--moderators=@USER,@USER`: Let the given users end, delete and export the poll and add options like the creator\n" +
+		"- `--weights=@USER:N,moderators:N`: Count the votes of the given users, of the `creator` or of the `moderators` N times, e.g. `--weights=@alice:3,moderators:2`\n" +
+		"- `--channels=CHANNEL,CHANNEL`: Post the poll into the given channels as well. All posts share the votes and show the same results\n" +
+		"- `--pin`: Pin the poll to the channel while it's running\n" +
 		"- `--progress`: During the poll, show how many votes each answer option got\n" +
-		"- `--public-add-option`: Allow all users to add additional options"
+		"- `--public-add-option`: Allow all users to add additional options\n" +
+		"- `--public-votes`: Show who voted for what while the poll is running\n" +
+		"- `--receipts`: Vote anonymously and get a receipt to verify your vote got counted\n" +
+		"- `--secret`: Hide the vote counts until the poll ends\n" +
+		"- `--sort-results`: Sort the results by votes and mark the leading answer option\n" +
+		"- `--tags=TAG,TAG`: Tag the poll, so it can be found with `list --tag=TAG`. Tags may contain letters, digits, - and _\n" +
+		"- `--vote-to-see`: Hide the vote counts and show voters the current results after they voted\n" +
+		"- `--votemode=ranked`: Let voters rank the answer options by preference. The winner is determined by instant-runoff voting\n" +
+		"- `--votemode=approval`: Let voters approve any number of answer options\n" +
+		"- `--votemode=rating`: Let voters rate every answer option from one to five stars. The results show the average rating\n" +
+		"- `--votemode=scheduling`: Find a date: every answer option is a date or time like `2024-06-03 10:00` and voters mark when they are available\n" +
+		"- `--votemode=estimate`: Let voters submit a number like story points or a budget instead of picking an answer option. The results show the count, mean, median and distribution of the numbers. Leave out the answer options\n" +
+		"- `--dates=FROM..TO`: Add a date for every day of a range to a scheduling poll. Add `--times=10:00,14:00` to get these times of every day instead\n" +
+		"- `--invite`: Attach a calendar invite for the best date to the results of a scheduling poll. Set the length of the event with `--duration`, e.g. `--duration=90m`\n" +
+		"- `--votes=X`: Let voters pick up to X answer options\n" +
+		"- `--max-per-option=N`: Let at most N users pick the same answer option\n" +
+		"- `--quorum=X%`: Mark the results as invalid unless at least X percent of the channel members voted, e.g. `--quorum=50%`\n" +
+		"- `--notify-at=X`: Send you a direct message once X users have voted, so you can decide whether to end the poll early\n" +
+		"- `--results-template=TEXT`: Announce the results with your own message, which can refer to `{{.Question}}`, `{{.Winner}}`, `{{.TotalVotes}}`, `{{.Voters}}`, `{{.Results}}`, `{{.Link}}`\n" +
+		"- `--end=TIME`: End the poll automatically after a duration, e.g. `--end=2h`, or at a time in UTC, e.g. `--end=2024-06-01T17:00`\n" +
+		"- `--schedule=TIME`: Post the poll later, after a duration, e.g. `--schedule=1h`, or at a time in UTC, e.g. `--schedule=\"2024-05-01 09:00\"`\n" +
+		"- `--repeat=INTERVAL`: Post a fresh copy of the poll `daily`, `weekly` or `monthly` and end the previous one, e.g. `--repeat=weekly`. Delete the latest poll to stop it\n" +
+		"- `--digest=INTERVAL`: Get a direct message with the current standings `daily`, `weekly` or `monthly` while the poll is running. `--digest` alone sends it daily\n" +
+		"- `--shuffle`: Show the answer options in random order to reduce position bias. `--shuffle=always` shuffles them again whenever the poll gets updated"
+
+	posted := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID2"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	paginated := func(p *poll.Poll) *poll.Poll {
+		p.PageSize = 2
+		return p
+	}
+	scheduled := func(p *poll.Poll) *poll.Poll {
+		p.Settings.PostAt = 1714554000000
+		p.ChannelID = "channelID1"
+		p.RootID = "postID1"
+		return p
+	}
+	scheduledByOtherUser := scheduled(testutils.GetPollTwoOptions())
+	scheduledByOtherUser.ID = "pollID2"
+	scheduledByOtherUser.Creator = "userID2"
+	postJob := job.NewJob(job.TypePostPoll, testutils.GetPollID(), 1714554000000)
+
+	newerPoll := posted(testutils.GetPollWithVotes())
+	newerPoll.ID = "pollID2"
+	newerPoll.PostID = "postID3"
+	newerPoll.CreatedAt = 1234567891
+	olderPoll := posted(testutils.GetPoll())
+	olderPoll.AnswerOptions[0].Voter = []string{"userID2"}
+	endedPoll := posted(testutils.GetPollWithVotes())
+	endedPoll.ID = "pollID3"
+	endedPoll.EndedAt = 1234567892
+	otherChannelPoll := posted(testutils.GetPoll())
+	otherChannelPoll.ID = "pollID4"
+	otherChannelPoll.ChannelID = "channelID2"
+	directMessagePoll := posted(testutils.GetPoll())
+	directMessagePoll.ID = "pollID5"
+	directMessagePoll.PostID = "postID4"
+	directMessagePoll.ChannelID = "channelID3"
+	manyPolls := []*poll.Poll{}
+	for i := 0; i <= maxSearchResults; i++ {
+		manyPolls = append(manyPolls, posted(testutils.GetPoll()))
+	}
+	auditEntries := []*audit.Entry{
+		{ID: "entryID1", PollID: testutils.GetPollID(), UserID: "userID2", Action: audit.ActionPollCreated, Details: "Question", CreatedAt: 1234567890},
+		{ID: "entryID2", PollID: testutils.GetPollID(), Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567891},
+		{ID: "entryID3", PollID: testutils.GetPollID(), UserID: testutils.GetBotUserID(), Action: audit.ActionPollEnded, CreatedAt: 1234567892},
+	}
+	systemAdmin := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	newSurvey := func() *poll.Poll {
+		return &poll.Poll{
+			ID:        testutils.GetPollID(),
+			CreatedAt: 1234567890,
+			Creator:   "userID1",
+			Question:  "Survey",
+			Questions: []*poll.Question{{
+				Question:      "Question 1",
+				AnswerOptions: []*poll.AnswerOption{{Answer: "Yes"}, {Answer: "No"}},
+			}, {
+				Question:      "Question 2",
+				AnswerOptions: []*poll.AnswerOption{{Answer: "Answer 1"}, {Answer: "Answer 2"}},
+			}},
+		}
+	}
+	newNPSPoll := func() *poll.Poll {
+		p := testutils.GetNPSPollWithVotes()
+		for _, o := range p.AnswerOptions {
+			o.Voter = nil
+		}
+		return p
+	}
 
 	for name, test := range map[string]struct {
 		SetupAPI     func(*plugintest.API) *plugintest.API
 		SetupStore   func(*mockstore.Store) *mockstore.Store
 		Command      string
+		TriggerID    string
 		ExpectedText string
 		ShouldError  bool
+		// AnswerOptionsPerPage overrides the configured number of answer options per page
+		AnswerOptionsPerPage int
+		// ChannelDisabled disallows polls in the channel of the command
+		ChannelDisabled bool
+		// ChannelCreators restricts who may create polls in the channel of the command
+		ChannelCreators string
 	}{
 		"No argument": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", model.OpenDialogRequest{
+					TriggerId: "triggerID1",
+					URL:       fmt.Sprintf("%s/plugins/%s/api/v1/polls/create", testutils.GetSiteURL(), manifest.ID),
+					Dialog: model.Dialog{
+						Title:       "Create Poll",
+						IconURL:     fmt.Sprintf(responseIconURL, testutils.GetSiteURL(), manifest.ID),
+						CallbackId:  "postID1",
+						SubmitLabel: "Create",
+						Elements: []model.DialogElement{{
+							DisplayName: "Question",
+							Name:        "question",
+							Type:        "text",
+							SubType:     "text",
+						}, {
+							DisplayName: "Answer Options",
+							Name:        "options",
+							Type:        "textarea",
+							Optional:    true,
+							HelpText:    "One answer option per line. To describe an answer option or show an image next to it, add a description or image URL, e.g. \"Logo A|Our current logo|https://example.com/a.png\". Leave empty to use \"Yes\" and \"No\".",
+						}, {
+							DisplayName: "Vote Mode",
+							Name:        "votemode",
+							Type:        "select",
+							Default:     "single",
+							Options: []*model.PostActionOptions{
+								{Text: "Single choice", Value: "single"},
+								{Text: "Ranked choice", Value: "ranked"},
+								{Text: "Approval", Value: "approval"},
+								{Text: "Rating", Value: "rating"},
+								{Text: "Scheduling", Value: "scheduling"},
+								{Text: "Estimate", Value: "estimate"},
+							},
+						}, {
+							DisplayName: "Poll Settings",
+							Name:        "settings",
+							Type:        "text",
+							SubType:     "text",
+							Optional:    true,
+							HelpText:    "Space separated list of Poll Settings, e.g. `anonymous progress end=2h`. Type `/poll help` to see all of them.",
+						}},
+					},
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:    fmt.Sprintf("/%s", trigger),
+			TriggerID:  "triggerID1",
+		},
+		"No argument, OpenInteractiveDialog fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("OpenInteractiveDialog", mock.AnythingOfType("model.OpenDialogRequest")).Return(&model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s", trigger),
+			TriggerID:    "triggerID1",
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"No argument, without trigger ID": {
 			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
 			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
 			Command:      fmt.Sprintf("/%s", trigger),
@@ -48,6 +249,50 @@ func TestPluginExecuteCommand(t *testing.T) {
 			Command:     fmt.Sprintf("/%s \"Question\" \"Just one option\"", trigger),
 			ShouldError: true,
 		},
+		"Channel disabled": {
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:         fmt.Sprintf("/%s \"Question\"", trigger),
+			ChannelDisabled: true,
+			ExpectedText:    responseCreatePollChannelDisabled.Other,
+		},
+		"No argument, channel disabled": {
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:         fmt.Sprintf("/%s", trigger),
+			TriggerID:       "triggerID1",
+			ChannelDisabled: true,
+			ExpectedText:    responseCreatePollChannelDisabled.Other,
+		},
+		"Survey, channel disabled": {
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:         fmt.Sprintf("/%s survey \"Survey\" \"Question 1\"", trigger),
+			ChannelDisabled: true,
+			ExpectedText:    responseCreatePollChannelDisabled.Other,
+		},
+		"Channel restricted to system admins": {
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:         fmt.Sprintf("/%s \"Question\"", trigger),
+			ChannelCreators: pollCreatorsSystemAdmins,
+			ExpectedText:    responseCreatePollRestricted.Other,
+		},
+		"No argument, channel restricted to system admins": {
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:         fmt.Sprintf("/%s", trigger),
+			TriggerID:       "triggerID1",
+			ChannelCreators: pollCreatorsSystemAdmins,
+			ExpectedText:    responseCreatePollRestricted.Other,
+		},
+		"Survey, channel restricted to system admins": {
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:         fmt.Sprintf("/%s survey \"Survey\" \"Question 1\"", trigger),
+			ChannelCreators: pollCreatorsSystemAdmins,
+			ExpectedText:    responseCreatePollRestricted.Other,
+		},
 		"Just question": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
@@ -61,11 +306,12 @@ func TestPluginExecuteCommand(t *testing.T) {
 				}
 				actions := testutils.GetPollTwoOptions().ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
 				model.ParseSlackAttachment(post, actions)
-				api.On("CreatePost", post).Return(post, nil)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Save", testutils.GetPollTwoOptions()).Return(nil)
+				store.PollStore.On("Save", posted(testutils.GetPollTwoOptions())).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\"", trigger),
@@ -93,6 +339,33 @@ func TestPluginExecuteCommand(t *testing.T) {
 			Command:      fmt.Sprintf("/%s \"Question\"", trigger),
 			ExpectedText: commandErrorGeneric.Other,
 		},
+		"Just question, estimate poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				p := testutils.GetPollWithEstimates()
+				p.Estimates = nil
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				p := testutils.GetPollWithEstimates()
+				p.Estimates = nil
+				store.PollStore.On("Save", p).Return(nil)
+				store.PollStore.On("Save", posted(p.Copy())).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" --votemode=estimate", trigger),
+		},
 		"With 4 arguments": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
@@ -106,15 +379,75 @@ func TestPluginExecuteCommand(t *testing.T) {
 				}
 				actions := testutils.GetPoll().ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
 				model.ParseSlackAttachment(post, actions)
-				api.On("CreatePost", post).Return(post, nil)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				store.PollStore.On("Save", testutils.GetPoll()).Return(nil)
+				store.PollStore.On("Save", posted(testutils.GetPoll())).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
 		},
+		"With 4 arguments, paginated": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := paginated(testutils.GetPoll()).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", paginated(testutils.GetPoll())).Return(nil)
+				store.PollStore.On("Save", posted(paginated(testutils.GetPoll()))).Return(nil)
+				return store
+			},
+			Command:              fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\"", trigger),
+			AnswerOptionsPerPage: 2,
+		},
+		"With 4 arguments and setting end-when-all-voted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{DeleteAt: 1}, nil)
+				api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{IsBot: true}, nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(&model.ChannelMembers{
+					{UserId: "userID1"},
+					{UserId: "userID2"},
+					{UserId: "userID3"},
+					{UserId: testutils.GetBotUserID()},
+				}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := testutils.GetPollWithSettings(poll.Settings{EndWhenAllVoted: true}).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{EndWhenAllVoted: true})
+				store.PollStore.On("Save", poll).Return(nil)
+				postedPoll := posted(poll.Copy())
+				postedPoll.EligibleVoters = []string{"userID1", "userID2"}
+				store.PollStore.On("Save", postedPoll).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --end-when-all-voted", trigger),
+		},
 		"With 4 arguments and settting progress": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
@@ -128,16 +461,72 @@ func TestPluginExecuteCommand(t *testing.T) {
 				}
 				actions := testutils.GetPollWithSettings(poll.Settings{Progress: true}).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
 				model.ParseSlackAttachment(post, actions)
-				api.On("CreatePost", post).Return(post, nil)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				poll := testutils.GetPollWithSettings(poll.Settings{Progress: true})
 				store.PollStore.On("Save", poll).Return(nil)
+				store.PollStore.On("Save", posted(poll.Copy())).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --progress", trigger),
 		},
+		"With 4 arguments and settting pin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := testutils.GetPollWithSettings(poll.Settings{Pin: true}).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("UpdatePost", &model.Post{Id: "postID2", ChannelId: "channelID1", IsPinned: true}).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{Pin: true})
+				store.PollStore.On("Save", poll).Return(nil)
+				postedPoll := posted(poll.Copy())
+				postedPoll.Pinned = true
+				store.PollStore.On("Save", postedPoll).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --pin", trigger),
+		},
+		"With 4 arguments and settting pin, pinning fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := testutils.GetPollWithSettings(poll.Settings{Pin: true}).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("UpdatePost", &model.Post{Id: "postID2", ChannelId: "channelID1", IsPinned: true}).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{Pin: true})
+				store.PollStore.On("Save", poll).Return(nil)
+				postedPoll := posted(poll.Copy())
+				postedPoll.Pinned = false
+				store.PollStore.On("Save", postedPoll).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --pin", trigger),
+		},
 		"With 4 arguments and settting anonymous and progress": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
@@ -151,16 +540,74 @@ func TestPluginExecuteCommand(t *testing.T) {
 				}
 				actions := testutils.GetPollWithSettings(poll.Settings{Progress: true, Anonymous: true}).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
 				model.ParseSlackAttachment(post, actions)
-				api.On("CreatePost", post).Return(post, nil)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
 				return api
 			},
 			SetupStore: func(store *mockstore.Store) *mockstore.Store {
 				poll := testutils.GetPollWithSettings(poll.Settings{Progress: true, Anonymous: true})
 				store.PollStore.On("Save", poll).Return(nil)
+				store.PollStore.On("Save", posted(poll.Copy())).Return(nil)
 				return store
 			},
 			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --anonymous --progress", trigger),
 		},
+		"With 4 arguments and settting end": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := testutils.GetPollWithSettings(poll.Settings{EndAt: 1241767890}).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				// The post is created two hours before the deadline
+				actions[0].Footer = "Ends in 2h"
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{EndAt: 1241767890})
+				store.PollStore.On("Save", poll).Return(nil)
+				store.PollStore.On("Save", posted(poll.Copy())).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1241767890)).Return(nil)
+				// The countdown changes to "Ends in 1h" right after the post is created
+				store.JobStore.On("Save", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 1234567891)).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --end=2h", trigger),
+		},
+		"JobStore.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := testutils.GetPollWithSettings(poll.Settings{EndAt: 1241767890}).ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				// The post is created two hours before the deadline
+				actions[0].Footer = "Ends in 2h"
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				poll := testutils.GetPollWithSettings(poll.Settings{EndAt: 1241767890})
+				store.PollStore.On("Save", poll).Return(nil)
+				store.PollStore.On("Save", posted(poll.Copy())).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1241767890)).Return(errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --end=2h", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
 		"Store.Save fails": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
@@ -193,6 +640,1012 @@ func TestPluginExecuteCommand(t *testing.T) {
 			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" \"Answer 3\" --unkownOption", trigger),
 			ShouldError: true,
 		},
+		"Unknown moderator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "alice").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" --moderators=@alice", trigger),
+			ShouldError: true,
+		},
+		"Unknown weighted user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "alice").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" --weights=@alice:3", trigger),
+			ShouldError: true,
+		},
+		"Unknown cross-post channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" --channels=~dev", trigger),
+			ShouldError: true,
+		},
+		"Creator isn't a member of cross-post channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("GetChannelMember", "channelID2", "userID1").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s \"Question\" \"Answer 1\" \"Answer 2\" --channels=dev", trigger),
+			ShouldError: true,
+		},
+		"Survey": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := newSurvey().ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", newSurvey()).Return(nil)
+				store.PollStore.On("Save", posted(newSurvey())).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s survey \"Survey\" \"Question 1\" \"Question 2|Answer 1|Answer 2\"", trigger),
+		},
+		"Survey, Store.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", newSurvey()).Return(&model.AppError{})
+				return store
+			},
+			Command:      fmt.Sprintf("/%s survey \"Survey\" \"Question 1\" \"Question 2|Answer 1|Answer 2\"", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Survey without questions": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s survey \"Survey\"", trigger),
+			ExpectedText: "Usage: `/poll survey \"Title\" \"Question 1\" \"Question 2|Answer 1|Answer 2\"`",
+		},
+		"Survey with invalid setting": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s survey \"Survey\" \"Question 1\" --votemode=ranked", trigger),
+			ShouldError: true,
+		},
+		"NPS poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+
+				post := &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					RootId:    "postID1",
+					Type:      model.POST_DEFAULT,
+				}
+				actions := newNPSPoll().ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe")
+				model.ParseSlackAttachment(post, actions)
+				api.On("CreatePost", post).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", newNPSPoll()).Return(nil)
+				store.PollStore.On("Save", posted(newNPSPoll())).Return(nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s nps \"Question\"", trigger),
+		},
+		"NPS poll without question": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s nps", trigger),
+			ExpectedText: "Usage: `/poll nps \"Question\"`",
+		},
+		"NPS poll with answer options": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s nps \"Question\" \"Answer 1\" \"Answer 2\"", trigger),
+			ExpectedText: "Usage: `/poll nps \"Question\"`",
+		},
+		"NPS poll with invalid setting": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:  func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:     fmt.Sprintf("/%s nps \"Question\" --votemode=ranked", trigger),
+			ShouldError: true,
+		},
+		"Scheduled poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", scheduled(testutils.GetPollTwoOptions())).Return(nil)
+				store.JobStore.On("Save", postJob).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s \"Question\" --schedule=\"2024-05-01 09:00\"", trigger),
+			ExpectedText: responseCreatePollScheduled.Other,
+		},
+		"Scheduled poll, JobStore.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", scheduled(testutils.GetPollTwoOptions())).Return(nil)
+				store.JobStore.On("Save", postJob).Return(errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s \"Question\" --schedule=\"2024-05-01 09:00\"", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"List scheduled polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{
+					job.NewJob(job.TypeEndPoll, "pollID3", 1234567890),
+					job.NewJob(job.TypePostPoll, "pollID2", 1714554000000),
+					postJob,
+				}, nil)
+				store.PollStore.On("Get", "pollID2").Return(scheduledByOtherUser, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduled(testutils.GetPollTwoOptions()), nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s scheduled", trigger),
+			ExpectedText: "Your scheduled polls:\n" +
+				"- **Question** gets posted at 2024-05-01T09:00 UTC. Poll ID: `" + testutils.GetPollID() + "`\n" +
+				"To cancel a scheduled poll, type `/poll scheduled cancel <poll ID>`",
+		},
+		"List scheduled polls, no polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{job.NewJob(job.TypeEndPoll, "pollID3", 1234567890)}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled", trigger),
+			ExpectedText: commandScheduledNone.Other,
+		},
+		"List scheduled polls, JobStore.List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Cancel scheduled poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduled(testutils.GetPollTwoOptions()), nil)
+				store.JobStore.On("Delete", postJob).Return(nil)
+				store.PollStore.On("Delete", scheduled(testutils.GetPollTwoOptions())).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled cancel %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandScheduledCanceled.Other,
+		},
+		"Cancel poll that is not scheduled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled cancel %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorScheduledNotFound.Other,
+		},
+		"Cancel scheduled poll, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID2").Return(scheduledByOtherUser, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled cancel pollID2", trigger),
+			ExpectedText: commandErrorScheduledInvalidPermission.Other,
+		},
+		"Cancel scheduled poll, PollStore.Delete fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduled(testutils.GetPollTwoOptions()), nil)
+				store.JobStore.On("Delete", postJob).Return(nil)
+				store.PollStore.On("Delete", scheduled(testutils.GetPollTwoOptions())).Return(errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s scheduled cancel %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Scheduled with invalid arguments": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s scheduled delete", trigger),
+			ExpectedText: "Usage: `/poll scheduled [cancel <poll ID>]`",
+		},
+		"List polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", "channelID1").Return([]*poll.Poll{newerPoll, endedPoll, scheduledByOtherUser, olderPoll}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s list", trigger),
+			ExpectedText: "Running polls in this channel:\n" +
+				"- [Question](https://example.org/team1/pl/postID2) by user1: 1 vote\n" +
+				"- [Question](https://example.org/team1/pl/postID3) by user1: 4 votes",
+		},
+		"List polls, anonymous creator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				anonymousCreatorPoll := newerPoll.Copy()
+				anonymousCreatorPoll.Settings.AnonymousCreator = true
+				store.PollStore.On("ListByChannel", "channelID1").Return([]*poll.Poll{anonymousCreatorPoll, olderPoll}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s list", trigger),
+			ExpectedText: "Running polls in this channel:\n" +
+				"- [Question](https://example.org/team1/pl/postID2) by user1: 1 vote\n" +
+				"- [Question](https://example.org/team1/pl/postID3): 4 votes",
+		},
+		"List polls, deadline in the timezone of the user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", Timezone: model.StringMap{"useAutomaticTimezone": "false", "manualTimezone": "Europe/Berlin"}}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pollWithDeadline := olderPoll.Copy()
+				pollWithDeadline.Settings.EndAt = 1717261200000
+				store.PollStore.On("ListByChannel", "channelID1").Return([]*poll.Poll{pollWithDeadline}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s list", trigger),
+			ExpectedText: "Running polls in this channel:\n" +
+				"- [Question](https://example.org/team1/pl/postID2) by user1: 1 vote (ends 2024-06-01 19:00 CEST)",
+		},
+		"List polls, deadline without timezone": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				pollWithDeadline := olderPoll.Copy()
+				pollWithDeadline.Settings.EndAt = 1717261200000
+				store.PollStore.On("ListByChannel", "channelID1").Return([]*poll.Poll{pollWithDeadline}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s list", trigger),
+			ExpectedText: "Running polls in this channel:\n" +
+				"- [Question](https://example.org/team1/pl/postID2) by user1: 1 vote (ends 2024-06-01 17:00 UTC)",
+		},
+		"List polls, no polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", "channelID1").Return([]*poll.Poll{endedPoll, scheduledByOtherUser}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s list", trigger),
+			ExpectedText: commandListNone.Other,
+		},
+		"List polls, PollStore.ListByChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", "channelID1").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s list", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"List polls, GetTeam fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", "channelID1").Return([]*poll.Poll{olderPoll}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s list", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"List polls by tag": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByTag", "retro").Return([]*poll.Poll{endedPoll, newerPoll, scheduledByOtherUser, otherChannelPoll}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s list --tag=#Retro", trigger),
+			ExpectedText: "Polls tagged \"retro\" in this channel, newest first:\n" +
+				"- [Question](https://example.org/team1/pl/postID2) by user1: 4 votes (ended)\n" +
+				"- [Question](https://example.org/team1/pl/postID3) by user1: 4 votes",
+		},
+		"List polls by tag, no polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByTag", "retro").Return([]*poll.Poll{scheduledByOtherUser, otherChannelPoll}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s list --tag=retro", trigger),
+			ExpectedText: "There are no polls tagged \"retro\" in this channel.",
+		},
+		"List polls by tag, too many polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByTag", "retro").Return(manyPolls, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s list --tag=retro", trigger),
+			ExpectedText: "Polls tagged \"retro\" in this channel, newest first:\n" +
+				strings.Repeat("- [Question](https://example.org/team1/pl/postID2) by user1: 0 votes\n", maxSearchResults) +
+				"Only the newest 20 polls are shown.",
+		},
+		"List polls by tag, PollStore.ListByTag fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByTag", "retro").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s list --tag=retro", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"List with invalid arguments": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s list all", trigger),
+			ExpectedText: "Usage: `/poll list [--tag=TAG]`",
+		},
+		"List with empty tag": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s list --tag=", trigger),
+			ExpectedText: "Usage: `/poll list [--tag=TAG]`",
+		},
+		"Search polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+				api.On("GetChannelMember", "channelID2", "userID1").Return(nil, &model.AppError{})
+				api.On("GetChannelMember", "channelID3", "userID1").Return(&model.ChannelMember{}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Name: "town-square", TeamId: "teamID1"}, nil)
+				api.On("GetChannel", "channelID3").Return(&model.Channel{Name: "userid1__userid2"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Search", "question").Return([]*poll.Poll{endedPoll, newerPoll, scheduledByOtherUser, otherChannelPoll, directMessagePoll}, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s search question", trigger),
+			ExpectedText: "Polls matching \"question\", newest first:\n" +
+				"- [Question](https://example.org/team1/pl/postID2) in ~town-square (ended)\n" +
+				"- [Question](https://example.org/team1/pl/postID3) in ~town-square\n" +
+				"- [Question](https://example.org/team1/pl/postID4) in ~userid1__userid2",
+		},
+		"Search polls, no matches": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Search", "lunch friday").Return([]*poll.Poll{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s search lunch  friday", trigger),
+			ExpectedText: "No polls match \"lunch friday\".",
+		},
+		"Search polls, too many matches": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Name: "town-square", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Search", "question").Return(manyPolls, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s search question", trigger),
+			ExpectedText: "Polls matching \"question\", newest first:\n" +
+				strings.Repeat("- [Question](https://example.org/team1/pl/postID2) in ~town-square\n", maxSearchResults) +
+				"Only the newest 20 polls are shown. Add more words to narrow down the search.",
+		},
+		"Search polls, Search fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Search", "question").Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s search question", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Search polls, GetChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+				api.On("GetChannel", "channelID1").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Search", "question").Return([]*poll.Poll{newerPoll}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s search question", trigger),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Search without text": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s search", trigger),
+			ExpectedText: "Usage: `/poll search <text>`",
+		},
+		"Export without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s export", trigger),
+			ExpectedText: "Usage: `/poll export <poll ID>`",
+		},
+		"Export poll that has not ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s export %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseExportPollNotEnded.Other,
+		},
+		"Export, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			Command:      fmt.Sprintf("/%s export %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"End poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+				expectedPost, appErr := posted(testutils.GetPoll()).ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe", nil)
+				if appErr != nil {
+					t.Fatal(appErr)
+				}
+				expectedPost.Id = "postID2"
+				expectedPost.ChannelId = "channelID1"
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetPost", "postID2").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(GetMockPollUpdate(posted(testutils.GetPoll())))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandEndSuccess.Other,
+		},
+		"End poll without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s end", trigger),
+			ExpectedText: "Usage: `/poll end <poll ID>`",
+		},
+		"End poll, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				otherUsersPoll := posted(testutils.GetPoll())
+				otherUsersPoll.Creator = "userID2"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(otherUsersPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseEndPollInvalidPermission.Other,
+		},
+		"End poll that has already ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", "pollID3").Return(endedPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end pollID3", trigger),
+			ExpectedText: commandErrorEndAlreadyEnded.Other,
+		},
+		"End poll that has been deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				deletedPoll := posted(testutils.GetPoll())
+				deletedPoll.DeletedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseDeletePollAlreadyDeleted.Other,
+		},
+		"End poll that is scheduled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduled(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end %s", trigger, testutils.GetPollID()),
+			ExpectedText: "This poll hasn't been posted yet. Type `/poll scheduled` to see and cancel your scheduled polls.",
+		},
+		"End poll, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s end %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Delete poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", "postID2").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				store.PollStore.On("Delete", posted(testutils.GetPoll())).Return(nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s delete %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseDeletePollSuccess.Other,
+		},
+		"Delete poll without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s delete", trigger),
+			ExpectedText: "Usage: `/poll delete <poll ID>`",
+		},
+		"Delete poll, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				otherUsersPoll := posted(testutils.GetPoll())
+				otherUsersPoll.Creator = "userID2"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(otherUsersPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s delete %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseDeletePollInvalidPermission.Other,
+		},
+		"Delete poll that is scheduled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduled(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s delete %s", trigger, testutils.GetPollID()),
+			ExpectedText: "This poll hasn't been posted yet. Type `/poll scheduled` to see and cancel your scheduled polls.",
+		},
+		"Delete poll, DeletePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", "postID2").Return(&model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s delete %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Delete poll that is already deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				deletedPoll := posted(testutils.GetPoll())
+				deletedPoll.DeletedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s delete %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseDeletePollAlreadyDeleted.Other,
+		},
+		"Restore poll that isn't deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s restore %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseRestorePollNotDeleted.Other,
+		},
+		"Restore poll without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s restore", trigger),
+			ExpectedText: "Usage: `/poll restore <poll ID>`",
+		},
+		"Reopen poll that hasn't ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s reopen %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseReopenPollNotEnded.Other,
+		},
+		"Reopen poll without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s reopen", trigger),
+			ExpectedText: "Usage: `/poll reopen <poll ID> [--end=TIME]`",
+		},
+		"Reopen poll with unknown parameter": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s reopen %s --until=24h", trigger, testutils.GetPollID()),
+			ExpectedText: "Usage: `/poll reopen <poll ID> [--end=TIME]`",
+		},
+		"Extend poll without duration": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s extend %s", trigger, testutils.GetPollID()),
+			ExpectedText: "Usage: `/poll extend <poll ID> <duration or time>`",
+		},
+		"Extend poll without deadline": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s extend %s 24h", trigger, testutils.GetPollID()),
+			ExpectedText: responseExtendPollNoDeadline.Other,
+		},
+		"Pause poll without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s pause", trigger),
+			ExpectedText: "Usage: `/poll pause <poll ID>`",
+		},
+		"Resume poll without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s resume", trigger),
+			ExpectedText: "Usage: `/poll resume <poll ID>`",
+		},
+		"List voters without poll ID": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s voters", trigger),
+			ExpectedText: "Usage: `/poll voters <poll ID> [page]`",
+		},
+		"List voters with invalid page": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s voters %s 0", trigger, testutils.GetPollID()),
+			ExpectedText: "Usage: `/poll voters <poll ID> [page]`",
+		},
+		"List voters of poll in channel user isn't a member of": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID1").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPollWithVotesAndSettings(poll.Settings{PublicVotes: true})), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s voters %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseShowVotersInvalidPermission.Other,
+		},
+		"List voters": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID1").Return(&model.ChannelMember{}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+				api.On("GetUser", "userID4").Return(&model.User{Username: "user4", Nickname: "Four"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPollWithVotesAndSettings(poll.Settings{PublicVotes: true})), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s voters %s", trigger, testutils.GetPollID()),
+			ExpectedText: "Voters of **Question** (page 1 of 1):\n**Answer 1** (3 votes): @user1, @user2, @user3\n**Answer 2** (1 vote): @user4",
+		},
+		"Resume poll that isn't paused": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s resume %s", trigger, testutils.GetPollID()),
+			ExpectedText: responseResumePollNotPaused.Other,
+		},
+		"Reopen poll with invalid end time": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s reopen %s --end=tomorrow", trigger, testutils.GetPollID()),
+			ExpectedText: responseReopenPollInvalidEnd.Other,
+		},
+		"Reopen poll with end time in the past": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s reopen %s --end=1970-01-01T12:00", trigger, testutils.GetPollID()),
+			ExpectedText: responseReopenPollInvalidEnd.Other,
+		},
+		"Transfer poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				// The updated post shows the new owner
+				api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetPost", "postID2").Return(&model.Post{Id: "postID2"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID2"
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(GetMockPollUpdate(posted(testutils.GetPoll())))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: responseTransferPollSuccess.Other,
+		},
+		"Delegate vote without delegate": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s delegate %s", trigger, testutils.GetPollID()),
+			ExpectedText: "Usage: `/poll delegate <poll ID> @username`",
+		},
+		"Delegate vote, unknown user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s delegate %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: responseDelegateVoteUnknownUser.Other,
+		},
+		"Delegate vote, delegations can't be chained": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				delegatedPoll := posted(testutils.GetPoll())
+				delegatedPoll.Delegations = map[string]string{"userID2": "userID3"}
+				store.PollStore.On("Get", testutils.GetPollID()).Return(delegatedPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s delegate %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: "Delegations can't be chained. Either votes got delegated to you or @user2 delegated their vote.",
+		},
+		"Transfer poll without new owner": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s transfer %s", trigger, testutils.GetPollID()),
+			ExpectedText: "Usage: `/poll transfer <poll ID> @username`",
+		},
+		"Transfer poll, unknown user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s transfer %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: responseTransferPollUnknownUser.Other,
+		},
+		"Transfer poll, invalid permission": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user3").Return(&model.User{Id: "userID3", Username: "user3"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				otherUsersPoll := posted(testutils.GetPoll())
+				otherUsersPoll.Creator = "userID2"
+				store.PollStore.On("Get", testutils.GetPollID()).Return(otherUsersPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user3", trigger, testutils.GetPollID()),
+			ExpectedText: responseTransferPollInvalidPermission.Other,
+		},
+		"Transfer poll that has been deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user3").Return(&model.User{Id: "userID3", Username: "user3"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				deletedPoll := posted(testutils.GetPoll())
+				deletedPoll.DeletedAt = 1234567890
+				store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user3", trigger, testutils.GetPollID()),
+			ExpectedText: responseDeletePollAlreadyDeleted.Other,
+		},
+		"Transfer poll to a bot": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "matterpoll").Return(&model.User{Id: testutils.GetBotUserID(), Username: "matterpoll", IsBot: true}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @matterpoll", trigger, testutils.GetPollID()),
+			ExpectedText: responseTransferPollBot.Other,
+		},
+		"Transfer poll to its owner": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user1", trigger, testutils.GetPollID()),
+			ExpectedText: responseTransferPollAlreadyOwner.Other,
+		},
+		"Transfer poll, updating the post fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetPost", "postID2").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(GetMockPollUpdate(posted(testutils.GetPoll())))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: responseTransferPollSuccess.Other,
+		},
+		"Transfer poll, PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(posted(testutils.GetPoll()), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s transfer %s @user2", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Audit": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{Username: "matterpoll"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.AuditStore.On("ListByPoll", testutils.GetPollID()).Return(auditEntries, nil)
+				return store
+			},
+			Command: fmt.Sprintf("/%s audit %s", trigger, testutils.GetPollID()),
+			ExpectedText: "Audit log of poll `" + testutils.GetPollID() + "`:\n" +
+				"- 1970-01-15T06:56 UTC: @user2 created the poll: Question\n" +
+				"- 1970-01-15T06:56 UTC: An anonymous user voted: Answer 1\n" +
+				"- 1970-01-15T06:56 UTC: @matterpoll ended the poll",
+		},
+		"Audit, no entries": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.AuditStore.On("ListByPoll", testutils.GetPollID()).Return([]*audit.Entry{}, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s audit %s", trigger, testutils.GetPollID()),
+			ExpectedText: auditListNone.Other,
+		},
+		"Audit, AuditStore.ListByPoll fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.AuditStore.On("ListByPoll", testutils.GetPollID()).Return(nil, errors.New(""))
+				return store
+			},
+			Command:      fmt.Sprintf("/%s audit %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Audit export": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{Username: "matterpoll"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("UploadFile", []byte("Time,User,Action,Details\n"+
+					"1970-01-15T06:56:07Z,@user2,poll_created,Question\n"+
+					"1970-01-15T06:56:07Z,An anonymous user,voted,Answer 1\n"+
+					"1970-01-15T06:56:07Z,@matterpoll,poll_ended,\n"), "channelID2", "poll-"+testutils.GetPollID()+"-audit.csv").Return(&model.FileInfo{Id: "fileID1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Message:   "Here is the audit log of poll `" + testutils.GetPollID() + "`.",
+					FileIds:   []string{"fileID1"},
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.AuditStore.On("ListByPoll", testutils.GetPollID()).Return(auditEntries, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s audit %s --export", trigger, testutils.GetPollID()),
+			ExpectedText: auditExportSuccess.Other,
+		},
+		"Audit export, UploadFile fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+				api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{Username: "matterpoll"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("UploadFile", mock.AnythingOfType("[]uint8"), "channelID2", "poll-"+testutils.GetPollID()+"-audit.csv").Return(nil, &model.AppError{})
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.AuditStore.On("ListByPoll", testutils.GetPollID()).Return(auditEntries, nil)
+				return store
+			},
+			Command:      fmt.Sprintf("/%s audit %s --export", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"Audit by a user who isn't a system admin": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s audit %s", trigger, testutils.GetPollID()),
+			ExpectedText: commandErrorAuditInvalidPermission.Other,
+		},
+		"Audit with invalid arguments": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Command:      fmt.Sprintf("/%s audit %s --csv", trigger, testutils.GetPollID()),
+			ExpectedText: "Usage: `/poll audit <poll ID> [--export]`",
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			assert := assert.New(t)
@@ -209,9 +1662,13 @@ func TestPluginExecuteCommand(t *testing.T) {
 			}
 			defer api.AssertExpectations(t)
 			store := test.SetupStore(&mockstore.Store{})
+			store.ChannelStore.On("IsDisabled", "channelID1").Return(test.ChannelDisabled, nil).Maybe()
+			store.ChannelStore.On("GetCreators", "channelID1").Return(test.ChannelCreators, nil).Maybe()
+			store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil).Maybe()
 			defer store.AssertExpectations(t)
 			p := setupTestPlugin(t, api, store)
 			p.configuration.Trigger = trigger
+			p.configuration.answerOptionsPerPage = test.AnswerOptionsPerPage
 
 			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
 			patch2 := monkey.Patch(model.NewId, func() string { return testutils.GetPollID() })
@@ -222,7 +1679,9 @@ func TestPluginExecuteCommand(t *testing.T) {
 				Command:   test.Command,
 				UserId:    "userID1",
 				ChannelId: "channelID1",
+				TeamId:    "teamID1",
 				RootId:    "postID1",
+				TriggerId: test.TriggerID,
 			})
 
 			assert.Equal(&model.CommandResponse{}, r)
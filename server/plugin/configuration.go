@@ -1,14 +1,425 @@
 package plugin
 
 import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/team"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
 )
 
+var (
+	limitErrorQuestionLength = &i18n.Message{
+		ID:    "limit.error.questionLength",
+		Other: "Questions can't be longer than {{.Limit}} characters",
+	}
+	limitErrorNumberOfAnswerOptions = &i18n.Message{
+		ID:    "limit.error.numberOfAnswerOptions",
+		Other: "Polls can't have more than {{.Limit}} answer options",
+	}
+	limitErrorAnswerOptionLength = &i18n.Message{
+		ID:    "limit.error.answerOptionLength",
+		Other: "Answer options can't be longer than {{.Limit}} characters",
+	}
+	limitErrorBlockedWords = &i18n.Message{
+		ID:    "limit.error.blockedWords",
+		Other: "Polls can't contain words that are blocked on this server",
+	}
+	limitErrorEmailSummaries = &i18n.Message{
+		ID:    "limit.error.emailSummaries",
+		Other: "The results of polls can't be emailed on this server",
+	}
+)
+
 // configuration captures the plugin's external configuration as exposed in the Mattermost server
 // configuration, as well as values computed from the configuration. Any public fields will be
 // deserialized from the Mattermost server configuration in OnConfigurationChange.
 type configuration struct {
 	Trigger string
+	// APIToken authenticates external tools that create polls via the REST API. The REST API is disabled if it's empty.
+	APIToken string
+	// TrustedPlugins is a comma separated list of plugin IDs that may use the REST API without the API token.
+	TrustedPlugins string
+	// StoreType selects where polls are stored. Changes take effect after a restart of the plugin.
+	StoreType string
+	// WebhookURL receives a JSON payload for every poll event. Webhooks are disabled if it's empty.
+	WebhookURL string
+	// WebhookSecret signs the webhook payloads. Payloads are not signed if it's empty.
+	WebhookSecret string
+	// WebhookEvents is a comma separated list of the events that trigger the webhook. All events trigger it if it's empty.
+	WebhookEvents string
+	// EnableMetrics exposes the plugin metrics in the Prometheus text format.
+	EnableMetrics bool
+	// EnableTelemetry sends anonymized usage statistics to TelemetryURL once a day, if the diagnostics of the server are enabled as well.
+	EnableTelemetry bool
+	// TelemetryURL receives the anonymized usage statistics. No statistics are sent if it's empty.
+	TelemetryURL string
+	// EnableAuditLog records who created, voted in, ended or deleted a poll and when.
+	EnableAuditLog bool
+	// ResultsChart attaches a bar chart of the results to the reply that announces the end of a poll.
+	ResultsChart bool
+	// ShowOwnVote tells voters what they voted for in the confirmation of their vote. It can be turned off if others may see the confirmation.
+	ShowOwnVote bool
+	// ResultsTemplate is the template of the message that announces the results of polls that don't set their own, see poll.ResultsTemplateData.
+	// The default message is used if it's empty.
+	ResultsTemplate string
+	// PollLanguage is the language of poll posts and other messages that everybody in a channel sees.
+	// The server default locale is used if it's empty.
+	PollLanguage string
+	// DefaultProgress and DefaultAnonymous enable the matching Poll Settings for polls that don't set them explicitly.
+	DefaultProgress  bool
+	DefaultAnonymous bool
+	// DefaultMembersOnly enables the members-only Poll Setting for polls that don't set it explicitly.
+	DefaultMembersOnly bool
+	// ExcludeGuests rejects votes from guest accounts in all polls. Polls can exclude guests on their own with the no-guests Poll Setting.
+	ExcludeGuests bool
+	// MaxAnswerOptions is the maximum number of answer options of a poll. There is no limit if it's empty.
+	MaxAnswerOptions string
+	// MaxQuestionLength is the maximum number of characters of a poll question. There is no limit if it's empty.
+	MaxQuestionLength string
+	// MaxAnswerOptionLength is the maximum number of characters of an answer option. There is no limit if it's empty.
+	MaxAnswerOptionLength string
+	// MaxPollsPerHour is the maximum number of polls a user can create per hour. System admins are exempt. There is no limit if it's empty.
+	MaxPollsPerHour string
+	// PollCreators restricts the creation of polls to channel admins or system admins. Everyone may create polls if it's empty.
+	PollCreators string
+	// PollCreatorUsernames is a comma separated list of user names of users that may create polls regardless of PollCreators.
+	PollCreatorUsernames string
+	// AnswerOptionsPerPage is the number of answer option buttons per page of polls with more answer options.
+	// All answer options are shown at once if it's empty.
+	AnswerOptionsPerPage string
+	// CompactAbove is the number of answer options above which polls show a select menu instead of a button per answer option.
+	// Polls are only compact with the compact setting if it's empty.
+	CompactAbove string
+	// ArchiveAfterDays is the number of days after which ended polls get archived. Polls are never archived if it's empty.
+	ArchiveAfterDays string
+	// DeadlineReminderMinutes is the number of minutes before the deadline of a poll at which its channel gets reminded to vote.
+	// There are no reminders if it's empty.
+	DeadlineReminderMinutes string
+	// BlockedWords is a comma separated list of words and phrases that polls can't contain. Entries wrapped in slashes are regular expressions.
+	// Nothing is blocked if it's empty.
+	BlockedWords string
+	// MaskBlockedWords replaces blocked words with asterisks instead of rejecting the poll.
+	MaskBlockedWords bool
+	// EnableEmailSummaries allows polls to email their results to their creator and further addresses once they ended.
+	EnableEmailSummaries bool
+	// DeleteGracePeriodHours is the number of hours during which deleted polls can be restored before they get removed for good.
+	// Polls are removed right away if it's empty.
+	DeleteGracePeriodHours string
+
+	// maxAnswerOptions, maxQuestionLength, maxAnswerOptionLength and maxPollsPerHour are the parsed limits. Zero means no limit.
+	maxAnswerOptions      int
+	maxQuestionLength     int
+	maxAnswerOptionLength int
+	maxPollsPerHour       int
+	// answerOptionsPerPage is the parsed AnswerOptionsPerPage. Zero means no pagination.
+	answerOptionsPerPage int
+	// compactAbove is the parsed CompactAbove. Zero means polls are only compact with the compact setting.
+	compactAbove int
+	// archiveAfterDays is the parsed ArchiveAfterDays. Zero means polls are never archived.
+	archiveAfterDays int
+	// deadlineReminderMinutes is the parsed DeadlineReminderMinutes. Zero means there are no reminders.
+	deadlineReminderMinutes int
+	// deleteGracePeriodHours is the parsed DeleteGracePeriodHours. Zero means polls are removed right away.
+	deleteGracePeriodHours int
+	// blockedWords is the parsed BlockedWords
+	blockedWords []*blockedWord
+}
+
+// blockedWord is an entry of the BlockedWords setting
+type blockedWord struct {
+	pattern *regexp.Regexp
+	// wholeWord is set for words and phrases, which don't match within other words. Regular expressions match anywhere.
+	wholeWord bool
+}
+
+// limitError is returned if a poll exceeds a limit of the configuration. It can be localized for the user that created the poll.
+type limitError struct {
+	message *i18n.Message
+	limit   int
+}
+
+// Error returns the English error message
+func (e *limitError) Error() string {
+	return strings.Replace(e.message.Other, "{{.Limit}}", strconv.Itoa(e.limit), -1)
+}
+
+// parseLimit parses the value of a limit setting. An empty value means no limit.
+func parseLimit(name, value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, errors.Errorf("Invalid %s %s", name, value)
+	}
+	return limit, nil
+}
+
+// parseBlockedWords parses the value of the BlockedWords setting. Words and phrases match regardless of their case.
+func parseBlockedWords(value string) ([]*blockedWord, error) {
+	var words []*blockedWord
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			pattern, err := regexp.Compile("(?i)" + entry[1:len(entry)-1])
+			if err != nil {
+				return nil, errors.Errorf("Invalid blocked word %s: %s", entry, err.Error())
+			}
+			words = append(words, &blockedWord{pattern: pattern})
+			continue
+		}
+		words = append(words, &blockedWord{pattern: regexp.MustCompile("(?i)" + regexp.QuoteMeta(entry)), wholeWord: true})
+	}
+	return words, nil
+}
+
+// find returns the start and end indexes of all occurrences of the blocked word in a given text
+func (w *blockedWord) find(text string) [][]int {
+	var matches [][]int
+	for _, m := range w.pattern.FindAllStringIndex(text, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		if w.wholeWord {
+			before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+			after, _ := utf8.DecodeRuneInString(text[m[1]:])
+			if isWordRune(before) || isWordRune(after) {
+				continue
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// isWordRune checks if a given rune is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// applyDefaultSettings adds the default Poll Settings to a given list of Poll Settings, unless the list sets them explicitly
+func (c *configuration) applyDefaultSettings(settings []string) []string {
+	explicit := map[string]bool{}
+	for _, s := range settings {
+		explicit[strings.SplitN(s, "=", 2)[0]] = true
+	}
+
+	result := append([]string{}, settings...)
+	if c.DefaultAnonymous && !explicit["anonymous"] {
+		result = append(result, "anonymous")
+	}
+	if c.DefaultProgress && !explicit["progress"] {
+		result = append(result, "progress")
+	}
+	if c.DefaultMembersOnly && !explicit["members-only"] {
+		result = append(result, "members-only")
+	}
+	return result
+}
+
+// withTeamDefaults returns a copy of the configuration whose default Poll Settings and maximum number of answer options
+// are overridden by given defaults of a team
+func (c *configuration) withTeamDefaults(defaults *team.Defaults) *configuration {
+	clone := *c
+	if defaults.Anonymous != nil {
+		clone.DefaultAnonymous = *defaults.Anonymous
+	}
+	if defaults.Progress != nil {
+		clone.DefaultProgress = *defaults.Progress
+	}
+	if defaults.MembersOnly != nil {
+		clone.DefaultMembersOnly = *defaults.MembersOnly
+	}
+	if defaults.MaxAnswerOptions != nil {
+		clone.maxAnswerOptions = *defaults.MaxAnswerOptions
+		clone.MaxAnswerOptions = strconv.Itoa(*defaults.MaxAnswerOptions)
+	}
+	return &clone
+}
+
+// checkLimits returns an error if a given poll exceeds the maximum question length, the maximum number of answer options
+// or the maximum answer option length, or if it emails its results while email summaries are disabled
+func (c *configuration) checkLimits(p *poll.Poll) error {
+	if err := c.checkEmailSummary(p); err != nil {
+		return err
+	}
+	questions := []*poll.Question{{Question: p.Question, AnswerOptions: p.AnswerOptions}}
+	questions = append(questions, p.Questions...)
+	for _, q := range questions {
+		if err := c.checkQuestionLength(q.Question); err != nil {
+			return err
+		}
+		if err := c.checkNumberOfAnswerOptions(len(q.AnswerOptions)); err != nil {
+			return err
+		}
+		if err := c.checkAnswerOptionLengths(q.AnswerOptions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkEmailSummary returns an error if a given poll emails its results while email summaries are disabled
+func (c *configuration) checkEmailSummary(p *poll.Poll) error {
+	if p.HasEmailSummary() && !c.EnableEmailSummaries {
+		return &limitError{message: limitErrorEmailSummaries}
+	}
+	return nil
+}
+
+// checkQuestionLength returns an error if a given question exceeds the maximum question length
+func (c *configuration) checkQuestionLength(question string) error {
+	if c.maxQuestionLength > 0 && utf8.RuneCountInString(question) > c.maxQuestionLength {
+		return &limitError{message: limitErrorQuestionLength, limit: c.maxQuestionLength}
+	}
+	return nil
+}
+
+// checkNumberOfAnswerOptions returns an error if a given number of answer options exceeds the maximum
+func (c *configuration) checkNumberOfAnswerOptions(n int) error {
+	if c.maxAnswerOptions > 0 && n > c.maxAnswerOptions {
+		return &limitError{message: limitErrorNumberOfAnswerOptions, limit: c.maxAnswerOptions}
+	}
+	return nil
+}
+
+// checkAnswerOptionLengths returns an error if one of the given answer options exceeds the maximum answer option length.
+// Image URLs don't count towards the length.
+func (c *configuration) checkAnswerOptionLengths(answerOptions []*poll.AnswerOption) error {
+	if c.maxAnswerOptionLength == 0 {
+		return nil
+	}
+	for _, o := range answerOptions {
+		if utf8.RuneCountInString(o.Answer) > c.maxAnswerOptionLength {
+			return &limitError{message: limitErrorAnswerOptionLength, limit: c.maxAnswerOptionLength}
+		}
+	}
+	return nil
+}
+
+// applyBlockedWords masks the blocked words in the questions and answer options of a given poll.
+// If blocked words aren't masked, it returns an error if the poll contains any.
+func (c *configuration) applyBlockedWords(p *poll.Poll) error {
+	question, err := c.filterBlockedWords(p.Question)
+	if err != nil {
+		return err
+	}
+	p.Question = question
+	if err = c.filterBlockedAnswerOptions(p.AnswerOptions); err != nil {
+		return err
+	}
+
+	for _, q := range p.Questions {
+		if question, err = c.filterBlockedWords(q.Question); err != nil {
+			return err
+		}
+		q.Question = question
+		if err = c.filterBlockedAnswerOptions(q.AnswerOptions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterBlockedAnswerOptions masks the blocked words in the given answer options.
+// If blocked words aren't masked, it returns an error if one of them contains any.
+func (c *configuration) filterBlockedAnswerOptions(answerOptions []*poll.AnswerOption) error {
+	for _, o := range answerOptions {
+		answer, err := c.filterBlockedWords(o.Answer)
+		if err != nil {
+			return err
+		}
+		o.Answer = answer
+		description, err := c.filterBlockedWords(o.Description)
+		if err != nil {
+			return err
+		}
+		o.Description = description
+	}
+	return nil
+}
+
+// filterBlockedWords returns a given text with its blocked words replaced by asterisks.
+// If blocked words aren't masked, it returns an error if the text contains any.
+func (c *configuration) filterBlockedWords(text string) (string, error) {
+	for _, w := range c.blockedWords {
+		matches := w.find(text)
+		if len(matches) == 0 {
+			continue
+		}
+		if !c.MaskBlockedWords {
+			return "", &limitError{message: limitErrorBlockedWords}
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			b.WriteString(text[last:m[0]])
+			b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[m[0]:m[1]])))
+			last = m[1]
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text, nil
+}
+
+// webhookEvents returns the events that trigger the webhook
+func (c *configuration) webhookEvents() []webhookEvent {
+	events := []webhookEvent{}
+	for _, e := range strings.Split(c.WebhookEvents, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, webhookEvent(e))
+		}
+	}
+	if len(events) == 0 {
+		return allWebhookEvents
+	}
+	return events
+}
+
+// isTrustedPlugin checks if a given plugin may use the REST API without the API token
+func (c *configuration) isTrustedPlugin(pluginID string) bool {
+	for _, id := range strings.Split(c.TrustedPlugins, ",") {
+		if id = strings.TrimSpace(id); id != "" && id == pluginID {
+			return true
+		}
+	}
+	return false
+}
+
+// isPollCreatorUsername checks if the user with a given user name may always create polls. The listed user names may start with an @.
+func (c *configuration) isPollCreatorUsername(username string) bool {
+	for _, u := range strings.Split(c.PollCreatorUsernames, ",") {
+		if u = strings.TrimPrefix(strings.TrimSpace(u), "@"); u != "" && strings.EqualFold(u, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWebhookEnabled checks if a given event triggers the webhook
+func (c *configuration) isWebhookEnabled(event webhookEvent) bool {
+	if c.WebhookURL == "" {
+		return false
+	}
+	for _, e := range c.webhookEvents() {
+		if e == event {
+			return true
+		}
+	}
+	return false
 }
 
 // OnConfigurationChange loads the plugin configuration, validates it and saves it.
@@ -25,6 +436,66 @@ func (p *MatterpollPlugin) OnConfigurationChange() error {
 		return errors.New("Empty trigger not allowed")
 	}
 
+	switch configuration.StoreType {
+	case "", storeTypeKV, storeTypeSQL:
+	default:
+		return errors.Errorf("Unknown store type %s", configuration.StoreType)
+	}
+
+	if _, ok := pollCreatorsRestrictiveness[configuration.PollCreators]; !ok {
+		return errors.Errorf("Unknown poll creators %s", configuration.PollCreators)
+	}
+
+	if configuration.WebhookURL != "" {
+		u, err := url.Parse(configuration.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("Invalid webhook URL %s", configuration.WebhookURL)
+		}
+	}
+	for _, e := range configuration.webhookEvents() {
+		if !e.isValid() {
+			return errors.Errorf("Unknown webhook event %s", e)
+		}
+	}
+
+	if configuration.ResultsTemplate != "" {
+		if _, err := poll.ParseResultsTemplate(configuration.ResultsTemplate); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if configuration.maxAnswerOptions, err = parseLimit("maximum number of answer options", configuration.MaxAnswerOptions); err != nil {
+		return err
+	}
+	if configuration.maxQuestionLength, err = parseLimit("maximum question length", configuration.MaxQuestionLength); err != nil {
+		return err
+	}
+	if configuration.maxAnswerOptionLength, err = parseLimit("maximum answer option length", configuration.MaxAnswerOptionLength); err != nil {
+		return err
+	}
+	if configuration.maxPollsPerHour, err = parseLimit("maximum number of polls per hour", configuration.MaxPollsPerHour); err != nil {
+		return err
+	}
+	if configuration.answerOptionsPerPage, err = parseLimit("number of answer options per page", configuration.AnswerOptionsPerPage); err != nil {
+		return err
+	}
+	if configuration.compactAbove, err = parseLimit("number of answer options above which polls are compact", configuration.CompactAbove); err != nil {
+		return err
+	}
+	if configuration.archiveAfterDays, err = parseLimit("number of days after which polls get archived", configuration.ArchiveAfterDays); err != nil {
+		return err
+	}
+	if configuration.deadlineReminderMinutes, err = parseLimit("number of minutes before the deadline of a poll at which its channel gets reminded", configuration.DeadlineReminderMinutes); err != nil {
+		return err
+	}
+	if configuration.deleteGracePeriodHours, err = parseLimit("number of hours during which deleted polls can be restored", configuration.DeleteGracePeriodHours); err != nil {
+		return err
+	}
+	if configuration.blockedWords, err = parseBlockedWords(configuration.BlockedWords); err != nil {
+		return err
+	}
+
 	// This require a loaded i18n bundle
 	if p.isActivated() {
 		// Update slash command help text
@@ -6,10 +6,13 @@ import (
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/team"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOnConfigurationChange(t *testing.T) {
@@ -71,6 +74,163 @@ func TestOnConfigurationChange(t *testing.T) {
 			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
 			ShouldError:           true,
 		},
+		"Load unknown store type": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.StoreType = "redis"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger"},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
+			ShouldError:           true,
+		},
+		"Load webhook configuration": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.WebhookURL = "https://example.org/hook"
+					arg.WebhookEvents = "poll_created, poll_ended"
+				})
+				api.On("RegisterCommand", command).Return(nil)
+				api.On("PatchBot", testutils.GetBotUserID(), botPatch).Return(nil, nil)
+				return api
+			},
+			Configuration:         nil,
+			ExpectedConfiguration: &configuration{Trigger: "poll", WebhookURL: "https://example.org/hook", WebhookEvents: "poll_created, poll_ended"},
+			ShouldError:           false,
+		},
+		"Load invalid webhook URL": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.WebhookURL = "example.org/hook"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger"},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
+			ShouldError:           true,
+		},
+		"Load unknown webhook event": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.WebhookURL = "https://example.org/hook"
+					arg.WebhookEvents = "poll_created,poll_archived"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger"},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
+			ShouldError:           true,
+		},
+		"Load poll limits": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.MaxAnswerOptions = "10"
+					arg.MaxQuestionLength = " 200 "
+					arg.MaxAnswerOptionLength = "50"
+					arg.MaxPollsPerHour = "5"
+					arg.AnswerOptionsPerPage = "8"
+					arg.CompactAbove = "12"
+					arg.ArchiveAfterDays = "30"
+					arg.DeadlineReminderMinutes = "60"
+				})
+				api.On("RegisterCommand", command).Return(nil)
+				api.On("PatchBot", testutils.GetBotUserID(), botPatch).Return(nil, nil)
+				return api
+			},
+			Configuration: nil,
+			ExpectedConfiguration: &configuration{
+				Trigger:                 "poll",
+				MaxAnswerOptions:        "10",
+				MaxQuestionLength:       " 200 ",
+				MaxAnswerOptionLength:   "50",
+				MaxPollsPerHour:         "5",
+				AnswerOptionsPerPage:    "8",
+				CompactAbove:            "12",
+				ArchiveAfterDays:        "30",
+				DeadlineReminderMinutes: "60",
+				maxAnswerOptions:        10,
+				maxQuestionLength:       200,
+				maxAnswerOptionLength:   50,
+				maxPollsPerHour:         5,
+				answerOptionsPerPage:    8,
+				compactAbove:            12,
+				archiveAfterDays:        30,
+				deadlineReminderMinutes: 60,
+			},
+			ShouldError: false,
+		},
+		"Load invalid poll limit": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.MaxAnswerOptions = "-1"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger"},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
+			ShouldError:           true,
+		},
+		"Load invalid results template": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.ResultsTemplate = "{{.Loser}}"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger"},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
+			ShouldError:           true,
+		},
+		"Load invalid blocked word": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.BlockedWords = "darn, /d[a4rn/"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger"},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
+			ShouldError:           true,
+		},
+		"Load unknown poll creators": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetConfig").Return(testutils.GetServerConfig())
+				api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).Return(nil).Run(func(args mock.Arguments) {
+					arg := args.Get(0).(*configuration)
+					arg.Trigger = "poll"
+					arg.PollCreators = "moderators"
+				})
+				return api
+			},
+			Configuration:         &configuration{Trigger: "oldTrigger"},
+			ExpectedConfiguration: &configuration{Trigger: "oldTrigger"},
+			ShouldError:           true,
+		},
 		"Load empty trigger": {
 			SetupAPI: func(api *plugintest.API) *plugintest.API {
 				api.On("GetConfig").Return(testutils.GetServerConfig())
@@ -196,3 +356,301 @@ func TestConfiguration(t *testing.T) {
 		assert.NotEqual(t, plugin, plugin.getConfiguration())
 	})
 }
+
+func TestConfigurationApplyDefaultSettings(t *testing.T) {
+	for name, test := range map[string]struct {
+		Configuration    *configuration
+		Settings         []string
+		ExpectedSettings []string
+	}{
+		"No defaults": {
+			Configuration:    &configuration{},
+			Settings:         []string{"secret"},
+			ExpectedSettings: []string{"secret"},
+		},
+		"Defaults": {
+			Configuration:    &configuration{DefaultAnonymous: true, DefaultProgress: true, DefaultMembersOnly: true},
+			Settings:         []string{"secret"},
+			ExpectedSettings: []string{"secret", "anonymous", "progress", "members-only"},
+		},
+		"Defaults set explicitly": {
+			Configuration:    &configuration{DefaultAnonymous: true, DefaultProgress: true, DefaultMembersOnly: true},
+			Settings:         []string{"anonymous", "progress=false", "members-only=false"},
+			ExpectedSettings: []string{"anonymous", "progress=false", "members-only=false"},
+		},
+		"No settings": {
+			Configuration:    &configuration{DefaultProgress: true},
+			Settings:         nil,
+			ExpectedSettings: []string{"progress"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedSettings, test.Configuration.applyDefaultSettings(test.Settings))
+		})
+	}
+}
+
+func TestConfigurationWithTeamDefaults(t *testing.T) {
+	enabled, disabled, maxAnswerOptions := true, false, 10
+	c := &configuration{Trigger: "poll", DefaultProgress: true, MaxAnswerOptions: "20", maxAnswerOptions: 20}
+
+	t.Run("overridden", func(t *testing.T) {
+		teamConfiguration := c.withTeamDefaults(&team.Defaults{Anonymous: &enabled, Progress: &disabled, MaxAnswerOptions: &maxAnswerOptions})
+
+		assert.Equal(t, &configuration{Trigger: "poll", DefaultAnonymous: true, MaxAnswerOptions: "10", maxAnswerOptions: 10}, teamConfiguration)
+		assert.Equal(t, &configuration{Trigger: "poll", DefaultProgress: true, MaxAnswerOptions: "20", maxAnswerOptions: 20}, c)
+	})
+	t.Run("nothing overridden", func(t *testing.T) {
+		assert.Equal(t, c, c.withTeamDefaults(&team.Defaults{}))
+	})
+}
+
+func TestConfigurationCheckLimits(t *testing.T) {
+	for name, test := range map[string]struct {
+		Configuration *configuration
+		Poll          *poll.Poll
+		ShouldError   bool
+	}{
+		"No limits": {
+			Configuration: &configuration{},
+			Poll:          testutils.GetPoll(),
+			ShouldError:   false,
+		},
+		"Within limits": {
+			Configuration: &configuration{maxAnswerOptions: 3, maxQuestionLength: 8, maxAnswerOptionLength: 8},
+			Poll:          testutils.GetPoll(),
+			ShouldError:   false,
+		},
+		"Too many answer options": {
+			Configuration: &configuration{maxAnswerOptions: 2},
+			Poll:          testutils.GetPoll(),
+			ShouldError:   true,
+		},
+		"Question too long": {
+			Configuration: &configuration{maxQuestionLength: 7},
+			Poll:          testutils.GetPoll(),
+			ShouldError:   true,
+		},
+		"Survey question too long": {
+			Configuration: &configuration{maxQuestionLength: 8},
+			Poll:          testutils.GetSurveyWithVotes(),
+			ShouldError:   true,
+		},
+		"Answer option too long": {
+			Configuration: &configuration{maxAnswerOptionLength: 7},
+			Poll:          testutils.GetPoll(),
+			ShouldError:   true,
+		},
+		"Answer option with image within limit": {
+			Configuration: &configuration{maxAnswerOptionLength: 8},
+			Poll: func() *poll.Poll {
+				p := testutils.GetPoll()
+				p.AnswerOptions[0].ImageURL = "https://example.com/a.png"
+				return p
+			}(),
+			ShouldError: false,
+		},
+		"Email summary enabled": {
+			Configuration: &configuration{EnableEmailSummaries: true},
+			Poll:          testutils.GetPollWithSettings(poll.Settings{EmailCreator: true}),
+			ShouldError:   false,
+		},
+		"Email summary disabled": {
+			Configuration: &configuration{},
+			Poll:          testutils.GetPollWithSettings(poll.Settings{EmailRecipients: []string{"hr@example.com"}}),
+			ShouldError:   true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := test.Configuration.checkLimits(test.Poll)
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigurationFilterBlockedWords(t *testing.T) {
+	for name, test := range map[string]struct {
+		BlockedWords     string
+		MaskBlockedWords bool
+		Text             string
+		ExpectedText     string
+		ShouldError      bool
+	}{
+		"No blocked words": {
+			BlockedWords: "",
+			Text:         "What a darn good question",
+			ExpectedText: "What a darn good question",
+		},
+		"No blocked word in the text": {
+			BlockedWords: "darn, heck",
+			Text:         "What a good question",
+			ExpectedText: "What a good question",
+		},
+		"Blocked word": {
+			BlockedWords: "darn, heck",
+			Text:         "What a darn good question",
+			ShouldError:  true,
+		},
+		"Blocked word with different case": {
+			BlockedWords: "darn",
+			Text:         "DARN",
+			ShouldError:  true,
+		},
+		"Blocked phrase": {
+			BlockedWords: "good question",
+			Text:         "What a good question",
+			ShouldError:  true,
+		},
+		"Blocked word within another word": {
+			BlockedWords: "ass",
+			Text:         "Who takes the class?",
+			ExpectedText: "Who takes the class?",
+		},
+		"Blocked word with non-ASCII letters": {
+			BlockedWords: "mist",
+			Text:         "Ist das Mist?",
+			ShouldError:  true,
+		},
+		"Blocked regular expression": {
+			BlockedWords: "/d[a4]rn/",
+			Text:         "What a d4rned good question",
+			ShouldError:  true,
+		},
+		"Masked blocked words": {
+			BlockedWords:     "darn, /h[e3]ck/",
+			MaskBlockedWords: true,
+			Text:             "Darn, what the h3ck is a darn heckler?",
+			ExpectedText:     "****, what the **** is a **** ****ler?",
+		},
+		"Masked blocked word with non-ASCII letters": {
+			BlockedWords:     "käse",
+			MaskBlockedWords: true,
+			Text:             "Käse oder Wurst?",
+			ExpectedText:     "**** oder Wurst?",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			blockedWords, err := parseBlockedWords(test.BlockedWords)
+			require.Nil(t, err)
+			c := &configuration{BlockedWords: test.BlockedWords, MaskBlockedWords: test.MaskBlockedWords, blockedWords: blockedWords}
+
+			text, err := c.filterBlockedWords(test.Text)
+			if test.ShouldError {
+				assert.Equal(t, &limitError{message: limitErrorBlockedWords}, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.ExpectedText, text)
+			}
+		})
+	}
+}
+
+func TestConfigurationApplyBlockedWords(t *testing.T) {
+	blockedWords, err := parseBlockedWords("Answer 2, Question 1")
+	require.Nil(t, err)
+
+	t.Run("Masked", func(t *testing.T) {
+		c := &configuration{MaskBlockedWords: true, blockedWords: blockedWords}
+
+		p := testutils.GetPoll()
+		p.AnswerOptions[0].Description = "Better than Answer 2"
+		require.Nil(t, c.applyBlockedWords(p))
+		assert.Equal(t, "Question", p.Question)
+		assert.Equal(t, "********", p.AnswerOptions[1].Answer)
+		assert.Equal(t, "Better than ********", p.AnswerOptions[0].Description)
+
+		survey := testutils.GetSurveyWithVotes()
+		require.Nil(t, c.applyBlockedWords(survey))
+		assert.Equal(t, "**********", survey.Questions[0].Question)
+		assert.Equal(t, "********", survey.Questions[1].AnswerOptions[1].Answer)
+	})
+	t.Run("Rejected", func(t *testing.T) {
+		c := &configuration{blockedWords: blockedWords}
+
+		assert.NotNil(t, c.applyBlockedWords(testutils.GetPoll()))
+		assert.NotNil(t, c.applyBlockedWords(testutils.GetSurveyWithVotes()))
+		assert.Nil(t, c.applyBlockedWords(testutils.GetPollTwoOptions()))
+
+		describedPoll := testutils.GetPollTwoOptions()
+		describedPoll.AnswerOptions[0].Description = "Better than Answer 2"
+		assert.NotNil(t, c.applyBlockedWords(describedPoll))
+	})
+}
+
+func TestConfigurationIsTrustedPlugin(t *testing.T) {
+	for name, test := range map[string]struct {
+		TrustedPlugins string
+		PluginID       string
+		Expected       bool
+	}{
+		"No trusted plugins": {
+			TrustedPlugins: "",
+			PluginID:       "playbooks",
+			Expected:       false,
+		},
+		"Trusted plugin": {
+			TrustedPlugins: "com.example.other, playbooks",
+			PluginID:       "playbooks",
+			Expected:       true,
+		},
+		"Untrusted plugin": {
+			TrustedPlugins: "com.example.other,playbooks",
+			PluginID:       "com.example",
+			Expected:       false,
+		},
+		"Empty plugin ID": {
+			TrustedPlugins: "playbooks, ,",
+			PluginID:       "",
+			Expected:       false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := &configuration{TrustedPlugins: test.TrustedPlugins}
+			assert.Equal(t, test.Expected, c.isTrustedPlugin(test.PluginID))
+		})
+	}
+}
+
+func TestConfigurationIsPollCreatorUsername(t *testing.T) {
+	for name, test := range map[string]struct {
+		PollCreatorUsernames string
+		Username             string
+		Expected             bool
+	}{
+		"No user names": {
+			PollCreatorUsernames: "",
+			Username:             "user1",
+			Expected:             false,
+		},
+		"Listed user name": {
+			PollCreatorUsernames: "moderator, user1",
+			Username:             "user1",
+			Expected:             true,
+		},
+		"Listed user name with @ and different case": {
+			PollCreatorUsernames: "@User1",
+			Username:             "user1",
+			Expected:             true,
+		},
+		"Unlisted user name": {
+			PollCreatorUsernames: "moderator,user1",
+			Username:             "user2",
+			Expected:             false,
+		},
+		"Empty user name": {
+			PollCreatorUsernames: "user1, ,",
+			Username:             "",
+			Expected:             false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := &configuration{PollCreatorUsernames: test.PollCreatorUsernames}
+			assert.Equal(t, test.Expected, c.isPollCreatorUsername(test.Username))
+		})
+	}
+}
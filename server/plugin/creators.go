@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// pollCreatorsChannelAdmins allows only channel admins and system admins to create polls
+	pollCreatorsChannelAdmins = "channel_admins"
+	// pollCreatorsSystemAdmins allows only system admins to create polls
+	pollCreatorsSystemAdmins = "system_admins"
+	// pollCreatorsEveryone is the argument of /poll channel creators that lifts the restriction of a channel.
+	// It's stored as empty value, like the Everyone option of the PollCreators setting.
+	pollCreatorsEveryone = "everyone"
+)
+
+// pollCreatorsRestrictiveness orders who may create polls from everyone to system admins only
+var pollCreatorsRestrictiveness = map[string]int{
+	"":                        0,
+	pollCreatorsChannelAdmins: 1,
+	pollCreatorsSystemAdmins:  2,
+}
+
+// channelCreatorsSuccess maps the arguments of /poll channel creators to the messages that confirm them
+var channelCreatorsSuccess = map[string]*i18n.Message{
+	pollCreatorsEveryone:      channelCreatorsEveryoneSuccess,
+	pollCreatorsChannelAdmins: channelCreatorsChannelAdminsSuccess,
+	pollCreatorsSystemAdmins:  channelCreatorsSystemAdminsSuccess,
+}
+
+var responseCreatePollRestricted = &i18n.Message{
+	ID:    "response.createPoll.restricted",
+	Other: "You aren't allowed to create polls in this channel.",
+}
+
+// isPollCreationRestricted checks if a given user isn't allowed to create polls in a given channel.
+// The stricter one of the PollCreators setting and the restriction of the channel applies. Users listed in PollCreatorUsernames
+// and the bot, which creates the polls of trusted tools, may always create polls.
+// If the permission can't be checked, the poll is rejected and the failure is logged.
+func (p *MatterpollPlugin) isPollCreationRestricted(channelID, userID string) bool {
+	if userID == p.botUserID {
+		return false
+	}
+	configuration := p.getConfiguration()
+
+	creators, err := p.Store.Channel().GetCreators(channelID)
+	if err != nil {
+		p.API.LogWarn("failed to get who may create polls in channel", "error", err.Error())
+		return true
+	}
+	if pollCreatorsRestrictiveness[configuration.PollCreators] > pollCreatorsRestrictiveness[creators] {
+		creators = configuration.PollCreators
+	}
+	if creators != pollCreatorsChannelAdmins && creators != pollCreatorsSystemAdmins {
+		return false
+	}
+
+	if strings.TrimSpace(configuration.PollCreatorUsernames) != "" {
+		user, appErr := p.API.GetUser(userID)
+		if appErr != nil {
+			p.API.LogWarn("failed to get user", "error", appErr.Error())
+			return true
+		}
+		if configuration.isPollCreatorUsername(user.Username) {
+			return false
+		}
+	}
+
+	if creators == pollCreatorsSystemAdmins {
+		isAdmin, appErr := p.isSystemAdmin(userID)
+		if appErr != nil {
+			p.API.LogWarn("failed to check if user is system admin", "error", appErr.Error())
+			return true
+		}
+		return !isAdmin
+	}
+	isAdmin, err := p.isChannelAdmin(channelID, userID)
+	if err != nil {
+		p.API.LogWarn("failed to check if user is channel admin", "error", err.Error())
+		return true
+	}
+	return !isAdmin
+}
@@ -0,0 +1,179 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPollCreationRestricted(t *testing.T) {
+	user := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_USER_ROLE_ID}
+	systemAdmin := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	channelMember := &model.ChannelMember{Roles: model.CHANNEL_USER_ROLE_ID}
+	channelAdmin := &model.ChannelMember{SchemeAdmin: true}
+	openChannel := &model.Channel{Id: "channelID1", Type: model.CHANNEL_OPEN}
+
+	for name, test := range map[string]struct {
+		PollCreators         string
+		PollCreatorUsernames string
+		ChannelCreators      string
+		UserID               string
+		SetupAPI             func(*plugintest.API) *plugintest.API
+		ExpectedResult       bool
+	}{
+		"Everyone": {
+			SetupAPI:       func(api *plugintest.API) *plugintest.API { return api },
+			ExpectedResult: false,
+		},
+		"Channel admins, user is channel admin": {
+			PollCreators: pollCreatorsChannelAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(channelAdmin, nil)
+				return api
+			},
+			ExpectedResult: false,
+		},
+		"Channel admins, user is channel member": {
+			PollCreators: pollCreatorsChannelAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(channelMember, nil)
+				api.On("GetChannel", "channelID1").Return(openChannel, nil)
+				return api
+			},
+			ExpectedResult: true,
+		},
+		"Channel admins, user is member of a direct message": {
+			PollCreators: pollCreatorsChannelAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(channelMember, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", Type: model.CHANNEL_DIRECT}, nil)
+				return api
+			},
+			ExpectedResult: false,
+		},
+		"System admins, user is system admin": {
+			PollCreators: pollCreatorsSystemAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			ExpectedResult: false,
+		},
+		"System admins, user is channel admin": {
+			PollCreators: pollCreatorsSystemAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				return api
+			},
+			ExpectedResult: true,
+		},
+		"Channel restricts further than the configuration": {
+			PollCreators:    pollCreatorsChannelAdmins,
+			ChannelCreators: pollCreatorsSystemAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				return api
+			},
+			ExpectedResult: true,
+		},
+		"Channel can't lift the restriction of the configuration": {
+			PollCreators:    pollCreatorsSystemAdmins,
+			ChannelCreators: pollCreatorsChannelAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				return api
+			},
+			ExpectedResult: true,
+		},
+		"Channel restricted, configuration allows everyone": {
+			ChannelCreators: pollCreatorsChannelAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(channelMember, nil)
+				api.On("GetChannel", "channelID1").Return(openChannel, nil)
+				return api
+			},
+			ExpectedResult: true,
+		},
+		"Listed user name": {
+			PollCreators:         pollCreatorsSystemAdmins,
+			PollCreatorUsernames: "moderator, @User1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				return api
+			},
+			ExpectedResult: false,
+		},
+		"Unlisted user name": {
+			PollCreators:         pollCreatorsSystemAdmins,
+			PollCreatorUsernames: "moderator",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				return api
+			},
+			ExpectedResult: true,
+		},
+		"Bot is never restricted": {
+			PollCreators:   pollCreatorsSystemAdmins,
+			UserID:         testutils.GetBotUserID(),
+			SetupAPI:       func(api *plugintest.API) *plugintest.API { return api },
+			ExpectedResult: false,
+		},
+		"GetUser fails": {
+			PollCreators: pollCreatorsSystemAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			ExpectedResult: true,
+		},
+		"GetChannelMember fails": {
+			PollCreators: pollCreatorsChannelAdmins,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID1").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			ExpectedResult: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.ChannelStore.On("GetCreators", "channelID1").Return(test.ChannelCreators, nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.configuration.PollCreators = test.PollCreators
+			p.configuration.PollCreatorUsernames = test.PollCreatorUsernames
+
+			userID := test.UserID
+			if userID == "" {
+				userID = "userID1"
+			}
+			assert.Equal(t, test.ExpectedResult, p.isPollCreationRestricted("channelID1", userID))
+		})
+	}
+
+	t.Run("GetCreators fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.ChannelStore.On("GetCreators", "channelID1").Return("", errors.New(""))
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		assert.True(t, p.isPollCreationRestricted("channelID1", "userID1"))
+	})
+}
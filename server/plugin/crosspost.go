@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// resolveChannels replaces the names of the channels a new poll gets cross-posted to by their channel IDs.
+// Channels are looked up in a given team. The creator has to be a member of every channel and be allowed to create polls in them.
+func (p *MatterpollPlugin) resolveChannels(newPoll *poll.Poll, teamID, channelID string) error {
+	return newPoll.ResolveChannels(channelID, func(name string) (string, error) {
+		channel, appErr := p.API.GetChannelByName(teamID, name, false)
+		if appErr != nil {
+			return "", fmt.Errorf("Unknown channel ~%s", name)
+		}
+		if _, appErr := p.API.GetChannelMember(channel.Id, newPoll.Creator); appErr != nil {
+			return "", fmt.Errorf("You aren't a member of ~%s", name)
+		}
+		if p.isChannelDisabled(channel.Id) {
+			return "", fmt.Errorf("Polls are disabled in ~%s", name)
+		}
+		if p.isPollCreationRestricted(channel.Id, newPoll.Creator) {
+			return "", fmt.Errorf("You aren't allowed to create polls in ~%s", name)
+		}
+		return channel.Id, nil
+	})
+}
+
+// crossPostPoll posts a new poll with given attachments into the further channels of its settings and records the cross posts.
+// A channel that can't be posted into is skipped, so the poll still runs in all other channels.
+func (p *MatterpollPlugin) crossPostPoll(newPoll *poll.Poll, attachments []*model.SlackAttachment) {
+	for _, channelID := range newPoll.Settings.Channels {
+		post := &model.Post{
+			UserId:    p.botUserID,
+			ChannelId: channelID,
+			Type:      model.POST_DEFAULT,
+		}
+		model.ParseSlackAttachment(post, attachments)
+
+		rpost, appErr := p.API.CreatePost(post)
+		if appErr != nil {
+			p.API.LogWarn("failed to cross-post poll", "pollID", newPoll.ID, "channelID", channelID, "error", appErr.Error())
+			continue
+		}
+		if newPoll.Settings.Pin {
+			p.pinPollPost(newPoll, rpost)
+		}
+		newPoll.CrossPosts = append(newPoll.CrossPosts, &poll.Post{ChannelID: rpost.ChannelId, PostID: rpost.Id})
+	}
+}
+
+// updateCrossPosts brings all posts of a cross-posted poll up to date after a change that only updated the post a user interacted with.
+// Polls that aren't cross-posted are skipped. Failures are only logged, since the votes are stored already.
+func (p *MatterpollPlugin) updateCrossPosts(currentPoll *poll.Poll) {
+	if !currentPoll.IsCrossPosted() {
+		return
+	}
+	if appErr := p.updatePollPost(currentPoll); appErr != nil {
+		p.API.LogWarn("failed to update cross posts", "pollID", currentPoll.ID, "error", appErr.Error())
+	}
+}
+
+// otherPollPosts returns the IDs of the posts of a cross-posted poll except a given one, e.g. the post a user deleted the poll from.
+// It's empty for polls that aren't cross-posted.
+func otherPollPosts(currentPoll *poll.Poll, postID string) []string {
+	postIDs := []string{}
+	if !currentPoll.IsCrossPosted() {
+		return postIDs
+	}
+	for _, post := range currentPoll.Posts() {
+		if post.PostID != postID {
+			postIDs = append(postIDs, post.PostID)
+		}
+	}
+	return postIDs
+}
+
+// getPollEligibleVoters returns the IDs of all users that may vote in a given poll, which are the members of all channels it's posted in.
+// Bots, deactivated users and excluded guest accounts are left out, and members of several channels are only counted once.
+func (p *MatterpollPlugin) getPollEligibleVoters(currentPoll *poll.Poll) ([]string, *model.AppError) {
+	eligibleVoters := []string{}
+	seen := map[string]bool{}
+	for _, channelID := range currentPoll.ChannelIDs() {
+		channelVoters, appErr := p.getEligibleVoters(channelID, p.excludesGuests(currentPoll))
+		if appErr != nil {
+			return nil, appErr
+		}
+		for _, userID := range channelVoters {
+			if !seen[userID] {
+				seen[userID] = true
+				eligibleVoters = append(eligibleVoters, userID)
+			}
+		}
+	}
+	return eligibleVoters, nil
+}
@@ -0,0 +1,235 @@
+package plugin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func crossPostedPoll(settings poll.Settings) *poll.Poll {
+	settings.Channels = []string{"channelID2"}
+	p := testutils.GetPollWithSettings(settings)
+	p.PostID = "postID1"
+	p.ChannelID = "channelID1"
+	p.CrossPosts = []*poll.Post{{ChannelID: "channelID2", PostID: "postID2"}}
+	return p
+}
+
+func TestResolveChannels(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		ExpectedChannels []string
+		ShouldError      bool
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("GetChannelMember", "channelID2", "userID1").Return(&model.ChannelMember{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("IsDisabled", "channelID2").Return(false, nil)
+				store.ChannelStore.On("GetCreators", "channelID2").Return("", nil)
+				return store
+			},
+			ExpectedChannels: []string{"channelID2"},
+		},
+		"unknown channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore:       func(store *mockstore.Store) *mockstore.Store { return store },
+			ExpectedChannels: []string{"dev"},
+			ShouldError:      true,
+		},
+		"creator isn't a member": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("GetChannelMember", "channelID2", "userID1").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore:       func(store *mockstore.Store) *mockstore.Store { return store },
+			ExpectedChannels: []string{"dev"},
+			ShouldError:      true,
+		},
+		"polls are disabled in channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("GetChannelMember", "channelID2", "userID1").Return(&model.ChannelMember{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("IsDisabled", "channelID2").Return(true, nil)
+				return store
+			},
+			ExpectedChannels: []string{"dev"},
+			ShouldError:      true,
+		},
+		"creator isn't allowed to create polls in channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelByName", "teamID1", "dev", false).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("GetChannelMember", "channelID2", "userID1").Return(&model.ChannelMember{}, nil)
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.ChannelStore.On("IsDisabled", "channelID2").Return(false, nil)
+				store.ChannelStore.On("GetCreators", "channelID2").Return(pollCreatorsSystemAdmins, nil)
+				return store
+			},
+			ExpectedChannels: []string{"dev"},
+			ShouldError:      true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			newPoll := testutils.GetPollWithSettings(poll.Settings{Channels: []string{"dev"}})
+			err := p.resolveChannels(newPoll, "teamID1", "channelID1")
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.ExpectedChannels, newPoll.Settings.Channels)
+		})
+	}
+}
+
+func TestCrossPostPoll(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "channelID2" && post.RootId == ""
+	})).Return(&model.Post{Id: "postID2", ChannelId: "channelID2"}, nil)
+	api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+		return post.ChannelId == "channelID3"
+	})).Return(nil, &model.AppError{})
+	api.On("LogWarn", GetMockArgumentsWithType("string", 7)...).Return()
+	defer api.AssertExpectations(t)
+	p := setupTestPlugin(t, api, &mockstore.Store{})
+
+	newPoll := testutils.GetPollWithSettings(poll.Settings{Channels: []string{"channelID2", "channelID3"}})
+	p.crossPostPoll(newPoll, newPoll.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe"))
+
+	// The poll keeps running in the channels it could be posted in
+	assert.Equal(t, []*poll.Post{{ChannelID: "channelID2", PostID: "postID2"}}, newPoll.CrossPosts)
+}
+
+func TestUpdatePollPostCrossPosted(t *testing.T) {
+	t.Run("running poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+		api.On("GetPost", "postID2").Return(&model.Post{Id: "postID2"}, nil)
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool { return post.Id == "postID1" })).Return(nil, nil).Once()
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool { return post.Id == "postID2" })).Return(nil, nil).Once()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Nil(t, p.updatePollPost(crossPostedPoll(poll.Settings{})))
+	})
+	t.Run("ended poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "postID1" && post.ChannelId == "channelID1"
+		})).Return(nil, nil).Once()
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "postID2" && post.ChannelId == "channelID2"
+		})).Return(nil, nil).Once()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		endedPoll := crossPostedPoll(poll.Settings{})
+		endedPoll.EndedAt = 1234567891
+		assert.Nil(t, p.updatePollPost(endedPoll))
+	})
+}
+
+func TestCheckVoterCrossPosted(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Expected    *i18n.Message
+		ShouldError bool
+	}{
+		"member of the channel the poll was created in": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID2").Return(&model.ChannelMember{}, nil)
+				return api
+			},
+			Expected: nil,
+		},
+		"member of a cross-posted channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID2").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				api.On("GetChannelMember", "channelID2", "userID2").Return(&model.ChannelMember{}, nil)
+				return api
+			},
+			Expected: nil,
+		},
+		"member of no channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID2").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				api.On("GetChannelMember", "channelID2", "userID2").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			Expected: responseVoteNotMember,
+		},
+		"GetChannelMember fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID2").Return(nil, &model.AppError{StatusCode: http.StatusInternalServerError})
+				return api
+			},
+			Expected:    nil,
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+
+			rejection, err := p.checkVoter(crossPostedPoll(poll.Settings{MembersOnly: true}), "userID2")
+			assert.Equal(t, test.Expected, rejection)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestOtherPollPosts(t *testing.T) {
+	p := crossPostedPoll(poll.Settings{})
+	assert.Equal(t, []string{"postID2"}, otherPollPosts(p, "postID1"))
+	assert.Equal(t, []string{"postID1"}, otherPollPosts(p, "postID2"))
+
+	p.CrossPosts = nil
+	assert.Empty(t, otherPollPosts(p, "postID1"))
+}
+
+func TestPublishPollEventCrossPosted(t *testing.T) {
+	api := &plugintest.API{}
+	for _, channelID := range []string{"channelID1", "channelID2"} {
+		api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), &model.WebsocketBroadcast{ChannelId: channelID}).Return().Once()
+	}
+	defer api.AssertExpectations(t)
+	p := setupTestPlugin(t, api, &mockstore.Store{})
+
+	p.publishPollEvent(websocketEventPollUpdated, crossPostedPoll(poll.Settings{}))
+}
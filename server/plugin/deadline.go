@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	deadlineReminderMessage = &i18n.Message{
+		ID:    "deadlineReminder.message",
+		Other: "@channel Voting on [{{.Question}}]({{.Link}}) closes at {{.EndAt}} UTC. {{.Voters}} of {{.Members}} channel members have voted so far.",
+	}
+	deadlineReminderMessageNoLink = &i18n.Message{
+		ID:    "deadlineReminder.messageNoLink",
+		Other: "@channel Voting on **{{.Question}}** closes at {{.EndAt}} UTC. {{.Voters}} of {{.Members}} channel members have voted so far.",
+	}
+)
+
+// scheduleDeadlineReminder stores a job that reminds the channel of a given poll before its deadline.
+// Nothing is scheduled if reminders are disabled or the time of the reminder has already passed.
+func (p *MatterpollPlugin) scheduleDeadlineReminder(poll *poll.Poll) error {
+	minutes := p.getConfiguration().deadlineReminderMinutes
+	if minutes == 0 {
+		return nil
+	}
+	runAt := poll.Settings.EndAt - int64(time.Duration(minutes)*time.Minute/time.Millisecond)
+	if runAt <= model.GetMillis() {
+		return nil
+	}
+	return p.Store.Job().Save(job.NewJob(job.TypeRemindDeadline, poll.ID, runAt))
+}
+
+// unscheduleDeadlineReminder removes the job that reminds the channel of a given poll before its deadline
+func (p *MatterpollPlugin) unscheduleDeadlineReminder(poll *poll.Poll) error {
+	if p.getConfiguration().deadlineReminderMinutes == 0 {
+		return nil
+	}
+	// Jobs are identified by their type and poll, hence the time of the reminder doesn't matter
+	return p.Store.Job().Delete(job.NewJob(job.TypeRemindDeadline, poll.ID, 0))
+}
+
+// remindDeadline posts into the channel of a given poll that voting closes soon.
+// Polls that ended in the meantime don't get a reminder and neither do polls after reminders got disabled.
+func (p *MatterpollPlugin) remindDeadline(pollID string) error {
+	runningPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get poll")
+	}
+	if runningPoll.IsEnded() || !runningPoll.HasDeadline() || p.getConfiguration().deadlineReminderMinutes == 0 {
+		return nil
+	}
+
+	if appErr := p.postDeadlineReminder(runningPoll); appErr != nil {
+		return errors.Wrap(appErr, "failed to post deadline reminder")
+	}
+	return nil
+}
+
+// postDeadlineReminder posts the reminder of a given poll into its channel. It replies to the thread the poll was posted in.
+func (p *MatterpollPlugin) postDeadlineReminder(runningPoll *poll.Poll) *model.AppError {
+	eligibleVoters, appErr := p.getPollEligibleVoters(runningPoll)
+	if appErr != nil {
+		return appErr
+	}
+	link, appErr := p.getPollPermalink(runningPoll)
+	if appErr != nil {
+		return appErr
+	}
+
+	data := map[string]interface{}{
+		"Question": runningPoll.Question,
+		"EndAt":    time.Unix(0, runningPoll.Settings.EndAt*int64(time.Millisecond)).UTC().Format(poll.TimeLayout),
+		"Voters":   runningPoll.NumberOfVoters(),
+		"Members":  len(eligibleVoters),
+	}
+	message := deadlineReminderMessageNoLink
+	if link != "" {
+		data["Link"] = link
+		message = deadlineReminderMessage
+	}
+
+	_, appErr = p.API.CreatePost(&model.Post{
+		UserId:    p.botUserID,
+		ChannelId: runningPoll.ChannelID,
+		RootId:    runningPoll.RootID,
+		Message: p.LocalizeWithConfig(p.getPublicLocalizer(), &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData:   data,
+		}),
+	})
+	return appErr
+}
+
+// scheduleCountdownRefresh stores a job that updates the post of a given poll once its countdown changes.
+// Nothing is scheduled once the deadline has passed.
+func (p *MatterpollPlugin) scheduleCountdownRefresh(poll *poll.Poll) error {
+	runAt := poll.NextCountdownChange(model.GetMillis())
+	if runAt == 0 {
+		return nil
+	}
+	return p.Store.Job().Save(job.NewJob(job.TypeRefreshCountdown, poll.ID, runAt))
+}
+
+// unscheduleCountdownRefresh removes the job that updates the post of a given poll once its countdown changes
+func (p *MatterpollPlugin) unscheduleCountdownRefresh(poll *poll.Poll) error {
+	// Jobs are identified by their type and poll, hence the time of the refresh doesn't matter
+	return p.Store.Job().Delete(job.NewJob(job.TypeRefreshCountdown, poll.ID, 0))
+}
+
+// refreshCountdown updates the post of a running poll, so it shows the current countdown, and returns the next refresh.
+// The last refresh happens at the deadline and shows that voting is closed, even if the poll hasn't been ended yet.
+// A failed refresh doesn't stop the following ones.
+func (p *MatterpollPlugin) refreshCountdown(j *job.Job) (*job.Job, error) {
+	runningPoll, err := p.Store.Poll().Get(j.PollID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get poll")
+	}
+	if runningPoll.IsEnded() || runningPoll.IsDeleted() || !runningPoll.HasDeadline() {
+		return nil, nil
+	}
+
+	var next *job.Job
+	if runAt := runningPoll.NextCountdownChange(model.GetMillis()); runAt != 0 {
+		next = job.NewJob(job.TypeRefreshCountdown, runningPoll.ID, runAt)
+	}
+	if appErr := p.updatePollPost(runningPoll); appErr != nil {
+		return next, errors.Wrap(appErr, "failed to update poll post")
+	}
+	return next, nil
+}
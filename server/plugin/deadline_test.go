@@ -0,0 +1,319 @@
+package plugin
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleDeadlineReminder(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	endJob := job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890+millisPerDay)
+	// The countdown changes from "Ends in 1d" to "Ends in 23h" right away
+	refreshJob := job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 1234567891)
+
+	t.Run("reminders disabled", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567890 + millisPerDay})
+
+		store := &mockstore.Store{}
+		store.JobStore.On("Save", endJob).Return(nil)
+		store.JobStore.On("Save", refreshJob).Return(nil)
+		store.JobStore.On("Delete", endJob).Return(nil)
+		store.JobStore.On("Delete", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 0)).Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleEnd(poll))
+		assert.Nil(t, p.unscheduleEnd(poll))
+	})
+
+	t.Run("reminder before the deadline", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567890 + millisPerDay})
+
+		store := &mockstore.Store{}
+		store.JobStore.On("Save", endJob).Return(nil)
+		store.JobStore.On("Save", job.NewJob(job.TypeRemindDeadline, testutils.GetPollID(), 1234567890+millisPerDay-60*60*1000)).Return(nil)
+		store.JobStore.On("Save", refreshJob).Return(nil)
+		store.JobStore.On("Delete", endJob).Return(nil)
+		store.JobStore.On("Delete", job.NewJob(job.TypeRemindDeadline, testutils.GetPollID(), 0)).Return(nil)
+		store.JobStore.On("Delete", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 0)).Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+		p.setConfiguration(&configuration{Trigger: "poll", deadlineReminderMinutes: 60})
+
+		assert.Nil(t, p.scheduleEnd(poll))
+		assert.Nil(t, p.unscheduleEnd(poll))
+	})
+
+	t.Run("reminder would be in the past", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567890 + 30*60*1000})
+
+		store := &mockstore.Store{}
+		store.JobStore.On("Save", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890+30*60*1000)).Return(nil)
+		// The countdown changes from "Ends in 30m" to "Ends in 29m" once a minute has passed
+		store.JobStore.On("Save", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 1234567890+60*1000)).Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+		p.setConfiguration(&configuration{Trigger: "poll", deadlineReminderMinutes: 60})
+
+		assert.Nil(t, p.scheduleEnd(poll))
+	})
+
+	t.Run("Save fails for the end job", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567890 + millisPerDay})
+
+		store := &mockstore.Store{}
+		store.JobStore.On("Save", endJob).Return(&model.AppError{})
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+		p.setConfiguration(&configuration{Trigger: "poll", deadlineReminderMinutes: 60})
+
+		assert.NotNil(t, p.scheduleEnd(poll))
+	})
+}
+
+func TestRemindDeadline(t *testing.T) {
+	runningPoll := func() *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{EndAt: 1234567890 + millisPerDay})
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	endedPoll := runningPoll()
+	endedPoll.EndedAt = 1234567890
+
+	setupMembers := func(api *plugintest.API) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+		api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{IsBot: true}, nil)
+		api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(&model.ChannelMembers{
+			{UserId: "userID1"}, {UserId: "userID2"}, {UserId: "userID3"}, {UserId: "userID4"}, {UserId: "userID5"}, {UserId: testutils.GetBotUserID()},
+		}, nil)
+		return api
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI                func(*plugintest.API) *plugintest.API
+		SetupStore              func(*mockstore.Store) *mockstore.Store
+		DeadlineReminderMinutes int
+		ShouldError             bool
+	}{
+		"Poll in a channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupMembers(api)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					Message:   "@channel Voting on [Question](" + testutils.GetSiteURL() + "/team1/pl/postID1) closes at 1970-01-16T06:56 UTC. 4 of 5 channel members have voted so far.",
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			DeadlineReminderMinutes: 60,
+			ShouldError:             false,
+		},
+		"Poll in a direct message": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupMembers(api)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					Message:   "@channel Voting on **Question** closes at 1970-01-16T06:56 UTC. 4 of 5 channel members have voted so far.",
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			DeadlineReminderMinutes: 60,
+			ShouldError:             false,
+		},
+		"Poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			DeadlineReminderMinutes: 60,
+			ShouldError:             false,
+		},
+		"Reminders got disabled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			DeadlineReminderMinutes: 0,
+			ShouldError:             false,
+		},
+		"PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			DeadlineReminderMinutes: 60,
+			ShouldError:             true,
+		},
+		"GetChannelMembers fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			DeadlineReminderMinutes: 60,
+			ShouldError:             true,
+		},
+		"CreatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupMembers(api)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					Message:   "@channel Voting on **Question** closes at 1970-01-16T06:56 UTC. 4 of 5 channel members have voted so far.",
+				}).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			DeadlineReminderMinutes: 60,
+			ShouldError:             true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.setConfiguration(&configuration{Trigger: "poll", deadlineReminderMinutes: test.DeadlineReminderMinutes})
+
+			err := p.remindDeadline(testutils.GetPollID())
+			if test.ShouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestRefreshCountdown(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	pollWithDeadline := func(endAt int64) *poll.Poll {
+		p := testutils.GetPollWithSettings(poll.Settings{EndAt: endAt})
+		p.PostID = "postID1"
+		return p
+	}
+	updatePost := func(api *plugintest.API) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			attachments := post.Attachments()
+			return post.Id == "postID1" && attachments[len(attachments)-1].Footer == "Ends in 45m"
+		})).Return(nil, nil)
+		return api
+	}
+	endedPoll := pollWithDeadline(1234567890 + 45*60*1000)
+	endedPoll.EndedAt = 1234567890
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Poll        *poll.Poll
+		GetError    error
+		ExpectedJob *job.Job
+		ShouldError bool
+	}{
+		"Countdown changes again": {
+			SetupAPI:    updatePost,
+			Poll:        pollWithDeadline(1234567890 + 45*60*1000),
+			ExpectedJob: job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 1234567890+60*1000),
+		},
+		"Deadline has passed": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					attachments := post.Attachments()
+					return attachments[len(attachments)-1].Footer == "Voting closed"
+				})).Return(nil, nil)
+				return api
+			},
+			Poll:        pollWithDeadline(1234567890),
+			ExpectedJob: nil,
+		},
+		"Poll has ended": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			Poll:        endedPoll,
+			ExpectedJob: nil,
+		},
+		"Poll has no deadline": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			Poll:        testutils.GetPoll(),
+			ExpectedJob: nil,
+		},
+		"PollStore.Get fails": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			GetError:    &model.AppError{},
+			ExpectedJob: nil,
+			ShouldError: true,
+		},
+		"UpdatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				return api
+			},
+			Poll:        pollWithDeadline(1234567890 + 45*60*1000),
+			ExpectedJob: job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 1234567890+60*1000),
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll, test.GetError)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			next, err := p.refreshCountdown(job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 1234567890))
+			assert.Equal(t, test.ExpectedJob, next)
+			if test.ShouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// postUpdateInterval is the minimum time between two updates of the posts of a poll caused by votes
+const postUpdateInterval = 2 * time.Second
+
+// postUpdateWindow tracks the votes on a poll during postUpdateInterval after its posts have been updated
+type postUpdateWindow struct {
+	timer *time.Timer
+	// pending is true if votes have been cast that the posts don't show yet
+	pending bool
+}
+
+// debouncePostUpdate reports whether the posts of a poll may be updated right away after a vote, and updates its cross posts if so.
+// Otherwise the posts have been updated less than postUpdateInterval ago and get updated with the latest poll once the interval has passed,
+// so a popular poll doesn't cause an edit of its posts for every single vote.
+// Every plugin instance of a cluster debounces the votes it handles on its own.
+func (p *MatterpollPlugin) debouncePostUpdate(votedPoll *poll.Poll) bool {
+	p.postUpdatesLock.Lock()
+	if p.postUpdates == nil {
+		p.postUpdates = map[string]*postUpdateWindow{}
+	}
+	if window, ok := p.postUpdates[votedPoll.ID]; ok {
+		window.pending = true
+		p.postUpdatesLock.Unlock()
+		return false
+	}
+	pollID := votedPoll.ID
+	p.postUpdates[pollID] = &postUpdateWindow{
+		timer: time.AfterFunc(postUpdateInterval, func() { p.closePostUpdateWindow(pollID) }),
+	}
+	p.postUpdatesLock.Unlock()
+
+	p.updateCrossPosts(votedPoll)
+	return true
+}
+
+// closePostUpdateWindow updates the posts of a poll if votes have been cast since they were last updated and opens the next window.
+// Without such votes the window is closed, so the next vote updates the posts right away.
+func (p *MatterpollPlugin) closePostUpdateWindow(pollID string) {
+	p.postUpdatesLock.Lock()
+	window, ok := p.postUpdates[pollID]
+	if !ok {
+		// The pending updates have been flushed already
+		p.postUpdatesLock.Unlock()
+		return
+	}
+	if !window.pending {
+		delete(p.postUpdates, pollID)
+		p.postUpdatesLock.Unlock()
+		return
+	}
+	window.pending = false
+	window.timer.Reset(postUpdateInterval)
+	p.postUpdatesLock.Unlock()
+
+	p.updateLatestPollPost(pollID)
+}
+
+// flushPostUpdates stops all debounced updates and updates the posts of the polls that have pending votes right away
+func (p *MatterpollPlugin) flushPostUpdates() {
+	p.postUpdatesLock.Lock()
+	pending := []string{}
+	for pollID, window := range p.postUpdates {
+		window.timer.Stop()
+		if window.pending {
+			pending = append(pending, pollID)
+		}
+	}
+	p.postUpdates = nil
+	p.postUpdatesLock.Unlock()
+
+	for _, pollID := range pending {
+		p.updateLatestPollPost(pollID)
+	}
+}
+
+// updateLatestPollPost updates the posts of a poll with its latest version from the store. Failures are only logged.
+func (p *MatterpollPlugin) updateLatestPollPost(pollID string) {
+	latestPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		p.API.LogWarn("failed to get poll for debounced update", "pollID", pollID, "error", err.Error())
+		return
+	}
+	// The post of a deleted poll doesn't show the poll anymore
+	if latestPoll.IsDeleted() {
+		return
+	}
+	if appErr := p.updatePollPost(latestPoll); appErr != nil {
+		p.API.LogWarn("failed to update poll post", "pollID", pollID, "error", appErr.Error())
+	}
+}
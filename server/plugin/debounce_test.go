@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDebouncePostUpdate(t *testing.T) {
+	votedPoll := testutils.GetPollWithVotes()
+	votedPoll.PostID = "postID1"
+	expectUpdate := func(api *plugintest.API) {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+		api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil).Once()
+	}
+
+	t.Run("votes within the interval are debounced", func(t *testing.T) {
+		store := &mockstore.Store{}
+		// Polls without post don't need an update
+		store.PollStore.On("Get", votedPoll.ID).Return(testutils.GetPollWithVotes(), nil)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+		defer p.flushPostUpdates()
+
+		assert.True(t, p.debouncePostUpdate(votedPoll))
+		assert.False(t, p.debouncePostUpdate(votedPoll))
+		assert.False(t, p.debouncePostUpdate(votedPoll))
+		assert.True(t, p.postUpdates[votedPoll.ID].pending)
+
+		otherPoll := testutils.GetPoll()
+		otherPoll.ID = "pollID2"
+		assert.True(t, p.debouncePostUpdate(otherPoll))
+		assert.False(t, p.postUpdates[otherPoll.ID].pending)
+	})
+	t.Run("window with pending votes updates the post", func(t *testing.T) {
+		api := &plugintest.API{}
+		expectUpdate(api)
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", votedPoll.ID).Return(votedPoll.Copy(), nil).Once()
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+		defer p.flushPostUpdates()
+
+		p.debouncePostUpdate(votedPoll)
+		p.debouncePostUpdate(votedPoll)
+		p.closePostUpdateWindow(votedPoll.ID)
+
+		// The next window has started
+		assert.Contains(t, p.postUpdates, votedPoll.ID)
+		assert.False(t, p.postUpdates[votedPoll.ID].pending)
+	})
+	t.Run("window without pending votes closes", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+		defer p.flushPostUpdates()
+
+		p.debouncePostUpdate(votedPoll)
+		p.closePostUpdateWindow(votedPoll.ID)
+
+		assert.NotContains(t, p.postUpdates, votedPoll.ID)
+		assert.True(t, p.debouncePostUpdate(votedPoll))
+	})
+	t.Run("flush updates the posts of polls with pending votes", func(t *testing.T) {
+		api := &plugintest.API{}
+		expectUpdate(api)
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", votedPoll.ID).Return(votedPoll.Copy(), nil).Once()
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		otherPoll := testutils.GetPoll()
+		otherPoll.ID = "pollID2"
+		p.debouncePostUpdate(votedPoll)
+		p.debouncePostUpdate(votedPoll)
+		p.debouncePostUpdate(otherPoll)
+		p.flushPostUpdates()
+
+		assert.Nil(t, p.postUpdates)
+		p.closePostUpdateWindow(votedPoll.ID)
+	})
+	t.Run("deleted poll", func(t *testing.T) {
+		deletedPoll := votedPoll.Copy()
+		deletedPoll.DeletedAt = 1234567891
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", votedPoll.ID).Return(deletedPoll, nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		p.updateLatestPollPost(votedPoll.ID)
+	})
+	t.Run("Get fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Get", votedPoll.ID).Return(nil, errors.New(""))
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		p.updateLatestPollPost(votedPoll.ID)
+	})
+}
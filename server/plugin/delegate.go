@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextDelegate = &i18n.Message{
+		ID:    "command.help.text.delegate",
+		Other: "To let another user vote for you in a poll, type `/{{.Trigger}} delegate <poll ID> @username`. Their votes count for you as well",
+	}
+	commandErrorDelegateUsage = &i18n.Message{
+		ID:    "command.error.delegate.usage",
+		Other: "Usage: `/{{.Trigger}} delegate <poll ID> @username`",
+	}
+
+	responseDelegateVoteSuccess = &i18n.Message{
+		ID:    "response.delegateVote.success",
+		Other: "You delegated your vote to @{{.Username}}. Their votes count for you as well.",
+	}
+	responseDelegateVoteUnknownUser = &i18n.Message{
+		ID:    "response.delegateVote.unknownUser",
+		Other: "The delegate couldn't be found. Please check the username.",
+	}
+	responseDelegateVoteBot = &i18n.Message{
+		ID:    "response.delegateVote.bot",
+		Other: "Votes can't be delegated to bots.",
+	}
+	responseDelegateVoteSelf = &i18n.Message{
+		ID:    "response.delegateVote.self",
+		Other: "You can't delegate your vote to yourself.",
+	}
+	responseDelegateVoteNotSupported = &i18n.Message{
+		ID:    "response.delegateVote.notSupported",
+		Other: "Votes can only be delegated in polls where voters pick answer options. Surveys, ranked, rating, approval and scheduling polls and polls with receipts don't support it.",
+	}
+	responseDelegateVoteAlreadyDelegated = &i18n.Message{
+		ID:    "response.delegateVote.alreadyDelegated",
+		Other: "You have already delegated your vote in this poll.",
+	}
+	responseDelegateVoteAlreadyVoted = &i18n.Message{
+		ID:    "response.delegateVote.alreadyVoted",
+		Other: "You have already voted in this poll. Reset your vote to delegate it.",
+	}
+	responseDelegateVoteChained = &i18n.Message{
+		ID:    "response.delegateVote.chained",
+		Other: "Delegations can't be chained. Either votes got delegated to you or @{{.Username}} delegated their vote.",
+	}
+	responseDelegateVoteDelegateNotMember = &i18n.Message{
+		ID:    "response.delegateVote.delegateNotMember",
+		Other: "@{{.Username}} isn't a member of the channel this poll was posted in and can't vote.",
+	}
+	responseDelegateVoteDelegateGuest = &i18n.Message{
+		ID:    "response.delegateVote.delegateGuest",
+		Other: "@{{.Username}} is a guest and isn't allowed to vote in this poll.",
+	}
+
+	responseVoteDelegated = &i18n.Message{
+		ID:    "response.vote.delegated",
+		Other: "You have delegated your vote in this poll. Your delegate votes for you.",
+	}
+)
+
+// executeDelegateCommand delegates the vote of the user in the poll with the ID given in params to the user given in params
+func (p *MatterpollPlugin) executeDelegateCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	if len(params) != 2 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorDelegateUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	delegate, appErr := p.API.GetUserByUsername(strings.TrimPrefix(params[1], "@"))
+	if appErr != nil {
+		return p.LocalizeDefaultMessage(userLocalizer, responseDelegateVoteUnknownUser), nil
+	}
+
+	msg, err := p.delegateVoteByID(params[0], args.UserId, delegate)
+	if err != nil {
+		p.API.LogError("failed to delegate vote", "err", err.Error())
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: msg,
+		TemplateData:   map[string]interface{}{"Trigger": trigger, "Username": delegate.Username},
+	}), nil
+}
+
+// delegateVoteByID lets a given user delegate their vote in a poll to another user.
+// Both users have to be allowed to vote, and the delegation is final until the poll ends.
+func (p *MatterpollPlugin) delegateVoteByID(pollID, userID string, delegate *model.User) (*i18n.Message, error) {
+	currentPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	switch {
+	case currentPoll.IsScheduled():
+		return commandErrorNotPosted, nil
+	case currentPoll.IsEnded() || currentPoll.IsDeleted():
+		return responseVotePollEnded, nil
+	case !currentPoll.CanDelegate():
+		return responseDelegateVoteNotSupported, nil
+	case delegate.IsBot:
+		return responseDelegateVoteBot, nil
+	case delegate.Id == userID:
+		return responseDelegateVoteSelf, nil
+	case currentPoll.HasDelegated(userID):
+		return responseDelegateVoteAlreadyDelegated, nil
+	case currentPoll.HasVoted(userID):
+		return responseDelegateVoteAlreadyVoted, nil
+	case currentPoll.HasDelegated(delegate.Id) || len(currentPoll.DelegatorsOf(userID)) > 0:
+		return responseDelegateVoteChained, nil
+	}
+
+	rejection, err := p.checkVoter(currentPoll, userID)
+	if err != nil {
+		return commandErrorGeneric, err
+	}
+	if rejection != nil {
+		return rejection, nil
+	}
+	if rejection, err = p.checkVoter(currentPoll, delegate.Id); err != nil {
+		return commandErrorGeneric, err
+	}
+	if rejection == responseVoteGuest {
+		return responseDelegateVoteDelegateGuest, nil
+	}
+	if rejection != nil {
+		return responseDelegateVoteDelegateNotMember, nil
+	}
+
+	delegatedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		return latest.Delegate(userID, delegate.Id)
+	})
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to delegate vote")
+	}
+	p.publishPollEvent(websocketEventPollUpdated, delegatedPoll)
+	p.recordAudit(audit.ActionVoteDelegated, delegatedPoll, userID, "@"+delegate.Username)
+
+	if appErr := p.updatePollPost(delegatedPoll); appErr != nil {
+		p.API.LogWarn("failed to update poll post", "pollID", pollID, "error", appErr.Error())
+	}
+	// The vote of the delegate may already cover the last missing voter
+	p.endPollIfAllVoted(delegatedPoll)
+	return responseDelegateVoteSuccess, nil
+}
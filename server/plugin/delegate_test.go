@@ -0,0 +1,207 @@
+package plugin
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDelegateVoteByID(t *testing.T) {
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	posted := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	delegate := &model.User{Id: "userID2", Username: "user2"}
+
+	for name, test := range map[string]struct {
+		Poll                *poll.Poll
+		UserID              string
+		Delegate            *model.User
+		SetupAPI            func(*plugintest.API) *plugintest.API
+		SetupStore          func(*mockstore.Store, *poll.Poll) *mockstore.Store
+		ExpectedMessage     string
+		ExpectedDelegations map[string]string
+		ShouldError         bool
+	}{
+		"all fine": {
+			Poll:     posted(testutils.GetPoll()),
+			UserID:   "userID3",
+			Delegate: delegate,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1"
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, delegatedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(delegatedPoll))
+				return store
+			},
+			ExpectedMessage:     responseDelegateVoteSuccess.Other,
+			ExpectedDelegations: map[string]string{"userID3": "userID2"},
+		},
+		"members only, delegate isn't a member": {
+			Poll:     posted(testutils.GetPollWithSettings(poll.Settings{MembersOnly: true})),
+			UserID:   "userID3",
+			Delegate: delegate,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID3").Return(&model.ChannelMember{}, nil)
+				api.On("GetChannelMember", "channelID1", "userID2").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDelegateVoteDelegateNotMember.Other,
+		},
+		"members only, user isn't a member": {
+			Poll:     posted(testutils.GetPollWithSettings(poll.Settings{MembersOnly: true})),
+			UserID:   "userID3",
+			Delegate: delegate,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannelMember", "channelID1", "userID3").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseVoteNotMember.Other,
+		},
+		"user has already voted": {
+			Poll:            posted(testutils.GetPollWithVotes()),
+			UserID:          "userID3",
+			Delegate:        delegate,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDelegateVoteAlreadyVoted.Other,
+		},
+		"user has already delegated": {
+			Poll: func() *poll.Poll {
+				p := posted(testutils.GetPoll())
+				p.Delegations = map[string]string{"userID3": "userID4"}
+				return p
+			}(),
+			UserID:              "userID3",
+			Delegate:            delegate,
+			SetupAPI:            func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:          func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage:     responseDelegateVoteAlreadyDelegated.Other,
+			ExpectedDelegations: map[string]string{"userID3": "userID4"},
+		},
+		"delegate has delegated": {
+			Poll: func() *poll.Poll {
+				p := posted(testutils.GetPoll())
+				p.Delegations = map[string]string{"userID2": "userID4"}
+				return p
+			}(),
+			UserID:              "userID3",
+			Delegate:            delegate,
+			SetupAPI:            func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:          func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage:     responseDelegateVoteChained.Other,
+			ExpectedDelegations: map[string]string{"userID2": "userID4"},
+		},
+		"delegate to oneself": {
+			Poll:            posted(testutils.GetPoll()),
+			UserID:          "userID2",
+			Delegate:        delegate,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDelegateVoteSelf.Other,
+		},
+		"delegate to a bot": {
+			Poll:            posted(testutils.GetPoll()),
+			UserID:          "userID3",
+			Delegate:        &model.User{Id: testutils.GetBotUserID(), Username: "matterpoll", IsBot: true},
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDelegateVoteBot.Other,
+		},
+		"ranked poll": {
+			Poll:            posted(testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked})),
+			UserID:          "userID3",
+			Delegate:        delegate,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDelegateVoteNotSupported.Other,
+		},
+		"poll has ended": {
+			Poll: func() *poll.Poll {
+				p := posted(testutils.GetPoll())
+				p.EndedAt = 1234567891
+				return p
+			}(),
+			UserID:          "userID3",
+			Delegate:        delegate,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseVotePollEnded.Other,
+		},
+		"poll isn't posted yet": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{PostAt: 1714554000000}),
+			UserID:          "userID3",
+			Delegate:        delegate,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: commandErrorNotPosted.Other,
+		},
+		"PollStore.Update fails": {
+			Poll:     posted(testutils.GetPoll()),
+			UserID:   "userID3",
+			Delegate: delegate,
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			ExpectedMessage: commandErrorGeneric.Other,
+			ShouldError:     true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll.Copy(), nil)
+			store = test.SetupStore(store, test.Poll)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, err := p.delegateVoteByID(testutils.GetPollID(), test.UserID, test.Delegate)
+			assert.Equal(t, test.ExpectedMessage, msg.Other)
+			assert.Equal(t, test.ExpectedDelegations, test.Poll.Delegations)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestHandleVoteDelegated(t *testing.T) {
+	delegatedPoll := testutils.GetPoll()
+	delegatedPoll.Delegations = map[string]string{"userID2": "userID1"}
+
+	api := &plugintest.API{}
+	defer api.AssertExpectations(t)
+	store := &mockstore.Store{}
+	store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(GetMockPollUpdate(delegatedPoll))
+	defer store.AssertExpectations(t)
+	p := setupTestPlugin(t, api, store)
+
+	msg, attachments, err := p.vote(testutils.GetPollID(), "channelID1", "userID2", 0)
+	assert.Equal(t, responseVoteDelegated, msg)
+	assert.Nil(t, attachments)
+	assert.Nil(t, err)
+	assert.Empty(t, delegatedPoll.AnswerOptions[0].Voter)
+}
@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	digestPostMessage = &i18n.Message{
+		ID:    "digest.post.message",
+		Other: "Here are the current standings of your poll [{{.Question}}]({{.Link}}):",
+	}
+	digestPostMessageNoLink = &i18n.Message{
+		ID:    "digest.post.messageNoLink",
+		Other: "Here are the current standings of your poll **{{.Question}}**:",
+	}
+)
+
+// scheduleDigest stores a job that sends the first digest of a given poll. Polls without a digest are ignored.
+func (p *MatterpollPlugin) scheduleDigest(poll *poll.Poll) error {
+	if !poll.HasDigest() {
+		return nil
+	}
+	next := nextDigest(poll, poll.StartAt())
+	if next == nil {
+		return nil
+	}
+	return p.Store.Job().Save(next)
+}
+
+// unscheduleDigest removes the job that sends the next digest of a given poll. Polls without a digest are ignored.
+func (p *MatterpollPlugin) unscheduleDigest(poll *poll.Poll) error {
+	if !poll.HasDigest() {
+		return nil
+	}
+	// Jobs are identified by their type and poll, hence the time of the next digest doesn't matter
+	return p.Store.Job().Delete(job.NewJob(job.TypeSendDigest, poll.ID, 0))
+}
+
+// nextDigest returns the job that sends the digest of a given poll which follows a given time in milliseconds.
+// Digests missed while the plugin was disabled are skipped. It's nil if the poll ends before the digest would be sent.
+func nextDigest(poll *poll.Poll, after int64) *job.Job {
+	runAt := poll.Settings.Digest.Next(after)
+	now := model.GetMillis()
+	for runAt <= now {
+		runAt = poll.Settings.Digest.Next(runAt)
+	}
+	if poll.HasDeadline() && runAt >= poll.Settings.EndAt {
+		return nil
+	}
+	return job.NewJob(job.TypeSendDigest, poll.ID, runAt)
+}
+
+// sendDigest sends the creator of the poll of a given job a direct message with the current standings.
+// It returns the job that sends the next digest, which is nil once the poll has ended.
+func (p *MatterpollPlugin) sendDigest(j *job.Job) (*job.Job, error) {
+	runningPoll, err := p.Store.Poll().Get(j.PollID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get poll")
+	}
+	if runningPoll.IsEnded() || !runningPoll.HasDigest() {
+		return nil, nil
+	}
+
+	// A failed digest shouldn't stop the following ones
+	next := nextDigest(runningPoll, j.RunAt)
+	if appErr := p.postDigest(runningPoll); appErr != nil {
+		return next, errors.Wrap(appErr, "failed to post digest")
+	}
+	return next, nil
+}
+
+// postDigest posts the current standings of a given poll into the direct channel between the bot and the creator of the poll
+func (p *MatterpollPlugin) postDigest(runningPoll *poll.Poll) *model.AppError {
+	creator, appErr := p.API.GetUser(runningPoll.Creator)
+	if appErr != nil {
+		return appErr
+	}
+	eligibleVoters, appErr := p.getPollEligibleVoters(runningPoll)
+	if appErr != nil {
+		return appErr
+	}
+	link, appErr := p.getPollPermalink(runningPoll)
+	if appErr != nil {
+		return appErr
+	}
+
+	localizer := p.getLocalizerForUser(creator)
+	heading := p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+		DefaultMessage: digestPostMessageNoLink,
+		TemplateData:   map[string]interface{}{"Question": runningPoll.Question},
+	})
+	if link != "" {
+		heading = p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+			DefaultMessage: digestPostMessage,
+			TemplateData: map[string]interface{}{
+				"Question": runningPoll.Question,
+				"Link":     link,
+			},
+		})
+	}
+
+	directChannel, appErr := p.API.GetDirectChannel(creator.Id, p.botUserID)
+	if appErr != nil {
+		return appErr
+	}
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: directChannel.Id,
+		Message:   heading + "\n\n" + runningPoll.ToDigest(localizer, len(eligibleVoters)),
+	}
+	_, appErr = p.API.CreatePost(post)
+	return appErr
+}
+
+// getPollPermalink returns the permalink to the post of a given poll.
+// It's empty for polls in direct and group messages, because permalinks require a team.
+func (p *MatterpollPlugin) getPollPermalink(runningPoll *poll.Poll) (string, *model.AppError) {
+	channel, appErr := p.API.GetChannel(runningPoll.ChannelID)
+	if appErr != nil {
+		return "", appErr
+	}
+	if channel.TeamId == "" {
+		return "", nil
+	}
+	team, appErr := p.API.GetTeam(channel.TeamId)
+	if appErr != nil {
+		return "", appErr
+	}
+	return p.makePermalink(team.Name, runningPoll.PostID), nil
+}
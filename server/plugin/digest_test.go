@@ -0,0 +1,228 @@
+package plugin
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const millisPerDay = 24 * 60 * 60 * 1000
+
+func TestScheduleDigest(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	t.Run("poll without digest", func(t *testing.T) {
+		store := &mockstore.Store{}
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleDigest(testutils.GetPoll()))
+		assert.Nil(t, p.unscheduleDigest(testutils.GetPoll()))
+	})
+
+	t.Run("poll with digest", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{Digest: poll.RecurrenceDaily})
+
+		store := &mockstore.Store{}
+		store.JobStore.On("Save", job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 1234567890+millisPerDay)).Return(nil)
+		store.JobStore.On("Delete", job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 0)).Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleDigest(poll))
+		assert.Nil(t, p.unscheduleDigest(poll))
+	})
+
+	t.Run("poll ends before the first digest", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{Digest: poll.RecurrenceWeekly, EndAt: 1234567890 + millisPerDay})
+
+		store := &mockstore.Store{}
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleDigest(poll))
+	})
+}
+
+func TestNextDigest(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{Digest: poll.RecurrenceDaily})
+
+	t.Run("next digest", func(t *testing.T) {
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 + millisPerDay })
+		defer patch.Unpatch()
+
+		assert.Equal(t, job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 1234567890+2*millisPerDay), nextDigest(p, 1234567890+millisPerDay))
+	})
+
+	t.Run("missed digests are skipped", func(t *testing.T) {
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 + 3*millisPerDay + 1 })
+		defer patch.Unpatch()
+
+		assert.Equal(t, job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 1234567890+4*millisPerDay), nextDigest(p, 1234567890+millisPerDay))
+	})
+
+	t.Run("poll ends before the next digest", func(t *testing.T) {
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 + millisPerDay })
+		defer patch.Unpatch()
+
+		endingPoll := testutils.GetPollWithSettings(poll.Settings{Digest: poll.RecurrenceDaily, EndAt: 1234567890 + 2*millisPerDay})
+		assert.Nil(t, nextDigest(endingPoll, 1234567890+millisPerDay))
+	})
+}
+
+func TestSendDigest(t *testing.T) {
+	runningPoll := func() *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Digest: poll.RecurrenceDaily})
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	endedPoll := runningPoll()
+	endedPoll.EndedAt = 1234567890
+
+	digestJob := job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 1234567890+millisPerDay)
+	nextJob := job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 1234567890+2*millisPerDay)
+
+	standings := "**Participation**: 4 of 5 channel members voted (80%).\n\n" +
+		"1. Answer 1: 3 votes (75%)\n" +
+		"2. Answer 2: 1 vote (25%)\n" +
+		"3. Answer 3: 0 votes (0%)"
+
+	setupUsers := func(api *plugintest.API) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+		api.On("GetUser", testutils.GetBotUserID()).Return(&model.User{IsBot: true}, nil)
+		api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(&model.ChannelMembers{
+			{UserId: "userID1"}, {UserId: "userID2"}, {UserId: "userID3"}, {UserId: "userID4"}, {UserId: "userID5"}, {UserId: testutils.GetBotUserID()},
+		}, nil)
+		return api
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		ExpectedJob *job.Job
+		ShouldError bool
+	}{
+		"Poll in a channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Message:   "Here are the current standings of your poll [Question](" + testutils.GetSiteURL() + "/team1/pl/postID1):\n\n" + standings,
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			ExpectedJob: nextJob,
+			ShouldError: false,
+		},
+		"Poll in a direct message": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Message:   "Here are the current standings of your poll **Question**:\n\n" + standings,
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			ExpectedJob: nextJob,
+			ShouldError: false,
+		},
+		"Poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+				return store
+			},
+			ExpectedJob: nil,
+			ShouldError: false,
+		},
+		"PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			ExpectedJob: nil,
+			ShouldError: true,
+		},
+		"CreatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Message:   "Here are the current standings of your poll **Question**:\n\n" + standings,
+				}).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			ExpectedJob: nextJob,
+			ShouldError: true,
+		},
+		"GetChannelMembers fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+				api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll(), nil)
+				return store
+			},
+			ExpectedJob: nextJob,
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 + millisPerDay })
+			defer patch.Unpatch()
+
+			next, err := p.sendDigest(digestJob)
+			assert.Equal(t, test.ExpectedJob, next)
+			if test.ShouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
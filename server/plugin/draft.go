@@ -0,0 +1,268 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+const (
+	// draftCancelKeyword discards the draft at any step
+	draftCancelKeyword = "cancel"
+	// draftSkipKeyword uses the defaults for the answer options or the settings
+	draftSkipKeyword = "skip"
+)
+
+var (
+	commandHelpTextDraft = &i18n.Message{
+		ID:    "command.help.text.draft",
+		Other: "If you prefer not to type commands, send a direct message to @{{.Bot}} and it walks you through creating a poll step by step",
+	}
+
+	draftAskQuestion = &i18n.Message{
+		ID:    "draft.ask.question",
+		Other: "Let's create a poll! What's the question? You can send `cancel` at any time to stop.",
+	}
+	draftAskAnswerOptions = &i18n.Message{
+		ID:    "draft.ask.answerOptions",
+		Other: "Send the answer options, one per line, or `skip` to use \"{{.Yes}}\" and \"{{.No}}\".",
+	}
+	draftAskSettings = &i18n.Message{
+		ID:    "draft.ask.settings",
+		Other: "Send the settings of the poll, e.g. `--anonymous --progress`, or `skip` to use the defaults. `/{{.Trigger}} help` lists all settings.",
+	}
+	draftAskChannel = &i18n.Message{
+		ID:    "draft.ask.channel",
+		Other: "Pick the channel to post the poll in.",
+	}
+	draftAskChannelSelect = &i18n.Message{
+		ID:    "draft.ask.channelSelect",
+		Other: "Select a channel",
+	}
+	draftPickChannel = &i18n.Message{
+		ID:    "draft.pickChannel",
+		Other: "Please pick the channel in the menu above or send `cancel` to stop.",
+	}
+	draftCancelled = &i18n.Message{
+		ID:    "draft.cancelled",
+		Other: "The poll has been discarded. Send me a message to start over.",
+	}
+	draftPosted = &i18n.Message{
+		ID:    "draft.posted",
+		Other: "Your poll has been posted in ~{{.Channel}}.",
+	}
+
+	responseDraftExpired = &i18n.Message{
+		ID:    "response.draft.expired",
+		Other: "This poll has already been posted or discarded. Send me a message to create a new one.",
+	}
+	responseDraftChannelInvalid = &i18n.Message{
+		ID:    "response.draft.channelInvalid",
+		Other: "The poll can't be posted in this channel. Please pick a channel you are a member of.",
+	}
+)
+
+// handleDraftMessage walks a user through creating a poll in the direct message with the bot.
+// Every message continues the draft of the user, the first one starts it. Failures are only logged, because the message has already been posted.
+func (p *MatterpollPlugin) handleDraftMessage(post *model.Post) {
+	channel, appErr := p.API.GetChannel(post.ChannelId)
+	if appErr != nil {
+		p.API.LogWarn("failed to get channel of post", "error", appErr.Error())
+		return
+	}
+	// The name of a direct channel consists of the IDs of both users
+	if channel.Type != model.CHANNEL_DIRECT || !strings.Contains(channel.Name, p.botUserID) {
+		return
+	}
+
+	reply, err := p.continueDraft(post.UserId, strings.TrimSpace(post.Message))
+	if err != nil {
+		p.API.LogWarn("failed to continue poll draft", "error", err.Error())
+	}
+	reply.UserId = p.botUserID
+	reply.ChannelId = post.ChannelId
+	if _, appErr := p.API.CreatePost(reply); appErr != nil {
+		p.API.LogWarn("failed to reply to poll draft", "error", appErr.Error())
+	}
+}
+
+// continueDraft stores a given message of a user as the next step of the user's draft and returns the reply that asks for the following step.
+// Invalid messages get an error as reply and the draft stays at its step.
+func (p *MatterpollPlugin) continueDraft(userID, message string) (*model.Post, error) {
+	userLocalizer := p.getUserLocalizer(userID)
+	reply := func(msg *i18n.Message, data map[string]interface{}) *model.Post {
+		return &model.Post{Message: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{DefaultMessage: msg, TemplateData: data})}
+	}
+	invalidInput := func(err error) *model.Post {
+		return reply(commandErrorInvalidInput, map[string]interface{}{"Error": p.localizeError(userLocalizer, err)})
+	}
+
+	draft, err := p.Store.Draft().Get(userID)
+	if err != nil {
+		return reply(commandErrorGeneric, nil), errors.Wrap(err, "failed to get draft")
+	}
+	if strings.EqualFold(message, draftCancelKeyword) {
+		if draft != nil {
+			if err = p.Store.Draft().Delete(userID); err != nil {
+				return reply(commandErrorGeneric, nil), errors.Wrap(err, "failed to delete draft")
+			}
+		}
+		return reply(draftCancelled, nil), nil
+	}
+
+	var next *model.Post
+	switch {
+	case draft == nil:
+		draft = poll.NewDraft()
+		next = reply(draftAskQuestion, nil)
+	case draft.Step == poll.DraftStepQuestion:
+		if message == "" {
+			return reply(draftAskQuestion, nil), nil
+		}
+		draft.Question = message
+		draft.Step = poll.DraftStepAnswerOptions
+		publicLocalizer := p.getPublicLocalizer()
+		next = reply(draftAskAnswerOptions, map[string]interface{}{
+			"Yes": p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes),
+			"No":  p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo),
+		})
+	case draft.Step == poll.DraftStepAnswerOptions:
+		answerOptions := []string{}
+		if !strings.EqualFold(message, draftSkipKeyword) {
+			for _, o := range strings.Split(message, "\n") {
+				if o = strings.TrimSpace(o); o != "" {
+					answerOptions = append(answerOptions, o)
+				}
+			}
+			if len(answerOptions) < 2 {
+				return reply(commandErrorinvalidNumberOfOptions, nil), nil
+			}
+			if _, err = poll.NewPoll(userID, draft.Question, answerOptions, nil); err != nil {
+				return invalidInput(err), nil
+			}
+		}
+		draft.AnswerOptions = answerOptions
+		draft.Step = poll.DraftStepSettings
+		next = reply(draftAskSettings, map[string]interface{}{"Trigger": p.getConfiguration().Trigger})
+	case draft.Step == poll.DraftStepSettings:
+		settings := []string{}
+		if !strings.EqualFold(message, draftSkipKeyword) {
+			settings = utils.ParseSettings(message)
+		}
+		draft.Settings = settings
+		// The defaults of the team are only known once the channel is picked
+		configuration := p.getConfiguration()
+		var newPoll *poll.Poll
+		if newPoll, err = p.makeDraftPoll(userID, draft, configuration); err == nil {
+			err = p.checkNewPoll(newPoll, configuration)
+		}
+		if err != nil {
+			return invalidInput(err), nil
+		}
+		draft.Step = poll.DraftStepChannel
+		next = p.makeDraftChannelPost(userLocalizer)
+	default:
+		return reply(draftPickChannel, nil), nil
+	}
+
+	if err = p.Store.Draft().Save(userID, draft); err != nil {
+		return reply(commandErrorGeneric, nil), errors.Wrap(err, "failed to save draft")
+	}
+	return next, nil
+}
+
+// makeDraftChannelPost returns the post that lets the user pick the channel the drafted poll gets posted in
+func (p *MatterpollPlugin) makeDraftChannelPost(l *i18n.Localizer) *model.Post {
+	siteURL := *p.ServerConfig.ServiceSettings.SiteURL
+	post := &model.Post{}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{{
+		Text: p.LocalizeDefaultMessage(l, draftAskChannel),
+		Actions: []*model.PostAction{{
+			Name:       p.LocalizeDefaultMessage(l, draftAskChannelSelect),
+			Type:       model.POST_ACTION_TYPE_SELECT,
+			DataSource: "channels",
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/drafts/channel", siteURL, manifest.ID),
+			},
+		}},
+	}})
+	return post
+}
+
+// makeDraftPoll creates the poll of a given draft with the defaults of a given configuration
+func (p *MatterpollPlugin) makeDraftPoll(userID string, draft *poll.Draft, configuration *configuration) (*poll.Poll, error) {
+	answerOptions := draft.AnswerOptions
+	if len(answerOptions) == 0 {
+		publicLocalizer := p.getPublicLocalizer()
+		answerOptions = []string{
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultYes),
+			p.LocalizeDefaultMessage(publicLocalizer, commandDefaultNo),
+		}
+	}
+
+	return poll.NewPoll(userID, draft.Question, answerOptions, configuration.applyDefaultSettings(draft.Settings))
+}
+
+// handlePickDraftChannel posts the drafted poll of a user in the channel the user picked and replaces the channel menu with a confirmation
+func (p *MatterpollPlugin) handlePickDraftChannel(_ map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	userLocalizer := p.getUserLocalizer(request.UserId)
+	channelID, _ := request.Context["selected_option"].(string)
+
+	draft, err := p.Store.Draft().Get(request.UserId)
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get draft")
+	}
+	if draft == nil || draft.Step != poll.DraftStepChannel {
+		return responseDraftExpired, nil, nil
+	}
+
+	channel, appErr := p.API.GetChannel(channelID)
+	if appErr != nil || channel.DeleteAt != 0 {
+		return responseDraftChannelInvalid, nil, nil
+	}
+	if _, appErr = p.API.GetChannelMember(channel.Id, request.UserId); appErr != nil {
+		return responseDraftChannelInvalid, nil, nil
+	}
+
+	configuration := p.getTeamConfiguration(channel.TeamId)
+	newPoll, err := p.makeDraftPoll(request.UserId, draft, configuration)
+	var reason *i18n.Message
+	if err == nil {
+		reason, err = p.createPoll(newPoll, configuration, channel.TeamId, channel.Id, "")
+		if invalidErr, ok := err.(*invalidPollError); ok {
+			err = invalidErr.err
+		} else if err != nil {
+			return commandErrorGeneric, nil, errors.Wrap(err, "failed to create poll")
+		}
+	}
+	if err != nil {
+		p.SendEphemeralPost(request.ChannelId, request.UserId, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorInvalidInput,
+			TemplateData:   map[string]interface{}{"Error": p.localizeError(userLocalizer, err)},
+		}))
+		return nil, nil, nil
+	}
+	if reason != nil {
+		return reason, nil, nil
+	}
+
+	if err = p.Store.Draft().Delete(request.UserId); err != nil {
+		p.API.LogWarn("failed to delete draft", "error", err.Error())
+	}
+
+	update := &model.Post{
+		Message: p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: draftPosted,
+			TemplateData:   map[string]interface{}{"Channel": channel.Name},
+		}),
+	}
+	if newPoll.IsScheduled() {
+		update.Message = p.LocalizeDefaultMessage(userLocalizer, responseCreatePollScheduled)
+	}
+	return nil, update, nil
+}
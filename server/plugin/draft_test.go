@@ -0,0 +1,314 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageHasBeenPostedDraft(t *testing.T) {
+	userID := "userID1"
+	directChannel := &model.Channel{Id: "directChannelID1", Type: model.CHANNEL_DIRECT, Name: userID + "__" + testutils.GetBotUserID()}
+	reply := func(message string) *model.Post {
+		return &model.Post{UserId: testutils.GetBotUserID(), ChannelId: directChannel.Id, Message: message}
+	}
+	channelPost := func() *model.Post {
+		post := reply("")
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{{
+			Text: draftAskChannel.Other,
+			Actions: []*model.PostAction{{
+				Name:        draftAskChannelSelect.Other,
+				Type:        model.POST_ACTION_TYPE_SELECT,
+				DataSource:  "channels",
+				Integration: &model.PostActionIntegration{URL: fmt.Sprintf("%s/plugins/%s/api/v1/drafts/channel", testutils.GetSiteURL(), manifest.ID)},
+			}},
+		}})
+		return post
+	}
+	// Drafts get changed while they are continued, hence every test gets its own
+	optionsDraft := func() *poll.Draft {
+		return &poll.Draft{Step: poll.DraftStepAnswerOptions, Question: "Question"}
+	}
+	settingsDraft := func() *poll.Draft {
+		return &poll.Draft{Step: poll.DraftStepSettings, Question: "Question", AnswerOptions: []string{"Answer 1", "Answer 2"}}
+	}
+	channelDraft := func() *poll.Draft {
+		return &poll.Draft{Step: poll.DraftStepChannel, Question: "Question", AnswerOptions: []string{"Answer 1", "Answer 2"}, Settings: []string{"anonymous", "progress"}}
+	}
+
+	for name, test := range map[string]struct {
+		Channel       *model.Channel
+		Message       string
+		Draft         *poll.Draft
+		GetError      error
+		ExpectedDraft *poll.Draft
+		DeleteDraft   bool
+		ExpectedReply *model.Post
+	}{
+		"Start": {
+			Message:       "Hi",
+			ExpectedDraft: poll.NewDraft(),
+			ExpectedReply: reply(draftAskQuestion.Other),
+		},
+		"Question": {
+			Message:       " Question ",
+			Draft:         poll.NewDraft(),
+			ExpectedDraft: optionsDraft(),
+			ExpectedReply: reply("Send the answer options, one per line, or `skip` to use \"Yes\" and \"No\"."),
+		},
+		"Empty question": {
+			Message:       "",
+			Draft:         poll.NewDraft(),
+			ExpectedReply: reply(draftAskQuestion.Other),
+		},
+		"Answer options": {
+			Message:       "Answer 1\n\n Answer 2",
+			Draft:         optionsDraft(),
+			ExpectedDraft: settingsDraft(),
+			ExpectedReply: reply("Send the settings of the poll, e.g. `--anonymous --progress`, or `skip` to use the defaults. `/poll help` lists all settings."),
+		},
+		"Skip answer options": {
+			Message:       "Skip",
+			Draft:         optionsDraft(),
+			ExpectedDraft: &poll.Draft{Step: poll.DraftStepSettings, Question: "Question", AnswerOptions: []string{}},
+			ExpectedReply: reply("Send the settings of the poll, e.g. `--anonymous --progress`, or `skip` to use the defaults. `/poll help` lists all settings."),
+		},
+		"Only one answer option": {
+			Message:       "Answer 1",
+			Draft:         optionsDraft(),
+			ExpectedReply: reply(commandErrorinvalidNumberOfOptions.Other),
+		},
+		"Duplicate answer options": {
+			Message:       "Answer 1\nAnswer 1",
+			Draft:         optionsDraft(),
+			ExpectedReply: reply("Invalid input: duplicate options: Answer 1"),
+		},
+		"Settings": {
+			Message:       "--anonymous --progress",
+			Draft:         settingsDraft(),
+			ExpectedDraft: channelDraft(),
+			ExpectedReply: channelPost(),
+		},
+		"Invalid setting": {
+			Message:       "--unknownOption",
+			Draft:         settingsDraft(),
+			ExpectedReply: reply("Invalid input: Unrecognised poll setting unknownOption"),
+		},
+		"Message instead of channel": {
+			Message:       "town-square",
+			Draft:         channelDraft(),
+			ExpectedReply: reply(draftPickChannel.Other),
+		},
+		"Cancel": {
+			Message:       "cancel",
+			Draft:         settingsDraft(),
+			DeleteDraft:   true,
+			ExpectedReply: reply(draftCancelled.Other),
+		},
+		"Cancel without draft": {
+			Message:       "Cancel",
+			ExpectedReply: reply(draftCancelled.Other),
+		},
+		"DraftStore.Get fails": {
+			Message:       "Question",
+			GetError:      errors.New(""),
+			ExpectedReply: reply(commandErrorGeneric.Other),
+		},
+		"Direct channel with another user": {
+			Channel: &model.Channel{Id: directChannel.Id, Type: model.CHANNEL_DIRECT, Name: userID + "__userID2"},
+			Message: "Question",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			channel := directChannel
+			if test.Channel != nil {
+				channel = test.Channel
+			}
+
+			api := &plugintest.API{}
+			api.On("GetChannel", directChannel.Id).Return(channel, nil)
+			api.On("GetUser", userID).Return(&model.User{Username: "user1"}, nil).Maybe()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			if test.ExpectedReply != nil {
+				api.On("CreatePost", test.ExpectedReply).Return(test.ExpectedReply, nil)
+			}
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			if test.ExpectedReply != nil {
+				store.DraftStore.On("Get", userID).Return(test.Draft, test.GetError)
+			}
+			if test.ExpectedDraft != nil {
+				store.DraftStore.On("Save", userID, test.ExpectedDraft).Return(nil)
+			}
+			if test.DeleteDraft {
+				store.DraftStore.On("Delete", userID).Return(nil)
+			}
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			p.MessageHasBeenPosted(nil, &model.Post{Id: "postID1", ChannelId: directChannel.Id, UserId: userID, Message: test.Message})
+		})
+	}
+}
+
+func TestHandlePickDraftChannel(t *testing.T) {
+	userID := "userID1"
+	channel := &model.Channel{Id: "channelID1", TeamId: "teamID1", Type: model.CHANNEL_OPEN, Name: "town-square"}
+	draft := &poll.Draft{Step: poll.DraftStepChannel, Question: "Question", AnswerOptions: []string{"Answer 1", "Answer 2", "Answer 3"}}
+	posted := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID2"
+		p.ChannelID = channel.Id
+		return p
+	}
+	expectedPost := &model.Post{UserId: testutils.GetBotUserID(), ChannelId: channel.Id, Type: model.POST_DEFAULT}
+	model.ParseSlackAttachment(expectedPost, testutils.GetPoll().ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		ExpectedResponse *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", channel.Id).Return(channel, nil)
+				api.On("GetChannelMember", channel.Id, userID).Return(&model.ChannelMember{}, nil)
+				api.On("CreatePost", expectedPost).Return(&model.Post{Id: "postID2", ChannelId: channel.Id}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(draft, nil)
+				store.ChannelStore.On("IsDisabled", channel.Id).Return(false, nil)
+				store.ChannelStore.On("GetCreators", channel.Id).Return("", nil)
+				store.TeamStore.On("GetDefaults", channel.TeamId).Return(nil, nil)
+				store.PollStore.On("Save", testutils.GetPoll()).Return(nil)
+				store.PollStore.On("Save", posted(testutils.GetPoll())).Return(nil)
+				store.DraftStore.On("Delete", userID).Return(nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{Update: &model.Post{Message: "Your poll has been posted in ~town-square."}},
+		},
+		"No draft": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(nil, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseDraftExpired.Other},
+		},
+		"Draft isn't at the channel step": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(poll.NewDraft(), nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseDraftExpired.Other},
+		},
+		"Archived channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", channel.Id).Return(&model.Channel{Id: channel.Id, DeleteAt: 1234567890}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(draft, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseDraftChannelInvalid.Other},
+		},
+		"User isn't a member of the channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", channel.Id).Return(channel, nil)
+				api.On("GetChannelMember", channel.Id, userID).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(draft, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseDraftChannelInvalid.Other},
+		},
+		"Channel disabled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", channel.Id).Return(channel, nil)
+				api.On("GetChannelMember", channel.Id, userID).Return(&model.ChannelMember{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(draft, nil)
+				store.TeamStore.On("GetDefaults", channel.TeamId).Return(nil, nil)
+				store.ChannelStore.On("IsDisabled", channel.Id).Return(true, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseCreatePollChannelDisabled.Other},
+		},
+		"Channel restricted to system admins": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", channel.Id).Return(channel, nil)
+				api.On("GetChannelMember", channel.Id, userID).Return(&model.ChannelMember{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(draft, nil)
+				store.TeamStore.On("GetDefaults", channel.TeamId).Return(nil, nil)
+				store.ChannelStore.On("IsDisabled", channel.Id).Return(false, nil)
+				store.ChannelStore.On("GetCreators", channel.Id).Return(pollCreatorsSystemAdmins, nil)
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: responseCreatePollRestricted.Other},
+		},
+		"DraftStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.DraftStore.On("Get", userID).Return(nil, errors.New(""))
+				return store
+			},
+			ExpectedResponse: &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", userID).Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			patch2 := monkey.Patch(model.NewId, func() string { return testutils.GetPollID() })
+			defer patch1.Unpatch()
+			defer patch2.Unpatch()
+
+			request := &model.PostActionIntegrationRequest{
+				UserId:    userID,
+				ChannelId: "directChannelID1",
+				PostId:    "postID1",
+				Context:   map[string]interface{}{"selected_option": channel.Id},
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/drafts/channel", bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", userID)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			assert.Equal(t, test.ExpectedResponse, model.PostActionIntegrationResponseFromJson(result.Body))
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+// emailTimeout is the time after which connecting to the SMTP server is given up
+const emailTimeout = 10 * time.Second
+
+var (
+	emailSummarySubject = &i18n.Message{
+		ID:    "email.summary.subject",
+		Other: "Results of the poll: {{.Question}}",
+	}
+	emailSummaryAttachment = &i18n.Message{
+		ID:    "email.summary.attachment",
+		Other: "The votes of every answer option are attached as CSV file.",
+	}
+)
+
+// email is an email with a single attachment
+type email struct {
+	from           *mail.Address
+	to             []string
+	subject        string
+	body           string
+	attachmentName string
+	attachment     []byte
+}
+
+// sendEmailSummary emails the results of a given ended poll together with its CSV export to the creator and the further recipients
+// the poll asks for. The email is sent in the background via the SMTP server of the Mattermost configuration. Failures are only logged.
+func (p *MatterpollPlugin) sendEmailSummary(endedPoll *poll.Poll, link string) {
+	if !endedPoll.HasEmailSummary() || !p.getConfiguration().EnableEmailSummaries {
+		return
+	}
+	settings := p.ServerConfig.EmailSettings
+	if stringValue(settings.SMTPServer) == "" || stringValue(settings.FeedbackEmail) == "" {
+		p.API.LogWarn("failed to email poll results", "error", "SMTP server or notification email address not configured")
+		return
+	}
+
+	localizer := p.getPublicLocalizer()
+	recipients := append([]string{}, endedPoll.Settings.EmailRecipients...)
+	if endedPoll.Settings.EmailCreator && endedPoll.Creator != "" {
+		creator, appErr := p.API.GetUser(endedPoll.Creator)
+		if appErr != nil {
+			p.API.LogWarn("failed to get creator to email poll results", "error", appErr.Error())
+		} else if creator.Email != "" {
+			localizer = p.getUserLocalizer(creator.Id)
+			recipients = append([]string{strings.ToLower(creator.Email)}, recipients...)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	data, appErr := endedPoll.ToCSV(localizer, p.ConvertUserIDToDisplayName)
+	if appErr != nil {
+		p.API.LogWarn("failed to convert poll to CSV", "error", appErr.Error())
+		return
+	}
+	e := &email{
+		from: &mail.Address{Name: stringValue(settings.FeedbackName), Address: stringValue(settings.FeedbackEmail)},
+		to:   recipients,
+		subject: p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+			DefaultMessage: emailSummarySubject,
+			TemplateData:   map[string]interface{}{"Question": endedPoll.Question},
+		}),
+		body:           p.makeResultsMessage(endedPoll, link, localizer) + "\n\n" + p.LocalizeDefaultMessage(localizer, emailSummaryAttachment),
+		attachmentName: fmt.Sprintf("poll-%s.csv", endedPoll.ID),
+		attachment:     data,
+	}
+	go func() {
+		if err := sendEmail(settings, e); err != nil {
+			p.API.LogWarn("failed to email poll results", "error", err.Error())
+		}
+	}()
+}
+
+// bytes returns the email in the MIME format, with the body as text and the attachment as CSV file
+func (e *email) bytes() ([]byte, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+
+	header := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n",
+		e.from.String(), strings.Join(e.to, ", "), mime.QEncoding.Encode("utf-8", e.subject), time.Now().Format(time.RFC1123Z), w.Boundary())
+	b.WriteString(header)
+
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create body")
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err = qp.Write([]byte(e.body)); err != nil {
+		return nil, errors.Wrap(err, "failed to write body")
+	}
+	if err = qp.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to write body")
+	}
+
+	part, err = w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/csv; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": e.attachmentName})},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create attachment")
+	}
+	encoded := base64.StdEncoding.EncodeToString(e.attachment)
+	// Lines of emails mustn't be longer than 998 characters
+	for len(encoded) > 76 {
+		if _, err = part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return nil, errors.Wrap(err, "failed to write attachment")
+		}
+		encoded = encoded[76:]
+	}
+	if _, err = part.Write([]byte(encoded + "\r\n")); err != nil {
+		return nil, errors.Wrap(err, "failed to write attachment")
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to finish email")
+	}
+	return b.Bytes(), nil
+}
+
+// sendEmail sends an email via the SMTP server of given email settings of the Mattermost configuration
+func sendEmail(settings model.EmailSettings, e *email) error {
+	msg, err := e.bytes()
+	if err != nil {
+		return err
+	}
+
+	host := stringValue(settings.SMTPServer)
+	addr := net.JoinHostPort(host, stringValue(settings.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: host, InsecureSkipVerify: settings.SkipServerCertificateVerification != nil && *settings.SkipServerCertificateVerification}
+
+	var conn net.Conn
+	if stringValue(settings.ConnectionSecurity) == model.CONN_SECURITY_TLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: emailTimeout}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, emailTimeout)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to SMTP server")
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "failed to greet SMTP server")
+	}
+	defer c.Close()
+
+	if stringValue(settings.ConnectionSecurity) == model.CONN_SECURITY_STARTTLS {
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return errors.Wrap(err, "failed to start TLS")
+		}
+	}
+	if settings.EnableSMTPAuth != nil && *settings.EnableSMTPAuth {
+		if err = c.Auth(smtp.PlainAuth("", stringValue(settings.SMTPUsername), stringValue(settings.SMTPPassword), host)); err != nil {
+			return errors.Wrap(err, "failed to authenticate")
+		}
+	}
+
+	if err = c.Mail(e.from.Address); err != nil {
+		return errors.Wrap(err, "failed to set sender")
+	}
+	for _, to := range e.to {
+		if err = c.Rcpt(to); err != nil {
+			return errors.Wrapf(err, "failed to add recipient %s", to)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, "failed to start data")
+	}
+	if _, err = w.Write(msg); err != nil {
+		return errors.Wrap(err, "failed to write email")
+	}
+	if err = w.Close(); err != nil {
+		return errors.Wrap(err, "failed to send email")
+	}
+	return c.Quit()
+}
+
+// stringValue returns the value of an optional setting of the Mattermost configuration. It's empty if the setting isn't set.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,244 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpMessage is an email received by a fake SMTP server
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer starts a fake SMTP server that accepts a single email and sends it to the returned channel.
+// It returns the host and the port of the server.
+func startSMTPServer(t *testing.T) (string, string, chan *smtpMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	messages := make(chan *smtpMessage, 1)
+
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost")
+		msg := &smtpMessage{}
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				msg.from = strings.Trim(strings.TrimSpace(line)[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				msg.data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				messages <- msg
+				return
+			default:
+				reply("502 Unknown command")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.Nil(t, err)
+	return host, port, messages
+}
+
+// parseEmail returns the header, the body and the attachment of an email sent by sendEmail
+func parseEmail(t *testing.T, data string) (mail.Header, string, *multipart.Part, []byte) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	require.Nil(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.Nil(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	part, err := r.NextPart()
+	require.Nil(t, err)
+	body, err := ioutil.ReadAll(quotedprintable.NewReader(part))
+	require.Nil(t, err)
+
+	part, err = r.NextPart()
+	require.Nil(t, err)
+	encoded, err := ioutil.ReadAll(part)
+	require.Nil(t, err)
+	attachment, err := base64.StdEncoding.DecodeString(strings.Replace(string(encoded), "\r\n", "", -1))
+	require.Nil(t, err)
+	return msg.Header, string(body), part, attachment
+}
+
+func TestEmailBytes(t *testing.T) {
+	e := &email{
+		from:           &mail.Address{Name: "Mattermost", Address: "noreply@example.com"},
+		to:             []string{"hr@example.com", "boss@example.com"},
+		subject:        "Results of the poll: Café?",
+		body:           "Café? has ended.\n\n" + strings.Repeat("Answer 1, ", 20) + "Answer 2",
+		attachmentName: "poll-1.csv",
+		attachment:     []byte(strings.Repeat("Answer,Votes\n", 10)),
+	}
+
+	data, err := e.bytes()
+	require.Nil(t, err)
+	for _, line := range strings.Split(string(data), "\r\n") {
+		assert.True(t, len(line) <= 998)
+	}
+
+	header, body, part, attachment := parseEmail(t, string(data))
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	require.Nil(t, err)
+	assert.Equal(t, "Results of the poll: Café?", subject)
+	assert.Equal(t, `"Mattermost" <noreply@example.com>`, header.Get("From"))
+	assert.Equal(t, "hr@example.com, boss@example.com", header.Get("To"))
+	// Text parts have CRLF line breaks
+	assert.Equal(t, strings.Replace(e.body, "\n", "\r\n", -1), body)
+	assert.Equal(t, "poll-1.csv", part.FileName())
+	assert.Equal(t, e.attachment, attachment)
+}
+
+func TestSendEmail(t *testing.T) {
+	e := &email{
+		from:           &mail.Address{Address: "noreply@example.com"},
+		to:             []string{"hr@example.com", "boss@example.com"},
+		subject:        "Subject",
+		body:           "Body",
+		attachmentName: "poll-1.csv",
+		attachment:     []byte("Answer,Votes\n"),
+	}
+
+	t.Run("all fine", func(t *testing.T) {
+		host, port, messages := startSMTPServer(t)
+
+		err := sendEmail(model.EmailSettings{SMTPServer: &host, SMTPPort: &port}, e)
+		require.Nil(t, err)
+
+		msg := <-messages
+		assert.Equal(t, "noreply@example.com", msg.from)
+		assert.Equal(t, []string{"hr@example.com", "boss@example.com"}, msg.to)
+		_, body, _, _ := parseEmail(t, msg.data)
+		assert.Equal(t, "Body", body)
+	})
+	t.Run("unreachable server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		host, port, _ := net.SplitHostPort(listener.Addr().String())
+		listener.Close()
+
+		err = sendEmail(model.EmailSettings{SMTPServer: &host, SMTPPort: &port}, e)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSendEmailSummary(t *testing.T) {
+	creator := &model.User{Id: "userID1", Username: "user1", Email: "User1@example.com"}
+	feedbackName := "Mattermost"
+	feedbackEmail := "noreply@example.com"
+
+	t.Run("all fine", func(t *testing.T) {
+		host, port, messages := startSMTPServer(t)
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(creator, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		p.configuration.EnableEmailSummaries = true
+		p.ServerConfig.EmailSettings = model.EmailSettings{SMTPServer: &host, SMTPPort: &port, FeedbackName: &feedbackName, FeedbackEmail: &feedbackEmail}
+
+		endedPoll := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, EmailCreator: true, EmailRecipients: []string{"hr@example.com"}})
+		endedPoll.EndedAt = 1234567890
+		p.sendEmailSummary(endedPoll, "https://example.org/team1/pl/postID1")
+
+		select {
+		case msg := <-messages:
+			assert.Equal(t, feedbackEmail, msg.from)
+			assert.Equal(t, []string{"user1@example.com", "hr@example.com"}, msg.to)
+			header, body, part, attachment := parseEmail(t, msg.data)
+			assert.Equal(t, "Results of the poll: Question", header.Get("Subject"))
+			assert.Contains(t, body, "https://example.org/team1/pl/postID1")
+			assert.Contains(t, body, emailSummaryAttachment.Other)
+			assert.Equal(t, "poll-"+testutils.GetPollID()+".csv", part.FileName())
+			assert.Equal(t, "Answer,Votes\nAnswer 1,0\nAnswer 2,0\nAnswer 3,0\n", string(attachment))
+		case <-time.After(5 * time.Second):
+			t.Fatal("email wasn't sent")
+		}
+	})
+	t.Run("email summaries disabled", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		p.sendEmailSummary(testutils.GetPollWithSettings(poll.Settings{EmailCreator: true}), "")
+	})
+	t.Run("poll without email", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+		p.configuration.EnableEmailSummaries = true
+
+		p.sendEmailSummary(testutils.GetPoll(), "")
+	})
+	t.Run("SMTP server not configured", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		p.configuration.EnableEmailSummaries = true
+
+		p.sendEmailSummary(testutils.GetPollWithSettings(poll.Settings{EmailCreator: true}), "")
+	})
+	t.Run("GetUser fails and no further recipients", func(t *testing.T) {
+		server := "localhost"
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+		api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		p.configuration.EnableEmailSummaries = true
+		p.ServerConfig.EmailSettings = model.EmailSettings{SMTPServer: &server, FeedbackEmail: &feedbackEmail}
+
+		p.sendEmailSummary(testutils.GetPollWithSettings(poll.Settings{EmailCreator: true}), "")
+	})
+}
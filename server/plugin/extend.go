@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextExtend = &i18n.Message{
+		ID:    "command.help.text.extend",
+		Other: "To push back the deadline of a running poll, type `/{{.Trigger}} extend <poll ID> 24h`. Instead of a duration, you can give the new deadline, e.g. `2024-06-01T17:00`",
+	}
+	commandErrorExtendUsage = &i18n.Message{
+		ID:    "command.error.extend.usage",
+		Other: "Usage: `/{{.Trigger}} extend <poll ID> <duration or time>`",
+	}
+
+	extendPollMessage = &i18n.Message{
+		ID:    "extendPoll.message",
+		Other: "The deadline of [{{.Question}}]({{.Link}}) has been extended. Voting now closes at {{.EndAt}} UTC.",
+	}
+	extendPollMessageNoLink = &i18n.Message{
+		ID:    "extendPoll.messageNoLink",
+		Other: "The deadline of **{{.Question}}** has been extended. Voting now closes at {{.EndAt}} UTC.",
+	}
+
+	responseExtendPollSuccess = &i18n.Message{
+		ID:    "response.extendPoll.success",
+		Other: "Successfully extended the deadline of the poll.",
+	}
+	responseExtendPollInvalidPermission = &i18n.Message{
+		ID:    "response.extendPoll.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to extend its deadline.",
+	}
+	responseExtendPollNoDeadline = &i18n.Message{
+		ID:    "response.extendPoll.noDeadline",
+		Other: "This poll doesn't have a deadline.",
+	}
+	responseExtendPollInvalidEnd = &i18n.Message{
+		ID:    "response.extendPoll.invalidEnd",
+		Other: "The deadline must be pushed back by a duration like `24h` or to a time like `2024-06-01T17:00` in UTC that lies after the current deadline.",
+	}
+)
+
+// executeExtendCommand pushes back the deadline of the poll with the ID given in params
+func (p *MatterpollPlugin) executeExtendCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	if len(params) != 2 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorExtendUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	msg, err := p.extendPollByID(params[0], args.UserId, params[1])
+	if err != nil {
+		p.API.LogError("failed to extend poll", "err", err.Error())
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: msg,
+		TemplateData:   map[string]interface{}{"Trigger": trigger},
+	}), nil
+}
+
+// extendPollByID pushes back the deadline of a running poll on behalf of a given user and announces the new deadline in the channel of the poll.
+// The value is either a duration that gets added to the current deadline or the new deadline itself.
+func (p *MatterpollPlugin) extendPollByID(pollID, userID, value string) (*i18n.Message, error) {
+	runningPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(runningPoll, userID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseExtendPollInvalidPermission, nil
+	}
+	if runningPoll.IsDeleted() {
+		return responseDeletePollAlreadyDeleted, nil
+	}
+	if runningPoll.IsScheduled() {
+		return commandErrorNotPosted, nil
+	}
+	if runningPoll.IsEnded() {
+		return commandErrorEndAlreadyEnded, nil
+	}
+	if !runningPoll.HasDeadline() {
+		return responseExtendPollNoDeadline, nil
+	}
+
+	// Durations are relative to the current deadline
+	endAt, ok := poll.ParseTime(value, runningPoll.Settings.EndAt)
+	if !ok || endAt <= runningPoll.Settings.EndAt || endAt <= model.GetMillis() {
+		return responseExtendPollInvalidEnd, nil
+	}
+
+	extendedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if latest.IsEnded() {
+			return errors.New("poll has already ended")
+		}
+		latest.Settings.EndAt = endAt
+		return nil
+	})
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to extend poll")
+	}
+	p.publishPollEvent(websocketEventPollUpdated, extendedPoll)
+	endAtText := time.Unix(0, endAt*int64(time.Millisecond)).UTC().Format(poll.TimeLayout)
+	p.recordAudit(audit.ActionDeadlineExtended, extendedPoll, userID, endAtText)
+
+	// The jobs of the previous deadline must not end the poll or remind the channel too early
+	if err := p.unscheduleEnd(runningPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll end", "error", err.Error())
+	}
+	if err := p.scheduleEnd(extendedPoll); err != nil {
+		p.API.LogWarn("failed to schedule poll end", "error", err.Error())
+	}
+	// The digests stopped before the previous deadline
+	if err := p.scheduleDigest(extendedPoll); err != nil {
+		p.API.LogWarn("failed to schedule poll digest", "error", err.Error())
+	}
+
+	if appErr := p.updatePollPost(extendedPoll); appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to update poll post")
+	}
+	if appErr := p.postExtendPollNotice(extendedPoll, endAtText); appErr != nil {
+		p.API.LogWarn("failed to post deadline extension", "error", appErr.Error())
+	}
+	return responseExtendPollSuccess, nil
+}
+
+// postExtendPollNotice tells the channel of a given poll that its deadline got pushed back. It replies to the thread the poll was posted in.
+func (p *MatterpollPlugin) postExtendPollNotice(extendedPoll *poll.Poll, endAt string) *model.AppError {
+	link, appErr := p.getPollPermalink(extendedPoll)
+	if appErr != nil {
+		return appErr
+	}
+
+	data := map[string]interface{}{
+		"Question": extendedPoll.Question,
+		"EndAt":    endAt,
+	}
+	message := extendPollMessageNoLink
+	if link != "" {
+		data["Link"] = link
+		message = extendPollMessage
+	}
+
+	_, appErr = p.API.CreatePost(&model.Post{
+		UserId:    p.botUserID,
+		ChannelId: extendedPoll.ChannelID,
+		RootId:    extendedPoll.RootID,
+		Message: p.LocalizeWithConfig(p.getPublicLocalizer(), &i18n.LocalizeConfig{
+			DefaultMessage: message,
+			TemplateData:   data,
+		}),
+	})
+	return appErr
+}
@@ -0,0 +1,218 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExtendPollByID(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	endAt := now + 1000
+	extendedEndAt := endAt + int64(24*time.Hour/time.Millisecond)
+	runningPoll := func(settings poll.Settings) *poll.Poll {
+		p := testutils.GetPollWithSettings(settings)
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	setupJobs := func(store *mockstore.Store, newEndAt int64) *mockstore.Store {
+		store.JobStore.On("Delete", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), endAt)).Return(nil)
+		store.JobStore.On("Delete", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 0)).Return(nil)
+		store.JobStore.On("Save", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), newEndAt)).Return(nil)
+		store.JobStore.On("Save", mock.MatchedBy(func(j *job.Job) bool {
+			return j.Type == job.TypeRefreshCountdown
+		})).Return(nil)
+		return store
+	}
+
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		UserID          string
+		Value           string
+		SetupAPI        func(*plugintest.API) *plugintest.API
+		SetupStore      func(*mockstore.Store, *poll.Poll) *mockstore.Store
+		ExpectedMessage string
+		ExpectedEndAt   int64
+		ShouldError     bool
+	}{
+		"all fine, duration": {
+			Poll:   runningPoll(poll.Settings{EndAt: endAt}),
+			UserID: "userID1",
+			Value:  "24h",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.GetProp("attachments") != nil
+				})).Return(nil, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					Message:   "The deadline of [Question](" + testutils.GetSiteURL() + "/team1/pl/postID1) has been extended. Voting now closes at 1970-01-16T06:56 UTC.",
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, extendedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(extendedPoll))
+				return setupJobs(store, extendedEndAt)
+			},
+			ExpectedMessage: responseExtendPollSuccess.Other,
+			ExpectedEndAt:   extendedEndAt,
+		},
+		"all fine, time in a direct message": {
+			Poll:   runningPoll(poll.Settings{EndAt: endAt}),
+			UserID: "userID1",
+			Value:  "1970-01-17T12:00",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					Message:   "The deadline of **Question** has been extended. Voting now closes at 1970-01-17T12:00 UTC.",
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, extendedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(extendedPoll))
+				return setupJobs(store, 1425600000)
+			},
+			ExpectedMessage: responseExtendPollSuccess.Other,
+			ExpectedEndAt:   1425600000,
+		},
+		"poll without deadline": {
+			Poll:            runningPoll(poll.Settings{}),
+			UserID:          "userID1",
+			Value:           "24h",
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseExtendPollNoDeadline.Other,
+		},
+		"poll has ended": {
+			Poll: func() *poll.Poll {
+				p := runningPoll(poll.Settings{EndAt: endAt})
+				p.EndedAt = now
+				return p
+			}(),
+			UserID:          "userID1",
+			Value:           "24h",
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: commandErrorEndAlreadyEnded.Other,
+			ExpectedEndAt:   endAt,
+		},
+		"poll has been deleted": {
+			Poll: func() *poll.Poll {
+				p := runningPoll(poll.Settings{EndAt: endAt})
+				p.DeletedAt = now
+				return p
+			}(),
+			UserID:          "userID1",
+			Value:           "24h",
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDeletePollAlreadyDeleted.Other,
+			ExpectedEndAt:   endAt,
+		},
+		"invalid value": {
+			Poll:            runningPoll(poll.Settings{EndAt: endAt}),
+			UserID:          "userID1",
+			Value:           "tomorrow",
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseExtendPollInvalidEnd.Other,
+			ExpectedEndAt:   endAt,
+		},
+		"deadline would be brought forward": {
+			Poll:            runningPoll(poll.Settings{EndAt: endAt}),
+			UserID:          "userID1",
+			Value:           "-1m",
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseExtendPollInvalidEnd.Other,
+			ExpectedEndAt:   endAt,
+		},
+		"invalid permission": {
+			Poll:   runningPoll(poll.Settings{EndAt: endAt}),
+			UserID: "userID2",
+			Value:  "24h",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseExtendPollInvalidPermission.Other,
+			ExpectedEndAt:   endAt,
+		},
+		"Store.Update fails": {
+			Poll:     runningPoll(poll.Settings{EndAt: endAt}),
+			UserID:   "userID1",
+			Value:    "24h",
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			ExpectedMessage: commandErrorGeneric.Other,
+			ExpectedEndAt:   endAt,
+			ShouldError:     true,
+		},
+		"CreatePost fails": {
+			Poll:   runningPoll(poll.Settings{EndAt: endAt}),
+			UserID: "userID1",
+			Value:  "24h",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, extendedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(extendedPoll))
+				return setupJobs(store, extendedEndAt)
+			},
+			ExpectedMessage: responseExtendPollSuccess.Other,
+			ExpectedEndAt:   extendedEndAt,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
+			api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return().Maybe()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll.Copy(), nil)
+			store = test.SetupStore(store, test.Poll)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, err := p.extendPollByID(testutils.GetPollID(), test.UserID, test.Value)
+			assert.Equal(t, test.ExpectedMessage, msg.Other)
+			assert.Equal(t, test.ExpectedEndAt, test.Poll.Settings.EndAt)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
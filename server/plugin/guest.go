@@ -0,0 +1,29 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// guestRoleID is the system role of guest accounts. The Mattermost version Matterpoll builds against doesn't define it yet.
+const guestRoleID = "system_guest"
+
+var responseVoteGuest = &i18n.Message{
+	ID:    "response.vote.guest",
+	Other: "Guest accounts aren't allowed to vote in this poll.",
+}
+
+// excludesGuests returns true if guest accounts may not vote in a given poll, either because of its settings or because the configuration excludes them from all polls
+func (p *MatterpollPlugin) excludesGuests(votedPoll *poll.Poll) bool {
+	return votedPoll.Settings.NoGuests || p.getConfiguration().ExcludeGuests
+}
+
+// isGuest checks if a given user is a guest account
+func (p *MatterpollPlugin) isGuest(userID string) (bool, *model.AppError) {
+	user, appErr := p.API.GetUser(userID)
+	if appErr != nil {
+		return false, appErr
+	}
+	return user.IsInRole(guestRoleID), nil
+}
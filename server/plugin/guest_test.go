@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckVoterGuests(t *testing.T) {
+	guest := &model.User{Id: "userID2", Roles: guestRoleID}
+	user := &model.User{Id: "userID2", Roles: model.SYSTEM_USER_ROLE_ID}
+
+	for name, test := range map[string]struct {
+		Settings      poll.Settings
+		ExcludeGuests bool
+		SetupAPI      func(*plugintest.API) *plugintest.API
+		Expected      *i18n.Message
+		ShouldError   bool
+	}{
+		"guests aren't excluded": {
+			Settings: poll.Settings{},
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			Expected: nil,
+		},
+		"poll excludes guests, guest": {
+			Settings: poll.Settings{NoGuests: true},
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(guest, nil)
+				return api
+			},
+			Expected: responseVoteGuest,
+		},
+		"poll excludes guests, user": {
+			Settings: poll.Settings{NoGuests: true},
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(user, nil)
+				return api
+			},
+			Expected: nil,
+		},
+		"configuration excludes guests, guest": {
+			Settings:      poll.Settings{},
+			ExcludeGuests: true,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(guest, nil)
+				return api
+			},
+			Expected: responseVoteGuest,
+		},
+		"guest check comes before members-only": {
+			Settings: poll.Settings{NoGuests: true, MembersOnly: true},
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(guest, nil)
+				return api
+			},
+			Expected: responseVoteGuest,
+		},
+		"user isn't a member": {
+			Settings: poll.Settings{NoGuests: true, MembersOnly: true},
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(user, nil)
+				api.On("GetChannelMember", "channelID1", "userID2").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+				return api
+			},
+			Expected: responseVoteNotMember,
+		},
+		"GetUser fails": {
+			Settings: poll.Settings{NoGuests: true},
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				return api
+			},
+			Expected:    nil,
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+			p.setConfiguration(&configuration{ExcludeGuests: test.ExcludeGuests})
+
+			votedPoll := testutils.GetPollWithSettings(test.Settings)
+			votedPoll.ChannelID = "channelID1"
+			rejection, err := p.checkVoter(votedPoll, "userID2")
+			assert.Equal(t, test.Expected, rejection)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestGetEligibleVotersExcludesGuests(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetChannelMembers", "channelID1", 0, channelMembersPerPage).Return(&model.ChannelMembers{{UserId: "userID1"}, {UserId: "userID2"}}, nil)
+	api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+	api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Roles: guestRoleID}, nil)
+	defer api.AssertExpectations(t)
+	p := setupTestPlugin(t, api, &mockstore.Store{})
+
+	eligibleVoters, appErr := p.getEligibleVoters("channelID1", true)
+	assert.Nil(t, appErr)
+	assert.Equal(t, []string{"userID1"}, eligibleVoters)
+
+	eligibleVoters, appErr = p.getEligibleVoters("channelID1", false)
+	assert.Nil(t, appErr)
+	assert.Equal(t, []string{"userID1", "userID2"}, eligibleVoters)
+}
@@ -0,0 +1,176 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	commandHelpTextAdminDiagnose = &i18n.Message{
+		ID:    "command.help.text.admin.diagnose",
+		Other: "System admins can check the plugin for misconfiguration and failures by typing `/{{.Trigger}} admin diagnose`",
+	}
+
+	adminDiagnoseNone = &i18n.Message{
+		ID:    "admin.diagnose.none",
+		Other: "No problems found. The store, the scheduler and the bot account work as expected.",
+	}
+	adminDiagnoseHeading = &i18n.Message{
+		ID:    "admin.diagnose.heading",
+		One:   "Found {{.Count}} problem:",
+		Other: "Found {{.Count}} problems:",
+	}
+	adminDiagnoseSiteURL = &i18n.Message{
+		ID:    "admin.diagnose.siteURL",
+		Other: "- The Site URL isn't set in the System Console. Poll buttons, links and images don't work without it.",
+	}
+	adminDiagnoseStore = &i18n.Message{
+		ID:    "admin.diagnose.store",
+		Other: "- The store can't be accessed: {{.Error}}",
+	}
+	adminDiagnoseJobs = &i18n.Message{
+		ID:    "admin.diagnose.jobs",
+		Other: "- The scheduled jobs can't be loaded: {{.Error}}",
+	}
+	adminDiagnoseSchedulerStopped = &i18n.Message{
+		ID:    "admin.diagnose.schedulerStopped",
+		Other: "- The scheduler isn't running, hence deadlines and scheduled polls aren't processed. Restart the plugin to start it again.",
+	}
+	adminDiagnoseOverdueJobs = &i18n.Message{
+		ID:    "admin.diagnose.overdueJobs",
+		One:   "- {{.Count}} scheduled job is overdue. Check the server log for jobs that fail or a plugin instance that can't lead the scheduler.",
+		Other: "- {{.Count}} scheduled jobs are overdue. Check the server log for jobs that fail or a plugin instance that can't lead the scheduler.",
+	}
+	adminDiagnoseBot = &i18n.Message{
+		ID:    "admin.diagnose.bot",
+		Other: "- The bot account @{{.Username}} can't be used: {{.Error}}. Restart the plugin to restore it.",
+	}
+)
+
+// healthResponse is the JSON body of the health route
+type healthResponse struct {
+	Healthy   bool             `json:"healthy"`
+	Activated bool             `json:"activated"`
+	Store     *componentHealth `json:"store"`
+	Scheduler *schedulerHealth `json:"scheduler"`
+	Bot       *componentHealth `json:"bot"`
+}
+
+// componentHealth tells if a part of the plugin works. Error describes why it doesn't.
+type componentHealth struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// schedulerHealth tells if the scheduler runs the jobs in time.
+// Jobs that have been due for longer than the scheduler lease count as overdue, as the leader should have run them by then.
+type schedulerHealth struct {
+	OK          bool   `json:"ok"`
+	Error       string `json:"error,omitempty"`
+	Running     bool   `json:"running"`
+	Jobs        int    `json:"jobs"`
+	OverdueJobs int    `json:"overdue_jobs"`
+}
+
+// handleHealth writes the health of the plugin. The status code is 503 if any part of the plugin doesn't work.
+func (p *MatterpollPlugin) handleHealth(w http.ResponseWriter, r *http.Request) {
+	health := p.checkHealth()
+
+	b, _ := json.Marshal(health)
+	w.Header().Set("Content-Type", "application/json")
+	if !health.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write healthResponse", "error", err.Error())
+	}
+}
+
+// checkHealth checks that the plugin is activated, the store can be accessed, the scheduler runs the jobs in time and the bot account can be used
+func (p *MatterpollPlugin) checkHealth() *healthResponse {
+	health := &healthResponse{
+		Activated: p.isActivated(),
+		Store:     &componentHealth{OK: true},
+		Scheduler: &schedulerHealth{OK: true, Running: p.schedulerStop != nil},
+		Bot:       &componentHealth{OK: true},
+	}
+
+	// Reading the schema version is the cheapest round trip to the store
+	if _, err := p.Store.System().GetVersion(); err != nil {
+		health.Store = &componentHealth{Error: err.Error()}
+	}
+
+	jobs, err := p.Store.Job().List()
+	if err != nil {
+		health.Scheduler.Error = err.Error()
+	}
+	now := model.GetMillis()
+	for _, j := range jobs {
+		if j.IsDue(now-int64(schedulerLease/time.Millisecond)) && !j.IsClaimed(now) {
+			health.Scheduler.OverdueJobs++
+		}
+	}
+	health.Scheduler.Jobs = len(jobs)
+	health.Scheduler.OK = err == nil && health.Scheduler.Running && health.Scheduler.OverdueJobs == 0
+
+	// Deactivated bots aren't returned
+	if _, appErr := p.API.GetBot(p.botUserID, false); appErr != nil {
+		health.Bot = &componentHealth{Error: appErr.Error()}
+	}
+
+	health.Healthy = health.Activated && health.Store.OK && health.Scheduler.OK && health.Bot.OK
+	return health
+}
+
+// executeAdminDiagnoseCommand returns a message that lists the misconfiguration and failures of the plugin
+func (p *MatterpollPlugin) executeAdminDiagnoseCommand(userLocalizer *i18n.Localizer) string {
+	health := p.checkHealth()
+
+	problems := []string{}
+	if siteURL := p.ServerConfig.ServiceSettings.SiteURL; siteURL == nil || *siteURL == "" {
+		problems = append(problems, p.LocalizeDefaultMessage(userLocalizer, adminDiagnoseSiteURL))
+	}
+	if !health.Store.OK {
+		problems = append(problems, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminDiagnoseStore,
+			TemplateData:   map[string]interface{}{"Error": health.Store.Error},
+		}))
+	}
+	if health.Scheduler.Error != "" {
+		problems = append(problems, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminDiagnoseJobs,
+			TemplateData:   map[string]interface{}{"Error": health.Scheduler.Error},
+		}))
+	}
+	if !health.Scheduler.Running {
+		problems = append(problems, p.LocalizeDefaultMessage(userLocalizer, adminDiagnoseSchedulerStopped))
+	}
+	if health.Scheduler.OverdueJobs > 0 {
+		problems = append(problems, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminDiagnoseOverdueJobs,
+			TemplateData:   map[string]interface{}{"Count": health.Scheduler.OverdueJobs},
+			PluralCount:    health.Scheduler.OverdueJobs,
+		}))
+	}
+	if !health.Bot.OK {
+		problems = append(problems, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminDiagnoseBot,
+			TemplateData:   map[string]interface{}{"Username": botUserName, "Error": health.Bot.Error},
+		}))
+	}
+
+	if len(problems) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, adminDiagnoseNone)
+	}
+	heading := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: adminDiagnoseHeading,
+		TemplateData:   map[string]interface{}{"Count": len(problems)},
+		PluralCount:    len(problems),
+	})
+	return heading + "\n" + strings.Join(problems, "\n")
+}
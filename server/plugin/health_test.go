@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHealth(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	systemAdmin := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	jobs := []*job.Job{
+		job.NewJob(job.TypeEndPoll, "pollID1", now+1000),
+		job.NewJob(job.TypeArchivePolls, "", now-1000),
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		UserID             string
+		SchedulerRunning   bool
+		ExpectedStatusCode int
+		ExpectedResponse   *healthResponse
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetBot", testutils.GetBotUserID(), false).Return(&model.Bot{UserId: testutils.GetBotUserID()}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.SystemStore.On("GetVersion").Return("1.3.0", nil)
+				store.JobStore.On("List").Return(jobs, nil)
+				return store
+			},
+			UserID:             "userID1",
+			SchedulerRunning:   true,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &healthResponse{
+				Healthy:   true,
+				Activated: true,
+				Store:     &componentHealth{OK: true},
+				Scheduler: &schedulerHealth{OK: true, Running: true, Jobs: 2},
+				Bot:       &componentHealth{OK: true},
+			},
+		},
+		"everything broken": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetBot", testutils.GetBotUserID(), false).Return(nil, &model.AppError{Where: "GetBot", Message: "bot not found", DetailedError: "deactivated"})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.SystemStore.On("GetVersion").Return("", errors.New("KV store unavailable"))
+				store.JobStore.On("List").Return(nil, errors.New("KV store unavailable"))
+				return store
+			},
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+			ExpectedResponse: &healthResponse{
+				Activated: true,
+				Store:     &componentHealth{Error: "KV store unavailable"},
+				Scheduler: &schedulerHealth{Error: "KV store unavailable"},
+				Bot:       &componentHealth{Error: "GetBot: bot not found, deactivated"},
+			},
+		},
+		"overdue job": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetBot", testutils.GetBotUserID(), false).Return(&model.Bot{UserId: testutils.GetBotUserID()}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.SystemStore.On("GetVersion").Return("1.3.0", nil)
+				store.JobStore.On("List").Return(append(jobs, job.NewJob(job.TypeEndPoll, "pollID2", now-int64(schedulerLease/time.Millisecond)-1)), nil)
+				return store
+			},
+			UserID:             "userID1",
+			SchedulerRunning:   true,
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+			ExpectedResponse: &healthResponse{
+				Activated: true,
+				Store:     &componentHealth{OK: true},
+				Scheduler: &schedulerHealth{Running: true, Jobs: 3, OverdueJobs: 1},
+				Bot:       &componentHealth{OK: true},
+			},
+		},
+		"not a system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "userID2",
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			if test.SchedulerRunning {
+				p.schedulerStop = make(chan struct{})
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+			r.Header.Set("Mattermost-User-ID", test.UserID)
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			if test.ExpectedResponse == nil {
+				return
+			}
+			var response *healthResponse
+			require.Nil(t, json.NewDecoder(result.Body).Decode(&response))
+			assert.Equal(t, test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestExecuteAdminDiagnoseCommand(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store) *mockstore.Store
+		SiteURL          string
+		SchedulerRunning bool
+		ExpectedText     string
+	}{
+		"no problems": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetBot", testutils.GetBotUserID(), false).Return(&model.Bot{UserId: testutils.GetBotUserID()}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.SystemStore.On("GetVersion").Return("1.3.0", nil)
+				store.JobStore.On("List").Return([]*job.Job{}, nil)
+				return store
+			},
+			SiteURL:          testutils.GetSiteURL(),
+			SchedulerRunning: true,
+			ExpectedText:     adminDiagnoseNone.Other,
+		},
+		"all problems": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetBot", testutils.GetBotUserID(), false).Return(nil, &model.AppError{Where: "GetBot", Message: "bot not found", DetailedError: "deactivated"})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.SystemStore.On("GetVersion").Return("", errors.New("KV store unavailable"))
+				store.JobStore.On("List").Return(nil, errors.New("KV store unavailable"))
+				return store
+			},
+			ExpectedText: "Found 5 problems:\n" +
+				"- The Site URL isn't set in the System Console. Poll buttons, links and images don't work without it.\n" +
+				"- The store can't be accessed: KV store unavailable\n" +
+				"- The scheduled jobs can't be loaded: KV store unavailable\n" +
+				"- The scheduler isn't running, hence deadlines and scheduled polls aren't processed. Restart the plugin to start it again.\n" +
+				"- The bot account @matterpoll can't be used: GetBot: bot not found, deactivated. Restart the plugin to restore it.",
+		},
+		"overdue job": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetBot", testutils.GetBotUserID(), false).Return(&model.Bot{UserId: testutils.GetBotUserID()}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.SystemStore.On("GetVersion").Return("1.3.0", nil)
+				store.JobStore.On("List").Return([]*job.Job{job.NewJob(job.TypeEndPoll, "pollID1", 1)}, nil)
+				return store
+			},
+			SiteURL:          testutils.GetSiteURL(),
+			SchedulerRunning: true,
+			ExpectedText: "Found 1 problem:\n" +
+				"- 1 scheduled job is overdue. Check the server log for jobs that fail or a plugin instance that can't lead the scheduler.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.ServerConfig.ServiceSettings.SiteURL = &test.SiteURL
+			if test.SchedulerRunning {
+				p.schedulerStop = make(chan struct{})
+			}
+
+			text := p.executeAdminDiagnoseCommand(testutils.GetLocalizer())
+			assert.Equal(t, test.ExpectedText, text)
+		})
+	}
+}
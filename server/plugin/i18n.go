@@ -5,12 +5,20 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/mattermost/mattermost-server/model"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
 	"golang.org/x/text/language"
 )
 
+// userTimeLayout is the layout of times shown to a single user. Translations can adapt it to the conventions of their locale.
+var userTimeLayout = &i18n.Message{
+	ID:    "time.userLayout",
+	Other: "2006-01-02 15:04 MST",
+}
+
 // initBundle loads all localization files in i18n into a bundle and return this
 func (p *MatterpollPlugin) initBundle() (*i18n.Bundle, error) {
 	bundle := i18n.NewBundle(language.English)
@@ -52,9 +60,27 @@ func (p *MatterpollPlugin) getUserLocalizer(userID string) *i18n.Localizer {
 		return p.getServerLocalizer()
 	}
 
+	return p.getLocalizerForUser(user)
+}
+
+// getLocalizerForUser returns a localizer that localizes in the locale of a given user.
+// Users without a locale get the server default locale.
+func (p *MatterpollPlugin) getLocalizerForUser(user *model.User) *i18n.Localizer {
+	if user.Locale == "" {
+		return p.getServerLocalizer()
+	}
 	return i18n.NewLocalizer(p.bundle, user.Locale)
 }
 
+// getPublicLocalizer returns a localizer for messages that everybody in a channel sees, e.g. poll posts.
+// It localizes in the configured poll language or in the server default locale if none is configured.
+func (p *MatterpollPlugin) getPublicLocalizer() *i18n.Localizer {
+	if pollLanguage := p.getConfiguration().PollLanguage; pollLanguage != "" {
+		return i18n.NewLocalizer(p.bundle, pollLanguage)
+	}
+	return p.getServerLocalizer()
+}
+
 // getServerLocalizer returns a localizer that localizes in the server default locale
 func (p *MatterpollPlugin) getServerLocalizer() *i18n.Localizer {
 	return i18n.NewLocalizer(p.bundle, *p.ServerConfig.LocalizationSettings.DefaultServerLocale)
@@ -79,3 +105,27 @@ func (p *MatterpollPlugin) LocalizeWithConfig(l *i18n.Localizer, lc *i18n.Locali
 	}
 	return s
 }
+
+// localizeError returns the message of a given error. Limit errors are localized, all other errors are returned as they are.
+func (p *MatterpollPlugin) localizeError(l *i18n.Localizer, err error) string {
+	limitErr, ok := err.(*limitError)
+	if !ok {
+		return err.Error()
+	}
+	return p.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+		DefaultMessage: limitErr.message,
+		TemplateData:   map[string]interface{}{"Limit": limitErr.limit},
+	})
+}
+
+// formatTimeForUser returns a time in milliseconds in the timezone of a given user, using the time layout of the given localizer.
+// If the user or their timezone can't be found, the time is returned in UTC.
+func (p *MatterpollPlugin) formatTimeForUser(userID string, l *i18n.Localizer, millis int64) string {
+	location := time.UTC
+	if user, appErr := p.API.GetUser(userID); appErr == nil {
+		if userLocation, err := time.LoadLocation(model.GetPreferredTimezone(user.Timezone)); err == nil {
+			location = userLocation
+		}
+	}
+	return time.Unix(0, millis*int64(time.Millisecond)).In(location).Format(p.LocalizeDefaultMessage(l, userTimeLayout))
+}
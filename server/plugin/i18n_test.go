@@ -1,11 +1,15 @@
 package plugin
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
 
+	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
 )
@@ -74,3 +78,70 @@ func TestLocalizeWithConfig(t *testing.T) {
 		assert.Equal(t, "", p.LocalizeWithConfig(l, lc))
 	})
 }
+
+func TestLocalizeError(t *testing.T) {
+	t.Run("limit error", func(t *testing.T) {
+		api := &plugintest.API{}
+
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		err := &limitError{message: limitErrorNumberOfAnswerOptions, limit: 5}
+
+		assert.Equal(t, "Polls can't have more than 5 answer options", p.localizeError(p.getServerLocalizer(), err))
+		assert.Equal(t, "Polls can't have more than 5 answer options", err.Error())
+	})
+	t.Run("other error", func(t *testing.T) {
+		api := &plugintest.API{}
+
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Equal(t, "some error", p.localizeError(p.getServerLocalizer(), errors.New("some error")))
+	})
+}
+
+func TestGetLocalizers(t *testing.T) {
+	message := &i18n.Message{ID: "test.message", Other: "test message"}
+	setupPlugin := func(api *plugintest.API) *MatterpollPlugin {
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+		require.Nil(t, p.bundle.AddMessages(language.German, &i18n.Message{ID: "test.message", Other: "Testnachricht"}))
+		return p
+	}
+
+	t.Run("user localizer", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Locale: "de"}, nil)
+		defer api.AssertExpectations(t)
+		p := setupPlugin(api)
+
+		assert.Equal(t, "Testnachricht", p.LocalizeDefaultMessage(p.getUserLocalizer("userID1"), message))
+	})
+	t.Run("user localizer, user without locale", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{}, nil)
+		defer api.AssertExpectations(t)
+		p := setupPlugin(api)
+		p.ServerConfig.LocalizationSettings.DefaultServerLocale = model.NewString("de")
+
+		assert.Equal(t, "Testnachricht", p.LocalizeDefaultMessage(p.getUserLocalizer("userID1"), message))
+	})
+	t.Run("user localizer, GetUser fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+		api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		p := setupPlugin(api)
+
+		assert.Equal(t, "test message", p.LocalizeDefaultMessage(p.getUserLocalizer("userID1"), message))
+	})
+	t.Run("public localizer, server default locale", func(t *testing.T) {
+		p := setupPlugin(&plugintest.API{})
+
+		assert.Equal(t, "test message", p.LocalizeDefaultMessage(p.getPublicLocalizer(), message))
+	})
+	t.Run("public localizer, poll language", func(t *testing.T) {
+		p := setupPlugin(&plugintest.API{})
+		p.setConfiguration(&configuration{PollLanguage: "de"})
+
+		assert.Equal(t, "Testnachricht", p.LocalizeDefaultMessage(p.getPublicLocalizer(), message))
+		assert.Equal(t, "test message", p.LocalizeDefaultMessage(p.getServerLocalizer(), message))
+	})
+}
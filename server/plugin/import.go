@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/importer"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/pkg/errors"
+)
+
+// importResponse is the response to an import. It counts the imported data.
+type importResponse struct {
+	Polls int `json:"polls"`
+	Votes int `json:"votes"`
+	// UnmatchedVoters counts the voters of the export that have no Mattermost account with the same user name or email address
+	UnmatchedVoters int `json:"unmatched_voters"`
+}
+
+// handleImport recreates the polls of an export of a Slack poll tool, sent as request body, as ended polls in a given channel.
+// Polls are posted in the order of the export, so the ones before a failure stay imported.
+func (p *MatterpollPlugin) handleImport(w http.ResponseWriter, r *http.Request) {
+	polls, err := importer.Parse(r.URL.Query().Get("format"), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	channel, appErr := p.API.GetChannel(r.URL.Query().Get("channel_id"))
+	if appErr != nil {
+		http.Error(w, "invalid channel_id", http.StatusBadRequest)
+		return
+	}
+
+	response := importResponse{}
+	// voters maps the voters of the export to their user IDs. Voters without account map to an empty string.
+	voters := map[string]string{}
+	for _, imported := range polls {
+		votes, err := p.importPoll(imported, r.Header.Get("Mattermost-User-ID"), channel.Id, voters)
+		if err != nil {
+			p.API.LogWarn("failed to import poll", "question", imported.Question, "error", err.Error())
+			http.Error(w, fmt.Sprintf("failed to import poll %q after %d polls", imported.Question, response.Polls), http.StatusInternalServerError)
+			return
+		}
+		response.Polls++
+		response.Votes += votes
+	}
+	for _, userID := range voters {
+		if userID == "" {
+			response.UnmatchedVoters++
+		}
+	}
+
+	b, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write importResponse", "error", err.Error())
+	}
+}
+
+// importPoll posts an imported poll as ended poll of a given creator in a given channel and returns its number of votes.
+// Votes of voters without account and votes the export doesn't list voters for can't be attributed to users,
+// so they are kept as anonymous votes and the poll becomes anonymous.
+func (p *MatterpollPlugin) importPoll(imported *importer.Poll, creatorID, channelID string, voters map[string]string) (int, error) {
+	answerOptions := []string{}
+	for _, o := range imported.AnswerOptions {
+		answerOptions = append(answerOptions, o.Answer)
+	}
+	settings := []string{}
+	if max := imported.MaxVotesPerVoter(); max > 1 {
+		settings = append(settings, fmt.Sprintf("votes=%d", max))
+	}
+	newPoll, err := poll.NewPoll(creatorID, imported.Question, answerOptions, settings)
+	if err != nil {
+		return 0, err
+	}
+
+	votes := 0
+	// Voters without account vote under the same placeholder for all answer options
+	placeholders := map[string]string{}
+	for i, o := range imported.AnswerOptions {
+		for _, voter := range o.Voters {
+			userID := p.lookupImportedVoter(voter, voters)
+			if userID == "" {
+				if placeholders[voter] == "" {
+					placeholders[voter] = model.NewId()
+				}
+				userID = placeholders[voter]
+			}
+			newPoll.AnswerOptions[i].Voter = append(newPoll.AnswerOptions[i].Voter, userID)
+		}
+		for j := 0; j < o.AnonymousVotes; j++ {
+			newPoll.AnswerOptions[i].Voter = append(newPoll.AnswerOptions[i].Voter, model.NewId())
+		}
+		if len(placeholders) > 0 || o.AnonymousVotes > 0 {
+			newPoll.Settings.Anonymous = true
+		}
+		votes += len(newPoll.AnswerOptions[i].Voter)
+	}
+	newPoll.EndedAt = model.GetMillis()
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(newPoll.Creator)
+	if appErr != nil {
+		return 0, errors.Wrap(appErr, "failed to get display name for creator")
+	}
+	post, appErr := newPoll.ToEndPollPost(p.getPublicLocalizer(), *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName, p.ConvertUserIDToDisplayName)
+	if appErr != nil {
+		return 0, errors.Wrap(appErr, "failed to get poll post")
+	}
+	post.UserId = p.botUserID
+	post.ChannelId = channelID
+	rpost, appErr := p.API.CreatePost(post)
+	if appErr != nil {
+		return 0, errors.Wrap(appErr, "failed to post poll post")
+	}
+
+	newPoll.PostID = rpost.Id
+	newPoll.ChannelID = rpost.ChannelId
+	if err := p.Store.Poll().Save(newPoll); err != nil {
+		return 0, errors.Wrap(err, "failed to save poll")
+	}
+	p.recordAudit(audit.ActionPollCreated, newPoll, newPoll.Creator, newPoll.Question)
+	return votes, nil
+}
+
+// lookupImportedVoter returns the ID of the user with a given user name or email address and caches it in a given map.
+// It's empty if there is no such user.
+func (p *MatterpollPlugin) lookupImportedVoter(voter string, voters map[string]string) string {
+	if userID, ok := voters[voter]; ok {
+		return userID
+	}
+
+	var user *model.User
+	var appErr *model.AppError
+	if strings.Contains(voter, "@") && !strings.HasPrefix(voter, "@") {
+		user, appErr = p.API.GetUserByEmail(voter)
+	} else {
+		user, appErr = p.API.GetUserByUsername(strings.TrimPrefix(voter, "@"))
+	}
+	userID := ""
+	if appErr == nil {
+		userID = user.Id
+	}
+	voters[voter] = userID
+	return userID
+}
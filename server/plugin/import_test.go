@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleImport(t *testing.T) {
+	systemAdmin := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	pollyExport := "Question,Response,Respondent\n" +
+		"Lunch?,Pizza,user2\n" +
+		"Lunch?,Sushi,user3@example.org\n" +
+		"Lunch?,Pizza,user3@example.org\n"
+	simplePollExport := "Poll,Option,Votes,Voter\n" +
+		"Lunch?,Pizza,,unknown\n" +
+		"Lunch?,Sushi,2,\n"
+	getChannel := func(api *plugintest.API) {
+		api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+	}
+	createPost := func(api *plugintest.API) {
+		api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.UserId == testutils.GetBotUserID() && post.ChannelId == "channelID1"
+		})).Return(&model.Post{Id: "postID1", ChannelId: "channelID1"}, nil)
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Query              string
+		Body               string
+		ExpectedStatusCode int
+		ExpectedBody       string
+	}{
+		"Voters with accounts": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				getChannel(api)
+				createPost(api)
+				api.On("GetUserByUsername", "user2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetUserByEmail", "user3@example.org").Return(&model.User{Id: "userID3", Username: "user3"}, nil)
+				api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Username: "user2"}, nil)
+				api.On("GetUser", "userID3").Return(&model.User{Id: "userID3", Username: "user3"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", mock.MatchedBy(func(p *poll.Poll) bool {
+					return p.Question == "Lunch?" && p.Creator == "userID1" && p.IsEnded() && !p.Settings.Anonymous &&
+						p.Settings.MaxVotes == 2 && p.PostID == "postID1" && p.ChannelID == "channelID1" &&
+						assert.ObjectsAreEqual([]string{"userID2", "userID3"}, p.AnswerOptions[0].Voter) &&
+						assert.ObjectsAreEqual([]string{"userID3"}, p.AnswerOptions[1].Voter)
+				})).Return(nil)
+				return store
+			},
+			Query:              "?format=polly&channel_id=channelID1",
+			Body:               pollyExport,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody:       `{"polls":1,"votes":3,"unmatched_voters":0}`,
+		},
+		"Voters without accounts and votes without voters": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				getChannel(api)
+				createPost(api)
+				api.On("GetUserByUsername", "unknown").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", mock.MatchedBy(func(p *poll.Poll) bool {
+					return p.IsEnded() && p.Settings.Anonymous && len(p.AnswerOptions[0].Voter) == 1 && len(p.AnswerOptions[1].Voter) == 2
+				})).Return(nil)
+				return store
+			},
+			Query:              "?format=simplepoll&channel_id=channelID1",
+			Body:               simplePollExport,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedBody:       `{"polls":1,"votes":3,"unmatched_voters":1}`,
+		},
+		"Unknown format": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Query:              "?format=doodle&channel_id=channelID1",
+			Body:               pollyExport,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "unknown format \"doodle\". Use polly, simplepoll\n",
+		},
+		"Invalid channel": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", "channelID2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Query:              "?format=polly&channel_id=channelID2",
+			Body:               pollyExport,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedBody:       "invalid channel_id\n",
+		},
+		"PollStore.Save fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				getChannel(api)
+				createPost(api)
+				api.On("GetUserByUsername", "unknown").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", mock.AnythingOfType("*poll.Poll")).Return(errors.New(""))
+				return store
+			},
+			Query:              "?format=simplepoll&channel_id=channelID1",
+			Body:               simplePollExport,
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedBody:       "failed to import poll \"Lunch?\" after 0 polls\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("GetUser", "userID1").Return(systemAdmin, nil)
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/admin/import"+test.Query, strings.NewReader(test.Body))
+			r.Header.Set("Mattermost-User-ID", "userID1")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			body, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(t, test.ExpectedBody, string(body))
+		})
+	}
+}
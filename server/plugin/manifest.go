@@ -5,5 +5,5 @@ var manifest = struct {
 	Version string
 }{
 	ID:      "com.github.matterpoll.matterpoll",
-	Version: "1.1.0",
+	Version: "1.2.0",
 }
@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextMyVotes = &i18n.Message{
+		ID:    "command.help.text.myVotes",
+		Other: "To see the polls you recently voted in and what you voted for, type `/{{.Trigger}} myvotes`",
+	}
+	commandErrorMyVotesUsage = &i18n.Message{
+		ID:    "command.error.myVotes.usage",
+		Other: "Usage: `/{{.Trigger}} myvotes`",
+	}
+
+	myVotesNone = &i18n.Message{
+		ID:    "myVotes.none",
+		Other: "You haven't voted in any poll recently.",
+	}
+	myVotesHeading = &i18n.Message{
+		ID:    "myVotes.heading",
+		Other: "Your recent votes, newest first:",
+	}
+	myVotesEntry = &i18n.Message{
+		ID:    "myVotes.entry",
+		Other: "- **{{.Question}}**: {{.Choice}} (running)",
+	}
+	myVotesEntryEnded = &i18n.Message{
+		ID:    "myVotes.entryEnded",
+		Other: "- **{{.Question}}**: {{.Choice}} (ended)",
+	}
+	myVotesSecretBallot = &i18n.Message{
+		ID:    "myVotes.secretBallot",
+		Other: "secret ballot",
+	}
+	myVotesOwnVote = &i18n.Message{
+		ID:    "myVotes.ownVote",
+		Other: "**Your vote**: {{.Choice}}",
+	}
+)
+
+// executeMyVotesCommand lists the polls the user recently voted in together with the user's choice
+func (p *MatterpollPlugin) executeMyVotesCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	if len(params) != 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorMyVotesUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	msg, err := p.listVotedPolls(args.UserId, userLocalizer)
+	if err != nil {
+		p.API.LogError("failed to list votes", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	return msg, nil
+}
+
+// listVotedPolls returns a message that lists the polls a given user recently voted in, newest first.
+// Polls that got deleted or in which the user no longer votes for anything are left out.
+func (p *MatterpollPlugin) listVotedPolls(userID string, userLocalizer *i18n.Localizer) (string, error) {
+	pollIDs, err := p.Store.Vote().List(userID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list voted polls")
+	}
+
+	lines := []string{}
+	for _, pollID := range pollIDs {
+		// Polls that have been deleted for good can't be loaded anymore
+		votedPoll, err := p.Store.Poll().Get(pollID)
+		if err != nil || votedPoll.IsDeleted() || !votedPoll.HasVoted(userID) {
+			continue
+		}
+
+		entry := myVotesEntry
+		if votedPoll.IsEnded() {
+			entry = myVotesEntryEnded
+		}
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: entry,
+			TemplateData: map[string]interface{}{
+				"Question": votedPoll.Question,
+				"Choice":   p.describeVote(votedPoll, userID, userLocalizer),
+			},
+		}))
+	}
+
+	if len(lines) == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, myVotesNone), nil
+	}
+	return strings.Join(append([]string{p.LocalizeDefaultMessage(userLocalizer, myVotesHeading)}, lines...), "\n"), nil
+}
+
+// describeVote returns what a given user votes for in a given poll.
+// Votes of polls with receipts are detached from their voters, hence they can't be described.
+func (p *MatterpollPlugin) describeVote(votedPoll *poll.Poll, userID string, l *i18n.Localizer) string {
+	switch {
+	case votedPoll.Settings.Receipts:
+		return p.LocalizeDefaultMessage(l, myVotesSecretBallot)
+	case len(votedPoll.Questions) > 0:
+		answers := []string{}
+		for i, question := range votedPoll.Questions {
+			if !votedPoll.HasAnswered(userID, i) {
+				continue
+			}
+			answers = append(answers, question.Question+": "+votedAnswerOptions(question.AnswerOptions, userID))
+		}
+		return strings.Join(answers, "; ")
+	case votedPoll.Settings.VoteMode == poll.VoteModeRanked:
+		return rankedAnswers(votedPoll, votedPoll.Rankings[userID])
+	case votedPoll.Settings.VoteMode == poll.VoteModeRating:
+		return ratedAnswers(votedPoll, votedPoll.Ratings[userID])
+	case votedPoll.Settings.VoteMode == poll.VoteModeEstimate:
+		return votedPoll.EstimateOf(userID)
+	default:
+		return votedAnswerOptions(append(append([]*poll.AnswerOption{}, votedPoll.AnswerOptions...), votedPoll.WriteIns...), userID)
+	}
+}
+
+// makeOwnVoteText returns what a given user votes for in a given poll, e.g. "Your vote: Answer 2".
+// It's empty if the user doesn't vote for anything anymore and for polls with receipts, whose voters get a receipt instead.
+func (p *MatterpollPlugin) makeOwnVoteText(votedPoll *poll.Poll, userID string, l *i18n.Localizer) string {
+	if votedPoll.Settings.Receipts || !votedPoll.HasVoted(userID) {
+		return ""
+	}
+	return p.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+		DefaultMessage: myVotesOwnVote,
+		TemplateData:   map[string]interface{}{"Choice": p.describeVote(votedPoll, userID, l)},
+	})
+}
+
+// votedAnswerOptions returns the answers of the given answer options a given user votes for, separated by commas
+func votedAnswerOptions(answerOptions []*poll.AnswerOption, userID string) string {
+	answers := []string{}
+	for _, o := range answerOptions {
+		for _, voter := range o.Voter {
+			if voter == userID {
+				answers = append(answers, o.Answer)
+				break
+			}
+		}
+	}
+	return strings.Join(answers, ", ")
+}
+
+// recordVotedPoll adds a poll to the polls a given user recently voted in. Failures are only logged, because the vote has already been cast.
+func (p *MatterpollPlugin) recordVotedPoll(votedPoll *poll.Poll, userID string) {
+	if err := p.Store.Vote().Record(userID, votedPoll.ID); err != nil {
+		p.API.LogWarn("failed to record voted poll", "pollID", votedPoll.ID, "error", err.Error())
+	}
+}
@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteMyVotesCommand(t *testing.T) {
+	withID := func(p *poll.Poll, id string) *poll.Poll {
+		p.ID = id
+		return p
+	}
+	endedPoll := withID(testutils.GetPollWithVotes(), "pollID2")
+	endedPoll.EndedAt = 1234567890
+	deletedPoll := withID(testutils.GetPollWithVotes(), "pollID3")
+	deletedPoll.DeletedAt = 1234567890
+	receiptPoll := withID(testutils.GetPollWithVotesAndSettings(poll.Settings{Receipts: true}), "pollID4")
+	receiptPoll.BallotVoters = []string{"userID1"}
+
+	for name, test := range map[string]struct {
+		SetupAPI     func(*plugintest.API) *plugintest.API
+		SetupStore   func(*mockstore.Store) *mockstore.Store
+		Params       []string
+		ExpectedText string
+	}{
+		"Running and ended polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.VoteStore.On("List", "userID1").Return([]string{testutils.GetPollID(), "pollID2"}, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				store.PollStore.On("Get", "pollID2").Return(endedPoll, nil)
+				return store
+			},
+			Params: []string{},
+			ExpectedText: "Your recent votes, newest first:\n" +
+				"- **Question**: Answer 1 (running)\n" +
+				"- **Question**: Answer 1 (ended)",
+		},
+		"Ranked, rating and survey polls": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.VoteStore.On("List", "userID1").Return([]string{"pollID2", "pollID3", "pollID4"}, nil)
+				store.PollStore.On("Get", "pollID2").Return(withID(testutils.GetPollWithRankings(), "pollID2"), nil)
+				store.PollStore.On("Get", "pollID3").Return(withID(testutils.GetPollWithRatings(), "pollID3"), nil)
+				store.PollStore.On("Get", "pollID4").Return(withID(testutils.GetSurveyWithVotes(), "pollID4"), nil)
+				return store
+			},
+			Params: []string{},
+			ExpectedText: "Your recent votes, newest first:\n" +
+				"- **Question**: Answer 1 > Answer 2 > Answer 3 (running)\n" +
+				"- **Question**: Answer 1: 5, Answer 2: 2 (running)\n" +
+				"- **Survey**: Question 1: Yes; Question 2: Answer 2 (running)",
+		},
+		"Poll with receipts": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.VoteStore.On("List", "userID1").Return([]string{"pollID4"}, nil)
+				store.PollStore.On("Get", "pollID4").Return(receiptPoll, nil)
+				return store
+			},
+			Params: []string{},
+			ExpectedText: "Your recent votes, newest first:\n" +
+				"- **Question**: secret ballot (running)",
+		},
+		"Deleted, missing and unvoted polls are left out": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.VoteStore.On("List", "userID1").Return([]string{testutils.GetPollID(), "pollID2", "pollID3"}, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				store.PollStore.On("Get", "pollID2").Return(nil, errors.New(""))
+				store.PollStore.On("Get", "pollID3").Return(deletedPoll, nil)
+				return store
+			},
+			Params:       []string{},
+			ExpectedText: myVotesNone.Other,
+		},
+		"No votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.VoteStore.On("List", "userID1").Return([]string{}, nil)
+				return store
+			},
+			Params:       []string{},
+			ExpectedText: myVotesNone.Other,
+		},
+		"Too many parameters": {
+			SetupAPI:     func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:   func(store *mockstore.Store) *mockstore.Store { return store },
+			Params:       []string{"all"},
+			ExpectedText: "Usage: `/poll myvotes`",
+		},
+		"List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.VoteStore.On("List", "userID1").Return(nil, errors.New(""))
+				return store
+			},
+			Params:       []string{},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Id: "userID1"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, appErr := p.executeMyVotesCommand(&model.CommandArgs{UserId: "userID1", ChannelId: "channelID1", TeamId: "teamID1"}, test.Params)
+
+			assert.Nil(t, appErr)
+			assert.Equal(t, test.ExpectedText, msg)
+		})
+	}
+}
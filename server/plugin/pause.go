@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextPause = &i18n.Message{
+		ID:    "command.help.text.pause",
+		Other: "To stop voting for a while without ending a poll, e.g. to discuss it in a meeting first, type `/{{.Trigger}} pause <poll ID>`. To let users vote again, type `/{{.Trigger}} resume <poll ID>`",
+	}
+	commandErrorPauseUsage = &i18n.Message{
+		ID:    "command.error.pause.usage",
+		Other: "Usage: `/{{.Trigger}} pause <poll ID>`",
+	}
+	commandErrorResumeUsage = &i18n.Message{
+		ID:    "command.error.resume.usage",
+		Other: "Usage: `/{{.Trigger}} resume <poll ID>`",
+	}
+
+	responsePausePollSuccess = &i18n.Message{
+		ID:    "response.pausePoll.success",
+		Other: "Successfully paused voting in the poll.",
+	}
+	responseResumePollSuccess = &i18n.Message{
+		ID:    "response.resumePoll.success",
+		Other: "Successfully resumed voting in the poll.",
+	}
+	responsePausePollInvalidPermission = &i18n.Message{
+		ID:    "response.pausePoll.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to pause and resume voting.",
+	}
+	responsePausePollAlreadyPaused = &i18n.Message{
+		ID:    "response.pausePoll.alreadyPaused",
+		Other: "Voting in this poll is already paused.",
+	}
+	responseResumePollNotPaused = &i18n.Message{
+		ID:    "response.resumePoll.notPaused",
+		Other: "Voting in this poll isn't paused.",
+	}
+)
+
+// executePauseCommand pauses or resumes voting in the poll with the ID given in params
+func (p *MatterpollPlugin) executePauseCommand(args *model.CommandArgs, params []string, pause bool) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	if len(params) != 1 {
+		usage := commandErrorPauseUsage
+		if !pause {
+			usage = commandErrorResumeUsage
+		}
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: usage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	msg, err := p.pausePollByID(params[0], args.UserId, pause)
+	if err != nil {
+		p.API.LogError("failed to pause or resume poll", "err", err.Error())
+	}
+	return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: msg,
+		TemplateData:   map[string]interface{}{"Trigger": trigger},
+	}), nil
+}
+
+// handlePausePoll pauses voting in the poll of the post the Pause Voting button belongs to
+func (p *MatterpollPlugin) handlePausePoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	msg, err := p.pausePollByID(vars["id"], request.UserId, true)
+	return msg, nil, err
+}
+
+// handleResumePoll resumes voting in the poll of the post the Resume Voting button belongs to
+func (p *MatterpollPlugin) handleResumePoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	msg, err := p.pausePollByID(vars["id"], request.UserId, false)
+	return msg, nil, err
+}
+
+// pausePollByID pauses voting in a running poll on behalf of a given user, or resumes it if pause is false.
+// The posts of the poll tell that voting is paused and offer the button that does the opposite.
+func (p *MatterpollPlugin) pausePollByID(pollID, userID string, pause bool) (*i18n.Message, error) {
+	runningPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(runningPoll, userID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responsePausePollInvalidPermission, nil
+	}
+	if runningPoll.IsDeleted() {
+		return responseDeletePollAlreadyDeleted, nil
+	}
+	if runningPoll.IsScheduled() {
+		return commandErrorNotPosted, nil
+	}
+	if runningPoll.IsEnded() {
+		return commandErrorEndAlreadyEnded, nil
+	}
+	if pause && runningPoll.IsPaused() {
+		return responsePausePollAlreadyPaused, nil
+	}
+	if !pause && !runningPoll.IsPaused() {
+		return responseResumePollNotPaused, nil
+	}
+
+	updatedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if latest.IsEnded() {
+			return errors.New("poll has already ended")
+		}
+		if pause {
+			latest.Pause()
+		} else {
+			latest.Resume()
+		}
+		return nil
+	})
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to update poll")
+	}
+	p.publishPollEvent(websocketEventPollUpdated, updatedPoll)
+
+	if pause {
+		p.recordAudit(audit.ActionVotingPaused, updatedPoll, userID, "")
+	} else {
+		p.recordAudit(audit.ActionVotingResumed, updatedPoll, userID, "")
+	}
+
+	if appErr := p.updatePollPost(updatedPoll); appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to update poll post")
+	}
+	if pause {
+		return responsePausePollSuccess, nil
+	}
+	return responseResumePollSuccess, nil
+}
@@ -0,0 +1,235 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPausePollByID(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	running := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	paused := func(p *poll.Poll) *poll.Poll {
+		p = running(p)
+		p.PausedAt = now - 1000
+		return p
+	}
+	setupPostUpdate := func(api *plugintest.API) *plugintest.API {
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "postID1" && post.GetProp("attachments") != nil
+		})).Return(nil, nil)
+		return api
+	}
+
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		UserID          string
+		Pause           bool
+		SetupAPI        func(*plugintest.API) *plugintest.API
+		SetupStore      func(*mockstore.Store, *poll.Poll) *mockstore.Store
+		ExpectedMessage string
+		ExpectedPaused  bool
+		ShouldError     bool
+	}{
+		"pause": {
+			Poll:     running(testutils.GetPoll()),
+			UserID:   "userID1",
+			Pause:    true,
+			SetupAPI: setupPostUpdate,
+			SetupStore: func(store *mockstore.Store, updatedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(updatedPoll))
+				return store
+			},
+			ExpectedMessage: responsePausePollSuccess.Other,
+			ExpectedPaused:  true,
+		},
+		"resume": {
+			Poll:     paused(testutils.GetPoll()),
+			UserID:   "userID1",
+			SetupAPI: setupPostUpdate,
+			SetupStore: func(store *mockstore.Store, updatedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(updatedPoll))
+				return store
+			},
+			ExpectedMessage: responseResumePollSuccess.Other,
+		},
+		"pause, already paused": {
+			Poll:            paused(testutils.GetPoll()),
+			UserID:          "userID1",
+			Pause:           true,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responsePausePollAlreadyPaused.Other,
+			ExpectedPaused:  true,
+		},
+		"resume, not paused": {
+			Poll:            running(testutils.GetPoll()),
+			UserID:          "userID1",
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseResumePollNotPaused.Other,
+		},
+		"poll has ended": {
+			Poll: func() *poll.Poll {
+				p := running(testutils.GetPoll())
+				p.EndedAt = now
+				return p
+			}(),
+			UserID:          "userID1",
+			Pause:           true,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: commandErrorEndAlreadyEnded.Other,
+		},
+		"poll has been deleted": {
+			Poll: func() *poll.Poll {
+				p := running(testutils.GetPoll())
+				p.DeletedAt = now
+				return p
+			}(),
+			UserID:          "userID1",
+			Pause:           true,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDeletePollAlreadyDeleted.Other,
+		},
+		"invalid permission": {
+			Poll:   running(testutils.GetPoll()),
+			UserID: "userID2",
+			Pause:  true,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responsePausePollInvalidPermission.Other,
+		},
+		"Store.Update fails": {
+			Poll:     running(testutils.GetPoll()),
+			UserID:   "userID1",
+			Pause:    true,
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			ExpectedMessage: commandErrorGeneric.Other,
+			ShouldError:     true,
+		},
+		"UpdatePost fails": {
+			Poll:   running(testutils.GetPoll()),
+			UserID: "userID1",
+			Pause:  true,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, updatedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(updatedPoll))
+				return store
+			},
+			ExpectedMessage: commandErrorGeneric.Other,
+			ExpectedPaused:  true,
+			ShouldError:     true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
+			api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll.Copy(), nil)
+			store = test.SetupStore(store, test.Poll)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, err := p.pausePollByID(testutils.GetPollID(), test.UserID, test.Pause)
+			assert.Equal(t, test.ExpectedMessage, msg.Other)
+			assert.Equal(t, test.ExpectedPaused, test.Poll.IsPaused())
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestHandlePausePoll(t *testing.T) {
+	for name, test := range map[string]struct {
+		Path             string
+		Paused           bool
+		ExpectedResponse string
+		ExpectedPaused   bool
+	}{
+		"pause": {
+			Path:             "pause",
+			ExpectedResponse: responsePausePollSuccess.Other,
+			ExpectedPaused:   true,
+		},
+		"resume": {
+			Path:             "resume",
+			Paused:           true,
+			ExpectedResponse: responseResumePollSuccess.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			runningPoll := testutils.GetPoll()
+			runningPoll.PostID = "postID1"
+			if test.Paused {
+				runningPoll.PausedAt = 1234567890
+			}
+
+			api := &plugintest.API{}
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+			api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+			api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(runningPoll.Copy(), nil)
+			store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(GetMockPollUpdate(runningPoll))
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/%s", testutils.GetPollID(), test.Path), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+			require.NotNil(t, response)
+			assert.Equal(t, test.ExpectedResponse, response.EphemeralText)
+			assert.Equal(t, test.ExpectedPaused, runningPoll.IsPaused())
+		})
+	}
+}
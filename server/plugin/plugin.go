@@ -2,17 +2,24 @@ package plugin
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/rand"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/metrics"
 	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/store"
 	"github.com/matterpoll/matterpoll/server/store/kvstore"
+	"github.com/matterpoll/matterpoll/server/store/metricsstore"
+	"github.com/matterpoll/matterpoll/server/store/sqlstore"
+	"github.com/matterpoll/matterpoll/server/store/statsstore"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
 )
@@ -25,6 +32,9 @@ type MatterpollPlugin struct {
 	router    *mux.Router
 	Store     store.Store
 
+	// metrics collects the metrics exposed on the metrics route. It's nil until the plugin is activated.
+	metrics *metrics.Metrics
+
 	// activated is used to track whether or not OnActivate has initialized the plugin state.
 	activated bool
 
@@ -35,6 +45,19 @@ type MatterpollPlugin struct {
 	// setConfiguration for usage.
 	configuration *configuration
 	ServerConfig  *model.Config
+
+	// instanceID identifies this plugin instance in a cluster. It changes with every activation.
+	instanceID string
+
+	// schedulerStop and schedulerDone control the goroutine that runs scheduled jobs.
+	schedulerStop chan struct{}
+	schedulerDone chan struct{}
+
+	// postUpdatesLock synchronizes access to postUpdates.
+	postUpdatesLock sync.Mutex
+	// postUpdates holds the debounced post updates of the polls that have recently been voted in, by poll ID.
+	// Consult debouncePostUpdate for usage.
+	postUpdates map[string]*postUpdateWindow
 }
 
 var botDescription = &i18n.Message{
@@ -43,10 +66,13 @@ var botDescription = &i18n.Message{
 }
 
 const (
-	minimumServerVersion = "5.10.0"
+	minimumServerVersion = "5.12.0"
 
 	botUserName    = "matterpoll"
 	botDisplayName = "Matterpoll"
+
+	storeTypeKV  = "kv"
+	storeTypeSQL = "sql"
 )
 
 // OnActivate ensures a configuration is set and initializes the API
@@ -60,10 +86,19 @@ func (p *MatterpollPlugin) OnActivate() error {
 		return errors.New("siteURL is not set. Please set a siteURL and restart the plugin")
 	}
 
-	p.Store, err = kvstore.NewStore(p.API, manifest.Version)
+	// Shuffled polls must not get the same order of answer options after every restart
+	rand.Seed(time.Now().UnixNano())
+
+	p.metrics = metrics.New()
+	s, err := p.initStore()
 	if err != nil {
 		return errors.Wrap(err, "failed to create store")
 	}
+	// The statistics are updated through the metrics store, so their latency gets recorded as well
+	p.Store, err = statsstore.NewStore(metricsstore.NewStore(s, p.metrics), p.API)
+	if err != nil {
+		return errors.Wrap(err, "failed to init poll statistics")
+	}
 
 	p.bundle, err = p.initBundle()
 	if err != nil {
@@ -90,18 +125,46 @@ func (p *MatterpollPlugin) OnActivate() error {
 
 	p.router = p.InitAPI()
 
+	p.instanceID = model.NewId()
+	if err = p.scheduleArchive(); err != nil {
+		p.API.LogWarn("failed to schedule archiving of ended polls", "error", err.Error())
+	}
+	if err = p.scheduleTelemetry(); err != nil {
+		p.API.LogWarn("failed to schedule telemetry", "error", err.Error())
+	}
+	if err = p.scheduleEndArchivedPolls(); err != nil {
+		p.API.LogWarn("failed to schedule ending polls of archived channels", "error", err.Error())
+	}
+	p.startScheduler()
+
 	p.setActivated(true)
 
 	return nil
 }
 
-// OnDeactivate marks the plugin as deactivated
+// OnDeactivate stops the scheduler, flushes pending post updates, closes the store and marks the plugin as deactivated
 func (p *MatterpollPlugin) OnDeactivate() error {
+	p.stopScheduler()
+	p.flushPostUpdates()
 	p.setActivated(false)
 
+	if closer, ok := p.Store.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return errors.Wrap(err, "failed to close store")
+		}
+	}
 	return nil
 }
 
+// initStore creates the store selected in the plugin configuration.
+// The database store uses the database of the Mattermost server.
+func (p *MatterpollPlugin) initStore() (store.Store, error) {
+	if p.getConfiguration().StoreType == storeTypeSQL {
+		return sqlstore.NewStore(p.API, p.ServerConfig.SqlSettings, manifest.Version)
+	}
+	return kvstore.NewStore(p.API, manifest.Version)
+}
+
 func (p *MatterpollPlugin) setActivated(activated bool) {
 	p.activated = activated
 }
@@ -125,6 +188,15 @@ func (p *MatterpollPlugin) checkServerVersion() error {
 	return nil
 }
 
+// supportsInterPluginRequests checks if the Mattermost Server passes the ID of the plugin that sent a request
+func (p *MatterpollPlugin) supportsInterPluginRequests() bool {
+	serverVersion, err := semver.Parse(p.API.GetServerVersion())
+	if err != nil {
+		return false
+	}
+	return serverVersion.GTE(semver.MustParse(interPluginRequestsServerVersion))
+}
+
 // patchBotDescription updates the bot description based on the servers local
 func (p *MatterpollPlugin) patchBotDescription() error {
 	publicLocalizer := p.getServerLocalizer()
@@ -169,8 +241,72 @@ func (p *MatterpollPlugin) ConvertUserIDToDisplayName(userID string) (string, *m
 	return displayName, nil
 }
 
-// ConvertCreatorIDToDisplayName returns the display name to a given user ID of a poll creator
+// makePollAttachments returns the attachments that display a running poll.
+// Polls with public votes additionally list the voters of every answer option.
+func (p *MatterpollPlugin) makePollAttachments(currentPoll *poll.Poll, displayName string) ([]*model.SlackAttachment, *model.AppError) {
+	publicLocalizer := p.getPublicLocalizer()
+	attachments := currentPoll.ToPostActions(publicLocalizer, *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName)
+	if currentPoll.Settings.PublicVotes {
+		fields, appErr := currentPoll.MakeVoterFields(publicLocalizer, poll.PublicVotersLimit, p.ConvertUserIDToDisplayName)
+		if appErr != nil {
+			return nil, appErr
+		}
+		attachments[0].Fields = fields
+	}
+	return attachments, nil
+}
+
+// updatePollPost replaces the posts of a given poll with its current state, including the posts it got cross-posted to.
+// Running polls show their buttons and ended polls their results. Polls that haven't been posted yet are ignored.
+func (p *MatterpollPlugin) updatePollPost(currentPoll *poll.Poll) *model.AppError {
+	if currentPoll.PostID == "" {
+		return nil
+	}
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(currentPoll.Creator)
+	if appErr != nil {
+		return appErr
+	}
+
+	if currentPoll.IsEnded() {
+		post, appErr := currentPoll.ToEndPollPost(p.getPublicLocalizer(), *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName, p.ConvertUserIDToDisplayName)
+		if appErr != nil {
+			return appErr
+		}
+		for _, pollPost := range currentPoll.Posts() {
+			post.Id = pollPost.PostID
+			post.ChannelId = pollPost.ChannelID
+			if _, appErr = p.API.UpdatePost(post); appErr != nil {
+				return appErr
+			}
+		}
+		return nil
+	}
+
+	attachments, appErr := p.makePollAttachments(currentPoll, displayName)
+	if appErr != nil {
+		return appErr
+	}
+	for _, pollPost := range currentPoll.Posts() {
+		post, appErr := p.API.GetPost(pollPost.PostID)
+		if appErr != nil {
+			return appErr
+		}
+		// The post of a restored poll still says that the poll got deleted
+		post.Message = ""
+		model.ParseSlackAttachment(post, attachments)
+		if _, appErr = p.API.UpdatePost(post); appErr != nil {
+			return appErr
+		}
+	}
+	return nil
+}
+
+// ConvertCreatorIDToDisplayName returns the display name to a given user ID of a poll creator.
+// Polls whose creator got erased have no creator and an empty display name.
 func (p *MatterpollPlugin) ConvertCreatorIDToDisplayName(creatorID string) (string, *model.AppError) {
+	if creatorID == "" {
+		return "", nil
+	}
 	user, err := p.API.GetUser(creatorID)
 	if err != nil {
 		return "", err
@@ -179,20 +315,49 @@ func (p *MatterpollPlugin) ConvertCreatorIDToDisplayName(creatorID string) (stri
 	return displayName, nil
 }
 
-// HasPermission checks if a given user has the permission to end or delete a given poll
+// HasPermission checks if a given user has the permission to end or delete a given poll.
+// Besides system admins, that's the creator and the moderators of the poll.
 func (p *MatterpollPlugin) HasPermission(poll *poll.Poll, issuerID string) (bool, *model.AppError) {
-	if issuerID == poll.Creator {
+	if issuerID == poll.Creator || poll.IsModerator(issuerID) {
 		return true, nil
 	}
 
-	user, appErr := p.API.GetUser(issuerID)
+	return p.isSystemAdmin(issuerID)
+}
+
+// resolveUsers replaces the usernames of the moderators and of the weighted voters of a new poll by their user IDs
+func (p *MatterpollPlugin) resolveUsers(newPoll *poll.Poll) error {
+	err := newPoll.ResolveModerators(func(username string) (string, error) {
+		user, appErr := p.API.GetUserByUsername(username)
+		if appErr != nil {
+			return "", fmt.Errorf("Unknown moderator @%s", username)
+		}
+		return user.Id, nil
+	})
+	if err != nil {
+		return err
+	}
+	return newPoll.ResolveWeights(func(username string) (string, error) {
+		user, appErr := p.API.GetUserByUsername(username)
+		if appErr != nil {
+			return "", fmt.Errorf("Unknown user @%s", username)
+		}
+		return user.Id, nil
+	})
+}
+
+// isSystemAdmin checks if a given user is a system admin
+func (p *MatterpollPlugin) isSystemAdmin(userID string) (bool, *model.AppError) {
+	user, appErr := p.API.GetUser(userID)
 	if appErr != nil {
 		return false, appErr
 	}
-	if user.IsInRole(model.SYSTEM_ADMIN_ROLE_ID) {
-		return true, nil
-	}
-	return false, nil
+	return user.IsInRole(model.SYSTEM_ADMIN_ROLE_ID), nil
+}
+
+// makePermalink returns the permalink to a given post in a given team
+func (p *MatterpollPlugin) makePermalink(teamName, postID string) string {
+	return fmt.Sprintf("%s/%s/pl/%s", *p.ServerConfig.ServiceSettings.SiteURL, teamName, postID)
 }
 
 // SendEphemeralPost sends an ephemeral post to a user as the bot account
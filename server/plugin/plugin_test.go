@@ -10,9 +10,13 @@ import (
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
 	"github.com/matterpoll/matterpoll/server/store"
 	"github.com/matterpoll/matterpoll/server/store/kvstore"
 	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/store/sqlstore"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/stretchr/testify/assert"
@@ -188,7 +192,13 @@ func TestPluginOnActivate(t *testing.T) {
 			}
 
 			patch := monkey.Patch(kvstore.NewStore, func(plugin.API, string) (store.Store, error) {
-				return &mockstore.Store{}, nil
+				store := &mockstore.Store{}
+				store.JobStore.On("List").Return([]*job.Job{}, nil).Maybe()
+				store.JobStore.On("Save", mock.AnythingOfType("*job.Job")).Return(nil).Maybe()
+				store.StatsStore.On("Get").Return(stats.New(), nil).Maybe()
+				store.LeaderStore.On("Lead", mock.AnythingOfType("string"), schedulerLease).Return(true, nil).Maybe()
+				store.LeaderStore.On("Resign", mock.AnythingOfType("string")).Return(nil).Maybe()
+				return store, nil
 			})
 			defer patch.Unpatch()
 
@@ -210,6 +220,7 @@ func TestPluginOnActivate(t *testing.T) {
 			p.SetAPI(api)
 			p.SetHelpers(helpers)
 			err := p.OnActivate()
+			defer p.stopScheduler()
 
 			if test.ShouldError {
 				assert.NotNil(t, err)
@@ -271,6 +282,40 @@ func TestPluginOnActivate(t *testing.T) {
 	})
 }
 
+func TestPluginInitStore(t *testing.T) {
+	kvStore := &mockstore.Store{}
+	sqlStore := &mockstore.Store{}
+	patch1 := monkey.Patch(kvstore.NewStore, func(plugin.API, string) (store.Store, error) {
+		return kvStore, nil
+	})
+	defer patch1.Unpatch()
+	patch2 := monkey.Patch(sqlstore.NewStore, func(plugin.API, model.SqlSettings, string) (store.Store, error) {
+		return sqlStore, nil
+	})
+	defer patch2.Unpatch()
+
+	for name, test := range map[string]struct {
+		StoreType     string
+		ExpectedStore store.Store
+	}{
+		"no store type":  {StoreType: "", ExpectedStore: kvStore},
+		"KV Store":       {StoreType: storeTypeKV, ExpectedStore: kvStore},
+		"database store": {StoreType: storeTypeSQL, ExpectedStore: sqlStore},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+			p.setConfiguration(&configuration{
+				Trigger:   "poll",
+				StoreType: test.StoreType,
+			})
+
+			s, err := p.initStore()
+			assert.Nil(t, err)
+			assert.True(t, s == test.ExpectedStore)
+		})
+	}
+}
+
 func TestPluginOnDeactivate(t *testing.T) {
 		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
 
@@ -278,6 +323,118 @@ func TestPluginOnDeactivate(t *testing.T) {
 		assert.Nil(t, err)
 }
 
+func TestPluginUpdatePollPost(t *testing.T) {
+	t.Run("running poll", func(t *testing.T) {
+		runningPoll := testutils.GetPollWithVotes()
+		runningPoll.PostID = "postID1"
+		expectedPost := &model.Post{Id: "postID1"}
+		model.ParseSlackAttachment(expectedPost, runningPoll.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "user1"))
+
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+		api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Nil(t, p.updatePollPost(runningPoll))
+	})
+
+	t.Run("ended poll of erased creator", func(t *testing.T) {
+		endedPoll := testutils.GetPollWithSettings(poll.Settings{AnonymousCreator: true})
+		endedPoll.Creator = ""
+		endedPoll.PostID = "postID1"
+		endedPoll.ChannelID = "channelID1"
+		endedPoll.EndedAt = 1234567890
+
+		api := &plugintest.API{}
+		api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.Id == "postID1" && post.ChannelId == "channelID1"
+		})).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.Nil(t, p.updatePollPost(endedPoll))
+	})
+
+	t.Run("poll not posted yet", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.Nil(t, p.updatePollPost(testutils.GetPollWithSettings(poll.Settings{PostAt: 1234567890})))
+	})
+
+	t.Run("GetPost fails", func(t *testing.T) {
+		runningPoll := testutils.GetPoll()
+		runningPoll.PostID = "postID1"
+
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetPost", "postID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		assert.NotNil(t, p.updatePollPost(runningPoll))
+	})
+}
+
+func TestPluginHasPermission(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		UserID             string
+		ExpectedPermission bool
+		ShouldError        bool
+	}{
+		"Creator": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			UserID:             "userID1",
+			ExpectedPermission: true,
+		},
+		"Moderator": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			UserID:             "userID2",
+			ExpectedPermission: true,
+		},
+		"System admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID3").Return(&model.User{Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			UserID:             "userID3",
+			ExpectedPermission: true,
+		},
+		"Other user": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID3").Return(&model.User{Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			UserID:             "userID3",
+			ExpectedPermission: false,
+		},
+		"GetUser fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID3").Return(nil, &model.AppError{})
+				return api
+			},
+			UserID:      "userID3",
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+
+			hasPermission, appErr := p.HasPermission(testutils.GetPollWithSettings(poll.Settings{Moderators: []string{"userID2"}}), test.UserID)
+			assert.Equal(t, test.ExpectedPermission, hasPermission)
+			if test.ShouldError {
+				assert.NotNil(t, appErr)
+			} else {
+				assert.Nil(t, appErr)
+			}
+		})
+	}
+}
+
 func GetMockArgumentsWithType(typeString string, num int) []interface{} {
 	ret := make([]interface{}, num)
 	for i := 0; i < len(ret); i++ {
@@ -285,3 +442,19 @@ func GetMockArgumentsWithType(typeString string, num int) []interface{} {
 	}
 	return ret
 }
+
+// GetMockPollUpdate returns the return values for a mocked PollStore.Update call,
+// which applies the update to p as if p was the latest version in the store.
+func GetMockPollUpdate(p *poll.Poll) (func(string, func(*poll.Poll) error) *poll.Poll, func(string, func(*poll.Poll) error) error) {
+	var err error
+	updatePoll := func(_ string, update func(*poll.Poll) error) *poll.Poll {
+		if err = update(p); err != nil {
+			return nil
+		}
+		return p
+	}
+	updateErr := func(string, func(*poll.Poll) error) error {
+		return err
+	}
+	return updatePoll, updateErr
+}
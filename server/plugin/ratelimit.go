@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+const (
+	// pollRateLimitWindow is the period MaxPollsPerHour applies to
+	pollRateLimitWindow = time.Hour
+	// pollRateLimitPrefix is the prefix of the rate limit counters of poll creations
+	pollRateLimitPrefix = "polls_"
+)
+
+var responseCreatePollRateLimited = &i18n.Message{
+	ID:    "response.createPoll.rateLimited",
+	Other: "You have created too many polls recently. Please try again later.",
+}
+
+// isPollRateLimited checks if a given user already created the maximum number of polls per hour.
+// If the limit can't be checked, the poll is allowed and the failure is logged.
+func (p *MatterpollPlugin) isPollRateLimited(userID string) bool {
+	limit := p.pollRateLimit(userID)
+	if limit == 0 {
+		return false
+	}
+
+	count, err := p.Store.RateLimit().Get(pollRateLimitPrefix+userID, pollRateLimitWindow)
+	if err != nil {
+		p.API.LogWarn("failed to get number of created polls", "error", err.Error())
+		return false
+	}
+	return count >= limit
+}
+
+// countPollCreation counts a poll that a given user created towards the maximum number of polls per hour.
+// The poll already exists at this point, so a failure is only logged.
+func (p *MatterpollPlugin) countPollCreation(userID string) {
+	if p.pollRateLimit(userID) == 0 {
+		return
+	}
+
+	if _, err := p.Store.RateLimit().Increment(pollRateLimitPrefix+userID, pollRateLimitWindow); err != nil {
+		p.API.LogWarn("failed to count poll creation", "error", err.Error())
+	}
+}
+
+// pollRateLimit returns the maximum number of polls per hour that applies to a given user, or zero if there is none.
+// System admins and the bot, which creates the polls of trusted tools, are exempt. If that can't be checked, no limit applies and the failure is logged.
+func (p *MatterpollPlugin) pollRateLimit(userID string) int {
+	limit := p.getConfiguration().maxPollsPerHour
+	if limit == 0 || userID == p.botUserID {
+		return 0
+	}
+
+	isAdmin, appErr := p.isSystemAdmin(userID)
+	if appErr != nil {
+		p.API.LogWarn("failed to check if user is system admin", "error", appErr.Error())
+		return 0
+	}
+	if isAdmin {
+		return 0
+	}
+	return limit
+}
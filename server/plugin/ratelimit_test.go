@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPollRateLimited(t *testing.T) {
+	for name, test := range map[string]struct {
+		MaxPollsPerHour int
+		SetupAPI        func(*plugintest.API) *plugintest.API
+		SetupStore      func(*mockstore.Store) *mockstore.Store
+		ExpectedResult  bool
+	}{
+		"No limit": {
+			SetupAPI:       func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:     func(store *mockstore.Store) *mockstore.Store { return store },
+			ExpectedResult: false,
+		},
+		"Below limit": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Get", "polls_userID1", pollRateLimitWindow).Return(2, nil)
+				return store
+			},
+			ExpectedResult: false,
+		},
+		"Limit reached": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Get", "polls_userID1", pollRateLimitWindow).Return(3, nil)
+				return store
+			},
+			ExpectedResult: true,
+		},
+		"System admins are exempt": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:     func(store *mockstore.Store) *mockstore.Store { return store },
+			ExpectedResult: false,
+		},
+		"GetUser fails": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore:     func(store *mockstore.Store) *mockstore.Store { return store },
+			ExpectedResult: false,
+		},
+		"Get fails": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Get", "polls_userID1", pollRateLimitWindow).Return(0, errors.New(""))
+				return store
+			},
+			ExpectedResult: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.configuration.maxPollsPerHour = test.MaxPollsPerHour
+
+			assert.Equal(t, test.ExpectedResult, p.isPollRateLimited("userID1"))
+		})
+	}
+
+	t.Run("The bot is exempt", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+		p.configuration.maxPollsPerHour = 3
+
+		assert.False(t, p.isPollRateLimited(testutils.GetBotUserID()))
+	})
+}
+
+func TestCountPollCreation(t *testing.T) {
+	for name, test := range map[string]struct {
+		MaxPollsPerHour int
+		SetupAPI        func(*plugintest.API) *plugintest.API
+		SetupStore      func(*mockstore.Store) *mockstore.Store
+	}{
+		"No limit": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"Creation is counted": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Increment", "polls_userID1", pollRateLimitWindow).Return(1, nil)
+				return store
+			},
+		},
+		"System admins are exempt": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"Increment fails": {
+			MaxPollsPerHour: 3,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.RateLimitStore.On("Increment", "polls_userID1", pollRateLimitWindow).Return(0, errors.New(""))
+				return store
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.configuration.maxPollsPerHour = test.MaxPollsPerHour
+
+			p.countPollCreation("userID1")
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var receiptPostMessage = &i18n.Message{
+	ID:    "receipt.post.message",
+	Other: "Your vote in the poll **{{.Question}}** has been counted. Your receipt is `{{.Receipt}}`. Keep it to verify your vote later, it can't be sent to you again.",
+}
+
+// verifyReceiptRequest is the request to verify a vote receipt.
+// The receipt is sent in the body, so it doesn't end up in any request log.
+type verifyReceiptRequest struct {
+	Receipt string `json:"receipt"`
+}
+
+// verifyReceiptResponse is the response of the receipt verification endpoint
+type verifyReceiptResponse struct {
+	Counted bool `json:"counted"`
+	// Answer is the answer option the vote was cast for. It's empty if the vote wasn't counted.
+	Answer string `json:"answer,omitempty"`
+}
+
+// handleVerifyReceipt writes whether the vote with the receipt of the request got counted as JSON
+func (p *MatterpollPlugin) handleVerifyReceipt(w http.ResponseWriter, r *http.Request) {
+	receiptPoll, err := p.Store.Poll().Get(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "poll not found", http.StatusNotFound)
+		return
+	}
+	if !receiptPoll.Settings.Receipts {
+		http.Error(w, "poll doesn't issue receipts", http.StatusBadRequest)
+		return
+	}
+
+	var request verifyReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Receipt == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	response := &verifyReceiptResponse{}
+	if index, ok := receiptPoll.VerifyReceipt(request.Receipt); ok {
+		response.Counted = true
+		response.Answer = receiptPoll.AnswerOptions[index].Answer
+	}
+	b, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write verifyReceiptResponse", "error", err.Error())
+	}
+}
+
+// sendVoteReceipt sends the receipt of a vote to the voter via direct message.
+// The receipt is only known at this point, so failures get logged for the admin.
+func (p *MatterpollPlugin) sendVoteReceipt(votedPoll *poll.Poll, userID, receipt string) {
+	channel, appErr := p.API.GetDirectChannel(userID, p.botUserID)
+	if appErr != nil {
+		p.API.LogWarn("failed to get direct channel to send vote receipt", "error", appErr.Error())
+		return
+	}
+
+	post := &model.Post{
+		UserId:    p.botUserID,
+		ChannelId: channel.Id,
+		Message: p.LocalizeWithConfig(p.getUserLocalizer(userID), &i18n.LocalizeConfig{
+			DefaultMessage: receiptPostMessage,
+			TemplateData: map[string]interface{}{
+				"Question": votedPoll.Question,
+				"Receipt":  receipt,
+			},
+		}),
+	}
+	if _, appErr = p.API.CreatePost(post); appErr != nil {
+		p.API.LogWarn("failed to send vote receipt", "error", appErr.Error())
+	}
+}
@@ -0,0 +1,200 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleVoteWithReceipt(t *testing.T) {
+	receiptRegexp := regexp.MustCompile("`([0-9a-f]{32})`")
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		ExpectedReceipt  bool
+		ExpectedResponse string
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.UserId == testutils.GetBotUserID() && post.ChannelId == "channelID2" && receiptRegexp.MatchString(post.Message)
+				})).Return(nil, nil)
+				return api
+			},
+			ExpectedReceipt:  true,
+			ExpectedResponse: responseVoteCounted.Other,
+		},
+		"GetDirectChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			ExpectedResponse: responseVoteCounted.Other,
+		},
+		"CreatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			ExpectedResponse: responseVoteCounted.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			receiptPoll := testutils.GetPollWithSettings(poll.Settings{Receipts: true, Anonymous: true, LockVotes: true})
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+			api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(receiptPoll))
+			store.VoteStore.On("Record", "userID1", testutils.GetPollID()).Return(nil)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			request := &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/vote/1", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(http.StatusOK, result.StatusCode)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+			require.NotNil(t, response)
+			assert.Equal(test.ExpectedResponse, response.EphemeralText)
+
+			// The vote is counted, but not stored under the user ID
+			assert.Equal([]string{"userID1"}, receiptPoll.BallotVoters)
+			assert.Len(receiptPoll.AnswerOptions[1].Voter, 1)
+			assert.False(receiptPoll.HasVotedFor("userID1", 1))
+
+			if test.ExpectedReceipt {
+				var receipt string
+				for _, call := range api.Calls {
+					if call.Method == "CreatePost" {
+						receipt = receiptRegexp.FindStringSubmatch(call.Arguments.Get(0).(*model.Post).Message)[1]
+					}
+				}
+				index, ok := receiptPoll.VerifyReceipt(receipt)
+				assert.True(ok)
+				assert.Equal(1, index)
+			}
+		})
+	}
+}
+
+func TestHandleVerifyReceipt(t *testing.T) {
+	receiptPoll := testutils.GetPollWithSettings(poll.Settings{Receipts: true, Anonymous: true, LockVotes: true})
+	receipt, err := receiptPoll.CastBallot("userID2", 2)
+	require.Nil(t, err)
+
+	for name, test := range map[string]struct {
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Body               string
+		ExpectedStatusCode int
+		ExpectedResponse   *verifyReceiptResponse
+	}{
+		"counted vote": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(receiptPoll.Copy(), nil)
+				return store
+			},
+			Body:               `{"receipt": "` + receipt + `"}`,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &verifyReceiptResponse{Counted: true, Answer: "Answer 3"},
+		},
+		"unknown receipt": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(receiptPoll.Copy(), nil)
+				return store
+			},
+			Body:               `{"receipt": "` + strings.Repeat("0", 32) + `"}`,
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &verifyReceiptResponse{Counted: false},
+		},
+		"empty receipt": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(receiptPoll.Copy(), nil)
+				return store
+			},
+			Body:               `{"receipt": ""}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		"invalid body": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(receiptPoll.Copy(), nil)
+				return store
+			},
+			Body:               `{`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		"poll without receipts": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+				return store
+			},
+			Body:               `{"receipt": "` + receipt + `"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		"poll not found": {
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, errors.New(""))
+				return store
+			},
+			Body:               `{"receipt": "` + receipt + `"}`,
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/receipts/verify", testutils.GetPollID()), strings.NewReader(test.Body))
+			r.Header.Set("Mattermost-User-ID", "userID2")
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			if test.ExpectedResponse == nil {
+				return
+			}
+
+			body, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+			response := &verifyReceiptResponse{}
+			require.Nil(t, json.Unmarshal(body, response))
+			assert.Equal(t, test.ExpectedResponse, response)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextReopen = &i18n.Message{
+		ID:    "command.help.text.reopen",
+		Other: "To reopen a poll that was ended by mistake, type `/{{.Trigger}} reopen <poll ID>`. To give it a new deadline, add e.g. `--end=24h` or `--end=2024-06-01T17:00`",
+	}
+	commandErrorReopenUsage = &i18n.Message{
+		ID:    "command.error.reopen.usage",
+		Other: "Usage: `/{{.Trigger}} reopen <poll ID> [--end=TIME]`",
+	}
+
+	responseReopenPollSuccess = &i18n.Message{
+		ID:    "response.reopenPoll.success",
+		Other: "Successfully reopened the poll.",
+	}
+	responseReopenPollInvalidPermission = &i18n.Message{
+		ID:    "response.reopenPoll.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to reopen it.",
+	}
+	responseReopenPollNotEnded = &i18n.Message{
+		ID:    "response.reopenPoll.notEnded",
+		Other: "The poll hasn't ended.",
+	}
+	responseReopenPollInvalidEnd = &i18n.Message{
+		ID:    "response.reopenPoll.invalidEnd",
+		Other: "The new deadline must be a duration like `24h` or a time like `2024-06-01T17:00` in UTC, and it must lie in the future.",
+	}
+)
+
+// executeReopenCommand reopens the ended poll with the ID given in params. An optional --end parameter sets a new deadline.
+func (p *MatterpollPlugin) executeReopenCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	endValue := ""
+	if len(params) == 2 && strings.HasPrefix(params[1], "--end=") {
+		endValue = strings.TrimPrefix(params[1], "--end=")
+	}
+	if len(params) == 0 || len(params) > 2 || (len(params) == 2 && endValue == "") {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorReopenUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	endAt := int64(0)
+	if endValue != "" {
+		// Durations are relative to the time the poll gets reopened
+		now := model.GetMillis()
+		var ok bool
+		if endAt, ok = poll.ParseTime(endValue, now); !ok || endAt <= now {
+			return p.LocalizeDefaultMessage(userLocalizer, responseReopenPollInvalidEnd), nil
+		}
+	}
+
+	msg, err := p.reopenPollByID(params[0], args.UserId, endAt)
+	if err != nil {
+		p.API.LogError("failed to reopen poll", "err", err.Error())
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, msg), nil
+}
+
+// handleReopenPoll reopens the ended poll of the post the Reopen button belongs to. The poll keeps a deadline that hasn't passed yet.
+func (p *MatterpollPlugin) handleReopenPoll(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	msg, err := p.reopenPollByID(vars["id"], request.UserId, 0)
+	return msg, nil, err
+}
+
+// reopenPollByID marks an ended poll as running again on behalf of a given user. Its posts show the voting buttons again and its jobs resume.
+// endAt is the new deadline in milliseconds, or zero to keep a deadline that hasn't passed yet.
+func (p *MatterpollPlugin) reopenPollByID(pollID, userID string, endAt int64) (*i18n.Message, error) {
+	endedPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(endedPoll, userID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseReopenPollInvalidPermission, nil
+	}
+	if endedPoll.IsDeleted() {
+		return responseDeletePollAlreadyDeleted, nil
+	}
+	if !endedPoll.IsEnded() {
+		return responseReopenPollNotEnded, nil
+	}
+
+	// Archived polls are left out of the poll list and the channel index, which running polls need to be part of
+	if err = p.Store.Poll().Unarchive(pollID); err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to unarchive poll")
+	}
+	reopenedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if !latest.IsEnded() {
+			return errors.New("poll hasn't ended")
+		}
+		latest.Reopen(endAt)
+		return nil
+	})
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to reopen poll")
+	}
+	p.publishPollEvent(websocketEventPollUpdated, reopenedPoll)
+	p.recordAudit(audit.ActionPollReopened, reopenedPoll, userID, "")
+
+	if appErr := p.updatePollPost(reopenedPoll); appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to update poll post")
+	}
+	// Ending the poll unpinned its posts
+	if reopenedPoll.Pinned {
+		for _, pollPost := range reopenedPoll.Posts() {
+			post, appErr := p.API.GetPost(pollPost.PostID)
+			if appErr != nil {
+				p.API.LogWarn("failed to get poll post", "error", appErr.Error())
+				continue
+			}
+			p.pinPollPost(reopenedPoll, post)
+		}
+	}
+
+	// Ending the poll stopped its jobs
+	if err := p.scheduleEnd(reopenedPoll); err != nil {
+		p.API.LogWarn("failed to schedule poll end", "error", err.Error())
+	}
+	if err := p.scheduleDigest(reopenedPoll); err != nil {
+		p.API.LogWarn("failed to schedule poll digest", "error", err.Error())
+	}
+	return responseReopenPollSuccess, nil
+}
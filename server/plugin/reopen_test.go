@@ -0,0 +1,251 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReopenPollByID(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	ended := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		p.EndedAt = now - 1000
+		return p
+	}
+
+	for name, test := range map[string]struct {
+		Poll             *poll.Poll
+		UserID           string
+		EndAt            int64
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store, *poll.Poll) *mockstore.Store
+		ExpectedMessage  string
+		ExpectedReopened bool
+		ExpectedEndAt    int64
+		ShouldError      bool
+	}{
+		"all fine": {
+			Poll:   ended(testutils.GetPoll()),
+			UserID: "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.GetProp("attachments") != nil
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, reopenedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Unarchive", testutils.GetPollID()).Return(nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(reopenedPoll))
+				return store
+			},
+			ExpectedMessage:  responseReopenPollSuccess.Other,
+			ExpectedReopened: true,
+		},
+		"new deadline": {
+			Poll:   ended(testutils.GetPollWithSettings(poll.Settings{EndAt: now - 1000})),
+			UserID: "userID1",
+			EndAt:  now + 1000,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, reopenedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Unarchive", testutils.GetPollID()).Return(nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(reopenedPoll))
+				store.JobStore.On("Save", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), now+1000)).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), now+1000)).Return(nil)
+				return store
+			},
+			ExpectedMessage:  responseReopenPollSuccess.Other,
+			ExpectedReopened: true,
+			ExpectedEndAt:    now + 1000,
+		},
+		"deadline passed": {
+			Poll:   ended(testutils.GetPollWithSettings(poll.Settings{EndAt: now - 1000})),
+			UserID: "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, reopenedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Unarchive", testutils.GetPollID()).Return(nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(reopenedPoll))
+				return store
+			},
+			ExpectedMessage:  responseReopenPollSuccess.Other,
+			ExpectedReopened: true,
+		},
+		"pinned poll": {
+			Poll: func() *poll.Poll {
+				p := ended(testutils.GetPollWithSettings(poll.Settings{Pin: true}))
+				p.Pinned = true
+				return p
+			}(),
+			UserID: "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool { return !post.IsPinned })).Return(nil, nil).Once()
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool { return post.IsPinned })).Return(nil, nil).Once()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, reopenedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Unarchive", testutils.GetPollID()).Return(nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(reopenedPoll))
+				return store
+			},
+			ExpectedMessage:  responseReopenPollSuccess.Other,
+			ExpectedReopened: true,
+		},
+		"poll hasn't ended": {
+			Poll:             testutils.GetPoll(),
+			UserID:           "userID1",
+			SetupAPI:         func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:       func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage:  responseReopenPollNotEnded.Other,
+			ExpectedReopened: true,
+		},
+		"poll has been deleted": {
+			Poll: func() *poll.Poll {
+				p := ended(testutils.GetPoll())
+				p.DeletedAt = 1234567890
+				return p
+			}(),
+			UserID:          "userID1",
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseDeletePollAlreadyDeleted.Other,
+		},
+		"invalid permission": {
+			Poll:   ended(testutils.GetPoll()),
+			UserID: "userID2",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseReopenPollInvalidPermission.Other,
+		},
+		"Store.Unarchive fails": {
+			Poll:     ended(testutils.GetPoll()),
+			UserID:   "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Unarchive", testutils.GetPollID()).Return(errors.New(""))
+				return store
+			},
+			ExpectedMessage: commandErrorGeneric.Other,
+			ShouldError:     true,
+		},
+		"Store.Update fails": {
+			Poll:     ended(testutils.GetPoll()),
+			UserID:   "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Unarchive", testutils.GetPollID()).Return(nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			ExpectedMessage: commandErrorGeneric.Other,
+			ShouldError:     true,
+		},
+		"UpdatePost fails": {
+			Poll:   ended(testutils.GetPoll()),
+			UserID: "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, reopenedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Unarchive", testutils.GetPollID()).Return(nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(reopenedPoll))
+				return store
+			},
+			ExpectedMessage:  commandErrorGeneric.Other,
+			ExpectedReopened: true,
+			ShouldError:      true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
+			api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return().Maybe()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll.Copy(), nil)
+			store = test.SetupStore(store, test.Poll)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, err := p.reopenPollByID(testutils.GetPollID(), test.UserID, test.EndAt)
+			assert.Equal(t, test.ExpectedMessage, msg.Other)
+			assert.Equal(t, test.ExpectedReopened, !test.Poll.IsEnded())
+			if test.ExpectedReopened {
+				assert.Equal(t, test.ExpectedEndAt, test.Poll.Settings.EndAt)
+			}
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestHandleReopenPoll(t *testing.T) {
+	endedPoll := testutils.GetPoll()
+	endedPoll.PostID = "postID1"
+	endedPoll.EndedAt = 1234567890
+
+	api := &plugintest.API{}
+	api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+	api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+	api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+	api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+	api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+	defer api.AssertExpectations(t)
+	store := &mockstore.Store{}
+	store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll.Copy(), nil)
+	store.PollStore.On("Unarchive", testutils.GetPollID()).Return(nil)
+	store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(GetMockPollUpdate(endedPoll))
+	defer store.AssertExpectations(t)
+	p := setupTestPlugin(t, api, store)
+
+	request := &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/reopen", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+	r.Header.Add("Mattermost-User-ID", model.NewId())
+	p.ServeHTTP(nil, w, r)
+
+	result := w.Result()
+	require.NotNil(t, result)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	response := model.PostActionIntegrationResponseFromJson(result.Body)
+	require.NotNil(t, response)
+	assert.Equal(t, responseReopenPollSuccess.Other, response.EphemeralText)
+	assert.False(t, endedPoll.IsEnded())
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+// replyVoteReaction is the emoji the bot reacts with to a reply whose vote got cast
+const replyVoteReaction = "ballot_box_with_check"
+
+var (
+	responseReplyVoteUnsupported = &i18n.Message{
+		ID:    "response.replyVote.unsupported",
+		Other: "You can't vote in this poll by replying with a number. Please use the buttons of the poll.",
+	}
+	responseReplyVoteInvalidOption = &i18n.Message{
+		ID:    "response.replyVote.invalidOption",
+		Other: "There is no answer option {{.Number}}. Please reply with a number from 1 to {{.Max}}.",
+	}
+	responseReplyVoteInvalidScore = &i18n.Message{
+		ID:    "response.replyVote.invalidScore",
+		Other: "There is no score {{.Number}}. Please reply with a score from 0 to {{.MaxScore}}.",
+	}
+)
+
+// MessageHasBeenPosted lets users vote by replying to a poll with the number of an answer option, e.g. "2".
+// The numbers count the answer options in the order they are shown, starting at 1. Replies to NPS polls are the score itself, e.g. "0".
+// Messages in the direct channel with the bot create a poll step by step, see handleDraftMessage.
+// Failures are only logged, because the reply has already been posted.
+func (p *MatterpollPlugin) MessageHasBeenPosted(_ *plugin.Context, post *model.Post) {
+	if post.UserId == p.botUserID || post.IsSystemMessage() {
+		return
+	}
+	if post.RootId == "" {
+		p.handleDraftMessage(post)
+		return
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(post.Message))
+	if err != nil {
+		return
+	}
+
+	repliedPoll, err := p.getPollByPostID(post.ChannelId, post.RootId)
+	if err != nil {
+		p.API.LogWarn("failed to get poll for reply", "error", err.Error())
+		return
+	}
+	if repliedPoll == nil {
+		return
+	}
+
+	msg, err := p.replyVote(repliedPoll, post, number)
+	if err != nil {
+		p.API.LogWarn("failed to vote by reply", "error", err.Error())
+	}
+	if msg != nil {
+		userLocalizer := p.getUserLocalizer(post.UserId)
+		p.SendEphemeralPost(post.ChannelId, post.UserId, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: msg,
+			TemplateData: map[string]interface{}{
+				"Number":   number,
+				"Max":      len(repliedPoll.AnswerOptions),
+				"MaxScore": poll.NPSMaxScore,
+			},
+		}))
+	}
+}
+
+// replyVote casts the vote of a reply to a given poll for the answer option with a given number.
+// Replies to anonymous and secret polls get deleted, so they don't reveal the vote. Other replies get a reaction once the vote is cast.
+// It returns the message for the user, which is nil if the user has already been notified.
+func (p *MatterpollPlugin) replyVote(repliedPoll *poll.Poll, reply *model.Post, number int) (*i18n.Message, error) {
+	// The order of polls that are shuffled every time can't be known by the voter
+	if len(repliedPoll.Questions) > 0 || repliedPoll.Settings.Shuffle == poll.ShuffleAlways ||
+		repliedPoll.Settings.VoteMode == poll.VoteModeRanked || repliedPoll.Settings.VoteMode == poll.VoteModeRating ||
+		repliedPoll.Settings.VoteMode == poll.VoteModeEstimate {
+		return responseReplyVoteUnsupported, nil
+	}
+	order := repliedPoll.DisplayOrder()
+	index := number - 1
+	if repliedPoll.IsNPS() {
+		// The answer options of NPS polls are the scores from 0 upwards, so voters reply with the score they pick
+		index = number
+		if index < 0 || index >= len(order) {
+			return responseReplyVoteInvalidScore, nil
+		}
+	}
+	if index < 0 || index >= len(order) {
+		return responseReplyVoteInvalidOption, nil
+	}
+
+	hidden := repliedPoll.Settings.Anonymous || repliedPoll.Settings.Secret
+	if hidden {
+		if appErr := p.API.DeletePost(reply.Id); appErr != nil {
+			return commandErrorGeneric, errors.Wrap(appErr, "failed to delete reply")
+		}
+	}
+
+	msg, attachments, err := p.vote(repliedPoll.ID, reply.ChannelId, reply.UserId, order[index])
+	if err != nil {
+		return msg, err
+	}
+	if attachments != nil {
+		post, appErr := p.API.GetPost(repliedPoll.PostID)
+		if appErr != nil {
+			return commandErrorGeneric, errors.Wrap(appErr, "failed to get post")
+		}
+		model.ParseSlackAttachment(post, attachments)
+		if _, appErr = p.API.UpdatePost(post); appErr != nil {
+			return commandErrorGeneric, errors.Wrap(appErr, "failed to update post")
+		}
+	}
+
+	if !hidden && isVoteCastMessage(msg) {
+		reaction := &model.Reaction{
+			UserId:    p.botUserID,
+			PostId:    reply.Id,
+			EmojiName: replyVoteReaction,
+		}
+		if _, appErr := p.API.AddReaction(reaction); appErr != nil {
+			p.API.LogWarn("failed to react to reply", "error", appErr.Error())
+		}
+	}
+	return msg, nil
+}
+
+// isVoteCastMessage checks if a message returned by vote means that the vote got cast.
+// vote returns no message if it has already notified the user about the cast vote.
+func isVoteCastMessage(msg *i18n.Message) bool {
+	return msg == nil || msg == responseVoteCounted || msg == responseVoteUpdated || msg == responseVoteRemoved
+}
+
+// getPollByPostID returns the running poll of a given channel that is displayed by a given post.
+// It returns nil if the post doesn't display a running poll.
+func (p *MatterpollPlugin) getPollByPostID(channelID, postID string) (*poll.Poll, error) {
+	polls, err := p.Store.Poll().ListByChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	for _, channelPoll := range polls {
+		if channelPoll.PostID == postID && !channelPoll.IsEnded() && !channelPoll.IsDeleted() {
+			return channelPoll, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,250 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageHasBeenPosted(t *testing.T) {
+	userID := "userID5"
+	channelID := "channelID1"
+	postID := "postID1"
+	replyID := "replyID1"
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	getRepliedPoll := func(settings poll.Settings) *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(settings)
+		p.PostID = postID
+		p.ChannelID = channelID
+		return p
+	}
+	getExpectedPost := func(settings poll.Settings) *model.Post {
+		pollOut := getRepliedPoll(settings)
+		require.Nil(t, pollOut.UpdateVote(userID, 1))
+		post := &model.Post{}
+		model.ParseSlackAttachment(post, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+		return post
+	}
+	ephemeralPost := func(message string) *model.Post {
+		return &model.Post{
+			ChannelId: channelID,
+			UserId:    testutils.GetBotUserID(),
+			Message:   message,
+		}
+	}
+	reply := func(message string) *model.Post {
+		return &model.Post{Id: replyID, ChannelId: channelID, RootId: postID, UserId: userID, Message: message}
+	}
+	getRepliedNPSPoll := func() *poll.Poll {
+		p := testutils.GetNPSPollWithVotes()
+		p.PostID = postID
+		p.ChannelID = channelID
+		return p
+	}
+	rankedSettings := poll.Settings{VoteMode: poll.VoteModeRanked}
+	anonymousSettings := poll.Settings{Anonymous: true}
+
+	for name, test := range map[string]struct {
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+		Post       *model.Post
+	}{
+		"Vote by reply": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", getExpectedPost(poll.Settings{})).Return(getExpectedPost(poll.Settings{}), nil)
+				api.On("AddReaction", &model.Reaction{UserId: testutils.GetBotUserID(), PostId: replyID, EmojiName: replyVoteReaction}).Return(nil, nil)
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost(responseVoteCounted.Other)).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedPoll(poll.Settings{})}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(getRepliedPoll(poll.Settings{})))
+				return store
+			},
+			Post: reply(" 2 "),
+		},
+		"Vote by reply, anonymous poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", replyID).Return(nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", getExpectedPost(anonymousSettings)).Return(getExpectedPost(anonymousSettings), nil)
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost(responseVoteCounted.Other)).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedPoll(anonymousSettings)}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(getRepliedPoll(anonymousSettings)))
+				return store
+			},
+			Post: reply("2"),
+		},
+		"Vote by reply, AddReaction fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", getExpectedPost(poll.Settings{})).Return(getExpectedPost(poll.Settings{}), nil)
+				api.On("AddReaction", &model.Reaction{UserId: testutils.GetBotUserID(), PostId: replyID, EmojiName: replyVoteReaction}).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost(responseVoteCounted.Other)).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedPoll(poll.Settings{})}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(getRepliedPoll(poll.Settings{})))
+				return store
+			},
+			Post: reply("2"),
+		},
+		"Vote by reply, GetPost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost(commandErrorGeneric.Other)).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedPoll(poll.Settings{})}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(getRepliedPoll(poll.Settings{})))
+				return store
+			},
+			Post: reply("2"),
+		},
+		"Vote by reply, poll has ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedPoll := getRepliedPoll(poll.Settings{})
+				endedPoll.End()
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{endedPoll}, nil)
+				return store
+			},
+			Post: reply("2"),
+		},
+		"Invalid option number": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost("There is no answer option 4. Please reply with a number from 1 to 3.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedPoll(poll.Settings{})}, nil)
+				return store
+			},
+			Post: reply("4"),
+		},
+		"Vote by reply, NPS poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				pollOut := getRepliedNPSPoll()
+				require.Nil(t, pollOut.UpdateVote(userID, 0))
+				expectedPost := &model.Post{}
+				model.ParseSlackAttachment(expectedPost, pollOut.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+				api.On("DeletePost", replyID).Return(nil)
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("GetPost", postID).Return(&model.Post{}, nil)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost(responseVoteCounted.Other)).Return(nil)
+				api.On("PublishWebSocketEvent", websocketEventPollUpdated, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedNPSPoll()}, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(getRepliedNPSPoll()))
+				return store
+			},
+			Post: reply("0"),
+		},
+		"Invalid NPS score": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost("There is no score 11. Please reply with a score from 0 to 10.")).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedNPSPoll()}, nil)
+				return store
+			},
+			Post: reply("11"),
+		},
+		"Ranked poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", userID).Return(&model.User{Username: "user5"}, nil)
+				api.On("SendEphemeralPost", userID, ephemeralPost(responseReplyVoteUnsupported.Other)).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedPoll(rankedSettings)}, nil)
+				return store
+			},
+			Post: reply("2"),
+		},
+		"Reply to another post": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return([]*poll.Poll{getRepliedPoll(poll.Settings{})}, nil)
+				return store
+			},
+			Post: &model.Post{Id: replyID, ChannelId: channelID, RootId: "postID2", UserId: userID, Message: "2"},
+		},
+		"ListByChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("ListByChannel", channelID).Return(nil, errors.New(""))
+				return store
+			},
+			Post: reply("2"),
+		},
+		"Reply is no number": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Post:       reply("2 is the best answer"),
+		},
+		"Post is no reply": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, Type: model.CHANNEL_OPEN}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Post:       &model.Post{Id: replyID, ChannelId: channelID, UserId: userID, Message: "2"},
+		},
+		"Reply by the bot": {
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+			Post:       &model.Post{Id: replyID, ChannelId: channelID, RootId: postID, UserId: testutils.GetBotUserID(), Message: "2"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			store.VoteStore.On("Record", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			p.MessageHasBeenPosted(nil, test.Post)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	commandHelpTextRestore = &i18n.Message{
+		ID:    "command.help.text.restore",
+		Other: "To restore a deleted poll before it gets removed for good, type `/{{.Trigger}} restore <poll ID>`",
+	}
+	commandErrorRestoreUsage = &i18n.Message{
+		ID:    "command.error.restore.usage",
+		Other: "Usage: `/{{.Trigger}} restore <poll ID>`",
+	}
+
+	deletedPollMessage = &i18n.Message{
+		ID:    "poll.message.deleted",
+		Other: "_This poll has been deleted._",
+	}
+
+	responseRestorePollSuccess = &i18n.Message{
+		ID:    "response.restorePoll.success",
+		Other: "Successfully restored the poll.",
+	}
+	responseRestorePollInvalidPermission = &i18n.Message{
+		ID:    "response.restorePoll.invalidPermission",
+		Other: "Only the creator of a poll and System Admins are allowed to restore it.",
+	}
+	responseRestorePollNotDeleted = &i18n.Message{
+		ID:    "response.restorePoll.notDeleted",
+		Other: "The poll hasn't been deleted.",
+	}
+)
+
+// softDeletePoll marks a poll as deleted and replaces its posts with a note, which keeps the replies in their threads.
+// The poll can be restored until the purge job removes it together with its posts once the grace period is over.
+func (p *MatterpollPlugin) softDeletePoll(pollToDelete *poll.Poll, postID string) error {
+	for _, deletedPostID := range append([]string{postID}, otherPollPosts(pollToDelete, postID)...) {
+		post, appErr := p.API.GetPost(deletedPostID)
+		if appErr != nil {
+			return errors.Wrap(appErr, "failed to get post")
+		}
+		post.Message = p.LocalizeDefaultMessage(p.getPublicLocalizer(), deletedPollMessage)
+		post.DelProp("attachments")
+		if _, appErr := p.API.UpdatePost(post); appErr != nil {
+			return errors.Wrap(appErr, "failed to update post")
+		}
+	}
+
+	deletedPoll, err := p.Store.Poll().Update(pollToDelete.ID, func(latest *poll.Poll) error {
+		latest.MarkDeleted()
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to mark poll as deleted")
+	}
+	if err := p.Store.Job().Save(p.newPurgeJob(deletedPoll)); err != nil {
+		return errors.Wrap(err, "failed to schedule poll purge")
+	}
+	return nil
+}
+
+// newPurgeJob returns the job that removes a given deleted poll once the configured grace period is over
+func (p *MatterpollPlugin) newPurgeJob(deletedPoll *poll.Poll) *job.Job {
+	gracePeriod := time.Duration(p.getConfiguration().deleteGracePeriodHours) * time.Hour
+	return job.NewJob(job.TypePurgePoll, deletedPoll.ID, deletedPoll.DeletedAt+int64(gracePeriod/time.Millisecond))
+}
+
+// purgePoll removes a deleted poll together with its posts. Polls that got restored in the meantime are kept.
+func (p *MatterpollPlugin) purgePoll(pollID string) error {
+	deletedPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get poll")
+	}
+	if !deletedPoll.IsDeleted() {
+		return nil
+	}
+
+	if appErr := p.API.DeletePost(deletedPoll.PostID); appErr != nil {
+		return errors.Wrap(appErr, "failed to delete post")
+	}
+	for _, crossPostID := range otherPollPosts(deletedPoll, deletedPoll.PostID) {
+		if appErr := p.API.DeletePost(crossPostID); appErr != nil {
+			p.API.LogWarn("failed to delete cross post", "pollID", deletedPoll.ID, "error", appErr.Error())
+		}
+	}
+	if err := p.Store.Poll().Delete(deletedPoll); err != nil {
+		return errors.Wrap(err, "failed to delete poll")
+	}
+	return nil
+}
+
+// executeRestoreCommand restores the deleted poll with the ID given in params
+func (p *MatterpollPlugin) executeRestoreCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+
+	if len(params) != 1 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorRestoreUsage,
+			TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger},
+		}), nil
+	}
+
+	msg, err := p.restorePollByID(params[0], args.UserId)
+	if err != nil {
+		p.API.LogError("failed to restore poll", "err", err.Error())
+	}
+	return p.LocalizeDefaultMessage(userLocalizer, msg), nil
+}
+
+// restorePollByID brings back a deleted poll on behalf of a given user. It shows the poll in its post again and resumes its jobs.
+func (p *MatterpollPlugin) restorePollByID(pollID, userID string) (*i18n.Message, error) {
+	deletedPoll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to get poll")
+	}
+
+	hasPermission, appErr := p.HasPermission(deletedPoll, userID)
+	if appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to check permission")
+	}
+	if !hasPermission {
+		return responseRestorePollInvalidPermission, nil
+	}
+	if !deletedPoll.IsDeleted() {
+		return responseRestorePollNotDeleted, nil
+	}
+
+	restoredPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if !latest.IsDeleted() {
+			return errors.New("poll isn't deleted")
+		}
+		latest.Restore()
+		return nil
+	})
+	if err != nil {
+		return commandErrorGeneric, errors.Wrap(err, "failed to restore poll")
+	}
+	if err := p.Store.Job().Delete(p.newPurgeJob(deletedPoll)); err != nil {
+		p.API.LogWarn("failed to unschedule poll purge", "error", err.Error())
+	}
+	p.recordAudit(audit.ActionPollRestored, restoredPoll, userID, "")
+
+	if appErr := p.updatePollPost(restoredPoll); appErr != nil {
+		return commandErrorGeneric, errors.Wrap(appErr, "failed to update poll post")
+	}
+
+	// Deleting the poll stopped its jobs. A deadline that passed in the meantime ends the poll right away.
+	if !restoredPoll.IsEnded() {
+		if err := p.scheduleEnd(restoredPoll); err != nil {
+			p.API.LogWarn("failed to schedule poll end", "error", err.Error())
+		}
+		if err := p.scheduleDigest(restoredPoll); err != nil {
+			p.API.LogWarn("failed to schedule poll digest", "error", err.Error())
+		}
+	}
+	// The recurrence only resumes if its next instance isn't due yet, so a missed instance isn't posted late
+	if restoredPoll.IsRecurring() && restoredPoll.Settings.Repeat.Next(restoredPoll.StartAt()) > model.GetMillis() {
+		if err := p.scheduleRepeat(restoredPoll); err != nil {
+			p.API.LogWarn("failed to schedule poll recurrence", "error", err.Error())
+		}
+	}
+	return responseRestorePollSuccess, nil
+}
@@ -0,0 +1,316 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDeletePollWithGracePeriod(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	purgeJob := job.NewJob(job.TypePurgePoll, testutils.GetPollID(), now+int64(24*time.Hour/time.Millisecond))
+
+	for name, test := range map[string]struct {
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store, *poll.Poll) *mockstore.Store
+		ExpectedResponse string
+		ExpectedDeleted  bool
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", Props: model.StringInterface{"attachments": []*model.SlackAttachment{}}}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.Message == deletedPollMessage.Other && post.GetProp("attachments") == nil
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, deletedPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(deletedPoll))
+				store.JobStore.On("Save", purgeJob).Return(nil)
+				return store
+			},
+			ExpectedResponse: "Successfully deleted the poll. Until it gets removed for good, you can restore it by typing `/poll restore " + testutils.GetPollID() + "`.",
+			ExpectedDeleted:  true,
+		},
+		"GetPost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore:       func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedResponse: commandErrorGeneric.Other,
+		},
+		"Store.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			ExpectedResponse: commandErrorGeneric.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			deletedPoll := testutils.GetPoll()
+			deletedPoll.PostID = "postID1"
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll.Copy(), nil)
+			store = test.SetupStore(store, deletedPoll)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.setConfiguration(&configuration{Trigger: "poll", deleteGracePeriodHours: 24})
+
+			request := &model.PostActionIntegrationRequest{UserId: "userID1", PostId: "postID1"}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/delete", testutils.GetPollID()), bytes.NewReader(request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, http.StatusOK, result.StatusCode)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+			require.NotNil(t, response)
+			assert.Equal(t, test.ExpectedResponse, response.EphemeralText)
+			assert.Equal(t, test.ExpectedDeleted, deletedPoll.IsDeleted())
+		})
+	}
+}
+
+func TestPurgePoll(t *testing.T) {
+	deletedPoll := testutils.GetPoll()
+	deletedPoll.PostID = "postID1"
+	deletedPoll.DeletedAt = 1234567890
+	restoredPoll := testutils.GetPoll()
+	restoredPoll.PostID = "postID1"
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		ShouldError bool
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", "postID1").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll, nil)
+				store.PollStore.On("Delete", deletedPoll).Return(nil)
+				return store
+			},
+		},
+		"Poll got restored": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(restoredPoll, nil)
+				return store
+			},
+		},
+		"Store.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+		"DeletePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", "postID1").Return(&model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll, nil)
+				return store
+			},
+			ShouldError: true,
+		},
+		"Store.Delete fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("DeletePost", "postID1").Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(deletedPoll, nil)
+				store.PollStore.On("Delete", deletedPoll).Return(errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			err := p.purgePoll(testutils.GetPollID())
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestRestorePollByID(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+	deletedAt := now - 1000
+	purgeJob := job.NewJob(job.TypePurgePoll, testutils.GetPollID(), deletedAt+int64(24*time.Hour/time.Millisecond))
+	deleted := func(p *poll.Poll) *poll.Poll {
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		p.DeletedAt = deletedAt
+		return p
+	}
+
+	for name, test := range map[string]struct {
+		Poll             *poll.Poll
+		UserID           string
+		SetupAPI         func(*plugintest.API) *plugintest.API
+		SetupStore       func(*mockstore.Store, *poll.Poll) *mockstore.Store
+		ExpectedMessage  string
+		ExpectedRestored bool
+		ShouldError      bool
+	}{
+		"all fine": {
+			Poll:   deleted(testutils.GetPoll()),
+			UserID: "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1", Message: deletedPollMessage.Other}, nil)
+				api.On("UpdatePost", mock.MatchedBy(func(post *model.Post) bool {
+					return post.Id == "postID1" && post.Message == "" && post.GetProp("attachments") != nil
+				})).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, restoredPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(restoredPoll))
+				store.JobStore.On("Delete", purgeJob).Return(nil)
+				return store
+			},
+			ExpectedMessage:  responseRestorePollSuccess.Other,
+			ExpectedRestored: true,
+		},
+		"poll with deadline": {
+			Poll:   deleted(testutils.GetPollWithSettings(poll.Settings{EndAt: now + 1000})),
+			UserID: "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, restoredPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(restoredPoll))
+				store.JobStore.On("Delete", purgeJob).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeEndPoll, testutils.GetPollID(), now+1000)).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), now+1000)).Return(nil)
+				return store
+			},
+			ExpectedMessage:  responseRestorePollSuccess.Other,
+			ExpectedRestored: true,
+		},
+		"poll isn't deleted": {
+			Poll:             testutils.GetPoll(),
+			UserID:           "userID1",
+			SetupAPI:         func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:       func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage:  responseRestorePollNotDeleted.Other,
+			ExpectedRestored: true,
+		},
+		"invalid permission": {
+			Poll:   deleted(testutils.GetPoll()),
+			UserID: "userID2",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Username: "user2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:      func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store { return store },
+			ExpectedMessage: responseRestorePollInvalidPermission.Other,
+		},
+		"Store.Update fails": {
+			Poll:     deleted(testutils.GetPoll()),
+			UserID:   "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store, _ *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			ExpectedMessage: commandErrorGeneric.Other,
+			ShouldError:     true,
+		},
+		"UpdatePost fails": {
+			Poll:   deleted(testutils.GetPoll()),
+			UserID: "userID1",
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetPost", "postID1").Return(&model.Post{Id: "postID1"}, nil)
+				api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store, restoredPoll *poll.Poll) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(restoredPoll))
+				store.JobStore.On("Delete", purgeJob).Return(nil)
+				return store
+			},
+			ExpectedMessage:  commandErrorGeneric.Other,
+			ExpectedRestored: true,
+			ShouldError:      true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil).Maybe()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := &mockstore.Store{}
+			store.PollStore.On("Get", testutils.GetPollID()).Return(test.Poll.Copy(), nil)
+			store = test.SetupStore(store, test.Poll)
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.setConfiguration(&configuration{Trigger: "poll", deleteGracePeriodHours: 24})
+
+			msg, err := p.restorePollByID(testutils.GetPollID(), test.UserID)
+			assert.Equal(t, test.ExpectedMessage, msg.Other)
+			assert.Equal(t, test.ExpectedRestored, !test.Poll.IsDeleted())
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	responseShowResultsNotVoted = &i18n.Message{
+		ID:    "response.showResults.notVoted",
+		Other: "Vote first to see the current results.",
+	}
+	showResultsMessage = &i18n.Message{
+		ID:    "showResults.message",
+		Other: "These are the current results of **{{.Question}}**:",
+	}
+)
+
+// handleShowResults sends the current results of a poll with vote-to-see as ephemeral post to a user who has voted
+func (p *MatterpollPlugin) handleShowResults(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	currentPoll, err := p.Store.Poll().Get(vars["id"])
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+	if !currentPoll.Settings.VoteToSee {
+		return commandErrorGeneric, nil, errors.New("poll doesn't have vote-to-see")
+	}
+	if !currentPoll.HasVoted(request.UserId) {
+		return responseShowResultsNotVoted, nil, nil
+	}
+
+	// The ephemeral response can't carry the results, because they aren't part of a localized message
+	p.sendResults(currentPoll, request.ChannelId, request.UserId)
+	return nil, nil, nil
+}
+
+// sendResultsIfVoteToSee sends a given user the current results of a poll with vote-to-see, if the user has voted.
+// Voters that took back their vote don't get the results.
+func (p *MatterpollPlugin) sendResultsIfVoteToSee(votedPoll *poll.Poll, userID string) {
+	if !votedPoll.Settings.VoteToSee || !votedPoll.HasVoted(userID) {
+		return
+	}
+	p.sendResults(votedPoll, votedPoll.ChannelID, userID)
+}
+
+// sendResults sends the current standings of a poll to a user as ephemeral post
+func (p *MatterpollPlugin) sendResults(currentPoll *poll.Poll, channelID, userID string) {
+	userLocalizer := p.getUserLocalizer(userID)
+	message := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: showResultsMessage,
+		TemplateData:   map[string]interface{}{"Question": currentPoll.Question},
+	})
+	p.SendEphemeralPost(channelID, userID, message+"\n"+currentPoll.ToStandings(userLocalizer))
+}
@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleShowResults(t *testing.T) {
+	voteToSeePoll := func() *poll.Poll {
+		return testutils.GetPollWithVotesAndSettings(poll.Settings{VoteToSee: true})
+	}
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "channelID1",
+		Message:   "These are the current results of **Question**:\n1. Answer 1: 3 votes (75%)\n2. Answer 2: 1 vote (25%)\n3. Answer 3: 0 votes (0%)",
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("SendEphemeralPost", "userID1", expectedPost).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(voteToSeePoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, user hasn't voted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID5").Return(&model.User{Username: "user5"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(voteToSeePoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID5", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: responseShowResultsNotVoted.Other},
+		},
+		"Valid request, poll without vote-to-see": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/results", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
+
+func TestSendResultsIfVoteToSee(t *testing.T) {
+	t.Run("voter gets the results", func(t *testing.T) {
+		votedPoll := testutils.GetPollWithVotesAndSettings(poll.Settings{VoteToSee: true})
+		votedPoll.ChannelID = "channelID1"
+
+		api := &plugintest.API{}
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		api.On("SendEphemeralPost", "userID4", &model.Post{
+			UserId:    testutils.GetBotUserID(),
+			ChannelId: "channelID1",
+			Message:   "These are the current results of **Question**:\n1. Answer 1: 3 votes (75%)\n2. Answer 2: 1 vote (25%)\n3. Answer 3: 0 votes (0%)",
+		}).Return(nil)
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		p.sendResultsIfVoteToSee(votedPoll, "userID4")
+	})
+	t.Run("user took back the vote", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		p.sendResultsIfVoteToSee(testutils.GetPollWithSettings(poll.Settings{VoteToSee: true}), "userID4")
+	})
+	t.Run("poll without vote-to-see", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		p.sendResultsIfVoteToSee(testutils.GetPollWithVotes(), "userID4")
+	})
+}
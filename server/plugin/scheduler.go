@@ -0,0 +1,299 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/pkg/errors"
+)
+
+const (
+	// schedulerInterval is the time between two checks for due jobs
+	schedulerInterval = 30 * time.Second
+	// schedulerLease is the time for which a plugin instance leads the scheduler.
+	// It spans several intervals, so the leader keeps the lead even if a run takes longer than an interval.
+	schedulerLease = 3 * schedulerInterval
+)
+
+// startScheduler periodically runs all due jobs until stopScheduler gets called.
+// Jobs are persisted in the store, hence jobs that became due while the plugin was disabled run right after activation.
+// In a cluster only the plugin instance that leads the scheduler runs the jobs. It resigns once the scheduler stops.
+func (p *MatterpollPlugin) startScheduler() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	p.schedulerStop = stop
+	p.schedulerDone = done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		p.runDueJobsIfLeader()
+		for {
+			select {
+			case <-ticker.C:
+				p.runDueJobsIfLeader()
+			case <-stop:
+				if err := p.Store.Leader().Resign(p.instanceID); err != nil {
+					p.API.LogWarn("failed to resign as scheduler leader", "error", err.Error())
+				}
+				return
+			}
+		}
+	}()
+}
+
+// stopScheduler stops the scheduler and waits for running jobs to finish
+func (p *MatterpollPlugin) stopScheduler() {
+	if p.schedulerStop == nil {
+		return
+	}
+	close(p.schedulerStop)
+	<-p.schedulerDone
+	p.schedulerStop = nil
+	p.schedulerDone = nil
+}
+
+// runDueJobsIfLeader runs all due jobs if this plugin instance leads the scheduler, so the other plugin instances of a cluster don't list the jobs as well.
+// If the leader goes away without resigning, another plugin instance takes over once the lease has expired.
+func (p *MatterpollPlugin) runDueJobsIfLeader() {
+	leads, err := p.Store.Leader().Lead(p.instanceID, schedulerLease)
+	if err != nil {
+		p.API.LogWarn("failed to lead scheduler", "error", err.Error())
+		return
+	}
+	if leads {
+		p.runDueJobs()
+	}
+}
+
+// runDueJobs runs all jobs whose time has come and removes them from the store. Recurring jobs get replaced by their next run.
+// Jobs that fail are removed as well to not retry them forever.
+// A job is claimed before it runs to make sure it runs only once, even if the lead changes while a previous leader still runs jobs.
+func (p *MatterpollPlugin) runDueJobs() {
+	jobs, err := p.Store.Job().List()
+	if err != nil {
+		p.API.LogWarn("failed to list scheduled jobs", "error", err.Error())
+		return
+	}
+
+	now := model.GetMillis()
+	for _, j := range jobs {
+		if !j.IsDue(now) || j.IsClaimed(now) {
+			continue
+		}
+		claimed, err := p.Store.Job().Claim(j)
+		if err != nil {
+			p.API.LogWarn("failed to claim scheduled job", "jobID", j.ID, "error", err.Error())
+			continue
+		}
+		if !claimed {
+			// Another plugin instance runs the job
+			continue
+		}
+		next, err := p.runJob(j)
+		if err != nil {
+			p.API.LogWarn("failed to run scheduled job", "jobID", j.ID, "error", err.Error())
+		}
+		if next != nil {
+			// The next run has the same ID, hence saving it replaces the job that just ran
+			if err := p.Store.Job().Save(next); err != nil {
+				p.API.LogWarn("failed to reschedule job", "jobID", j.ID, "error", err.Error())
+			}
+			continue
+		}
+		if err := p.Store.Job().Delete(j); err != nil {
+			p.API.LogWarn("failed to delete scheduled job", "jobID", j.ID, "error", err.Error())
+		}
+	}
+}
+
+// runJob executes a given job. It returns the next run of recurring jobs, which is nil once they are done.
+func (p *MatterpollPlugin) runJob(j *job.Job) (*job.Job, error) {
+	switch j.Type {
+	case job.TypeEndPoll:
+		return nil, p.endPollByDeadline(j.PollID)
+	case job.TypePostPoll:
+		return nil, p.postScheduledPoll(j.PollID)
+	case job.TypeRepeatPoll:
+		return nil, p.repeatPoll(j.PollID, j.RunAt)
+	case job.TypeRemindDeadline:
+		return nil, p.remindDeadline(j.PollID)
+	case job.TypeSendDigest:
+		return p.sendDigest(j)
+	case job.TypeArchivePolls:
+		return p.archivePolls(j)
+	case job.TypeSendTelemetry:
+		return p.sendTelemetry(j)
+	case job.TypePurgePoll:
+		return nil, p.purgePoll(j.PollID)
+	case job.TypeEndArchivedPolls:
+		return p.endArchivedPolls(j)
+	case job.TypeRefreshCountdown:
+		return p.refreshCountdown(j)
+	default:
+		return nil, fmt.Errorf("unknown job type %s", j.Type)
+	}
+}
+
+// scheduleEnd stores a job that ends a given poll at its deadline together with the reminder before it and the refresh of its countdown.
+// Polls without a deadline are ignored.
+func (p *MatterpollPlugin) scheduleEnd(poll *poll.Poll) error {
+	if !poll.HasDeadline() {
+		return nil
+	}
+	if err := p.Store.Job().Save(job.NewJob(job.TypeEndPoll, poll.ID, poll.Settings.EndAt)); err != nil {
+		return err
+	}
+	if err := p.scheduleDeadlineReminder(poll); err != nil {
+		return err
+	}
+	return p.scheduleCountdownRefresh(poll)
+}
+
+// unscheduleEnd removes the job that ends a given poll at its deadline together with the reminder before it and the refresh of its countdown.
+// Polls without a deadline are ignored.
+func (p *MatterpollPlugin) unscheduleEnd(poll *poll.Poll) error {
+	if !poll.HasDeadline() {
+		return nil
+	}
+	if err := p.Store.Job().Delete(job.NewJob(job.TypeEndPoll, poll.ID, poll.Settings.EndAt)); err != nil {
+		return err
+	}
+	if err := p.unscheduleDeadlineReminder(poll); err != nil {
+		return err
+	}
+	return p.unscheduleCountdownRefresh(poll)
+}
+
+// schedulePost stores a job that posts a given scheduled poll
+func (p *MatterpollPlugin) schedulePost(poll *poll.Poll) error {
+	return p.Store.Job().Save(job.NewJob(job.TypePostPoll, poll.ID, poll.Settings.PostAt))
+}
+
+// unschedulePost removes the job that posts a given scheduled poll
+func (p *MatterpollPlugin) unschedulePost(poll *poll.Poll) error {
+	return p.Store.Job().Delete(job.NewJob(job.TypePostPoll, poll.ID, poll.Settings.PostAt))
+}
+
+// scheduleRepeat stores a job that posts the next instance of a given poll. Polls without recurrence are ignored.
+func (p *MatterpollPlugin) scheduleRepeat(poll *poll.Poll) error {
+	if !poll.IsRecurring() {
+		return nil
+	}
+	return p.Store.Job().Save(job.NewJob(job.TypeRepeatPoll, poll.ID, poll.Settings.Repeat.Next(poll.StartAt())))
+}
+
+// unscheduleRepeat removes the job that posts the next instance of a given poll. Polls without recurrence are ignored.
+func (p *MatterpollPlugin) unscheduleRepeat(poll *poll.Poll) error {
+	if !poll.IsRecurring() {
+		return nil
+	}
+	return p.Store.Job().Delete(job.NewJob(job.TypeRepeatPoll, poll.ID, poll.Settings.Repeat.Next(poll.StartAt())))
+}
+
+// repeatPoll ends the current instance of a recurring poll and posts the next one at a given time in milliseconds
+func (p *MatterpollPlugin) repeatPoll(pollID string, postAt int64) error {
+	previous, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get poll")
+	}
+
+	if !previous.IsEnded() {
+		// Failing to end the previous instance shouldn't break the recurrence
+		if err := p.endPoll(previous.ID, ""); err != nil {
+			p.API.LogWarn("failed to end previous instance of recurring poll", "pollID", previous.ID, "error", err.Error())
+		} else if err := p.unscheduleEnd(previous); err != nil {
+			p.API.LogWarn("failed to unschedule poll end", "pollID", previous.ID, "error", err.Error())
+		}
+	}
+
+	next := previous.NextInstance(postAt)
+	if err := p.Store.Poll().Save(next); err != nil {
+		return errors.Wrap(err, "failed to save poll")
+	}
+	return p.publishPoll(next, next.ChannelID, next.RootID)
+}
+
+// postScheduledPoll posts a scheduled poll into the channel it was created in
+func (p *MatterpollPlugin) postScheduledPoll(pollID string) error {
+	poll, err := p.Store.Poll().Get(pollID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get poll")
+	}
+	if !poll.IsScheduled() {
+		return errors.New("poll is not scheduled")
+	}
+
+	return p.publishPoll(poll, poll.ChannelID, poll.RootID)
+}
+
+// endPollByDeadline ends a poll whose deadline has passed
+func (p *MatterpollPlugin) endPollByDeadline(pollID string) error {
+	return p.endPoll(pollID, "")
+}
+
+// endPollIfAllVoted ends a poll once all eligible voters have voted. It returns true if the poll got ended.
+func (p *MatterpollPlugin) endPollIfAllVoted(votedPoll *poll.Poll) bool {
+	if !votedPoll.HaveAllEligibleVotersVoted() {
+		return false
+	}
+	if err := p.endPoll(votedPoll.ID, ""); err != nil {
+		p.API.LogWarn("failed to end poll after all eligible voters voted", "error", err.Error())
+		return false
+	}
+	if err := p.unscheduleEnd(votedPoll); err != nil {
+		p.API.LogWarn("failed to unschedule poll end", "error", err.Error())
+	}
+	return true
+}
+
+// endPoll ends a poll outside of its post, either by a job or by a given user. It updates the poll post and announces the results.
+// userID is empty if the poll isn't ended by a user.
+func (p *MatterpollPlugin) endPoll(pollID, userID string) error {
+	// End the latest version of the poll, so votes cast in the meantime are part of the results.
+	// A poll that ended in the meantime isn't announced twice.
+	endedPoll, err := p.Store.Poll().Update(pollID, func(latest *poll.Poll) error {
+		if latest.IsEnded() {
+			return errors.New("poll has already ended")
+		}
+		return p.endLatestPoll(latest)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to end poll")
+	}
+	p.publishPollEvent(websocketEventPollEnded, endedPoll)
+	p.notifyWebhook(webhookEventPollEnded, endedPoll, userID)
+	p.recordAudit(audit.ActionPollEnded, endedPoll, userID, "")
+
+	displayName, appErr := p.ConvertCreatorIDToDisplayName(endedPoll.Creator)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get display name for creator")
+	}
+
+	post, appErr := endedPoll.ToEndPollPost(p.getPublicLocalizer(), *p.ServerConfig.ServiceSettings.SiteURL, manifest.ID, displayName, p.ConvertUserIDToDisplayName)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get convert to end poll post")
+	}
+	post.Id = endedPoll.PostID
+	post.ChannelId = endedPoll.ChannelID
+
+	// The post of an ended poll isn't pinned, so updating it also unpins pinned polls
+	if _, appErr = p.API.UpdatePost(post); appErr != nil {
+		return errors.Wrap(appErr, "failed to update post")
+	}
+	p.updateCrossPosts(endedPoll)
+
+	channel, appErr := p.API.GetChannel(endedPoll.ChannelID)
+	if appErr != nil {
+		return errors.Wrap(appErr, "failed to get channel")
+	}
+	p.postEndPollAnnouncement(channel.TeamId, endedPoll.PostID, endedPoll)
+	return nil
+}
@@ -0,0 +1,742 @@
+package plugin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDueJobs(t *testing.T) {
+	dueJob := job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890)
+	pendingJob := job.NewJob(job.TypeEndPoll, "pollID2", 1234567891)
+	unknownJob := job.NewJob(job.Type("unknown"), testutils.GetPollID(), 1234567890)
+	claimedJob := job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890)
+	claimedJob.ClaimedAt = 1234567890
+	expiredJob := job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890)
+	expiredJob.ClaimedAt = 1234567890 - int64(job.ClaimTimeout/time.Millisecond)
+	digestJob := job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 1234567890)
+	nextDigestJob := job.NewJob(job.TypeSendDigest, testutils.GetPollID(), 1234567890+24*60*60*1000)
+
+	for name, test := range map[string]struct {
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+	}{
+		"No jobs": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{}, nil)
+				return store
+			},
+		},
+		"Job is not due": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{pendingJob}, nil)
+				return store
+			},
+		},
+		"Due job fails and gets deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{dueJob, pendingJob}, nil)
+				store.JobStore.On("Claim", dueJob).Return(true, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(nil, &model.AppError{})
+				store.JobStore.On("Delete", dueJob).Return(nil)
+				return store
+			},
+		},
+		"Recurring job fails and gets rescheduled": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{digestJob}, nil)
+				store.JobStore.On("Claim", digestJob).Return(true, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithSettings(poll.Settings{Digest: poll.RecurrenceDaily}), nil)
+				store.JobStore.On("Save", nextDigestJob).Return(nil)
+				return store
+			},
+		},
+		"Recurring job is done and gets deleted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedPoll := testutils.GetPollWithSettings(poll.Settings{Digest: poll.RecurrenceDaily})
+				endedPoll.EndedAt = 1234567890
+				store.JobStore.On("List").Return([]*job.Job{digestJob}, nil)
+				store.JobStore.On("Claim", digestJob).Return(true, nil)
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPoll, nil)
+				store.JobStore.On("Delete", digestJob).Return(nil)
+				return store
+			},
+		},
+		"Job is running on another plugin instance": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{claimedJob}, nil)
+				return store
+			},
+		},
+		"Job gets claimed by another plugin instance": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{dueJob}, nil)
+				store.JobStore.On("Claim", dueJob).Return(false, nil)
+				return store
+			},
+		},
+		"Job with expired claim": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{expiredJob}, nil)
+				store.JobStore.On("Claim", expiredJob).Return(true, nil)
+				store.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(nil, &model.AppError{})
+				store.JobStore.On("Delete", expiredJob).Return(nil)
+				return store
+			},
+		},
+		"JobStore.Claim fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{dueJob}, nil)
+				store.JobStore.On("Claim", dueJob).Return(false, &model.AppError{})
+				return store
+			},
+		},
+		"Unknown job type": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{unknownJob}, nil)
+				store.JobStore.On("Claim", unknownJob).Return(true, nil)
+				store.JobStore.On("Delete", unknownJob).Return(nil)
+				return store
+			},
+		},
+		"JobStore.List fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return(nil, &model.AppError{})
+				return store
+			},
+		},
+		"JobStore.Delete fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.JobStore.On("List").Return([]*job.Job{unknownJob}, nil)
+				store.JobStore.On("Claim", unknownJob).Return(true, nil)
+				store.JobStore.On("Delete", unknownJob).Return(&model.AppError{})
+				return store
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			defer patch.Unpatch()
+
+			p.runDueJobs()
+		})
+	}
+}
+
+func TestRunDueJobsIfLeader(t *testing.T) {
+	for name, test := range map[string]struct {
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+	}{
+		"Plugin instance leads": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.LeaderStore.On("Lead", "instanceID1", schedulerLease).Return(true, nil)
+				store.JobStore.On("List").Return([]*job.Job{}, nil)
+				return store
+			},
+		},
+		"Another plugin instance leads": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.LeaderStore.On("Lead", "instanceID1", schedulerLease).Return(false, nil)
+				return store
+			},
+		},
+		"LeaderStore.Lead fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.LeaderStore.On("Lead", "instanceID1", schedulerLease).Return(false, &model.AppError{})
+				return store
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.instanceID = "instanceID1"
+
+			p.runDueJobsIfLeader()
+		})
+	}
+}
+
+func TestStopScheduler(t *testing.T) {
+	t.Run("leader resigns", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.LeaderStore.On("Lead", "instanceID1", schedulerLease).Return(false, nil)
+		store.LeaderStore.On("Resign", "instanceID1").Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+		p.instanceID = "instanceID1"
+
+		p.startScheduler()
+		p.stopScheduler()
+		assert.Nil(t, p.schedulerStop)
+	})
+	t.Run("LeaderStore.Resign fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.LeaderStore.On("Lead", "instanceID1", schedulerLease).Return(false, nil)
+		store.LeaderStore.On("Resign", "instanceID1").Return(&model.AppError{})
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+		p.instanceID = "instanceID1"
+
+		p.startScheduler()
+		p.stopScheduler()
+	})
+}
+
+func TestRepeatPoll(t *testing.T) {
+	previousPoll := func() *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Repeat: poll.RecurrenceDaily})
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	endedPreviousPoll := previousPoll()
+	endedPreviousPoll.EndedAt = 1234567890
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	postAt := poll.RecurrenceDaily.Next(testutils.GetPoll().CreatedAt)
+	nextPoll := func() *poll.Poll {
+		p := testutils.GetPollWithSettings(poll.Settings{Repeat: poll.RecurrenceDaily, PostAt: postAt})
+		p.ID = "pollID2"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	postedNextPoll := nextPoll()
+	postedNextPoll.PostID = "postID2"
+
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + strings.Replace(userID, "userID", "user", 1), nil
+	}
+	expectedEndPost, appErr := previousPoll().ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe", converter)
+	require.Nil(t, appErr)
+	expectedEndPost.Id = "postID1"
+	expectedEndPost.ChannelId = "channelID1"
+
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "channelID1",
+		Type:      model.POST_DEFAULT,
+	}
+	model.ParseSlackAttachment(expectedPost, nextPoll().ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	setupUsers := func(api *plugintest.API) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		return api
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		ShouldError bool
+	}{
+		"Previous poll is still running": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("UpdatePost", expectedEndPost).Return(expectedEndPost, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("CreatePost", expectedPost).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(previousPoll(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(previousPoll()))
+				store.PollStore.On("Save", nextPoll()).Return(nil)
+				store.PollStore.On("Save", postedNextPoll).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeRepeatPoll, "pollID2", poll.RecurrenceDaily.Next(postAt))).Return(nil)
+				return store
+			},
+			ShouldError: false,
+		},
+		"Previous poll has already ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", expectedPost).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPreviousPoll.Copy(), nil)
+				store.PollStore.On("Save", nextPoll()).Return(nil)
+				store.PollStore.On("Save", postedNextPoll).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeRepeatPoll, "pollID2", poll.RecurrenceDaily.Next(postAt))).Return(nil)
+				return store
+			},
+			ShouldError: false,
+		},
+		"Ending previous poll fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("UpdatePost", expectedEndPost).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+				api.On("CreatePost", expectedPost).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(previousPoll(), nil)
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(previousPoll()))
+				store.PollStore.On("Save", nextPoll()).Return(nil)
+				store.PollStore.On("Save", postedNextPoll).Return(nil)
+				store.JobStore.On("Save", job.NewJob(job.TypeRepeatPoll, "pollID2", poll.RecurrenceDaily.Next(postAt))).Return(nil)
+				return store
+			},
+			ShouldError: false,
+		},
+		"PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+		"PollStore.Save fails for next poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(endedPreviousPoll.Copy(), nil)
+				store.PollStore.On("Save", nextPoll()).Return(errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			patch2 := monkey.Patch(model.NewId, func() string { return "pollID2" })
+			defer patch1.Unpatch()
+			defer patch2.Unpatch()
+
+			err := p.repeatPoll(testutils.GetPollID(), postAt)
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestPostScheduledPoll(t *testing.T) {
+	scheduledPoll := func() *poll.Poll {
+		p := testutils.GetPollWithSettings(poll.Settings{PostAt: 1234567890})
+		p.ChannelID = "channelID1"
+		p.RootID = "postID1"
+		return p
+	}
+	postedPoll := scheduledPoll()
+	postedPoll.PostID = "postID2"
+
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "channelID1",
+		RootId:    "postID1",
+		Type:      model.POST_DEFAULT,
+	}
+	model.ParseSlackAttachment(expectedPost, scheduledPoll().ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe"))
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		ShouldError bool
+	}{
+		"Valid poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", expectedPost).Return(&model.Post{Id: "postID2", ChannelId: "channelID1"}, nil)
+				api.On("LogDebug", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduledPoll(), nil)
+				store.PollStore.On("Save", postedPoll).Return(nil)
+				return store
+			},
+			ShouldError: false,
+		},
+		"Poll is already posted": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(postedPoll.Copy(), nil)
+				return store
+			},
+			ShouldError: true,
+		},
+		"PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+		"CreatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{FirstName: "John", LastName: "Doe"}, nil)
+				api.On("CreatePost", expectedPost).Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(scheduledPoll(), nil)
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			err := p.postScheduledPoll(testutils.GetPollID())
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestEndPollByDeadline(t *testing.T) {
+	pollWithDeadline := func() *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{EndAt: 1234567890})
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+
+	converter := func(userID string) (string, *model.AppError) {
+		switch userID {
+		case "userID1":
+			return "@user1", nil
+		case "userID2":
+			return "@user2", nil
+		case "userID3":
+			return "@user3", nil
+		case "userID4":
+			return "@user4", nil
+		default:
+			return "", &model.AppError{}
+		}
+	}
+	expectedPost, appErr := pollWithDeadline().ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), manifest.ID, "John Doe", converter)
+	require.Nil(t, appErr)
+	expectedPost.Id = "postID1"
+	expectedPost.ChannelId = "channelID1"
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	setupUsers := func(api *plugintest.API) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		return api
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		ShouldError bool
+	}{
+		"Valid poll": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithDeadline()))
+				return store
+			},
+			ShouldError: false,
+		},
+		"PollStore.Update fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+		"Poll has already ended": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				endedPoll := pollWithDeadline()
+				endedPoll.EndedAt = 1234567800
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll))
+				return store
+			},
+			ShouldError: true,
+		},
+		"GetUser fails for poll creator": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithDeadline()))
+				return store
+			},
+			ShouldError: true,
+		},
+		"GetUser fails for voter": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithDeadline()))
+				return store
+			},
+			ShouldError: true,
+		},
+		"UpdatePost fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("UpdatePost", expectedPost).Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithDeadline()))
+				return store
+			},
+			ShouldError: true,
+		},
+		"GetChannel fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("UpdatePost", expectedPost).Return(expectedPost, nil)
+				api.On("GetChannel", "channelID1").Return(nil, &model.AppError{})
+				api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithDeadline()))
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			defer patch.Unpatch()
+
+			err := p.endPollByDeadline(testutils.GetPollID())
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestEndPollIfAllVoted(t *testing.T) {
+	pollWithEligibleVoters := func(voters ...string) *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{EndWhenAllVoted: true})
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		p.EligibleVoters = voters
+		return p
+	}
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	t.Run("not all eligible voters voted", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		assert.False(t, p.endPollIfAllVoted(pollWithEligibleVoters("userID1", "userID5")))
+	})
+
+	t.Run("all eligible voters voted", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1", FirstName: "John", LastName: "Doe"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		api.On("UpdatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+		api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+		api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+		api.On("GetPost", "postID1").Return(&model.Post{ChannelId: "channelID1"}, nil)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, nil)
+		api.On("PublishWebSocketEvent", websocketEventPollEnded, mock.AnythingOfType("map[string]interface {}"), mock.AnythingOfType("*model.WebsocketBroadcast")).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(pollWithEligibleVoters("userID1", "userID4")))
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		assert.True(t, p.endPollIfAllVoted(pollWithEligibleVoters("userID1", "userID4")))
+	})
+
+	t.Run("poll has already ended", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		endedPoll := pollWithEligibleVoters("userID1")
+		endedPoll.EndedAt = 1234567890
+		store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(endedPoll))
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		assert.False(t, p.endPollIfAllVoted(pollWithEligibleVoters("userID1")))
+	})
+}
+
+func TestScheduleEnd(t *testing.T) {
+	t.Run("poll without deadline", func(t *testing.T) {
+		store := &mockstore.Store{}
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleEnd(testutils.GetPoll()))
+		assert.Nil(t, p.unscheduleEnd(testutils.GetPoll()))
+	})
+
+	t.Run("poll with deadline", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567890})
+		expectedJob := job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890)
+
+		store := &mockstore.Store{}
+		store.JobStore.On("Save", expectedJob).Return(nil)
+		store.JobStore.On("Delete", expectedJob).Return(nil)
+		// The deadline has passed, so there is no countdown left to refresh
+		store.JobStore.On("Delete", job.NewJob(job.TypeRefreshCountdown, testutils.GetPollID(), 0)).Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleEnd(poll))
+		assert.Nil(t, p.unscheduleEnd(poll))
+	})
+}
+
+func TestScheduleRepeat(t *testing.T) {
+	t.Run("poll without recurrence", func(t *testing.T) {
+		store := &mockstore.Store{}
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleRepeat(testutils.GetPoll()))
+		assert.Nil(t, p.unscheduleRepeat(testutils.GetPoll()))
+	})
+
+	t.Run("recurring poll", func(t *testing.T) {
+		poll := testutils.GetPollWithSettings(poll.Settings{Repeat: poll.RecurrenceWeekly})
+		expectedJob := job.NewJob(job.TypeRepeatPoll, testutils.GetPollID(), 1234567890+7*24*60*60*1000)
+
+		store := &mockstore.Store{}
+		store.JobStore.On("Save", expectedJob).Return(nil)
+		store.JobStore.On("Delete", expectedJob).Return(nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Nil(t, p.scheduleRepeat(poll))
+		assert.Nil(t, p.unscheduleRepeat(poll))
+	})
+}
@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/stats"
+)
+
+const (
+	// statsTopLimit is the number of teams, channels and creators listed by the statistics
+	statsTopLimit = 10
+	// statsDays is the number of days for which the statistics list the votes per day
+	statsDays = 30
+)
+
+// statsResponse is the response of the statistics endpoint
+type statsResponse struct {
+	Polls int `json:"polls"`
+	// AverageParticipation is the average percentage of channel members that voted in a poll.
+	// It's based on the current members of the channels, because the members at the time of the polls aren't known.
+	AverageParticipation float64         `json:"average_participation"`
+	PollsPerTeam         []statsCount    `json:"polls_per_team"`
+	PollsPerChannel      []statsCount    `json:"polls_per_channel"`
+	TopCreators          []statsCount    `json:"top_creators"`
+	VotesPerDay          []statsDayVotes `json:"votes_per_day"`
+}
+
+// statsCount is the number of polls of a team, channel or creator
+type statsCount struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Polls int    `json:"polls"`
+}
+
+// statsDayVotes is the number of votes cast on a day in UTC
+type statsDayVotes struct {
+	Day   string `json:"day"`
+	Votes int    `json:"votes"`
+}
+
+// handleStats writes the aggregate statistics of all polls as JSON
+func (p *MatterpollPlugin) handleStats(w http.ResponseWriter, r *http.Request) {
+	st, err := p.Store.Stats().Get()
+	if err != nil {
+		p.API.LogWarn("failed to get poll statistics", "error", err.Error())
+		http.Error(w, "failed to get poll statistics", http.StatusInternalServerError)
+		return
+	}
+	if st == nil {
+		st = stats.New()
+	}
+
+	b, _ := json.Marshal(p.makeStatsResponse(st, model.GetMillis()))
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(b); err != nil {
+		p.API.LogWarn("failed to write statsResponse", "error", err.Error())
+	}
+}
+
+// makeStatsResponse resolves the IDs of given statistics to names and computes the participation at a given time in milliseconds.
+// Channels, teams and users that can't be loaded anymore, e.g. because they got deleted, are listed by their ID only.
+func (p *MatterpollPlugin) makeStatsResponse(st *stats.Stats, now int64) *statsResponse {
+	channels := map[string]*model.Channel{}
+	pollsPerTeam := map[string]int{}
+	participation, participationPolls := 0.0, 0
+	for channelID, polls := range st.PollsPerChannel {
+		channel, appErr := p.API.GetChannel(channelID)
+		if appErr != nil {
+			continue
+		}
+		channels[channelID] = channel
+		// Polls in direct and group messages don't belong to a team
+		if channel.TeamId != "" {
+			pollsPerTeam[channel.TeamId] += polls
+		}
+
+		channelStats, appErr := p.API.GetChannelStats(channelID)
+		if appErr != nil || channelStats.MemberCount == 0 {
+			continue
+		}
+		// Voters who left the channel may outnumber its current members
+		participation += math.Min(float64(st.VotersPerChannel[channelID])/float64(channelStats.MemberCount), float64(polls))
+		participationPolls += polls
+	}
+
+	response := &statsResponse{
+		Polls:           st.NumberOfPolls(),
+		PollsPerTeam:    []statsCount{},
+		PollsPerChannel: []statsCount{},
+		TopCreators:     []statsCount{},
+		VotesPerDay:     []statsDayVotes{},
+	}
+	if participationPolls > 0 {
+		response.AverageParticipation = math.Round(participation/float64(participationPolls)*1000) / 10
+	}
+
+	for _, c := range stats.Top(pollsPerTeam, statsTopLimit) {
+		name := ""
+		if team, appErr := p.API.GetTeam(c.ID); appErr == nil {
+			name = team.DisplayName
+		}
+		response.PollsPerTeam = append(response.PollsPerTeam, statsCount{ID: c.ID, Name: name, Polls: c.Count})
+	}
+	for _, c := range stats.Top(st.PollsPerChannel, statsTopLimit) {
+		name := ""
+		if channel, ok := channels[c.ID]; ok {
+			name = channel.DisplayName
+		}
+		response.PollsPerChannel = append(response.PollsPerChannel, statsCount{ID: c.ID, Name: name, Polls: c.Count})
+	}
+	for _, c := range stats.Top(st.PollsPerCreator, statsTopLimit) {
+		name := ""
+		if user, appErr := p.API.GetUser(c.ID); appErr == nil {
+			name = user.Username
+		}
+		response.TopCreators = append(response.TopCreators, statsCount{ID: c.ID, Name: name, Polls: c.Count})
+	}
+
+	today := time.Unix(0, now*int64(time.Millisecond)).UTC()
+	for i := statsDays - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i).Format(stats.DayLayout)
+		response.VotesPerDay = append(response.VotesPerDay, statsDayVotes{Day: day, Votes: st.VotesPerDay[day]})
+	}
+	return response
+}
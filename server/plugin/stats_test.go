@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleStats(t *testing.T) {
+	systemAdmin := &model.User{Id: "userID1", Username: "user1", Roles: model.SYSTEM_ADMIN_ROLE_ID + " " + model.SYSTEM_USER_ROLE_ID}
+	st := stats.New()
+	st.PollsPerChannel = map[string]int{"channelID1": 3, "channelID2": 1, "channelID3": 1}
+	st.VotersPerChannel = map[string]int{"channelID1": 6, "channelID2": 2, "channelID3": 5}
+	st.PollsPerCreator = map[string]int{"userID1": 4, "userID2": 1}
+	st.VotesPerDay = map[string]int{"1970-01-15": 5, "1970-01-10": 2, "1969-10-18": 1}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		UserID             string
+		ExpectedStatusCode int
+		ExpectedResponse   *statsResponse
+		ExpectedVotesToday int
+	}{
+		"all fine": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1", DisplayName: "Town Square"}, nil)
+				api.On("GetChannel", "channelID2").Return(&model.Channel{Id: "channelID2", DisplayName: "user1, user2"}, nil)
+				api.On("GetChannel", "channelID3").Return(nil, &model.AppError{})
+				api.On("GetChannelStats", "channelID1").Return(&model.ChannelStats{ChannelId: "channelID1", MemberCount: 4}, nil)
+				api.On("GetChannelStats", "channelID2").Return(&model.ChannelStats{ChannelId: "channelID2", MemberCount: 2}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Id: "teamID1", DisplayName: "Team 1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.StatsStore.On("Get").Return(st, nil)
+				return store
+			},
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &statsResponse{
+				Polls:                5,
+				AverageParticipation: 62.5,
+				PollsPerTeam:         []statsCount{{ID: "teamID1", Name: "Team 1", Polls: 3}},
+				PollsPerChannel: []statsCount{
+					{ID: "channelID1", Name: "Town Square", Polls: 3},
+					{ID: "channelID2", Name: "user1, user2", Polls: 1},
+					{ID: "channelID3", Name: "", Polls: 1},
+				},
+				TopCreators: []statsCount{
+					{ID: "userID1", Name: "user1", Polls: 4},
+					{ID: "userID2", Name: "", Polls: 1},
+				},
+			},
+			ExpectedVotesToday: 5,
+		},
+		"no statistics": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.StatsStore.On("Get").Return(nil, nil)
+				return store
+			},
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse: &statsResponse{
+				Polls:           0,
+				PollsPerTeam:    []statsCount{},
+				PollsPerChannel: []statsCount{},
+				TopCreators:     []statsCount{},
+			},
+		},
+		"not a system admin": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID2").Return(&model.User{Id: "userID2", Roles: model.SYSTEM_USER_ROLE_ID}, nil)
+				return api
+			},
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "userID2",
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		"not logged in": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			UserID:             "",
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		"StatsStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(systemAdmin, nil)
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.StatsStore.On("Get").Return(nil, errors.New(""))
+				return store
+			},
+			UserID:             "userID1",
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+			defer patch.Unpatch()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/stats", nil)
+			if test.UserID != "" {
+				r.Header.Set("Mattermost-User-ID", test.UserID)
+			}
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			assert.Equal(t, test.ExpectedStatusCode, result.StatusCode)
+			if test.ExpectedResponse == nil {
+				return
+			}
+
+			body, err := ioutil.ReadAll(result.Body)
+			require.Nil(t, err)
+			response := &statsResponse{}
+			require.Nil(t, json.Unmarshal(body, response))
+
+			// The votes per day cover the last 30 days, today included
+			require.Len(t, response.VotesPerDay, statsDays)
+			assert.Equal(t, statsDayVotes{Day: "1969-12-17", Votes: 0}, response.VotesPerDay[0])
+			assert.Equal(t, statsDayVotes{Day: "1970-01-15", Votes: test.ExpectedVotesToday}, response.VotesPerDay[statsDays-1])
+			response.VotesPerDay = nil
+			assert.Equal(t, test.ExpectedResponse, response)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/team"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	commandHelpTextTeam = &i18n.Message{
+		ID:    "command.help.text.team",
+		Other: "Team admins can override the defaults of the plugin configuration for all polls in the current team by typing `/{{.Trigger}} team set anonymous=true progress=true members-only=false max-options=10`. `/{{.Trigger}} team` shows the defaults of the team and `/{{.Trigger}} team reset` removes them",
+	}
+	commandErrorTeamUsage = &i18n.Message{
+		ID:    "command.error.team.usage",
+		Other: "Usage: `/{{.Trigger}} team`, `/{{.Trigger}} team set KEY=VALUE [KEY=VALUE...]` or `/{{.Trigger}} team reset`. Keys are {{.Keys}}",
+	}
+	commandErrorTeamInvalidPermission = &i18n.Message{
+		ID:    "command.error.team.invalidPermission",
+		Other: "Only team admins and System Admins can change the defaults of a team.",
+	}
+
+	teamDefaultsShow = &i18n.Message{
+		ID:    "team.defaults.show",
+		Other: "Defaults of this team: {{.Defaults}}",
+	}
+	teamDefaultsNone = &i18n.Message{
+		ID:    "team.defaults.none",
+		Other: "This team uses the defaults of the plugin configuration.",
+	}
+	teamDefaultsReset = &i18n.Message{
+		ID:    "team.defaults.reset",
+		Other: "This team uses the defaults of the plugin configuration again. Existing polls aren't changed.",
+	}
+)
+
+// executeTeamCommand shows, overrides or resets the defaults of the team the command was sent in.
+// Everybody may see them, but only team admins and system admins may change them.
+func (p *MatterpollPlugin) executeTeamCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	usage := p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: commandErrorTeamUsage,
+		TemplateData:   map[string]interface{}{"Trigger": p.getConfiguration().Trigger, "Keys": strings.Join(team.Keys, ", ")},
+	})
+
+	defaults, err := p.Store.Team().GetDefaults(args.TeamId)
+	if err != nil {
+		p.API.LogError("failed to get team defaults", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	if defaults == nil {
+		defaults = &team.Defaults{}
+	}
+
+	if len(params) == 0 {
+		return p.makeTeamDefaultsText(userLocalizer, defaults), nil
+	}
+	switch {
+	case params[0] == "set" && len(params) > 1:
+	case params[0] == "reset" && len(params) == 1:
+	default:
+		return usage, nil
+	}
+
+	// System admins have the permission to manage every team
+	if !p.API.HasPermissionToTeam(args.UserId, args.TeamId, model.PERMISSION_MANAGE_TEAM) {
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorTeamInvalidPermission), nil
+	}
+
+	if params[0] == "reset" {
+		defaults = &team.Defaults{}
+	}
+	for _, pair := range params[1:] {
+		i := strings.Index(pair, "=")
+		if i == -1 {
+			return usage, nil
+		}
+		if err := defaults.Set(pair[:i], pair[i+1:]); err != nil {
+			return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+				DefaultMessage: commandErrorInvalidInput,
+				TemplateData:   map[string]interface{}{"Error": err.Error()},
+			}), nil
+		}
+	}
+
+	if err := p.Store.Team().SaveDefaults(args.TeamId, defaults); err != nil {
+		p.API.LogError("failed to save team defaults", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	if defaults.IsEmpty() {
+		return p.LocalizeDefaultMessage(userLocalizer, teamDefaultsReset), nil
+	}
+	return p.makeTeamDefaultsText(userLocalizer, defaults), nil
+}
+
+// makeTeamDefaultsText returns the defaults a team overrides
+func (p *MatterpollPlugin) makeTeamDefaultsText(l *i18n.Localizer, defaults *team.Defaults) string {
+	if defaults.IsEmpty() {
+		return p.LocalizeDefaultMessage(l, teamDefaultsNone)
+	}
+	return p.LocalizeWithConfig(l, &i18n.LocalizeConfig{
+		DefaultMessage: teamDefaultsShow,
+		TemplateData:   map[string]interface{}{"Defaults": "`" + defaults.String() + "`"},
+	})
+}
+
+// getTeamConfiguration returns the configuration that applies to new polls in a given team, which is the plugin configuration
+// overridden by the defaults of the team. If the defaults can't be loaded, the plugin configuration applies and the failure is logged.
+func (p *MatterpollPlugin) getTeamConfiguration(teamID string) *configuration {
+	configuration := p.getConfiguration()
+	if teamID == "" {
+		return configuration
+	}
+
+	defaults, err := p.Store.Team().GetDefaults(teamID)
+	if err != nil {
+		p.API.LogWarn("failed to get team defaults", "teamID", teamID, "error", err.Error())
+		return configuration
+	}
+	if defaults == nil {
+		return configuration
+	}
+	return configuration.withTeamDefaults(defaults)
+}
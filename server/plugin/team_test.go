@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/team"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteTeamCommand(t *testing.T) {
+	enabled, maxAnswerOptions := true, 10
+	existing := &team.Defaults{Anonymous: &enabled}
+	usage := "Usage: `/poll team`, `/poll team set KEY=VALUE [KEY=VALUE...]` or `/poll team reset`. Keys are anonymous, progress, members-only, max-options"
+	canManageTeam := func(allowed bool) func(*plugintest.API) *plugintest.API {
+		return func(api *plugintest.API) *plugintest.API {
+			api.On("HasPermissionToTeam", "userID1", "teamID1", model.PERMISSION_MANAGE_TEAM).Return(allowed)
+			return api
+		}
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI     func(*plugintest.API) *plugintest.API
+		SetupStore   func(*mockstore.Store) *mockstore.Store
+		Params       []string
+		ExpectedText string
+	}{
+		"Show defaults": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(existing, nil)
+				return store
+			},
+			Params:       []string{},
+			ExpectedText: "Defaults of this team: `anonymous=true`",
+		},
+		"Show without defaults": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+				return store
+			},
+			Params:       []string{},
+			ExpectedText: teamDefaultsNone.Other,
+		},
+		"Set as team admin": {
+			SetupAPI: canManageTeam(true),
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(&team.Defaults{Anonymous: &enabled}, nil)
+				store.TeamStore.On("SaveDefaults", "teamID1", &team.Defaults{Anonymous: &enabled, MaxAnswerOptions: &maxAnswerOptions}).Return(nil)
+				return store
+			},
+			Params:       []string{"set", "max-options=10"},
+			ExpectedText: "Defaults of this team: `anonymous=true max-options=10`",
+		},
+		"Reset as team admin": {
+			SetupAPI: canManageTeam(true),
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(existing, nil)
+				store.TeamStore.On("SaveDefaults", "teamID1", &team.Defaults{}).Return(nil)
+				return store
+			},
+			Params:       []string{"reset"},
+			ExpectedText: teamDefaultsReset.Other,
+		},
+		"Not a team admin": {
+			SetupAPI: canManageTeam(false),
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+				return store
+			},
+			Params:       []string{"set", "anonymous=true"},
+			ExpectedText: commandErrorTeamInvalidPermission.Other,
+		},
+		"Invalid default": {
+			SetupAPI: canManageTeam(true),
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+				return store
+			},
+			Params:       []string{"set", "anonymous=true", "max-options=many"},
+			ExpectedText: "Invalid input: Invalid value many for max-options. Use a number or 0 for no limit",
+		},
+		"Default without value": {
+			SetupAPI: canManageTeam(true),
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+				return store
+			},
+			Params:       []string{"set", "anonymous"},
+			ExpectedText: usage,
+		},
+		"Set without defaults": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+				return store
+			},
+			Params:       []string{"set"},
+			ExpectedText: usage,
+		},
+		"Unknown sub command": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+				return store
+			},
+			Params:       []string{"clear"},
+			ExpectedText: usage,
+		},
+		"GetDefaults fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, errors.New(""))
+				return store
+			},
+			Params:       []string{},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+		"SaveDefaults fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = canManageTeam(true)(api)
+				api.On("LogError", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+				store.TeamStore.On("SaveDefaults", "teamID1", &team.Defaults{Anonymous: &enabled}).Return(errors.New(""))
+				return store
+			},
+			Params:       []string{"set", "anonymous=true"},
+			ExpectedText: commandErrorGeneric.Other,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("GetUser", "userID1").Return(&model.User{Id: "userID1"}, nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			msg, appErr := p.executeTeamCommand(&model.CommandArgs{UserId: "userID1", ChannelId: "channelID1", TeamId: "teamID1"}, test.Params)
+
+			assert.Nil(t, appErr)
+			assert.Equal(t, test.ExpectedText, msg)
+		})
+	}
+}
+
+func TestGetTeamConfiguration(t *testing.T) {
+	enabled := true
+
+	t.Run("team defaults", func(t *testing.T) {
+		store := &mockstore.Store{}
+		store.TeamStore.On("GetDefaults", "teamID1").Return(&team.Defaults{Anonymous: &enabled}, nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		c := p.getTeamConfiguration("teamID1")
+		assert.True(t, c.DefaultAnonymous)
+		assert.False(t, p.getConfiguration().DefaultAnonymous)
+	})
+	t.Run("no team defaults", func(t *testing.T) {
+		store := &mockstore.Store{}
+		store.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, &plugintest.API{}, store)
+
+		assert.Equal(t, p.getConfiguration(), p.getTeamConfiguration("teamID1"))
+	})
+	t.Run("no team", func(t *testing.T) {
+		p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+
+		assert.Equal(t, p.getConfiguration(), p.getTeamConfiguration(""))
+	})
+	t.Run("GetDefaults fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", GetMockArgumentsWithType("string", 5)...).Return()
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.TeamStore.On("GetDefaults", "teamID1").Return(nil, errors.New(""))
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		assert.Equal(t, p.getConfiguration(), p.getTeamConfiguration("teamID1"))
+	})
+}
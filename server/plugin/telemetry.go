@@ -0,0 +1,196 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+const (
+	// telemetryInterval is the time between two reports of the anonymized usage statistics
+	telemetryInterval = 24 * time.Hour
+	telemetryTimeout  = 10 * time.Second
+)
+
+var (
+	commandHelpTextAdminUsage = &i18n.Message{
+		ID:    "command.help.text.admin.usage",
+		Other: "System admins can see how polls are used on this server by typing `/{{.Trigger}} admin usage`",
+	}
+
+	adminUsageHeading = &i18n.Message{
+		ID:    "admin.usage.heading",
+		Other: "#### Poll usage",
+	}
+	adminUsagePolls = &i18n.Message{
+		ID:    "admin.usage.polls",
+		Other: "**Polls**: {{.Polls}} ({{.Running}} running, {{.Surveys}} surveys)",
+	}
+	adminUsageRecent = &i18n.Message{
+		ID:    "admin.usage.recent",
+		Other: "**Last {{.Days}} days**: {{.Polls}} polls created, {{.Votes}} votes cast",
+	}
+	adminUsageVoters = &i18n.Message{
+		ID:    "admin.usage.voters",
+		Other: "**Voters in all polls**: {{.Voters}}",
+	}
+	adminUsageSettings = &i18n.Message{
+		ID:    "admin.usage.settings",
+		Other: "**Poll Settings**: {{.Settings}}",
+	}
+	adminUsageSettingsNone = &i18n.Message{
+		ID:    "admin.usage.settingsNone",
+		Other: "**Poll Settings**: none",
+	}
+	adminUsageTelemetryEnabled = &i18n.Message{
+		ID:    "admin.usage.telemetryEnabled",
+		Other: "These statistics are sent anonymized once a day, because telemetry is enabled.",
+	}
+	adminUsageTelemetryDisabled = &i18n.Message{
+		ID:    "admin.usage.telemetryDisabled",
+		Other: "These statistics are not sent anywhere. To share them anonymized, enable telemetry in the plugin settings and diagnostics in the server settings.",
+	}
+)
+
+// telemetryPayload is the JSON body of a telemetry report
+type telemetryPayload struct {
+	// DiagnosticID is the anonymous ID of the Mattermost server, which allows to tell reports of different servers apart
+	DiagnosticID  string       `json:"diagnostic_id"`
+	PluginVersion string       `json:"plugin_version"`
+	Timestamp     int64        `json:"timestamp"`
+	Usage         *stats.Usage `json:"usage"`
+}
+
+// isTelemetryEnabled checks if the plugin and the server both allow to send the usage statistics
+func (p *MatterpollPlugin) isTelemetryEnabled() bool {
+	configuration := p.getConfiguration()
+	if !configuration.EnableTelemetry || configuration.TelemetryURL == "" {
+		return false
+	}
+	diagnostics := p.ServerConfig.LogSettings.EnableDiagnostics
+	return diagnostics != nil && *diagnostics
+}
+
+// scheduleTelemetry stores the job that sends the usage statistics, which runs right away and then once a day.
+// Every plugin instance schedules it on activation. They all store the same job, hence it exists only once.
+func (p *MatterpollPlugin) scheduleTelemetry() error {
+	return p.Store.Job().Save(job.NewJob(job.TypeSendTelemetry, "", model.GetMillis()))
+}
+
+// sendTelemetry sends the usage statistics to the telemetry URL if telemetry is enabled.
+// It returns the next run of the job, which is scheduled even if telemetry is disabled, so enabling it takes effect without a restart.
+func (p *MatterpollPlugin) sendTelemetry(j *job.Job) (*job.Job, error) {
+	next := job.NewJob(job.TypeSendTelemetry, "", model.GetMillis()+int64(telemetryInterval/time.Millisecond))
+	if !p.isTelemetryEnabled() {
+		return next, nil
+	}
+
+	usage, err := p.getUsage()
+	if err != nil {
+		return next, err
+	}
+	payload := &telemetryPayload{
+		DiagnosticID:  p.API.GetDiagnosticId(),
+		PluginVersion: manifest.Version,
+		Timestamp:     model.GetMillis(),
+		Usage:         usage,
+	}
+	if err := postTelemetry(p.getConfiguration().TelemetryURL, payload); err != nil {
+		return next, errors.Wrap(err, "failed to send telemetry")
+	}
+	return next, nil
+}
+
+// postTelemetry posts a telemetry report to a given URL
+func postTelemetry(url string, payload *telemetryPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal payload")
+	}
+
+	client := &http.Client{Timeout: telemetryTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// getUsage returns the usage statistics of all polls, including archived ones
+func (p *MatterpollPlugin) getUsage() (*stats.Usage, error) {
+	polls, err := p.Store.Poll().List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list polls")
+	}
+	archived, err := p.Store.Poll().ListArchived()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list archived polls")
+	}
+	st, err := p.Store.Stats().Get()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get statistics")
+	}
+	if st == nil {
+		st = stats.New()
+	}
+	return stats.NewUsage(append(polls, archived...), st, model.GetMillis()), nil
+}
+
+// executeAdminUsageCommand returns a summary of the usage statistics, which are the same that telemetry reports
+func (p *MatterpollPlugin) executeAdminUsageCommand(userLocalizer *i18n.Localizer) string {
+	usage, err := p.getUsage()
+	if err != nil {
+		p.API.LogError("failed to get usage statistics", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric)
+	}
+
+	lines := []string{
+		p.LocalizeDefaultMessage(userLocalizer, adminUsageHeading),
+		p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminUsagePolls,
+			TemplateData:   map[string]interface{}{"Polls": usage.Polls, "Running": usage.RunningPolls, "Surveys": usage.Surveys},
+		}),
+		p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminUsageRecent,
+			TemplateData:   map[string]interface{}{"Days": stats.UsageWindowDays, "Polls": usage.RecentPolls, "Votes": usage.RecentVotes},
+		}),
+		p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminUsageVoters,
+			TemplateData:   map[string]interface{}{"Voters": usage.Voters},
+		}),
+	}
+
+	settings := []string{}
+	for _, c := range stats.Top(usage.Settings, len(usage.Settings)) {
+		settings = append(settings, fmt.Sprintf("`%s` (%d)", c.ID, c.Count))
+	}
+	if len(settings) == 0 {
+		lines = append(lines, p.LocalizeDefaultMessage(userLocalizer, adminUsageSettingsNone))
+	} else {
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: adminUsageSettings,
+			TemplateData:   map[string]interface{}{"Settings": strings.Join(settings, ", ")},
+		}))
+	}
+
+	telemetry := adminUsageTelemetryDisabled
+	if p.isTelemetryEnabled() {
+		telemetry = adminUsageTelemetryEnabled
+	}
+	lines = append(lines, "", p.LocalizeDefaultMessage(userLocalizer, telemetry))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleTelemetry(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	store := &mockstore.Store{}
+	store.JobStore.On("Save", job.NewJob(job.TypeSendTelemetry, "", 1234567890)).Return(nil)
+	defer store.AssertExpectations(t)
+	p := setupTestPlugin(t, &plugintest.API{}, store)
+
+	assert.Nil(t, p.scheduleTelemetry())
+}
+
+func TestSendTelemetry(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	next := job.NewJob(job.TypeSendTelemetry, "", now+millisPerDay)
+	anonymousPoll := testutils.GetPollWithSettings(poll.Settings{Anonymous: true})
+	archivedPoll := testutils.GetPollWithVotes()
+	archivedPoll.ID = "pollID2"
+	archivedPoll.EndedAt = now
+
+	for name, test := range map[string]struct {
+		EnableTelemetry   bool
+		EnableDiagnostics *bool
+		StatusCode        int
+		SetupStore        func(*mockstore.Store) *mockstore.Store
+		ExpectedUsage     *stats.Usage
+		ShouldError       bool
+	}{
+		"all fine": {
+			EnableTelemetry:   true,
+			EnableDiagnostics: model.NewBool(true),
+			StatusCode:        http.StatusOK,
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{anonymousPoll}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{archivedPoll}, nil)
+				store.StatsStore.On("Get").Return(&stats.Stats{VotesPerDay: map[string]int{"1970-01-15": 3}}, nil)
+				return store
+			},
+			ExpectedUsage: &stats.Usage{
+				Polls:        2,
+				RunningPolls: 1,
+				RecentPolls:  2,
+				Voters:       archivedPoll.NumberOfVoters(),
+				RecentVotes:  3,
+				Settings:     map[string]int{"anonymous": 1},
+			},
+		},
+		"telemetry disabled": {
+			EnableTelemetry:   false,
+			EnableDiagnostics: model.NewBool(true),
+			SetupStore:        func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"diagnostics disabled": {
+			EnableTelemetry:   true,
+			EnableDiagnostics: model.NewBool(false),
+			SetupStore:        func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"diagnostics not set": {
+			EnableTelemetry: true,
+			SetupStore:      func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"PollStore.List fails": {
+			EnableTelemetry:   true,
+			EnableDiagnostics: model.NewBool(true),
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+		"error status code": {
+			EnableTelemetry:   true,
+			EnableDiagnostics: model.NewBool(true),
+			StatusCode:        http.StatusInternalServerError,
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.StatsStore.On("Get").Return(nil, nil)
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var received *telemetryPayload
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				received = &telemetryPayload{}
+				require.Nil(t, json.Unmarshal(body, received))
+				w.WriteHeader(test.StatusCode)
+			}))
+			defer server.Close()
+
+			api := &plugintest.API{}
+			api.On("GetDiagnosticId").Return("diagnosticID1").Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+			p.setConfiguration(&configuration{Trigger: "poll", EnableTelemetry: test.EnableTelemetry, TelemetryURL: server.URL})
+			p.ServerConfig.LogSettings.EnableDiagnostics = test.EnableDiagnostics
+
+			j, err := p.sendTelemetry(job.NewJob(job.TypeSendTelemetry, "", now))
+			assert.Equal(t, next, j)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			if test.ExpectedUsage == nil {
+				assert.Nil(t, received)
+				return
+			}
+			assert.Equal(t, &telemetryPayload{
+				DiagnosticID:  "diagnosticID1",
+				PluginVersion: manifest.Version,
+				Timestamp:     now,
+				Usage:         test.ExpectedUsage,
+			}, received)
+		})
+	}
+}
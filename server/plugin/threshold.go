@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+var (
+	thresholdPostMessage = &i18n.Message{
+		ID:    "threshold.post.message",
+		One:   "Your poll [{{.Question}}]({{.Link}}) has reached {{.Count}} voter. You can end it now if that's enough.",
+		Other: "Your poll [{{.Question}}]({{.Link}}) has reached {{.Count}} voters. You can end it now if that's enough.",
+	}
+	thresholdPostMessageNoLink = &i18n.Message{
+		ID:    "threshold.post.messageNoLink",
+		One:   "Your poll **{{.Question}}** has reached {{.Count}} voter. You can end it now if that's enough.",
+		Other: "Your poll **{{.Question}}** has reached {{.Count}} voters. You can end it now if that's enough.",
+	}
+)
+
+// notifyIfThresholdReached sends the creator of a given poll a direct message once the number of voters reaches Settings.NotifyAt.
+// The poll remembers the notification, so it's sent only once even if several votes are cast at the same time. Failures are only logged.
+func (p *MatterpollPlugin) notifyIfThresholdReached(votedPoll *poll.Poll) {
+	if !votedPoll.NeedsThresholdNotification() {
+		return
+	}
+
+	var notified bool
+	notifiedPoll, err := p.Store.Poll().Update(votedPoll.ID, func(latest *poll.Poll) error {
+		if notified = !latest.NeedsThresholdNotification(); notified {
+			return errors.New("creator has already been notified")
+		}
+		latest.ThresholdNotified = true
+		return nil
+	})
+	if notified {
+		return
+	}
+	if err != nil {
+		p.API.LogWarn("failed to store threshold notification", "error", err.Error())
+		return
+	}
+
+	if appErr := p.postThresholdNotification(notifiedPoll); appErr != nil {
+		p.API.LogWarn("failed to notify creator about reached threshold", "error", appErr.Error())
+	}
+}
+
+// postThresholdNotification posts into the direct channel between the bot and the creator of a given poll that the poll reached Settings.NotifyAt voters
+func (p *MatterpollPlugin) postThresholdNotification(notifiedPoll *poll.Poll) *model.AppError {
+	creator, appErr := p.API.GetUser(notifiedPoll.Creator)
+	if appErr != nil {
+		return appErr
+	}
+	link, appErr := p.getPollPermalink(notifiedPoll)
+	if appErr != nil {
+		return appErr
+	}
+
+	localizer := p.getLocalizerForUser(creator)
+	count := notifiedPoll.NumberOfVoters()
+	message := p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+		DefaultMessage: thresholdPostMessageNoLink,
+		TemplateData: map[string]interface{}{
+			"Question": notifiedPoll.Question,
+			"Count":    count,
+		},
+		PluralCount: count,
+	})
+	if link != "" {
+		message = p.LocalizeWithConfig(localizer, &i18n.LocalizeConfig{
+			DefaultMessage: thresholdPostMessage,
+			TemplateData: map[string]interface{}{
+				"Question": notifiedPoll.Question,
+				"Link":     link,
+				"Count":    count,
+			},
+			PluralCount: count,
+		})
+	}
+
+	directChannel, appErr := p.API.GetDirectChannel(creator.Id, p.botUserID)
+	if appErr != nil {
+		return appErr
+	}
+	_, appErr = p.API.CreatePost(&model.Post{
+		UserId:    p.botUserID,
+		ChannelId: directChannel.Id,
+		Message:   message,
+	})
+	return appErr
+}
@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNotifyIfThresholdReached(t *testing.T) {
+	votedPoll := func(notifyAt int) *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{NotifyAt: notifyAt})
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+		return p
+	}
+	notifiedPoll := votedPoll(4)
+	notifiedPoll.ThresholdNotified = true
+
+	updateFunc := mock.AnythingOfType("func(*poll.Poll) error")
+
+	for name, test := range map[string]struct {
+		Poll       *poll.Poll
+		SetupAPI   func(*plugintest.API) *plugintest.API
+		SetupStore func(*mockstore.Store) *mockstore.Store
+	}{
+		"Threshold reached, poll in a channel": {
+			Poll: votedPoll(4),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1", TeamId: "teamID1"}, nil)
+				api.On("GetTeam", "teamID1").Return(&model.Team{Name: "team1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Message:   "Your poll [Question](" + testutils.GetSiteURL() + "/team1/pl/postID1) has reached 4 voters. You can end it now if that's enough.",
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(votedPoll(4)))
+				return store
+			},
+		},
+		"Threshold reached, poll in a direct message": {
+			Poll: votedPoll(1),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID2",
+					Message:   "Your poll **Question** has reached 4 voters. You can end it now if that's enough.",
+				}).Return(&model.Post{}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(votedPoll(1)))
+				return store
+			},
+		},
+		"Threshold not reached": {
+			Poll:       votedPoll(5),
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"No threshold": {
+			Poll:       votedPoll(0),
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"Creator has already been notified": {
+			Poll:       notifiedPoll.Copy(),
+			SetupAPI:   func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store { return store },
+		},
+		"Creator got notified by a simultaneous vote": {
+			Poll:     votedPoll(4),
+			SetupAPI: func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(notifiedPoll.Copy()))
+				return store
+			},
+		},
+		"PollStore.Update fails": {
+			Poll: votedPoll(4),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(nil, &model.AppError{})
+				return store
+			},
+		},
+		"CreatePost fails": {
+			Poll: votedPoll(4),
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+				api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+				api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+				api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(nil, &model.AppError{})
+				api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return()
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(votedPoll(4)))
+				return store
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			p.notifyIfThresholdReached(test.Poll)
+		})
+	}
+
+	t.Run("Poll remembers the notification", func(t *testing.T) {
+		updatedPoll := votedPoll(4)
+
+		api := &plugintest.API{}
+		api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Username: "user1"}, nil)
+		api.On("GetChannel", "channelID1").Return(&model.Channel{Id: "channelID1"}, nil)
+		api.On("GetDirectChannel", "userID1", testutils.GetBotUserID()).Return(&model.Channel{Id: "channelID2"}, nil)
+		api.On("CreatePost", mock.AnythingOfType("*model.Post")).Return(&model.Post{}, nil)
+		defer api.AssertExpectations(t)
+		store := &mockstore.Store{}
+		store.PollStore.On("Update", testutils.GetPollID(), updateFunc).Return(GetMockPollUpdate(updatedPoll))
+		defer store.AssertExpectations(t)
+		p := setupTestPlugin(t, api, store)
+
+		p.notifyIfThresholdReached(votedPoll(4))
+
+		assert.True(t, updatedPoll.ThresholdNotified)
+	})
+}
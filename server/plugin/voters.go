@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+)
+
+const (
+	// votersPerPage is the number of voters per page of /poll voters
+	votersPerPage = 100
+)
+
+var (
+	commandHelpTextVoters = &i18n.Message{
+		ID:    "command.help.text.voters",
+		Other: "To see who voted for which answer option in a poll with public votes, type `/{{.Trigger}} voters <poll ID> [page]`",
+	}
+	commandErrorVotersUsage = &i18n.Message{
+		ID:    "command.error.voters.usage",
+		Other: "Usage: `/{{.Trigger}} voters <poll ID> [page]`",
+	}
+
+	votersNone = &i18n.Message{
+		ID:    "voters.none",
+		Other: "Nobody has voted in this poll yet.",
+	}
+	votersPageEmpty = &i18n.Message{
+		ID:    "voters.pageEmpty",
+		Other: "Page {{.Page}} is empty. There are only {{.Pages}} pages.",
+	}
+	votersHeading = &i18n.Message{
+		ID:    "voters.heading",
+		Other: "Voters of **{{.Question}}** (page {{.Page}} of {{.Pages}}):",
+	}
+	votersEntry = &i18n.Message{
+		ID:    "voters.entry",
+		One:   "**{{.Answer}}** ({{.Count}} vote): {{.Voters}}",
+		Other: "**{{.Answer}}** ({{.Count}} votes): {{.Voters}}",
+	}
+	votersNextPage = &i18n.Message{
+		ID:    "voters.nextPage",
+		Other: "Type `/{{.Trigger}} voters {{.ID}} {{.Next}}` to see the next page.",
+	}
+
+	responseShowVotersNotPublic = &i18n.Message{
+		ID:    "response.showVoters.notPublic",
+		Other: "The votes of this poll aren't public.",
+	}
+	responseShowVotersInvalidPermission = &i18n.Message{
+		ID:    "response.showVoters.invalidPermission",
+		Other: "You can only see the voters of polls in channels you are a member of.",
+	}
+)
+
+// executeVotersCommand lists the voters of the poll with the ID given in params. An optional second parameter selects the page.
+func (p *MatterpollPlugin) executeVotersCommand(args *model.CommandArgs, params []string) (string, *model.AppError) {
+	userLocalizer := p.getUserLocalizer(args.UserId)
+	trigger := p.getConfiguration().Trigger
+
+	page := 1
+	if len(params) == 2 {
+		var err error
+		if page, err = strconv.Atoi(params[1]); err != nil || page < 1 {
+			page = 0
+		}
+	}
+	if len(params) == 0 || len(params) > 2 || page == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorVotersUsage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+
+	votersPoll, err := p.Store.Poll().Get(params[0])
+	if err != nil {
+		p.API.LogError("failed to get poll", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	if votersPoll.IsScheduled() {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: commandErrorNotPosted,
+			TemplateData:   map[string]interface{}{"Trigger": trigger},
+		}), nil
+	}
+	// The poll ID alone mustn't reveal the voters of polls in other channels
+	if _, appErr := p.API.GetChannelMember(votersPoll.ChannelID, args.UserId); appErr != nil {
+		return p.LocalizeDefaultMessage(userLocalizer, responseShowVotersInvalidPermission), nil
+	}
+
+	msg, err := p.listVoters(votersPoll, page, userLocalizer, trigger)
+	if err != nil {
+		p.API.LogError("failed to list voters", "err", err.Error())
+		return p.LocalizeDefaultMessage(userLocalizer, commandErrorGeneric), nil
+	}
+	return msg, nil
+}
+
+// handleShowVoters sends the first page of voters of a poll with public votes as ephemeral post
+func (p *MatterpollPlugin) handleShowVoters(vars map[string]string, request *model.PostActionIntegrationRequest) (*i18n.Message, *model.Post, error) {
+	votersPoll, err := p.Store.Poll().Get(vars["id"])
+	if err != nil {
+		return commandErrorGeneric, nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	msg, err := p.listVoters(votersPoll, 1, p.getUserLocalizer(request.UserId), p.getConfiguration().Trigger)
+	if err != nil {
+		return commandErrorGeneric, nil, err
+	}
+
+	// The ephemeral response can't carry the voters, because they aren't part of a localized message
+	p.SendEphemeralPost(request.ChannelId, request.UserId, msg)
+	return nil, nil, nil
+}
+
+// listVoters returns a message that lists a given page of the voters of a poll with public votes, grouped by answer option. Pages start at one.
+func (p *MatterpollPlugin) listVoters(votersPoll *poll.Poll, page int, userLocalizer *i18n.Localizer, trigger string) (string, error) {
+	if !votersPoll.Settings.PublicVotes {
+		return p.LocalizeDefaultMessage(userLocalizer, responseShowVotersNotPublic), nil
+	}
+
+	groups, total := votersPoll.PageVoters(page-1, votersPerPage)
+	if total == 0 {
+		return p.LocalizeDefaultMessage(userLocalizer, votersNone), nil
+	}
+	pages := (total + votersPerPage - 1) / votersPerPage
+	if len(groups) == 0 {
+		return p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: votersPageEmpty,
+			TemplateData:   map[string]interface{}{"Page": page, "Pages": pages},
+		}), nil
+	}
+
+	lines := []string{p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+		DefaultMessage: votersHeading,
+		TemplateData:   map[string]interface{}{"Question": votersPoll.Question, "Page": page, "Pages": pages},
+	})}
+	for _, group := range groups {
+		names := []string{}
+		for _, userID := range group.Voter {
+			name, appErr := p.convertUserIDToVoterName(userID)
+			if appErr != nil {
+				return "", errors.Wrap(appErr, "failed to get display name for voter")
+			}
+			names = append(names, name)
+		}
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: votersEntry,
+			TemplateData:   map[string]interface{}{"Answer": group.Answer, "Count": group.Votes, "Voters": strings.Join(names, ", ")},
+			PluralCount:    group.Votes,
+		}))
+	}
+	if page < pages {
+		lines = append(lines, p.LocalizeWithConfig(userLocalizer, &i18n.LocalizeConfig{
+			DefaultMessage: votersNextPage,
+			TemplateData:   map[string]interface{}{"Trigger": trigger, "ID": votersPoll.ID, "Next": page + 1},
+		}))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// convertUserIDToVoterName returns the @mention of a given user followed by the full name, e.g. "@jdoe (John Doe)".
+// Users without a full name only get the @mention.
+func (p *MatterpollPlugin) convertUserIDToVoterName(userID string) (string, *model.AppError) {
+	user, appErr := p.API.GetUser(userID)
+	if appErr != nil {
+		return "", appErr
+	}
+	if fullName := user.GetFullName(); fullName != "" {
+		return "@" + user.Username + " (" + fullName + ")", nil
+	}
+	return "@" + user.Username, nil
+}
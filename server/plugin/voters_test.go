@@ -0,0 +1,226 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListVoters(t *testing.T) {
+	manyVoters := func() *poll.Poll {
+		p := testutils.GetPollWithSettings(poll.Settings{PublicVotes: true})
+		for i := 0; i < votersPerPage+1; i++ {
+			p.AnswerOptions[i%2].Voter = append(p.AnswerOptions[i%2].Voter, fmt.Sprintf("userID%d", i))
+		}
+		return p
+	}
+
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		Page            int
+		SetupAPI        func(*plugintest.API) *plugintest.API
+		ExpectedMessage string
+		ExpectedLines   int
+		ShouldError     bool
+	}{
+		"first of two pages": {
+			Poll: manyVoters(),
+			Page: 1,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				for i := 0; i < votersPerPage+1; i++ {
+					api.On("GetUser", fmt.Sprintf("userID%d", i)).Return(&model.User{Username: fmt.Sprintf("user%d", i)}, nil).Maybe()
+				}
+				return api
+			},
+			ExpectedLines: 4,
+		},
+		"second of two pages": {
+			Poll: manyVoters(),
+			Page: 2,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID99").Return(&model.User{Username: "user99"}, nil)
+				return api
+			},
+			ExpectedMessage: "Voters of **Question** (page 2 of 2):\n**Answer 2** (50 votes): @user99",
+		},
+		"page after the last one": {
+			Poll:            manyVoters(),
+			Page:            3,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			ExpectedMessage: "Page 3 is empty. There are only 2 pages.",
+		},
+		"no votes": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{PublicVotes: true}),
+			Page:            1,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			ExpectedMessage: votersNone.Other,
+		},
+		"poll without public votes": {
+			Poll:            testutils.GetPollWithVotes(),
+			Page:            1,
+			SetupAPI:        func(api *plugintest.API) *plugintest.API { return api },
+			ExpectedMessage: responseShowVotersNotPublic.Other,
+		},
+		"GetUser fails": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{PublicVotes: true}),
+			Page: 1,
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(nil, &model.AppError{})
+				return api
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			defer api.AssertExpectations(t)
+			p := setupTestPlugin(t, api, &mockstore.Store{})
+
+			msg, err := p.listVoters(test.Poll, test.Page, testutils.GetLocalizer(), "poll")
+			if test.ShouldError {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			if test.ExpectedLines > 0 {
+				lines := strings.Split(msg, "\n")
+				require.Len(t, lines, test.ExpectedLines)
+				assert.Equal(t, "Voters of **Question** (page 1 of 2):", lines[0])
+				assert.True(t, strings.HasPrefix(lines[1], "**Answer 1** (51 votes): @user0, @user2, "))
+				assert.True(t, strings.HasPrefix(lines[2], "**Answer 2** (50 votes): @user1, @user3, "))
+				assert.Equal(t, "Type `/poll voters "+testutils.GetPollID()+" 2` to see the next page.", lines[3])
+				return
+			}
+			assert.Equal(t, test.ExpectedMessage, msg)
+		})
+	}
+}
+
+func TestHandleShowVoters(t *testing.T) {
+	publicPoll := func() *poll.Poll {
+		return testutils.GetPollWithVotesAndSettings(poll.Settings{PublicVotes: true})
+	}
+	expectedPost := &model.Post{
+		UserId:    testutils.GetBotUserID(),
+		ChannelId: "channelID1",
+		Message:   "Voters of **Question** (page 1 of 1):\n**Answer 1** (3 votes): @user1, @user2 (User Two), @user3\n**Answer 2** (1 vote): @user4",
+	}
+
+	setupUsers := func(api *plugintest.API) *plugintest.API {
+		api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+		api.On("GetUser", "userID2").Return(&model.User{Username: "user2", FirstName: "User", LastName: "Two"}, nil)
+		api.On("GetUser", "userID3").Return(&model.User{Username: "user3"}, nil)
+		api.On("GetUser", "userID4").Return(&model.User{Username: "user4"}, nil)
+		return api
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI           func(*plugintest.API) *plugintest.API
+		SetupStore         func(*mockstore.Store) *mockstore.Store
+		Request            *model.PostActionIntegrationRequest
+		ExpectedStatusCode int
+		ExpectedResponse   *model.PostActionIntegrationResponse
+	}{
+		"Valid request": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api = setupUsers(api)
+				api.On("SendEphemeralPost", "userID1", expectedPost).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(publicPoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, poll without public votes": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("SendEphemeralPost", "userID1", &model.Post{
+					UserId:    testutils.GetBotUserID(),
+					ChannelId: "channelID1",
+					Message:   responseShowVotersNotPublic.Other,
+				}).Return(nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPollWithVotes(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{},
+		},
+		"Valid request, PollStore.Get fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(nil, &model.AppError{})
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Valid request, GetUser fails for voter": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("GetUser", "userID1").Return(&model.User{Username: "user1"}, nil)
+				api.On("GetUser", "userID2").Return(nil, &model.AppError{})
+				return api
+			},
+			SetupStore: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Get", testutils.GetPollID()).Return(publicPoll(), nil)
+				return store
+			},
+			Request:            &model.PostActionIntegrationRequest{UserId: "userID1", ChannelId: "channelID1", PostId: "postID1"},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedResponse:   &model.PostActionIntegrationResponse{EphemeralText: commandErrorGeneric.Other},
+		},
+		"Invalid request": {
+			SetupAPI:           func(api *plugintest.API) *plugintest.API { return api },
+			SetupStore:         func(store *mockstore.Store) *mockstore.Store { return store },
+			Request:            nil,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedResponse:   nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogDebug", GetMockArgumentsWithType("string", 7)...).Return()
+			api.On("LogWarn", GetMockArgumentsWithType("string", 3)...).Return().Maybe()
+			defer api.AssertExpectations(t)
+			store := test.SetupStore(&mockstore.Store{})
+			defer store.AssertExpectations(t)
+			p := setupTestPlugin(t, api, store)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/polls/%s/voters", testutils.GetPollID()), bytes.NewReader(test.Request.ToJson()))
+			r.Header.Add("Mattermost-User-ID", model.NewId())
+			p.ServeHTTP(nil, w, r)
+
+			result := w.Result()
+			require.NotNil(t, result)
+			response := model.PostActionIntegrationResponseFromJson(result.Body)
+
+			assert.Equal(test.ExpectedStatusCode, result.StatusCode)
+			assert.Equal(test.ExpectedResponse, response)
+		})
+	}
+}
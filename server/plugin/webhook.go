@@ -0,0 +1,190 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/pkg/errors"
+)
+
+// webhookEvent is the kind of poll activity that triggers the webhook
+type webhookEvent string
+
+const (
+	webhookEventPollCreated webhookEvent = "poll_created"
+	webhookEventVoteCast    webhookEvent = "vote_cast"
+	webhookEventPollEnded   webhookEvent = "poll_ended"
+	webhookEventPollDeleted webhookEvent = "poll_deleted"
+
+	// webhookEventHeader contains the event of a webhook request
+	webhookEventHeader = "Matterpoll-Event"
+	// webhookSignatureHeader contains the hex encoded HMAC-SHA256 of the request body, keyed with the webhook secret
+	webhookSignatureHeader = "Matterpoll-Signature"
+
+	webhookTimeout = 10 * time.Second
+)
+
+var allWebhookEvents = []webhookEvent{
+	webhookEventPollCreated,
+	webhookEventVoteCast,
+	webhookEventPollEnded,
+	webhookEventPollDeleted,
+}
+
+// isValid checks if the event is known
+func (e webhookEvent) isValid() bool {
+	for _, known := range allWebhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookPayload is the JSON body of a webhook request
+type webhookPayload struct {
+	Event     webhookEvent `json:"event"`
+	Timestamp int64        `json:"timestamp"`
+	// UserID is the user that caused the event. It's left out for votes in anonymous polls
+	// and for all other events of polls with an anonymous creator.
+	UserID string       `json:"user_id,omitempty"`
+	Poll   *webhookPoll `json:"poll"`
+}
+
+// webhookPoll is the representation of a poll in webhook payloads and WebSocket events. It contains the number of votes, but not the voters.
+type webhookPoll struct {
+	ID            string                 `json:"id"`
+	PostID        string                 `json:"post_id,omitempty"`
+	ChannelID     string                 `json:"channel_id,omitempty"`
+	Creator       string                 `json:"creator,omitempty"`
+	Question      string                 `json:"question"`
+	AnswerOptions []*webhookAnswerOption `json:"answer_options"`
+	Questions     []*webhookQuestion     `json:"questions,omitempty"`
+	Settings      poll.Settings          `json:"settings"`
+	CreatedAt     int64                  `json:"created_at"`
+	EndedAt       int64                  `json:"ended_at,omitempty"`
+	PausedAt      int64                  `json:"paused_at,omitempty"`
+	Voters        int                    `json:"voters"`
+}
+
+// webhookAnswerOption is the representation of an answer option in webhook payloads
+type webhookAnswerOption struct {
+	Answer string `json:"answer"`
+	Votes  int    `json:"votes"`
+}
+
+// webhookQuestion is the representation of a survey question in webhook payloads
+type webhookQuestion struct {
+	Question      string                 `json:"question"`
+	AnswerOptions []*webhookAnswerOption `json:"answer_options"`
+}
+
+// newWebhookAnswerOptions returns the representation of the given answer options of a poll in webhook payloads
+func newWebhookAnswerOptions(p *poll.Poll, answerOptions []*poll.AnswerOption) []*webhookAnswerOption {
+	options := make([]*webhookAnswerOption, len(answerOptions))
+	for i, o := range answerOptions {
+		options[i] = &webhookAnswerOption{Answer: o.Answer, Votes: p.VotesOf(o)}
+	}
+	return options
+}
+
+// newWebhookPoll returns the representation of a given poll in webhook payloads
+func newWebhookPoll(p *poll.Poll) *webhookPoll {
+	var questions []*webhookQuestion
+	for _, q := range p.Questions {
+		questions = append(questions, &webhookQuestion{Question: q.Question, AnswerOptions: newWebhookAnswerOptions(p, q.AnswerOptions)})
+	}
+	creator := p.Creator
+	if p.Settings.AnonymousCreator {
+		creator = ""
+	}
+
+	return &webhookPoll{
+		ID:            p.ID,
+		PostID:        p.PostID,
+		ChannelID:     p.ChannelID,
+		Creator:       creator,
+		Question:      p.Question,
+		AnswerOptions: newWebhookAnswerOptions(p, p.AnswerOptions),
+		Questions:     questions,
+		Settings:      p.Settings,
+		CreatedAt:     p.CreatedAt,
+		EndedAt:       p.EndedAt,
+		PausedAt:      p.PausedAt,
+		Voters:        p.NumberOfVoters(),
+	}
+}
+
+// newWebhookPayload returns the payload for a given event
+func newWebhookPayload(event webhookEvent, p *poll.Poll, userID string) *webhookPayload {
+	if (event == webhookEventVoteCast && p.Settings.Anonymous) || (event != webhookEventVoteCast && p.Settings.AnonymousCreator) {
+		userID = ""
+	}
+
+	return &webhookPayload{
+		Event:     event,
+		Timestamp: model.GetMillis(),
+		UserID:    userID,
+		Poll:      newWebhookPoll(p),
+	}
+}
+
+// notifyWebhook sends an event to the configured webhook, if the event is enabled.
+// The request is sent in the background to not delay the user interaction. Failures are only logged.
+func (p *MatterpollPlugin) notifyWebhook(event webhookEvent, poll *poll.Poll, userID string) {
+	configuration := p.getConfiguration()
+	if !configuration.isWebhookEnabled(event) {
+		return
+	}
+
+	payload := newWebhookPayload(event, poll, userID)
+	go func() {
+		if err := sendWebhook(configuration.WebhookURL, configuration.WebhookSecret, payload); err != nil {
+			p.API.LogWarn("failed to send webhook", "event", string(event), "error", err.Error())
+		}
+	}()
+}
+
+// sendWebhook posts a payload to a given URL. The payload is signed if a secret is given.
+func sendWebhook(url, secret string, payload *webhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal payload")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, string(payload.Event))
+	if secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, body))
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookPayload returns the hex encoded HMAC-SHA256 of a given body
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,223 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhookPayload(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	t.Run("vote cast", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+
+		payload := newWebhookPayload(webhookEventVoteCast, p, "userID2")
+
+		assert.Equal(t, &webhookPayload{
+			Event:     webhookEventVoteCast,
+			Timestamp: 1234567890,
+			UserID:    "userID2",
+			Poll: &webhookPoll{
+				ID:        testutils.GetPollID(),
+				PostID:    "postID1",
+				ChannelID: "channelID1",
+				Creator:   "userID1",
+				Question:  "Question",
+				AnswerOptions: []*webhookAnswerOption{
+					{Answer: "Answer 1", Votes: 3},
+					{Answer: "Answer 2", Votes: 1},
+					{Answer: "Answer 3", Votes: 0},
+				},
+				CreatedAt: 1234567890,
+				Voters:    4,
+			},
+		}, payload)
+	})
+
+	t.Run("vote cast in anonymous poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true})
+
+		payload := newWebhookPayload(webhookEventVoteCast, p, "userID2")
+
+		assert.Equal(t, "", payload.UserID)
+	})
+
+	t.Run("vote cast in poll with anonymous creator", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{AnonymousCreator: true})
+
+		payload := newWebhookPayload(webhookEventVoteCast, p, "userID2")
+
+		assert.Equal(t, "userID2", payload.UserID)
+		assert.Equal(t, "", payload.Poll.Creator)
+	})
+
+	t.Run("poll with anonymous creator ended", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{AnonymousCreator: true})
+
+		payload := newWebhookPayload(webhookEventPollEnded, p, "userID1")
+
+		assert.Equal(t, "", payload.UserID)
+		assert.Equal(t, "", payload.Poll.Creator)
+	})
+
+	t.Run("survey", func(t *testing.T) {
+		p := testutils.GetSurveyWithVotes()
+
+		payload := newWebhookPayload(webhookEventVoteCast, p, "userID2")
+
+		assert.Equal(t, []*webhookAnswerOption{}, payload.Poll.AnswerOptions)
+		assert.Equal(t, []*webhookQuestion{{
+			Question: "Question 1",
+			AnswerOptions: []*webhookAnswerOption{
+				{Answer: "Yes", Votes: 2},
+				{Answer: "No", Votes: 1},
+			},
+		}, {
+			Question: "Question 2",
+			AnswerOptions: []*webhookAnswerOption{
+				{Answer: "Answer 1", Votes: 0},
+				{Answer: "Answer 2", Votes: 1},
+				{Answer: "Answer 3", Votes: 0},
+			},
+		}}, payload.Poll.Questions)
+		assert.Equal(t, 3, payload.Poll.Voters)
+	})
+
+	t.Run("anonymous poll ended", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true})
+
+		payload := newWebhookPayload(webhookEventPollEnded, p, "userID1")
+
+		assert.Equal(t, "userID1", payload.UserID)
+	})
+}
+
+func TestSendWebhook(t *testing.T) {
+	payload := newWebhookPayload(webhookEventPollCreated, testutils.GetPoll(), "userID1")
+
+	t.Run("signed payload", func(t *testing.T) {
+		var (
+			body      []byte
+			event     string
+			signature string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = ioutil.ReadAll(r.Body)
+			event = r.Header.Get(webhookEventHeader)
+			signature = r.Header.Get(webhookSignatureHeader)
+		}))
+		defer server.Close()
+
+		err := sendWebhook(server.URL, "secret", payload)
+		require.Nil(t, err)
+
+		var received webhookPayload
+		require.Nil(t, json.Unmarshal(body, &received))
+		assert.Equal(t, payload, &received)
+		assert.Equal(t, string(webhookEventPollCreated), event)
+		assert.Equal(t, signWebhookPayload("secret", body), signature)
+	})
+
+	t.Run("without secret", func(t *testing.T) {
+		signature := "unset"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signature = r.Header.Get(webhookSignatureHeader)
+		}))
+		defer server.Close()
+
+		err := sendWebhook(server.URL, "", payload)
+		require.Nil(t, err)
+		assert.Equal(t, "", signature)
+	})
+
+	t.Run("error status code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := sendWebhook(server.URL, "", payload)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		err := sendWebhook(server.URL, "", payload)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	for name, test := range map[string]struct {
+		WebhookEvents  string
+		Event          webhookEvent
+		ShouldBeCalled bool
+	}{
+		"All events enabled": {
+			WebhookEvents:  "",
+			Event:          webhookEventVoteCast,
+			ShouldBeCalled: true,
+		},
+		"Event enabled": {
+			WebhookEvents:  "poll_created, poll_ended",
+			Event:          webhookEventPollEnded,
+			ShouldBeCalled: true,
+		},
+		"Event disabled": {
+			WebhookEvents:  "poll_created,poll_ended",
+			Event:          webhookEventVoteCast,
+			ShouldBeCalled: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			events := make(chan string, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				events <- r.Header.Get(webhookEventHeader)
+			}))
+			defer server.Close()
+
+			p := setupTestPlugin(t, &plugintest.API{}, &mockstore.Store{})
+			p.setConfiguration(&configuration{
+				Trigger:       "poll",
+				WebhookURL:    server.URL,
+				WebhookEvents: test.WebhookEvents,
+			})
+
+			p.notifyWebhook(test.Event, testutils.GetPoll(), "userID1")
+
+			select {
+			case event := <-events:
+				assert.True(t, test.ShouldBeCalled)
+				assert.Equal(t, string(test.Event), event)
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, test.ShouldBeCalled)
+			}
+		})
+	}
+
+	t.Run("Webhook disabled", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		p := setupTestPlugin(t, api, &mockstore.Store{})
+
+		p.notifyWebhook(webhookEventPollCreated, testutils.GetPoll(), "userID1")
+	})
+}
@@ -0,0 +1,41 @@
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+const (
+	// websocketEventPollUpdated is published whenever the votes or answer options of a poll change
+	websocketEventPollUpdated = "poll_updated"
+	// websocketEventPollEnded is published once a poll has ended
+	websocketEventPollEnded = "poll_ended"
+)
+
+// newWebsocketPayload returns the payload of a WebSocket event for a given poll.
+// It uses the same representation of the poll as webhooks, but leaves out the results of running secret polls,
+// because the event is received by every member of the channel.
+func newWebsocketPayload(p *poll.Poll) map[string]interface{} {
+	payload := map[string]interface{}{
+		"poll_id": p.ID,
+		"post_id": p.PostID,
+	}
+	if !p.Settings.Secret || p.IsEnded() {
+		payload["poll"] = newWebhookPoll(p)
+	}
+	return payload
+}
+
+// publishPollEvent sends a WebSocket event about a given poll to all members of its channel and of the channels it got cross-posted to,
+// so clients can show live results without polling the API.
+func (p *MatterpollPlugin) publishPollEvent(event string, poll *poll.Poll) {
+	payload := newWebsocketPayload(poll)
+	p.API.PublishWebSocketEvent(event, payload, &model.WebsocketBroadcast{
+		ChannelId: poll.ChannelID,
+	})
+	for _, crossPost := range poll.CrossPosts {
+		p.API.PublishWebSocketEvent(event, payload, &model.WebsocketBroadcast{
+			ChannelId: crossPost.ChannelID,
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebsocketPayload(t *testing.T) {
+	t.Run("running poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.PostID = "postID1"
+		p.ChannelID = "channelID1"
+
+		payload := newWebsocketPayload(p)
+
+		assert.Equal(t, map[string]interface{}{
+			"poll_id": testutils.GetPollID(),
+			"post_id": "postID1",
+			"poll":    newWebhookPoll(p),
+		}, payload)
+	})
+
+	t.Run("running secret poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true})
+		p.PostID = "postID1"
+
+		payload := newWebsocketPayload(p)
+
+		assert.Equal(t, map[string]interface{}{
+			"poll_id": testutils.GetPollID(),
+			"post_id": "postID1",
+		}, payload)
+	})
+
+	t.Run("ended secret poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true})
+		p.PostID = "postID1"
+		p.EndedAt = 1234567890
+
+		payload := newWebsocketPayload(p)
+
+		assert.Equal(t, newWebhookPoll(p), payload["poll"])
+	})
+}
+
+func TestPublishPollEvent(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+	p.PostID = "postID1"
+	p.ChannelID = "channelID1"
+
+	api := &plugintest.API{}
+	api.On("PublishWebSocketEvent", websocketEventPollUpdated, newWebsocketPayload(p), &model.WebsocketBroadcast{ChannelId: "channelID1"}).Return()
+	defer api.AssertExpectations(t)
+	plugin := setupTestPlugin(t, api, &mockstore.Store{})
+
+	plugin.publishPollEvent(websocketEventPollUpdated, p)
+}
@@ -0,0 +1,100 @@
+package poll
+
+import (
+	"sort"
+	"time"
+)
+
+// VoteTimeBuckets are the upper bounds of the time ranges after the start of a poll by which Analytics group the voters.
+// Voters who voted after the last bound get grouped into an additional, open range.
+var VoteTimeBuckets = []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour}
+
+// Analytics describes how voters took part in a poll
+type Analytics struct {
+	// Voters is the number of users who voted
+	Voters int
+	// VotersPerBucket counts the voters by the time of their first vote, see VoteTimeBuckets.
+	// It has one more entry than VoteTimeBuckets for the voters who voted after the last bound.
+	VotersPerBucket []int
+	// UnknownVoteTimes counts the voters whose time of voting isn't known, because they voted before it got stored
+	UnknownVoteTimes int
+	// FastestVoters are the voters who voted first, the fastest one first
+	FastestVoters []*Responder
+	// VoteChanges counts how often voters updated or reset a vote they had already cast
+	VoteChanges int
+}
+
+// Responder is a voter together with the time after the start of the poll at which the voter first voted
+type Responder struct {
+	UserID string
+	After  time.Duration
+}
+
+// Analytics returns how voters took part in the poll. It lists up to a given number of the fastest voters.
+func (p *Poll) Analytics(fastest int) *Analytics {
+	voters := p.allVoters()
+	analytics := &Analytics{
+		Voters:          len(voters),
+		VotersPerBucket: make([]int, len(VoteTimeBuckets)+1),
+		VoteChanges:     p.VoteChanges,
+	}
+
+	responders := []*Responder{}
+	for _, userID := range voters {
+		votedAt, ok := p.VotedAt[userID]
+		if !ok {
+			analytics.UnknownVoteTimes++
+			continue
+		}
+		after := time.Duration(votedAt-p.StartAt()) * time.Millisecond
+		if after < 0 {
+			after = 0
+		}
+		responders = append(responders, &Responder{UserID: userID, After: after})
+
+		bucket := len(VoteTimeBuckets)
+		for i, bound := range VoteTimeBuckets {
+			if after < bound {
+				bucket = i
+				break
+			}
+		}
+		analytics.VotersPerBucket[bucket]++
+	}
+
+	sort.SliceStable(responders, func(i, j int) bool {
+		return responders[i].After < responders[j].After
+	})
+	if len(responders) > fastest {
+		responders = responders[:fastest]
+	}
+	analytics.FastestVoters = responders
+	return analytics
+}
+
+// allVoters returns the user IDs of all voters, including those of ranked, rating and estimate polls
+func (p *Poll) allVoters() []string {
+	var votes map[string][]int
+	switch p.Settings.VoteMode {
+	case VoteModeRanked:
+		votes = p.Rankings
+	case VoteModeRating:
+		votes = p.Ratings
+	case VoteModeEstimate:
+		voters := make([]string, 0, len(p.Estimates))
+		for userID := range p.Estimates {
+			voters = append(voters, userID)
+		}
+		sort.Strings(voters)
+		return voters
+	default:
+		return p.voters()
+	}
+
+	voters := make([]string, 0, len(votes))
+	for userID := range votes {
+		voters = append(voters, userID)
+	}
+	sort.Strings(voters)
+	return voters
+}
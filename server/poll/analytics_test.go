@@ -0,0 +1,127 @@
+package poll_test
+
+import (
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollAnalytics(t *testing.T) {
+	minute := int64(60 * 1000)
+
+	for name, test := range map[string]struct {
+		Poll              *poll.Poll
+		Fastest           int
+		ExpectedAnalytics *poll.Analytics
+	}{
+		"No votes": {
+			Poll:    testutils.GetPoll(),
+			Fastest: 3,
+			ExpectedAnalytics: &poll.Analytics{
+				VotersPerBucket: []int{0, 0, 0, 0, 0},
+				FastestVoters:   []*poll.Responder{},
+			},
+		},
+		"Votes": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.VotedAt = map[string]int64{
+					"userID1": p.CreatedAt + 3*minute,
+					"userID2": p.CreatedAt + 2*24*60*minute,
+					"userID3": p.CreatedAt + 8*24*60*minute,
+					"userID4": p.CreatedAt + minute,
+				}
+				p.VoteChanges = 3
+				return p
+			}(),
+			Fastest: 2,
+			ExpectedAnalytics: &poll.Analytics{
+				Voters:          4,
+				VotersPerBucket: []int{2, 0, 0, 1, 1},
+				FastestVoters: []*poll.Responder{
+					{UserID: "userID4", After: time.Minute},
+					{UserID: "userID1", After: 3 * time.Minute},
+				},
+				VoteChanges: 3,
+			},
+		},
+		"Votes before vote times got stored": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.VotedAt = map[string]int64{"userID2": p.CreatedAt + 90*minute}
+				return p
+			}(),
+			Fastest: 3,
+			ExpectedAnalytics: &poll.Analytics{
+				Voters:           4,
+				VotersPerBucket:  []int{0, 0, 1, 0, 0},
+				UnknownVoteTimes: 3,
+				FastestVoters:    []*poll.Responder{{UserID: "userID2", After: 90 * time.Minute}},
+			},
+		},
+		"Scheduled poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.Settings.PostAt = p.CreatedAt + 60*minute
+				p.AnswerOptions[1].Voter = nil
+				p.AnswerOptions[0].Voter = []string{"userID1"}
+				p.VotedAt = map[string]int64{"userID1": p.CreatedAt + 70*minute}
+				return p
+			}(),
+			Fastest: 3,
+			ExpectedAnalytics: &poll.Analytics{
+				Voters:          1,
+				VotersPerBucket: []int{0, 1, 0, 0, 0},
+				FastestVoters:   []*poll.Responder{{UserID: "userID1", After: 10 * time.Minute}},
+			},
+		},
+		"Rating poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRatings()
+				p.VotedAt = map[string]int64{"userID3": p.CreatedAt}
+				return p
+			}(),
+			Fastest: 3,
+			ExpectedAnalytics: &poll.Analytics{
+				Voters:           3,
+				VotersPerBucket:  []int{1, 0, 0, 0, 0},
+				UnknownVoteTimes: 2,
+				FastestVoters:    []*poll.Responder{{UserID: "userID3", After: 0}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedAnalytics, test.Poll.Analytics(test.Fastest))
+		})
+	}
+}
+
+func TestPollVoteTimes(t *testing.T) {
+	now := int64(1234567890)
+	patch := monkey.Patch(model.GetMillis, func() int64 { return now })
+	defer patch.Unpatch()
+
+	p := testutils.GetPoll()
+	require.Nil(t, p.UpdateVote("userID1", 0))
+	assert.Equal(t, map[string]int64{"userID1": 1234567890}, p.VotedAt)
+	assert.Equal(t, 0, p.VoteChanges)
+
+	now += 1000
+	require.Nil(t, p.UpdateVote("userID1", 1))
+	require.Nil(t, p.UpdateVote("userID2", 1))
+	assert.Equal(t, map[string]int64{"userID1": 1234567890, "userID2": 1234568890}, p.VotedAt)
+	assert.Equal(t, 1, p.VoteChanges)
+
+	require.Nil(t, p.ResetVote("userID1"))
+	assert.Equal(t, map[string]int64{"userID2": 1234568890}, p.VotedAt)
+	assert.Equal(t, 2, p.VoteChanges)
+
+	p.EraseUser("userID2")
+	assert.Equal(t, map[string]int64{}, p.VotedAt)
+}
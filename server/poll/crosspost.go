@@ -0,0 +1,75 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxCrossPostChannels is the number of further channels a poll may be cross-posted to
+const MaxCrossPostChannels = 10
+
+// Post identifies a post that displays a poll
+type Post struct {
+	ChannelID string
+	PostID    string
+}
+
+// parseChannels returns the channel names of a comma separated list of channels like town-square,~dev.
+// The leading ~ is optional and duplicates are left out.
+func parseChannels(value string) ([]string, error) {
+	channels := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "~")
+		if name == "" || containsString(channels, name) {
+			continue
+		}
+		channels = append(channels, name)
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("Invalid channels %s", value)
+	}
+	if len(channels) > MaxCrossPostChannels {
+		return nil, fmt.Errorf("A poll can be cross-posted to at most %d channels", MaxCrossPostChannels)
+	}
+	return channels, nil
+}
+
+// ResolveChannels replaces the channel names the poll gets cross-posted to by the channel IDs returned by lookup.
+// The channel with a given ID, which the poll gets posted in anyway, is left out.
+func (p *Poll) ResolveChannels(channelID string, lookup func(name string) (string, error)) error {
+	channels := []string{}
+	for _, name := range p.Settings.Channels {
+		id, err := lookup(name)
+		if err != nil {
+			return err
+		}
+		if id == channelID || containsString(channels, id) {
+			continue
+		}
+		channels = append(channels, id)
+	}
+	if len(channels) == 0 {
+		channels = nil
+	}
+	p.Settings.Channels = channels
+	return nil
+}
+
+// IsCrossPosted returns true if the poll is displayed by posts in further channels, which share its votes
+func (p *Poll) IsCrossPosted() bool {
+	return len(p.CrossPosts) > 0
+}
+
+// Posts returns the post that displays the poll in its channel, followed by its cross posts.
+// It's empty if the poll hasn't been posted yet.
+func (p *Poll) Posts() []*Post {
+	if p.PostID == "" {
+		return []*Post{}
+	}
+	return append([]*Post{{ChannelID: p.ChannelID, PostID: p.PostID}}, p.CrossPosts...)
+}
+
+// ChannelIDs returns the IDs of the channel the poll is posted in and of the channels it gets cross-posted to
+func (p *Poll) ChannelIDs() []string {
+	return append([]string{p.ChannelID}, p.Settings.Channels...)
+}
@@ -0,0 +1,71 @@
+package poll_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveChannels(t *testing.T) {
+	lookup := func(name string) (string, error) {
+		switch name {
+		case "town-square":
+			return "channelID1", nil
+		case "dev":
+			return "channelID2", nil
+		}
+		return "", fmt.Errorf("unknown channel %s", name)
+	}
+
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Channels: []string{"dev", "town-square"}})
+
+		require.Nil(t, p.ResolveChannels("channelID3", lookup))
+		assert.Equal(t, []string{"channelID2", "channelID1"}, p.Settings.Channels)
+	})
+	t.Run("channel of the poll is left out", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Channels: []string{"town-square"}})
+
+		require.Nil(t, p.ResolveChannels("channelID1", lookup))
+		assert.Nil(t, p.Settings.Channels)
+	})
+	t.Run("unknown channel", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Channels: []string{"dev", "random"}})
+
+		assert.NotNil(t, p.ResolveChannels("channelID1", lookup))
+	})
+	t.Run("no channels", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		require.Nil(t, p.ResolveChannels("channelID1", lookup))
+		assert.Nil(t, p.Settings.Channels)
+	})
+}
+
+func TestPollPosts(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{Channels: []string{"channelID2", "channelID3"}})
+	assert.Empty(t, p.Posts())
+	assert.False(t, p.IsCrossPosted())
+
+	p.PostID = "postID1"
+	p.ChannelID = "channelID1"
+	assert.Equal(t, []*poll.Post{{ChannelID: "channelID1", PostID: "postID1"}}, p.Posts())
+	assert.Equal(t, []string{"channelID1", "channelID2", "channelID3"}, p.ChannelIDs())
+
+	p.CrossPosts = []*poll.Post{{ChannelID: "channelID2", PostID: "postID2"}}
+	assert.True(t, p.IsCrossPosted())
+	assert.Equal(t, []*poll.Post{
+		{ChannelID: "channelID1", PostID: "postID1"},
+		{ChannelID: "channelID2", PostID: "postID2"},
+	}, p.Posts())
+
+	p2 := p.Copy()
+	p2.CrossPosts[0].PostID = "postID3"
+	p2.Settings.Channels[0] = "channelID4"
+	assert.Equal(t, "postID2", p.CrossPosts[0].PostID)
+	assert.Equal(t, "channelID2", p.Settings.Channels[0])
+}
@@ -0,0 +1,103 @@
+package poll
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// CanDelegate returns true if voters of the poll may delegate their vote.
+// Only polls whose voters pick answer options support it, since a delegate casts a single ballot for all their delegators.
+func (p *Poll) CanDelegate() bool {
+	return !p.IsSurvey() && p.Settings.VoteMode == VoteModeSingle && !p.Settings.Receipts
+}
+
+// Delegate lets a given user delegate their vote to another user, whose votes then count for both of them.
+// Delegations can't be chained, so a delegate can't delegate their vote in turn.
+func (p *Poll) Delegate(userID, delegateID string) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if !p.CanDelegate() {
+		return fmt.Errorf("votes of this poll can't be delegated")
+	}
+	if userID == "" || delegateID == "" {
+		return fmt.Errorf("invalid userID")
+	}
+	if userID == delegateID {
+		return fmt.Errorf("users can't delegate their vote to themselves")
+	}
+	if p.HasDelegated(userID) {
+		return fmt.Errorf("user has already delegated their vote")
+	}
+	if p.HasVoted(userID) {
+		return fmt.Errorf("user has already voted")
+	}
+	if p.HasDelegated(delegateID) || len(p.DelegatorsOf(userID)) > 0 {
+		return fmt.Errorf("delegations can't be chained")
+	}
+
+	if p.Delegations == nil {
+		p.Delegations = map[string]string{}
+	}
+	p.Delegations[userID] = delegateID
+	return nil
+}
+
+// HasDelegated returns true if a given user delegated their vote to another user
+func (p *Poll) HasDelegated(userID string) bool {
+	_, ok := p.Delegations[userID]
+	return ok
+}
+
+// DelegatorsOf returns the IDs of the users that delegated their vote to a given user, sorted to keep their order stable
+func (p *Poll) DelegatorsOf(delegateID string) []string {
+	delegators := []string{}
+	for userID, delegate := range p.Delegations {
+		if delegate == delegateID {
+			delegators = append(delegators, userID)
+		}
+	}
+	sort.Strings(delegators)
+	return delegators
+}
+
+// VoteWeight returns the number of votes a vote of a given user counts for.
+// That's the weight of the user plus the weights of the users that delegated their vote to the user.
+func (p *Poll) VoteWeight(userID string) int {
+	weight := p.WeightOf(userID)
+	for _, delegator := range p.DelegatorsOf(userID) {
+		weight += p.WeightOf(delegator)
+	}
+	return weight
+}
+
+// VotesOf returns the number of votes an answer option got, taking weights and delegated votes into account
+func (p *Poll) VotesOf(o *AnswerOption) int {
+	if len(p.Delegations) == 0 && !p.IsWeighted() {
+		return len(o.Voter)
+	}
+	votes := 0
+	for _, userID := range o.Voter {
+		votes += p.VoteWeight(userID)
+	}
+	return votes
+}
+
+// withDelegations wraps convert, so the display names of delegates show how many votes were delegated to them, e.g. "@a (+2)"
+func (p *Poll) withDelegations(convert func(string) (string, *model.AppError)) func(string) (string, *model.AppError) {
+	if len(p.Delegations) == 0 {
+		return convert
+	}
+	return func(userID string) (string, *model.AppError) {
+		name, err := convert(userID)
+		if err != nil {
+			return "", err
+		}
+		if delegated := len(p.DelegatorsOf(userID)); delegated > 0 {
+			name = fmt.Sprintf("%s (+%d)", name, delegated)
+		}
+		return name, nil
+	}
+}
@@ -0,0 +1,197 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegate(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll                *poll.Poll
+		UserID              string
+		DelegateID          string
+		ShouldError         bool
+		ExpectedDelegations map[string]string
+	}{
+		"all fine": {
+			Poll:                testutils.GetPollWithVotes(),
+			UserID:              "userID5",
+			DelegateID:          "userID1",
+			ExpectedDelegations: map[string]string{"userID5": "userID1"},
+		},
+		"delegate hasn't voted yet": {
+			Poll:                testutils.GetPoll(),
+			UserID:              "userID1",
+			DelegateID:          "userID2",
+			ExpectedDelegations: map[string]string{"userID1": "userID2"},
+		},
+		"multiple delegators": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.Delegations = map[string]string{"userID6": "userID1"}
+				return p
+			}(),
+			UserID:              "userID5",
+			DelegateID:          "userID1",
+			ExpectedDelegations: map[string]string{"userID5": "userID1", "userID6": "userID1"},
+		},
+		"user has already voted": {
+			Poll:        testutils.GetPollWithVotes(),
+			UserID:      "userID2",
+			DelegateID:  "userID1",
+			ShouldError: true,
+		},
+		"user has already delegated": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.Delegations = map[string]string{"userID5": "userID1"}
+				return p
+			}(),
+			UserID:              "userID5",
+			DelegateID:          "userID4",
+			ShouldError:         true,
+			ExpectedDelegations: map[string]string{"userID5": "userID1"},
+		},
+		"delegate has delegated": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPoll()
+				p.Delegations = map[string]string{"userID2": "userID3"}
+				return p
+			}(),
+			UserID:              "userID1",
+			DelegateID:          "userID2",
+			ShouldError:         true,
+			ExpectedDelegations: map[string]string{"userID2": "userID3"},
+		},
+		"user is a delegate": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPoll()
+				p.Delegations = map[string]string{"userID2": "userID1"}
+				return p
+			}(),
+			UserID:              "userID1",
+			DelegateID:          "userID3",
+			ShouldError:         true,
+			ExpectedDelegations: map[string]string{"userID2": "userID1"},
+		},
+		"delegate to oneself": {
+			Poll:        testutils.GetPoll(),
+			UserID:      "userID1",
+			DelegateID:  "userID1",
+			ShouldError: true,
+		},
+		"empty delegate": {
+			Poll:        testutils.GetPoll(),
+			UserID:      "userID1",
+			DelegateID:  "",
+			ShouldError: true,
+		},
+		"poll has ended": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPoll()
+				p.EndedAt = 1234567890
+				return p
+			}(),
+			UserID:      "userID1",
+			DelegateID:  "userID2",
+			ShouldError: true,
+		},
+		"ranked poll": {
+			Poll:        testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked}),
+			UserID:      "userID1",
+			DelegateID:  "userID2",
+			ShouldError: true,
+		},
+		"approval poll": {
+			Poll:        testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeApproval}),
+			UserID:      "userID1",
+			DelegateID:  "userID2",
+			ShouldError: true,
+		},
+		"poll with receipts": {
+			Poll:        testutils.GetPollWithSettings(poll.Settings{Receipts: true, Anonymous: true, LockVotes: true}),
+			UserID:      "userID1",
+			DelegateID:  "userID2",
+			ShouldError: true,
+		},
+		"survey": {
+			Poll:        testutils.GetSurveyWithVotes(),
+			UserID:      "userID5",
+			DelegateID:  "userID1",
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := test.Poll.Delegate(test.UserID, test.DelegateID)
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.ExpectedDelegations, test.Poll.Delegations)
+		})
+	}
+}
+
+func TestDelegatedVotes(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+	p.Delegations = map[string]string{"userID6": "userID4", "userID5": "userID4", "userID7": "userID8"}
+
+	assert.True(t, p.HasDelegated("userID5"))
+	assert.False(t, p.HasDelegated("userID4"))
+	assert.Equal(t, []string{"userID5", "userID6"}, p.DelegatorsOf("userID4"))
+	assert.Equal(t, 3, p.VoteWeight("userID4"))
+	assert.Equal(t, 1, p.VoteWeight("userID1"))
+
+	assert.Equal(t, 3, p.VotesOf(p.AnswerOptions[0]))
+	assert.Equal(t, 3, p.VotesOf(p.AnswerOptions[1]))
+	assert.Equal(t, 0, p.VotesOf(p.AnswerOptions[2]))
+
+	// Delegators can't vote themselves
+	assert.NotNil(t, p.UpdateVote("userID5", 0))
+	assert.NotNil(t, p.UpdateWriteIn("userID5", "Answer 4"))
+}
+
+func TestHaveAllEligibleVotersVotedDelegated(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{EndWhenAllVoted: true})
+	p.EligibleVoters = []string{"userID1", "userID4", "userID5"}
+	assert.False(t, p.HaveAllEligibleVotersVoted())
+
+	// The vote of the delegate covers the delegator
+	p.Delegations = map[string]string{"userID5": "userID4"}
+	assert.True(t, p.HaveAllEligibleVotersVoted())
+}
+
+func TestPollToPostActionsDelegations(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Progress: true})
+	p.Delegations = map[string]string{"userID5": "userID4", "userID6": "userID4"}
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Equal(t, "---\n**Poll Settings**: progress\n**Total votes**: 6\n**Delegated votes**: 2 voters delegated their vote", attachments[0].Text)
+	assert.Equal(t, "Answer 1 (3)", attachments[0].Actions[0].Name)
+	assert.Equal(t, "Answer 2 (3)", attachments[0].Actions[1].Name)
+}
+
+func TestPollToEndPollPostDelegations(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+	p := testutils.GetPollWithVotes()
+	p.Delegations = map[string]string{"userID5": "userID4"}
+
+	post, err := p.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe", converter)
+
+	assert.Nil(t, err)
+	attachments := post.Attachments()
+	assert.Equal(t, "This poll has ended. The results are:\n**Delegated votes**: 1 voter delegated their vote", attachments[0].Text)
+	assert.Equal(t, []*model.SlackAttachmentField{
+		{Short: true, Title: "Answer 1 (3 votes)", Value: "@userID1, @userID2 and @userID3"},
+		{Short: true, Title: "Answer 2 (2 votes)", Value: "@userID4 (+1)"},
+		{Short: true, Title: "Answer 3 (0 votes)", Value: ""},
+	}, attachments[0].Fields)
+}
@@ -0,0 +1,43 @@
+package poll
+
+import "encoding/json"
+
+const (
+	// DraftStepQuestion waits for the question of the poll
+	DraftStepQuestion = "question"
+	// DraftStepAnswerOptions waits for the answer options of the poll
+	DraftStepAnswerOptions = "options"
+	// DraftStepSettings waits for the settings of the poll
+	DraftStepSettings = "settings"
+	// DraftStepChannel waits for the channel the poll gets posted in
+	DraftStepChannel = "channel"
+)
+
+// Draft stores a poll a user is creating step by step in a direct message with the bot
+type Draft struct {
+	// Step is what the bot waits for next. See DraftStepQuestion and the following steps.
+	Step          string
+	Question      string   `json:",omitempty"`
+	AnswerOptions []string `json:",omitempty"`
+	Settings      []string `json:",omitempty"`
+}
+
+// NewDraft returns a draft that waits for the question
+func NewDraft() *Draft {
+	return &Draft{Step: DraftStepQuestion}
+}
+
+// EncodeToByte returns the draft as a byte array
+func (d *Draft) EncodeToByte() []byte {
+	b, _ := json.Marshal(d)
+	return b
+}
+
+// DecodeDraftFromByte tries to create a draft from a byte array
+func DecodeDraftFromByte(b []byte) *Draft {
+	var d Draft
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil
+	}
+	return &d
+}
@@ -0,0 +1,38 @@
+package poll
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// MaxEmailRecipients is the number of further email addresses the results of a poll may be sent to
+const MaxEmailRecipients = 10
+
+// parseEmailRecipients returns the addresses of a comma separated list of email addresses like hr@example.com,boss@example.com.
+// Addresses are stored in lower case and duplicates are left out.
+func parseEmailRecipients(value string) ([]string, error) {
+	recipients := []string{}
+	for _, address := range strings.Split(value, ",") {
+		address = strings.ToLower(strings.TrimSpace(address))
+		if address == "" || containsString(recipients, address) {
+			continue
+		}
+		if parsed, err := mail.ParseAddress(address); err != nil || parsed.Address != address {
+			return nil, fmt.Errorf("Invalid email address %s", address)
+		}
+		recipients = append(recipients, address)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("Invalid email addresses %s", value)
+	}
+	if len(recipients) > MaxEmailRecipients {
+		return nil, fmt.Errorf("The results of a poll can be emailed to at most %d further addresses", MaxEmailRecipients)
+	}
+	return recipients, nil
+}
+
+// HasEmailSummary returns true if the results of the poll get emailed once it ended
+func (p *Poll) HasEmailSummary() bool {
+	return p.Settings.EmailCreator || len(p.Settings.EmailRecipients) > 0
+}
@@ -0,0 +1,31 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollHasEmailSummary(t *testing.T) {
+	assert.True(t, testutils.GetPollWithSettings(poll.Settings{EmailCreator: true}).HasEmailSummary())
+	assert.True(t, testutils.GetPollWithSettings(poll.Settings{EmailRecipients: []string{"hr@example.com"}}).HasEmailSummary())
+	assert.False(t, testutils.GetPoll().HasEmailSummary())
+}
+
+func TestPollToPostActionsEmail(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{Progress: true, EmailCreator: true, EmailRecipients: []string{"hr@example.com"}})
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Contains(t, attachments[0].Text, "**Poll Settings**: progress, email")
+	assert.NotContains(t, attachments[0].Text, "hr@example.com")
+}
+
+func TestPollCopyEmailRecipients(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{EmailRecipients: []string{"hr@example.com"}})
+
+	p2 := p.Copy()
+	p2.Settings.EmailRecipients[0] = "boss@example.com"
+	assert.Equal(t, []string{"hr@example.com"}, p.Settings.EmailRecipients)
+}
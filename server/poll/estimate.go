@@ -0,0 +1,175 @@
+package poll
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EstimateDistributionBuckets is the highest number of bars the distribution of an estimate poll shows.
+// If voters submitted more distinct estimates, they are grouped into ranges of equal width.
+const EstimateDistributionBuckets = 8
+
+// EstimateResult stores the statistics of the estimates of an estimate poll
+type EstimateResult struct {
+	Count  int
+	Mean   float64
+	Median float64
+	Min    float64
+	Max    float64
+	// Distribution counts the estimates per value or range, starting with the lowest one
+	Distribution []*EstimateBucket
+}
+
+// EstimateBucket is a value or range of values of the distribution of an estimate poll
+type EstimateBucket struct {
+	// From and To are the bounds of the range. They are equal if the bucket holds a single value.
+	From   float64
+	To     float64
+	Voters []string
+}
+
+// Label returns the value of the bucket, or its range like 5–10
+func (b *EstimateBucket) Label() string {
+	if b.From == b.To {
+		return formatEstimate(b.From)
+	}
+	return formatEstimate(b.From) + "–" + formatEstimate(b.To)
+}
+
+// ParseEstimate returns the number a voter typed in as estimate, e.g. 5 or 2.5
+func ParseEstimate(value string) (float64, error) {
+	estimate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(estimate) || math.IsInf(estimate, 0) {
+		return 0, fmt.Errorf("Invalid estimate %s. Enter a number, e.g. 5 or 2.5", value)
+	}
+	return estimate, nil
+}
+
+// formatEstimate returns a number with at most two decimals and without trailing zeros, e.g. 5 or 2.75
+func formatEstimate(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// NeedsAnswerOptions returns true if a poll with the given poll settings gets the default answer options when it's created without any.
+// Estimate polls never have answer options.
+func NeedsAnswerOptions(settings []string) bool {
+	for _, s := range settings {
+		if s == "votemode="+string(VoteModeEstimate) {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateEstimate stores the number a given user submitted in an estimate poll
+func (p *Poll) UpdateEstimate(userID string, estimate float64) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if p.Settings.VoteMode != VoteModeEstimate {
+		return fmt.Errorf("poll is not an estimate poll")
+	}
+	if userID == "" {
+		return fmt.Errorf("invalid userID")
+	}
+	if math.IsNaN(estimate) || math.IsInf(estimate, 0) {
+		return fmt.Errorf("invalid estimate")
+	}
+	if p.IsVoteLocked(userID) {
+		return fmt.Errorf("vote is locked")
+	}
+
+	hasVoted := p.HasVoted(userID)
+	if p.Estimates == nil {
+		p.Estimates = map[string]float64{}
+	}
+	p.Estimates[userID] = estimate
+	p.recordVote(userID, hasVoted)
+	return nil
+}
+
+// EstimateOf returns the estimate of a given user as text. It's empty if the user hasn't submitted an estimate.
+func (p *Poll) EstimateOf(userID string) string {
+	estimate, ok := p.Estimates[userID]
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(estimate, 'f', -1, 64)
+}
+
+// EstimateResults returns the statistics of the estimates of an estimate poll.
+// The distribution has a bucket per distinct estimate, unless there are more than EstimateDistributionBuckets of them.
+func (p *Poll) EstimateResults() *EstimateResult {
+	return p.estimateResults(EstimateDistributionBuckets)
+}
+
+// estimateResults returns the statistics of the estimates with at most a given number of buckets. Zero gives a bucket per distinct estimate.
+func (p *Poll) estimateResults(maxBuckets int) *EstimateResult {
+	voters := make([]string, 0, len(p.Estimates))
+	for userID := range p.Estimates {
+		voters = append(voters, userID)
+	}
+	// Voters with the same estimate are sorted by ID, so the result doesn't depend on the order of the map
+	sort.Slice(voters, func(i, j int) bool {
+		a, b := p.Estimates[voters[i]], p.Estimates[voters[j]]
+		if a != b {
+			return a < b
+		}
+		return voters[i] < voters[j]
+	})
+
+	result := &EstimateResult{Count: len(voters), Distribution: []*EstimateBucket{}}
+	if result.Count == 0 {
+		return result
+	}
+	total := 0.0
+	for _, userID := range voters {
+		total += p.Estimates[userID]
+	}
+	result.Mean = total / float64(result.Count)
+	result.Min, result.Max = p.Estimates[voters[0]], p.Estimates[voters[result.Count-1]]
+	if middle := result.Count / 2; result.Count%2 == 1 {
+		result.Median = p.Estimates[voters[middle]]
+	} else {
+		result.Median = (p.Estimates[voters[middle-1]] + p.Estimates[voters[middle]]) / 2
+	}
+
+	distinct := 1
+	for i := 1; i < len(voters); i++ {
+		if p.Estimates[voters[i]] != p.Estimates[voters[i-1]] {
+			distinct++
+		}
+	}
+	if maxBuckets == 0 || distinct <= maxBuckets {
+		for _, userID := range voters {
+			estimate := p.Estimates[userID]
+			if n := len(result.Distribution); n == 0 || result.Distribution[n-1].From != estimate {
+				result.Distribution = append(result.Distribution, &EstimateBucket{From: estimate, To: estimate})
+			}
+			last := result.Distribution[len(result.Distribution)-1]
+			last.Voters = append(last.Voters, userID)
+		}
+		return result
+	}
+
+	width := (result.Max - result.Min) / float64(maxBuckets)
+	for i := 0; i < maxBuckets; i++ {
+		result.Distribution = append(result.Distribution, &EstimateBucket{
+			From:   result.Min + float64(i)*width,
+			To:     result.Min + float64(i+1)*width,
+			Voters: []string{},
+		})
+	}
+	for _, userID := range voters {
+		// The highest estimate belongs to the last bucket instead of one of its own
+		i := int((p.Estimates[userID] - result.Min) / width)
+		if i >= maxBuckets {
+			i = maxBuckets - 1
+		}
+		result.Distribution[i].Voters = append(result.Distribution[i].Voters, userID)
+	}
+	return result
+}
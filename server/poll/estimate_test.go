@@ -0,0 +1,213 @@
+package poll_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEstimate(t *testing.T) {
+	for name, test := range map[string]struct {
+		Value            string
+		ExpectedEstimate float64
+		ShouldError      bool
+	}{
+		"Integer":         {Value: "5", ExpectedEstimate: 5},
+		"Decimal":         {Value: " 2.5 ", ExpectedEstimate: 2.5},
+		"Negative":        {Value: "-3", ExpectedEstimate: -3},
+		"Empty":           {Value: "", ShouldError: true},
+		"Not a number":    {Value: "five", ShouldError: true},
+		"NaN":             {Value: "NaN", ShouldError: true},
+		"Infinite number": {Value: "Inf", ShouldError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			estimate, err := poll.ParseEstimate(test.Value)
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.ExpectedEstimate, estimate)
+			}
+		})
+	}
+}
+
+func TestNeedsAnswerOptions(t *testing.T) {
+	assert.True(t, poll.NeedsAnswerOptions(nil))
+	assert.True(t, poll.NeedsAnswerOptions([]string{"anonymous", "votemode=rating"}))
+	assert.False(t, poll.NeedsAnswerOptions([]string{"anonymous", "votemode=estimate"}))
+}
+
+func TestUpdateEstimate(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll              *poll.Poll
+		UserID            string
+		Estimate          float64
+		ExpectedEstimates map[string]float64
+		Error             bool
+	}{
+		"First estimate": {
+			Poll:              testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate}),
+			UserID:            "a",
+			Estimate:          2.5,
+			ExpectedEstimates: map[string]float64{"a": 2.5},
+		},
+		"Change estimate": {
+			Poll:     testutils.GetPollWithEstimates(),
+			UserID:   "userID1",
+			Estimate: 13,
+			ExpectedEstimates: map[string]float64{
+				"userID1": 13,
+				"userID2": 3,
+				"userID3": 8,
+				"userID4": 3,
+			},
+		},
+		"Estimate is locked": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithEstimates()
+				p.Settings.LockVotes = true
+				return p
+			}(),
+			UserID:   "userID1",
+			Estimate: 13,
+			ExpectedEstimates: map[string]float64{
+				"userID1": 5,
+				"userID2": 3,
+				"userID3": 8,
+				"userID4": 3,
+			},
+			Error: true,
+		},
+		"Not an estimate poll": {
+			Poll:              testutils.GetPoll(),
+			UserID:            "a",
+			Estimate:          1,
+			ExpectedEstimates: nil,
+			Error:             true,
+		},
+		"Invalid userID": {
+			Poll:              testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate}),
+			UserID:            "",
+			Estimate:          1,
+			ExpectedEstimates: nil,
+			Error:             true,
+		},
+		"Invalid estimate": {
+			Poll:              testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate}),
+			UserID:            "a",
+			Estimate:          math.NaN(),
+			ExpectedEstimates: nil,
+			Error:             true,
+		},
+		"Poll has ended": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate})
+				p.EndedAt = 1234567891
+				return p
+			}(),
+			UserID:            "a",
+			Estimate:          1,
+			ExpectedEstimates: nil,
+			Error:             true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := test.Poll.UpdateEstimate(test.UserID, test.Estimate)
+
+			if test.Error {
+				assert.NotNil(err)
+			} else {
+				assert.Nil(err)
+				assert.True(test.Poll.HasVoted(test.UserID))
+			}
+			assert.Equal(test.ExpectedEstimates, test.Poll.Estimates)
+		})
+	}
+}
+
+func TestPollEstimateOf(t *testing.T) {
+	p := testutils.GetPollWithEstimates()
+	p.Estimates["userID5"] = 2.75
+
+	assert.Equal(t, "5", p.EstimateOf("userID1"))
+	assert.Equal(t, "2.75", p.EstimateOf("userID5"))
+	assert.Equal(t, "", p.EstimateOf("userID6"))
+}
+
+func TestPollEstimateResults(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll           *poll.Poll
+		ExpectedResult *poll.EstimateResult
+		ExpectedLabels []string
+	}{
+		"No estimates": {
+			Poll:           testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate}),
+			ExpectedResult: &poll.EstimateResult{Distribution: []*poll.EstimateBucket{}},
+			ExpectedLabels: []string{},
+		},
+		"A bucket per value": {
+			Poll: testutils.GetPollWithEstimates(),
+			ExpectedResult: &poll.EstimateResult{
+				Count:  4,
+				Mean:   4.75,
+				Median: 4,
+				Min:    3,
+				Max:    8,
+				Distribution: []*poll.EstimateBucket{
+					{From: 3, To: 3, Voters: []string{"userID2", "userID4"}},
+					{From: 5, To: 5, Voters: []string{"userID1"}},
+					{From: 8, To: 8, Voters: []string{"userID3"}},
+				},
+			},
+			ExpectedLabels: []string{"3", "5", "8"},
+		},
+		"Ranges for many values": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate})
+				p.Estimates = map[string]float64{}
+				for i, estimate := range []float64{0, 1, 2, 3, 5, 8, 13, 21, 40} {
+					p.Estimates[string(rune('a'+i))] = estimate
+				}
+				return p
+			}(),
+			ExpectedResult: &poll.EstimateResult{
+				Count:  9,
+				Mean:   93.0 / 9,
+				Median: 5,
+				Min:    0,
+				Max:    40,
+				Distribution: []*poll.EstimateBucket{
+					{From: 0, To: 5, Voters: []string{"a", "b", "c", "d"}},
+					{From: 5, To: 10, Voters: []string{"e", "f"}},
+					{From: 10, To: 15, Voters: []string{"g"}},
+					{From: 15, To: 20, Voters: []string{}},
+					{From: 20, To: 25, Voters: []string{"h"}},
+					{From: 25, To: 30, Voters: []string{}},
+					{From: 30, To: 35, Voters: []string{}},
+					{From: 35, To: 40, Voters: []string{"i"}},
+				},
+			},
+			ExpectedLabels: []string{"0–5", "5–10", "10–15", "15–20", "20–25", "25–30", "30–35", "35–40"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			result := test.Poll.EstimateResults()
+
+			assert.Equal(test.ExpectedResult, result)
+			labels := []string{}
+			for _, b := range result.Distribution {
+				labels = append(labels, b.Label())
+			}
+			assert.Equal(test.ExpectedLabels, labels)
+		})
+	}
+}
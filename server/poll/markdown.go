@@ -0,0 +1,100 @@
+package poll
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// markdownPattern matches the inline Markdown that is rendered in attachment texts: links, emphasis, strikethrough, inline code and emojis
+	markdownPattern = regexp.MustCompile("\\[[^\\]]+\\]\\([^)]+\\)|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*|~~[^~]+~~|`[^`]+`|:[a-z0-9_+-]*[a-z][a-z0-9_+-]*:")
+
+	linkPattern          = regexp.MustCompile(`!?\[([^\]]+)\]\([^)]+\)`)
+	strongPattern        = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emphasisPattern      = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	strikethroughPattern = regexp.MustCompile(`~~([^~]+)~~`)
+	codePattern          = regexp.MustCompile("`([^`]+)`")
+
+	// blockPattern matches the start of a line that Markdown renders as heading, quote, list item or horizontal rule
+	blockPattern = regexp.MustCompile(`^(#|>|[-+*](\s|$)|\d+[.)](\s|$)|[-_*]{3,})`)
+)
+
+// hasMarkdown returns true if a given text contains inline Markdown
+func hasMarkdown(text string) bool {
+	return markdownPattern.MatchString(text)
+}
+
+// sanitizeMarkdown returns a given text as a single line of inline Markdown, so that it can be embedded in an attachment text
+// without breaking its layout. Line breaks are replaced by spaces, block syntax at the start is escaped and so is an unbalanced backtick.
+func sanitizeMarkdown(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if blockPattern.MatchString(text) {
+		text = `\` + text
+	}
+	if strings.Count(text, "`")%2 == 1 {
+		i := strings.LastIndex(text, "`")
+		text = text[:i] + `\` + text[i:]
+	}
+	return text
+}
+
+// stripMarkdown returns a given text without inline Markdown. It's used for attachment titles and button names, which are shown as plain text.
+// Links are replaced by their text. Emojis are kept, because their names are readable.
+func stripMarkdown(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = strongPattern.ReplaceAllString(text, "$1$2")
+	text = emphasisPattern.ReplaceAllString(text, "$1")
+	text = strikethroughPattern.ReplaceAllString(text, "$1")
+	text = codePattern.ReplaceAllString(text, "$1")
+	return text
+}
+
+// makeTitle returns a given title as attachment title. A title with Markdown is returned as heading for the attachment text instead,
+// because attachment titles don't render Markdown.
+func makeTitle(title string) (plainTitle, heading string) {
+	if !hasMarkdown(title) {
+		return title, ""
+	}
+	return "", "#### " + sanitizeMarkdown(title)
+}
+
+// hasMarkdownOptions returns true if an answer option of the poll contains Markdown
+func (p *Poll) hasMarkdownOptions() bool {
+	for _, o := range p.AnswerOptions {
+		if hasMarkdown(o.Answer) {
+			return true
+		}
+	}
+	return false
+}
+
+// makeOptionsText returns a markdown list of the answer options in a given order.
+// It lets answer options with Markdown or a description render next to their buttons, which only show plain text.
+func (p *Poll) makeOptionsText(order []int) string {
+	lines := []string{}
+	for _, i := range order {
+		lines = append(lines, describeOption("- "+sanitizeMarkdown(p.AnswerOptions[i].Answer), p.AnswerOptions[i]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeOption returns a given list item of an answer option followed by the description of the option, if it has one.
+// The description is indented, so that it renders as part of the list item.
+func describeOption(line string, o *AnswerOption) string {
+	if o.Description == "" {
+		return line
+	}
+	return line + "\n  " + sanitizeMarkdown(o.Description)
+}
+
+// joinText joins the non-empty parts of an attachment text by line breaks
+func joinText(parts ...string) string {
+	nonEmpty := []string{}
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
@@ -0,0 +1,45 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollMarkdownSanitization(t *testing.T) {
+	for name, test := range map[string]struct {
+		Answer       string
+		ExpectedText string
+		ExpectedName string
+	}{
+		"Line breaks":         {Answer: "**Line**\nbreak", ExpectedText: "- **Line** break", ExpectedName: "Line break"},
+		"Heading":             {Answer: "# **Heading**", ExpectedText: `- \# **Heading**`, ExpectedName: "# Heading"},
+		"Quote":               {Answer: "> **Quote**", ExpectedText: `- \> **Quote**`, ExpectedName: "> Quote"},
+		"List item":           {Answer: "1. **Item**", ExpectedText: `- \1. **Item**`, ExpectedName: "1. Item"},
+		"Unbalanced backtick": {Answer: "`code` and `", ExpectedText: "- `code` and \\`", ExpectedName: "code and `"},
+		"Image":               {Answer: "![Logo](https://example.org/logo.png)", ExpectedText: "- ![Logo](https://example.org/logo.png)", ExpectedName: "Logo"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollTwoOptions()
+			p.AnswerOptions[0].Answer = test.Answer
+
+			attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+			assert.Equal(t, "Question", attachments[0].Title)
+			assert.Equal(t, test.ExpectedText+"\n- No\n---\n**Total votes**: 0", attachments[0].Text)
+			assert.Equal(t, test.ExpectedName, attachments[0].Actions[0].Name)
+		})
+	}
+}
+
+func TestPollWithoutMarkdown(t *testing.T) {
+	// Text that only looks like Markdown keeps the question as title and isn't listed
+	p := testutils.GetPollTwoOptions()
+	p.Question = "Is 2 * 3 * 4 = 24 at 10:30:00?"
+	p.AnswerOptions[0].Answer = "snake_case_name"
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Equal(t, "Is 2 * 3 * 4 = 24 at 10:30:00?", attachments[0].Title)
+	assert.Equal(t, "---\n**Total votes**: 0", attachments[0].Text)
+	assert.Equal(t, "snake_case_name", attachments[0].Actions[0].Name)
+}
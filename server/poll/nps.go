@@ -0,0 +1,100 @@
+package poll
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// NPSMaxScore is the highest score of an NPS poll. Its answer options are the scores from 0 to NPSMaxScore.
+	NPSMaxScore = 10
+	// NPSMinPromoterScore is the lowest score of a promoter
+	NPSMinPromoterScore = 9
+	// NPSMaxDetractorScore is the highest score of a detractor. Voters between detractors and promoters are passives.
+	NPSMaxDetractorScore = 6
+)
+
+// NPSResult stores the number of votes of the promoters, passives and detractors of an NPS poll
+type NPSResult struct {
+	Promoters  int
+	Passives   int
+	Detractors int
+}
+
+// Total returns the number of votes of the NPS poll
+func (r *NPSResult) Total() int {
+	return r.Promoters + r.Passives + r.Detractors
+}
+
+// Score returns the Net Promoter Score, which is the percentage of promoters minus the percentage of detractors.
+// It ranges from -100 to 100 and is zero if nobody has voted.
+func (r *NPSResult) Score() int {
+	if r.Total() == 0 {
+		return 0
+	}
+	return int(math.Round(float64(100*(r.Promoters-r.Detractors)) / float64(r.Total())))
+}
+
+// formatScore returns the Net Promoter Score with its sign, e.g. +42 or -10
+func (r *NPSResult) formatScore() string {
+	if score := r.Score(); score > 0 {
+		return "+" + strconv.Itoa(score)
+	}
+	return strconv.Itoa(r.Score())
+}
+
+// NewNPSPoll creates a new Net Promoter Score poll with a given question. Voters pick a score from 0 to NPSMaxScore.
+// The poll is anonymous unless the settings turn it off explicitly with anonymous=false.
+func NewNPSPoll(creator, question string, settings []string) (*Poll, error) {
+	answerOptions := []string{}
+	for score := 0; score <= NPSMaxScore; score++ {
+		answerOptions = append(answerOptions, strconv.Itoa(score))
+	}
+
+	p, err := NewPoll(creator, question, answerOptions, append([]string{"anonymous"}, settings...))
+	if err != nil {
+		return nil, err
+	}
+	// Every voter picks a single score from a fixed scale, which rules out some Poll Settings
+	switch {
+	case p.Settings.VoteMode != VoteModeSingle:
+		return nil, fmt.Errorf("votemode=%s is not supported in NPS polls", p.Settings.VoteMode)
+	case p.Settings.PublicAddOption:
+		return nil, fmt.Errorf("public-add-option is not supported in NPS polls")
+	case p.Settings.AllowOther:
+		return nil, fmt.Errorf("allow-other is not supported in NPS polls")
+	case p.IsMultiVote():
+		return nil, fmt.Errorf("votes=%d is not supported in NPS polls", p.Settings.MaxVotes)
+	case p.Settings.MaxPerOption > 0:
+		return nil, fmt.Errorf("max-per-option is not supported in NPS polls")
+	case p.Settings.Shuffle != ShuffleNone:
+		return nil, fmt.Errorf("shuffle is not supported in NPS polls")
+	case p.Settings.SortResults:
+		return nil, fmt.Errorf("sort-results is not supported in NPS polls")
+	}
+	p.Settings.NPS = true
+	return p, nil
+}
+
+// IsNPS returns true if the poll asks for a Net Promoter Score
+func (p *Poll) IsNPS() bool {
+	return p.Settings.NPS
+}
+
+// NPSResults returns the number of votes of the promoters, passives and detractors of an NPS poll
+func (p *Poll) NPSResults() *NPSResult {
+	r := &NPSResult{}
+	// The answer options are the scores in ascending order
+	for score, o := range p.AnswerOptions {
+		switch {
+		case score >= NPSMinPromoterScore:
+			r.Promoters += p.VotesOf(o)
+		case score > NPSMaxDetractorScore:
+			r.Passives += p.VotesOf(o)
+		default:
+			r.Detractors += p.VotesOf(o)
+		}
+	}
+	return r
+}
@@ -0,0 +1,112 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNPSPoll(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := poll.NewNPSPoll("userID1", "How likely are you to recommend us?", []string{"progress"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal("How likely are you to recommend us?", p.Question)
+		assert.Equal(poll.Settings{Anonymous: true, Progress: true, NPS: true}, p.Settings)
+		assert.True(p.IsNPS())
+		answers := []string{}
+		for _, o := range p.AnswerOptions {
+			answers = append(answers, o.Answer)
+		}
+		assert.Equal([]string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, answers)
+	})
+
+	t.Run("all fine, anonymous turned off", func(t *testing.T) {
+		p, err := poll.NewNPSPoll("userID1", "Question", []string{"anonymous=false", "public-votes"})
+
+		require.Nil(t, err)
+		assert.Equal(t, poll.Settings{PublicVotes: true, NPS: true}, p.Settings)
+	})
+
+	for name, settings := range map[string][]string{
+		"votemode":          {"votemode=approval"},
+		"public-add-option": {"public-add-option"},
+		"allow-other":       {"allow-other"},
+		"votes":             {"votes=2"},
+		"max-per-option":    {"max-per-option=2"},
+		"shuffle":           {"shuffle"},
+		"sort-results":      {"sort-results"},
+		"invalid setting":   {"unknown"},
+	} {
+		t.Run("error, "+name, func(t *testing.T) {
+			p, err := poll.NewNPSPoll("userID1", "Question", settings)
+
+			assert.NotNil(t, err)
+			assert.Nil(t, p)
+		})
+	}
+}
+
+func TestPollNPSResults(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll           *poll.Poll
+		ExpectedResult *poll.NPSResult
+		ExpectedScore  int
+	}{
+		"No votes": {
+			Poll: func() *poll.Poll {
+				p, _ := poll.NewNPSPoll("userID1", "Question", nil)
+				return p
+			}(),
+			ExpectedResult: &poll.NPSResult{},
+			ExpectedScore:  0,
+		},
+		"Some votes": {
+			Poll:           testutils.GetNPSPollWithVotes(),
+			ExpectedResult: &poll.NPSResult{Promoters: 2, Passives: 1, Detractors: 1},
+			ExpectedScore:  25,
+		},
+		"Only detractors": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetNPSPollWithVotes()
+				for i, o := range p.AnswerOptions {
+					if i > poll.NPSMaxDetractorScore {
+						o.Voter = nil
+					}
+				}
+				return p
+			}(),
+			ExpectedResult: &poll.NPSResult{Detractors: 1},
+			ExpectedScore:  -100,
+		},
+		"Weighted votes": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetNPSPollWithVotes()
+				p.Settings.Weights = map[string]int{"userID4": 3}
+				return p
+			}(),
+			ExpectedResult: &poll.NPSResult{Promoters: 2, Passives: 1, Detractors: 3},
+			ExpectedScore:  -17,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result := test.Poll.NPSResults()
+
+			assert.Equal(t, test.ExpectedResult, result)
+			assert.Equal(t, test.ExpectedScore, result.Score())
+		})
+	}
+}
+
+func TestPollAddAnswerOptionNPS(t *testing.T) {
+	p := testutils.GetNPSPollWithVotes()
+
+	assert.NotNil(t, p.AddAnswerOption("11"))
+	assert.Len(t, p.AnswerOptions, poll.NPSMaxScore+1)
+}
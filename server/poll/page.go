@@ -0,0 +1,79 @@
+package poll
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// IsPaginated returns true if the poll post shows the answer option buttons on several pages.
+// Ranked and rating polls and surveys are never paginated, because they don't have a button per answer option.
+// Compact polls list all answer options in their select menu.
+func (p *Poll) IsPaginated() bool {
+	if p.IsSurvey() || p.Settings.VoteMode == VoteModeRanked || p.Settings.VoteMode == VoteModeRating || p.IsCompact() {
+		return false
+	}
+	return p.PageSize > 0 && len(p.AnswerOptions) > p.PageSize
+}
+
+// IsCompact returns true if the poll post shows a select menu with all answer options instead of a button per answer option.
+// That's the case if the poll has the compact setting or more answer options than CompactAbove.
+// Ranked, rating and estimate polls and surveys are never compact, because they don't have a button per answer option.
+func (p *Poll) IsCompact() bool {
+	if p.IsSurvey() || p.Settings.VoteMode == VoteModeRanked || p.Settings.VoteMode == VoteModeRating || p.Settings.VoteMode == VoteModeEstimate {
+		return false
+	}
+	return p.Settings.Compact || (p.CompactAbove > 0 && len(p.AnswerOptions) > p.CompactAbove)
+}
+
+// NumberOfPages returns the number of pages of answer options. Polls that aren't paginated have a single page.
+func (p *Poll) NumberOfPages() int {
+	if !p.IsPaginated() {
+		return 1
+	}
+	return (len(p.AnswerOptions) + p.PageSize - 1) / p.PageSize
+}
+
+// CurrentPage returns the zero-based page of answer options shown on the poll post.
+// It's the last page if answer options got removed since the page was set.
+func (p *Poll) CurrentPage() int {
+	if last := p.NumberOfPages() - 1; p.Page > last {
+		return last
+	}
+	return p.Page
+}
+
+// SetPage sets the zero-based page of answer options shown on the poll post
+func (p *Poll) SetPage(page int) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if page < 0 || page >= p.NumberOfPages() {
+		return fmt.Errorf("invalid page: %d", page)
+	}
+	p.Page = page
+	return nil
+}
+
+// pageOrder returns the indices of the answer options on the current page in the order they are shown.
+// Polls that get shuffled for every rendering keep the answer options on their page and are only shuffled within it,
+// so that voters can find every answer option by paging through the poll.
+func (p *Poll) pageOrder() []int {
+	if !p.IsPaginated() {
+		return p.DisplayOrder()
+	}
+
+	order := p.DisplayOrder()
+	if p.Settings.Shuffle == ShuffleAlways {
+		order = p.originalOrder()
+	}
+	start := p.CurrentPage() * p.PageSize
+	end := start + p.PageSize
+	if end > len(order) {
+		end = len(order)
+	}
+	page := append([]int{}, order[start:end]...)
+	if p.Settings.Shuffle == ShuffleAlways {
+		rand.Shuffle(len(page), func(i, j int) { page[i], page[j] = page[j], page[i] })
+	}
+	return page
+}
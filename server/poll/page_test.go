@@ -0,0 +1,129 @@
+package poll_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumberOfPages(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll          *poll.Poll
+		PageSize      int
+		ExpectedPages int
+	}{
+		"No page size":           {Poll: testutils.GetPoll(), PageSize: 0, ExpectedPages: 1},
+		"All options fit":        {Poll: testutils.GetPoll(), PageSize: 3, ExpectedPages: 1},
+		"Last page is full":      {Poll: testutils.GetPoll(), PageSize: 1, ExpectedPages: 3},
+		"Last page is not full":  {Poll: testutils.GetPoll(), PageSize: 2, ExpectedPages: 2},
+		"Ranked polls":           {Poll: testutils.GetPollWithRankings(), PageSize: 1, ExpectedPages: 1},
+		"Rating polls":           {Poll: testutils.GetPollWithRatings(), PageSize: 1, ExpectedPages: 1},
+		"Surveys":                {Poll: testutils.GetSurveyWithVotes(), PageSize: 1, ExpectedPages: 1},
+		"Scheduling polls":       {Poll: testutils.GetSchedulingPoll(), PageSize: 2, ExpectedPages: 2},
+		"Approval polls":         {Poll: testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeApproval}), PageSize: 2, ExpectedPages: 2},
+		"Multiple votes allowed": {Poll: testutils.GetPollWithSettings(poll.Settings{MaxVotes: 2}), PageSize: 2, ExpectedPages: 2},
+		"Compact polls":          {Poll: testutils.GetPollWithSettings(poll.Settings{Compact: true}), PageSize: 1, ExpectedPages: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			test.Poll.PageSize = test.PageSize
+
+			assert.Equal(t, test.ExpectedPages, test.Poll.NumberOfPages())
+			assert.Equal(t, test.ExpectedPages > 1, test.Poll.IsPaginated())
+		})
+	}
+}
+
+func TestIsCompact(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		CompactAbove    int
+		ExpectedCompact bool
+	}{
+		"No compact setting":           {Poll: testutils.GetPoll(), CompactAbove: 0, ExpectedCompact: false},
+		"Compact setting":              {Poll: testutils.GetPollWithSettings(poll.Settings{Compact: true}), CompactAbove: 0, ExpectedCompact: true},
+		"More options than threshold":  {Poll: testutils.GetPoll(), CompactAbove: 2, ExpectedCompact: true},
+		"As many options as threshold": {Poll: testutils.GetPoll(), CompactAbove: 3, ExpectedCompact: false},
+		"Approval polls":               {Poll: testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeApproval}), CompactAbove: 2, ExpectedCompact: true},
+		"Ranked polls":                 {Poll: testutils.GetPollWithRankings(), CompactAbove: 1, ExpectedCompact: false},
+		"Rating polls":                 {Poll: testutils.GetPollWithRatings(), CompactAbove: 1, ExpectedCompact: false},
+		"Estimate polls":               {Poll: testutils.GetPollWithEstimates(), CompactAbove: 1, ExpectedCompact: false},
+		"Surveys":                      {Poll: testutils.GetSurveyWithVotes(), CompactAbove: 1, ExpectedCompact: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			test.Poll.CompactAbove = test.CompactAbove
+
+			assert.Equal(t, test.ExpectedCompact, test.Poll.IsCompact())
+		})
+	}
+}
+
+func TestSetPage(t *testing.T) {
+	t.Run("valid page", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.PageSize = 2
+
+		assert.Nil(t, p.SetPage(1))
+		assert.Equal(t, 1, p.CurrentPage())
+		assert.Nil(t, p.SetPage(0))
+		assert.Equal(t, 0, p.CurrentPage())
+	})
+	t.Run("page out of range", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.PageSize = 2
+
+		assert.NotNil(t, p.SetPage(2))
+		assert.NotNil(t, p.SetPage(-1))
+		assert.Equal(t, 0, p.CurrentPage())
+	})
+	t.Run("poll not paginated", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		assert.NotNil(t, p.SetPage(1))
+	})
+	t.Run("poll ended", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.PageSize = 2
+		p.EndedAt = 1234567890
+
+		assert.NotNil(t, p.SetPage(1))
+	})
+}
+
+func TestCurrentPage(t *testing.T) {
+	p := testutils.GetPoll()
+	p.PageSize = 1
+	p.Page = 2
+	assert.Equal(t, 2, p.CurrentPage())
+
+	// A page that doesn't exist anymore falls back to the last page
+	p.PageSize = 2
+	assert.Equal(t, 1, p.CurrentPage())
+	assert.Equal(t, 2, p.Page)
+}
+
+func TestPaginatedPollShuffledAlways(t *testing.T) {
+	// Answer options only get shuffled within their page
+	votedOptions := func(p *poll.Poll) []string {
+		options := []string{}
+		for _, action := range p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")[0].Actions {
+			if strings.HasPrefix(action.Name, "Answer ") {
+				options = append(options, action.Name)
+			}
+		}
+		sort.Strings(options)
+		return options
+	}
+
+	p := testutils.GetPollWithSettings(poll.Settings{Shuffle: poll.ShuffleAlways})
+	p.PageSize = 2
+	for i := 0; i < 10; i++ {
+		p.Page = 0
+		assert.Equal(t, []string{"Answer 1", "Answer 2"}, votedOptions(p))
+		p.Page = 1
+		assert.Equal(t, []string{"Answer 3"}, votedOptions(p))
+	}
+}
@@ -3,7 +3,12 @@ package poll
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/pkg/errors"
@@ -17,12 +22,67 @@ type Poll struct {
 	Question      string
 	AnswerOptions []*AnswerOption
 	Settings      Settings
+	// PostID and ChannelID identify the post that displays the poll. They are set once the poll got posted.
+	// Scheduled polls store their ChannelID and RootID right away to know where to get posted.
+	PostID    string `json:",omitempty"`
+	ChannelID string `json:",omitempty"`
+	RootID    string `json:",omitempty"`
+	// Pinned is true if the post of the poll got pinned to the channel. It's only set once pinning succeeded.
+	Pinned bool `json:",omitempty"`
+	// EndedAt is the time in milliseconds at which the poll ended. Zero means the poll is still running.
+	EndedAt int64 `json:",omitempty"`
+	// PausedAt is the time in milliseconds at which voting got paused. Zero means voting isn't paused.
+	PausedAt int64 `json:",omitempty"`
+	// Rankings stores the preference order of answer option indices per voter. Only used by ranked polls.
+	Rankings map[string][]int `json:",omitempty"`
+	// Ratings stores the scores of the answer options per voter, in the order of the answer options.
+	// Zero means that the voter didn't rate an answer option. Only used by rating polls.
+	Ratings map[string][]int `json:",omitempty"`
+	// Estimates stores the number every voter submitted. Only used by estimate polls.
+	Estimates map[string]float64 `json:",omitempty"`
+	// WriteIns stores the answers voters typed in themselves. Identical answers are merged into one entry with all their voters.
+	// Only used by polls that allow other answers.
+	WriteIns []*AnswerOption `json:",omitempty"`
+	// Questions stores the questions of a survey. Question is the title of the survey and AnswerOptions is empty then.
+	Questions []*Question `json:",omitempty"`
+	// EligibleVoters stores the channel members at the time the poll got posted. Only used by polls that end when all of them voted.
+	EligibleVoters []string `json:",omitempty"`
+	// NumberOfEligibleVoters stores the number of channel members at the time the poll ended. Only used by polls with a quorum.
+	NumberOfEligibleVoters int `json:",omitempty"`
+	// ShuffledOrder stores the indices of the answer options in the order they are shown. Only used by polls that are shuffled once.
+	ShuffledOrder []int `json:",omitempty"`
+	// ThresholdNotified is true once the creator got notified that Settings.NotifyAt voters have voted
+	ThresholdNotified bool `json:",omitempty"`
+	// PageSize is the number of answer options shown per page of the poll post. Zero shows all answer options at once.
+	PageSize int `json:",omitempty"`
+	// Page is the zero-based page of answer options the poll post shows. Only used by polls with more answer options than PageSize.
+	Page int `json:",omitempty"`
+	// CompactAbove is the number of answer options above which the poll post shows a select menu instead of buttons.
+	// Zero only shows the select menu if Settings.Compact is set.
+	CompactAbove int `json:",omitempty"`
+	// VotedAt stores the time in milliseconds at which each voter first voted. Votes cast before it got introduced are missing.
+	VotedAt map[string]int64 `json:",omitempty"`
+	// VoteChanges counts how often voters updated or reset a vote they had already cast
+	VoteChanges int `json:",omitempty"`
+	// BallotVoters stores who voted in a poll with receipts. Their votes are stored as hashed receipts instead of user IDs.
+	BallotVoters []string `json:",omitempty"`
+	// DeletedAt is the time in milliseconds at which the poll got deleted. Deleted polls can be restored until they get purged.
+	// Zero means the poll isn't deleted.
+	DeletedAt int64 `json:",omitempty"`
+	// Delegations maps the users that delegated their vote to their delegate. The votes of a delegate count for their delegators as well.
+	Delegations map[string]string `json:",omitempty"`
+	// CrossPosts stores the posts that display the poll in the channels of Settings.Channels. All of them share the votes of the poll.
+	CrossPosts []*Post `json:",omitempty"`
 }
 
 // AnswerOption stores a possible answer and a list of user who voted for this
 type AnswerOption struct {
 	Answer string
 	Voter  []string
+	// ImageURL links to an image that is shown as thumbnail next to the answer
+	ImageURL string `json:",omitempty"`
+	// Description gives voters more context. It's shown under the answer, which keeps the button short.
+	Description string `json:",omitempty"`
 }
 
 // Settings stores possible settings for a poll
@@ -30,6 +90,236 @@ type Settings struct {
 	Anonymous       bool
 	Progress        bool
 	PublicAddOption bool
+	Secret          bool
+	// AnonymousCreator hides who created the poll. The creator is still stored to check permissions.
+	AnonymousCreator bool `json:",omitempty"`
+	// PublicVotes lists the voters of every answer option while the poll is running
+	PublicVotes bool `json:",omitempty"`
+	// EndWhenAllVoted ends the poll as soon as every eligible voter has voted
+	EndWhenAllVoted bool `json:",omitempty"`
+	// LockVotes prevents voters from changing their vote once it's cast
+	LockVotes bool `json:",omitempty"`
+	// AllowOther lets voters type in an answer of their own instead of picking an answer option
+	AllowOther bool `json:",omitempty"`
+	// Pin pins the poll post to the channel while the poll is running
+	Pin bool `json:",omitempty"`
+	// MembersOnly rejects votes from users who aren't members of the channel the poll was posted in
+	MembersOnly bool `json:",omitempty"`
+	// NoGuests rejects votes from guest accounts
+	NoGuests bool `json:",omitempty"`
+	// SortResults orders the progress and the results by the number of votes and marks the answer options with the most votes
+	SortResults bool     `json:",omitempty"`
+	VoteMode    VoteMode `json:",omitempty"`
+	// MaxVotes is the number of answer options a voter may pick. Zero means a single vote.
+	MaxVotes int `json:",omitempty"`
+	// MaxPerOption is the number of votes an answer option accepts, e.g. the seats of a workshop. Zero means no limit.
+	MaxPerOption int `json:",omitempty"`
+	// Quorum is the percentage of channel members that have to vote for the results to be valid. Zero means no quorum.
+	Quorum int `json:",omitempty"`
+	// EndAt is the time in milliseconds at which the poll gets ended automatically. Zero means no deadline.
+	EndAt int64 `json:",omitempty"`
+	// PostAt is the time in milliseconds at which a scheduled poll gets posted. Zero means the poll is posted right away.
+	PostAt  int64       `json:",omitempty"`
+	Repeat  Recurrence  `json:",omitempty"`
+	Shuffle ShuffleMode `json:",omitempty"`
+	// Digest is how often the creator gets a direct message with the current standings while the poll is running
+	Digest Recurrence `json:",omitempty"`
+	// Invite attaches a calendar invite for the best time slot to the announcement of an ended scheduling poll
+	Invite bool `json:",omitempty"`
+	// SlotDuration is the length of a time slot in minutes. Zero means DefaultSlotDuration.
+	SlotDuration int `json:",omitempty"`
+	// NotifyAt is the number of voters at which the creator gets a direct message, e.g. to end the poll early. Zero means no notification.
+	NotifyAt int `json:",omitempty"`
+	// VoteToSee hides the results in the poll post. Voters see the current results after they voted.
+	VoteToSee bool `json:",omitempty"`
+	// Compact shows a select menu with all answer options in the poll post instead of a button per answer option
+	Compact bool `json:",omitempty"`
+	// Moderators are the IDs of the users that share the permission of the creator to end, delete and export the poll and to add options.
+	// NewPoll sets usernames, which ResolveModerators replaces by user IDs.
+	Moderators []string `json:",omitempty"`
+	// ResultsTemplate is the template of the message that announces the results once the poll ended, see ResultsTemplateData.
+	// The template of the plugin configuration or the default message is used if it's empty.
+	ResultsTemplate string `json:",omitempty"`
+	// Receipts detaches the votes from the voters and issues every voter a receipt to verify the vote got counted.
+	// It implies Anonymous and LockVotes.
+	Receipts bool `json:",omitempty"`
+	// Weights are the number of votes the vote of a user counts for, by user ID or by the roles WeightRoleCreator and WeightRoleModerators.
+	// Users without a weight count once. NewPoll sets usernames prefixed by @, which ResolveWeights replaces by user IDs.
+	Weights map[string]int `json:",omitempty"`
+	// Channels are the IDs of further channels the poll gets cross-posted to.
+	// NewPoll sets channel names, which ResolveChannels replaces by channel IDs.
+	Channels []string `json:",omitempty"`
+	// Tags categorize the poll, so it can be found by them, e.g. retro or team-alpha. They are stored in lower case.
+	Tags []string `json:",omitempty"`
+	// EmailCreator emails the results to the creator once the poll ended
+	EmailCreator bool `json:",omitempty"`
+	// EmailRecipients are further email addresses the results get sent to once the poll ended, e.g. of stakeholders outside of Mattermost
+	EmailRecipients []string `json:",omitempty"`
+	// NPS marks a Net Promoter Score poll, whose answer options are the scores from 0 to NPSMaxScore. It's set by NewNPSPoll.
+	NPS bool `json:",omitempty"`
+}
+
+const (
+	// PublicVotersLimit is the number of voters listed per answer option of a running poll with public votes
+	PublicVotersLimit = 10
+	// WriteInMaxLength is the maximum number of characters of an answer typed in by a voter
+	WriteInMaxLength = 100
+
+	// TimeLayout is the layout for absolute times in Poll Settings. Times are interpreted as UTC.
+	TimeLayout = "2006-01-02T15:04"
+	// timeLayoutSpace is an alternative to TimeLayout that separates date and time by a space.
+	timeLayoutSpace = "2006-01-02 15:04"
+)
+
+// VoteMode defines how voters express their choice in a poll
+type VoteMode string
+
+const (
+	// VoteModeSingle lets every voter pick exactly one answer option. It's the default vote mode.
+	VoteModeSingle VoteMode = ""
+	// VoteModeRanked lets every voter order the answer options by preference.
+	// The winner is determined by an instant-runoff tally.
+	VoteModeRanked VoteMode = "ranked"
+	// VoteModeApproval lets every voter approve any number of answer options independently.
+	VoteModeApproval VoteMode = "approval"
+	// VoteModeRating lets every voter score each answer option from 1 to RatingMaxScore.
+	// The results show the average score of every answer option.
+	VoteModeRating VoteMode = "rating"
+	// VoteModeScheduling lets every voter mark the dates or times they are available at, like in approval polls.
+	// Every answer option has to be a time slot.
+	VoteModeScheduling VoteMode = "scheduling"
+	// VoteModeEstimate lets every voter submit a number, e.g. story points or a budget, instead of picking an answer option.
+	// The results show statistics and the distribution of the estimates.
+	VoteModeEstimate VoteMode = "estimate"
+)
+
+// ShuffleMode defines whether the answer options of a poll are shown in random order to reduce position bias
+type ShuffleMode string
+
+const (
+	// ShuffleNone shows the answer options in the order they were given. It's the default shuffle mode.
+	ShuffleNone ShuffleMode = ""
+	// ShuffleOnce shows the answer options in an order that is randomized once when the poll gets created.
+	ShuffleOnce ShuffleMode = "once"
+	// ShuffleAlways randomizes the order of the answer options again every time the poll gets displayed.
+	ShuffleAlways ShuffleMode = "always"
+)
+
+// Recurrence defines how often a poll gets posted again
+type Recurrence string
+
+const (
+	// RecurrenceNone posts a poll only once. It's the default recurrence.
+	RecurrenceNone Recurrence = ""
+	// RecurrenceDaily posts a poll again every day.
+	RecurrenceDaily Recurrence = "daily"
+	// RecurrenceWeekly posts a poll again every week.
+	RecurrenceWeekly Recurrence = "weekly"
+	// RecurrenceMonthly posts a poll again every month.
+	RecurrenceMonthly Recurrence = "monthly"
+)
+
+// parseRecurrence returns the Recurrence for a given poll setting value
+func parseRecurrence(value string) (Recurrence, error) {
+	switch Recurrence(value) {
+	case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		return Recurrence(value), nil
+	default:
+		return RecurrenceNone, fmt.Errorf("Unrecognised recurrence %s", value)
+	}
+}
+
+// Next returns the time in milliseconds of the occurrence that follows a given time in milliseconds
+func (r Recurrence) Next(t int64) int64 {
+	next := time.Unix(0, t*int64(time.Millisecond)).UTC()
+	switch r {
+	case RecurrenceDaily:
+		next = next.AddDate(0, 0, 1)
+	case RecurrenceWeekly:
+		next = next.AddDate(0, 0, 7)
+	case RecurrenceMonthly:
+		next = next.AddDate(0, 1, 0)
+	default:
+		return 0
+	}
+	return next.UnixNano() / int64(time.Millisecond)
+}
+
+// parseVoteMode returns the VoteMode for a given poll setting value
+func parseVoteMode(value string) (VoteMode, error) {
+	switch value {
+	case "single":
+		return VoteModeSingle, nil
+	case string(VoteModeRanked):
+		return VoteModeRanked, nil
+	case string(VoteModeApproval):
+		return VoteModeApproval, nil
+	case string(VoteModeRating):
+		return VoteModeRating, nil
+	case string(VoteModeScheduling):
+		return VoteModeScheduling, nil
+	case string(VoteModeEstimate):
+		return VoteModeEstimate, nil
+	default:
+		return VoteModeSingle, fmt.Errorf("Unrecognised vote mode %s", value)
+	}
+}
+
+// parseShuffleMode returns the ShuffleMode for a given poll setting value. A shuffle setting without value shuffles once.
+func parseShuffleMode(value string) (ShuffleMode, error) {
+	switch value {
+	case "", "true", string(ShuffleOnce):
+		return ShuffleOnce, nil
+	case "false":
+		return ShuffleNone, nil
+	case string(ShuffleAlways):
+		return ShuffleAlways, nil
+	default:
+		return ShuffleNone, fmt.Errorf("Unrecognised shuffle mode %s", value)
+	}
+}
+
+// ParseTime returns the time in milliseconds for a given poll setting value.
+// The value is either a duration relative to base, e.g. 2h, or an absolute time, e.g. 2024-06-01T17:00.
+func ParseTime(value string, base int64) (int64, bool) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return base + int64(d/time.Millisecond), true
+	}
+	for _, layout := range []string{TimeLayout, timeLayoutSpace} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UnixNano() / int64(time.Millisecond), true
+		}
+	}
+	return 0, false
+}
+
+// parseBoolSetting returns whether a boolean poll setting is enabled. A setting without value is enabled.
+func parseBoolSetting(key, value string) (bool, error) {
+	if value == "" {
+		return true, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("Invalid value %s for poll setting %s", value, key)
+	}
+	return enabled, nil
+}
+
+// parseModerators returns the usernames of a comma separated list of moderators like @alice,@bob.
+// The leading @ is optional and duplicates are left out.
+func parseModerators(value string) ([]string, error) {
+	moderators := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+		if name == "" || containsString(moderators, name) {
+			continue
+		}
+		moderators = append(moderators, name)
+	}
+	if len(moderators) == 0 {
+		return nil, fmt.Errorf("Invalid moderators %s", value)
+	}
+	return moderators, nil
 }
 
 // NewPoll creates a new poll with the given paramatern
@@ -45,58 +335,727 @@ func NewPoll(creator, question string, answerOptions, settings []string) (*Poll,
 			return nil, err
 		}
 	}
+	var endValue, scheduleValue, datesValue, timesValue string
 	for _, s := range settings {
-		switch s {
+		key, value := s, ""
+		if i := strings.Index(s, "="); i != -1 {
+			key, value = s[:i], s[i+1:]
+		}
+
+		var err error
+		switch key {
+		case "allow-other":
+			p.Settings.AllowOther, err = parseBoolSetting(key, value)
+		case "compact":
+			p.Settings.Compact, err = parseBoolSetting(key, value)
 		case "anonymous":
-			p.Settings.Anonymous = true
+			p.Settings.Anonymous, err = parseBoolSetting(key, value)
+		case "anonymous-creator":
+			p.Settings.AnonymousCreator, err = parseBoolSetting(key, value)
+		case "end-when-all-voted":
+			p.Settings.EndWhenAllVoted, err = parseBoolSetting(key, value)
+		case "lock-votes":
+			p.Settings.LockVotes, err = parseBoolSetting(key, value)
+		case "members-only":
+			p.Settings.MembersOnly, err = parseBoolSetting(key, value)
+		case "no-guests":
+			p.Settings.NoGuests, err = parseBoolSetting(key, value)
+		case "pin":
+			p.Settings.Pin, err = parseBoolSetting(key, value)
 		case "progress":
-			p.Settings.Progress = true
+			p.Settings.Progress, err = parseBoolSetting(key, value)
 		case "public-add-option":
-			p.Settings.PublicAddOption = true
+			p.Settings.PublicAddOption, err = parseBoolSetting(key, value)
+		case "public-votes":
+			p.Settings.PublicVotes, err = parseBoolSetting(key, value)
+		case "receipts":
+			p.Settings.Receipts, err = parseBoolSetting(key, value)
+		case "secret":
+			p.Settings.Secret, err = parseBoolSetting(key, value)
+		case "sort-results":
+			p.Settings.SortResults, err = parseBoolSetting(key, value)
+		case "vote-to-see":
+			p.Settings.VoteToSee, err = parseBoolSetting(key, value)
+		case "votemode":
+			voteMode, err := parseVoteMode(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.VoteMode = voteMode
+		case "votes":
+			maxVotes, err := strconv.Atoi(value)
+			if err != nil || maxVotes < 1 {
+				return nil, fmt.Errorf("Invalid number of votes %s", value)
+			}
+			if maxVotes > 1 {
+				p.Settings.MaxVotes = maxVotes
+			}
+		case "max-per-option":
+			maxPerOption, err := strconv.Atoi(value)
+			if err != nil || maxPerOption < 1 {
+				return nil, fmt.Errorf("Invalid number of votes per option %s", value)
+			}
+			p.Settings.MaxPerOption = maxPerOption
+		case "quorum":
+			quorum, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			if err != nil || quorum < 1 || quorum > 100 {
+				return nil, fmt.Errorf("Invalid quorum %s. It must be a percentage between 1%% and 100%%", value)
+			}
+			p.Settings.Quorum = quorum
+		case "notify-at":
+			notifyAt, err := strconv.Atoi(value)
+			if err != nil || notifyAt < 1 {
+				return nil, fmt.Errorf("Invalid number of voters %s", value)
+			}
+			p.Settings.NotifyAt = notifyAt
+		case "moderators":
+			moderators, err := parseModerators(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.Moderators = moderators
+		case "channels":
+			channels, err := parseChannels(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.Channels = channels
+		case "tags":
+			tags, err := parseTags(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.Tags = tags
+		case "email":
+			// An email without addresses is only sent to the creator
+			p.Settings.EmailCreator = true
+			if value != "" {
+				recipients, err := parseEmailRecipients(value)
+				if err != nil {
+					return nil, err
+				}
+				p.Settings.EmailRecipients = recipients
+			}
+		case "weights":
+			weights, err := parseWeights(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.Weights = weights
+		case "results-template":
+			if _, err := ParseResultsTemplate(value); err != nil {
+				return nil, err
+			}
+			p.Settings.ResultsTemplate = value
+		case "end":
+			endValue = value
+		case "schedule":
+			scheduleValue = value
+		case "repeat":
+			repeat, err := parseRecurrence(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.Repeat = repeat
+		case "shuffle":
+			shuffle, err := parseShuffleMode(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.Shuffle = shuffle
+		case "digest":
+			// A digest without value is sent daily
+			if value == "" {
+				value = string(RecurrenceDaily)
+			}
+			digest, err := parseRecurrence(value)
+			if err != nil {
+				return nil, err
+			}
+			p.Settings.Digest = digest
+		case "dates":
+			datesValue = value
+		case "times":
+			timesValue = value
+		case "invite":
+			p.Settings.Invite, err = parseBoolSetting(key, value)
+		case "duration":
+			d, err := time.ParseDuration(value)
+			if err != nil || d < time.Minute {
+				return nil, fmt.Errorf("Invalid duration %s", value)
+			}
+			p.Settings.SlotDuration = int(d / time.Minute)
 		default:
 			return nil, fmt.Errorf("Unrecognised poll setting %s", s)
 		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.addTimeSlots(datesValue, timesValue); err != nil {
+		return nil, err
+	}
+
+	// Estimates are typed in, so there is nothing to pick from
+	if p.Settings.VoteMode == VoteModeEstimate && len(p.AnswerOptions) > 0 {
+		return nil, fmt.Errorf("votemode=%s can't have answer options", p.Settings.VoteMode)
+	}
+	// Votes with receipts are single votes that can't be traced back to the voter, and hence can't be changed
+	if p.Settings.Receipts {
+		switch {
+		case p.Settings.VoteMode != VoteModeSingle:
+			return nil, fmt.Errorf("receipts can't be combined with votemode=%s", p.Settings.VoteMode)
+		case p.IsMultiVote():
+			return nil, fmt.Errorf("receipts can't be combined with votes=%d", p.Settings.MaxVotes)
+		case p.Settings.AllowOther:
+			return nil, fmt.Errorf("receipts can't be combined with allow-other")
+		case p.Settings.PublicVotes:
+			return nil, fmt.Errorf("receipts can't be combined with public-votes")
+		}
+		p.Settings.Anonymous = true
+		p.Settings.LockVotes = true
+	}
+	// Approval voters pick their options one by one, which a locked vote wouldn't allow
+	if p.Settings.LockVotes && p.IsApprovalVote() {
+		return nil, fmt.Errorf("lock-votes can't be combined with votemode=%s", p.Settings.VoteMode)
+	}
+	if p.IsMultiVote() && p.Settings.VoteMode != VoteModeSingle {
+		return nil, fmt.Errorf("votes=%d can't be combined with votemode=%s", p.Settings.MaxVotes, p.Settings.VoteMode)
+	}
+	if p.IsMultiVote() && p.Settings.LockVotes {
+		return nil, fmt.Errorf("votes=%d can't be combined with lock-votes", p.Settings.MaxVotes)
+	}
+	// A write-in replaces the single vote of a voter
+	if p.Settings.AllowOther && p.Settings.VoteMode != VoteModeSingle {
+		return nil, fmt.Errorf("allow-other can't be combined with votemode=%s", p.Settings.VoteMode)
+	}
+	if p.Settings.AllowOther && p.IsMultiVote() {
+		return nil, fmt.Errorf("allow-other can't be combined with votes=%d", p.Settings.MaxVotes)
 	}
+	// Public votes reveal what the other settings hide, and ranked, rating and estimate polls have no voters per answer option
+	if p.Settings.PublicVotes {
+		switch {
+		case p.Settings.Anonymous:
+			return nil, fmt.Errorf("public-votes can't be combined with anonymous")
+		case p.Settings.Secret:
+			return nil, fmt.Errorf("public-votes can't be combined with secret")
+		case p.Settings.VoteMode == VoteModeRanked, p.Settings.VoteMode == VoteModeRating, p.Settings.VoteMode == VoteModeEstimate:
+			return nil, fmt.Errorf("public-votes can't be combined with votemode=%s", p.Settings.VoteMode)
+		}
+	}
+	// Ranked, rating and estimate polls have no votes per answer option
+	if p.Settings.MaxPerOption > 0 && (p.Settings.VoteMode == VoteModeRanked || p.Settings.VoteMode == VoteModeRating || p.Settings.VoteMode == VoteModeEstimate) {
+		return nil, fmt.Errorf("max-per-option can't be combined with votemode=%s", p.Settings.VoteMode)
+	}
+	// Ranked and rating polls order their results by preferences and scores already, and estimate polls by value
+	if p.Settings.SortResults && (p.Settings.VoteMode == VoteModeRanked || p.Settings.VoteMode == VoteModeRating || p.Settings.VoteMode == VoteModeEstimate) {
+		return nil, fmt.Errorf("sort-results can't be combined with votemode=%s", p.Settings.VoteMode)
+	}
+	// Ranked, rating and estimate polls are voted on in a dialog, so there is no button per answer option to replace
+	if p.Settings.Compact && (p.Settings.VoteMode == VoteModeRanked || p.Settings.VoteMode == VoteModeRating || p.Settings.VoteMode == VoteModeEstimate) {
+		return nil, fmt.Errorf("compact can't be combined with votemode=%s", p.Settings.VoteMode)
+	}
+	// Weights apply to the voters of answer options, which ranked and rating polls don't have and receipts hide
+	if p.IsWeighted() {
+		switch {
+		case p.Settings.VoteMode != VoteModeSingle:
+			return nil, fmt.Errorf("weights can't be combined with votemode=%s", p.Settings.VoteMode)
+		case p.Settings.Receipts:
+			return nil, fmt.Errorf("weights can't be combined with receipts")
+		}
+	}
+	// The results of secret polls are hidden from voters as well, while public votes show them to everybody
+	if p.Settings.VoteToSee {
+		switch {
+		case p.Settings.Secret:
+			return nil, fmt.Errorf("vote-to-see can't be combined with secret")
+		case p.Settings.PublicVotes:
+			return nil, fmt.Errorf("vote-to-see can't be combined with public-votes")
+		}
+	}
+
+	start := p.CreatedAt
+	if scheduleValue != "" {
+		postAt, ok := ParseTime(scheduleValue, p.CreatedAt)
+		if !ok {
+			return nil, fmt.Errorf("Invalid schedule time %s", scheduleValue)
+		}
+		if postAt <= p.CreatedAt {
+			return nil, fmt.Errorf("Schedule time %s must be in the future", scheduleValue)
+		}
+		p.Settings.PostAt = postAt
+		start = postAt
+	}
+	if endValue != "" {
+		// Durations are relative to the time the poll gets posted
+		endAt, ok := ParseTime(endValue, start)
+		if !ok {
+			return nil, fmt.Errorf("Invalid end time %s", endValue)
+		}
+		if endAt <= start {
+			return nil, fmt.Errorf("End time %s must be after the start of the poll", endValue)
+		}
+		p.Settings.EndAt = endAt
+	}
+	p.shuffle()
 	return &p, nil
 }
 
-// AddAnswerOption adds a new AnswerOption to a poll
+// addTimeSlots generates the time slots of a scheduling poll from the given poll setting values and checks that every answer option is a time slot
+func (p *Poll) addTimeSlots(dates, times string) error {
+	if p.Settings.VoteMode != VoteModeScheduling {
+		switch {
+		case dates != "":
+			return fmt.Errorf("dates can only be used with votemode=scheduling")
+		case times != "":
+			return fmt.Errorf("times can only be used with votemode=scheduling")
+		case p.Settings.Invite:
+			return fmt.Errorf("invite can only be used with votemode=scheduling")
+		case p.Settings.SlotDuration != 0:
+			return fmt.Errorf("duration can only be used with votemode=scheduling")
+		}
+		return nil
+	}
+	// The slots are fixed dates, so they can't be repeated
+	if p.Settings.Repeat != RecurrenceNone {
+		return fmt.Errorf("repeat can't be combined with votemode=scheduling")
+	}
+
+	if dates != "" {
+		slots, err := generateSlots(dates, times)
+		if err != nil {
+			return err
+		}
+		for _, slot := range slots {
+			if err := p.AddAnswerOption(slot); err != nil {
+				return err
+			}
+		}
+	} else if times != "" {
+		return fmt.Errorf("times can only be used together with dates")
+	}
+
+	for _, o := range p.AnswerOptions {
+		if _, err := ParseSlot(o.Answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shuffle randomizes the order of the answer options of a poll that is shuffled once
+func (p *Poll) shuffle() {
+	if p.Settings.Shuffle == ShuffleOnce {
+		p.ShuffledOrder = rand.Perm(len(p.AnswerOptions))
+	}
+}
+
+// DisplayOrder returns the indices of the answer options in the order they are shown to voters.
+// Answer options that got added after the poll was shuffled are shown last.
+func (p *Poll) DisplayOrder() []int {
+	switch p.Settings.Shuffle {
+	case ShuffleAlways:
+		return rand.Perm(len(p.AnswerOptions))
+	case ShuffleOnce:
+		order := []int{}
+		for _, i := range p.ShuffledOrder {
+			if i < len(p.AnswerOptions) {
+				order = append(order, i)
+			}
+		}
+		return append(order, p.originalOrder()[len(order):]...)
+	default:
+		return p.originalOrder()
+	}
+}
+
+// originalOrder returns the indices of all answer options in the order they were given
+func (p *Poll) originalOrder() []int {
+	order := make([]int, len(p.AnswerOptions))
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// AddAnswerOption adds a new AnswerOption to a poll.
+// A description and an image URL may follow the answer separated by pipes, e.g. "Logo A|Our current logo|https://example.com/a.png".
 func (p *Poll) AddAnswerOption(newAnswerOption string) error {
+	if p.IsEnded() {
+		return errors.New("poll has already ended")
+	}
+	if p.Settings.VoteMode == VoteModeEstimate {
+		return fmt.Errorf("votemode=%s can't have answer options", p.Settings.VoteMode)
+	}
+	if p.IsNPS() {
+		return errors.New("the scores of an NPS poll can't be changed")
+	}
+	parts := strings.Split(newAnswerOption, "|")
+	newAnswerOption = parts[0]
+	var description, imageURL string
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		switch {
+		case looksLikeURL(part):
+			if !isValidImageURL(part) {
+				return fmt.Errorf("invalid image URL %s", part)
+			}
+			if imageURL != "" {
+				return fmt.Errorf("more than one image URL for option %s", newAnswerOption)
+			}
+			imageURL = part
+		case part == "":
+			return errors.New("empty description not allowed")
+		case description != "":
+			return fmt.Errorf("more than one description for option %s", newAnswerOption)
+		default:
+			description = part
+		}
+	}
 	newAnswerOption = strings.TrimSpace(newAnswerOption)
 	if newAnswerOption == "" {
 		return errors.New("empty option not allowed")
 	}
+	if p.Settings.VoteMode == VoteModeScheduling {
+		if _, err := ParseSlot(newAnswerOption); err != nil {
+			return err
+		}
+	}
 	for _, answerOption := range p.AnswerOptions {
 		if answerOption.Answer == newAnswerOption {
 			return fmt.Errorf("duplicate options: %s", newAnswerOption)
 		}
 	}
-	p.AnswerOptions = append(p.AnswerOptions, &AnswerOption{Answer: newAnswerOption})
+	p.AnswerOptions = append(p.AnswerOptions, &AnswerOption{Answer: newAnswerOption, ImageURL: imageURL, Description: description})
 	return nil
 }
 
-// UpdateVote performs a vote for a given user
+// looksLikeURL returns true if a part of an answer option is meant as image URL rather than as description.
+// Descriptions are told apart by containing whitespace or no slash at all.
+func looksLikeURL(part string) bool {
+	return strings.Contains(part, "/") && !strings.ContainsAny(part, " \t")
+}
+
+// isValidImageURL returns true if a given image URL is an absolute http or https URL
+func isValidImageURL(imageURL string) bool {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// HasImages returns true if at least one answer option of the poll shows an image
+func (p *Poll) HasImages() bool {
+	for _, o := range p.AnswerOptions {
+		if o.ImageURL != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDescriptions returns true if at least one answer option of the poll has a description
+func (p *Poll) HasDescriptions() bool {
+	for _, o := range p.AnswerOptions {
+		if o.Description != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateVote performs a vote for a given user.
+// In approval and scheduling polls and in polls with multiple votes the vote toggles the answer option without touching other options.
 func (p *Poll) UpdateVote(userID string, index int) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
 	if len(p.AnswerOptions) <= index || index < 0 {
 		return fmt.Errorf("invalid index")
 	}
 	if userID == "" {
 		return fmt.Errorf("invalid userID")
 	}
-	for _, o := range p.AnswerOptions {
-		for i := 0; i < len(o.Voter); i++ {
-			if userID == o.Voter[i] {
-				o.Voter = append(o.Voter[:i], o.Voter[i+1:]...)
-			}
+	if p.IsVoteLocked(userID) {
+		return fmt.Errorf("vote is locked")
+	}
+	if p.Settings.VoteMode == VoteModeRanked {
+		return fmt.Errorf("ranked polls require a ranking")
+	}
+	if p.Settings.VoteMode == VoteModeRating {
+		return fmt.Errorf("rating polls require ratings")
+	}
+	if p.Settings.VoteMode == VoteModeEstimate {
+		return fmt.Errorf("estimate polls require an estimate")
+	}
+	if p.Settings.Receipts {
+		return fmt.Errorf("polls with receipts require a ballot")
+	}
+	if p.HasDelegated(userID) {
+		return fmt.Errorf("vote is delegated")
+	}
+	hasVoted := p.HasVoted(userID)
+	if p.IsApprovalVote() {
+		p.AnswerOptions[index].toggleVoter(userID)
+		p.recordVote(userID, hasVoted)
+		return nil
+	}
+	if p.IsMultiVote() {
+		if p.IsVoteLimitReached(userID, index) {
+			return fmt.Errorf("vote limit reached")
 		}
+		p.AnswerOptions[index].toggleVoter(userID)
+		p.recordVote(userID, hasVoted)
+		return nil
 	}
+	p.removeVote(userID)
 	p.AnswerOptions[index].Voter = append(p.AnswerOptions[index].Voter, userID)
+	p.recordVote(userID, hasVoted)
+	return nil
+}
+
+// UpdateWriteIn replaces the vote of a given user with an answer the user typed in.
+// An answer that matches an answer option or another write-in, ignoring case and spacing, counts for it.
+func (p *Poll) UpdateWriteIn(userID, answer string) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if !p.Settings.AllowOther {
+		return fmt.Errorf("poll doesn't allow other answers")
+	}
+	if userID == "" {
+		return fmt.Errorf("invalid userID")
+	}
+	answer = strings.Join(strings.Fields(answer), " ")
+	if answer == "" {
+		return fmt.Errorf("empty answer")
+	}
+	if utf8.RuneCountInString(answer) > WriteInMaxLength {
+		return fmt.Errorf("answers can't be longer than %d characters", WriteInMaxLength)
+	}
+	if p.IsVoteLocked(userID) {
+		return fmt.Errorf("vote is locked")
+	}
+	if p.HasDelegated(userID) {
+		return fmt.Errorf("vote is delegated")
+	}
+
+	hasVoted := p.HasVoted(userID)
+	p.removeVote(userID)
+	defer p.recordVote(userID, hasVoted)
+	for _, o := range append(append([]*AnswerOption{}, p.AnswerOptions...), p.WriteIns...) {
+		if isSameAnswer(o.Answer, answer) {
+			o.Voter = append(o.Voter, userID)
+			return nil
+		}
+	}
+	p.WriteIns = append(p.WriteIns, &AnswerOption{Answer: answer, Voter: []string{userID}})
+	return nil
+}
+
+// WriteInOf returns the answer a given user typed in. It's empty if the user hasn't typed in an answer.
+func (p *Poll) WriteInOf(userID string) string {
+	for _, o := range p.WriteIns {
+		for _, voter := range o.Voter {
+			if userID == voter {
+				return o.Answer
+			}
+		}
+	}
+	return ""
+}
+
+// ResetVote removes all votes of a given user, so the user abstains again.
+// This covers the answer options, write-ins, survey questions, rankings and ratings.
+func (p *Poll) ResetVote(userID string) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if userID == "" {
+		return fmt.Errorf("invalid userID")
+	}
+	if !p.HasVoted(userID) {
+		return fmt.Errorf("user hasn't voted")
+	}
+	if p.IsVoteLocked(userID) {
+		return fmt.Errorf("vote is locked")
+	}
+
+	p.removeAllVotes(userID)
+	p.recordVote(userID, true)
 	return nil
 }
 
+// removeAllVotes removes a given user from all answer options, write-ins and survey questions and drops the ranking, the ratings and the estimate of the user
+func (p *Poll) removeAllVotes(userID string) {
+	p.removeVote(userID)
+	for _, q := range p.Questions {
+		for _, o := range q.AnswerOptions {
+			o.removeVoter(userID)
+		}
+	}
+	delete(p.Rankings, userID)
+	delete(p.Ratings, userID)
+	delete(p.Estimates, userID)
+}
+
+// removeVote removes the single vote of a given user from the answer options and the write-ins.
+// Write-ins without voters are dropped.
+func (p *Poll) removeVote(userID string) {
+	for _, o := range p.AnswerOptions {
+		o.removeVoter(userID)
+	}
+	writeIns := []*AnswerOption{}
+	for _, o := range p.WriteIns {
+		if o.removeVoter(userID); len(o.Voter) > 0 {
+			writeIns = append(writeIns, o)
+		}
+	}
+	if len(writeIns) == 0 {
+		writeIns = nil
+	}
+	p.WriteIns = writeIns
+}
+
+// removeVoter removes a given user from the voters of an answer option
+func (o *AnswerOption) removeVoter(userID string) {
+	for i := 0; i < len(o.Voter); i++ {
+		if userID == o.Voter[i] {
+			o.Voter = append(o.Voter[:i], o.Voter[i+1:]...)
+		}
+	}
+}
+
+// isSameAnswer returns true if two answers only differ in case and spacing
+func isSameAnswer(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// toggleVoter adds a given user to the voters of an answer option, or removes the user if already present
+func (o *AnswerOption) toggleVoter(userID string) {
+	for i := 0; i < len(o.Voter); i++ {
+		if userID == o.Voter[i] {
+			o.Voter = append(o.Voter[:i], o.Voter[i+1:]...)
+			return
+		}
+	}
+	o.Voter = append(o.Voter, userID)
+}
+
+// UpdateRanking stores the preference order of a given user in a ranked poll.
+// ranking contains the indices of the answer options, starting with the most preferred one.
+func (p *Poll) UpdateRanking(userID string, ranking []int) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if p.Settings.VoteMode != VoteModeRanked {
+		return fmt.Errorf("poll is not a ranked poll")
+	}
+	if userID == "" {
+		return fmt.Errorf("invalid userID")
+	}
+	if len(ranking) == 0 {
+		return fmt.Errorf("empty ranking")
+	}
+	if p.IsVoteLocked(userID) {
+		return fmt.Errorf("vote is locked")
+	}
+
+	ranked := make(map[int]bool, len(ranking))
+	for _, index := range ranking {
+		if len(p.AnswerOptions) <= index || index < 0 {
+			return fmt.Errorf("invalid index")
+		}
+		if ranked[index] {
+			return fmt.Errorf("duplicate index: %d", index)
+		}
+		ranked[index] = true
+	}
+
+	hasVoted := p.HasVoted(userID)
+	if p.Rankings == nil {
+		p.Rankings = map[string][]int{}
+	}
+	p.Rankings[userID] = append([]int{}, ranking...)
+	p.recordVote(userID, hasVoted)
+	return nil
+}
+
+// UpdateRating stores the scores a given user gave the answer options of a rating poll.
+// scores contains a score from 1 to RatingMaxScore per answer option, or zero for answer options the user didn't rate.
+func (p *Poll) UpdateRating(userID string, scores []int) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if p.Settings.VoteMode != VoteModeRating {
+		return fmt.Errorf("poll is not a rating poll")
+	}
+	if userID == "" {
+		return fmt.Errorf("invalid userID")
+	}
+	if len(scores) > len(p.AnswerOptions) {
+		return fmt.Errorf("too many scores")
+	}
+	if p.IsVoteLocked(userID) {
+		return fmt.Errorf("vote is locked")
+	}
+
+	rated := false
+	for _, score := range scores {
+		if score < 0 || score > RatingMaxScore {
+			return fmt.Errorf("invalid score: %d", score)
+		}
+		rated = rated || score > 0
+	}
+	if !rated {
+		return fmt.Errorf("empty rating")
+	}
+
+	hasVoted := p.HasVoted(userID)
+	if p.Ratings == nil {
+		p.Ratings = map[string][]int{}
+	}
+	p.Ratings[userID] = append([]int{}, scores...)
+	p.recordVote(userID, hasVoted)
+	return nil
+}
+
+// recordVote stores when a given user first voted and counts the vote as changed if the user had already voted.
+// Users who no longer vote for anything lose their time of voting.
+func (p *Poll) recordVote(userID string, changed bool) {
+	if changed {
+		p.VoteChanges++
+	}
+	if !p.HasVoted(userID) {
+		delete(p.VotedAt, userID)
+		return
+	}
+	if _, ok := p.VotedAt[userID]; ok || changed {
+		return
+	}
+	if p.VotedAt == nil {
+		p.VotedAt = map[string]int64{}
+	}
+	p.VotedAt[userID] = model.GetMillis()
+}
+
 // HasVoted return true if a given user has voted in this poll
 func (p *Poll) HasVoted(userID string) bool {
-	for _, o := range p.AnswerOptions {
+	if containsString(p.BallotVoters, userID) {
+		return true
+	}
+	if _, ok := p.Rankings[userID]; ok {
+		return true
+	}
+	if _, ok := p.Ratings[userID]; ok {
+		return true
+	}
+	if _, ok := p.Estimates[userID]; ok {
+		return true
+	}
+	for _, o := range p.allAnswerOptions() {
 		for i := 0; i < len(o.Voter); i++ {
 			if userID == o.Voter[i] {
 				return true
@@ -106,6 +1065,402 @@ func (p *Poll) HasVoted(userID string) bool {
 	return false
 }
 
+// IsVoteLocked returns true if a given user has voted in a poll that doesn't allow changing votes
+func (p *Poll) IsVoteLocked(userID string) bool {
+	return p.Settings.LockVotes && p.HasVoted(userID)
+}
+
+// HasVotedFor return true if a given user has voted for the answer option with the given index
+func (p *Poll) HasVotedFor(userID string, index int) bool {
+	if len(p.AnswerOptions) <= index || index < 0 {
+		return false
+	}
+	for _, voter := range p.AnswerOptions[index].Voter {
+		if userID == voter {
+			return true
+		}
+	}
+	return false
+}
+
+// IsApprovalVote returns true if voters approve any number of answer options independently, which is the case in approval and scheduling polls
+func (p *Poll) IsApprovalVote() bool {
+	return p.Settings.VoteMode == VoteModeApproval || p.Settings.VoteMode == VoteModeScheduling
+}
+
+// ResolveModerators replaces the usernames of the moderators by the user IDs returned by lookup.
+// The creator is left out, because they already have all permissions.
+func (p *Poll) ResolveModerators(lookup func(username string) (string, error)) error {
+	moderators := []string{}
+	for _, name := range p.Settings.Moderators {
+		userID, err := lookup(name)
+		if err != nil {
+			return err
+		}
+		if userID == p.Creator || containsString(moderators, userID) {
+			continue
+		}
+		moderators = append(moderators, userID)
+	}
+	if len(moderators) == 0 {
+		moderators = nil
+	}
+	p.Settings.Moderators = moderators
+	return nil
+}
+
+// IsModerator returns true if a given user is a moderator of the poll
+func (p *Poll) IsModerator(userID string) bool {
+	return containsString(p.Settings.Moderators, userID)
+}
+
+// IsMultiVote returns true if voters may pick more than one answer option, up to Settings.MaxVotes
+func (p *Poll) IsMultiVote() bool {
+	return p.Settings.MaxVotes > 1
+}
+
+// NumberOfVotes returns the number of answer options a given user has voted for
+func (p *Poll) NumberOfVotes(userID string) int {
+	votes := 0
+	for i := range p.AnswerOptions {
+		if p.HasVotedFor(userID, i) {
+			votes++
+		}
+	}
+	return votes
+}
+
+// IsVoteLimitReached returns true if a vote of a given user for the answer option with the given index would exceed Settings.MaxVotes.
+// Taking back a vote is always possible.
+func (p *Poll) IsVoteLimitReached(userID string, index int) bool {
+	return p.IsMultiVote() && !p.HasVotedFor(userID, index) && p.NumberOfVotes(userID) >= p.Settings.MaxVotes
+}
+
+// IsOptionFull returns true if the answer option with a given index got as many votes as it accepts
+func (p *Poll) IsOptionFull(index int) bool {
+	if p.Settings.MaxPerOption == 0 || index < 0 || index >= len(p.AnswerOptions) {
+		return false
+	}
+	return len(p.AnswerOptions[index].Voter) >= p.Settings.MaxPerOption
+}
+
+// IsOptionLimitReached returns true if a given user can't vote for the answer option with a given index, because it's full.
+// Voters of a full answer option can still withdraw their vote.
+func (p *Poll) IsOptionLimitReached(userID string, index int) bool {
+	return p.IsOptionFull(index) && !p.HasVotedFor(userID, index)
+}
+
+// voters returns the IDs of all users that voted for at least one answer option
+func (p *Poll) voters() []string {
+	voters := []string{}
+	seen := map[string]bool{}
+	for _, o := range p.allAnswerOptions() {
+		for _, userID := range o.Voter {
+			if !seen[userID] {
+				seen[userID] = true
+				voters = append(voters, userID)
+			}
+		}
+	}
+	return voters
+}
+
+// NumberOfVoters returns the number of users that have voted in this poll
+func (p *Poll) NumberOfVoters() int {
+	switch p.Settings.VoteMode {
+	case VoteModeRanked:
+		return len(p.Rankings)
+	case VoteModeRating:
+		return len(p.Ratings)
+	case VoteModeEstimate:
+		return len(p.Estimates)
+	}
+	return len(p.voters())
+}
+
+// HaveAllEligibleVotersVoted returns true if the poll ends when all eligible voters voted and none of them is missing.
+// In surveys every eligible voter has to answer all questions.
+func (p *Poll) HaveAllEligibleVotersVoted() bool {
+	if !p.Settings.EndWhenAllVoted || len(p.EligibleVoters) == 0 {
+		return false
+	}
+	for _, userID := range p.EligibleVoters {
+		if !p.hasCompleted(userID) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasQuorum returns true if the results of the poll are only valid once enough channel members voted
+func (p *Poll) HasQuorum() bool {
+	return p.Settings.Quorum > 0
+}
+
+// IsQuorumReached returns true if at least the required percentage of eligible voters voted.
+// Polls without a quorum always reach it.
+func (p *Poll) IsQuorumReached() bool {
+	if !p.HasQuorum() {
+		return true
+	}
+	return p.NumberOfVoters()*100 >= p.Settings.Quorum*p.NumberOfEligibleVoters
+}
+
+// hasCompleted returns true if a given user or their delegate has voted in a poll or the user answered all questions of a survey
+func (p *Poll) hasCompleted(userID string) bool {
+	if !p.IsSurvey() {
+		return p.HasVoted(userID) || (p.HasDelegated(userID) && p.HasVoted(p.Delegations[userID]))
+	}
+	for i := range p.Questions {
+		if !p.HasAnswered(userID, i) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasDeadline returns true if the poll gets ended automatically
+func (p *Poll) HasDeadline() bool {
+	return p.Settings.EndAt != 0
+}
+
+// IsPastDeadline returns true if the deadline of the poll has passed. The poll may still be running until the job that ends it has run.
+func (p *Poll) IsPastDeadline() bool {
+	return p.HasDeadline() && p.Settings.EndAt <= model.GetMillis()
+}
+
+// StartAt returns the time in milliseconds at which the poll starts.
+// That's the post time for scheduled polls and the creation time for all other polls.
+func (p *Poll) StartAt() int64 {
+	if p.Settings.PostAt != 0 {
+		return p.Settings.PostAt
+	}
+	return p.CreatedAt
+}
+
+// IsRecurring returns true if the poll gets posted again on a regular basis
+func (p *Poll) IsRecurring() bool {
+	return p.Settings.Repeat != RecurrenceNone
+}
+
+// NeedsThresholdNotification returns true if the number of voters reached Settings.NotifyAt and the creator hasn't been notified yet
+func (p *Poll) NeedsThresholdNotification() bool {
+	return p.Settings.NotifyAt > 0 && !p.ThresholdNotified && !p.IsEnded() && p.NumberOfVoters() >= p.Settings.NotifyAt
+}
+
+// HasDigest returns true if the creator gets the current standings of the poll on a regular basis
+func (p *Poll) HasDigest() bool {
+	return p.Settings.Digest != RecurrenceNone
+}
+
+// SettingNames returns the names of the Poll Settings the poll uses, without their values.
+// Only the vote mode is included with its value, e.g. "votemode=ranked", since it can't contain user data.
+func (p *Poll) SettingNames() []string {
+	names := []string{}
+	add := func(used bool, name string) {
+		if used {
+			names = append(names, name)
+		}
+	}
+	add(p.Settings.AllowOther, "allow-other")
+	add(p.Settings.Anonymous, "anonymous")
+	add(p.Settings.AnonymousCreator, "anonymous-creator")
+	add(len(p.Settings.Channels) > 0, "channels")
+	add(p.Settings.Compact, "compact")
+	add(p.HasDigest(), "digest")
+	add(p.Settings.SlotDuration != 0, "duration")
+	add(p.HasEmailSummary(), "email")
+	add(p.HasDeadline(), "end")
+	add(p.Settings.EndWhenAllVoted, "end-when-all-voted")
+	add(p.Settings.Invite, "invite")
+	add(p.Settings.LockVotes, "lock-votes")
+	add(p.Settings.MaxPerOption > 0, "max-per-option")
+	add(p.Settings.MembersOnly, "members-only")
+	add(len(p.Settings.Moderators) > 0, "moderators")
+	add(p.Settings.NoGuests, "no-guests")
+	add(p.IsNPS(), "nps")
+	add(p.Settings.NotifyAt > 0, "notify-at")
+	add(p.Settings.Pin, "pin")
+	add(p.Settings.Progress, "progress")
+	add(p.Settings.PublicAddOption, "public-add-option")
+	add(p.Settings.PublicVotes, "public-votes")
+	add(p.HasQuorum(), "quorum")
+	add(p.Settings.Receipts, "receipts")
+	add(p.IsRecurring(), "repeat")
+	add(p.Settings.ResultsTemplate != "", "results-template")
+	add(p.Settings.PostAt != 0, "schedule")
+	add(p.Settings.Secret, "secret")
+	add(p.Settings.Shuffle != ShuffleNone, "shuffle")
+	add(p.Settings.SortResults, "sort-results")
+	add(len(p.Settings.Tags) > 0, "tags")
+	add(p.Settings.VoteToSee, "vote-to-see")
+	add(p.Settings.VoteMode != VoteModeSingle, "votemode="+string(p.Settings.VoteMode))
+	add(p.IsMultiVote(), "votes")
+	add(p.IsWeighted(), "weights")
+	return names
+}
+
+// EraseUser removes all votes of a given user and anonymizes the poll if the user created it.
+// Settings that only serve the creator, like digests, threshold notifications and the email to the creator, are turned off then.
+// It returns true if the poll referenced the user.
+func (p *Poll) EraseUser(userID string) bool {
+	erased := p.HasVoted(userID)
+	p.removeAllVotes(userID)
+	delete(p.VotedAt, userID)
+	// The ballot of the user stays counted, since it can't be traced back to the user anyway
+	ballotVoters := []string{}
+	for _, voter := range p.BallotVoters {
+		if voter != userID {
+			ballotVoters = append(ballotVoters, voter)
+		}
+	}
+	if len(ballotVoters) == 0 {
+		ballotVoters = nil
+	}
+	p.BallotVoters = ballotVoters
+
+	eligibleVoters := p.EligibleVoters[:0]
+	for _, voter := range p.EligibleVoters {
+		if voter == userID {
+			erased = true
+			continue
+		}
+		eligibleVoters = append(eligibleVoters, voter)
+	}
+	if len(eligibleVoters) == 0 {
+		eligibleVoters = nil
+	}
+	p.EligibleVoters = eligibleVoters
+
+	moderators := []string{}
+	for _, moderator := range p.Settings.Moderators {
+		if moderator == userID {
+			erased = true
+			continue
+		}
+		moderators = append(moderators, moderator)
+	}
+	if len(moderators) == 0 {
+		moderators = nil
+	}
+	p.Settings.Moderators = moderators
+
+	if _, ok := p.Settings.Weights[userID]; ok {
+		erased = true
+		delete(p.Settings.Weights, userID)
+	}
+	if len(p.Settings.Weights) == 0 {
+		p.Settings.Weights = nil
+	}
+
+	// Delegations of the user and to the user are dropped, so the delegators get to vote themselves
+	for delegator, delegate := range p.Delegations {
+		if delegator == userID || delegate == userID {
+			erased = true
+			delete(p.Delegations, delegator)
+		}
+	}
+	if len(p.Delegations) == 0 {
+		p.Delegations = nil
+	}
+
+	if p.Creator == userID {
+		erased = true
+		p.Creator = ""
+		p.Settings.AnonymousCreator = true
+		p.Settings.Digest = RecurrenceNone
+		p.Settings.NotifyAt = 0
+		p.Settings.EmailCreator = false
+	}
+	return erased
+}
+
+// NextInstance returns a copy of a recurring poll without any votes that gets posted at a given time in milliseconds.
+// A deadline keeps its distance to the start of the poll.
+func (p *Poll) NextInstance(postAt int64) *Poll {
+	next := &Poll{
+		ID:        model.NewId(),
+		CreatedAt: model.GetMillis(),
+		Creator:   p.Creator,
+		Question:  p.Question,
+		Settings:  p.Settings,
+		ChannelID: p.ChannelID,
+		RootID:    p.RootID,
+		PageSize:  p.PageSize,
+
+		CompactAbove: p.CompactAbove,
+	}
+	for _, o := range p.AnswerOptions {
+		next.AnswerOptions = append(next.AnswerOptions, &AnswerOption{Answer: o.Answer, ImageURL: o.ImageURL, Description: o.Description})
+	}
+	for _, q := range p.Questions {
+		next.Questions = append(next.Questions, &Question{Question: q.Question, AnswerOptions: copyAnswerOptions(q.AnswerOptions, false)})
+	}
+	next.shuffle()
+	next.Settings.PostAt = postAt
+	if p.HasDeadline() {
+		next.Settings.EndAt = postAt + p.Settings.EndAt - p.StartAt()
+	}
+	return next
+}
+
+// IsScheduled returns true if the poll waits to get posted at a later time
+func (p *Poll) IsScheduled() bool {
+	return p.Settings.PostAt != 0 && p.PostID == ""
+}
+
+// End marks the poll as ended
+func (p *Poll) End() {
+	p.EndedAt = model.GetMillis()
+}
+
+// IsEnded returns true if the poll has ended
+func (p *Poll) IsEnded() bool {
+	return p.EndedAt != 0
+}
+
+// Reopen marks an ended poll as running again, which also resumes voting if it was paused. A given time in milliseconds becomes the new deadline.
+// Without a new deadline, a deadline that has passed already is removed, so the poll doesn't end again right away.
+func (p *Poll) Reopen(endAt int64) {
+	p.EndedAt = 0
+	p.PausedAt = 0
+	// The number of eligible voters gets counted again once the poll ends
+	p.NumberOfEligibleVoters = 0
+	if endAt != 0 || p.IsPastDeadline() {
+		p.Settings.EndAt = endAt
+	}
+}
+
+// Pause stops voting until the poll gets resumed. The poll keeps running meanwhile.
+func (p *Poll) Pause() {
+	p.PausedAt = model.GetMillis()
+}
+
+// Resume lets users vote again in a paused poll
+func (p *Poll) Resume() {
+	p.PausedAt = 0
+}
+
+// IsPaused returns true if voting in the poll is paused
+func (p *Poll) IsPaused() bool {
+	return p.PausedAt != 0
+}
+
+// MarkDeleted marks the poll as deleted
+func (p *Poll) MarkDeleted() {
+	p.DeletedAt = model.GetMillis()
+}
+
+// Restore brings back a deleted poll
+func (p *Poll) Restore() {
+	p.DeletedAt = 0
+}
+
+// IsDeleted returns true if the poll got deleted, but not purged yet
+func (p *Poll) IsDeleted() bool {
+	return p.DeletedAt != 0
+}
+
 // EncodeToByte returns a poll as a byte array
 func (p *Poll) EncodeToByte() []byte {
 	b, _ := json.Marshal(p)
@@ -126,11 +1481,90 @@ func DecodePollFromByte(b []byte) *Poll {
 func (p *Poll) Copy() *Poll {
 	p2 := new(Poll)
 	*p2 = *p
-	p2.AnswerOptions = make([]*AnswerOption, len(p.AnswerOptions))
-	for i, o := range p.AnswerOptions {
-		p2.AnswerOptions[i] = new(AnswerOption)
-		p2.AnswerOptions[i].Answer = o.Answer
-		p2.AnswerOptions[i].Voter = o.Voter
+	if p.AnswerOptions != nil {
+		p2.AnswerOptions = copyAnswerOptions(p.AnswerOptions, true)
+	}
+	if p.WriteIns != nil {
+		p2.WriteIns = copyAnswerOptions(p.WriteIns, true)
+	}
+	if p.Rankings != nil {
+		p2.Rankings = make(map[string][]int, len(p.Rankings))
+		for userID, ranking := range p.Rankings {
+			p2.Rankings[userID] = append([]int{}, ranking...)
+		}
+	}
+	if p.Ratings != nil {
+		p2.Ratings = make(map[string][]int, len(p.Ratings))
+		for userID, scores := range p.Ratings {
+			p2.Ratings[userID] = append([]int{}, scores...)
+		}
+	}
+	if p.Estimates != nil {
+		p2.Estimates = make(map[string]float64, len(p.Estimates))
+		for userID, estimate := range p.Estimates {
+			p2.Estimates[userID] = estimate
+		}
+	}
+	if p.Delegations != nil {
+		p2.Delegations = make(map[string]string, len(p.Delegations))
+		for userID, delegateID := range p.Delegations {
+			p2.Delegations[userID] = delegateID
+		}
+	}
+	if p.CrossPosts != nil {
+		p2.CrossPosts = make([]*Post, len(p.CrossPosts))
+		for i, post := range p.CrossPosts {
+			p2.CrossPosts[i] = &Post{ChannelID: post.ChannelID, PostID: post.PostID}
+		}
+	}
+	if p.Questions != nil {
+		p2.Questions = make([]*Question, len(p.Questions))
+		for i, q := range p.Questions {
+			p2.Questions[i] = &Question{Question: q.Question, AnswerOptions: copyAnswerOptions(q.AnswerOptions, true)}
+		}
+	}
+	if p.EligibleVoters != nil {
+		p2.EligibleVoters = append([]string{}, p.EligibleVoters...)
+	}
+	if p.ShuffledOrder != nil {
+		p2.ShuffledOrder = append([]int{}, p.ShuffledOrder...)
+	}
+	if p.BallotVoters != nil {
+		p2.BallotVoters = append([]string{}, p.BallotVoters...)
+	}
+	if p.VotedAt != nil {
+		p2.VotedAt = make(map[string]int64, len(p.VotedAt))
+		for userID, votedAt := range p.VotedAt {
+			p2.VotedAt[userID] = votedAt
+		}
+	}
+	if p.Settings.Moderators != nil {
+		p2.Settings.Moderators = append([]string{}, p.Settings.Moderators...)
+	}
+	if p.Settings.Channels != nil {
+		p2.Settings.Channels = append([]string{}, p.Settings.Channels...)
+	}
+	if p.Settings.Tags != nil {
+		p2.Settings.Tags = append([]string{}, p.Settings.Tags...)
+	}
+	if p.Settings.EmailRecipients != nil {
+		p2.Settings.EmailRecipients = append([]string{}, p.Settings.EmailRecipients...)
+	}
+	if p.Settings.Weights != nil {
+		p2.Settings.Weights = make(map[string]int, len(p.Settings.Weights))
+		for key, weight := range p.Settings.Weights {
+			p2.Settings.Weights[key] = weight
+		}
 	}
 	return p2
 }
+
+// containsString returns true if a given slice contains a given string
+func containsString(slice []string, s string) bool {
+	for _, e := range slice {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,8 @@ package poll_test
 
 import (
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 
 	"bou.ke/monkey"
@@ -23,7 +25,7 @@ func TestNewPoll(t *testing.T) {
 		creator := model.NewRandomString(10)
 		question := model.NewRandomString(10)
 		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
-		p, err := poll.NewPoll(creator, question, answerOptions, []string{"anonymous", "progress", "public-add-option"})
+		p, err := poll.NewPoll(creator, question, answerOptions, []string{"anonymous", "progress", "public-add-option", "secret"})
 
 		require.Nil(t, err)
 		require.NotNil(t, p)
@@ -34,221 +36,2272 @@ func TestNewPoll(t *testing.T) {
 		assert.Equal(&poll.AnswerOption{Answer: answerOptions[0], Voter: nil}, p.AnswerOptions[0])
 		assert.Equal(&poll.AnswerOption{Answer: answerOptions[1], Voter: nil}, p.AnswerOptions[1])
 		assert.Equal(&poll.AnswerOption{Answer: answerOptions[2], Voter: nil}, p.AnswerOptions[2])
-		assert.Equal(poll.Settings{Anonymous: true, Progress: true, PublicAddOption: true}, p.Settings)
+		assert.Equal(poll.Settings{Anonymous: true, Progress: true, PublicAddOption: true, Secret: true}, p.Settings)
 	})
-	t.Run("error, unknown setting", func(t *testing.T) {
+	t.Run("all fine, anonymous creator", func(t *testing.T) {
 		assert := assert.New(t)
 
 		creator := model.NewRandomString(10)
-		question := model.NewRandomString(10)
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(creator, model.NewRandomString(10), answerOptions, []string{"anonymous-creator"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(creator, p.Creator)
+		assert.Equal(poll.Settings{AnonymousCreator: true}, p.Settings)
+	})
+	t.Run("all fine, ranked vote mode", func(t *testing.T) {
+		assert := assert.New(t)
+
 		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
-		p, err := poll.NewPoll(creator, question, answerOptions, []string{"unkownOption"})
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votemode=ranked"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{VoteMode: poll.VoteModeRanked}, p.Settings)
+	})
+	t.Run("all fine, approval vote mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votemode=approval"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{VoteMode: poll.VoteModeApproval}, p.Settings)
+	})
+	t.Run("all fine, rating vote mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votemode=rating"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{VoteMode: poll.VoteModeRating}, p.Settings)
+	})
+	t.Run("all fine, estimate vote mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), nil, []string{"votemode=estimate", "progress"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{VoteMode: poll.VoteModeEstimate, Progress: true}, p.Settings)
+		assert.Empty(p.AnswerOptions)
+		assert.NotNil(p.AddAnswerOption(model.NewRandomString(10)))
+	})
+	for name, settings := range map[string][]string{
+		"error, public votes in estimate poll":   {"votemode=estimate", "public-votes"},
+		"error, max per option in estimate poll": {"votemode=estimate", "max-per-option=2"},
+		"error, sort results in estimate poll":   {"votemode=estimate", "sort-results"},
+		"error, receipts in estimate poll":       {"votemode=estimate", "receipts"},
+		"error, compact in estimate poll":        {"votemode=estimate", "compact"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), nil, settings)
+
+			assert.Nil(p)
+			assert.NotNil(err)
+		})
+	}
+	t.Run("error, public votes in rating poll", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votemode=rating", "public-votes"})
 
 		assert.Nil(p)
 		assert.NotNil(err)
 	})
+	t.Run("all fine, end after duration", func(t *testing.T) {
+		assert := assert.New(t)
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+		defer patch.Unpatch()
 
-	t.Run("error, duplicate option", func(t *testing.T) {
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"end=2h"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{EndAt: 1234567890 + 2*60*60*1000}, p.Settings)
+		assert.True(p.HasDeadline())
+	})
+	t.Run("all fine, end at time", func(t *testing.T) {
 		assert := assert.New(t)
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+		defer patch.Unpatch()
 
-		creator := model.NewRandomString(10)
-		question := model.NewRandomString(10)
-		option := model.NewRandomString(10)
-		answerOptions := []string{option, model.NewRandomString(10), option}
-		p, err := poll.NewPoll(creator, question, answerOptions, nil)
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"end=2024-06-01T17:00"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{EndAt: 1717261200000}, p.Settings)
+	})
+	t.Run("error, end in the past", func(t *testing.T) {
+		assert := assert.New(t)
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1717261200000 })
+		defer patch.Unpatch()
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"end=2024-06-01T17:00"})
 
 		assert.Nil(p)
 		assert.NotNil(err)
 	})
-}
+	t.Run("all fine, scheduled", func(t *testing.T) {
+		assert := assert.New(t)
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+		defer patch.Unpatch()
 
-func TestAddAnswerOption(t *testing.T) {
-	assert := assert.New(t)
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"end=2h", "schedule=1h"})
 
-	t.Run("all fine", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{PostAt: 1234567890 + 60*60*1000, EndAt: 1234567890 + 3*60*60*1000}, p.Settings)
+		assert.True(p.IsScheduled())
 
-		err := p.AddAnswerOption("new option")
-		assert.Nil(err)
-		assert.Equal("new option", p.AnswerOptions[len(p.AnswerOptions)-1].Answer)
+		p.PostID = "postID1"
+		assert.False(p.IsScheduled())
 	})
-	t.Run("dublicant options", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	t.Run("all fine, scheduled at time", func(t *testing.T) {
+		assert := assert.New(t)
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+		defer patch.Unpatch()
 
-		err := p.AddAnswerOption(p.AnswerOptions[0].Answer)
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"schedule=2024-05-01 09:00"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{PostAt: 1714554000000}, p.Settings)
+	})
+	t.Run("error, schedule in the past", func(t *testing.T) {
+		assert := assert.New(t)
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1714554000000 })
+		defer patch.Unpatch()
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"schedule=2024-05-01 09:00"})
+
+		assert.Nil(p)
 		assert.NotNil(err)
 	})
-	t.Run("dublicant options with spaces", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	t.Run("error, end before schedule", func(t *testing.T) {
+		assert := assert.New(t)
+		patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+		defer patch.Unpatch()
 
-		err := p.AddAnswerOption(fmt.Sprintf(" %s ", p.AnswerOptions[0].Answer))
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"end=2024-05-01T08:00", "schedule=2024-05-01 09:00"})
+
+		assert.Nil(p)
 		assert.NotNil(err)
 	})
-	t.Run("empty options", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	t.Run("all fine, recurring", func(t *testing.T) {
+		assert := assert.New(t)
 
-		err := p.AddAnswerOption("")
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"repeat=weekly"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Repeat: poll.RecurrenceWeekly}, p.Settings)
+		assert.True(p.IsRecurring())
+	})
+	t.Run("all fine, digest", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"digest"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Digest: poll.RecurrenceDaily}, p.Settings)
+		assert.True(p.HasDigest())
+	})
+	t.Run("all fine, weekly digest", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"digest=weekly"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Digest: poll.RecurrenceWeekly}, p.Settings)
+	})
+	t.Run("error, unknown digest interval", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"digest=hourly"})
+
+		assert.Nil(p)
 		assert.NotNil(err)
 	})
-	t.Run("empty optinos with spaces", func(t *testing.T) {
-		p := testutils.GetPollWithVotes()
+	t.Run("all fine, shuffle", func(t *testing.T) {
+		assert := assert.New(t)
+		patch := monkey.Patch(rand.Perm, func(n int) []int { return []int{2, 0, 1} })
+		defer patch.Unpatch()
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"shuffle"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Shuffle: poll.ShuffleOnce}, p.Settings)
+		assert.Equal([]int{2, 0, 1}, p.ShuffledOrder)
+	})
+	t.Run("all fine, shuffle always", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"shuffle=always"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Shuffle: poll.ShuffleAlways}, p.Settings)
+		assert.Nil(p.ShuffledOrder)
+	})
+	t.Run("error, unknown shuffle mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"shuffle=sometimes"})
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+	t.Run("error, unknown recurrence", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"repeat=hourly"})
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+	t.Run("error, invalid schedule", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"schedule=tomorrow"})
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+	t.Run("error, invalid end", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"end=tomorrow"})
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+	t.Run("all fine, lock votes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"lock-votes", "votemode=ranked"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{LockVotes: true, VoteMode: poll.VoteModeRanked}, p.Settings)
+	})
+	t.Run("all fine, pin", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"pin"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Pin: true}, p.Settings)
+		assert.False(p.Pinned)
+	})
+	t.Run("all fine, max per option", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"max-per-option=5", "votemode=approval"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{MaxPerOption: 5, VoteMode: poll.VoteModeApproval}, p.Settings)
+	})
+	t.Run("all fine, receipts", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"receipts"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Anonymous: true, LockVotes: true, Receipts: true}, p.Settings)
+	})
+	t.Run("all fine, scheduling vote mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{"2024-06-03", "2024-06-04 10:00", "2024-06-05T14:30"}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votemode=scheduling", "invite", "duration=90m"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{VoteMode: poll.VoteModeScheduling, Invite: true, SlotDuration: 90}, p.Settings)
+		assert.Len(p.AnswerOptions, 3)
+	})
+	t.Run("all fine, scheduling poll with generated dates", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), []string{"2024-05-31"}, []string{"votemode=scheduling", "dates=2024-06-01..2024-06-03"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		answers := []string{}
+		for _, o := range p.AnswerOptions {
+			answers = append(answers, o.Answer)
+		}
+		assert.Equal([]string{"2024-05-31", "Sat 2024-06-01", "Sun 2024-06-02", "Mon 2024-06-03"}, answers)
+	})
+	t.Run("all fine, scheduling poll with generated times", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), nil, []string{"votemode=scheduling", "dates=2024-06-03..2024-06-04", "times=10:00, 14:30"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		answers := []string{}
+		for _, o := range p.AnswerOptions {
+			answers = append(answers, o.Answer)
+		}
+		assert.Equal([]string{"Mon 2024-06-03 10:00", "Mon 2024-06-03 14:30", "Tue 2024-06-04 10:00", "Tue 2024-06-04 14:30"}, answers)
+	})
+	for name, settings := range map[string][]string{
+		"invalid time slot":             {"votemode=scheduling"},
+		"dates without scheduling":      {"dates=2024-06-01..2024-06-03"},
+		"invite without scheduling":     {"invite"},
+		"duration without scheduling":   {"duration=1h"},
+		"times without dates":           {"votemode=scheduling", "times=10:00"},
+		"invalid range of dates":        {"votemode=scheduling", "dates=2024-06-03..tomorrow"},
+		"range of dates ends too early": {"votemode=scheduling", "dates=2024-06-03..2024-06-01"},
+		"invalid time of day":           {"votemode=scheduling", "dates=2024-06-03", "times=noon"},
+		"too many time slots":           {"votemode=scheduling", "dates=2024-06-01..2024-07-31"},
+		"invalid duration":              {"votemode=scheduling", "duration=soon"},
+		"lock votes":                    {"votemode=scheduling", "lock-votes"},
+		"repeat":                        {"votemode=scheduling", "repeat=weekly"},
+	} {
+		t.Run("error, scheduling poll, "+name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			answerOptions := []string{"2024-06-03", "2024-06-04"}
+			if name == "invalid time slot" {
+				answerOptions = append(answerOptions, "Next Friday")
+			}
+			p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, settings)
+
+			assert.Nil(p)
+			assert.NotNil(err)
+		})
+	}
+	t.Run("error, lock votes in approval poll", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"lock-votes", "votemode=approval"})
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+	t.Run("all fine, multiple votes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votes=2"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{MaxVotes: 2}, p.Settings)
+		assert.True(p.IsMultiVote())
+	})
+	t.Run("all fine, single vote", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votes=1"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{}, p.Settings)
+		assert.False(p.IsMultiVote())
+	})
+	t.Run("all fine, public votes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"public-votes", "votemode=approval"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{PublicVotes: true, VoteMode: poll.VoteModeApproval}, p.Settings)
+	})
+	t.Run("all fine, image options", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), []string{"Logo A|https://example.com/a.png", "Logo B|http://example.com/b.png", "None"}, nil)
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal([]*poll.AnswerOption{
+			{Answer: "Logo A", ImageURL: "https://example.com/a.png"},
+			{Answer: "Logo B", ImageURL: "http://example.com/b.png"},
+			{Answer: "None"},
+		}, p.AnswerOptions)
+		assert.True(p.HasImages())
+	})
+	t.Run("all fine, end when all voted", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"end-when-all-voted"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{EndWhenAllVoted: true}, p.Settings)
+		assert.Nil(p.EligibleVoters)
+	})
+	t.Run("all fine, allow other", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"allow-other", "lock-votes"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{AllowOther: true, LockVotes: true}, p.Settings)
+		assert.Nil(p.WriteIns)
+	})
+	t.Run("all fine, members only", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"members-only"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{MembersOnly: true}, p.Settings)
+	})
+	t.Run("all fine, members only disabled", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"members-only=false"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{}, p.Settings)
+	})
+	t.Run("all fine, quorum", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"quorum=50%"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Quorum: 50}, p.Settings)
+		assert.True(p.HasQuorum())
+	})
+	t.Run("all fine, notify at", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"notify-at=25"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{NotifyAt: 25}, p.Settings)
+		assert.False(p.ThresholdNotified)
+	})
+	t.Run("all fine, results template", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"results-template=The winner is {{.Winner}}"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{ResultsTemplate: "The winner is {{.Winner}}"}, p.Settings)
+	})
+	t.Run("all fine, vote to see", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"vote-to-see", "progress"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{VoteToSee: true, Progress: true}, p.Settings)
+	})
+	t.Run("all fine, moderators", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"moderators=@alice, bob,,@alice"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Moderators: []string{"alice", "bob"}}, p.Settings)
+	})
+	t.Run("all fine, weights", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"weights=@alice:3, moderators:2,,creator:1"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Weights: map[string]int{"@alice": 3, "moderators": 2, "creator": 1}}, p.Settings)
+	})
+	t.Run("all fine, no guests", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"no-guests"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{NoGuests: true}, p.Settings)
+	})
+	t.Run("all fine, sort results", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"sort-results"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{SortResults: true}, p.Settings)
+	})
+	t.Run("all fine, compact", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"compact", "votemode=approval"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Compact: true, VoteMode: poll.VoteModeApproval}, p.Settings)
+		assert.True(p.IsCompact())
+	})
+	t.Run("all fine, channels", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"channels=town-square, ~dev,,dev"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Channels: []string{"town-square", "dev"}}, p.Settings)
+	})
+	t.Run("all fine, tags", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"tags=Retro, #team-alpha,,retro"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Tags: []string{"retro", "team-alpha"}}, p.Settings)
+	})
+	t.Run("all fine, email", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"email"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{EmailCreator: true}, p.Settings)
+	})
+	t.Run("all fine, email with recipients", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"email=HR@example.com, boss@example.com,,hr@example.com"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{EmailCreator: true, EmailRecipients: []string{"hr@example.com", "boss@example.com"}}, p.Settings)
+	})
+	t.Run("all fine, quorum without percent sign", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"quorum=100"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Quorum: 100}, p.Settings)
+	})
+	t.Run("all fine, explicit boolean values", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"anonymous=true", "progress", "progress=false"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(poll.Settings{Anonymous: true}, p.Settings)
+	})
+	for name, settings := range map[string][]string{
+		"error, invalid boolean value":              {"progress=maybe"},
+		"error, invalid number of votes":            {"votes=abc"},
+		"error, zero votes":                         {"votes=0"},
+		"error, multiple votes in ranked poll":      {"votes=2", "votemode=ranked"},
+		"error, multiple votes with lock votes":     {"votes=2", "lock-votes"},
+		"error, invalid quorum":                     {"quorum=half"},
+		"error, zero quorum":                        {"quorum=0%"},
+		"error, quorum above 100%":                  {"quorum=101%"},
+		"error, quorum without value":               {"quorum"},
+		"error, invalid notify at":                  {"notify-at=many"},
+		"error, zero notify at":                     {"notify-at=0"},
+		"error, invalid results template":           {"results-template={{.Winner"},
+		"error, results template with unknown data": {"results-template={{.Loser}}"},
+		"error, empty moderators":                   {"moderators=@,"},
+		"error, vote to see with secret":            {"vote-to-see", "secret"},
+		"error, vote to see with public votes":      {"vote-to-see", "public-votes"},
+		"error, moderators without value":           {"moderators"},
+		"error, allow other in ranked poll":         {"allow-other", "votemode=ranked"},
+		"error, allow other in approval poll":       {"allow-other", "votemode=approval"},
+		"error, allow other with multiple votes":    {"allow-other", "votes=2"},
+		"error, zero votes per option":              {"max-per-option=0"},
+		"error, invalid votes per option":           {"max-per-option=many"},
+		"error, max per option in ranked poll":      {"max-per-option=5", "votemode=ranked"},
+		"error, max per option in rating poll":      {"max-per-option=5", "votemode=rating"},
+		"error, receipts in ranked poll":            {"receipts", "votemode=ranked"},
+		"error, receipts in scheduling poll":        {"receipts", "votemode=scheduling"},
+		"error, receipts with multiple votes":       {"receipts", "votes=2"},
+		"error, receipts with allow other":          {"receipts", "allow-other"},
+		"error, receipts with public votes":         {"receipts", "public-votes"},
+		"error, weights without value":              {"weights"},
+		"error, empty channels":                     {"channels=~,"},
+		"error, too many channels":                  {"channels=a,b,c,d,e,f,g,h,i,j,k"},
+		"error, empty tags":                         {"tags=#,"},
+		"error, too many tags":                      {"tags=a,b,c,d,e,f"},
+		"error, tag with invalid characters":        {"tags=team alpha"},
+		"error, tag too long":                       {"tags=" + strings.Repeat("a", poll.MaxTagLength+1)},
+		"error, empty email addresses":              {"email=,"},
+		"error, invalid email address":              {"email=hr@example.com,boss"},
+		"error, email address with name":            {"email=HR <hr@example.com>"},
+		"error, too many email addresses":           {"email=a@x.org,b@x.org,c@x.org,d@x.org,e@x.org,f@x.org,g@x.org,h@x.org,i@x.org,j@x.org,k@x.org"},
+		"error, weight without user":                {"weights=@:3"},
+		"error, weight without @":                   {"weights=alice:3"},
+		"error, weight without number":              {"weights=@alice"},
+		"error, zero weight":                        {"weights=@alice:0"},
+		"error, weight above maximum":               {"weights=@alice:101"},
+		"error, weights in ranked poll":             {"weights=@alice:3", "votemode=ranked"},
+		"error, weights in approval poll":           {"weights=@alice:3", "votemode=approval"},
+		"error, weights with receipts":              {"weights=@alice:3", "receipts"},
+		"error, sort results in ranked poll":        {"sort-results", "votemode=ranked"},
+		"error, sort results in rating poll":        {"sort-results", "votemode=rating"},
+		"error, compact in ranked poll":             {"compact", "votemode=ranked"},
+		"error, compact in rating poll":             {"compact", "votemode=rating"},
+		"error, answer options in estimate poll":    {"votemode=estimate"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+			p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, settings)
+
+			assert.Nil(p)
+			assert.NotNil(err)
+		})
+	}
+	t.Run("error, unknown vote mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(model.NewRandomString(10), model.NewRandomString(10), answerOptions, []string{"votemode=unknown"})
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+	t.Run("error, unknown setting", func(t *testing.T) {
+		assert := assert.New(t)
+
+		creator := model.NewRandomString(10)
+		question := model.NewRandomString(10)
+		answerOptions := []string{model.NewRandomString(10), model.NewRandomString(10), model.NewRandomString(10)}
+		p, err := poll.NewPoll(creator, question, answerOptions, []string{"unkownOption"})
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+
+	t.Run("error, duplicate option", func(t *testing.T) {
+		assert := assert.New(t)
+
+		creator := model.NewRandomString(10)
+		question := model.NewRandomString(10)
+		option := model.NewRandomString(10)
+		answerOptions := []string{option, model.NewRandomString(10), option}
+		p, err := poll.NewPoll(creator, question, answerOptions, nil)
+
+		assert.Nil(p)
+		assert.NotNil(err)
+	})
+}
+
+func TestAddAnswerOption(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("new option")
+		assert.Nil(err)
+		assert.Equal("new option", p.AnswerOptions[len(p.AnswerOptions)-1].Answer)
+		assert.False(p.HasImages())
+	})
+	t.Run("all fine, with image", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption(" Logo A | https://example.com/a.png ")
+		assert.Nil(err)
+		assert.Equal(&poll.AnswerOption{Answer: "Logo A", ImageURL: "https://example.com/a.png"}, p.AnswerOptions[len(p.AnswerOptions)-1])
+		assert.True(p.HasImages())
+	})
+	t.Run("all fine, with description", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("Logo A | Our current logo, used since 2015")
+		assert.Nil(err)
+		assert.Equal(&poll.AnswerOption{Answer: "Logo A", Description: "Our current logo, used since 2015"}, p.AnswerOptions[len(p.AnswerOptions)-1])
+		assert.True(p.HasDescriptions())
+		assert.False(p.HasImages())
+	})
+	t.Run("all fine, with description and image", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("Logo A|Our current logo|https://example.com/a.png")
+		assert.Nil(err)
+		assert.Equal(&poll.AnswerOption{Answer: "Logo A", ImageURL: "https://example.com/a.png", Description: "Our current logo"}, p.AnswerOptions[len(p.AnswerOptions)-1])
+	})
+	for name, imageURL := range map[string]string{
+		"empty image URL":        "",
+		"relative image URL":     "/files/a.png",
+		"unsupported scheme":     "ftp://example.com/a.png",
+		"image URL without host": "https://",
+		"two descriptions":       "Our current logo|Blue",
+		"two image URLs":         "https://example.com/a.png|https://example.com/b.png",
+		"empty description":      "|https://example.com/a.png",
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithVotes()
+
+			err := p.AddAnswerOption("Logo A|" + imageURL)
+			assert.NotNil(err)
+			assert.Len(p.AnswerOptions, 3)
+		})
+	}
+	t.Run("dublicant options", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption(p.AnswerOptions[0].Answer)
+		assert.NotNil(err)
+	})
+	t.Run("dublicant options with different image", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption(p.AnswerOptions[0].Answer + "|https://example.com/a.png")
+		assert.NotNil(err)
+	})
+	t.Run("dublicant options with spaces", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption(fmt.Sprintf(" %s ", p.AnswerOptions[0].Answer))
+		assert.NotNil(err)
+	})
+	t.Run("empty options", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("")
+		assert.NotNil(err)
+	})
+	t.Run("empty optinos with spaces", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		err := p.AddAnswerOption("  ")
+		assert.NotNil(err)
+	})
+	t.Run("ended poll", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.EndedAt = 1234567890
+
+		err := p.AddAnswerOption("new option")
+		assert.NotNil(err)
+		assert.Len(p.AnswerOptions, 3)
+	})
+	t.Run("scheduling poll", func(t *testing.T) {
+		p := testutils.GetSchedulingPoll()
+
+		err := p.AddAnswerOption("2024-06-06 12:00")
+		assert.Nil(err)
+		assert.Len(p.AnswerOptions, 4)
+	})
+	t.Run("scheduling poll, no time slot", func(t *testing.T) {
+		p := testutils.GetSchedulingPoll()
+
+		err := p.AddAnswerOption("new option")
+		assert.NotNil(err)
+		assert.Len(p.AnswerOptions, 3)
+	})
+}
+
+func TestEncodeDecode(t *testing.T) {
+	p1 := testutils.GetPollWithVotes()
+	p2 := poll.DecodePollFromByte(p1.EncodeToByte())
+	assert.Equal(t, p1, p2)
+}
+
+func TestDecode(t *testing.T) {
+	p := poll.DecodePollFromByte([]byte{})
+	assert.Nil(t, p)
+}
+
+func TestUpdateVote(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Poll         poll.Poll
+		UserID       string
+		Index        int
+		ExpectedPoll poll.Poll
+		Error        bool
+	}{
+		"Negative Index": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			UserID: "a",
+			Index:  -1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			Error: true,
+		},
+		"To high Index": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			UserID: "a",
+			Index:  2,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			Error: true,
+		},
+		"Invalid userID": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			UserID: "",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			Error: true,
+		},
+		"Idempotent": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			UserID: "a",
+			Index:  0,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+				VoteChanges: 1,
+			},
+			Error: false,
+		},
+		"Valid Vote": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+				},
+				VoteChanges: 1,
+			},
+			Error: false,
+		},
+		"Vote is locked": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{LockVotes: true},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{LockVotes: true},
+			},
+			Error: true,
+		},
+		"First vote, votes locked": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{LockVotes: true},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+				},
+				Settings: poll.Settings{LockVotes: true},
+				VotedAt:  map[string]int64{"a": 1234567890},
+			},
+			Error: false,
+		},
+		"Poll has ended": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+				EndedAt: 1234567890,
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+				EndedAt: 1234567890,
+			},
+			Error: true,
+		},
+		"Ranked poll": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{VoteMode: poll.VoteModeRanked},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{VoteMode: poll.VoteModeRanked},
+			},
+			Error: true,
+		},
+		"Rating poll": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{VoteMode: poll.VoteModeRating},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1"},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{VoteMode: poll.VoteModeRating},
+			},
+			Error: true,
+		},
+		"Approval poll, approve additional option": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{VoteMode: poll.VoteModeApproval},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+				},
+				Settings:    poll.Settings{VoteMode: poll.VoteModeApproval},
+				VoteChanges: 1,
+			},
+			Error: false,
+		},
+		"Scheduling poll, mark additional slot as available": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+				},
+				Settings: poll.Settings{VoteMode: poll.VoteModeScheduling},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+				},
+				Settings:    poll.Settings{VoteMode: poll.VoteModeScheduling},
+				VoteChanges: 1,
+			},
+			Error: false,
+		},
+		"Approval poll, withdraw approval": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a", "b"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+				},
+				Settings: poll.Settings{VoteMode: poll.VoteModeApproval},
+			},
+			UserID: "a",
+			Index:  0,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"b"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+				},
+				Settings:    poll.Settings{VoteMode: poll.VoteModeApproval},
+				VoteChanges: 1,
+			},
+			Error: false,
+		},
+		"Multiple votes, second vote": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2"},
+					{Answer: "Answer 3"},
+				},
+				Settings: poll.Settings{MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  1,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+					{Answer: "Answer 3"},
+				},
+				Settings:    poll.Settings{MaxVotes: 2},
+				VoteChanges: 1,
+			},
+			Error: false,
+		},
+		"Multiple votes, withdraw vote at limit": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+					{Answer: "Answer 3"},
+				},
+				Settings: poll.Settings{MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  0,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+					{Answer: "Answer 3"},
+				},
+				Settings:    poll.Settings{MaxVotes: 2},
+				VoteChanges: 1,
+			},
+			Error: false,
+		},
+		"Multiple votes, limit reached": {
+			Poll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+					{Answer: "Answer 3"},
+				},
+				Settings: poll.Settings{MaxVotes: 2},
+			},
+			UserID: "a",
+			Index:  2,
+			ExpectedPoll: poll.Poll{
+				Question: "Question",
+				AnswerOptions: []*poll.AnswerOption{
+					{Answer: "Answer 1",
+						Voter: []string{"a"}},
+					{Answer: "Answer 2",
+						Voter: []string{"a"}},
+					{Answer: "Answer 3"},
+				},
+				Settings: poll.Settings{MaxVotes: 2},
+			},
+			Error: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := test.Poll.UpdateVote(test.UserID, test.Index)
+
+			if test.Error {
+				assert.NotNil(err)
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(test.ExpectedPoll, test.Poll)
+		})
+	}
+}
+
+func TestHasVoted(t *testing.T) {
+	p1 := &poll.Poll{Question: "Question",
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1",
+				Voter: []string{"a"}},
+			{Answer: "Answer 2"},
+		},
+	}
+	assert.True(t, p1.HasVoted("a"))
+	assert.False(t, p1.HasVoted("b"))
+
+	p2 := testutils.GetPollWithRankings()
+	assert.True(t, p2.HasVoted("userID4"))
+	assert.False(t, p2.HasVoted("userID5"))
+
+	p3 := testutils.GetPollWithRatings()
+	assert.True(t, p3.HasVoted("userID3"))
+	assert.False(t, p3.HasVoted("userID4"))
+
+	p4 := testutils.GetPollWithEstimates()
+	assert.True(t, p4.HasVoted("userID4"))
+	assert.False(t, p4.HasVoted("userID5"))
+}
+
+func TestNumberOfVoters(t *testing.T) {
+	assert.Equal(t, 0, testutils.GetPoll().NumberOfVoters())
+	assert.Equal(t, 4, testutils.GetPollWithVotes().NumberOfVoters())
+	assert.Equal(t, 4, testutils.GetPollWithRankings().NumberOfVoters())
+	assert.Equal(t, 3, testutils.GetPollWithRatings().NumberOfVoters())
+	assert.Equal(t, 4, testutils.GetPollWithEstimates().NumberOfVoters())
+
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{VoteMode: poll.VoteModeApproval})
+	p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+	assert.Equal(t, 4, p.NumberOfVoters())
+}
+
+func TestHasVotedFor(t *testing.T) {
+	p := &poll.Poll{Question: "Question",
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1",
+				Voter: []string{"a"}},
+			{Answer: "Answer 2",
+				Voter: []string{"a", "b"}},
+		},
+	}
+	assert.True(t, p.HasVotedFor("a", 0))
+	assert.True(t, p.HasVotedFor("b", 1))
+	assert.False(t, p.HasVotedFor("b", 0))
+	assert.False(t, p.HasVotedFor("a", 2))
+	assert.False(t, p.HasVotedFor("a", -1))
+}
+
+func TestNumberOfVotes(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 2})
+	p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+
+	assert.Equal(t, 2, p.NumberOfVotes("userID1"))
+	assert.Equal(t, 1, p.NumberOfVotes("userID2"))
+	assert.Equal(t, 0, p.NumberOfVotes("userID5"))
+}
+
+func TestIsVoteLimitReached(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxVotes: 2})
+	p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+
+	assert.True(t, p.IsVoteLimitReached("userID1", 2))
+	assert.False(t, p.IsVoteLimitReached("userID1", 0))
+	assert.False(t, p.IsVoteLimitReached("userID2", 2))
+	assert.False(t, testutils.GetPollWithVotes().IsVoteLimitReached("userID1", 2))
+}
+
+func TestIsOptionLimitReached(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxPerOption: 3})
+
+	assert.True(t, p.IsOptionFull(0))
+	assert.False(t, p.IsOptionFull(1))
+	assert.False(t, p.IsOptionFull(3))
+	assert.True(t, p.IsOptionLimitReached("userID4", 0))
+	// Voters of a full answer option can withdraw their vote
+	assert.False(t, p.IsOptionLimitReached("userID1", 0))
+	assert.False(t, p.IsOptionLimitReached("userID1", 1))
+	assert.False(t, testutils.GetPollWithVotes().IsOptionLimitReached("userID4", 0))
+
+	// The answer option reopens once a voter withdraws
+	require.Nil(t, p.UpdateVote("userID1", 2))
+	assert.False(t, p.IsOptionFull(0))
+	assert.False(t, p.IsOptionLimitReached("userID4", 0))
+}
+
+func TestIsQuorumReached(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll     *poll.Poll
+		Expected bool
+	}{
+		"No quorum": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.NumberOfEligibleVoters = 100
+				return p
+			}(),
+			Expected: true,
+		},
+		"Quorum reached": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Quorum: 50})
+				p.NumberOfEligibleVoters = 8
+				return p
+			}(),
+			Expected: true,
+		},
+		"Quorum not reached": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Quorum: 50})
+				p.NumberOfEligibleVoters = 9
+				return p
+			}(),
+			Expected: false,
+		},
+		"Ranked poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRankings()
+				p.Settings.Quorum = 100
+				p.NumberOfEligibleVoters = len(p.Rankings)
+				return p
+			}(),
+			Expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Poll.IsQuorumReached())
+		})
+	}
+}
+
+func TestNeedsThresholdNotification(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll     *poll.Poll
+		Expected bool
+	}{
+		"No threshold": {
+			Poll:     testutils.GetPollWithVotes(),
+			Expected: false,
+		},
+		"Threshold reached": {
+			Poll:     testutils.GetPollWithVotesAndSettings(poll.Settings{NotifyAt: 4}),
+			Expected: true,
+		},
+		"Threshold not reached": {
+			Poll:     testutils.GetPollWithVotesAndSettings(poll.Settings{NotifyAt: 5}),
+			Expected: false,
+		},
+		"Creator has already been notified": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{NotifyAt: 4})
+				p.ThresholdNotified = true
+				return p
+			}(),
+			Expected: false,
+		},
+		"Poll has ended": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{NotifyAt: 4})
+				p.EndedAt = 1234567890
+				return p
+			}(),
+			Expected: false,
+		},
+		"Ranked poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRankings()
+				p.Settings.NotifyAt = 4
+				return p
+			}(),
+			Expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Poll.NeedsThresholdNotification())
+		})
+	}
+}
+
+func TestHaveAllEligibleVotersVoted(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll     *poll.Poll
+		Expected bool
+	}{
+		"Setting not set": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.EligibleVoters = []string{"userID1", "userID4"}
+				return p
+			}(),
+			Expected: false,
+		},
+		"No eligible voters": {
+			Poll:     testutils.GetPollWithVotesAndSettings(poll.Settings{EndWhenAllVoted: true}),
+			Expected: false,
+		},
+		"Eligible voter missing": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{EndWhenAllVoted: true})
+				p.EligibleVoters = []string{"userID1", "userID5"}
+				return p
+			}(),
+			Expected: false,
+		},
+		"All eligible voters voted": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{EndWhenAllVoted: true})
+				p.EligibleVoters = []string{"userID1", "userID2", "userID4"}
+				return p
+			}(),
+			Expected: true,
+		},
+		"Survey with unanswered question": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetSurveyWithVotes()
+				p.Settings.EndWhenAllVoted = true
+				p.EligibleVoters = []string{"userID1", "userID3"}
+				return p
+			}(),
+			Expected: false,
+		},
+		"Survey with all questions answered": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetSurveyWithVotes()
+				p.Settings.EndWhenAllVoted = true
+				p.EligibleVoters = []string{"userID1"}
+				return p
+			}(),
+			Expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Poll.HaveAllEligibleVotersVoted())
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	p := testutils.GetPoll()
+	assert.False(t, p.IsEnded())
+
+	p.End()
+	assert.True(t, p.IsEnded())
+	assert.Equal(t, int64(1234567890), p.EndedAt)
+}
+
+func TestReopen(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		EndAt         int64
+		NewEndAt      int64
+		ExpectedEndAt int64
+	}{
+		"No deadline":                      {},
+		"New deadline":                     {NewEndAt: 1234567999, ExpectedEndAt: 1234567999},
+		"Deadline not passed yet":          {EndAt: 1234567999, ExpectedEndAt: 1234567999},
+		"Deadline passed":                  {EndAt: 1234567000},
+		"Deadline passed and new deadline": {EndAt: 1234567000, NewEndAt: 1234567999, ExpectedEndAt: 1234567999},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{EndAt: test.EndAt})
+			p.EndedAt = 1234567000
+			p.NumberOfEligibleVoters = 5
+			p.PausedAt = 1234566000
+
+			p.Reopen(test.NewEndAt)
+			assert.False(t, p.IsEnded())
+			assert.False(t, p.IsPaused())
+			assert.Equal(t, 0, p.NumberOfEligibleVoters)
+			assert.Equal(t, test.ExpectedEndAt, p.Settings.EndAt)
+		})
+	}
+}
+
+func TestMarkDeleted(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	p := testutils.GetPoll()
+	assert.False(t, p.IsDeleted())
+
+	p.MarkDeleted()
+	assert.True(t, p.IsDeleted())
+	assert.Equal(t, int64(1234567890), p.DeletedAt)
+
+	p.Restore()
+	assert.False(t, p.IsDeleted())
+}
+
+func TestPause(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	p := testutils.GetPoll()
+	assert.False(t, p.IsPaused())
+
+	p.Pause()
+	assert.True(t, p.IsPaused())
+	assert.Equal(t, int64(1234567890), p.PausedAt)
+
+	p.Resume()
+	assert.False(t, p.IsPaused())
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// 2024-01-31T09:00 UTC
+	start := int64(1706691600000)
+
+	assert.Equal(t, int64(1706778000000), poll.RecurrenceDaily.Next(start))
+	assert.Equal(t, int64(1707296400000), poll.RecurrenceWeekly.Next(start))
+	// Normalized from 2024-02-31 to 2024-03-02
+	assert.Equal(t, int64(1709370000000), poll.RecurrenceMonthly.Next(start))
+	assert.Equal(t, int64(0), poll.RecurrenceNone.Next(start))
+}
+
+func TestIsPastDeadline(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	assert.False(t, testutils.GetPoll().IsPastDeadline())
+	assert.False(t, testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567891}).IsPastDeadline())
+	assert.True(t, testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567890}).IsPastDeadline())
+	assert.True(t, testutils.GetPollWithSettings(poll.Settings{EndAt: 1234567889}).IsPastDeadline())
+}
+
+func TestStartAt(t *testing.T) {
+	p := testutils.GetPoll()
+	assert.Equal(t, p.CreatedAt, p.StartAt())
+
+	p.Settings.PostAt = 1234567899
+	assert.Equal(t, int64(1234567899), p.StartAt())
+}
+
+func TestNextInstance(t *testing.T) {
+	patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567999 })
+	patch2 := monkey.Patch(model.NewId, func() string { return "pollID2" })
+	defer patch1.Unpatch()
+	defer patch2.Unpatch()
+
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Progress: true, Repeat: poll.RecurrenceDaily, EndAt: 1234567890 + 1000})
+	p.PostID = "postID1"
+	p.ChannelID = "channelID1"
+	p.RootID = "rootID1"
+	p.EndedAt = 1234567895
+	p.AnswerOptions[0].ImageURL = "https://example.com/a.png"
+
+	next := p.NextInstance(1234567990)
+	assert.Equal(t, &poll.Poll{
+		ID:        "pollID2",
+		CreatedAt: 1234567999,
+		Creator:   "userID1",
+		Question:  "Question",
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "Answer 1", ImageURL: "https://example.com/a.png"},
+			{Answer: "Answer 2"},
+			{Answer: "Answer 3"},
+		},
+		Settings:  poll.Settings{Progress: true, Repeat: poll.RecurrenceDaily, PostAt: 1234567990, EndAt: 1234567990 + 1000},
+		ChannelID: "channelID1",
+		RootID:    "rootID1",
+	}, next)
+}
+
+func TestNextInstanceShuffled(t *testing.T) {
+	patch := monkey.Patch(rand.Perm, func(n int) []int { return []int{1, 2, 0} })
+	defer patch.Unpatch()
+
+	p := testutils.GetPollWithSettings(poll.Settings{Shuffle: poll.ShuffleOnce, Repeat: poll.RecurrenceDaily})
+	p.ShuffledOrder = []int{2, 0, 1}
+
+	next := p.NextInstance(1234567990)
+	assert.Equal(t, []int{1, 2, 0}, next.ShuffledOrder)
+}
+
+func TestDisplayOrder(t *testing.T) {
+	t.Run("not shuffled", func(t *testing.T) {
+		p := testutils.GetPoll()
+		assert.Equal(t, []int{0, 1, 2}, p.DisplayOrder())
+	})
+	t.Run("shuffled once", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Shuffle: poll.ShuffleOnce})
+		p.ShuffledOrder = []int{2, 0, 1}
+		assert.Equal(t, []int{2, 0, 1}, p.DisplayOrder())
+	})
+	t.Run("shuffled once, added option is shown last", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Shuffle: poll.ShuffleOnce})
+		p.ShuffledOrder = []int{2, 0, 1}
+		require.Nil(t, p.AddAnswerOption("Answer 4"))
+		assert.Equal(t, []int{2, 0, 1, 3}, p.DisplayOrder())
+	})
+	t.Run("shuffled always", func(t *testing.T) {
+		patch := monkey.Patch(rand.Perm, func(n int) []int { return []int{1, 0, 2} })
+		defer patch.Unpatch()
+
+		p := testutils.GetPollWithSettings(poll.Settings{Shuffle: poll.ShuffleAlways})
+		assert.Equal(t, []int{1, 0, 2}, p.DisplayOrder())
+	})
+}
+
+func TestUpdateRanking(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll             *poll.Poll
+		UserID           string
+		Ranking          []int
+		ExpectedRankings map[string][]int
+		Error            bool
+	}{
+		"First ranking": {
+			Poll:             testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked}),
+			UserID:           "a",
+			Ranking:          []int{2, 0},
+			ExpectedRankings: map[string][]int{"a": {2, 0}},
+			Error:            false,
+		},
+		"Update ranking": {
+			Poll:    testutils.GetPollWithRankings(),
+			UserID:  "userID4",
+			Ranking: []int{1, 2, 0},
+			ExpectedRankings: map[string][]int{
+				"userID1": {0, 1, 2},
+				"userID2": {1, 0},
+				"userID3": {2, 1},
+				"userID4": {1, 2, 0},
+			},
+			Error: false,
+		},
+		"Poll has ended": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRankings()
+				p.EndedAt = 1234567890
+				return p
+			}(),
+			UserID:  "userID4",
+			Ranking: []int{1, 2, 0},
+			ExpectedRankings: map[string][]int{
+				"userID1": {0, 1, 2},
+				"userID2": {1, 0},
+				"userID3": {2, 1},
+				"userID4": {0},
+			},
+			Error: true,
+		},
+		"Ranking is locked": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRankings()
+				p.Settings.LockVotes = true
+				return p
+			}(),
+			UserID:  "userID4",
+			Ranking: []int{1, 2, 0},
+			ExpectedRankings: map[string][]int{
+				"userID1": {0, 1, 2},
+				"userID2": {1, 0},
+				"userID3": {2, 1},
+				"userID4": {0},
+			},
+			Error: true,
+		},
+		"Not a ranked poll": {
+			Poll:             testutils.GetPoll(),
+			UserID:           "a",
+			Ranking:          []int{0},
+			ExpectedRankings: nil,
+			Error:            true,
+		},
+		"Invalid userID": {
+			Poll:             testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked}),
+			UserID:           "",
+			Ranking:          []int{0},
+			ExpectedRankings: nil,
+			Error:            true,
+		},
+		"Empty ranking": {
+			Poll:             testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked}),
+			UserID:           "a",
+			Ranking:          []int{},
+			ExpectedRankings: nil,
+			Error:            true,
+		},
+		"Invalid index": {
+			Poll:             testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked}),
+			UserID:           "a",
+			Ranking:          []int{0, 3},
+			ExpectedRankings: nil,
+			Error:            true,
+		},
+		"Duplicate index": {
+			Poll:             testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked}),
+			UserID:           "a",
+			Ranking:          []int{1, 1},
+			ExpectedRankings: nil,
+			Error:            true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := test.Poll.UpdateRanking(test.UserID, test.Ranking)
+
+			if test.Error {
+				assert.NotNil(err)
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(test.ExpectedRankings, test.Poll.Rankings)
+		})
+	}
+}
+
+func TestUpdateRating(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		UserID          string
+		Scores          []int
+		ExpectedRatings map[string][]int
+		Error           bool
+	}{
+		"First rating": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating}),
+			UserID:          "a",
+			Scores:          []int{5, 0, 1},
+			ExpectedRatings: map[string][]int{"a": {5, 0, 1}},
+			Error:           false,
+		},
+		"Update rating": {
+			Poll:   testutils.GetPollWithRatings(),
+			UserID: "userID3",
+			Scores: []int{1, 1, 1},
+			ExpectedRatings: map[string][]int{
+				"userID1": {5, 2, 0},
+				"userID2": {4, 3, 1},
+				"userID3": {1, 1, 1},
+			},
+			Error: false,
+		},
+		"Poll has ended": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRatings()
+				p.EndedAt = 1234567890
+				return p
+			}(),
+			UserID: "userID3",
+			Scores: []int{1, 1, 1},
+			ExpectedRatings: map[string][]int{
+				"userID1": {5, 2, 0},
+				"userID2": {4, 3, 1},
+				"userID3": {3},
+			},
+			Error: true,
+		},
+		"Rating is locked": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRatings()
+				p.Settings.LockVotes = true
+				return p
+			}(),
+			UserID: "userID3",
+			Scores: []int{1, 1, 1},
+			ExpectedRatings: map[string][]int{
+				"userID1": {5, 2, 0},
+				"userID2": {4, 3, 1},
+				"userID3": {3},
+			},
+			Error: true,
+		},
+		"Not a rating poll": {
+			Poll:            testutils.GetPoll(),
+			UserID:          "a",
+			Scores:          []int{1},
+			ExpectedRatings: nil,
+			Error:           true,
+		},
+		"Invalid userID": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating}),
+			UserID:          "",
+			Scores:          []int{1},
+			ExpectedRatings: nil,
+			Error:           true,
+		},
+		"Empty rating": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating}),
+			UserID:          "a",
+			Scores:          []int{0, 0, 0},
+			ExpectedRatings: nil,
+			Error:           true,
+		},
+		"Too many scores": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating}),
+			UserID:          "a",
+			Scores:          []int{1, 2, 3, 4},
+			ExpectedRatings: nil,
+			Error:           true,
+		},
+		"Invalid score": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating}),
+			UserID:          "a",
+			Scores:          []int{1, 6},
+			ExpectedRatings: nil,
+			Error:           true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
 
-		err := p.AddAnswerOption("  ")
-		assert.NotNil(err)
-	})
-}
+			err := test.Poll.UpdateRating(test.UserID, test.Scores)
 
-func TestEncodeDecode(t *testing.T) {
-	p1 := testutils.GetPollWithVotes()
-	p2 := poll.DecodePollFromByte(p1.EncodeToByte())
-	assert.Equal(t, p1, p2)
+			if test.Error {
+				assert.NotNil(err)
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(test.ExpectedRatings, test.Poll.Ratings)
+		})
+	}
 }
 
-func TestDecode(t *testing.T) {
-	p := poll.DecodePollFromByte([]byte{})
-	assert.Nil(t, p)
-}
+func TestUpdateWriteIn(t *testing.T) {
+	pollWithWriteIns := func() *poll.Poll {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{AllowOther: true})
+		p.WriteIns = []*poll.AnswerOption{
+			{Answer: "Maybe", Voter: []string{"userID5"}},
+			{Answer: "Tomorrow", Voter: []string{"userID6"}},
+		}
+		return p
+	}
 
-func TestUpdateVote(t *testing.T) {
 	for name, test := range map[string]struct {
-		Poll         poll.Poll
-		UserID       string
-		Index        int
-		ExpectedPoll poll.Poll
-		Error        bool
+		Poll                  *poll.Poll
+		UserID                string
+		Answer                string
+		ExpectedAnswerOptions []*poll.AnswerOption
+		ExpectedWriteIns      []*poll.AnswerOption
+		Error                 bool
 	}{
-		"Negative Index": {
-			Poll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+		"New write-in": {
+			Poll:   pollWithWriteIns(),
+			UserID: "userID7",
+			Answer: "  Next   week ",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
 			},
-			UserID: "a",
-			Index:  -1,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+			ExpectedWriteIns: []*poll.AnswerOption{
+				{Answer: "Maybe", Voter: []string{"userID5"}},
+				{Answer: "Tomorrow", Voter: []string{"userID6"}},
+				{Answer: "Next week", Voter: []string{"userID7"}},
 			},
-			Error: true,
+			Error: false,
 		},
-		"To high Index": {
-			Poll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+		"Identical write-in is merged": {
+			Poll:   pollWithWriteIns(),
+			UserID: "userID7",
+			Answer: "maybe",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
 			},
-			UserID: "a",
-			Index:  2,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+			ExpectedWriteIns: []*poll.AnswerOption{
+				{Answer: "Maybe", Voter: []string{"userID5", "userID7"}},
+				{Answer: "Tomorrow", Voter: []string{"userID6"}},
 			},
-			Error: true,
+			Error: false,
 		},
-		"Invalid userID": {
-			Poll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+		"Write-in of an answer option counts for it": {
+			Poll:   pollWithWriteIns(),
+			UserID: "userID1",
+			Answer: "answer 2",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4", "userID1"}},
+				{Answer: "Answer 3"},
 			},
-			UserID: "",
-			Index:  1,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+			ExpectedWriteIns: []*poll.AnswerOption{
+				{Answer: "Maybe", Voter: []string{"userID5"}},
+				{Answer: "Tomorrow", Voter: []string{"userID6"}},
 			},
-			Error: true,
+			Error: false,
 		},
-		"Idempotent": {
-			Poll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+		"Changed write-in drops the previous one": {
+			Poll:   pollWithWriteIns(),
+			UserID: "userID6",
+			Answer: "Maybe",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
 			},
-			UserID: "a",
-			Index:  0,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+			ExpectedWriteIns: []*poll.AnswerOption{
+				{Answer: "Maybe", Voter: []string{"userID5", "userID6"}},
 			},
 			Error: false,
 		},
-		"Valid Vote": {
-			Poll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{"a"}},
-					{Answer: "Answer 2"},
-				},
+		"Empty answer": {
+			Poll:   pollWithWriteIns(),
+			UserID: "userID7",
+			Answer: "   ",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
 			},
-			UserID: "a",
-			Index:  1,
-			ExpectedPoll: poll.Poll{
-				Question: "Question",
-				AnswerOptions: []*poll.AnswerOption{
-					{Answer: "Answer 1",
-						Voter: []string{}},
-					{Answer: "Answer 2",
-						Voter: []string{"a"}},
-				},
+			ExpectedWriteIns: pollWithWriteIns().WriteIns,
+			Error:            true,
+		},
+		"Answer too long": {
+			Poll:   pollWithWriteIns(),
+			UserID: "userID7",
+			Answer: strings.Repeat("a", poll.WriteInMaxLength+1),
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
 			},
-			Error: false,
+			ExpectedWriteIns: pollWithWriteIns().WriteIns,
+			Error:            true,
+		},
+		"Vote is locked": {
+			Poll: func() *poll.Poll {
+				p := pollWithWriteIns()
+				p.Settings.LockVotes = true
+				return p
+			}(),
+			UserID: "userID1",
+			Answer: "Maybe",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
+			},
+			ExpectedWriteIns: pollWithWriteIns().WriteIns,
+			Error:            true,
+		},
+		"Poll has ended": {
+			Poll: func() *poll.Poll {
+				p := pollWithWriteIns()
+				p.EndedAt = 1234567890
+				return p
+			}(),
+			UserID: "userID7",
+			Answer: "Maybe",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
+			},
+			ExpectedWriteIns: pollWithWriteIns().WriteIns,
+			Error:            true,
+		},
+		"Poll doesn't allow other answers": {
+			Poll:   testutils.GetPollWithVotes(),
+			UserID: "userID7",
+			Answer: "Maybe",
+			ExpectedAnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1", Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Voter: []string{"userID4"}},
+				{Answer: "Answer 3"},
+			},
+			ExpectedWriteIns: nil,
+			Error:            true,
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			assert := assert.New(t)
 
-			err := test.Poll.UpdateVote(test.UserID, test.Index)
+			err := test.Poll.UpdateWriteIn(test.UserID, test.Answer)
 
 			if test.Error {
 				assert.NotNil(err)
 			} else {
 				assert.Nil(err)
 			}
-			assert.Equal(test.ExpectedPoll, test.Poll)
+			assert.Equal(test.ExpectedAnswerOptions, test.Poll.AnswerOptions)
+			assert.Equal(test.ExpectedWriteIns, test.Poll.WriteIns)
 		})
 	}
+
+	t.Run("Vote for an answer option drops the write-in", func(t *testing.T) {
+		assert := assert.New(t)
+
+		p := pollWithWriteIns()
+		require.Nil(t, p.UpdateVote("userID5", 2))
+
+		assert.Equal([]string{"userID5"}, p.AnswerOptions[2].Voter)
+		assert.Equal([]*poll.AnswerOption{{Answer: "Tomorrow", Voter: []string{"userID6"}}}, p.WriteIns)
+		assert.Equal(6, p.NumberOfVoters())
+	})
+	t.Run("Last write-in is dropped", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{AllowOther: true})
+		require.Nil(t, p.UpdateWriteIn("userID1", "Maybe"))
+		require.Nil(t, p.UpdateVote("userID1", 0))
+
+		assert.Nil(t, p.WriteIns)
+	})
 }
 
-func TestHasVoted(t *testing.T) {
-	p1 := &poll.Poll{Question: "Question",
-		AnswerOptions: []*poll.AnswerOption{
-			{Answer: "Answer 1",
-				Voter: []string{"a"}},
-			{Answer: "Answer 2"},
+func TestWriteInOf(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{AllowOther: true})
+	require.Nil(t, p.UpdateWriteIn("userID1", "Maybe"))
+
+	assert.Equal(t, "Maybe", p.WriteInOf("userID1"))
+	assert.True(t, p.HasVoted("userID1"))
+	assert.Equal(t, "", p.WriteInOf("userID2"))
+}
+
+func TestResetVote(t *testing.T) {
+	t.Run("voter", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		assert.Nil(t, p.ResetVote("userID2"))
+		assert.Equal(t, []string{"userID1", "userID3"}, p.AnswerOptions[0].Voter)
+		assert.False(t, p.HasVoted("userID2"))
+		assert.Equal(t, 3, p.NumberOfVoters())
+	})
+	t.Run("approval poll", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeApproval})
+		require.Nil(t, p.UpdateVote("userID2", 0))
+		require.Nil(t, p.UpdateVote("userID2", 2))
+
+		assert.Nil(t, p.ResetVote("userID2"))
+		assert.Equal(t, 0, p.NumberOfVotes("userID2"))
+	})
+	t.Run("write-in", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{AllowOther: true})
+		require.Nil(t, p.UpdateWriteIn("userID2", "Maybe"))
+
+		assert.Nil(t, p.ResetVote("userID2"))
+		assert.Nil(t, p.WriteIns)
+	})
+	t.Run("survey", func(t *testing.T) {
+		p := testutils.GetSurveyWithVotes()
+
+		assert.Nil(t, p.ResetVote("userID2"))
+		assert.False(t, p.HasVoted("userID2"))
+	})
+	t.Run("rankings", func(t *testing.T) {
+		p := testutils.GetPollWithRankings()
+
+		assert.Nil(t, p.ResetVote("userID2"))
+		assert.NotContains(t, p.Rankings, "userID2")
+	})
+	t.Run("ratings", func(t *testing.T) {
+		p := testutils.GetPollWithRatings()
+
+		assert.Nil(t, p.ResetVote("userID3"))
+		assert.NotContains(t, p.Ratings, "userID3")
+	})
+	t.Run("estimates", func(t *testing.T) {
+		p := testutils.GetPollWithEstimates()
+
+		assert.Nil(t, p.ResetVote("userID3"))
+		assert.NotContains(t, p.Estimates, "userID3")
+	})
+	t.Run("not voted", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		assert.NotNil(t, p.ResetVote("userID5"))
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+	t.Run("invalid userID", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		assert.NotNil(t, p.ResetVote(""))
+	})
+	t.Run("vote is locked", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{LockVotes: true})
+
+		assert.NotNil(t, p.ResetVote("userID2"))
+		assert.True(t, p.HasVoted("userID2"))
+	})
+	t.Run("poll has ended", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.End()
+
+		assert.NotNil(t, p.ResetVote("userID2"))
+		assert.True(t, p.HasVoted("userID2"))
+	})
+}
+
+func TestResolveModerators(t *testing.T) {
+	lookup := func(username string) (string, error) {
+		switch username {
+		case "user1":
+			return "userID1", nil
+		case "user2":
+			return "userID2", nil
+		default:
+			return "", fmt.Errorf("unknown user %s", username)
+		}
+	}
+
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Moderators: []string{"user2"}})
+
+		require.Nil(t, p.ResolveModerators(lookup))
+		assert.Equal(t, []string{"userID2"}, p.Settings.Moderators)
+		assert.True(t, p.IsModerator("userID2"))
+		assert.False(t, p.IsModerator("userID3"))
+	})
+	t.Run("creator is left out", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Moderators: []string{"user1"}})
+
+		require.Nil(t, p.ResolveModerators(lookup))
+		assert.Nil(t, p.Settings.Moderators)
+		assert.False(t, p.IsModerator("userID1"))
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Moderators: []string{"user2", "user3"}})
+
+		assert.NotNil(t, p.ResolveModerators(lookup))
+	})
+	t.Run("no moderators", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		require.Nil(t, p.ResolveModerators(lookup))
+		assert.Nil(t, p.Settings.Moderators)
+	})
+}
+
+func TestEraseUser(t *testing.T) {
+	t.Run("voter", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, []string{"userID1", "userID3"}, p.AnswerOptions[0].Voter)
+		assert.Equal(t, "userID1", p.Creator)
+		assert.Equal(t, 3, p.NumberOfVoters())
+	})
+	t.Run("creator", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Digest: poll.RecurrenceDaily, NotifyAt: 3, EmailCreator: true})
+
+		assert.True(t, p.EraseUser("userID1"))
+		assert.Equal(t, "", p.Creator)
+		assert.Equal(t, poll.Settings{AnonymousCreator: true}, p.Settings)
+		assert.False(t, p.HasVoted("userID1"))
+	})
+	t.Run("write-in", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{AllowOther: true})
+		require.Nil(t, p.UpdateWriteIn("userID2", "Maybe"))
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Nil(t, p.WriteIns)
+	})
+	t.Run("survey", func(t *testing.T) {
+		p := testutils.GetSurveyWithVotes()
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, []string{"userID1"}, p.Questions[0].AnswerOptions[0].Voter)
+		assert.False(t, p.HasVoted("userID2"))
+	})
+	t.Run("rankings", func(t *testing.T) {
+		p := testutils.GetPollWithRankings()
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.NotContains(t, p.Rankings, "userID2")
+		assert.Len(t, p.Rankings, 3)
+	})
+	t.Run("ratings", func(t *testing.T) {
+		p := testutils.GetPollWithRatings()
+
+		assert.True(t, p.EraseUser("userID3"))
+		assert.NotContains(t, p.Ratings, "userID3")
+		assert.Len(t, p.Ratings, 2)
+	})
+	t.Run("eligible voter", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{EndWhenAllVoted: true})
+		p.EligibleVoters = []string{"userID2", "userID3"}
+
+		assert.True(t, p.EraseUser("userID2"))
+		assert.Equal(t, []string{"userID3"}, p.EligibleVoters)
+		assert.True(t, p.EraseUser("userID3"))
+		assert.Nil(t, p.EligibleVoters)
+	})
+	t.Run("moderator", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Moderators: []string{"userID5"}})
+
+		assert.True(t, p.EraseUser("userID5"))
+		assert.Nil(t, p.Settings.Moderators)
+	})
+	t.Run("not referenced", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+
+		assert.False(t, p.EraseUser("userID5"))
+		assert.Equal(t, testutils.GetPollWithVotes(), p)
+	})
+}
+
+func TestPollSettingNames(t *testing.T) {
+	for name, test := range map[string]struct {
+		Settings poll.Settings
+		Expected []string
+	}{
+		"no settings": {
+			Settings: poll.Settings{},
+			Expected: []string{},
+		},
+		"flags": {
+			Settings: poll.Settings{Anonymous: true, Progress: true, Pin: true, Compact: true},
+			Expected: []string{"anonymous", "compact", "pin", "progress"},
+		},
+		"values are left out": {
+			Settings: poll.Settings{MaxVotes: 2, Quorum: 50, EndAt: 1234567890, Moderators: []string{"userID2"}, ResultsTemplate: "{{.Winner}}"},
+			Expected: []string{"end", "moderators", "quorum", "results-template", "votes"},
 		},
+		"vote mode": {
+			Settings: poll.Settings{VoteMode: poll.VoteModeRanked, Shuffle: poll.ShuffleAlways},
+			Expected: []string{"shuffle", "votemode=ranked"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &poll.Poll{Settings: test.Settings}
+			assert.Equal(t, test.Expected, p.SettingNames())
+		})
 	}
-	assert.True(t, p1.HasVoted("a"))
-	assert.False(t, p1.HasVoted("b"))
 }
 
 func TestPollCopy(t *testing.T) {
@@ -284,4 +2337,81 @@ func TestPollCopy(t *testing.T) {
 		assert.NotEqual(p.Settings.Progress, p2.Settings.Progress)
 		assert.NotEqual(p, p2)
 	})
+	t.Run("change ImageURL", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AnswerOptions[0].ImageURL = "https://example.com/a.png"
+		p2 := p.Copy()
+
+		p.AnswerOptions[0].ImageURL = "https://example.com/b.png"
+		assert.NotEqual(p.AnswerOptions[0].ImageURL, p2.AnswerOptions[0].ImageURL)
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change EligibleVoters", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.EligibleVoters = []string{"userID1", "userID2"}
+		p2 := p.Copy()
+
+		p.EligibleVoters[0] = "userID3"
+		assert.NotEqual(p.EligibleVoters, p2.EligibleVoters)
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change BallotVoters", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Receipts: true})
+		p.BallotVoters = []string{"userID1", "userID2"}
+		p2 := p.Copy()
+
+		p.BallotVoters[0] = "userID3"
+		assert.NotEqual(p.BallotVoters, p2.BallotVoters)
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change Estimates", func(t *testing.T) {
+		p := testutils.GetPollWithEstimates()
+		p2 := p.Copy()
+
+		p.Estimates["userID1"] = 13
+		assert.NotEqual(p.Estimates, p2.Estimates)
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change WriteIns", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{AllowOther: true})
+		p.WriteIns = []*poll.AnswerOption{{Answer: "Maybe", Voter: []string{"userID1"}}}
+		p2 := p.Copy()
+
+		p.WriteIns[0].Answer = "Tomorrow"
+		assert.NotEqual(p.WriteIns[0].Answer, p2.WriteIns[0].Answer)
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change Moderators", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Moderators: []string{"userID2"}})
+		p2 := p.Copy()
+
+		p.Settings.Moderators[0] = "userID3"
+		assert.NotEqual(p.Settings.Moderators, p2.Settings.Moderators)
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change ShuffledOrder", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Shuffle: poll.ShuffleOnce})
+		p.ShuffledOrder = []int{2, 0, 1}
+		p2 := p.Copy()
+
+		p.ShuffledOrder[0] = 1
+		assert.NotEqual(p.ShuffledOrder, p2.ShuffledOrder)
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change Ratings", func(t *testing.T) {
+		p := testutils.GetPollWithRatings()
+		p2 := p.Copy()
+
+		p.Ratings["userID1"][0] = 1
+		assert.NotEqual(p.Ratings["userID1"], p2.Ratings["userID1"])
+		assert.NotEqual(p, p2)
+	})
+	t.Run("change Rankings", func(t *testing.T) {
+		p := testutils.GetPollWithRankings()
+		p2 := p.Copy()
+
+		p.Rankings["userID1"][0] = 2
+		assert.NotEqual(p.Rankings["userID1"], p2.Rankings["userID1"])
+		assert.NotEqual(p, p2)
+	})
 }
@@ -0,0 +1,86 @@
+package poll
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RatingMaxScore is the highest score a voter can give an answer option of a rating poll
+const RatingMaxScore = 5
+
+// RatingResult stores the scores that an answer option of a rating poll got
+type RatingResult struct {
+	// Distribution counts the ratings per score, starting with the lowest score.
+	Distribution [RatingMaxScore]int
+}
+
+// Count returns the number of ratings
+func (r *RatingResult) Count() int {
+	return sum(r.Distribution[:])
+}
+
+// Average returns the average score. It's zero if nobody rated the answer option.
+func (r *RatingResult) Average() float64 {
+	count := r.Count()
+	if count == 0 {
+		return 0
+	}
+	total := 0
+	for i, n := range r.Distribution {
+		total += (i + 1) * n
+	}
+	return float64(total) / float64(count)
+}
+
+// formatAverage returns the average score with a single decimal, e.g. 4.2
+func (r *RatingResult) formatAverage() string {
+	return fmt.Sprintf("%.1f", r.Average())
+}
+
+// RatingResults tallies the ratings of a rating poll. It returns the result of every answer option in their original order.
+func (p *Poll) RatingResults() []*RatingResult {
+	results := make([]*RatingResult, len(p.AnswerOptions))
+	for i := range results {
+		results[i] = &RatingResult{}
+	}
+	for _, scores := range p.Ratings {
+		for i, score := range scores {
+			if i < len(results) && score > 0 && score <= RatingMaxScore {
+				results[i].Distribution[score-1]++
+			}
+		}
+	}
+	return results
+}
+
+// sortByAverage returns the indices of the given rating results, sorted by their average score, highest first.
+// Answer options with the same average keep their original order.
+func sortByAverage(results []*RatingResult) []int {
+	indices := make([]int, len(results))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		return results[indices[a]].Average() > results[indices[b]].Average()
+	})
+	return indices
+}
+
+// bestRated returns the indices of the answer options with the highest average score. It's empty if nobody has voted.
+func bestRated(results []*RatingResult) []int {
+	best := []int{}
+	highest := 0.0
+	for i, r := range results {
+		if r.Count() == 0 {
+			continue
+		}
+		switch average := r.Average(); {
+		case average > highest:
+			best = []int{i}
+			highest = average
+		case average == highest:
+			best = append(best, i)
+		}
+	}
+	return best
+}
@@ -0,0 +1,67 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollRatingResults(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll             *poll.Poll
+		ExpectedResults  []*poll.RatingResult
+		ExpectedCounts   []int
+		ExpectedAverages []float64
+	}{
+		"No ratings": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating}),
+			ExpectedResults: []*poll.RatingResult{
+				{Distribution: [5]int{0, 0, 0, 0, 0}},
+				{Distribution: [5]int{0, 0, 0, 0, 0}},
+				{Distribution: [5]int{0, 0, 0, 0, 0}},
+			},
+			ExpectedCounts:   []int{0, 0, 0},
+			ExpectedAverages: []float64{0, 0, 0},
+		},
+		"With ratings": {
+			Poll: testutils.GetPollWithRatings(),
+			ExpectedResults: []*poll.RatingResult{
+				{Distribution: [5]int{0, 0, 1, 1, 1}},
+				{Distribution: [5]int{0, 1, 1, 0, 0}},
+				{Distribution: [5]int{1, 0, 0, 0, 0}},
+			},
+			ExpectedCounts:   []int{3, 2, 1},
+			ExpectedAverages: []float64{4, 2.5, 1},
+		},
+		"Invalid scores are ignored": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating})
+				p.Ratings = map[string][]int{
+					"userID1": {6, -1, 2, 5},
+				}
+				return p
+			}(),
+			ExpectedResults: []*poll.RatingResult{
+				{Distribution: [5]int{0, 0, 0, 0, 0}},
+				{Distribution: [5]int{0, 0, 0, 0, 0}},
+				{Distribution: [5]int{0, 1, 0, 0, 0}},
+			},
+			ExpectedCounts:   []int{0, 0, 1},
+			ExpectedAverages: []float64{0, 0, 2},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			results := test.Poll.RatingResults()
+
+			assert.Equal(test.ExpectedResults, results)
+			for i, r := range results {
+				assert.Equal(test.ExpectedCounts[i], r.Count())
+				assert.InDelta(test.ExpectedAverages[i], r.Average(), 0.001)
+			}
+		})
+	}
+}
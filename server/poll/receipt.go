@@ -0,0 +1,64 @@
+package poll
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// receiptLength is the number of random bytes of a receipt
+const receiptLength = 16
+
+// CastBallot casts the vote of a given user in a poll with receipts and returns the receipt of the vote.
+// The vote is stored as hash of the receipt instead of the user ID, so it can't be traced back to the voter.
+// Only the receipt proves that the vote got counted, and ballots can't be changed.
+func (p *Poll) CastBallot(userID string, index int) (string, error) {
+	if p.IsEnded() {
+		return "", fmt.Errorf("poll has already ended")
+	}
+	if !p.Settings.Receipts {
+		return "", fmt.Errorf("poll doesn't issue receipts")
+	}
+	if len(p.AnswerOptions) <= index || index < 0 {
+		return "", fmt.Errorf("invalid index")
+	}
+	if userID == "" {
+		return "", fmt.Errorf("invalid userID")
+	}
+	if p.HasVoted(userID) {
+		return "", fmt.Errorf("user has already voted")
+	}
+
+	b := make([]byte, receiptLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create receipt: %s", err.Error())
+	}
+	receipt := hex.EncodeToString(b)
+
+	p.BallotVoters = append(p.BallotVoters, userID)
+	p.AnswerOptions[index].Voter = append(p.AnswerOptions[index].Voter, p.hashReceipt(receipt))
+	return receipt, nil
+}
+
+// VerifyReceipt returns the index of the answer option the vote with a given receipt was cast for.
+// It returns false if no vote with the receipt got counted.
+func (p *Poll) VerifyReceipt(receipt string) (int, bool) {
+	if !p.Settings.Receipts || receipt == "" {
+		return 0, false
+	}
+	hash := p.hashReceipt(receipt)
+	for i, o := range p.AnswerOptions {
+		if containsString(o.Voter, hash) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// hashReceipt returns the hash under which the vote with a given receipt is stored.
+// The poll ID is part of the hash, so identical receipts of different polls don't match.
+func (p *Poll) hashReceipt(receipt string) string {
+	sum := sha256.Sum256([]byte(p.ID + ":" + receipt))
+	return hex.EncodeToString(sum[:])
+}
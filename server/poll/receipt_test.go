@@ -0,0 +1,132 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollCastBallot(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		assert := assert.New(t)
+		p := testutils.GetPollWithSettings(poll.Settings{Receipts: true, Anonymous: true, LockVotes: true})
+
+		receipt, err := p.CastBallot("userID1", 1)
+		require.Nil(t, err)
+		assert.Len(receipt, 32)
+		assert.Equal([]string{"userID1"}, p.BallotVoters)
+		assert.True(p.HasVoted("userID1"))
+		assert.False(p.HasVotedFor("userID1", 1))
+		assert.Len(p.AnswerOptions[1].Voter, 1)
+		assert.NotEqual(receipt, p.AnswerOptions[1].Voter[0])
+
+		receipt2, err := p.CastBallot("userID2", 1)
+		require.Nil(t, err)
+		assert.NotEqual(receipt, receipt2)
+		assert.Len(p.AnswerOptions[1].Voter, 2)
+	})
+
+	for name, test := range map[string]struct {
+		Settings poll.Settings
+		UserID   string
+		Index    int
+		Ended    bool
+	}{
+		"poll without receipts": {
+			Settings: poll.Settings{},
+			UserID:   "userID1",
+			Index:    0,
+		},
+		"invalid index": {
+			Settings: poll.Settings{Receipts: true},
+			UserID:   "userID1",
+			Index:    3,
+		},
+		"negative index": {
+			Settings: poll.Settings{Receipts: true},
+			UserID:   "userID1",
+			Index:    -1,
+		},
+		"empty user ID": {
+			Settings: poll.Settings{Receipts: true},
+			UserID:   "",
+			Index:    0,
+		},
+		"ended poll": {
+			Settings: poll.Settings{Receipts: true},
+			UserID:   "userID1",
+			Index:    0,
+			Ended:    true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			p := testutils.GetPollWithSettings(test.Settings)
+			if test.Ended {
+				p.EndedAt = 1234567890
+			}
+
+			receipt, err := p.CastBallot(test.UserID, test.Index)
+			assert.NotNil(err)
+			assert.Empty(receipt)
+			assert.Nil(p.BallotVoters)
+		})
+	}
+	t.Run("user has already voted", func(t *testing.T) {
+		assert := assert.New(t)
+		p := testutils.GetPollWithSettings(poll.Settings{Receipts: true})
+
+		_, err := p.CastBallot("userID1", 0)
+		require.Nil(t, err)
+		receipt, err := p.CastBallot("userID1", 1)
+		assert.NotNil(err)
+		assert.Empty(receipt)
+		assert.Len(p.AnswerOptions[0].Voter, 1)
+		assert.Len(p.AnswerOptions[1].Voter, 0)
+	})
+	t.Run("ballots can't be updated as votes", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Receipts: true})
+
+		assert.NotNil(t, p.UpdateVote("userID1", 0))
+	})
+}
+
+func TestPollVerifyReceipt(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{Receipts: true})
+	receipt, err := p.CastBallot("userID1", 2)
+	require.Nil(t, err)
+
+	t.Run("counted vote", func(t *testing.T) {
+		index, ok := p.VerifyReceipt(receipt)
+		assert.True(t, ok)
+		assert.Equal(t, 2, index)
+	})
+	t.Run("unknown receipt", func(t *testing.T) {
+		_, ok := p.VerifyReceipt("0123456789abcdef0123456789abcdef")
+		assert.False(t, ok)
+	})
+	t.Run("empty receipt", func(t *testing.T) {
+		_, ok := p.VerifyReceipt("")
+		assert.False(t, ok)
+	})
+	t.Run("receipt of another poll", func(t *testing.T) {
+		p2 := p.Copy()
+		p2.ID = "anotherPollID"
+
+		_, ok := p2.VerifyReceipt(receipt)
+		assert.False(t, ok)
+	})
+	t.Run("erased voter", func(t *testing.T) {
+		p2 := p.Copy()
+
+		assert.True(t, p2.EraseUser("userID1"))
+		assert.Nil(t, p2.BallotVoters)
+		assert.False(t, p2.HasVoted("userID1"))
+		index, ok := p2.VerifyReceipt(receipt)
+		assert.True(t, ok)
+		assert.Equal(t, 2, index)
+	})
+}
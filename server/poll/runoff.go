@@ -0,0 +1,66 @@
+package poll
+
+// RunoffRound stores the result of a single counting round of an instant-runoff tally
+type RunoffRound struct {
+	// Counts maps the index of every answer option still in the race to the number of ballots ranking it highest.
+	Counts map[int]int
+	// Eliminated is the index of the answer option dropped after this round, or -1 if the round was final.
+	Eliminated int
+}
+
+// InstantRunoff tallies the rankings of a ranked poll.
+// In every round the answer option with the fewest first preferences gets eliminated, until one option
+// holds the majority of the remaining ballots. Ties are resolved by eliminating the option added last.
+// It returns all counting rounds and the index of the winning option, which is -1 if nobody has voted.
+func (p *Poll) InstantRunoff() ([]*RunoffRound, int) {
+	remaining := make(map[int]bool, len(p.AnswerOptions))
+	for i := range p.AnswerOptions {
+		remaining[i] = true
+	}
+
+	rounds := []*RunoffRound{}
+	for len(remaining) > 0 {
+		round := &RunoffRound{Counts: make(map[int]int, len(remaining)), Eliminated: -1}
+		for i := range remaining {
+			round.Counts[i] = 0
+		}
+
+		total := 0
+		for _, ranking := range p.Rankings {
+			for _, index := range ranking {
+				if remaining[index] {
+					round.Counts[index]++
+					total++
+					break
+				}
+			}
+		}
+		rounds = append(rounds, round)
+
+		if total == 0 {
+			return rounds, -1
+		}
+
+		leader, loser := -1, -1
+		for i := range p.AnswerOptions {
+			if !remaining[i] {
+				continue
+			}
+			if leader == -1 || round.Counts[i] > round.Counts[leader] {
+				leader = i
+			}
+			if loser == -1 || round.Counts[i] <= round.Counts[loser] {
+				loser = i
+			}
+		}
+
+		if len(remaining) == 1 || round.Counts[leader]*2 > total {
+			return rounds, leader
+		}
+
+		round.Eliminated = loser
+		delete(remaining, loser)
+	}
+
+	return rounds, -1
+}
@@ -0,0 +1,56 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollInstantRunoff(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll           *poll.Poll
+		ExpectedRounds []*poll.RunoffRound
+		ExpectedWinner int
+	}{
+		"No rankings": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked}),
+			ExpectedRounds: []*poll.RunoffRound{
+				{Counts: map[int]int{0: 0, 1: 0, 2: 0}, Eliminated: -1},
+			},
+			ExpectedWinner: -1,
+		},
+		"Majority in first round": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked})
+				p.Rankings = map[string][]int{
+					"userID1": {1},
+					"userID2": {1, 0},
+					"userID3": {0, 1},
+				}
+				return p
+			}(),
+			ExpectedRounds: []*poll.RunoffRound{
+				{Counts: map[int]int{0: 1, 1: 2, 2: 0}, Eliminated: -1},
+			},
+			ExpectedWinner: 1,
+		},
+		"Multiple rounds": {
+			Poll: testutils.GetPollWithRankings(),
+			ExpectedRounds: []*poll.RunoffRound{
+				{Counts: map[int]int{0: 2, 1: 1, 2: 1}, Eliminated: 2},
+				{Counts: map[int]int{0: 2, 1: 2}, Eliminated: 1},
+				{Counts: map[int]int{0: 3}, Eliminated: -1},
+			},
+			ExpectedWinner: 0,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rounds, winner := test.Poll.InstantRunoff()
+
+			assert.Equal(t, test.ExpectedRounds, rounds)
+			assert.Equal(t, test.ExpectedWinner, winner)
+		})
+	}
+}
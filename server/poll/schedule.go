@@ -0,0 +1,155 @@
+package poll
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	// MaxGeneratedSlots is the maximum number of time slots that can be generated from a range of dates
+	MaxGeneratedSlots = 50
+	// DefaultSlotDuration is the length of a time slot in a calendar invite, if the poll doesn't define one
+	DefaultSlotDuration = time.Hour
+
+	// dateLayout is the layout of time slots that last a whole day
+	dateLayout = "2006-01-02"
+	// slotTimeLayout is the layout of the times of day that --times generates slots for
+	slotTimeLayout = "15:04"
+	// generatedDateLayout and generatedSlotLayout are the layouts of generated time slots. The weekday makes them easier to read.
+	generatedDateLayout = "Mon " + dateLayout
+	generatedSlotLayout = "Mon " + timeLayoutSpace
+
+	// icsTimeLayout and icsDateLayout are the layouts of times and dates in iCalendar files
+	icsTimeLayout = "20060102T150405Z"
+	icsDateLayout = "20060102"
+)
+
+// Slot is a date or time of a scheduling poll
+type Slot struct {
+	Start time.Time
+	// AllDay is true if the slot is a date without time
+	AllDay bool
+}
+
+// ParseSlot returns the slot an answer option of a scheduling poll stands for.
+// Slots are dates, e.g. 2024-06-03, or times, e.g. 2024-06-03 10:00, optionally prefixed by the weekday. Times are interpreted as UTC.
+func ParseSlot(answer string) (*Slot, error) {
+	for _, layout := range []string{TimeLayout, timeLayoutSpace, generatedSlotLayout} {
+		if t, err := time.Parse(layout, answer); err == nil {
+			return &Slot{Start: t}, nil
+		}
+	}
+	for _, layout := range []string{dateLayout, generatedDateLayout} {
+		if t, err := time.Parse(layout, answer); err == nil {
+			return &Slot{Start: t, AllDay: true}, nil
+		}
+	}
+	return nil, fmt.Errorf("Invalid time slot %s. Use a date like 2024-06-03 or a time like 2024-06-03 10:00", answer)
+}
+
+// generateSlots returns the answer options for every day of a range of dates, e.g. 2024-06-03..2024-06-07.
+// If times of day are given, e.g. 10:00,14:00, there is a slot for each of them per day.
+func generateSlots(dates, times string) ([]string, error) {
+	from, to := dates, dates
+	if i := strings.Index(dates, ".."); i != -1 {
+		from, to = dates[:i], dates[i+2:]
+	}
+	first, err := time.Parse(dateLayout, strings.TrimSpace(from))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid range of dates %s. Use a range like 2024-06-03..2024-06-07", dates)
+	}
+	last, err := time.Parse(dateLayout, strings.TrimSpace(to))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid range of dates %s. Use a range like 2024-06-03..2024-06-07", dates)
+	}
+	if last.Before(first) {
+		return nil, fmt.Errorf("Range of dates %s ends before it starts", dates)
+	}
+
+	offsets := []time.Duration{}
+	if times != "" {
+		for _, s := range strings.Split(times, ",") {
+			t, err := time.Parse(slotTimeLayout, strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("Invalid time of day %s. Use times like 10:00,14:00", s)
+			}
+			offsets = append(offsets, time.Duration(t.Hour())*time.Hour+time.Duration(t.Minute())*time.Minute)
+		}
+	}
+
+	slots := []string{}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if len(offsets) == 0 {
+			slots = append(slots, day.Format(generatedDateLayout))
+		}
+		for _, offset := range offsets {
+			slots = append(slots, day.Add(offset).Format(generatedSlotLayout))
+		}
+		if len(slots) > MaxGeneratedSlots {
+			return nil, fmt.Errorf("Range of dates %s has more than %d time slots", dates, MaxGeneratedSlots)
+		}
+	}
+	return slots, nil
+}
+
+// BestSlots returns the indices of the time slots most voters are available at. It's empty if nobody has voted.
+func (p *Poll) BestSlots() []int {
+	return leaders(p.countVotes(p.AnswerOptions))
+}
+
+// ToICS returns a calendar invite for the time slot with the given index as iCalendar file
+func (p *Poll) ToICS(index int) ([]byte, error) {
+	if index < 0 || index >= len(p.AnswerOptions) {
+		return nil, fmt.Errorf("invalid index")
+	}
+	slot, err := ParseSlot(p.AnswerOptions[index].Answer)
+	if err != nil {
+		return nil, err
+	}
+
+	start := "DTSTART:" + slot.Start.Format(icsTimeLayout)
+	end := "DTEND:" + slot.Start.Add(p.slotDuration()).Format(icsTimeLayout)
+	if slot.AllDay {
+		start = "DTSTART;VALUE=DATE:" + slot.Start.Format(icsDateLayout)
+		end = "DTEND;VALUE=DATE:" + slot.Start.AddDate(0, 0, 1).Format(icsDateLayout)
+	}
+	stamp := time.Unix(0, model.GetMillis()*int64(time.Millisecond)).UTC()
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Matterpoll//Matterpoll//EN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:%s@matterpoll", p.ID),
+		"DTSTAMP:" + stamp.Format(icsTimeLayout),
+		start,
+		end,
+		"SUMMARY:" + escapeICSText(p.Question),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	var b bytes.Buffer
+	for _, line := range lines {
+		// iCalendar files use CRLF line breaks
+		b.WriteString(line + "\r\n")
+	}
+	return b.Bytes(), nil
+}
+
+// slotDuration returns the length of the time slots of a poll
+func (p *Poll) slotDuration() time.Duration {
+	if p.Settings.SlotDuration == 0 {
+		return DefaultSlotDuration
+	}
+	return time.Duration(p.Settings.SlotDuration) * time.Minute
+}
+
+// escapeICSText escapes the characters that have a special meaning in iCalendar text values
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
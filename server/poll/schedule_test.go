@@ -0,0 +1,161 @@
+package poll_test
+
+import (
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSlot(t *testing.T) {
+	for name, test := range map[string]struct {
+		Answer       string
+		ExpectedSlot *poll.Slot
+		Error        bool
+	}{
+		"Date": {
+			Answer:       "2024-06-03",
+			ExpectedSlot: &poll.Slot{Start: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), AllDay: true},
+		},
+		"Date with weekday": {
+			Answer:       "Mon 2024-06-03",
+			ExpectedSlot: &poll.Slot{Start: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), AllDay: true},
+		},
+		"Time": {
+			Answer:       "2024-06-03 10:30",
+			ExpectedSlot: &poll.Slot{Start: time.Date(2024, 6, 3, 10, 30, 0, 0, time.UTC)},
+		},
+		"Time with T": {
+			Answer:       "2024-06-03T10:30",
+			ExpectedSlot: &poll.Slot{Start: time.Date(2024, 6, 3, 10, 30, 0, 0, time.UTC)},
+		},
+		"Time with weekday": {
+			Answer:       "Mon 2024-06-03 10:30",
+			ExpectedSlot: &poll.Slot{Start: time.Date(2024, 6, 3, 10, 30, 0, 0, time.UTC)},
+		},
+		"No time slot": {
+			Answer: "Next Monday",
+			Error:  true,
+		},
+		"Invalid date": {
+			Answer: "2024-02-30",
+			Error:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			slot, err := poll.ParseSlot(test.Answer)
+
+			if test.Error {
+				assert.NotNil(err)
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(test.ExpectedSlot, slot)
+		})
+	}
+}
+
+func TestPollBestSlots(t *testing.T) {
+	assert.Equal(t, []int{1}, testutils.GetSchedulingPoll().BestSlots())
+
+	p := testutils.GetSchedulingPoll()
+	p.AnswerOptions[0].Voter = append(p.AnswerOptions[0].Voter, "userID4")
+	assert.Equal(t, []int{0, 1}, p.BestSlots())
+
+	assert.Equal(t, []int{}, testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeScheduling}).BestSlots())
+}
+
+func TestPollToICS(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1717200000000 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Poll        *poll.Poll
+		Index       int
+		ExpectedICS string
+		Error       bool
+	}{
+		"Time slot": {
+			Poll:  testutils.GetSchedulingPoll(),
+			Index: 1,
+			ExpectedICS: "BEGIN:VCALENDAR\r\n" +
+				"VERSION:2.0\r\n" +
+				"PRODID:-//Matterpoll//Matterpoll//EN\r\n" +
+				"METHOD:PUBLISH\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"UID:" + testutils.GetPollID() + "@matterpoll\r\n" +
+				"DTSTAMP:20240601T000000Z\r\n" +
+				"DTSTART:20240604T120000Z\r\n" +
+				"DTEND:20240604T130000Z\r\n" +
+				"SUMMARY:Team lunch\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+		},
+		"Time slot with duration": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetSchedulingPoll()
+				p.Settings.SlotDuration = 90
+				p.Question = "Lunch; Dinner, or both?"
+				return p
+			}(),
+			Index: 0,
+			ExpectedICS: "BEGIN:VCALENDAR\r\n" +
+				"VERSION:2.0\r\n" +
+				"PRODID:-//Matterpoll//Matterpoll//EN\r\n" +
+				"METHOD:PUBLISH\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"UID:" + testutils.GetPollID() + "@matterpoll\r\n" +
+				"DTSTAMP:20240601T000000Z\r\n" +
+				"DTSTART:20240603T120000Z\r\n" +
+				"DTEND:20240603T133000Z\r\n" +
+				"SUMMARY:Lunch\\; Dinner\\, or both?\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+		},
+		"Date": {
+			Poll:  testutils.GetSchedulingPoll(),
+			Index: 2,
+			ExpectedICS: "BEGIN:VCALENDAR\r\n" +
+				"VERSION:2.0\r\n" +
+				"PRODID:-//Matterpoll//Matterpoll//EN\r\n" +
+				"METHOD:PUBLISH\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"UID:" + testutils.GetPollID() + "@matterpoll\r\n" +
+				"DTSTAMP:20240601T000000Z\r\n" +
+				"DTSTART;VALUE=DATE:20240605\r\n" +
+				"DTEND;VALUE=DATE:20240606\r\n" +
+				"SUMMARY:Team lunch\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+		},
+		"Invalid index": {
+			Poll:  testutils.GetSchedulingPoll(),
+			Index: 3,
+			Error: true,
+		},
+		"No time slot": {
+			Poll:  testutils.GetPoll(),
+			Index: 0,
+			Error: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := test.Poll.ToICS(test.Index)
+
+			if test.Error {
+				assert.NotNil(t, err)
+				assert.Nil(t, data)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, test.ExpectedICS, string(data))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package poll
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// leaderMark is put in front of the answer options with the most votes of polls that sort their results
+const leaderMark = "🏆"
+
+var pollEndPostLeader = &i18n.Message{
+	ID:    "poll.endPost.leader",
+	Other: "🏆 **{{.Answers}}**",
+}
+
+// resultOrder returns given indices of answer options in the order their results are shown, together with the answer options that lead.
+// Polls that sort their results list the answer options by their number of votes and mark the ones with the most votes,
+// while all others keep the given order and mark none.
+func (p *Poll) resultOrder(answerOptions []*AnswerOption, order []int) ([]int, map[int]bool) {
+	leading := map[int]bool{}
+	if !p.Settings.SortResults {
+		return order, leading
+	}
+
+	counts := p.countVotes(answerOptions)
+	for _, i := range leaders(counts) {
+		leading[i] = true
+	}
+	sorted := append([]int{}, order...)
+	sort.SliceStable(sorted, func(i, j int) bool { return counts[sorted[i]] > counts[sorted[j]] })
+	return sorted, leading
+}
+
+// optionIndices returns the indices of given answer options in their order
+func optionIndices(answerOptions []*AnswerOption) []int {
+	order := make([]int, len(answerOptions))
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// markLeader puts the leader mark in front of the text of an answer option with the most votes
+func markLeader(text string) string {
+	return leaderMark + " " + text
+}
+
+// makeLeaderText returns the answer options with the most votes in bold. It's empty if the poll doesn't sort its results or nobody has voted.
+func (p *Poll) makeLeaderText(localizer *i18n.Localizer, answerOptions []*AnswerOption) string {
+	if !p.Settings.SortResults {
+		return ""
+	}
+	answers := []string{}
+	for _, i := range leaders(p.countVotes(answerOptions)) {
+		answers = append(answers, stripMarkdown(answerOptions[i].Answer))
+	}
+	if len(answers) == 0 {
+		return ""
+	}
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollEndPostLeader,
+		TemplateData:   map[string]interface{}{"Answers": strings.Join(answers, ", ")},
+	})
+}
@@ -0,0 +1,111 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getPollWithLateLeader returns a poll whose last answer option got the most votes
+func getPollWithLateLeader(settings poll.Settings) *poll.Poll {
+	p := testutils.GetPollWithSettings(settings)
+	p.AnswerOptions[0].Voter = []string{"userID1"}
+	p.AnswerOptions[2].Voter = []string{"userID2", "userID3"}
+	return p
+}
+
+func TestPollToPostActionsSortResults(t *testing.T) {
+	actionNames := func(p *poll.Poll) []string {
+		names := []string{}
+		for _, action := range p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")[0].Actions[:len(p.AnswerOptions)] {
+			names = append(names, action.Name)
+		}
+		return names
+	}
+
+	for name, test := range map[string]struct {
+		Poll          *poll.Poll
+		ExpectedNames []string
+	}{
+		"progress": {
+			Poll:          getPollWithLateLeader(poll.Settings{SortResults: true, Progress: true}),
+			ExpectedNames: []string{"🏆 Answer 3 (2)", "Answer 1 (1)", "Answer 2 (0)"},
+		},
+		"progress, tie": {
+			Poll: func() *poll.Poll {
+				p := getPollWithLateLeader(poll.Settings{SortResults: true, Progress: true})
+				p.AnswerOptions[1].Voter = []string{"userID4", "userID5"}
+				return p
+			}(),
+			ExpectedNames: []string{"🏆 Answer 2 (2)", "🏆 Answer 3 (2)", "Answer 1 (1)"},
+		},
+		"progress, no votes": {
+			Poll:          testutils.GetPollWithSettings(poll.Settings{SortResults: true, Progress: true}),
+			ExpectedNames: []string{"Answer 1 (0)", "Answer 2 (0)", "Answer 3 (0)"},
+		},
+		"progress, without sort results": {
+			Poll:          getPollWithLateLeader(poll.Settings{Progress: true}),
+			ExpectedNames: []string{"Answer 1 (1)", "Answer 2 (0)", "Answer 3 (2)"},
+		},
+		"without progress": {
+			Poll:          getPollWithLateLeader(poll.Settings{SortResults: true}),
+			ExpectedNames: []string{"Answer 1", "Answer 2", "Answer 3"},
+		},
+		"secret": {
+			Poll:          getPollWithLateLeader(poll.Settings{SortResults: true, Progress: true, Secret: true}),
+			ExpectedNames: []string{"Answer 1", "Answer 2", "Answer 3"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedNames, actionNames(test.Poll))
+		})
+	}
+}
+
+func TestPollToEndPollPostSortResults(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	t.Run("sorted", func(t *testing.T) {
+		p := getPollWithLateLeader(poll.Settings{SortResults: true})
+
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe", converter)
+
+		require.Nil(t, err)
+		attachments := post.Attachments()
+		assert.Equal(t, "This poll has ended. The results are:\n🏆 **Answer 3**", attachments[0].Text)
+		assert.Equal(t, []*model.SlackAttachmentField{
+			{Short: true, Title: "🏆 Answer 3 (2 votes)", Value: "@userID2 and @userID3"},
+			{Short: true, Title: "Answer 1 (1 vote)", Value: "@userID1"},
+			{Short: true, Title: "Answer 2 (0 votes)", Value: ""},
+		}, attachments[0].Fields)
+	})
+
+	t.Run("no votes", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{SortResults: true})
+
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe", converter)
+
+		require.Nil(t, err)
+		attachments := post.Attachments()
+		assert.Equal(t, "This poll has ended. The results are:", attachments[0].Text)
+		assert.Equal(t, "Answer 1 (0 votes)", attachments[0].Fields[0].Title)
+	})
+
+	t.Run("without sort results", func(t *testing.T) {
+		p := getPollWithLateLeader(poll.Settings{})
+
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe", converter)
+
+		require.Nil(t, err)
+		attachments := post.Attachments()
+		assert.Equal(t, "This poll has ended. The results are:", attachments[0].Text)
+		assert.Equal(t, "Answer 1 (1 vote)", attachments[0].Fields[0].Title)
+		assert.Equal(t, "Answer 3 (2 votes)", attachments[0].Fields[2].Title)
+	})
+}
@@ -0,0 +1,165 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SurveyQuestionSeparator separates a survey question from its answer options, e.g. "Question|Answer 1|Answer 2"
+const SurveyQuestionSeparator = "|"
+
+// Question is a single question of a survey
+type Question struct {
+	Question      string
+	AnswerOptions []*AnswerOption
+}
+
+// NewSurvey creates a new survey with a given title.
+// Every question consists of the question text, optionally followed by its answer options separated by SurveyQuestionSeparator.
+// Questions without answer options get the given default answer options.
+func NewSurvey(creator, title string, questions, defaultAnswerOptions, settings []string) (*Poll, error) {
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("a survey needs at least one question")
+	}
+
+	p, err := NewPoll(creator, title, nil, settings)
+	if err != nil {
+		return nil, err
+	}
+	// Every answer option of a survey has a single voter per question, which rules out some Poll Settings
+	switch {
+	case p.Settings.VoteMode != VoteModeSingle:
+		return nil, fmt.Errorf("votemode=%s is not supported in surveys", p.Settings.VoteMode)
+	case p.Settings.PublicAddOption:
+		return nil, fmt.Errorf("public-add-option is not supported in surveys")
+	case p.Settings.MaxPerOption > 0:
+		return nil, fmt.Errorf("max-per-option is not supported in surveys")
+	case p.Settings.Receipts:
+		return nil, fmt.Errorf("receipts is not supported in surveys")
+	case p.Settings.LockVotes:
+		return nil, fmt.Errorf("lock-votes is not supported in surveys")
+	case p.Settings.PublicVotes:
+		return nil, fmt.Errorf("public-votes is not supported in surveys")
+	case p.Settings.AllowOther:
+		return nil, fmt.Errorf("allow-other is not supported in surveys")
+	case p.IsMultiVote():
+		return nil, fmt.Errorf("votes=%d is not supported in surveys", p.Settings.MaxVotes)
+	case p.Settings.Shuffle != ShuffleNone:
+		return nil, fmt.Errorf("shuffle is not supported in surveys")
+	case p.IsWeighted():
+		return nil, fmt.Errorf("weights is not supported in surveys")
+	case p.Settings.Compact:
+		return nil, fmt.Errorf("compact is not supported in surveys")
+	}
+
+	for _, q := range questions {
+		parts := strings.Split(q, SurveyQuestionSeparator)
+		question := &Question{Question: strings.TrimSpace(parts[0])}
+		if question.Question == "" {
+			return nil, fmt.Errorf("empty survey question")
+		}
+
+		answerOptions := parts[1:]
+		if len(answerOptions) == 0 {
+			answerOptions = defaultAnswerOptions
+		}
+		if len(answerOptions) == 1 {
+			return nil, fmt.Errorf("question %s needs at least two answer options", question.Question)
+		}
+		for _, a := range answerOptions {
+			if err := question.addAnswerOption(strings.TrimSpace(a)); err != nil {
+				return nil, err
+			}
+		}
+		p.Questions = append(p.Questions, question)
+	}
+	return p, nil
+}
+
+// addAnswerOption adds a new AnswerOption to a survey question
+func (q *Question) addAnswerOption(newAnswerOption string) error {
+	if newAnswerOption == "" {
+		return fmt.Errorf("empty answer option in question %s", q.Question)
+	}
+	for _, o := range q.AnswerOptions {
+		if o.Answer == newAnswerOption {
+			return fmt.Errorf("duplicate answer option %s in question %s", newAnswerOption, q.Question)
+		}
+	}
+	q.AnswerOptions = append(q.AnswerOptions, &AnswerOption{Answer: newAnswerOption})
+	return nil
+}
+
+// IsSurvey returns true if the poll consists of several questions
+func (p *Poll) IsSurvey() bool {
+	return len(p.Questions) > 0
+}
+
+// UpdateSurveyVote performs the vote of a given user for a single question of a survey
+func (p *Poll) UpdateSurveyVote(userID string, questionIndex, optionIndex int) error {
+	if p.IsEnded() {
+		return fmt.Errorf("poll has already ended")
+	}
+	if !p.IsSurvey() {
+		return fmt.Errorf("poll is not a survey")
+	}
+	if len(p.Questions) <= questionIndex || questionIndex < 0 {
+		return fmt.Errorf("invalid question index")
+	}
+	question := p.Questions[questionIndex]
+	if len(question.AnswerOptions) <= optionIndex || optionIndex < 0 {
+		return fmt.Errorf("invalid index")
+	}
+	if userID == "" {
+		return fmt.Errorf("invalid userID")
+	}
+
+	hasAnswered := p.HasAnswered(userID, questionIndex)
+	for _, o := range question.AnswerOptions {
+		for i := 0; i < len(o.Voter); i++ {
+			if userID == o.Voter[i] {
+				o.Voter = append(o.Voter[:i], o.Voter[i+1:]...)
+			}
+		}
+	}
+	question.AnswerOptions[optionIndex].Voter = append(question.AnswerOptions[optionIndex].Voter, userID)
+	p.recordVote(userID, hasAnswered)
+	return nil
+}
+
+// HasAnswered returns true if a given user has voted for one of the answer options of a survey question
+func (p *Poll) HasAnswered(userID string, questionIndex int) bool {
+	if len(p.Questions) <= questionIndex || questionIndex < 0 {
+		return false
+	}
+	for _, o := range p.Questions[questionIndex].AnswerOptions {
+		for _, voter := range o.Voter {
+			if userID == voter {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allAnswerOptions returns the answer options of the poll together with its write-ins and the answer options of all survey questions
+func (p *Poll) allAnswerOptions() []*AnswerOption {
+	options := append([]*AnswerOption{}, p.AnswerOptions...)
+	options = append(options, p.WriteIns...)
+	for _, q := range p.Questions {
+		options = append(options, q.AnswerOptions...)
+	}
+	return options
+}
+
+// copyAnswerOptions returns a copy of the given answer options. The votes are only kept if keepVotes is true.
+func copyAnswerOptions(answerOptions []*AnswerOption, keepVotes bool) []*AnswerOption {
+	options := make([]*AnswerOption, len(answerOptions))
+	for i, o := range answerOptions {
+		options[i] = &AnswerOption{Answer: o.Answer, ImageURL: o.ImageURL, Description: o.Description}
+		if keepVotes {
+			options[i].Voter = o.Voter
+		}
+	}
+	return options
+}
@@ -0,0 +1,256 @@
+package poll_test
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSurvey(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		assert := assert.New(t)
+		patch1 := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+		patch2 := monkey.Patch(model.NewId, func() string { return testutils.GetPollID() })
+		defer patch1.Unpatch()
+		defer patch2.Unpatch()
+
+		p, err := poll.NewSurvey("userID1", "Survey", []string{"Question 1", "Question 2| Answer 1 |Answer 2|Answer 3"}, []string{"Yes", "No"}, []string{"anonymous", "progress"})
+
+		require.Nil(t, err)
+		require.NotNil(t, p)
+		assert.Equal(testutils.GetPollID(), p.ID)
+		assert.Equal(int64(1234567890), p.CreatedAt)
+		assert.Equal("userID1", p.Creator)
+		assert.Equal("Survey", p.Question)
+		assert.Empty(p.AnswerOptions)
+		assert.Equal([]*poll.Question{{
+			Question:      "Question 1",
+			AnswerOptions: []*poll.AnswerOption{{Answer: "Yes"}, {Answer: "No"}},
+		}, {
+			Question:      "Question 2",
+			AnswerOptions: []*poll.AnswerOption{{Answer: "Answer 1"}, {Answer: "Answer 2"}, {Answer: "Answer 3"}},
+		}}, p.Questions)
+		assert.Equal(poll.Settings{Anonymous: true, Progress: true}, p.Settings)
+		assert.True(p.IsSurvey())
+	})
+
+	for name, test := range map[string]struct {
+		Questions []string
+		Settings  []string
+	}{
+		"No questions": {
+			Questions: []string{},
+		},
+		"Empty question": {
+			Questions: []string{" |Answer 1|Answer 2"},
+		},
+		"Single answer option": {
+			Questions: []string{"Question|Answer 1"},
+		},
+		"Empty answer option": {
+			Questions: []string{"Question|Answer 1||Answer 2"},
+		},
+		"Duplicate answer option": {
+			Questions: []string{"Question|Answer 1|Answer 1"},
+		},
+		"Invalid setting": {
+			Questions: []string{"Question"},
+			Settings:  []string{"unknown"},
+		},
+		"Ranked vote mode": {
+			Questions: []string{"Question"},
+			Settings:  []string{"votemode=ranked"},
+		},
+		"Approval vote mode": {
+			Questions: []string{"Question"},
+			Settings:  []string{"votemode=approval"},
+		},
+		"Public add option": {
+			Questions: []string{"Question"},
+			Settings:  []string{"public-add-option"},
+		},
+		"Lock votes": {
+			Questions: []string{"Question"},
+			Settings:  []string{"lock-votes"},
+		},
+		"Public votes": {
+			Questions: []string{"Question"},
+			Settings:  []string{"public-votes"},
+		},
+		"Max per option": {
+			Questions: []string{"Question"},
+			Settings:  []string{"max-per-option=5"},
+		},
+		"Receipts": {
+			Questions: []string{"Question"},
+			Settings:  []string{"receipts"},
+		},
+		"Multiple votes": {
+			Questions: []string{"Question"},
+			Settings:  []string{"votes=2"},
+		},
+		"Allow other": {
+			Questions: []string{"Question"},
+			Settings:  []string{"allow-other"},
+		},
+		"Shuffle": {
+			Questions: []string{"Question"},
+			Settings:  []string{"shuffle"},
+		},
+		"Compact": {
+			Questions: []string{"Question"},
+			Settings:  []string{"compact"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, err := poll.NewSurvey("userID1", "Survey", test.Questions, []string{"Yes", "No"}, test.Settings)
+
+			assert.NotNil(t, err)
+			assert.Nil(t, p)
+		})
+	}
+}
+
+func TestUpdateSurveyVote(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Poll          poll.Poll
+		UserID        string
+		QuestionIndex int
+		OptionIndex   int
+		ExpectedPoll  poll.Poll
+		Error         bool
+	}{
+		"First answer": {
+			Poll:          *testutils.GetSurveyWithVotes(),
+			UserID:        "userID4",
+			QuestionIndex: 1,
+			OptionIndex:   0,
+			ExpectedPoll: func() poll.Poll {
+				p := testutils.GetSurveyWithVotes()
+				p.Questions[1].AnswerOptions[0].Voter = []string{"userID4"}
+				p.VotedAt = map[string]int64{"userID4": 1234567890}
+				return *p
+			}(),
+		},
+		"Change answer": {
+			Poll:          *testutils.GetSurveyWithVotes(),
+			UserID:        "userID1",
+			QuestionIndex: 0,
+			OptionIndex:   1,
+			ExpectedPoll: func() poll.Poll {
+				p := testutils.GetSurveyWithVotes()
+				p.Questions[0].AnswerOptions[0].Voter = []string{"userID2"}
+				p.Questions[0].AnswerOptions[1].Voter = []string{"userID3", "userID1"}
+				p.VoteChanges = 1
+				return *p
+			}(),
+		},
+		"Invalid question index": {
+			Poll:          *testutils.GetSurveyWithVotes(),
+			UserID:        "userID1",
+			QuestionIndex: 2,
+			OptionIndex:   0,
+			ExpectedPoll:  *testutils.GetSurveyWithVotes(),
+			Error:         true,
+		},
+		"Invalid option index": {
+			Poll:          *testutils.GetSurveyWithVotes(),
+			UserID:        "userID1",
+			QuestionIndex: 0,
+			OptionIndex:   2,
+			ExpectedPoll:  *testutils.GetSurveyWithVotes(),
+			Error:         true,
+		},
+		"Empty userID": {
+			Poll:          *testutils.GetSurveyWithVotes(),
+			UserID:        "",
+			QuestionIndex: 0,
+			OptionIndex:   0,
+			ExpectedPoll:  *testutils.GetSurveyWithVotes(),
+			Error:         true,
+		},
+		"Not a survey": {
+			Poll:          *testutils.GetPoll(),
+			UserID:        "userID1",
+			QuestionIndex: 0,
+			OptionIndex:   0,
+			ExpectedPoll:  *testutils.GetPoll(),
+			Error:         true,
+		},
+		"Ended survey": {
+			Poll: func() poll.Poll {
+				p := testutils.GetSurveyWithVotes()
+				p.EndedAt = 1234567890
+				return *p
+			}(),
+			UserID:        "userID4",
+			QuestionIndex: 0,
+			OptionIndex:   0,
+			ExpectedPoll: func() poll.Poll {
+				p := testutils.GetSurveyWithVotes()
+				p.EndedAt = 1234567890
+				return *p
+			}(),
+			Error: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := test.Poll.UpdateSurveyVote(test.UserID, test.QuestionIndex, test.OptionIndex)
+
+			if test.Error {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.ExpectedPoll, test.Poll)
+		})
+	}
+}
+
+func TestHasAnswered(t *testing.T) {
+	p := testutils.GetSurveyWithVotes()
+
+	assert.True(t, p.HasAnswered("userID1", 0))
+	assert.True(t, p.HasAnswered("userID1", 1))
+	assert.True(t, p.HasAnswered("userID3", 0))
+	assert.False(t, p.HasAnswered("userID3", 1))
+	assert.False(t, p.HasAnswered("userID1", 2))
+}
+
+func TestSurveyVoters(t *testing.T) {
+	p := testutils.GetSurveyWithVotes()
+
+	assert.True(t, p.HasVoted("userID2"))
+	assert.False(t, p.HasVoted("userID4"))
+	assert.Equal(t, 3, p.NumberOfVoters())
+}
+
+func TestSurveyCopyAndNextInstance(t *testing.T) {
+	t.Run("copy", func(t *testing.T) {
+		p := testutils.GetSurveyWithVotes()
+		p2 := p.Copy()
+		assert.Equal(t, p, p2)
+
+		p.Questions[0].AnswerOptions[0].Answer = "abc"
+		assert.NotEqual(t, p, p2)
+	})
+	t.Run("next instance", func(t *testing.T) {
+		p := testutils.GetSurveyWithVotes()
+		p.Settings.Repeat = poll.RecurrenceDaily
+
+		next := p.NextInstance(1234567890)
+
+		require.Len(t, next.Questions, 2)
+		assert.Equal(t, "Question 1", next.Questions[0].Question)
+		assert.Equal(t, []*poll.AnswerOption{{Answer: "Yes"}, {Answer: "No"}}, next.Questions[0].AnswerOptions)
+		assert.Equal(t, 0, next.NumberOfVoters())
+	})
+}
@@ -0,0 +1,59 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxTags is the number of tags a poll may have
+	MaxTags = 5
+	// MaxTagLength is the number of characters a tag may have. Tags are part of the keys of the tag index, which are limited in length.
+	MaxTagLength = 30
+)
+
+// NormalizeTag returns a tag the way it's stored, in lower case and without a leading #
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// isValidTag checks if a normalized tag consists only of letters, digits, - and _
+func isValidTag(tag string) bool {
+	if tag == "" || len(tag) > MaxTagLength {
+		return false
+	}
+	for _, r := range tag {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTags returns the normalized tags of a comma separated list of tags like retro,#team-alpha.
+// Duplicates are left out.
+func parseTags(value string) ([]string, error) {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = NormalizeTag(tag)
+		if tag == "" || containsString(tags, tag) {
+			continue
+		}
+		if !isValidTag(tag) {
+			return nil, fmt.Errorf("Invalid tag %s. Tags may only contain letters, digits, - and _ and have at most %d characters", tag, MaxTagLength)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("Invalid tags %s", value)
+	}
+	if len(tags) > MaxTags {
+		return nil, fmt.Errorf("A poll can have at most %d tags", MaxTags)
+	}
+	return tags, nil
+}
+
+// HasTag returns true if the poll is tagged with a given tag. The tag is compared the way it's stored, see NormalizeTag.
+func (p *Poll) HasTag(tag string) bool {
+	return containsString(p.Settings.Tags, NormalizeTag(tag))
+}
@@ -0,0 +1,38 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	assert.Equal(t, "retro", poll.NormalizeTag("retro"))
+	assert.Equal(t, "team-alpha", poll.NormalizeTag(" #Team-Alpha "))
+	assert.Equal(t, "", poll.NormalizeTag("#"))
+}
+
+func TestPollHasTag(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro", "team-alpha"}})
+	assert.True(t, p.HasTag("retro"))
+	assert.True(t, p.HasTag("#Team-Alpha"))
+	assert.False(t, p.HasTag("team"))
+	assert.False(t, testutils.GetPoll().HasTag("retro"))
+}
+
+func TestPollToPostActionsTags(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{Progress: true, Tags: []string{"retro", "team-alpha"}})
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Contains(t, attachments[0].Text, "**Poll Settings**: progress, tags=retro,team-alpha")
+}
+
+func TestPollCopyTags(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro"}})
+
+	p2 := p.Copy()
+	p2.Settings.Tags[0] = "team-alpha"
+	assert.Equal(t, []string{"retro"}, p.Settings.Tags)
+}
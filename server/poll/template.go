@@ -0,0 +1,89 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// ResultsTemplateData is the data a results template can refer to, e.g. {{.Question}} or {{.Winner}}
+type ResultsTemplateData struct {
+	Question string
+	// Winner is the answer option that won. Tied answer options are separated by commas.
+	// It's empty if nobody voted and for surveys, estimate polls and NPS polls.
+	Winner string
+	// TotalVotes is the number of votes cast. Approval and scheduling polls count every approved answer option.
+	TotalVotes int
+	// Voters is the number of users who voted
+	Voters int
+	// Results is the default summary of the results
+	Results string
+	// Link is the permalink to the poll post. It's empty in direct and group messages.
+	Link string
+}
+
+// ParseResultsTemplate parses a template for the message that announces the results of a poll.
+// Templates that refer to unknown data are invalid.
+func ParseResultsTemplate(text string) (*template.Template, error) {
+	t, err := template.New("results").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid results template: %s", err.Error())
+	}
+	if err = t.Execute(&strings.Builder{}, &ResultsTemplateData{}); err != nil {
+		return nil, fmt.Errorf("Invalid results template: %s", err.Error())
+	}
+	return t, nil
+}
+
+// ToResultsMessage returns the message that announces the results of the poll according to a given template.
+// link is the permalink to the poll post.
+func (p *Poll) ToResultsMessage(localizer *i18n.Localizer, text, link string) (string, error) {
+	t, err := ParseResultsTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err = t.Execute(&b, p.makeResultsTemplateData(localizer, link)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// makeResultsTemplateData returns the data of the poll a results template can refer to
+func (p *Poll) makeResultsTemplateData(localizer *i18n.Localizer, link string) *ResultsTemplateData {
+	data := &ResultsTemplateData{
+		Question: p.Question,
+		Voters:   p.NumberOfVoters(),
+		Results:  p.ToResultsSummary(localizer),
+		Link:     link,
+	}
+	if p.IsSurvey() || p.Settings.VoteMode == VoteModeEstimate {
+		data.TotalVotes = data.Voters
+		return data
+	}
+	if p.IsNPS() {
+		data.TotalVotes = p.NPSResults().Total()
+		return data
+	}
+
+	answerOptions := p.resultOptions()
+	var winners []int
+	if p.Settings.VoteMode == VoteModeRating {
+		winners = bestRated(p.RatingResults())
+		data.TotalVotes = data.Voters
+	} else {
+		var counts []int
+		counts, _, winners = p.countResults()
+		data.TotalVotes = sum(counts)
+	}
+
+	answers := []string{}
+	for _, i := range winners {
+		answers = append(answers, answerOptions[i].Answer)
+	}
+	data.Winner = strings.Join(answers, ", ")
+	return data
+}
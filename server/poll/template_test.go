@@ -0,0 +1,94 @@
+package poll_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResultsTemplate(t *testing.T) {
+	for name, test := range map[string]struct {
+		Text          string
+		ExpectedError bool
+	}{
+		"all fine":                {Text: "{{.Question}}: {{.Winner}} won with {{.TotalVotes}} votes of {{.Voters}} voters. {{.Link}}\n{{.Results}}"},
+		"no placeholders":         {Text: "The poll has ended"},
+		"invalid syntax":          {Text: "{{.Winner", ExpectedError: true},
+		"unknown data":            {Text: "{{.Loser}}", ExpectedError: true},
+		"calls unknown functions": {Text: "{{upper .Winner}}", ExpectedError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := poll.ParseResultsTemplate(test.Text)
+			if test.ExpectedError {
+				assert.NotNil(t, err)
+				assert.Nil(t, tmpl)
+			} else {
+				assert.Nil(t, err)
+				assert.NotNil(t, tmpl)
+			}
+		})
+	}
+}
+
+func TestPollToResultsMessage(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		Text            string
+		Link            string
+		ExpectedMessage string
+		ExpectedError   bool
+	}{
+		"Normal poll": {
+			Poll:            testutils.GetPollWithVotes(),
+			Text:            "{{.Question}}: {{.Winner}} won with {{.TotalVotes}} votes of {{.Voters}} voters",
+			ExpectedMessage: "Question: Answer 1 won with 4 votes of 4 voters",
+		},
+		"Tie": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.AnswerOptions[1].Voter = []string{"userID4", "userID5", "userID6"}
+				return p
+			}(),
+			Text:            "{{.Winner}}",
+			ExpectedMessage: "Answer 1, Answer 2",
+		},
+		"No votes": {
+			Poll:            testutils.GetPoll(),
+			Text:            "{{if .Winner}}{{.Winner}} won{{else}}Nobody voted{{end}}",
+			ExpectedMessage: "Nobody voted",
+		},
+		"Rating poll": {
+			Poll:            testutils.GetPollWithRatings(),
+			Text:            "{{.Winner}} is rated best by {{.TotalVotes}} voters",
+			ExpectedMessage: "Answer 1 is rated best by 3 voters",
+		},
+		"Survey": {
+			Poll:            testutils.GetSurveyWithVotes(),
+			Text:            "{{.Question}} has {{.TotalVotes}} responses{{.Winner}}",
+			ExpectedMessage: "Survey has 3 responses",
+		},
+		"With link and results": {
+			Poll:            testutils.GetPollWithVotes(),
+			Text:            "[{{.Question}}]({{.Link}})\n{{.Results}}",
+			Link:            "https://example.org/team1/pl/postID1",
+			ExpectedMessage: "[Question](https://example.org/team1/pl/postID1)\n" + testutils.GetPollWithVotes().ToResultsSummary(testutils.GetLocalizer()),
+		},
+		"Invalid template": {
+			Poll:          testutils.GetPollWithVotes(),
+			Text:          "{{.Loser}}",
+			ExpectedError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			msg, err := test.Poll.ToResultsMessage(testutils.GetLocalizer(), test.Text, test.Link)
+			if test.ExpectedError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.ExpectedMessage, msg)
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package poll
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/chart"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -21,6 +28,78 @@ var (
 		ID:    "poll.button.endPoll",
 		Other: "End Poll",
 	}
+	pollButtonPausePoll = &i18n.Message{
+		ID:    "poll.button.pausePoll",
+		Other: "Pause Voting",
+	}
+	pollButtonResumePoll = &i18n.Message{
+		ID:    "poll.button.resumePoll",
+		Other: "Resume Voting",
+	}
+	pollButtonTransferPoll = &i18n.Message{
+		ID:    "poll.button.transferPoll",
+		Other: "Transfer Poll",
+	}
+	pollButtonExport = &i18n.Message{
+		ID:    "poll.button.export",
+		Other: "Export Results",
+	}
+	pollButtonReopen = &i18n.Message{
+		ID:    "poll.button.reopen",
+		Other: "Reopen Poll",
+	}
+	pollButtonResetVote = &i18n.Message{
+		ID:    "poll.button.resetVote",
+		Other: "Reset My Vote",
+	}
+	pollButtonShowResults = &i18n.Message{
+		ID:    "poll.button.showResults",
+		Other: "Show Results",
+	}
+	pollButtonShowNonVoters = &i18n.Message{
+		ID:    "poll.button.showNonVoters",
+		Other: "Show Non-Voters",
+	}
+	pollButtonRemindNonVoters = &i18n.Message{
+		ID:    "poll.button.remindNonVoters",
+		Other: "Remind Non-Voters",
+	}
+	pollButtonRankOptions = &i18n.Message{
+		ID:    "poll.button.rankOptions",
+		Other: "Rank Options",
+	}
+	pollButtonRateOptions = &i18n.Message{
+		ID:    "poll.button.rateOptions",
+		Other: "Rate Options",
+	}
+	pollButtonSubmitEstimate = &i18n.Message{
+		ID:    "poll.button.submitEstimate",
+		Other: "Submit Estimate",
+	}
+	pollButtonOptionFull = &i18n.Message{
+		ID:    "poll.button.optionFull",
+		Other: "{{.Answer}} (full)",
+	}
+	pollSelectAnswerOption = &i18n.Message{
+		ID:    "poll.select.answerOption",
+		Other: "Choose an answer option",
+	}
+	pollButtonOther = &i18n.Message{
+		ID:    "poll.button.other",
+		Other: "Other…",
+	}
+	pollButtonShowAllVoters = &i18n.Message{
+		ID:    "poll.button.showAllVoters",
+		Other: "Show All Voters",
+	}
+	pollButtonPreviousPage = &i18n.Message{
+		ID:    "poll.button.previousPage",
+		Other: "◀ Previous",
+	}
+	pollButtonNextPage = &i18n.Message{
+		ID:    "poll.button.nextPage",
+		Other: "Next ▶",
+	}
 
 	pollMessageSettings = &i18n.Message{
 		ID:    "poll.message.pollSettings",
@@ -30,74 +109,692 @@ var (
 		ID:    "poll.message.totalVotes",
 		Other: "**Total votes**: {{.TotalVotes}}",
 	}
+	pollMessageTotalWeightedVotes = &i18n.Message{
+		ID:    "poll.message.totalWeightedVotes",
+		One:   "**Total votes**: {{.TotalVotes}} weighted, cast by {{.Voters}} voter",
+		Other: "**Total votes**: {{.TotalVotes}} weighted, cast by {{.Voters}} voters",
+	}
+	pollMessageDelegatedVotes = &i18n.Message{
+		ID:    "poll.message.delegatedVotes",
+		One:   "**Delegated votes**: {{.Count}} voter delegated their vote",
+		Other: "**Delegated votes**: {{.Count}} voters delegated their vote",
+	}
+	pollMessageEstimates = &i18n.Message{
+		ID:    "poll.message.estimates",
+		One:   "{{.Count}} estimate, mean {{.Mean}}, median {{.Median}}",
+		Other: "{{.Count}} estimates, mean {{.Mean}}, median {{.Median}}",
+	}
+	pollMessageNPS = &i18n.Message{
+		ID:    "poll.message.nps",
+		Other: "**Net Promoter Score**: {{.Score}}",
+	}
+	pollMessageResultsHidden = &i18n.Message{
+		ID:    "poll.message.resultsHidden",
+		Other: "The results are hidden until the poll ends.",
+	}
+	pollMessageResultsVoteToSee = &i18n.Message{
+		ID:    "poll.message.resultsVoteToSee",
+		Other: "The results are shown to you once you voted.",
+	}
+	pollMessageEndsIn = &i18n.Message{
+		ID:    "poll.message.endsIn",
+		Other: "Ends in {{.Countdown}}",
+	}
+	pollMessageVotingClosed = &i18n.Message{
+		ID:    "poll.message.votingClosed",
+		Other: "Voting closed",
+	}
+	pollMessageVotingPaused = &i18n.Message{
+		ID:    "poll.message.votingPaused",
+		Other: "Voting paused",
+	}
+	pollMessagePage = &i18n.Message{
+		ID:    "poll.message.page",
+		Other: "Answer options {{.First}} to {{.Last}} of {{.Total}} (page {{.Page}} of {{.Pages}})",
+	}
 
 	pollEndPostText = &i18n.Message{
 		ID:    "poll.endPost.text",
 		Other: "This poll has ended. The results are:",
 	}
+	pollEndPostQuorumReached = &i18n.Message{
+		ID:    "poll.endPost.quorumReached",
+		Other: "**Quorum reached**: {{.Voters}} of {{.Members}} channel members voted.",
+	}
+	pollEndPostQuorumNotReached = &i18n.Message{
+		ID:    "poll.endPost.quorumNotReached",
+		Other: "**Invalid — quorum not reached**: {{.Voters}} of {{.Members}} channel members voted, but {{.Quorum}}% were required.",
+	}
 	pollEndPostSeperator = &i18n.Message{
 		ID:    "poll.endPost.seperator",
 		Other: "and",
 	}
+	pollMessageMoreVoters = &i18n.Message{
+		ID:    "poll.message.moreVoters",
+		Other: "{{.Count}} more",
+	}
 	pollEndPostAnswerHeading = &i18n.Message{
 		ID:    "poll.endPost.answer.heading",
 		One:   "{{.Answer}} ({{.Count}} vote)",
 		Other: "{{.Answer}} ({{.Count}} votes)",
 	}
+	pollEndPostAnswerWeightedHeading = &i18n.Message{
+		ID:    "poll.endPost.answer.weightedHeading",
+		One:   "{{.Answer}} ({{.Count}} weighted vote, {{.Percentage}}%, {{.Voters}} by headcount)",
+		Other: "{{.Answer}} ({{.Count}} weighted votes, {{.Percentage}}%, {{.Voters}} by headcount)",
+	}
+	pollEndPostAnswerApprovalHeading = &i18n.Message{
+		ID:    "poll.endPost.answer.approvalHeading",
+		One:   "{{.Answer}} ({{.Count}} approval, {{.Percentage}}%)",
+		Other: "{{.Answer}} ({{.Count}} approvals, {{.Percentage}}%)",
+	}
+	pollEndPostAnswerSchedulingHeading = &i18n.Message{
+		ID:    "poll.endPost.answer.schedulingHeading",
+		One:   "{{.Answer}} ({{.Count}} available, {{.Percentage}}%)",
+		Other: "{{.Answer}} ({{.Count}} available, {{.Percentage}}%)",
+	}
+	pollEndPostSchedulingBestSlot = &i18n.Message{
+		ID:    "poll.endPost.scheduling.bestSlot",
+		One:   "Best Slot",
+		Other: "Best Slots",
+	}
+	pollEndPostRatingHeading = &i18n.Message{
+		ID:    "poll.endPost.rating.heading",
+		One:   "{{.Answer}} ({{.Average}} average, {{.Count}} rating)",
+		Other: "{{.Answer}} ({{.Average}} average, {{.Count}} ratings)",
+	}
+	pollEndPostEstimateStatistics = &i18n.Message{
+		ID:    "poll.endPost.estimate.statistics",
+		Other: "Statistics",
+	}
+	pollEndPostEstimateStatisticsValue = &i18n.Message{
+		ID:    "poll.endPost.estimate.statisticsValue",
+		Other: "Estimates: {{.Count}}\nMean: {{.Mean}}\nMedian: {{.Median}}\nRange: {{.Min}}–{{.Max}}",
+	}
+	pollEndPostEstimateDistribution = &i18n.Message{
+		ID:    "poll.endPost.estimate.distribution",
+		Other: "Distribution",
+	}
+	pollEndPostNPS = &i18n.Message{
+		ID:    "poll.endPost.nps",
+		Other: "Net Promoter Score",
+	}
+	pollEndPostRankedWinner = &i18n.Message{
+		ID:    "poll.endPost.ranked.winner",
+		Other: "Winner",
+	}
+	pollEndPostRankedRound = &i18n.Message{
+		ID:    "poll.endPost.ranked.round",
+		Other: "Round {{.Round}}",
+	}
+	pollEndPostRankedEliminated = &i18n.Message{
+		ID:    "poll.endPost.ranked.eliminated",
+		Other: "{{.Answer}} has been eliminated",
+	}
+
+	pollResultsWinner = &i18n.Message{
+		ID:    "poll.results.winner",
+		Other: "**Winner**: {{.Answer}}",
+	}
+	pollResultsTie = &i18n.Message{
+		ID:    "poll.results.tie",
+		Other: "**Tie**: {{.Answers}}",
+	}
+	pollResultsNoVotes = &i18n.Message{
+		ID:    "poll.results.noVotes",
+		Other: "Nobody has voted.",
+	}
+	pollResultsAnswer = &i18n.Message{
+		ID:    "poll.results.answer",
+		One:   "{{.Position}}. {{.Answer}}: {{.Count}} vote ({{.Percentage}}%)",
+		Other: "{{.Position}}. {{.Answer}}: {{.Count}} votes ({{.Percentage}}%)",
+	}
+
+	pollResultsRating = &i18n.Message{
+		ID:    "poll.results.rating",
+		One:   "{{.Position}}. {{.Answer}}: {{.Average}} average ({{.Count}} rating)",
+		Other: "{{.Position}}. {{.Answer}}: {{.Average}} average ({{.Count}} ratings)",
+	}
+	pollResultsEstimate = &i18n.Message{
+		ID:    "poll.results.estimate",
+		One:   "**Mean**: {{.Mean}}, **Median**: {{.Median}} ({{.Count}} estimate from {{.Min}} to {{.Max}})",
+		Other: "**Mean**: {{.Mean}}, **Median**: {{.Median}} ({{.Count}} estimates from {{.Min}} to {{.Max}})",
+	}
+	pollResultsEstimateBucket = &i18n.Message{
+		ID:    "poll.results.estimateBucket",
+		One:   "{{.Position}}. {{.Estimate}}: {{.Count}} vote ({{.Percentage}}%)",
+		Other: "{{.Position}}. {{.Estimate}}: {{.Count}} votes ({{.Percentage}}%)",
+	}
+	pollResultsNPSPromoters = &i18n.Message{
+		ID:    "poll.results.nps.promoters",
+		Other: "Promoters ({{.From}}–{{.To}})",
+	}
+	pollResultsNPSPassives = &i18n.Message{
+		ID:    "poll.results.nps.passives",
+		Other: "Passives ({{.From}}–{{.To}})",
+	}
+	pollResultsNPSDetractors = &i18n.Message{
+		ID:    "poll.results.nps.detractors",
+		Other: "Detractors ({{.From}}–{{.To}})",
+	}
+
+	pollDigestParticipation = &i18n.Message{
+		ID:    "poll.digest.participation",
+		Other: "**Participation**: {{.Voters}} of {{.Members}} channel members voted ({{.Percentage}}%).",
+	}
+
+	pollExportHeaderQuestion = &i18n.Message{
+		ID:    "poll.export.header.question",
+		Other: "Question",
+	}
+	pollExportHeaderAnswer = &i18n.Message{
+		ID:    "poll.export.header.answer",
+		Other: "Answer",
+	}
+	pollExportHeaderVotes = &i18n.Message{
+		ID:    "poll.export.header.votes",
+		Other: "Votes",
+	}
+	pollExportHeaderEstimate = &i18n.Message{
+		ID:    "poll.export.header.estimate",
+		Other: "Estimate",
+	}
+	pollExportHeaderAverage = &i18n.Message{
+		ID:    "poll.export.header.average",
+		Other: "Average Rating",
+	}
+	pollExportHeaderVoters = &i18n.Message{
+		ID:    "poll.export.header.voters",
+		Other: "Voters",
+	}
 )
 
 // ToPostActions returns the poll as a message
 func (p *Poll) ToPostActions(localizer *i18n.Localizer, siteURL, pluginID, authorName string) []*model.SlackAttachment {
+	if p.IsSurvey() {
+		return p.surveyToPostActions(localizer, siteURL, pluginID, authorName)
+	}
+
 	numberOfVotes := 0
 	actions := []*model.PostAction{}
+	text := ""
+	// Buttons and images share the order, even if it's randomized for every rendering
+	order := p.DisplayOrder()
 
-	for i, o := range p.AnswerOptions {
-		numberOfVotes += len(o.Voter)
-		answer := o.Answer
-		if p.Settings.Progress {
-			answer = fmt.Sprintf("%s (%d)", answer, len(o.Voter))
+	switch p.Settings.VoteMode {
+	case VoteModeRanked:
+		numberOfVotes = len(p.Rankings)
+		text = p.makeRankedOptionsText(order) + "\n"
+		actions = append(actions, &model.PostAction{
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonRankOptions}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/rank/request", siteURL, pluginID, p.ID),
+			},
+		})
+	case VoteModeRating:
+		numberOfVotes = len(p.Ratings)
+		text = p.makeRatedOptionsText(order) + "\n"
+		actions = append(actions, &model.PostAction{
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonRateOptions}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/rate/request", siteURL, pluginID, p.ID),
+			},
+		})
+	case VoteModeEstimate:
+		numberOfVotes = len(p.Estimates)
+		if p.showProgress() && numberOfVotes > 0 {
+			text = p.makeEstimateText(localizer) + "\n"
+		}
+		actions = append(actions, &model.PostAction{
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonSubmitEstimate}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/estimate/request", siteURL, pluginID, p.ID),
+			},
+		})
+	default:
+		for _, o := range p.AnswerOptions {
+			numberOfVotes += p.VotesOf(o)
+		}
+		// Polls with many answer options only show the buttons of the current page
+		order = p.pageOrder()
+		// The leading answer options are only moved to the top and marked if the progress is shown
+		leading := map[int]bool{}
+		if p.showProgress() {
+			order, leading = p.resultOrder(p.AnswerOptions, order)
+		}
+		// Compact polls list all answer options in a single select menu instead of a button each
+		var options []*model.PostActionOptions
+		for _, i := range order {
+			o := p.AnswerOptions[i]
+			answer := stripMarkdown(o.Answer)
+			if p.showProgress() {
+				answer = fmt.Sprintf("%s (%d)", answer, p.VotesOf(o))
+			}
+			if leading[i] {
+				answer = markLeader(answer)
+			}
+			// Buttons can't be disabled, so full answer options are marked instead
+			if p.IsOptionFull(i) {
+				answer = localizer.MustLocalize(&i18n.LocalizeConfig{
+					DefaultMessage: pollButtonOptionFull,
+					TemplateData:   map[string]interface{}{"Answer": answer},
+				})
+			}
+			if p.IsCompact() {
+				options = append(options, &model.PostActionOptions{Text: answer, Value: strconv.Itoa(i)})
+				continue
+			}
+			url := fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/vote/%v", siteURL, pluginID, p.ID, i)
+			// Votes that can't be changed need to be confirmed first
+			if p.Settings.LockVotes {
+				url += "/confirm/request"
+			}
+			actions = append(actions, &model.PostAction{
+				Name: answer,
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Integration: &model.PostActionIntegration{
+					URL: url,
+				},
+			})
+		}
+		if p.IsCompact() {
+			url := fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/vote/select", siteURL, pluginID, p.ID)
+			if p.Settings.LockVotes {
+				url += "/confirm/request"
+			}
+			actions = append(actions, &model.PostAction{
+				Name:    localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollSelectAnswerOption}),
+				Type:    model.POST_ACTION_TYPE_SELECT,
+				Options: options,
+				Integration: &model.PostActionIntegration{
+					URL: url,
+				},
+			})
+		}
+		// Buttons can't render Markdown or descriptions, so such answer options are listed in the text as well
+		if p.hasMarkdownOptions() || p.HasDescriptions() {
+			text = p.makeOptionsText(order) + "\n"
+		}
+		if p.IsNPS() && p.showProgress() && numberOfVotes > 0 {
+			text += p.makeNPSText(localizer) + "\n"
 		}
+		if p.IsPaginated() {
+			text += p.makePageText(localizer) + "\n"
+			actions = append(actions, p.makePageActions(localizer, siteURL, pluginID)...)
+		}
+		if p.Settings.AllowOther {
+			writeIns := 0
+			for _, o := range p.WriteIns {
+				writeIns += p.VotesOf(o)
+			}
+			numberOfVotes += writeIns
+			other := localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonOther})
+			if p.showProgress() {
+				other = fmt.Sprintf("%s (%d)", other, writeIns)
+			}
+			actions = append(actions, &model.PostAction{
+				Name: other,
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Integration: &model.PostActionIntegration{
+					URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/other/request", siteURL, pluginID, p.ID),
+				},
+			})
+		}
+		// Approval voters may pick several options, but count as a single voter
+		if p.IsApprovalVote() {
+			numberOfVotes = len(p.voters())
+		}
+		if p.hasTruncatedVoters() {
+			actions = append(actions, &model.PostAction{
+				Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonShowAllVoters}),
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Integration: &model.PostActionIntegration{
+					URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/voters", siteURL, pluginID, p.ID),
+				},
+			})
+		}
+	}
+
+	// Estimate polls have no answer options to add to and the scores of NPS polls are fixed
+	if p.Settings.VoteMode != VoteModeEstimate && !p.IsNPS() {
+		actions = append(actions, &model.PostAction{
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonAddOption}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/option/add/request", siteURL, pluginID, p.ID),
+			},
+		})
+	}
+
+	actions = append(actions, p.makeShowResultsActions(localizer, siteURL, pluginID)...)
+	actions = append(actions, p.makeResetVoteActions(localizer, siteURL, pluginID)...)
+	actions = append(actions, p.makeManagementActions(localizer, siteURL, pluginID)...)
+
+	title, heading := makeTitle(p.Question)
+	attachments := []*model.SlackAttachment{{
+		AuthorName: p.displayedAuthorName(authorName),
+		Title:      title,
+		Text:       joinText(heading, text+p.makeAdditionalText(localizer, numberOfVotes)),
+		Actions:    actions,
+		Footer:     p.makeFooterText(localizer),
+	}}
+	return append(attachments, p.makeImageAttachments(order)...)
+}
+
+// makeFooterText returns the footer of a running poll, which tells whether voting is paused and how long the poll keeps running
+func (p *Poll) makeFooterText(localizer *i18n.Localizer) string {
+	countdown := p.makeCountdownText(localizer)
+	// Once the deadline has passed, voting stays closed even if the poll gets resumed
+	if !p.IsPaused() || p.IsPastDeadline() {
+		return countdown
+	}
+	paused := localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollMessageVotingPaused})
+	if countdown == "" {
+		return paused
+	}
+	return paused + " · " + countdown
+}
+
+// makeCountdownText returns how long a poll with a deadline keeps running, e.g. "Ends in 3h".
+// The countdown is only as current as the post, so it gets refreshed whenever the post is updated and whenever it changes, see NextCountdownChange.
+// Once the deadline has passed, it says that voting is closed, even if the poll hasn't been ended yet. It's empty for polls without a deadline.
+func (p *Poll) makeCountdownText(localizer *i18n.Localizer) string {
+	if !p.HasDeadline() {
+		return ""
+	}
+	remaining := time.Duration(p.Settings.EndAt-model.GetMillis()) * time.Millisecond
+	if remaining <= 0 {
+		return localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollMessageVotingClosed})
+	}
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollMessageEndsIn,
+		TemplateData:   map[string]interface{}{"Countdown": formatCountdown(remaining)},
+	})
+}
+
+// formatCountdown returns a remaining duration in its largest unit, e.g. "2d", "3h" or "45m".
+// Minutes are rounded up, so that less than a minute still shows as "1m".
+func formatCountdown(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", (d+time.Minute-1)/time.Minute)
+	}
+}
+
+// NextCountdownChange returns the time in milliseconds after a given time at which the countdown of the poll shows a different text next.
+// That's the deadline once the countdown shows the last minute. It's zero for polls without a deadline and once the deadline has passed.
+func (p *Poll) NextCountdownChange(now int64) int64 {
+	if !p.HasDeadline() || now >= p.Settings.EndAt {
+		return 0
+	}
+	remaining := time.Duration(p.Settings.EndAt-now) * time.Millisecond
+	// Days and hours are rounded down, so the text changes right after the remaining time falls below a full unit.
+	// Minutes are rounded up, so it changes as soon as the remaining time reaches a full minute.
+	var changesAt time.Duration
+	switch {
+	case remaining >= 24*time.Hour:
+		changesAt = remaining.Truncate(24*time.Hour) - time.Millisecond
+	case remaining >= time.Hour:
+		changesAt = remaining.Truncate(time.Hour) - time.Millisecond
+	default:
+		changesAt = (remaining - time.Millisecond).Truncate(time.Minute)
+	}
+	return p.Settings.EndAt - int64(changesAt/time.Millisecond)
+}
+
+// makePageText returns the text that tells which answer options the current page of a paginated poll shows
+func (p *Poll) makePageText(localizer *i18n.Localizer) string {
+	page := p.CurrentPage()
+	last := (page + 1) * p.PageSize
+	if last > len(p.AnswerOptions) {
+		last = len(p.AnswerOptions)
+	}
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollMessagePage,
+		TemplateData: map[string]interface{}{
+			"First": page*p.PageSize + 1,
+			"Last":  last,
+			"Total": len(p.AnswerOptions),
+			"Page":  page + 1,
+			"Pages": p.NumberOfPages(),
+		},
+	})
+}
+
+// makePageActions returns the buttons to navigate to the previous and the next page of a paginated poll.
+// The buttons are left out on the first and the last page.
+func (p *Poll) makePageActions(localizer *i18n.Localizer, siteURL, pluginID string) []*model.PostAction {
+	page := p.CurrentPage()
+	actions := []*model.PostAction{}
+	if page > 0 {
+		actions = append(actions, &model.PostAction{
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonPreviousPage}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/page/%d", siteURL, pluginID, p.ID, page-1),
+			},
+		})
+	}
+	if page < p.NumberOfPages()-1 {
 		actions = append(actions, &model.PostAction{
-			Name: answer,
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonNextPage}),
 			Type: model.POST_ACTION_TYPE_BUTTON,
 			Integration: &model.PostActionIntegration{
-				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/vote/%v", siteURL, pluginID, p.ID, i),
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/page/%d", siteURL, pluginID, p.ID, page+1),
 			},
 		})
 	}
+	return actions
+}
+
+// makeImageAttachments returns an attachment for every answer option with an image, which shows the image as thumbnail next to the answer.
+// The answer options are given as indices in the order they are shown.
+func (p *Poll) makeImageAttachments(order []int) []*model.SlackAttachment {
+	attachments := []*model.SlackAttachment{}
+	for _, i := range order {
+		o := p.AnswerOptions[i]
+		if o.ImageURL == "" {
+			continue
+		}
+		attachments = append(attachments, &model.SlackAttachment{
+			Title:    stripMarkdown(o.Answer),
+			ThumbURL: o.ImageURL,
+		})
+	}
+	return attachments
+}
+
+// surveyToPostActions returns a survey as a message with one attachment per question.
+// The first attachment holds the title of the survey and the last one the Poll Settings and the management buttons.
+func (p *Poll) surveyToPostActions(localizer *i18n.Localizer, siteURL, pluginID, authorName string) []*model.SlackAttachment {
+	title, heading := makeTitle(p.Question)
+	attachments := []*model.SlackAttachment{{
+		AuthorName: p.displayedAuthorName(authorName),
+		Title:      title,
+		Text:       heading,
+	}}
+
+	for i, q := range p.Questions {
+		actions := []*model.PostAction{}
+		order, leading := optionIndices(q.AnswerOptions), map[int]bool{}
+		if p.showProgress() {
+			order, leading = p.resultOrder(q.AnswerOptions, order)
+		}
+		for _, j := range order {
+			o := q.AnswerOptions[j]
+			answer := stripMarkdown(o.Answer)
+			if p.showProgress() {
+				answer = fmt.Sprintf("%s (%d)", answer, len(o.Voter))
+			}
+			if leading[j] {
+				answer = markLeader(answer)
+			}
+			actions = append(actions, &model.PostAction{
+				Name: answer,
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Integration: &model.PostActionIntegration{
+					URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/survey/%d/vote/%d", siteURL, pluginID, p.ID, i, j),
+				},
+			})
+		}
+		questionTitle, questionHeading := makeTitle(fmt.Sprintf("%d. %s", i+1, q.Question))
+		attachments = append(attachments, &model.SlackAttachment{
+			Title:   questionTitle,
+			Text:    questionHeading,
+			Actions: actions,
+		})
+	}
 
-	actions = append(actions, &model.PostAction{
-		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonAddOption}),
+	attachments = append(attachments, &model.SlackAttachment{
+		Text:    p.makeAdditionalText(localizer, p.NumberOfVoters()),
+		Actions: append(append(p.makeShowResultsActions(localizer, siteURL, pluginID), p.makeResetVoteActions(localizer, siteURL, pluginID)...), p.makeManagementActions(localizer, siteURL, pluginID)...),
+		Footer:  p.makeFooterText(localizer),
+	})
+	return attachments
+}
+
+// makeShowResultsActions returns the button that shows voters the current results of a poll with vote-to-see
+func (p *Poll) makeShowResultsActions(localizer *i18n.Localizer, siteURL, pluginID string) []*model.PostAction {
+	if !p.Settings.VoteToSee {
+		return []*model.PostAction{}
+	}
+	return []*model.PostAction{{
+		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonShowResults}),
 		Type: model.POST_ACTION_TYPE_BUTTON,
 		Integration: &model.PostActionIntegration{
-			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/option/add/request", siteURL, pluginID, p.ID),
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/results", siteURL, pluginID, p.ID),
 		},
-	})
+	}}
+}
 
-	actions = append(actions, &model.PostAction{
-		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonDeltePoll}),
+// makeResetVoteActions returns the button that lets voters take back all their votes.
+// Polls with locked votes don't get it, because their votes are final.
+func (p *Poll) makeResetVoteActions(localizer *i18n.Localizer, siteURL, pluginID string) []*model.PostAction {
+	if p.Settings.LockVotes {
+		return []*model.PostAction{}
+	}
+	return []*model.PostAction{{
+		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonResetVote}),
 		Type: model.POST_ACTION_TYPE_BUTTON,
 		Integration: &model.PostActionIntegration{
-			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/delete", siteURL, pluginID, p.ID),
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/vote/reset", siteURL, pluginID, p.ID),
 		},
-	})
+	}}
+}
 
-	actions = append(actions, &model.PostAction{
+// makeManagementActions returns the buttons to show and remind the non-voters, delete, pause or resume, end and transfer the poll
+func (p *Poll) makeManagementActions(localizer *i18n.Localizer, siteURL, pluginID string) []*model.PostAction {
+	return []*model.PostAction{{
+		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonShowNonVoters}),
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/nonvoters", siteURL, pluginID, p.ID),
+		},
+	}, {
+		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonRemindNonVoters}),
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/remind", siteURL, pluginID, p.ID),
+		},
+	}, {
+		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonDeltePoll}),
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/delete/confirm/request", siteURL, pluginID, p.ID),
+		},
+	}, p.makePauseAction(localizer, siteURL, pluginID), {
 		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonEndPoll}),
 		Type: model.POST_ACTION_TYPE_BUTTON,
 		Integration: &model.PostActionIntegration{
-			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/end", siteURL, pluginID, p.ID),
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/end/confirm/request", siteURL, pluginID, p.ID),
+		},
+	}, {
+		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonTransferPoll}),
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/transfer/request", siteURL, pluginID, p.ID),
 		},
-	})
-
-	return []*model.SlackAttachment{{
-		AuthorName: authorName,
-		Title:      p.Question,
-		Text:       p.makeAdditionalText(localizer, numberOfVotes),
-		Actions:    actions,
 	}}
 }
 
+// makePauseAction returns the button that pauses voting in the poll, or the button that resumes it if voting is paused
+func (p *Poll) makePauseAction(localizer *i18n.Localizer, siteURL, pluginID string) *model.PostAction {
+	if p.IsPaused() {
+		return &model.PostAction{
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonResumePoll}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/resume", siteURL, pluginID, p.ID),
+			},
+		}
+	}
+	return &model.PostAction{
+		Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonPausePoll}),
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/pause", siteURL, pluginID, p.ID),
+		},
+	}
+}
+
+// displayedAuthorName returns the author name shown on poll posts. Polls with an anonymous creator are posted without one.
+func (p *Poll) displayedAuthorName(authorName string) string {
+	if p.Settings.AnonymousCreator {
+		return ""
+	}
+	return authorName
+}
+
+// showProgress returns true if vote counts are shown while the poll is running.
+// Secret polls only reveal their results once they ended and vote-to-see polls only reveal them to voters.
+func (p *Poll) showProgress() bool {
+	return p.Settings.Progress && !p.Settings.Secret && !p.Settings.VoteToSee
+}
+
+// makeRankedOptionsText returns a numbered markdown list of all answer options of a ranked poll in a given order.
+// If the progress is shown, the number of first preferences is shown for every option.
+func (p *Poll) makeRankedOptionsText(order []int) string {
+	firstPreferences := make([]int, len(p.AnswerOptions))
+	for _, ranking := range p.Rankings {
+		if len(ranking) > 0 && ranking[0] < len(firstPreferences) {
+			firstPreferences[ranking[0]]++
+		}
+	}
+
+	lines := []string{}
+	for n, i := range order {
+		line := fmt.Sprintf("%d. %s", n+1, sanitizeMarkdown(p.AnswerOptions[i].Answer))
+		if p.showProgress() {
+			line = fmt.Sprintf("%s (%d)", line, firstPreferences[i])
+		}
+		lines = append(lines, describeOption(line, p.AnswerOptions[i]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// makeRatedOptionsText returns a numbered markdown list of all answer options of a rating poll in a given order.
+// If the progress is shown, the average score is shown for every option that has been rated.
+func (p *Poll) makeRatedOptionsText(order []int) string {
+	results := p.RatingResults()
+
+	lines := []string{}
+	for n, i := range order {
+		line := fmt.Sprintf("%d. %s", n+1, sanitizeMarkdown(p.AnswerOptions[i].Answer))
+		if p.showProgress() && results[i].Count() > 0 {
+			line = fmt.Sprintf("%s (%s ★)", line, results[i].formatAverage())
+		}
+		lines = append(lines, describeOption(line, p.AnswerOptions[i]))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // makeAdditionalText make descriptions about poll
 // This method returns markdown text, because it is used for SlackAttachment.Text field.
 func (p *Poll) makeAdditionalText(localizer *i18n.Localizer, numberOfVotes int) string {
@@ -105,12 +802,89 @@ func (p *Poll) makeAdditionalText(localizer *i18n.Localizer, numberOfVotes int)
 	if p.Settings.Anonymous {
 		settingsText = append(settingsText, "anonymous")
 	}
+	if p.Settings.AnonymousCreator {
+		settingsText = append(settingsText, "anonymous-creator")
+	}
+	if p.Settings.AllowOther {
+		settingsText = append(settingsText, "allow-other")
+	}
+	if p.Settings.EndWhenAllVoted {
+		settingsText = append(settingsText, "end-when-all-voted")
+	}
+	if p.Settings.LockVotes {
+		settingsText = append(settingsText, "lock-votes")
+	}
+	if p.Settings.MembersOnly {
+		settingsText = append(settingsText, "members-only")
+	}
+	if p.Settings.NoGuests {
+		settingsText = append(settingsText, "no-guests")
+	}
 	if p.Settings.Progress {
 		settingsText = append(settingsText, "progress")
 	}
 	if p.Settings.PublicAddOption {
 		settingsText = append(settingsText, "public-add-option")
 	}
+	if p.Settings.PublicVotes {
+		settingsText = append(settingsText, "public-votes")
+	}
+	if p.Settings.Secret {
+		settingsText = append(settingsText, "secret")
+	}
+	if p.Settings.SortResults {
+		settingsText = append(settingsText, "sort-results")
+	}
+	if p.Settings.VoteToSee {
+		settingsText = append(settingsText, "vote-to-see")
+	}
+	switch p.Settings.Shuffle {
+	case ShuffleOnce:
+		settingsText = append(settingsText, "shuffle")
+	case ShuffleAlways:
+		settingsText = append(settingsText, "shuffle="+string(ShuffleAlways))
+	}
+	if p.Settings.VoteMode != VoteModeSingle {
+		settingsText = append(settingsText, "votemode="+string(p.Settings.VoteMode))
+	}
+	if p.IsMultiVote() {
+		settingsText = append(settingsText, "votes="+strconv.Itoa(p.Settings.MaxVotes))
+	}
+	if p.IsWeighted() {
+		settingsText = append(settingsText, "weights")
+	}
+	if p.Settings.MaxPerOption > 0 {
+		settingsText = append(settingsText, "max-per-option="+strconv.Itoa(p.Settings.MaxPerOption))
+	}
+	if p.HasQuorum() {
+		settingsText = append(settingsText, "quorum="+strconv.Itoa(p.Settings.Quorum)+"%")
+	}
+	if p.Settings.NotifyAt > 0 {
+		settingsText = append(settingsText, "notify-at="+strconv.Itoa(p.Settings.NotifyAt))
+	}
+	if p.IsRecurring() {
+		settingsText = append(settingsText, "repeat="+string(p.Settings.Repeat))
+	}
+	if p.HasDigest() {
+		settingsText = append(settingsText, "digest="+string(p.Settings.Digest))
+	}
+	if p.Settings.Invite {
+		settingsText = append(settingsText, "invite")
+	}
+	if p.Settings.SlotDuration != 0 {
+		settingsText = append(settingsText, "duration="+strconv.Itoa(p.Settings.SlotDuration)+"m")
+	}
+	if p.HasDeadline() {
+		endAt := time.Unix(0, p.Settings.EndAt*int64(time.Millisecond)).UTC()
+		settingsText = append(settingsText, "end="+endAt.Format(TimeLayout)+" UTC")
+	}
+	if len(p.Settings.Tags) > 0 {
+		settingsText = append(settingsText, "tags="+strings.Join(p.Settings.Tags, ","))
+	}
+	// The email addresses are left out, since everybody in the channel sees the settings
+	if p.HasEmailSummary() {
+		settingsText = append(settingsText, "email")
+	}
 
 	lines := []string{"---"}
 	if len(settingsText) > 0 {
@@ -120,56 +894,972 @@ func (p *Poll) makeAdditionalText(localizer *i18n.Localizer, numberOfVotes int)
 		}))
 	}
 
-	lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
-		DefaultMessage: pollMessageTotalVotes,
-		TemplateData:   map[string]interface{}{"TotalVotes": numberOfVotes},
-	}))
+	switch {
+	case p.Settings.Secret:
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollMessageResultsHidden}))
+	case p.Settings.VoteToSee:
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollMessageResultsVoteToSee}))
+	default:
+		lines = append(lines, p.makeTotalVotesText(localizer, numberOfVotes))
+		if len(p.Delegations) > 0 {
+			lines = append(lines, p.makeDelegationText(localizer))
+		}
+	}
 	return strings.Join(lines, "\n")
 }
 
 // ToEndPollPost returns the poll end message
-func (p *Poll) ToEndPollPost(localizer *i18n.Localizer, authorName string, convert func(string) (string, *model.AppError)) (*model.Post, *model.AppError) {
+func (p *Poll) ToEndPollPost(localizer *i18n.Localizer, siteURL, pluginID, authorName string, convert func(string) (string, *model.AppError)) (*model.Post, *model.AppError) {
 	post := &model.Post{}
-	fields := []*model.SlackAttachmentField{}
 
-	for _, o := range p.AnswerOptions {
-		var voter string
-		if !p.Settings.Anonymous {
-			for i := 0; i < len(o.Voter); i++ {
-				displayName, err := convert(o.Voter[i])
-				if err != nil {
-					return nil, err
-				}
-				if i+1 == len(o.Voter) && len(o.Voter) > 1 {
-					voter += " " + localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostSeperator}) + " "
-				} else if i != 0 {
-					voter += ", "
-				}
-				voter += displayName
-			}
+	var fields []*model.SlackAttachmentField
+	leader := ""
+	switch {
+	case p.IsSurvey():
+		// The results of every question get an attachment of their own
+	case p.Settings.VoteMode == VoteModeRanked:
+		fields = p.makeRankedResultFields(localizer)
+	case p.Settings.VoteMode == VoteModeRating:
+		fields = p.makeRatingResultFields(localizer)
+	case p.Settings.VoteMode == VoteModeEstimate:
+		fields = p.makeEstimateResultFields(localizer)
+	case p.IsNPS():
+		// The scores keep their order, and instead of a winner the Net Promoter Score is shown
+		var err *model.AppError
+		fields, err = p.makeResultFields(localizer, p.AnswerOptions, convert)
+		if err != nil {
+			return nil, err
 		}
+		fields = append([]*model.SlackAttachmentField{p.makeNPSResultField(localizer)}, fields...)
+	default:
+		var err *model.AppError
+		fields, err = p.makeResultFields(localizer, p.resultOptions(), convert)
+		if err != nil {
+			return nil, err
+		}
+		leader = p.makeLeaderText(localizer, p.resultOptions())
+		if p.Settings.VoteMode == VoteModeScheduling {
+			fields = append(p.makeBestSlotFields(localizer), fields...)
+		}
+	}
 
-		fields = append(fields, &model.SlackAttachmentField{
+	text := localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostText})
+	if p.HasQuorum() {
+		text = p.makeQuorumText(localizer) + "\n" + text
+	}
+	if p.IsWeighted() {
+		text += "\n" + p.makeTotalVotesText(localizer, sum(p.countVotes(p.resultOptions())))
+	}
+	if len(p.Delegations) > 0 {
+		text += "\n" + p.makeDelegationText(localizer)
+	}
+	text = joinText(text, leader)
+
+	title, heading := makeTitle(p.Question)
+	attachments := []*model.SlackAttachment{{
+		AuthorName: p.displayedAuthorName(authorName),
+		Title:      title,
+		Text:       joinText(heading, text),
+		Fields:     fields,
+		Actions: []*model.PostAction{{
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonExport}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/export", siteURL, pluginID, p.ID),
+			},
+		}, {
+			Name: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollButtonReopen}),
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/reopen", siteURL, pluginID, p.ID),
+			},
+		}},
+	}}
+	for i, q := range p.Questions {
+		questionFields, err := p.makeResultFields(localizer, q.AnswerOptions, convert)
+		if err != nil {
+			return nil, err
+		}
+		questionTitle, questionHeading := makeTitle(fmt.Sprintf("%d. %s", i+1, q.Question))
+		attachments = append(attachments, &model.SlackAttachment{
+			Title:  questionTitle,
+			Text:   joinText(questionHeading, p.makeLeaderText(localizer, q.AnswerOptions)),
+			Fields: questionFields,
+		})
+	}
+	// Images follow the order of the results
+	order, _ := p.resultOrder(p.AnswerOptions, p.originalOrder())
+	attachments = append(attachments, p.makeImageAttachments(order)...)
+	model.ParseSlackAttachment(post, attachments)
+
+	return post, nil
+}
+
+// makeQuorumText returns whether an ended poll reached its quorum
+func (p *Poll) makeQuorumText(localizer *i18n.Localizer) string {
+	message := pollEndPostQuorumReached
+	if !p.IsQuorumReached() {
+		message = pollEndPostQuorumNotReached
+	}
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: message,
+		TemplateData: map[string]interface{}{
+			"Voters":  p.NumberOfVoters(),
+			"Members": p.NumberOfEligibleVoters,
+			"Quorum":  p.Settings.Quorum,
+		},
+	})
+}
+
+// makeTotalVotesText returns the total number of votes. For weighted polls the number of voters is included.
+func (p *Poll) makeTotalVotesText(localizer *i18n.Localizer, numberOfVotes int) string {
+	if !p.IsWeighted() {
+		return localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollMessageTotalVotes,
+			TemplateData:   map[string]interface{}{"TotalVotes": numberOfVotes},
+		})
+	}
+	numberOfVoters := len(p.voters())
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollMessageTotalWeightedVotes,
+		TemplateData:   map[string]interface{}{"TotalVotes": numberOfVotes, "Voters": numberOfVoters},
+		PluralCount:    numberOfVoters,
+	})
+}
+
+// makeDelegationText returns how many voters delegated their vote
+func (p *Poll) makeDelegationText(localizer *i18n.Localizer) string {
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollMessageDelegatedVotes,
+		TemplateData:   map[string]interface{}{"Count": len(p.Delegations)},
+		PluralCount:    len(p.Delegations),
+	})
+}
+
+// makeResultFields returns the number of votes and the voters of the given answer options as attachment fields.
+// For approval and scheduling polls the share of voters that approved an answer option is included.
+func (p *Poll) makeResultFields(localizer *i18n.Localizer, answerOptions []*AnswerOption, convert func(string) (string, *model.AppError)) ([]*model.SlackAttachmentField, *model.AppError) {
+	fields := []*model.SlackAttachmentField{}
+	numberOfVoters := len(p.voters())
+	numberOfVotes := sum(p.countVotes(answerOptions))
+	// Delegates are listed with the number of votes delegated to them
+	convert = p.withDelegations(convert)
+
+	order, leading := p.resultOrder(answerOptions, optionIndices(answerOptions))
+	for _, i := range order {
+		o := answerOptions[i]
+		var voter string
+		if !p.Settings.Anonymous {
+			var err *model.AppError
+			if voter, err = makeVoterList(localizer, o.Voter, 0, convert); err != nil {
+				return nil, err
+			}
+		}
+
+		votes := p.VotesOf(o)
+		heading := &i18n.LocalizeConfig{
+			DefaultMessage: pollEndPostAnswerHeading,
+			TemplateData: map[string]interface{}{
+				"Answer": stripMarkdown(o.Answer),
+				"Count":  votes,
+			},
+			PluralCount: votes,
+		}
+		// Weighted polls show the share of the weighted votes alongside the headcount
+		if p.IsWeighted() {
+			heading.DefaultMessage = pollEndPostAnswerWeightedHeading
+			heading.TemplateData = map[string]interface{}{
+				"Answer":     stripMarkdown(o.Answer),
+				"Count":      votes,
+				"Percentage": percentage(votes, numberOfVotes),
+				"Voters":     len(o.Voter),
+			}
+		}
+		if p.IsApprovalVote() {
+			heading.DefaultMessage = pollEndPostAnswerApprovalHeading
+			if p.Settings.VoteMode == VoteModeScheduling {
+				heading.DefaultMessage = pollEndPostAnswerSchedulingHeading
+			}
+			heading.TemplateData = map[string]interface{}{
+				"Answer":     stripMarkdown(o.Answer),
+				"Count":      len(o.Voter),
+				"Percentage": percentage(len(o.Voter), numberOfVoters),
+			}
+		}
+
+		title := localizer.MustLocalize(heading)
+		if leading[i] {
+			title = markLeader(title)
+		}
+		fields = append(fields, &model.SlackAttachmentField{
 			Short: true,
-			Title: localizer.MustLocalize(&i18n.LocalizeConfig{
+			Title: title,
+			Value: voter,
+		})
+	}
+
+	return fields, nil
+}
+
+// MakeVoterFields returns the voters of every answer option and write-in of a poll with public votes as attachment fields.
+// Only the first limit voters of an answer option are listed. A limit of zero lists all voters.
+func (p *Poll) MakeVoterFields(localizer *i18n.Localizer, limit int, convert func(string) (string, *model.AppError)) ([]*model.SlackAttachmentField, *model.AppError) {
+	fields := []*model.SlackAttachmentField{}
+	for _, o := range p.resultOptions() {
+		voter, err := makeVoterList(localizer, o.Voter, limit, convert)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, &model.SlackAttachmentField{
+			Short: true,
+			Title: stripMarkdown(o.Answer),
+			Value: voter,
+		})
+	}
+	return fields, nil
+}
+
+// VoterGroup is the part of the voters of an answer option that is listed on a page of voters
+type VoterGroup struct {
+	Answer string
+	// Votes is the number of votes of the answer option, not only of the listed voters
+	Votes int
+	Voter []string
+}
+
+// PageVoters returns the voters of a poll with public votes that are listed on a given page, grouped by answer option.
+// The voters of all answer options and write-ins are counted across pages, so the voters of an answer option may be split
+// between two pages. Pages start at zero. The total number of votes listed on all pages is returned as well.
+func (p *Poll) PageVoters(page, perPage int) ([]*VoterGroup, int) {
+	first := page * perPage
+	groups := []*VoterGroup{}
+	total := 0
+	for _, o := range p.resultOptions() {
+		start, end := first-total, first+perPage-total
+		total += len(o.Voter)
+		if start < 0 {
+			start = 0
+		}
+		if end > len(o.Voter) {
+			end = len(o.Voter)
+		}
+		if start >= end {
+			continue
+		}
+		groups = append(groups, &VoterGroup{
+			Answer: stripMarkdown(o.Answer),
+			Votes:  p.VotesOf(o),
+			Voter:  o.Voter[start:end],
+		})
+	}
+	return groups, total
+}
+
+// hasTruncatedVoters returns true if the running poll doesn't list all voters of an answer option
+func (p *Poll) hasTruncatedVoters() bool {
+	if !p.Settings.PublicVotes {
+		return false
+	}
+	for _, o := range p.resultOptions() {
+		if len(o.Voter) > PublicVotersLimit {
+			return true
+		}
+	}
+	return false
+}
+
+// makeVoterList returns the display names of the given voters, e.g. "@a, @b and @c".
+// If there are more than limit voters, the remaining ones are only counted. A limit of zero lists all voters.
+func makeVoterList(localizer *i18n.Localizer, voters []string, limit int, convert func(string) (string, *model.AppError)) (string, *model.AppError) {
+	listed := voters
+	if limit > 0 && len(voters) > limit {
+		listed = voters[:limit]
+	}
+
+	names := []string{}
+	for _, userID := range listed {
+		displayName, err := convert(userID)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, displayName)
+	}
+	if len(listed) < len(voters) {
+		names = append(names, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollMessageMoreVoters,
+			TemplateData:   map[string]interface{}{"Count": len(voters) - len(listed)},
+		}))
+	}
+
+	if len(names) < 2 {
+		return strings.Join(names, ""), nil
+	}
+	seperator := localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostSeperator})
+	return strings.Join(names[:len(names)-1], ", ") + " " + seperator + " " + names[len(names)-1], nil
+}
+
+// makeBestSlotFields returns the time slots most voters of a scheduling poll are available at as attachment field.
+// There is no field if nobody has voted.
+func (p *Poll) makeBestSlotFields(localizer *i18n.Localizer) []*model.SlackAttachmentField {
+	best := p.BestSlots()
+	if len(best) == 0 {
+		return nil
+	}
+	answers := []string{}
+	for _, i := range best {
+		answers = append(answers, p.AnswerOptions[i].Answer)
+	}
+	return []*model.SlackAttachmentField{{
+		Title: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostSchedulingBestSlot, PluralCount: len(best)}),
+		Value: strings.Join(answers, "\n"),
+	}}
+}
+
+// makeRankedResultFields returns the winner and all counting rounds of a ranked poll as attachment fields
+func (p *Poll) makeRankedResultFields(localizer *i18n.Localizer) []*model.SlackAttachmentField {
+	fields := []*model.SlackAttachmentField{}
+
+	rounds, winner := p.InstantRunoff()
+	if winner != -1 {
+		fields = append(fields, &model.SlackAttachmentField{
+			Title: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostRankedWinner}),
+			Value: p.AnswerOptions[winner].Answer,
+		})
+	}
+
+	for i, round := range rounds {
+		lines := []string{}
+		for j, o := range p.AnswerOptions {
+			count, ok := round.Counts[j]
+			if !ok {
+				continue
+			}
+			lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
 				DefaultMessage: pollEndPostAnswerHeading,
 				TemplateData: map[string]interface{}{
 					"Answer": o.Answer,
-					"Count":  len(o.Voter),
+					"Count":  count,
 				},
-				PluralCount: len(o.Voter),
+				PluralCount: count,
+			}))
+		}
+		if round.Eliminated != -1 {
+			lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: pollEndPostRankedEliminated,
+				TemplateData:   map[string]interface{}{"Answer": p.AnswerOptions[round.Eliminated].Answer},
+			}))
+		}
+
+		fields = append(fields, &model.SlackAttachmentField{
+			Title: localizer.MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: pollEndPostRankedRound,
+				TemplateData:   map[string]interface{}{"Round": i + 1},
 			}),
-			Value: voter,
+			Value: strings.Join(lines, "\n"),
 		})
 	}
 
-	attachments := []*model.SlackAttachment{{
-		AuthorName: authorName,
-		Title:      p.Question,
-		Text:       localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostText}),
-		Fields:     fields,
+	return fields
+}
+
+// makeRatingResultFields returns the average score and the distribution of the scores of every answer option of a rating poll as attachment fields
+func (p *Poll) makeRatingResultFields(localizer *i18n.Localizer) []*model.SlackAttachmentField {
+	fields := []*model.SlackAttachmentField{}
+	for i, r := range p.RatingResults() {
+		lines := []string{}
+		for score := RatingMaxScore; score > 0; score-- {
+			lines = append(lines, fmt.Sprintf("%s %d", strings.Repeat("★", score), r.Distribution[score-1]))
+		}
+		fields = append(fields, &model.SlackAttachmentField{
+			Short: true,
+			Title: localizer.MustLocalize(&i18n.LocalizeConfig{
+				DefaultMessage: pollEndPostRatingHeading,
+				TemplateData: map[string]interface{}{
+					"Answer":  p.AnswerOptions[i].Answer,
+					"Average": r.formatAverage(),
+					"Count":   r.Count(),
+				},
+				PluralCount: r.Count(),
+			}),
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+	return fields
+}
+
+// makeEstimateText returns the number of estimates of an estimate poll together with their mean and median
+func (p *Poll) makeEstimateText(localizer *i18n.Localizer) string {
+	r := p.EstimateResults()
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollMessageEstimates,
+		TemplateData: map[string]interface{}{
+			"Count":  r.Count,
+			"Mean":   formatEstimate(r.Mean),
+			"Median": formatEstimate(r.Median),
+		},
+		PluralCount: r.Count,
+	})
+}
+
+// makeEstimateResultFields returns the statistics of an estimate poll and the distribution of its estimates as a bar per value or range
+func (p *Poll) makeEstimateResultFields(localizer *i18n.Localizer) []*model.SlackAttachmentField {
+	r := p.EstimateResults()
+	if r.Count == 0 {
+		return []*model.SlackAttachmentField{{
+			Title: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostEstimateStatistics}),
+			Value: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollResultsNoVotes}),
+		}}
+	}
+
+	highest := 0
+	for _, b := range r.Distribution {
+		if len(b.Voters) > highest {
+			highest = len(b.Voters)
+		}
+	}
+	lines := []string{}
+	for _, b := range r.Distribution {
+		// The longest bar has ten blocks, but every value with votes gets at least one
+		blocks := (len(b.Voters)*10 + highest - 1) / highest
+		lines = append(lines, fmt.Sprintf("%s: %s %d", b.Label(), strings.Repeat("█", blocks), len(b.Voters)))
+	}
+	return []*model.SlackAttachmentField{{
+		Short: true,
+		Title: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostEstimateStatistics}),
+		Value: localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollEndPostEstimateStatisticsValue,
+			TemplateData:   r.templateData(),
+		}),
+	}, {
+		Short: true,
+		Title: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostEstimateDistribution}),
+		Value: strings.Join(lines, "\n"),
 	}}
-	model.ParseSlackAttachment(post, attachments)
+}
 
-	return post, nil
+// templateData returns the statistics of the estimates in the form the messages about them refer to
+func (r *EstimateResult) templateData() map[string]interface{} {
+	return map[string]interface{}{
+		"Count":  r.Count,
+		"Mean":   formatEstimate(r.Mean),
+		"Median": formatEstimate(r.Median),
+		"Min":    formatEstimate(r.Min),
+		"Max":    formatEstimate(r.Max),
+	}
+}
+
+// makeEstimateList returns the statistics of an estimate poll followed by a numbered line for every value or range of its distribution
+func (p *Poll) makeEstimateList(localizer *i18n.Localizer) []string {
+	r := p.EstimateResults()
+	if r.Count == 0 {
+		return []string{localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollResultsNoVotes})}
+	}
+
+	lines := []string{localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollResultsEstimate,
+		TemplateData:   r.templateData(),
+		PluralCount:    r.Count,
+	})}
+	for position, b := range r.Distribution {
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollResultsEstimateBucket,
+			TemplateData: map[string]interface{}{
+				"Position":   position + 1,
+				"Estimate":   b.Label(),
+				"Count":      len(b.Voters),
+				"Percentage": percentage(len(b.Voters), r.Count),
+			},
+			PluralCount: len(b.Voters),
+		}))
+	}
+	return lines
+}
+
+// makeNPSText returns the Net Promoter Score of an NPS poll
+func (p *Poll) makeNPSText(localizer *i18n.Localizer) string {
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollMessageNPS,
+		TemplateData:   map[string]interface{}{"Score": p.NPSResults().formatScore()},
+	})
+}
+
+// makeNPSResultField returns the Net Promoter Score of an NPS poll and the share of its promoters, passives and detractors
+func (p *Poll) makeNPSResultField(localizer *i18n.Localizer) *model.SlackAttachmentField {
+	field := &model.SlackAttachmentField{
+		Title: localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollEndPostNPS}),
+	}
+	r := p.NPSResults()
+	if r.Total() == 0 {
+		field.Value = localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollResultsNoVotes})
+		return field
+	}
+	field.Value = fmt.Sprintf("**%s**\n%s", r.formatScore(), strings.Join(p.makeNPSGroupList(localizer, r), "\n"))
+	return field
+}
+
+// makeNPSList returns the Net Promoter Score of an NPS poll followed by a numbered line for its promoters, passives and detractors
+func (p *Poll) makeNPSList(localizer *i18n.Localizer) []string {
+	r := p.NPSResults()
+	if r.Total() == 0 {
+		return []string{localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollResultsNoVotes})}
+	}
+	return append([]string{p.makeNPSText(localizer)}, p.makeNPSGroupList(localizer, r)...)
+}
+
+// makeNPSGroupList returns a numbered line with the number and share of the votes of the promoters, passives and detractors
+func (p *Poll) makeNPSGroupList(localizer *i18n.Localizer, r *NPSResult) []string {
+	groups := []struct {
+		message  *i18n.Message
+		from, to int
+		count    int
+	}{
+		{pollResultsNPSPromoters, NPSMinPromoterScore, NPSMaxScore, r.Promoters},
+		{pollResultsNPSPassives, NPSMaxDetractorScore + 1, NPSMinPromoterScore - 1, r.Passives},
+		{pollResultsNPSDetractors, 0, NPSMaxDetractorScore, r.Detractors},
+	}
+	lines := []string{}
+	for position, g := range groups {
+		group := localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: g.message,
+			TemplateData:   map[string]interface{}{"From": g.from, "To": g.to},
+		})
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollResultsAnswer,
+			TemplateData: map[string]interface{}{
+				"Position":   position + 1,
+				"Answer":     group,
+				"Count":      g.count,
+				"Percentage": percentage(g.count, r.Total()),
+			},
+			PluralCount: g.count,
+		}))
+	}
+	return lines
+}
+
+// ToResultsSummary returns the results of the poll as markdown. The answer options are sorted by their number of votes
+// and the winner is called out. Ranked polls show the first preferences and the winner of the instant-runoff tally.
+// Approval and scheduling polls show the share of voters that approved an answer option. Rating polls are sorted by the average score instead.
+// Estimate polls show the mean and median and the distribution of the estimates.
+// NPS polls show the Net Promoter Score and the share of promoters, passives and detractors.
+// Polls with a quorum state whether it was reached first.
+func (p *Poll) ToResultsSummary(localizer *i18n.Localizer) string {
+	if p.HasQuorum() {
+		return p.makeQuorumText(localizer) + "\n\n" + p.summarizeResults(localizer)
+	}
+	return p.summarizeResults(localizer)
+}
+
+// summarizeResults returns the results summary of the poll according to its vote mode
+func (p *Poll) summarizeResults(localizer *i18n.Localizer) string {
+	if p.IsSurvey() {
+		sections := []string{}
+		for i, q := range p.Questions {
+			counts := p.countVotes(q.AnswerOptions)
+			summary := makeResultsSummary(localizer, q.AnswerOptions, counts, sum(counts), leaders(counts))
+			sections = append(sections, fmt.Sprintf("**%d. %s**\n%s", i+1, q.Question, summary))
+		}
+		return strings.Join(sections, "\n\n")
+	}
+
+	if p.Settings.VoteMode == VoteModeRating {
+		results := p.RatingResults()
+		lines := append([]string{makeWinnersLine(localizer, p.AnswerOptions, bestRated(results))}, p.makeRatingList(localizer, results)...)
+		return strings.Join(lines, "\n")
+	}
+	if p.Settings.VoteMode == VoteModeEstimate {
+		return strings.Join(p.makeEstimateList(localizer), "\n")
+	}
+	if p.IsNPS() {
+		return strings.Join(p.makeNPSList(localizer), "\n")
+	}
+
+	counts, total, winners := p.countResults()
+	return makeResultsSummary(localizer, p.resultOptions(), counts, total, winners)
+}
+
+// makeRatingList returns a numbered line for every answer option of a rating poll, sorted by their average score
+func (p *Poll) makeRatingList(localizer *i18n.Localizer, results []*RatingResult) []string {
+	lines := []string{}
+	for position, i := range sortByAverage(results) {
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollResultsRating,
+			TemplateData: map[string]interface{}{
+				"Position": position + 1,
+				"Answer":   p.AnswerOptions[i].Answer,
+				"Average":  results[i].formatAverage(),
+				"Count":    results[i].Count(),
+			},
+			PluralCount: results[i].Count(),
+		}))
+	}
+	return lines
+}
+
+// resultOptions returns the answer options of the poll followed by its write-ins
+func (p *Poll) resultOptions() []*AnswerOption {
+	return append(append([]*AnswerOption{}, p.AnswerOptions...), p.WriteIns...)
+}
+
+// countResults returns the number of votes of every answer option and write-in, the total the percentages are relative to and the winners
+// according to the vote mode of the poll. Ranked polls count the first preferences.
+func (p *Poll) countResults() (counts []int, total int, winners []int) {
+	switch p.Settings.VoteMode {
+	case VoteModeRanked:
+		counts = make([]int, len(p.AnswerOptions))
+		for i := range p.AnswerOptions {
+			counts[i] = len(p.firstPreferenceVoters(i))
+		}
+		winners = []int{}
+		if _, winner := p.InstantRunoff(); winner != -1 {
+			winners = append(winners, winner)
+		}
+		return counts, len(p.Rankings), winners
+	case VoteModeApproval, VoteModeScheduling:
+		counts = p.countVotes(p.AnswerOptions)
+		return counts, len(p.voters()), leaders(counts)
+	default:
+		counts = p.countVotes(p.resultOptions())
+		return counts, sum(counts), leaders(counts)
+	}
+}
+
+// ToResultsChart returns a bar chart of the results as PNG image. The bars are numbered and ordered like the answer options
+// of ToResultsSummary, so the chart can be read together with the summary. Surveys have no chart.
+func (p *Poll) ToResultsChart() ([]byte, error) {
+	if p.IsSurvey() {
+		return nil, errors.New("surveys have no results chart")
+	}
+	if p.Settings.VoteMode == VoteModeRating {
+		return p.ratingChart()
+	}
+	if p.Settings.VoteMode == VoteModeEstimate {
+		return p.estimateChart()
+	}
+	if p.IsNPS() {
+		return p.npsChart()
+	}
+	counts, total, _ := p.countResults()
+	_, leading := p.resultOrder(p.resultOptions(), nil)
+	bars := []chart.Bar{}
+	for position, i := range sortByVotes(counts) {
+		bars = append(bars, chart.Bar{
+			Label:     strconv.Itoa(position + 1),
+			Value:     counts[i],
+			Caption:   fmt.Sprintf("%d (%d%%)", counts[i], percentage(counts[i], total)),
+			Highlight: leading[i],
+		})
+	}
+	return chart.RenderBarChart(bars)
+}
+
+// ratingChart returns a bar chart of the average scores of a rating poll, ordered like the answer options of ToResultsSummary
+func (p *Poll) ratingChart() ([]byte, error) {
+	results := p.RatingResults()
+	bars := []chart.Bar{}
+	for position, i := range sortByAverage(results) {
+		bars = append(bars, chart.Bar{
+			Label: strconv.Itoa(position + 1),
+			// The chart only draws whole numbers, so the average is scaled to keep its first decimal
+			Value:   int(results[i].Average() * 10),
+			Caption: results[i].formatAverage(),
+		})
+	}
+	return chart.RenderBarChart(bars)
+}
+
+// estimateChart returns a bar chart of the distribution of the estimates of an estimate poll, numbered like the values of ToResultsSummary
+func (p *Poll) estimateChart() ([]byte, error) {
+	r := p.EstimateResults()
+	bars := []chart.Bar{}
+	for position, b := range r.Distribution {
+		bars = append(bars, chart.Bar{
+			Label:   strconv.Itoa(position + 1),
+			Value:   len(b.Voters),
+			Caption: fmt.Sprintf("%d (%d%%)", len(b.Voters), percentage(len(b.Voters), r.Count)),
+		})
+	}
+	return chart.RenderBarChart(bars)
+}
+
+// npsChart returns a bar chart of the promoters, passives and detractors of an NPS poll, numbered like the groups of ToResultsSummary
+func (p *Poll) npsChart() ([]byte, error) {
+	r := p.NPSResults()
+	bars := []chart.Bar{}
+	for position, count := range []int{r.Promoters, r.Passives, r.Detractors} {
+		bars = append(bars, chart.Bar{
+			Label:   strconv.Itoa(position + 1),
+			Value:   count,
+			Caption: fmt.Sprintf("%d (%d%%)", count, percentage(count, r.Total())),
+		})
+	}
+	return chart.RenderBarChart(bars)
+}
+
+// ToDigest returns the number of voters out of a given number of eligible voters followed by the current standings as markdown.
+// The answer options are sorted by their number of votes. Ranked polls show the first preferences.
+// Secret polls only show the number of voters, as their results are hidden until they end.
+func (p *Poll) ToDigest(localizer *i18n.Localizer, numberOfEligibleVoters int) string {
+	voters := p.NumberOfVoters()
+	participation := localizer.MustLocalize(&i18n.LocalizeConfig{
+		DefaultMessage: pollDigestParticipation,
+		TemplateData: map[string]interface{}{
+			"Voters":     voters,
+			"Members":    numberOfEligibleVoters,
+			"Percentage": percentage(voters, numberOfEligibleVoters),
+		},
+	})
+	if p.Settings.Secret {
+		return participation
+	}
+	return participation + "\n\n" + p.ToStandings(localizer)
+}
+
+// ToStandings returns the current standings of the poll as markdown. The answer options are sorted by their number of votes.
+// Ranked polls show the first preferences, rating polls the average scores and estimate polls the statistics of the estimates.
+// NPS polls show the Net Promoter Score. Surveys list the standings of every question.
+func (p *Poll) ToStandings(localizer *i18n.Localizer) string {
+	if p.IsSurvey() {
+		sections := []string{}
+		for i, q := range p.Questions {
+			counts := p.countVotes(q.AnswerOptions)
+			list := makeResultsList(localizer, q.AnswerOptions, counts, sum(counts))
+			sections = append(sections, fmt.Sprintf("**%d. %s**\n%s", i+1, q.Question, strings.Join(list, "\n")))
+		}
+		return strings.Join(sections, "\n\n")
+	}
+
+	if p.Settings.VoteMode == VoteModeRating {
+		return strings.Join(p.makeRatingList(localizer, p.RatingResults()), "\n")
+	}
+	if p.Settings.VoteMode == VoteModeEstimate {
+		return strings.Join(p.makeEstimateList(localizer), "\n")
+	}
+	if p.IsNPS() {
+		return strings.Join(p.makeNPSList(localizer), "\n")
+	}
+
+	counts, total, _ := p.countResults()
+	return strings.Join(makeResultsList(localizer, p.resultOptions(), counts, total), "\n")
+}
+
+// makeResultsSummary returns the winners followed by a numbered list of the answer options, sorted by their number of votes.
+// The percentages are relative to total.
+func makeResultsSummary(localizer *i18n.Localizer, answerOptions []*AnswerOption, counts []int, total int, winners []int) string {
+	lines := []string{makeWinnersLine(localizer, answerOptions, winners)}
+	lines = append(lines, makeResultsList(localizer, answerOptions, counts, total)...)
+	return strings.Join(lines, "\n")
+}
+
+// makeWinnersLine calls out the winner or the tied answer options with the given indices. It states that nobody has voted if there are none.
+func makeWinnersLine(localizer *i18n.Localizer, answerOptions []*AnswerOption, winners []int) string {
+	switch len(winners) {
+	case 0:
+		return localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollResultsNoVotes})
+	case 1:
+		return localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollResultsWinner,
+			TemplateData:   map[string]interface{}{"Answer": answerOptions[winners[0]].Answer},
+		})
+	default:
+		answers := []string{}
+		for _, i := range winners {
+			answers = append(answers, answerOptions[i].Answer)
+		}
+		return localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollResultsTie,
+			TemplateData:   map[string]interface{}{"Answers": strings.Join(answers, ", ")},
+		})
+	}
+}
+
+// makeResultsList returns a numbered line for every answer option, sorted by their number of votes.
+// The percentages are relative to total.
+func makeResultsList(localizer *i18n.Localizer, answerOptions []*AnswerOption, counts []int, total int) []string {
+	lines := []string{}
+	for position, i := range sortByVotes(counts) {
+		lines = append(lines, localizer.MustLocalize(&i18n.LocalizeConfig{
+			DefaultMessage: pollResultsAnswer,
+			TemplateData: map[string]interface{}{
+				"Position":   position + 1,
+				"Answer":     answerOptions[i].Answer,
+				"Count":      counts[i],
+				"Percentage": percentage(counts[i], total),
+			},
+			PluralCount: counts[i],
+		}))
+	}
+	return lines
+}
+
+// sortByVotes returns the indices of the answer options sorted by their number of votes in descending order.
+// Answer options with the same number of votes keep their order.
+func sortByVotes(counts []int) []int {
+	order := make([]int, len(counts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	return order
+}
+
+// percentage returns count as rounded down percentage of total. It's zero if total is zero.
+func percentage(count, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return count * 100 / total
+}
+
+// countVotes returns the number of votes of every answer option, including the votes delegated to its voters
+func (p *Poll) countVotes(answerOptions []*AnswerOption) []int {
+	counts := make([]int, len(answerOptions))
+	for i, o := range answerOptions {
+		counts[i] = p.VotesOf(o)
+	}
+	return counts
+}
+
+// leaders returns the indices of all answer options with the highest number of votes. It's empty if nobody has voted.
+func leaders(counts []int) []int {
+	max := 0
+	for _, c := range counts {
+		if c > max {
+			max = c
+		}
+	}
+	indices := []int{}
+	if max == 0 {
+		return indices
+	}
+	for i, c := range counts {
+		if c == max {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// sum returns the sum of all given numbers
+func sum(numbers []int) int {
+	total := 0
+	for _, n := range numbers {
+		total += n
+	}
+	return total
+}
+
+// ToCSV returns the results of the poll as CSV with one record per answer option and write-in.
+// Ranked polls count the first preferences. Rating polls count the ratings and have an additional column with the average score.
+// Estimate polls have a record per distinct estimate instead.
+// The voters are left out for anonymous polls.
+// Surveys have an additional column with the question of every answer option.
+func (p *Poll) ToCSV(localizer *i18n.Localizer, convert func(string) (string, *model.AppError)) ([]byte, *model.AppError) {
+	header := []string{
+		localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollExportHeaderAnswer}),
+		localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollExportHeaderVotes}),
+	}
+	if p.Settings.VoteMode == VoteModeEstimate {
+		header[0] = localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollExportHeaderEstimate})
+	}
+	if p.Settings.VoteMode == VoteModeRating {
+		header = append(header, localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollExportHeaderAverage}))
+	}
+	if !p.Settings.Anonymous {
+		header = append(header, localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollExportHeaderVoters}))
+	}
+	if p.IsSurvey() {
+		header = append([]string{localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: pollExportHeaderQuestion})}, header...)
+	}
+	records := [][]string{header}
+
+	ratingResults := p.RatingResults()
+	for i, o := range p.AnswerOptions {
+		voters := o.Voter
+		switch p.Settings.VoteMode {
+		case VoteModeRanked:
+			voters = p.firstPreferenceVoters(i)
+		case VoteModeRating:
+			voters = p.raters(i)
+		}
+
+		record, err := p.makeCSVRecord(o.Answer, voters, convert)
+		if err != nil {
+			return nil, err
+		}
+		if p.Settings.VoteMode == VoteModeRating {
+			record = append(record[:2], append([]string{ratingResults[i].formatAverage()}, record[2:]...)...)
+		}
+		records = append(records, record)
+	}
+	if p.Settings.VoteMode == VoteModeEstimate {
+		for _, b := range p.estimateResults(0).Distribution {
+			record, err := p.makeCSVRecord(strconv.FormatFloat(b.From, 'f', -1, 64), b.Voters, convert)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+	}
+	for _, o := range p.WriteIns {
+		record, err := p.makeCSVRecord(o.Answer, o.Voter, convert)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	for _, q := range p.Questions {
+		for _, o := range q.AnswerOptions {
+			record, err := p.makeCSVRecord(o.Answer, o.Voter, convert)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, append([]string{q.Question}, record...))
+		}
+	}
+
+	var b bytes.Buffer
+	_ = csv.NewWriter(&b).WriteAll(records)
+	return b.Bytes(), nil
+}
+
+// makeCSVRecord returns the CSV record of an answer option with the given voters
+func (p *Poll) makeCSVRecord(answer string, voters []string, convert func(string) (string, *model.AppError)) ([]string, *model.AppError) {
+	record := []string{answer, strconv.Itoa(len(voters))}
+	if !p.Settings.Anonymous {
+		names := []string{}
+		for _, userID := range voters {
+			name, err := convert(userID)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+		record = append(record, strings.Join(names, ", "))
+	}
+	return record, nil
+}
+
+// firstPreferenceVoters returns the sorted IDs of all users that ranked the answer option with the given index first
+func (p *Poll) firstPreferenceVoters(index int) []string {
+	voters := []string{}
+	for userID, ranking := range p.Rankings {
+		if len(ranking) > 0 && ranking[0] == index {
+			voters = append(voters, userID)
+		}
+	}
+	sort.Strings(voters)
+	return voters
+}
+
+// raters returns the sorted IDs of all users that rated the answer option with the given index
+func (p *Poll) raters(index int) []string {
+	voters := []string{}
+	for userID, scores := range p.Ratings {
+		if index < len(scores) && scores[index] > 0 {
+			voters = append(voters, userID)
+		}
+	}
+	sort.Strings(voters)
+	return voters
 }
@@ -3,8 +3,11 @@ package poll_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	"bou.ke/monkey"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/chart"
 	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +30,21 @@ func TestPollToEndPollPost(t *testing.T) {
 		}
 	}
 
+	PluginID := "com.github.matterpoll.matterpoll"
+	endActions := []*model.PostAction{{
+		Name: "Export Results",
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/export", testutils.GetSiteURL(), PluginID, testutils.GetPollID()),
+		},
+	}, {
+		Name: "Reopen Poll",
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/reopen", testutils.GetSiteURL(), PluginID, testutils.GetPollID()),
+		},
+	}}
+
 	for name, test := range map[string]struct {
 		Poll                *poll.Poll
 		ExpectedAttachments []*model.SlackAttachment
@@ -50,6 +68,65 @@ func TestPollToEndPollPost(t *testing.T) {
 					Value: "",
 					Short: true,
 				}},
+				Actions: endActions,
+			}},
+		},
+		"Markdown": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.Question = "Which **document** is right?"
+				p.AnswerOptions[0].Answer = "[Spec](https://example.org/spec)"
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "",
+				Text:       "#### Which **document** is right?\nThis poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Spec (3 votes)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "Answer 2 (1 vote)",
+					Value: "@user4",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 votes)",
+					Value: "",
+					Short: true,
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Poll with write-ins": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{AllowOther: true})
+				p.AnswerOptions[1].Voter = nil
+				p.WriteIns = []*poll.AnswerOption{{Answer: "Maybe", Voter: []string{"userID4"}}}
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (3 votes)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "Answer 2 (0 votes)",
+					Value: "",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 votes)",
+					Value: "",
+					Short: true,
+				}, {
+					Title: "Maybe (1 vote)",
+					Value: "@user4",
+					Short: true,
+				}},
+				Actions: endActions,
 			}},
 		},
 		"Anonymous poll": {
@@ -71,6 +148,247 @@ func TestPollToEndPollPost(t *testing.T) {
 					Value: "",
 					Short: true,
 				}},
+				Actions: endActions,
+			}},
+		},
+		"Secret poll": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true}),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (3 votes)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "Answer 2 (1 vote)",
+					Value: "@user4",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 votes)",
+					Value: "",
+					Short: true,
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Approval poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{VoteMode: poll.VoteModeApproval})
+				p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (3 approvals, 75%)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "Answer 2 (2 approvals, 50%)",
+					Value: "@user4 and @user1",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 approvals, 0%)",
+					Value: "",
+					Short: true,
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Scheduling poll": {
+			Poll: testutils.GetSchedulingPoll(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Team lunch",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Best Slot",
+					Value: "2024-06-04 12:00",
+				}, {
+					Title: "2024-06-03 12:00 (2 available, 50%)",
+					Value: "@user1 and @user2",
+					Short: true,
+				}, {
+					Title: "2024-06-04 12:00 (3 available, 75%)",
+					Value: "@user1, @user2 and @user3",
+					Short: true,
+				}, {
+					Title: "2024-06-05 (1 available, 25%)",
+					Value: "@user4",
+					Short: true,
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Scheduling poll, tie": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetSchedulingPoll()
+				p.Settings.Anonymous = true
+				p.AnswerOptions[0].Voter = append(p.AnswerOptions[0].Voter, "userID3")
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Team lunch",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Best Slots",
+					Value: "2024-06-03 12:00\n2024-06-04 12:00",
+				}, {
+					Title: "2024-06-03 12:00 (3 available, 75%)",
+					Value: "",
+					Short: true,
+				}, {
+					Title: "2024-06-04 12:00 (3 available, 75%)",
+					Value: "",
+					Short: true,
+				}, {
+					Title: "2024-06-05 (1 available, 25%)",
+					Value: "",
+					Short: true,
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Ranked poll": {
+			Poll: testutils.GetPollWithRankings(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Winner",
+					Value: "Answer 1",
+				}, {
+					Title: "Round 1",
+					Value: "Answer 1 (2 votes)\nAnswer 2 (1 vote)\nAnswer 3 (1 vote)\nAnswer 3 has been eliminated",
+				}, {
+					Title: "Round 2",
+					Value: "Answer 1 (2 votes)\nAnswer 2 (2 votes)\nAnswer 2 has been eliminated",
+				}, {
+					Title: "Round 3",
+					Value: "Answer 1 (3 votes)",
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Rating poll": {
+			Poll: testutils.GetPollWithRatings(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (4.0 average, 3 ratings)",
+					Value: "★★★★★ 1\n★★★★ 1\n★★★ 1\n★★ 0\n★ 0",
+					Short: true,
+				}, {
+					Title: "Answer 2 (2.5 average, 2 ratings)",
+					Value: "★★★★★ 0\n★★★★ 0\n★★★ 1\n★★ 1\n★ 0",
+					Short: true,
+				}, {
+					Title: "Answer 3 (1.0 average, 1 rating)",
+					Value: "★★★★★ 0\n★★★★ 0\n★★★ 0\n★★ 0\n★ 1",
+					Short: true,
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Estimate poll": {
+			Poll: testutils.GetPollWithEstimates(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Statistics",
+					Value: "Estimates: 4\nMean: 4.75\nMedian: 4\nRange: 3–8",
+					Short: true,
+				}, {
+					Title: "Distribution",
+					Value: "3: ██████████ 2\n5: █████ 1\n8: █████ 1",
+					Short: true,
+				}},
+				Actions: endActions,
+			}},
+		},
+		"Estimate poll, no estimates": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithEstimates()
+				p.Estimates = nil
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Statistics",
+					Value: "Nobody has voted.",
+				}},
+				Actions: endActions,
+			}},
+		},
+		"NPS poll": {
+			Poll: testutils.GetNPSPollWithVotes(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "This poll has ended. The results are:",
+				Fields: []*model.SlackAttachmentField{
+					{Title: "Net Promoter Score", Value: "**+25**\n1. Promoters (9–10): 2 votes (50%)\n2. Passives (7–8): 1 vote (25%)\n3. Detractors (0–6): 1 vote (25%)"},
+					{Title: "0 (0 votes)", Value: "", Short: true},
+					{Title: "1 (0 votes)", Value: "", Short: true},
+					{Title: "2 (0 votes)", Value: "", Short: true},
+					{Title: "3 (1 vote)", Value: "", Short: true},
+					{Title: "4 (0 votes)", Value: "", Short: true},
+					{Title: "5 (0 votes)", Value: "", Short: true},
+					{Title: "6 (0 votes)", Value: "", Short: true},
+					{Title: "7 (0 votes)", Value: "", Short: true},
+					{Title: "8 (1 vote)", Value: "", Short: true},
+					{Title: "9 (1 vote)", Value: "", Short: true},
+					{Title: "10 (1 vote)", Value: "", Short: true},
+				},
+				Actions: endActions,
+			}},
+		},
+		"Survey": {
+			Poll: testutils.GetSurveyWithVotes(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Survey",
+				Text:       "This poll has ended. The results are:",
+				Actions:    endActions,
+			}, {
+				Title: "1. Question 1",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Yes (2 votes)",
+					Value: "@user1 and @user2",
+					Short: true,
+				}, {
+					Title: "No (1 vote)",
+					Value: "@user3",
+					Short: true,
+				}},
+			}, {
+				Title: "2. Question 2",
+				Fields: []*model.SlackAttachmentField{{
+					Title: "Answer 1 (0 votes)",
+					Value: "",
+					Short: true,
+				}, {
+					Title: "Answer 2 (1 vote)",
+					Value: "@user1",
+					Short: true,
+				}, {
+					Title: "Answer 3 (0 votes)",
+					Value: "",
+					Short: true,
+				}},
 			}},
 		},
 	} {
@@ -78,26 +396,157 @@ func TestPollToEndPollPost(t *testing.T) {
 			expectedPost := &model.Post{}
 			model.ParseSlackAttachment(expectedPost, test.ExpectedAttachments)
 
-			post, err := test.Poll.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+			post, err := test.Poll.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), PluginID, "John Doe", converter)
 
 			require.Nil(t, err)
 			assert.Equal(t, expectedPost, post)
 		})
 	}
 
+	t.Run("image options", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.AnswerOptions[0].ImageURL = "https://example.com/a.png"
+		p.AnswerOptions[2].ImageURL = "https://example.com/c.png"
+
+		post, err := p.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), PluginID, "John Doe", converter)
+
+		require.Nil(t, err)
+		attachments := post.Attachments()
+		require.Len(t, attachments, 3)
+		assert.Len(t, attachments[0].Fields, 3)
+		assert.Equal(t, &model.SlackAttachment{Title: "Answer 1", ThumbURL: "https://example.com/a.png"}, attachments[1])
+		assert.Equal(t, &model.SlackAttachment{Title: "Answer 3", ThumbURL: "https://example.com/c.png"}, attachments[2])
+	})
+
+	t.Run("quorum", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			NumberOfEligibleVoters int
+			ExpectedText           string
+		}{
+			"reached": {
+				NumberOfEligibleVoters: 8,
+				ExpectedText:           "**Quorum reached**: 4 of 8 channel members voted.\nThis poll has ended. The results are:",
+			},
+			"not reached": {
+				NumberOfEligibleVoters: 10,
+				ExpectedText:           "**Invalid — quorum not reached**: 4 of 10 channel members voted, but 50% were required.\nThis poll has ended. The results are:",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Quorum: 50})
+				p.NumberOfEligibleVoters = test.NumberOfEligibleVoters
+
+				post, err := p.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), PluginID, "John Doe", converter)
+
+				require.Nil(t, err)
+				attachments := post.Attachments()
+				require.Len(t, attachments, 1)
+				assert.Equal(t, test.ExpectedText, attachments[0].Text)
+				assert.Len(t, attachments[0].Fields, 3)
+			})
+		}
+	})
+
 	t.Run("converter fails", func(t *testing.T) {
 		converter := func(userID string) (string, *model.AppError) {
 			return "", &model.AppError{}
 		}
 		poll := testutils.GetPollWithVotes()
 
-		post, err := poll.ToEndPollPost(testutils.GetLocalizer(), "John Doe", converter)
+		post, err := poll.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), PluginID, "John Doe", converter)
 
 		assert.NotNil(t, err)
 		require.Nil(t, post)
 	})
 }
 
+func TestPollToCSV(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+
+	for name, test := range map[string]struct {
+		Poll        *poll.Poll
+		ExpectedCSV string
+	}{
+		"Normal poll": {
+			Poll: testutils.GetPollWithVotes(),
+			ExpectedCSV: "Answer,Votes,Voters\n" +
+				"Answer 1,3,\"@userID1, @userID2, @userID3\"\n" +
+				"Answer 2,1,@userID4\n" +
+				"Answer 3,0,\n",
+		},
+		"Poll with write-ins": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{AllowOther: true})
+				p.WriteIns = []*poll.AnswerOption{{Answer: "Maybe", Voter: []string{"userID5", "userID6"}}}
+				return p
+			}(),
+			ExpectedCSV: "Answer,Votes,Voters\n" +
+				"Answer 1,3,\"@userID1, @userID2, @userID3\"\n" +
+				"Answer 2,1,@userID4\n" +
+				"Answer 3,0,\n" +
+				"Maybe,2,\"@userID5, @userID6\"\n",
+		},
+		"Anonymous poll": {
+			Poll: testutils.GetPollWithVotesAndSettings(poll.Settings{Anonymous: true}),
+			ExpectedCSV: "Answer,Votes\n" +
+				"Answer 1,3\n" +
+				"Answer 2,1\n" +
+				"Answer 3,0\n",
+		},
+		"Ranked poll": {
+			Poll: testutils.GetPollWithRankings(),
+			ExpectedCSV: "Answer,Votes,Voters\n" +
+				"Answer 1,2,\"@userID1, @userID4\"\n" +
+				"Answer 2,1,@userID2\n" +
+				"Answer 3,1,@userID3\n",
+		},
+		"Rating poll": {
+			Poll: testutils.GetPollWithRatings(),
+			ExpectedCSV: "Answer,Votes,Average Rating,Voters\n" +
+				"Answer 1,3,4.0,\"@userID1, @userID2, @userID3\"\n" +
+				"Answer 2,2,2.5,\"@userID1, @userID2\"\n" +
+				"Answer 3,1,1.0,@userID2\n",
+		},
+		"Estimate poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithEstimates()
+				p.Estimates["userID5"] = 2.5
+				return p
+			}(),
+			ExpectedCSV: "Estimate,Votes,Voters\n" +
+				"2.5,1,@userID5\n" +
+				"3,2,\"@userID2, @userID4\"\n" +
+				"5,1,@userID1\n" +
+				"8,1,@userID3\n",
+		},
+		"Survey": {
+			Poll: testutils.GetSurveyWithVotes(),
+			ExpectedCSV: "Question,Answer,Votes,Voters\n" +
+				"Question 1,Yes,2,\"@userID1, @userID2\"\n" +
+				"Question 1,No,1,@userID3\n" +
+				"Question 2,Answer 1,0,\n" +
+				"Question 2,Answer 2,1,@userID1\n" +
+				"Question 2,Answer 3,0,\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := test.Poll.ToCSV(testutils.GetLocalizer(), converter)
+			require.Nil(t, err)
+			assert.Equal(t, test.ExpectedCSV, string(data))
+		})
+	}
+
+	t.Run("converter fails", func(t *testing.T) {
+		data, err := testutils.GetPollWithVotes().ToCSV(testutils.GetLocalizer(), func(userID string) (string, *model.AppError) {
+			return "", &model.AppError{}
+		})
+		assert.NotNil(t, err)
+		assert.Nil(t, data)
+	})
+}
+
 func TestPollToPostActions(t *testing.T) {
 	PluginID := "com.github.matterpoll.matterpoll"
 	authorName := "John Doe"
@@ -132,115 +581,2288 @@ func TestPollToPostActions(t *testing.T) {
 						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Delete Poll",
+					Name: "Reset My Vote",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "End Poll",
-					Type: model.POST_ACTION_TYPE_BUTTON,
-					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
-					}},
-				},
-			}},
-		},
-		"Multipile questions, settings: progress": {
-			Poll: testutils.GetPollWithSettings(poll.Settings{Progress: true}),
-			ExpectedAttachments: []*model.SlackAttachment{{
-				AuthorName: "John Doe",
-				Title:      "Question",
-				Text:       "---\n**Poll Settings**: progress\n**Total votes**: 0",
-				Actions: []*model.PostAction{{
-					Name: "Answer 1 (0)",
+					Name: "Show Non-Voters",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Answer 2 (0)",
+					Name: "Remind Non-Voters",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Answer 3 (0)",
+					Name: "Delete Poll",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Add Option",
+					Name: "Pause Voting",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Delete Poll",
+					Name: "End Poll",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "End Poll",
+					Name: "Transfer Poll",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
-					},
-				},
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
 				},
 			}},
 		},
-		"Multipile questions, settings: anonymous, public-add-option": {
-			Poll: testutils.GetPollWithSettings(poll.Settings{Anonymous: true, PublicAddOption: true}),
+		"Markdown": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Question = "Which **document** is right?"
+				p.AnswerOptions[0].Answer = "[Spec](https://example.org/spec)"
+				p.AnswerOptions[1].Answer = "`None` :thinking:"
+				return p
+			}(),
 			ExpectedAttachments: []*model.SlackAttachment{{
 				AuthorName: "John Doe",
-				Title:      "Question",
-				Text:       "---\n**Poll Settings**: anonymous, public-add-option\n**Total votes**: 0",
+				Title:      "",
+				Text:       "#### Which **document** is right?\n- [Spec](https://example.org/spec)\n- `None` :thinking:\n---\n**Total votes**: 0",
 				Actions: []*model.PostAction{{
-					Name: "Answer 1",
+					Name: "Spec",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Answer 2",
+					Name: "None :thinking:",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Answer 3",
+					Name: "Add Option",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
-					Name: "Add Option",
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Paginated, first page": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.PageSize = 2
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "Answer options 1 to 2 of 3 (page 1 of 2)\n---\n**Total votes**: 4",
+				Actions: []*model.PostAction{{
+					Name: "Answer 1",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 2",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Next ▶",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/page/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Paginated, last page": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotes()
+				p.PageSize = 2
+				p.Page = 1
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "Answer options 3 to 3 of 3 (page 2 of 2)\n---\n**Total votes**: 4",
+				Actions: []*model.PostAction{{
+					Name: "Answer 3",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "◀ Previous",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/page/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Two options, settings: allow-other, progress": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Settings.AllowOther = true
+				p.Settings.Progress = true
+				p.WriteIns = []*poll.AnswerOption{{Answer: "Maybe", Voter: []string{"userID1"}}}
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: allow-other, progress\n**Total votes**: 1",
+				Actions: []*model.PostAction{{
+					Name: "Yes (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Other… (1)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/other/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Two options, image options": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.AnswerOptions[1].ImageURL = "https://example.com/no.png"
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "Yes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}, {
+				Title:    "No",
+				ThumbURL: "https://example.com/no.png",
+			}},
+		},
+		"Two options, image options, settings: shuffle": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Settings.Shuffle = poll.ShuffleOnce
+				p.ShuffledOrder = []int{1, 0}
+				p.AnswerOptions[0].ImageURL = "https://example.com/yes.png"
+				p.AnswerOptions[1].ImageURL = "https://example.com/no.png"
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: shuffle\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "No",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Yes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}, {
+				Title:    "No",
+				ThumbURL: "https://example.com/no.png",
+			}, {
+				Title:    "Yes",
+				ThumbURL: "https://example.com/yes.png",
+			}},
+		},
+		"Two options, settings: members-only": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Settings.MembersOnly = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: members-only\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "Yes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Two options, settings: lock-votes": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Settings.LockVotes = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: lock-votes\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "Yes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Two options, settings: votes, quorum, notify-at": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Settings.MaxVotes = 3
+				p.Settings.Quorum = 50
+				p.Settings.NotifyAt = 10
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: votes=3, quorum=50%, notify-at=10\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "Yes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Two options, settings: public-votes, truncated voters": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Settings.PublicVotes = true
+				for i := 0; i <= poll.PublicVotersLimit; i++ {
+					p.AnswerOptions[0].Voter = append(p.AnswerOptions[0].Voter, fmt.Sprintf("userID%d", i))
+				}
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: public-votes\n**Total votes**: 11",
+				Actions: []*model.PostAction{{
+					Name: "Yes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show All Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/voters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Two options, settings: anonymous-creator": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollTwoOptions()
+				p.Settings.AnonymousCreator = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: anonymous-creator\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "Yes",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					}},
+				},
+			}},
+		},
+		"Multipile questions, settings: progress": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{Progress: true}),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: progress\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "Answer 1 (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 2 (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 3 (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Multipile questions, settings: progress, secret": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{Progress: true, Secret: true}),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: progress, secret\nThe results are hidden until the poll ends.",
+				Actions: []*model.PostAction{{
+					Name: "Answer 1",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 2",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 3",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Multipile questions, settings: anonymous, public-add-option": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{Anonymous: true, PublicAddOption: true}),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: anonymous, public-add-option\n**Total votes**: 0",
+				Actions: []*model.PostAction{{
+					Name: "Answer 1",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 2",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 3",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Multipile questions, settings: end": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{EndAt: 1717261200000}),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: end=2024-06-01T17:00 UTC\n**Total votes**: 0",
+				// The deadline has passed, but the poll hasn't been ended yet
+				Footer: "Voting closed",
+				Actions: []*model.PostAction{{
+					Name: "Answer 1",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 2",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 3",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Approval poll, settings: progress": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Progress: true, VoteMode: poll.VoteModeApproval})
+				p.AnswerOptions[1].Voter = append(p.AnswerOptions[1].Voter, "userID1")
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "---\n**Poll Settings**: progress, votemode=approval\n**Total votes**: 4",
+				Actions: []*model.PostAction{{
+					Name: "Answer 1 (3)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 2 (2)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 3 (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Scheduling poll, settings: progress, invite, duration": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetSchedulingPoll()
+				p.Settings.Progress = true
+				p.Settings.Invite = true
+				p.Settings.SlotDuration = 90
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Team lunch",
+				Text:       "---\n**Poll Settings**: progress, votemode=scheduling, invite, duration=90m\n**Total votes**: 4",
+				Actions: []*model.PostAction{{
+					Name: "2024-06-03 12:00 (2)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "2024-06-04 12:00 (3)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "2024-06-05 (1)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Ranked poll, settings: progress": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRankings()
+				p.Settings.Progress = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "1. Answer 1 (2)\n2. Answer 2 (1)\n3. Answer 3 (1)\n---\n**Poll Settings**: progress, votemode=ranked\n**Total votes**: 4",
+				Actions: []*model.PostAction{{
+					Name: "Rank Options",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/rank/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Rating poll, settings: progress": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRatings()
+				p.Settings.Progress = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "1. Answer 1 (4.0 ★)\n2. Answer 2 (2.5 ★)\n3. Answer 3 (1.0 ★)\n---\n**Poll Settings**: progress, votemode=rating\n**Total votes**: 3",
+				Actions: []*model.PostAction{{
+					Name: "Rate Options",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/rate/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Estimate poll, settings: progress": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithEstimates()
+				p.Settings.Progress = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "4 estimates, mean 4.75, median 4\n---\n**Poll Settings**: progress, votemode=estimate\n**Total votes**: 4",
+				Actions: []*model.PostAction{{
+					Name: "Submit Estimate",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/estimate/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				},
+				},
+			}},
+		},
+		"Ranked poll, settings: progress, shuffle": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithRankings()
+				p.Settings.Progress = true
+				p.Settings.Shuffle = poll.ShuffleOnce
+				p.ShuffledOrder = []int{2, 0, 1}
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Question",
+				Text:       "1. Answer 3 (1)\n2. Answer 1 (2)\n3. Answer 2 (1)\n---\n**Poll Settings**: progress, shuffle, votemode=ranked\n**Total votes**: 4",
+				Actions: []*model.PostAction{{
+					Name: "Rank Options",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/rank/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Add Option",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
 						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/option/add/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
+				}, {
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
 				}, {
 					Name: "Delete Poll",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				}, {
 					Name: "End Poll",
 					Type: model.POST_ACTION_TYPE_BUTTON,
 					Integration: &model.PostActionIntegration{
-						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
 					},
 				},
 				},
 			}},
 		},
+		"Survey, settings: progress": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetSurveyWithVotes()
+				p.Settings.Progress = true
+				return p
+			}(),
+			ExpectedAttachments: []*model.SlackAttachment{{
+				AuthorName: "John Doe",
+				Title:      "Survey",
+			}, {
+				Title: "1. Question 1",
+				Actions: []*model.PostAction{{
+					Name: "Yes (2)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/survey/0/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "No (1)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/survey/0/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}},
+			}, {
+				Title: "2. Question 2",
+				Actions: []*model.PostAction{{
+					Name: "Answer 1 (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/survey/1/vote/0", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 2 (1)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/survey/1/vote/1", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Answer 3 (0)",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/survey/1/vote/2", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}},
+			}, {
+				Text: "---\n**Poll Settings**: progress\n**Total votes**: 3",
+				Actions: []*model.PostAction{{
+					Name: "Reset My Vote",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/vote/reset", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Show Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/nonvoters", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Remind Non-Voters",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/remind", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Delete Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/delete/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Pause Voting",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/pause", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "End Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/end/confirm/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}, {
+					Name: "Transfer Poll",
+					Type: model.POST_ACTION_TYPE_BUTTON,
+					Integration: &model.PostActionIntegration{
+						URL: fmt.Sprintf("%s/plugins/%s/api/%s/polls/%s/transfer/request", testutils.GetSiteURL(), PluginID, currentAPIVersion, testutils.GetPollID()),
+					},
+				}},
+			}},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			assert.Equal(t, test.ExpectedAttachments, test.Poll.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), PluginID, authorName))
 		})
 	}
 }
+
+func TestPollToResultsSummary(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll            *poll.Poll
+		ExpectedSummary string
+	}{
+		"Normal poll": {
+			Poll: testutils.GetPollWithVotes(),
+			ExpectedSummary: "**Winner**: Answer 1\n" +
+				"1. Answer 1: 3 votes (75%)\n" +
+				"2. Answer 2: 1 vote (25%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+		"Sorted by votes": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPoll()
+				p.AnswerOptions[1].Voter = []string{"userID1"}
+				p.AnswerOptions[2].Voter = []string{"userID2", "userID3"}
+				return p
+			}(),
+			ExpectedSummary: "**Winner**: Answer 3\n" +
+				"1. Answer 3: 2 votes (66%)\n" +
+				"2. Answer 2: 1 vote (33%)\n" +
+				"3. Answer 1: 0 votes (0%)",
+		},
+		"Poll with write-ins": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{AllowOther: true})
+				p.WriteIns = []*poll.AnswerOption{
+					{Answer: "Maybe", Voter: []string{"userID5"}},
+					{Answer: "Tomorrow", Voter: []string{"userID6", "userID7"}},
+				}
+				return p
+			}(),
+			ExpectedSummary: "**Winner**: Answer 1\n" +
+				"1. Answer 1: 3 votes (42%)\n" +
+				"2. Tomorrow: 2 votes (28%)\n" +
+				"3. Answer 2: 1 vote (14%)\n" +
+				"4. Maybe: 1 vote (14%)\n" +
+				"5. Answer 3: 0 votes (0%)",
+		},
+		"Tie": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPoll()
+				p.AnswerOptions[0].Voter = []string{"userID1"}
+				p.AnswerOptions[2].Voter = []string{"userID2"}
+				return p
+			}(),
+			ExpectedSummary: "**Tie**: Answer 1, Answer 3\n" +
+				"1. Answer 1: 1 vote (50%)\n" +
+				"2. Answer 3: 1 vote (50%)\n" +
+				"3. Answer 2: 0 votes (0%)",
+		},
+		"Quorum not reached": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithVotesAndSettings(poll.Settings{Quorum: 60})
+				p.NumberOfEligibleVoters = 10
+				return p
+			}(),
+			ExpectedSummary: "**Invalid — quorum not reached**: 4 of 10 channel members voted, but 60% were required.\n\n" +
+				"**Winner**: Answer 1\n" +
+				"1. Answer 1: 3 votes (75%)\n" +
+				"2. Answer 2: 1 vote (25%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+		"No votes": {
+			Poll: testutils.GetPoll(),
+			ExpectedSummary: "Nobody has voted.\n" +
+				"1. Answer 1: 0 votes (0%)\n" +
+				"2. Answer 2: 0 votes (0%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+		"Approval poll": {
+			Poll: func() *poll.Poll {
+				p := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeApproval})
+				p.AnswerOptions[0].Voter = []string{"userID1", "userID2"}
+				p.AnswerOptions[1].Voter = []string{"userID1"}
+				return p
+			}(),
+			ExpectedSummary: "**Winner**: Answer 1\n" +
+				"1. Answer 1: 2 votes (100%)\n" +
+				"2. Answer 2: 1 vote (50%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+		"Scheduling poll": {
+			Poll: testutils.GetSchedulingPoll(),
+			ExpectedSummary: "**Winner**: 2024-06-04 12:00\n" +
+				"1. 2024-06-04 12:00: 3 votes (75%)\n" +
+				"2. 2024-06-03 12:00: 2 votes (50%)\n" +
+				"3. 2024-06-05: 1 vote (25%)",
+		},
+		"Ranked poll": {
+			Poll: testutils.GetPollWithRankings(),
+			ExpectedSummary: "**Winner**: Answer 1\n" +
+				"1. Answer 1: 2 votes (50%)\n" +
+				"2. Answer 2: 1 vote (25%)\n" +
+				"3. Answer 3: 1 vote (25%)",
+		},
+		"Rating poll": {
+			Poll: testutils.GetPollWithRatings(),
+			ExpectedSummary: "**Winner**: Answer 1\n" +
+				"1. Answer 1: 4.0 average (3 ratings)\n" +
+				"2. Answer 2: 2.5 average (2 ratings)\n" +
+				"3. Answer 3: 1.0 average (1 rating)",
+		},
+		"Estimate poll": {
+			Poll: testutils.GetPollWithEstimates(),
+			ExpectedSummary: "**Mean**: 4.75, **Median**: 4 (4 estimates from 3 to 8)\n" +
+				"1. 3: 2 votes (50%)\n" +
+				"2. 5: 1 vote (25%)\n" +
+				"3. 8: 1 vote (25%)",
+		},
+		"Estimate poll, no estimates": {
+			Poll:            testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate}),
+			ExpectedSummary: "Nobody has voted.",
+		},
+		"NPS poll": {
+			Poll: testutils.GetNPSPollWithVotes(),
+			ExpectedSummary: "**Net Promoter Score**: +25\n" +
+				"1. Promoters (9–10): 2 votes (50%)\n" +
+				"2. Passives (7–8): 1 vote (25%)\n" +
+				"3. Detractors (0–6): 1 vote (25%)",
+		},
+		"NPS poll, no votes": {
+			Poll: func() *poll.Poll {
+				p, _ := poll.NewNPSPoll("userID1", "Question", nil)
+				return p
+			}(),
+			ExpectedSummary: "Nobody has voted.",
+		},
+		"Rating poll, no ratings": {
+			Poll: testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating}),
+			ExpectedSummary: "Nobody has voted.\n" +
+				"1. Answer 1: 0.0 average (0 ratings)\n" +
+				"2. Answer 2: 0.0 average (0 ratings)\n" +
+				"3. Answer 3: 0.0 average (0 ratings)",
+		},
+		"Survey": {
+			Poll: testutils.GetSurveyWithVotes(),
+			ExpectedSummary: "**1. Question 1**\n" +
+				"**Winner**: Yes\n" +
+				"1. Yes: 2 votes (66%)\n" +
+				"2. No: 1 vote (33%)\n\n" +
+				"**2. Question 2**\n" +
+				"**Winner**: Answer 2\n" +
+				"1. Answer 2: 1 vote (100%)\n" +
+				"2. Answer 1: 0 votes (0%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedSummary, test.Poll.ToResultsSummary(testutils.GetLocalizer()))
+		})
+	}
+}
+
+func TestPollToDigest(t *testing.T) {
+	for name, test := range map[string]struct {
+		Poll           *poll.Poll
+		ExpectedDigest string
+	}{
+		"Normal poll": {
+			Poll: testutils.GetPollWithVotes(),
+			ExpectedDigest: "**Participation**: 4 of 5 channel members voted (80%).\n\n" +
+				"1. Answer 1: 3 votes (75%)\n" +
+				"2. Answer 2: 1 vote (25%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+		"No votes": {
+			Poll: testutils.GetPoll(),
+			ExpectedDigest: "**Participation**: 0 of 5 channel members voted (0%).\n\n" +
+				"1. Answer 1: 0 votes (0%)\n" +
+				"2. Answer 2: 0 votes (0%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+		"Secret poll": {
+			Poll:           testutils.GetPollWithVotesAndSettings(poll.Settings{Secret: true}),
+			ExpectedDigest: "**Participation**: 4 of 5 channel members voted (80%).",
+		},
+		"Ranked poll": {
+			Poll: testutils.GetPollWithRankings(),
+			ExpectedDigest: "**Participation**: 4 of 5 channel members voted (80%).\n\n" +
+				"1. Answer 1: 2 votes (50%)\n" +
+				"2. Answer 2: 1 vote (25%)\n" +
+				"3. Answer 3: 1 vote (25%)",
+		},
+		"Rating poll": {
+			Poll: testutils.GetPollWithRatings(),
+			ExpectedDigest: "**Participation**: 3 of 5 channel members voted (60%).\n\n" +
+				"1. Answer 1: 4.0 average (3 ratings)\n" +
+				"2. Answer 2: 2.5 average (2 ratings)\n" +
+				"3. Answer 3: 1.0 average (1 rating)",
+		},
+		"Survey": {
+			Poll: testutils.GetSurveyWithVotes(),
+			ExpectedDigest: "**Participation**: 3 of 5 channel members voted (60%).\n\n" +
+				"**1. Question 1**\n" +
+				"1. Yes: 2 votes (66%)\n" +
+				"2. No: 1 vote (33%)\n\n" +
+				"**2. Question 2**\n" +
+				"1. Answer 2: 1 vote (100%)\n" +
+				"2. Answer 1: 0 votes (0%)\n" +
+				"3. Answer 3: 0 votes (0%)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedDigest, test.Poll.ToDigest(testutils.GetLocalizer(), 5))
+		})
+	}
+}
+
+func TestPollToStandings(t *testing.T) {
+	assert.Equal(t, "1. Answer 1: 3 votes (75%)\n2. Answer 2: 1 vote (25%)\n3. Answer 3: 0 votes (0%)", testutils.GetPollWithVotes().ToStandings(testutils.GetLocalizer()))
+	assert.Equal(t, "1. Answer 1: 4.0 average (3 ratings)\n2. Answer 2: 2.5 average (2 ratings)\n3. Answer 3: 1.0 average (1 rating)", testutils.GetPollWithRatings().ToStandings(testutils.GetLocalizer()))
+	assert.Equal(t, "**Mean**: 4.75, **Median**: 4 (4 estimates from 3 to 8)\n1. 3: 2 votes (50%)\n2. 5: 1 vote (25%)\n3. 8: 1 vote (25%)", testutils.GetPollWithEstimates().ToStandings(testutils.GetLocalizer()))
+	assert.Equal(t, "**Net Promoter Score**: +25\n1. Promoters (9–10): 2 votes (50%)\n2. Passives (7–8): 1 vote (25%)\n3. Detractors (0–6): 1 vote (25%)", testutils.GetNPSPollWithVotes().ToStandings(testutils.GetLocalizer()))
+}
+
+func TestPollToPostActionsVoteToSee(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{VoteToSee: true, Progress: true})
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Equal(t, "---\n**Poll Settings**: progress, vote-to-see\nThe results are shown to you once you voted.", attachments[0].Text)
+	// The progress is hidden, so the counts don't show up on the buttons
+	assert.Equal(t, "Answer 1", attachments[0].Actions[0].Name)
+	assert.Equal(t, "Show Results", attachments[0].Actions[4].Name)
+	assert.Equal(t, fmt.Sprintf("%s/plugins/pluginID/api/v1/polls/%s/results", testutils.GetSiteURL(), testutils.GetPollID()), attachments[0].Actions[4].Integration.URL)
+}
+
+func TestPollToPostActionsCompact(t *testing.T) {
+	t.Run("compact setting", func(t *testing.T) {
+		p := testutils.GetPollWithVotesAndSettings(poll.Settings{Compact: true, Progress: true})
+
+		attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+		assert.Equal(t, &model.PostAction{
+			Name: "Choose an answer option",
+			Type: model.POST_ACTION_TYPE_SELECT,
+			Options: []*model.PostActionOptions{
+				{Text: "Answer 1 (3)", Value: "0"},
+				{Text: "Answer 2 (1)", Value: "1"},
+				{Text: "Answer 3 (0)", Value: "2"},
+			},
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/pluginID/api/v1/polls/%s/vote/select", testutils.GetSiteURL(), testutils.GetPollID()),
+			},
+		}, attachments[0].Actions[0])
+		assert.Equal(t, "Add Option", attachments[0].Actions[1].Name)
+	})
+	t.Run("more answer options than CompactAbove", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.CompactAbove = 2
+		p.PageSize = 2
+
+		attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+		// All answer options are listed in the select menu, so there are no pages
+		assert.Equal(t, "---\n**Total votes**: 4", attachments[0].Text)
+		assert.Equal(t, model.POST_ACTION_TYPE_SELECT, attachments[0].Actions[0].Type)
+		assert.Len(t, attachments[0].Actions[0].Options, 3)
+		assert.Equal(t, "Add Option", attachments[0].Actions[1].Name)
+	})
+	t.Run("lock votes", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Compact: true, LockVotes: true})
+
+		attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+		assert.Equal(t, fmt.Sprintf("%s/plugins/pluginID/api/v1/polls/%s/vote/select/confirm/request", testutils.GetSiteURL(), testutils.GetPollID()), attachments[0].Actions[0].Integration.URL)
+	})
+}
+
+func TestPollToPostActionsNPS(t *testing.T) {
+	p := testutils.GetNPSPollWithVotes()
+	p.Settings.Progress = true
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Equal(t, "**Net Promoter Score**: +25\n---\n**Poll Settings**: anonymous, progress\n**Total votes**: 4", attachments[0].Text)
+	assert.Equal(t, "0 (0)", attachments[0].Actions[0].Name)
+	assert.Equal(t, "10 (1)", attachments[0].Actions[10].Name)
+	// The scores are fixed, so there is no button to add answer options
+	for _, action := range attachments[0].Actions {
+		assert.NotEqual(t, "Add Option", action.Name)
+	}
+}
+
+func TestPollToPostActionsDescriptions(t *testing.T) {
+	t.Run("single vote", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.AnswerOptions[1].Description = "The *second* answer"
+
+		attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+		assert.Equal(t, "- Answer 1\n- Answer 2\n  The *second* answer\n- Answer 3\n---\n**Total votes**: 0", attachments[0].Text)
+		// The buttons stay short
+		assert.Equal(t, "Answer 2", attachments[0].Actions[1].Name)
+	})
+	t.Run("ranked", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked})
+		p.AnswerOptions[0].Description = "The first answer"
+
+		attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+		assert.Contains(t, attachments[0].Text, "1. Answer 1\n  The first answer\n2. Answer 2\n3. Answer 3\n")
+	})
+}
+
+func TestPollToPostActionsCountdown(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1717261200000 })
+	defer patch.Unpatch()
+	millis := func(d time.Duration) int64 { return int64(d / time.Millisecond) }
+
+	for name, test := range map[string]struct {
+		EndAt          int64
+		ExpectedFooter string
+	}{
+		"no deadline":         {EndAt: 0, ExpectedFooter: ""},
+		"days left":           {EndAt: 1717261200000 + millis(50*time.Hour), ExpectedFooter: "Ends in 2d"},
+		"hours left":          {EndAt: 1717261200000 + millis(3*time.Hour+59*time.Minute), ExpectedFooter: "Ends in 3h"},
+		"minutes left":        {EndAt: 1717261200000 + millis(45*time.Minute), ExpectedFooter: "Ends in 45m"},
+		"seconds left":        {EndAt: 1717261200000 + millis(10*time.Second), ExpectedFooter: "Ends in 1m"},
+		"deadline has passed": {EndAt: 1717261200000 - 1, ExpectedFooter: "Voting closed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{EndAt: test.EndAt})
+
+			attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+			assert.Equal(t, test.ExpectedFooter, attachments[0].Footer)
+		})
+	}
+
+	t.Run("survey", func(t *testing.T) {
+		survey := testutils.GetSurveyWithVotes()
+		survey.Settings.EndAt = 1717261200000 + millis(3*time.Hour)
+
+		attachments := survey.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+		assert.Equal(t, "Ends in 3h", attachments[len(attachments)-1].Footer)
+	})
+}
+
+func TestPollToPostActionsPaused(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1717261200000 })
+	defer patch.Unpatch()
+	millis := func(d time.Duration) int64 { return int64(d / time.Millisecond) }
+
+	for name, test := range map[string]struct {
+		EndAt          int64
+		ExpectedFooter string
+	}{
+		"no deadline":         {EndAt: 0, ExpectedFooter: "Voting paused"},
+		"hours left":          {EndAt: 1717261200000 + millis(3*time.Hour), ExpectedFooter: "Voting paused · Ends in 3h"},
+		"deadline has passed": {EndAt: 1717261200000 - 1, ExpectedFooter: "Voting closed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{EndAt: test.EndAt})
+			p.Pause()
+
+			attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+			assert.Equal(t, test.ExpectedFooter, attachments[0].Footer)
+		})
+	}
+
+	t.Run("resume button", func(t *testing.T) {
+		p := testutils.GetPoll()
+		p.Pause()
+
+		attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+		assert.Contains(t, attachments[0].Actions, &model.PostAction{
+			Name: "Resume Voting",
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/%s/api/v1/polls/%s/resume", testutils.GetSiteURL(), "pluginID", testutils.GetPollID()),
+			},
+		})
+		for _, action := range attachments[0].Actions {
+			assert.NotEqual(t, "Pause Voting", action.Name)
+		}
+	})
+}
+
+func TestPollNextCountdownChange(t *testing.T) {
+	now := int64(1717261200000)
+	millis := func(d time.Duration) int64 { return int64(d / time.Millisecond) }
+
+	for name, test := range map[string]struct {
+		EndAt            int64
+		ExpectedChangeAt int64
+	}{
+		"no deadline":         {EndAt: 0, ExpectedChangeAt: 0},
+		"days left":           {EndAt: now + millis(50*time.Hour), ExpectedChangeAt: now + millis(2*time.Hour) + 1},
+		"exactly one day":     {EndAt: now + millis(24*time.Hour), ExpectedChangeAt: now + 1},
+		"hours left":          {EndAt: now + millis(3*time.Hour+59*time.Minute), ExpectedChangeAt: now + millis(59*time.Minute) + 1},
+		"minutes left":        {EndAt: now + millis(45*time.Minute), ExpectedChangeAt: now + millis(time.Minute)},
+		"partial minute left": {EndAt: now + millis(45*time.Minute+10*time.Second), ExpectedChangeAt: now + millis(10*time.Second)},
+		"last minute":         {EndAt: now + millis(10*time.Second), ExpectedChangeAt: now + millis(10*time.Second)},
+		"deadline is now":     {EndAt: now, ExpectedChangeAt: 0},
+		"deadline has passed": {EndAt: now - 1, ExpectedChangeAt: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := testutils.GetPollWithSettings(poll.Settings{EndAt: test.EndAt})
+			assert.Equal(t, test.ExpectedChangeAt, p.NextCountdownChange(now))
+		})
+	}
+}
+
+func TestPollToPostActionsMaxPerOption(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{MaxPerOption: 3})
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Contains(t, attachments[0].Text, "**Poll Settings**: max-per-option=3")
+	assert.Equal(t, "Answer 1 (full)", attachments[0].Actions[0].Name)
+	assert.Equal(t, "Answer 2", attachments[0].Actions[1].Name)
+}
+
+func TestPollMakeVoterFields(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		if userID == "" {
+			return "", &model.AppError{}
+		}
+		return "@" + userID, nil
+	}
+
+	t.Run("all voters", func(t *testing.T) {
+		fields, err := testutils.GetPollWithVotes().MakeVoterFields(testutils.GetLocalizer(), 0, converter)
+
+		require.Nil(t, err)
+		assert.Equal(t, []*model.SlackAttachmentField{
+			{Short: true, Title: "Answer 1", Value: "@userID1, @userID2 and @userID3"},
+			{Short: true, Title: "Answer 2", Value: "@userID4"},
+			{Short: true, Title: "Answer 3", Value: ""},
+		}, fields)
+	})
+	t.Run("truncated voters", func(t *testing.T) {
+		fields, err := testutils.GetPollWithVotes().MakeVoterFields(testutils.GetLocalizer(), 1, converter)
+
+		require.Nil(t, err)
+		assert.Equal(t, []*model.SlackAttachmentField{
+			{Short: true, Title: "Answer 1", Value: "@userID1 and 2 more"},
+			{Short: true, Title: "Answer 2", Value: "@userID4"},
+			{Short: true, Title: "Answer 3", Value: ""},
+		}, fields)
+	})
+	t.Run("converter fails", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.AnswerOptions[1].Voter = []string{""}
+
+		fields, err := p.MakeVoterFields(testutils.GetLocalizer(), 0, converter)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, fields)
+	})
+}
+
+func TestPollPageVoters(t *testing.T) {
+	for name, test := range map[string]struct {
+		Page           int
+		PerPage        int
+		ExpectedGroups []*poll.VoterGroup
+	}{
+		"all voters on one page": {
+			Page:    0,
+			PerPage: 10,
+			ExpectedGroups: []*poll.VoterGroup{
+				{Answer: "Answer 1", Votes: 3, Voter: []string{"userID1", "userID2", "userID3"}},
+				{Answer: "Answer 2", Votes: 1, Voter: []string{"userID4"}},
+			},
+		},
+		"answer option split between pages": {
+			Page:    0,
+			PerPage: 2,
+			ExpectedGroups: []*poll.VoterGroup{
+				{Answer: "Answer 1", Votes: 3, Voter: []string{"userID1", "userID2"}},
+			},
+		},
+		"second page": {
+			Page:    1,
+			PerPage: 2,
+			ExpectedGroups: []*poll.VoterGroup{
+				{Answer: "Answer 1", Votes: 3, Voter: []string{"userID3"}},
+				{Answer: "Answer 2", Votes: 1, Voter: []string{"userID4"}},
+			},
+		},
+		"page after the last one": {
+			Page:           2,
+			PerPage:        2,
+			ExpectedGroups: []*poll.VoterGroup{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			groups, total := testutils.GetPollWithVotes().PageVoters(test.Page, test.PerPage)
+
+			assert.Equal(t, test.ExpectedGroups, groups)
+			assert.Equal(t, 4, total)
+		})
+	}
+	t.Run("weighted votes", func(t *testing.T) {
+		p := testutils.GetPollWithVotes()
+		p.Settings.Weights = map[string]int{"userID4": 3}
+
+		groups, total := p.PageVoters(1, 3)
+
+		assert.Equal(t, []*poll.VoterGroup{{Answer: "Answer 2", Votes: 3, Voter: []string{"userID4"}}}, groups)
+		assert.Equal(t, 4, total)
+	})
+}
+
+func TestPollToResultsChart(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		data, err := testutils.GetPollWithVotes().ToResultsChart()
+		require.Nil(t, err)
+
+		expected, err := chart.RenderBarChart([]chart.Bar{
+			{Label: "1", Value: 3, Caption: "3 (75%)"},
+			{Label: "2", Value: 1, Caption: "1 (25%)"},
+			{Label: "3", Value: 0, Caption: "0 (0%)"},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, expected, data)
+	})
+	t.Run("ranked poll", func(t *testing.T) {
+		data, err := testutils.GetPollWithRankings().ToResultsChart()
+		require.Nil(t, err)
+
+		expected, err := chart.RenderBarChart([]chart.Bar{
+			{Label: "1", Value: 2, Caption: "2 (50%)"},
+			{Label: "2", Value: 1, Caption: "1 (25%)"},
+			{Label: "3", Value: 1, Caption: "1 (25%)"},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, expected, data)
+	})
+	t.Run("rating poll", func(t *testing.T) {
+		p := testutils.GetPollWithRatings()
+		p.Ratings["userID3"] = []int{3, 0, 5}
+		data, err := p.ToResultsChart()
+		require.Nil(t, err)
+
+		expected, err := chart.RenderBarChart([]chart.Bar{
+			{Label: "1", Value: 40, Caption: "4.0"},
+			{Label: "2", Value: 30, Caption: "3.0"},
+			{Label: "3", Value: 25, Caption: "2.5"},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, expected, data)
+	})
+	t.Run("estimate poll", func(t *testing.T) {
+		data, err := testutils.GetPollWithEstimates().ToResultsChart()
+		require.Nil(t, err)
+
+		expected, err := chart.RenderBarChart([]chart.Bar{
+			{Label: "1", Value: 2, Caption: "2 (50%)"},
+			{Label: "2", Value: 1, Caption: "1 (25%)"},
+			{Label: "3", Value: 1, Caption: "1 (25%)"},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, expected, data)
+	})
+	t.Run("NPS poll", func(t *testing.T) {
+		data, err := testutils.GetNPSPollWithVotes().ToResultsChart()
+		require.Nil(t, err)
+
+		expected, err := chart.RenderBarChart([]chart.Bar{
+			{Label: "1", Value: 2, Caption: "2 (50%)"},
+			{Label: "2", Value: 1, Caption: "1 (25%)"},
+			{Label: "3", Value: 1, Caption: "1 (25%)"},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, expected, data)
+	})
+	t.Run("survey", func(t *testing.T) {
+		data, err := testutils.GetSurveyWithVotes().ToResultsChart()
+
+		assert.NotNil(t, err)
+		assert.Nil(t, data)
+	})
+}
@@ -0,0 +1,86 @@
+package poll
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// WeightRoleCreator is the key of Settings.Weights that weights the votes of the creator of a poll
+	WeightRoleCreator = "creator"
+	// WeightRoleModerators is the key of Settings.Weights that weights the votes of the moderators of a poll
+	WeightRoleModerators = "moderators"
+	// MaxWeight is the highest number of votes a single vote may count for
+	MaxWeight = 100
+)
+
+// parseWeights returns the weights of a comma separated list like @alice:3,moderators:2.
+// Users are prefixed by @, while creator and moderators weight everyone with that role in the poll.
+func parseWeights(value string) (map[string]int, error) {
+	weights := map[string]int{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, ":")
+		if i == -1 {
+			return nil, fmt.Errorf("Invalid weight %s. Use @username:N, creator:N or moderators:N", entry)
+		}
+		key := strings.TrimSpace(entry[:i])
+		weight, err := strconv.Atoi(strings.TrimSpace(entry[i+1:]))
+		if err != nil || weight < 1 || weight > MaxWeight {
+			return nil, fmt.Errorf("Invalid weight %s. It must be a number between 1 and %d", entry, MaxWeight)
+		}
+		if key != WeightRoleCreator && key != WeightRoleModerators && (!strings.HasPrefix(key, "@") || len(key) == 1) {
+			return nil, fmt.Errorf("Invalid weight %s. Use @username:N, creator:N or moderators:N", entry)
+		}
+		weights[key] = weight
+	}
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("Invalid weights %s", value)
+	}
+	return weights, nil
+}
+
+// ResolveWeights replaces the usernames in the weights of the poll by the user IDs returned by lookup. The weights of roles are kept.
+func (p *Poll) ResolveWeights(lookup func(username string) (string, error)) error {
+	if len(p.Settings.Weights) == 0 {
+		return nil
+	}
+	weights := map[string]int{}
+	for key, weight := range p.Settings.Weights {
+		if !strings.HasPrefix(key, "@") {
+			weights[key] = weight
+			continue
+		}
+		userID, err := lookup(strings.TrimPrefix(key, "@"))
+		if err != nil {
+			return err
+		}
+		weights[userID] = weight
+	}
+	p.Settings.Weights = weights
+	return nil
+}
+
+// IsWeighted returns true if the votes of some users count more than once
+func (p *Poll) IsWeighted() bool {
+	return len(p.Settings.Weights) > 0
+}
+
+// WeightOf returns the number of votes the own vote of a given user counts for.
+// A weight of the user takes precedence over the weights of the roles creator and moderators. Everybody else counts once.
+func (p *Poll) WeightOf(userID string) int {
+	if weight, ok := p.Settings.Weights[userID]; ok {
+		return weight
+	}
+	if weight, ok := p.Settings.Weights[WeightRoleCreator]; ok && userID == p.Creator {
+		return weight
+	}
+	if weight, ok := p.Settings.Weights[WeightRoleModerators]; ok && p.IsModerator(userID) {
+		return weight
+	}
+	return 1
+}
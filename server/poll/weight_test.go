@@ -0,0 +1,95 @@
+package poll_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveWeights(t *testing.T) {
+	lookup := func(username string) (string, error) {
+		if username == "user2" {
+			return "userID2", nil
+		}
+		return "", fmt.Errorf("unknown user %s", username)
+	}
+
+	t.Run("all fine", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Weights: map[string]int{"@user2": 3, poll.WeightRoleModerators: 2}})
+
+		require.Nil(t, p.ResolveWeights(lookup))
+		assert.Equal(t, map[string]int{"userID2": 3, poll.WeightRoleModerators: 2}, p.Settings.Weights)
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		p := testutils.GetPollWithSettings(poll.Settings{Weights: map[string]int{"@user3": 3}})
+
+		assert.NotNil(t, p.ResolveWeights(lookup))
+	})
+	t.Run("no weights", func(t *testing.T) {
+		p := testutils.GetPoll()
+
+		require.Nil(t, p.ResolveWeights(lookup))
+		assert.Nil(t, p.Settings.Weights)
+	})
+}
+
+func TestWeightOf(t *testing.T) {
+	p := testutils.GetPollWithSettings(poll.Settings{
+		Moderators: []string{"userID2", "userID3"},
+		Weights:    map[string]int{"userID3": 5, poll.WeightRoleModerators: 2, poll.WeightRoleCreator: 4},
+	})
+
+	assert.True(t, p.IsWeighted())
+	assert.Equal(t, 4, p.WeightOf("userID1"))
+	assert.Equal(t, 2, p.WeightOf("userID2"))
+	// The weight of the user takes precedence over the weight of their role
+	assert.Equal(t, 5, p.WeightOf("userID3"))
+	assert.Equal(t, 1, p.WeightOf("userID4"))
+
+	assert.False(t, testutils.GetPoll().IsWeighted())
+	assert.Equal(t, 1, testutils.GetPoll().WeightOf("userID1"))
+}
+
+func TestWeightedVotes(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Weights: map[string]int{"userID1": 3, "userID4": 2, "userID5": 4}})
+
+	assert.Equal(t, 5, p.VotesOf(p.AnswerOptions[0]))
+	assert.Equal(t, 2, p.VotesOf(p.AnswerOptions[1]))
+
+	// Delegators pass on their weight
+	p.Delegations = map[string]string{"userID5": "userID4"}
+	assert.Equal(t, 6, p.VoteWeight("userID4"))
+	assert.Equal(t, 6, p.VotesOf(p.AnswerOptions[1]))
+}
+
+func TestPollToPostActionsWeighted(t *testing.T) {
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Progress: true, Weights: map[string]int{"userID1": 3}})
+
+	attachments := p.ToPostActions(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe")
+	assert.Equal(t, "---\n**Poll Settings**: progress, weights\n**Total votes**: 6 weighted, cast by 4 voters", attachments[0].Text)
+	assert.Equal(t, "Answer 1 (5)", attachments[0].Actions[0].Name)
+	assert.Equal(t, "Answer 2 (1)", attachments[0].Actions[1].Name)
+}
+
+func TestPollToEndPollPostWeighted(t *testing.T) {
+	converter := func(userID string) (string, *model.AppError) {
+		return "@" + userID, nil
+	}
+	p := testutils.GetPollWithVotesAndSettings(poll.Settings{Weights: map[string]int{"userID1": 3}})
+
+	post, err := p.ToEndPollPost(testutils.GetLocalizer(), testutils.GetSiteURL(), "pluginID", "John Doe", converter)
+
+	require.Nil(t, err)
+	attachments := post.Attachments()
+	assert.Equal(t, "This poll has ended. The results are:\n**Total votes**: 6 weighted, cast by 4 voters", attachments[0].Text)
+	assert.Equal(t, []*model.SlackAttachmentField{
+		{Short: true, Title: "Answer 1 (5 weighted votes, 83%, 3 by headcount)", Value: "@userID1, @userID2 and @userID3"},
+		{Short: true, Title: "Answer 2 (1 weighted vote, 16%, 1 by headcount)", Value: "@userID4"},
+		{Short: true, Title: "Answer 3 (0 weighted votes, 0%, 0 by headcount)", Value: ""},
+	}, attachments[0].Fields)
+}
@@ -0,0 +1,171 @@
+package stats
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+const (
+	// DayLayout is the layout of the days in VotesPerDay
+	DayLayout = "2006-01-02"
+	// VotesPerDayRetention is the number of days for which VotesPerDay keeps the number of votes
+	VotesPerDayRetention = 90
+)
+
+// Stats stores aggregate statistics about all polls.
+// They are updated whenever a poll changes, hence they don't require to load every poll.
+type Stats struct {
+	// PollsPerChannel counts the polls per channel ID
+	PollsPerChannel map[string]int
+	// VotersPerChannel sums the voters of all polls per channel ID
+	VotersPerChannel map[string]int
+	// PollsPerCreator counts the polls per user ID of their creator
+	PollsPerCreator map[string]int
+	// VotesPerDay counts the votes cast per day in UTC. Votes are counted once per voter and poll, hence changed votes don't count again.
+	VotesPerDay map[string]int
+}
+
+// Count is the number of polls of a channel or creator
+type Count struct {
+	ID    string
+	Count int
+}
+
+// New returns empty statistics
+func New() *Stats {
+	return &Stats{
+		PollsPerChannel:  map[string]int{},
+		VotersPerChannel: map[string]int{},
+		PollsPerCreator:  map[string]int{},
+		VotesPerDay:      map[string]int{},
+	}
+}
+
+// FromPolls returns the statistics of a given list of polls.
+// The days on which votes were cast aren't stored in polls, hence VotesPerDay stays empty.
+func FromPolls(polls []*poll.Poll) *Stats {
+	s := New()
+	for _, p := range polls {
+		s.add(p)
+	}
+	return s
+}
+
+// Record updates the statistics with a change of a poll at a given time in milliseconds.
+// before is nil for polls that got created and after is nil for polls that got deleted.
+func (s *Stats) Record(before, after *poll.Poll, now int64) {
+	if before != nil {
+		s.remove(before)
+	}
+	if after != nil {
+		s.add(after)
+	}
+	if before == nil || after == nil {
+		return
+	}
+
+	if votes := after.NumberOfVoters() - before.NumberOfVoters(); votes > 0 {
+		day := time.Unix(0, now*int64(time.Millisecond)).UTC()
+		s.VotesPerDay[day.Format(DayLayout)] += votes
+		s.pruneVotesPerDay(day)
+	}
+}
+
+// Affects returns true if a change of a poll changes the statistics.
+// before is nil for polls that got created and after is nil for polls that got deleted.
+func Affects(before, after *poll.Poll) bool {
+	if before == nil || after == nil {
+		return before != after
+	}
+	return before.ChannelID != after.ChannelID || before.Creator != after.Creator || before.NumberOfVoters() != after.NumberOfVoters()
+}
+
+// NumberOfPolls returns the number of all polls
+func (s *Stats) NumberOfPolls() int {
+	n := 0
+	for _, count := range s.PollsPerChannel {
+		n += count
+	}
+	return n
+}
+
+// Top returns the n IDs with the highest counts of a given map, highest first. IDs with the same count are sorted by ID.
+func Top(counts map[string]int, n int) []Count {
+	result := []Count{}
+	for id, count := range counts {
+		result = append(result, Count{ID: id, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].ID < result[j].ID
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// add counts a given poll
+func (s *Stats) add(p *poll.Poll) {
+	if p.ChannelID != "" {
+		s.PollsPerChannel[p.ChannelID]++
+		if voters := p.NumberOfVoters(); voters > 0 {
+			s.VotersPerChannel[p.ChannelID] += voters
+		}
+	}
+	s.PollsPerCreator[p.Creator]++
+}
+
+// remove stops counting a given poll. Counts never drop below zero, so polls that were never counted don't corrupt the statistics.
+func (s *Stats) remove(p *poll.Poll) {
+	if p.ChannelID != "" {
+		decrement(s.PollsPerChannel, p.ChannelID, 1)
+		decrement(s.VotersPerChannel, p.ChannelID, p.NumberOfVoters())
+	}
+	decrement(s.PollsPerCreator, p.Creator, 1)
+}
+
+// pruneVotesPerDay removes the days that are older than VotesPerDayRetention days before a given day
+func (s *Stats) pruneVotesPerDay(today time.Time) {
+	oldest := today.AddDate(0, 0, -VotesPerDayRetention+1).Format(DayLayout)
+	for day := range s.VotesPerDay {
+		// Days in DayLayout sort chronologically
+		if day < oldest {
+			delete(s.VotesPerDay, day)
+		}
+	}
+}
+
+// decrement decreases the count of a given key and removes the key once the count drops to zero
+func decrement(counts map[string]int, key string, n int) {
+	if counts[key] <= n {
+		delete(counts, key)
+		return
+	}
+	counts[key] -= n
+}
+
+// EncodeToByte returns the statistics as a byte array
+func (s *Stats) EncodeToByte() []byte {
+	b, _ := json.Marshal(s)
+	return b
+}
+
+// DecodeStatsFromByte tries to create statistics from a byte array. Missing maps are initialized.
+func DecodeStatsFromByte(b []byte) *Stats {
+	s := New()
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil
+	}
+	for _, m := range []*map[string]int{&s.PollsPerChannel, &s.VotersPerChannel, &s.PollsPerCreator, &s.VotesPerDay} {
+		if *m == nil {
+			*m = map[string]int{}
+		}
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package stats_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// millisPerDay is the number of milliseconds of a day
+const millisPerDay = 24 * 60 * 60 * 1000
+
+func getPollInChannel(channelID string, votes bool) *poll.Poll {
+	p := testutils.GetPoll()
+	if votes {
+		p = testutils.GetPollWithVotes()
+	}
+	p.ChannelID = channelID
+	return p
+}
+
+func TestStatsRecord(t *testing.T) {
+	t.Run("poll created", func(t *testing.T) {
+		s := stats.New()
+		s.Record(nil, getPollInChannel("channelID1", false), 1234567890)
+
+		assert.Equal(t, map[string]int{"channelID1": 1}, s.PollsPerChannel)
+		assert.Equal(t, map[string]int{}, s.VotersPerChannel)
+		assert.Equal(t, map[string]int{"userID1": 1}, s.PollsPerCreator)
+		assert.Equal(t, map[string]int{}, s.VotesPerDay)
+		assert.Equal(t, 1, s.NumberOfPolls())
+	})
+
+	t.Run("votes cast", func(t *testing.T) {
+		s := stats.New()
+		s.Record(nil, getPollInChannel("channelID1", false), 1234567890)
+		s.Record(getPollInChannel("channelID1", false), getPollInChannel("channelID1", true), 1234567890)
+
+		assert.Equal(t, map[string]int{"channelID1": 1}, s.PollsPerChannel)
+		assert.Equal(t, map[string]int{"channelID1": 4}, s.VotersPerChannel)
+		assert.Equal(t, map[string]int{"userID1": 1}, s.PollsPerCreator)
+		assert.Equal(t, map[string]int{"1970-01-15": 4}, s.VotesPerDay)
+	})
+
+	t.Run("votes reset", func(t *testing.T) {
+		s := stats.New()
+		s.Record(nil, getPollInChannel("channelID1", true), 1234567890)
+		s.Record(getPollInChannel("channelID1", true), getPollInChannel("channelID1", false), 1234567890)
+
+		assert.Equal(t, map[string]int{"channelID1": 1}, s.PollsPerChannel)
+		assert.Equal(t, map[string]int{}, s.VotersPerChannel)
+		assert.Equal(t, map[string]int{}, s.VotesPerDay)
+	})
+
+	t.Run("poll deleted", func(t *testing.T) {
+		s := stats.New()
+		s.Record(nil, getPollInChannel("channelID1", true), 1234567890)
+		s.Record(nil, getPollInChannel("channelID2", false), 1234567890)
+		s.Record(getPollInChannel("channelID1", true), nil, 1234567890)
+
+		assert.Equal(t, map[string]int{"channelID2": 1}, s.PollsPerChannel)
+		assert.Equal(t, map[string]int{}, s.VotersPerChannel)
+		assert.Equal(t, map[string]int{"userID1": 1}, s.PollsPerCreator)
+	})
+
+	t.Run("poll that was never counted gets deleted", func(t *testing.T) {
+		s := stats.New()
+		s.Record(getPollInChannel("channelID1", true), nil, 1234567890)
+
+		assert.Equal(t, stats.New(), s)
+	})
+
+	t.Run("old days are pruned", func(t *testing.T) {
+		s := stats.New()
+		s.VotesPerDay["1969-10-17"] = 2
+		s.VotesPerDay["1969-10-18"] = 3
+		s.Record(getPollInChannel("channelID1", false), getPollInChannel("channelID1", true), 1234567890)
+
+		assert.Equal(t, map[string]int{"1969-10-18": 3, "1970-01-15": 4}, s.VotesPerDay)
+	})
+}
+
+func TestAffects(t *testing.T) {
+	otherCreator := getPollInChannel("channelID1", false)
+	otherCreator.Creator = "userID2"
+	otherQuestion := getPollInChannel("channelID1", false)
+	otherQuestion.Question = "Other question"
+
+	for name, test := range map[string]struct {
+		Before   *poll.Poll
+		After    *poll.Poll
+		Expected bool
+	}{
+		"poll created":     {Before: nil, After: getPollInChannel("channelID1", false), Expected: true},
+		"poll deleted":     {Before: getPollInChannel("channelID1", false), After: nil, Expected: true},
+		"votes cast":       {Before: getPollInChannel("channelID1", false), After: getPollInChannel("channelID1", true), Expected: true},
+		"channel changed":  {Before: getPollInChannel("", false), After: getPollInChannel("channelID1", false), Expected: true},
+		"creator changed":  {Before: getPollInChannel("channelID1", false), After: otherCreator, Expected: true},
+		"question changed": {Before: getPollInChannel("channelID1", false), After: otherQuestion, Expected: false},
+		"nothing happened": {Before: nil, After: nil, Expected: false},
+		"nothing changed":  {Before: getPollInChannel("channelID1", true), After: getPollInChannel("channelID1", true), Expected: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, stats.Affects(test.Before, test.After))
+		})
+	}
+}
+
+func TestFromPolls(t *testing.T) {
+	s := stats.FromPolls([]*poll.Poll{
+		getPollInChannel("channelID1", true),
+		getPollInChannel("channelID1", false),
+		getPollInChannel("channelID2", true),
+	})
+
+	assert.Equal(t, map[string]int{"channelID1": 2, "channelID2": 1}, s.PollsPerChannel)
+	assert.Equal(t, map[string]int{"channelID1": 4, "channelID2": 4}, s.VotersPerChannel)
+	assert.Equal(t, map[string]int{"userID1": 3}, s.PollsPerCreator)
+	assert.Equal(t, map[string]int{}, s.VotesPerDay)
+	assert.Equal(t, 3, s.NumberOfPolls())
+}
+
+func TestTop(t *testing.T) {
+	counts := map[string]int{"a": 1, "b": 3, "c": 2, "d": 3}
+
+	assert.Equal(t, []stats.Count{{ID: "b", Count: 3}, {ID: "d", Count: 3}, {ID: "c", Count: 2}}, stats.Top(counts, 3))
+	assert.Equal(t, 4, len(stats.Top(counts, 10)))
+	assert.Equal(t, []stats.Count{}, stats.Top(map[string]int{}, 10))
+}
+
+func TestStatsEncodeDecode(t *testing.T) {
+	s := stats.New()
+	s.Record(nil, getPollInChannel("channelID1", true), 1234567890+millisPerDay)
+
+	assert.Equal(t, s, stats.DecodeStatsFromByte(s.EncodeToByte()))
+	assert.Equal(t, stats.New(), stats.DecodeStatsFromByte([]byte(`{"PollsPerChannel":null}`)))
+	assert.Nil(t, stats.DecodeStatsFromByte([]byte{}))
+}
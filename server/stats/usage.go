@@ -0,0 +1,57 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// UsageWindowDays is the number of days the recent activity in Usage covers
+const UsageWindowDays = 30
+
+// Usage summarizes how polls are used on a server. It only contains counts, no IDs, users or content of polls.
+type Usage struct {
+	Polls        int `json:"polls"`
+	RunningPolls int `json:"running_polls"`
+	Surveys      int `json:"surveys"`
+	// RecentPolls is the number of polls created within the last UsageWindowDays days
+	RecentPolls int `json:"recent_polls"`
+	// Voters sums the voters of all polls
+	Voters int `json:"voters"`
+	// RecentVotes is the number of votes cast within the last UsageWindowDays days, see Stats.VotesPerDay
+	RecentVotes int `json:"recent_votes"`
+	// Settings counts the polls per Poll Setting they use, see poll.Poll.SettingNames
+	Settings map[string]int `json:"settings"`
+}
+
+// NewUsage returns the usage of the given polls at a given time in milliseconds. The recent votes are taken from the given statistics.
+func NewUsage(polls []*poll.Poll, s *Stats, now int64) *Usage {
+	u := &Usage{Settings: map[string]int{}}
+	recent := now - int64(UsageWindowDays*24*time.Hour/time.Millisecond)
+	for _, p := range polls {
+		u.Polls++
+		if !p.IsEnded() {
+			u.RunningPolls++
+		}
+		if p.IsSurvey() {
+			u.Surveys++
+		}
+		if p.CreatedAt >= recent {
+			u.RecentPolls++
+		}
+		u.Voters += p.NumberOfVoters()
+		for _, name := range p.SettingNames() {
+			u.Settings[name]++
+		}
+	}
+
+	today := time.Unix(0, now*int64(time.Millisecond)).UTC()
+	oldest := today.AddDate(0, 0, -UsageWindowDays+1).Format(DayLayout)
+	for day, votes := range s.VotesPerDay {
+		// Days in DayLayout sort chronologically
+		if day >= oldest {
+			u.RecentVotes += votes
+		}
+	}
+	return u
+}
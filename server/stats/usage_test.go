@@ -0,0 +1,46 @@
+package stats_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUsage(t *testing.T) {
+	// 2009-03-01 00:00 UTC
+	now := int64(1235865600000)
+
+	oldPoll := testutils.GetPollWithVotes()
+	oldPoll.CreatedAt = now - 40*millisPerDay
+	oldPoll.EndedAt = now - 39*millisPerDay
+	recentPoll := testutils.GetPollWithSettings(poll.Settings{Anonymous: true, Progress: true})
+	recentPoll.CreatedAt = now - millisPerDay
+	survey := &poll.Poll{
+		CreatedAt: now,
+		Question:  "Survey",
+		Questions: []*poll.Question{{Question: "Question 1", AnswerOptions: []*poll.AnswerOption{{Answer: "Yes"}, {Answer: "No"}}}},
+		Settings:  poll.Settings{Anonymous: true},
+	}
+
+	s := stats.New()
+	s.VotesPerDay = map[string]int{"2009-03-01": 2, "2009-01-31": 3, "2009-01-30": 5}
+
+	usage := stats.NewUsage([]*poll.Poll{oldPoll, recentPoll, survey}, s, now)
+	assert.Equal(t, &stats.Usage{
+		Polls:        3,
+		RunningPolls: 2,
+		Surveys:      1,
+		RecentPolls:  2,
+		Voters:       oldPoll.NumberOfVoters(),
+		RecentVotes:  5,
+		Settings:     map[string]int{"anonymous": 2, "progress": 1},
+	}, usage)
+}
+
+func TestNewUsageWithoutPolls(t *testing.T) {
+	usage := stats.NewUsage([]*poll.Poll{}, stats.New(), 1234567890)
+	assert.Equal(t, &stats.Usage{Settings: map[string]int{}}, usage)
+}
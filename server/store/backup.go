@@ -0,0 +1,97 @@
+package store
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/pkg/errors"
+)
+
+// BackupVersion is the version of the backup format. It changes once backups can't be restored by older plugin versions.
+const BackupVersion = 1
+
+// Backup holds all polls, including their votes and settings, jobs and audit entries of a store
+type Backup struct {
+	Version       int            `json:"version"`
+	Polls         []*poll.Poll   `json:"polls"`
+	ArchivedPolls []*poll.Poll   `json:"archived_polls"`
+	Jobs          []*job.Job     `json:"jobs"`
+	AuditEntries  []*audit.Entry `json:"audit_entries"`
+}
+
+// NewBackup returns a backup of all data in a given store
+func NewBackup(s Store) (*Backup, error) {
+	polls, err := s.Poll().List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list polls")
+	}
+	archived, err := s.Poll().ListArchived()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list archived polls")
+	}
+	jobs, err := s.Job().List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list jobs")
+	}
+	entries, err := s.Audit().List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list audit entries")
+	}
+	return &Backup{
+		Version:       BackupVersion,
+		Polls:         polls,
+		ArchivedPolls: archived,
+		Jobs:          jobs,
+		AuditEntries:  entries,
+	}, nil
+}
+
+// DecodeBackup reads a backup in the JSON format. It returns an error if the backup was made by a newer plugin version.
+func DecodeBackup(r io.Reader) (*Backup, error) {
+	b := &Backup{}
+	if err := json.NewDecoder(r).Decode(b); err != nil {
+		return nil, errors.Wrap(err, "failed to decode backup")
+	}
+	if b.Version < 1 || b.Version > BackupVersion {
+		return nil, errors.Errorf("unsupported backup version %d", b.Version)
+	}
+	for _, p := range append(append([]*poll.Poll{}, b.Polls...), b.ArchivedPolls...) {
+		if p == nil || p.ID == "" {
+			return nil, errors.New("backup contains a poll without ID")
+		}
+	}
+	return b, nil
+}
+
+// Restore saves all polls, jobs and audit entries of the backup in a given store.
+// Archived polls get archived in the store as well.
+// Polls, jobs and audit entries that already exist in the store get overwritten.
+func (b *Backup) Restore(to Store) error {
+	for _, p := range b.Polls {
+		if err := to.Poll().Save(p); err != nil {
+			return errors.Wrapf(err, "failed to save poll %s", p.ID)
+		}
+	}
+	for _, p := range b.ArchivedPolls {
+		if err := to.Poll().Save(p); err != nil {
+			return errors.Wrapf(err, "failed to save poll %s", p.ID)
+		}
+		if err := to.Poll().Archive(p); err != nil {
+			return errors.Wrapf(err, "failed to archive poll %s", p.ID)
+		}
+	}
+	for _, j := range b.Jobs {
+		if err := to.Job().Save(j); err != nil {
+			return errors.Wrapf(err, "failed to save job %s", j.ID)
+		}
+	}
+	for _, e := range b.AuditEntries {
+		if err := to.Audit().Save(e); err != nil {
+			return errors.Wrapf(err, "failed to save audit entry %s", e.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package store_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBackup(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll2 := testutils.GetPollWithVotes()
+	poll2.ID = "pollID2"
+	poll2.EndedAt = 1234567890
+	job1 := job.NewJob(job.TypeEndPoll, poll1.ID, 1234567890)
+	entry1 := audit.NewEntry(poll1.ID, poll1.Creator, audit.ActionPollCreated, "")
+
+	for name, test := range map[string]struct {
+		SetupStore     func(*mockstore.Store) *mockstore.Store
+		ExpectedBackup *store.Backup
+	}{
+		"all fine": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("List").Return([]*poll.Poll{poll1}, nil)
+				s.PollStore.On("ListArchived").Return([]*poll.Poll{poll2}, nil)
+				s.JobStore.On("List").Return([]*job.Job{job1}, nil)
+				s.AuditStore.On("List").Return([]*audit.Entry{entry1}, nil)
+				return s
+			},
+			ExpectedBackup: &store.Backup{
+				Version:       store.BackupVersion,
+				Polls:         []*poll.Poll{poll1},
+				ArchivedPolls: []*poll.Poll{poll2},
+				Jobs:          []*job.Job{job1},
+				AuditEntries:  []*audit.Entry{entry1},
+			},
+		},
+		"PollStore.List fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("List").Return(nil, errors.New(""))
+				return s
+			},
+		},
+		"PollStore.ListArchived fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("List").Return([]*poll.Poll{poll1}, nil)
+				s.PollStore.On("ListArchived").Return(nil, errors.New(""))
+				return s
+			},
+		},
+		"JobStore.List fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("List").Return([]*poll.Poll{poll1}, nil)
+				s.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				s.JobStore.On("List").Return(nil, errors.New(""))
+				return s
+			},
+		},
+		"AuditStore.List fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("List").Return([]*poll.Poll{poll1}, nil)
+				s.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				s.JobStore.On("List").Return([]*job.Job{}, nil)
+				s.AuditStore.On("List").Return(nil, errors.New(""))
+				return s
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := test.SetupStore(&mockstore.Store{})
+			defer s.AssertExpectations(t)
+
+			b, err := store.NewBackup(s)
+			if test.ExpectedBackup == nil {
+				assert.NotNil(t, err)
+				assert.Nil(t, b)
+			} else {
+				require.Nil(t, err)
+				assert.Equal(t, test.ExpectedBackup, b)
+			}
+		})
+	}
+}
+
+func TestDecodeBackup(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		archived := testutils.GetPollWithVotes()
+		archived.ID = "pollID2"
+		archived.EndedAt = 1234567890
+		backup := &store.Backup{
+			Version:       store.BackupVersion,
+			Polls:         []*poll.Poll{testutils.GetPollWithVotes()},
+			ArchivedPolls: []*poll.Poll{archived},
+			Jobs:          []*job.Job{job.NewJob(job.TypeEndPoll, testutils.GetPollID(), 1234567890)},
+			AuditEntries:  []*audit.Entry{},
+		}
+		data, err := json.Marshal(backup)
+		require.Nil(t, err)
+
+		decoded, err := store.DecodeBackup(bytes.NewReader(data))
+		require.Nil(t, err)
+		assert.Equal(t, backup, decoded)
+	})
+
+	for name, data := range map[string]string{
+		"invalid JSON":       `{"version":`,
+		"missing version":    `{"polls":[]}`,
+		"newer version":      `{"version":2,"polls":[]}`,
+		"poll without ID":    `{"version":1,"polls":[{"Question":"Question"}]}`,
+		"null archived poll": `{"version":1,"archived_polls":[null]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := store.DecodeBackup(strings.NewReader(data))
+			assert.NotNil(t, err)
+			assert.Nil(t, decoded)
+		})
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll2 := testutils.GetPollWithVotes()
+	poll2.ID = "pollID2"
+	poll2.EndedAt = 1234567890
+	job1 := job.NewJob(job.TypeEndPoll, poll1.ID, 1234567890)
+	entry1 := audit.NewEntry(poll1.ID, poll1.Creator, audit.ActionPollCreated, "")
+	backup := &store.Backup{
+		Version:       store.BackupVersion,
+		Polls:         []*poll.Poll{poll1},
+		ArchivedPolls: []*poll.Poll{poll2},
+		Jobs:          []*job.Job{job1},
+		AuditEntries:  []*audit.Entry{entry1},
+	}
+
+	for name, test := range map[string]struct {
+		SetupStore  func(*mockstore.Store) *mockstore.Store
+		ShouldError bool
+	}{
+		"all fine": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("Save", poll1).Return(nil)
+				s.PollStore.On("Save", poll2).Return(nil)
+				s.PollStore.On("Archive", poll2).Return(nil)
+				s.JobStore.On("Save", job1).Return(nil)
+				s.AuditStore.On("Save", entry1).Return(nil)
+				return s
+			},
+			ShouldError: false,
+		},
+		"PollStore.Save fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("Save", poll1).Return(errors.New(""))
+				return s
+			},
+			ShouldError: true,
+		},
+		"PollStore.Archive fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("Save", poll1).Return(nil)
+				s.PollStore.On("Save", poll2).Return(nil)
+				s.PollStore.On("Archive", poll2).Return(errors.New(""))
+				return s
+			},
+			ShouldError: true,
+		},
+		"JobStore.Save fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("Save", poll1).Return(nil)
+				s.PollStore.On("Save", poll2).Return(nil)
+				s.PollStore.On("Archive", poll2).Return(nil)
+				s.JobStore.On("Save", job1).Return(errors.New(""))
+				return s
+			},
+			ShouldError: true,
+		},
+		"AuditStore.Save fails": {
+			SetupStore: func(s *mockstore.Store) *mockstore.Store {
+				s.PollStore.On("Save", poll1).Return(nil)
+				s.PollStore.On("Save", poll2).Return(nil)
+				s.PollStore.On("Archive", poll2).Return(nil)
+				s.JobStore.On("Save", job1).Return(nil)
+				s.AuditStore.On("Save", entry1).Return(errors.New(""))
+				return s
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := test.SetupStore(&mockstore.Store{})
+			defer s.AssertExpectations(t)
+
+			err := backup.Restore(s)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package store
+
+// Copy saves all polls, jobs and audit entries from one store in another store.
+// Archived polls get archived in the destination store as well.
+// Polls, jobs and audit entries that already exist in the destination store get overwritten.
+func Copy(from, to Store) error {
+	b, err := NewBackup(from)
+	if err != nil {
+		return err
+	}
+	return b.Restore(to)
+}
@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopy(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll2 := testutils.GetPollWithVotes()
+	poll2.ID = "pollID2"
+	poll3 := testutils.GetPollWithVotes()
+	poll3.ID = "pollID3"
+	poll3.EndedAt = 1234567890
+	job1 := job.NewJob(job.TypeEndPoll, poll1.ID, 1234567890)
+	entry1 := audit.NewEntry(poll1.ID, poll1.Creator, audit.ActionPollCreated, "")
+
+	for name, test := range map[string]struct {
+		SetupFrom   func(*mockstore.Store) *mockstore.Store
+		SetupTo     func(*mockstore.Store) *mockstore.Store
+		ShouldError bool
+	}{
+		"all fine": {
+			SetupFrom: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{poll1, poll2}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{poll3}, nil)
+				store.JobStore.On("List").Return([]*job.Job{job1}, nil)
+				store.AuditStore.On("List").Return([]*audit.Entry{entry1}, nil)
+				return store
+			},
+			SetupTo: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll1).Return(nil)
+				store.PollStore.On("Save", poll2).Return(nil)
+				store.PollStore.On("Save", poll3).Return(nil)
+				store.PollStore.On("Archive", poll3).Return(nil)
+				store.JobStore.On("Save", job1).Return(nil)
+				store.AuditStore.On("Save", entry1).Return(nil)
+				return store
+			},
+			ShouldError: false,
+		},
+		"empty store": {
+			SetupFrom: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.JobStore.On("List").Return([]*job.Job{}, nil)
+				store.AuditStore.On("List").Return([]*audit.Entry{}, nil)
+				return store
+			},
+			SetupTo:     func(store *mockstore.Store) *mockstore.Store { return store },
+			ShouldError: false,
+		},
+		"listing fails": {
+			SetupFrom: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return(nil, errors.New(""))
+				return store
+			},
+			SetupTo:     func(store *mockstore.Store) *mockstore.Store { return store },
+			ShouldError: true,
+		},
+		"saving fails": {
+			SetupFrom: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("List").Return([]*poll.Poll{poll1, poll2}, nil)
+				store.PollStore.On("ListArchived").Return([]*poll.Poll{}, nil)
+				store.JobStore.On("List").Return([]*job.Job{}, nil)
+				store.AuditStore.On("List").Return([]*audit.Entry{}, nil)
+				return store
+			},
+			SetupTo: func(store *mockstore.Store) *mockstore.Store {
+				store.PollStore.On("Save", poll1).Return(errors.New(""))
+				return store
+			},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			from := test.SetupFrom(&mockstore.Store{})
+			defer from.AssertExpectations(t)
+			to := test.SetupTo(&mockstore.Store{})
+			defer to.AssertExpectations(t)
+
+			err := store.Copy(from, to)
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/audit"
+)
+
+// AuditStore allows to access the audit trail of polls in the KV Store.
+// All entries of a poll are stored under a single key, oldest first.
+type AuditStore struct {
+	api plugin.API
+}
+
+const auditPrefix = "audit_"
+
+// List returns the entries of all polls.
+func (s *AuditStore) List() ([]*audit.Entry, error) {
+	entries := []*audit.Entry{}
+	for page := 0; ; page++ {
+		keys, err := s.api.KVList(page, listPerPage)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if !strings.HasPrefix(key, auditPrefix) {
+				continue
+			}
+			pollEntries, err := s.ListByPoll(strings.TrimPrefix(key, auditPrefix))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, pollEntries...)
+		}
+
+		if len(keys) < listPerPage {
+			return entries, nil
+		}
+	}
+}
+
+// ListByPoll returns the entries of a given poll, oldest first. Returns an empty list if there are none.
+func (s *AuditStore) ListByPoll(pollID string) ([]*audit.Entry, error) {
+	entries, _, err := s.get(pollID)
+	return entries, err
+}
+
+// Save appends an entry to the audit trail of its poll. Overwrittes any existing entry with the same id.
+func (s *AuditStore) Save(entry *audit.Entry) error {
+	return s.update(entry.PollID, func(entries []*audit.Entry) ([]*audit.Entry, bool) {
+		newEntries := []*audit.Entry{}
+		for _, e := range entries {
+			if e.ID != entry.ID {
+				newEntries = append(newEntries, e)
+			}
+		}
+		return append(newEntries, entry), true
+	})
+}
+
+// AnonymizeUser removes a given user from the entries of all polls, so their actions show up as anonymous.
+func (s *AuditStore) AnonymizeUser(userID string) error {
+	for page := 0; ; page++ {
+		keys, err := s.api.KVList(page, listPerPage)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if !strings.HasPrefix(key, auditPrefix) {
+				continue
+			}
+			err := s.update(strings.TrimPrefix(key, auditPrefix), func(entries []*audit.Entry) ([]*audit.Entry, bool) {
+				changed := false
+				for _, e := range entries {
+					if e.UserID == userID {
+						e.UserID = ""
+						changed = true
+					}
+				}
+				return entries, changed
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(keys) < listPerPage {
+			return nil
+		}
+	}
+}
+
+// update applies a given change to the latest entries of a poll, so concurrent updates don't get lost.
+// Nothing is stored if the change reports that it left the entries as they are.
+func (s *AuditStore) update(pollID string, change func(entries []*audit.Entry) ([]*audit.Entry, bool)) error {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		entries, oldValue, err := s.get(pollID)
+		if err != nil {
+			return err
+		}
+
+		newEntries, changed := change(entries)
+		if !changed {
+			return nil
+		}
+		newValue, err := json.Marshal(newEntries)
+		if err != nil {
+			return err
+		}
+		ok, appErr := s.api.KVCompareAndSet(auditPrefix+pollID, oldValue, newValue)
+		if appErr != nil {
+			return appErr
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errors.New("too many concurrent updates")
+}
+
+// get returns the entries of a given poll and the raw value they were decoded from.
+func (s *AuditStore) get(pollID string) ([]*audit.Entry, []byte, error) {
+	b, appErr := s.api.KVGet(auditPrefix + pollID)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	entries := []*audit.Entry{}
+	if b == nil {
+		return entries, nil, nil
+	}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, nil, err
+	}
+	return entries, b, nil
+}
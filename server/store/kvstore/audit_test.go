@@ -0,0 +1,183 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeEntries(t *testing.T, entries ...*audit.Entry) []byte {
+	b, err := json.Marshal(entries)
+	require.Nil(t, err)
+	return b
+}
+
+func TestAuditStoreList(t *testing.T) {
+	e1 := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567890}
+	e2 := &audit.Entry{ID: "entryID2", PollID: "pollID1", UserID: "userID2", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567891}
+	e3 := &audit.Entry{ID: "entryID3", PollID: "pollID2", Action: audit.ActionPollEnded, CreatedAt: 1234567892}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + "pollID1", auditPrefix + "pollID1", auditPrefix + "pollID2"}, nil)
+		api.On("KVGet", auditPrefix+"pollID1").Return(encodeEntries(t, e1, e2), nil)
+		api.On("KVGet", auditPrefix+"pollID2").Return(encodeEntries(t, e3), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		entries, err := store.Audit().List()
+		require.Nil(t, err)
+		assert.Equal(t, []*audit.Entry{e1, e2, e3}, entries)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		entries, err := store.Audit().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, entries)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{auditPrefix + "pollID1"}, nil)
+		api.On("KVGet", auditPrefix+"pollID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		entries, err := store.Audit().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, entries)
+	})
+}
+
+func TestAuditStoreListByPoll(t *testing.T) {
+	e1 := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567890}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return(encodeEntries(t, e1), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		entries, err := store.Audit().ListByPoll("pollID1")
+		require.Nil(t, err)
+		assert.Equal(t, []*audit.Entry{e1}, entries)
+	})
+	t.Run("no entries", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		entries, err := store.Audit().ListByPoll("pollID1")
+		require.Nil(t, err)
+		assert.Equal(t, []*audit.Entry{}, entries)
+	})
+	t.Run("Decode fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return([]byte("{"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		entries, err := store.Audit().ListByPoll("pollID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, entries)
+	})
+}
+
+func TestAuditStoreSave(t *testing.T) {
+	e1 := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567890}
+	e2 := &audit.Entry{ID: "entryID2", PollID: "pollID1", UserID: "userID2", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567891}
+
+	t.Run("first entry", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return(nil, nil)
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", []byte(nil), encodeEntries(t, e1)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Audit().Save(e1))
+	})
+	t.Run("entry is appended", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return(encodeEntries(t, e1), nil)
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", encodeEntries(t, e1), encodeEntries(t, e1, e2)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Audit().Save(e2))
+	})
+	t.Run("existing entry is overwritten", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return(encodeEntries(t, e1, e2), nil)
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", encodeEntries(t, e1, e2), encodeEntries(t, e2, e1)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Audit().Save(e1))
+	})
+	t.Run("concurrent save", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return(nil, nil).Once()
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", []byte(nil), encodeEntries(t, e2)).Return(false, nil)
+		api.On("KVGet", auditPrefix+"pollID1").Return(encodeEntries(t, e1), nil).Once()
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", encodeEntries(t, e1), encodeEntries(t, e1, e2)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Audit().Save(e2))
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", auditPrefix+"pollID1").Return(nil, nil)
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", []byte(nil), encodeEntries(t, e1)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Audit().Save(e1))
+	})
+}
+
+func TestAuditStoreAnonymizeUser(t *testing.T) {
+	e1 := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567890}
+	e2 := &audit.Entry{ID: "entryID2", PollID: "pollID1", UserID: "userID2", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567891}
+	e3 := &audit.Entry{ID: "entryID3", PollID: "pollID2", UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567892}
+	anonymized := &audit.Entry{ID: "entryID2", PollID: "pollID1", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567891}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + "pollID1", auditPrefix + "pollID1", auditPrefix + "pollID2"}, nil)
+		api.On("KVGet", auditPrefix+"pollID1").Return(encodeEntries(t, e1, e2), nil)
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", encodeEntries(t, e1, e2), encodeEntries(t, e1, anonymized)).Return(true, nil)
+		api.On("KVGet", auditPrefix+"pollID2").Return(encodeEntries(t, e3), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Audit().AnonymizeUser("userID2"))
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Audit().AnonymizeUser("userID2"))
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{auditPrefix + "pollID1"}, nil)
+		api.On("KVGet", auditPrefix+"pollID1").Return(encodeEntries(t, e1, e2), nil)
+		api.On("KVCompareAndSet", auditPrefix+"pollID1", encodeEntries(t, e1, e2), encodeEntries(t, e1, anonymized)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Audit().AnonymizeUser("userID2"))
+	})
+}
@@ -0,0 +1,70 @@
+package kvstore
+
+import (
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// ChannelStore allows to access the Matterpoll settings of channels in the KV Store.
+type ChannelStore struct {
+	api plugin.API
+}
+
+const (
+	// disabledChannelPrefix is the prefix of the keys that mark channels in which polls can't be created
+	disabledChannelPrefix = "channeldisabled_"
+	// channelCreatorsPrefix is the prefix of the keys that hold who may create polls in a channel
+	channelCreatorsPrefix = "channelcreators_"
+)
+
+// NewChannelStore returns a Channel Store that uses the KV Store of a given plugin API.
+func NewChannelStore(api plugin.API) *ChannelStore {
+	return &ChannelStore{api: api}
+}
+
+// IsDisabled returns true if polls can't be created in a given channel.
+func (s *ChannelStore) IsDisabled(channelID string) (bool, error) {
+	b, appErr := s.api.KVGet(disabledChannelPrefix + channelID)
+	if appErr != nil {
+		return false, appErr
+	}
+	return b != nil, nil
+}
+
+// SetDisabled allows or disallows the creation of polls in a given channel.
+// Only disabled channels are stored, hence enabling a channel removes its key.
+func (s *ChannelStore) SetDisabled(channelID string, disabled bool) error {
+	if !disabled {
+		if appErr := s.api.KVDelete(disabledChannelPrefix + channelID); appErr != nil {
+			return appErr
+		}
+		return nil
+	}
+	if appErr := s.api.KVSet(disabledChannelPrefix+channelID, []byte("true")); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// GetCreators returns who may create polls in a given channel. It's empty if the channel doesn't restrict it.
+func (s *ChannelStore) GetCreators(channelID string) (string, error) {
+	b, appErr := s.api.KVGet(channelCreatorsPrefix + channelID)
+	if appErr != nil {
+		return "", appErr
+	}
+	return string(b), nil
+}
+
+// SetCreators restricts who may create polls in a given channel.
+// Only restricted channels are stored, hence an empty value removes the key.
+func (s *ChannelStore) SetCreators(channelID, creators string) error {
+	if creators == "" {
+		if appErr := s.api.KVDelete(channelCreatorsPrefix + channelID); appErr != nil {
+			return appErr
+		}
+		return nil
+	}
+	if appErr := s.api.KVSet(channelCreatorsPrefix+channelID, []byte(creators)); appErr != nil {
+		return appErr
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelStoreIsDisabled(t *testing.T) {
+	t.Run("disabled channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", disabledChannelPrefix+"channelID1").Return([]byte("true"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		disabled, err := store.Channel().IsDisabled("channelID1")
+		require.Nil(t, err)
+		assert.True(t, disabled)
+	})
+	t.Run("enabled channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", disabledChannelPrefix+"channelID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		disabled, err := store.Channel().IsDisabled("channelID1")
+		require.Nil(t, err)
+		assert.False(t, disabled)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", disabledChannelPrefix+"channelID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		disabled, err := store.Channel().IsDisabled("channelID1")
+		assert.NotNil(t, err)
+		assert.False(t, disabled)
+	})
+}
+
+func TestChannelStoreSetDisabled(t *testing.T) {
+	t.Run("disable", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", disabledChannelPrefix+"channelID1", []byte("true")).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Channel().SetDisabled("channelID1", true))
+	})
+	t.Run("enable", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", disabledChannelPrefix+"channelID1").Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Channel().SetDisabled("channelID1", false))
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", disabledChannelPrefix+"channelID1", []byte("true")).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Channel().SetDisabled("channelID1", true))
+	})
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", disabledChannelPrefix+"channelID1").Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Channel().SetDisabled("channelID1", false))
+	})
+}
+
+func TestChannelStoreGetCreators(t *testing.T) {
+	t.Run("restricted channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelCreatorsPrefix+"channelID1").Return([]byte("channel_admins"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		creators, err := store.Channel().GetCreators("channelID1")
+		require.Nil(t, err)
+		assert.Equal(t, "channel_admins", creators)
+	})
+	t.Run("unrestricted channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelCreatorsPrefix+"channelID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		creators, err := store.Channel().GetCreators("channelID1")
+		require.Nil(t, err)
+		assert.Equal(t, "", creators)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelCreatorsPrefix+"channelID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		creators, err := store.Channel().GetCreators("channelID1")
+		assert.NotNil(t, err)
+		assert.Equal(t, "", creators)
+	})
+}
+
+func TestChannelStoreSetCreators(t *testing.T) {
+	t.Run("restrict", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", channelCreatorsPrefix+"channelID1", []byte("system_admins")).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Channel().SetCreators("channelID1", "system_admins"))
+	})
+	t.Run("remove restriction", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", channelCreatorsPrefix+"channelID1").Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Channel().SetCreators("channelID1", ""))
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", channelCreatorsPrefix+"channelID1", []byte("system_admins")).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Channel().SetCreators("channelID1", "system_admins"))
+	})
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", channelCreatorsPrefix+"channelID1").Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Channel().SetCreators("channelID1", ""))
+	})
+}
@@ -0,0 +1,53 @@
+package kvstore
+
+import (
+	"errors"
+
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// DraftStore allows to access the polls users are creating in a direct message with the bot in the KV Store.
+type DraftStore struct {
+	api plugin.API
+}
+
+// draftPrefix is the prefix of the keys that store the drafts of users
+const draftPrefix = "draft_"
+
+// NewDraftStore returns a Draft Store that uses the KV Store of a given plugin API.
+func NewDraftStore(api plugin.API) *DraftStore {
+	return &DraftStore{api: api}
+}
+
+// Get returns the draft of a given user. It returns nil if the user isn't creating a poll.
+func (s *DraftStore) Get(userID string) (*poll.Draft, error) {
+	b, appErr := s.api.KVGet(draftPrefix + userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if b == nil {
+		return nil, nil
+	}
+	draft := poll.DecodeDraftFromByte(b)
+	if draft == nil {
+		return nil, errors.New("failed to decode draft")
+	}
+	return draft, nil
+}
+
+// Save stores the draft of a given user.
+func (s *DraftStore) Save(userID string, draft *poll.Draft) error {
+	if appErr := s.api.KVSet(draftPrefix+userID, draft.EncodeToByte()); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// Delete removes the draft of a given user.
+func (s *DraftStore) Delete(userID string) error {
+	if appErr := s.api.KVDelete(draftPrefix + userID); appErr != nil {
+		return appErr
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraftStoreGet(t *testing.T) {
+	draft := &poll.Draft{Step: poll.DraftStepAnswerOptions, Question: "Question"}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", draftPrefix+"userID1").Return(draft.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Draft().Get("userID1")
+		require.Nil(t, err)
+		assert.Equal(t, draft, d)
+	})
+	t.Run("no draft", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", draftPrefix+"userID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Draft().Get("userID1")
+		require.Nil(t, err)
+		assert.Nil(t, d)
+	})
+	t.Run("invalid draft", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", draftPrefix+"userID1").Return([]byte("{"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Draft().Get("userID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, d)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", draftPrefix+"userID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Draft().Get("userID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, d)
+	})
+}
+
+func TestDraftStoreSave(t *testing.T) {
+	draft := &poll.Draft{Step: poll.DraftStepAnswerOptions, Question: "Question"}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", draftPrefix+"userID1", draft.EncodeToByte()).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Draft().Save("userID1", draft))
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", draftPrefix+"userID1", draft.EncodeToByte()).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Draft().Save("userID1", draft))
+	})
+}
+
+func TestDraftStoreDelete(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", draftPrefix+"userID1").Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Draft().Delete("userID1"))
+	})
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", draftPrefix+"userID1").Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Draft().Delete("userID1"))
+	})
+}
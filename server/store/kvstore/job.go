@@ -0,0 +1,92 @@
+package kvstore
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/job"
+)
+
+// JobStore allows to access scheduled jobs in the KV Store.
+type JobStore struct {
+	api plugin.API
+}
+
+const (
+	jobPrefix = "job_"
+	// listPerPage is the number of keys fetched per KVList call
+	listPerPage = 100
+)
+
+// Get returns the job for a given id. Returns an error if the job doesn't exist or a KV Store error occurred.
+func (s *JobStore) Get(id string) (*job.Job, error) {
+	b, err := s.api.KVGet(jobPrefix + id)
+	if err != nil {
+		return nil, err
+	}
+	j := job.DecodeJobFromByte(b)
+	if j == nil {
+		return nil, errors.New("failed to decode job")
+	}
+	return j, nil
+}
+
+// List returns all scheduled jobs.
+func (s *JobStore) List() ([]*job.Job, error) {
+	jobs := []*job.Job{}
+	for page := 0; ; page++ {
+		keys, err := s.api.KVList(page, listPerPage)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if !strings.HasPrefix(key, jobPrefix) {
+				continue
+			}
+			j, err := s.Get(strings.TrimPrefix(key, jobPrefix))
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, j)
+		}
+
+		if len(keys) < listPerPage {
+			return jobs, nil
+		}
+	}
+}
+
+// Claim marks a job as running to prevent other plugin instances in a cluster from running it as well.
+// Returns false if the job has been changed or claimed since it was read from the KV Store.
+func (s *JobStore) Claim(j *job.Job) (bool, error) {
+	claimed := *j
+	claimed.ClaimedAt = model.GetMillis()
+
+	ok, err := s.api.KVCompareAndSet(jobPrefix+j.ID, j.EncodeToByte(), claimed.EncodeToByte())
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		j.ClaimedAt = claimed.ClaimedAt
+	}
+	return ok, nil
+}
+
+// Save stores a job in the KV Store. Overwrittes any existing job with the same id.
+func (s *JobStore) Save(j *job.Job) error {
+	if err := s.api.KVSet(jobPrefix+j.ID, j.EncodeToByte()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a job from the KV Store.
+func (s *JobStore) Delete(j *job.Job) error {
+	if err := s.api.KVDelete(jobPrefix + j.ID); err != nil {
+		return err
+	}
+	return nil
+}
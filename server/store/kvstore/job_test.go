@@ -0,0 +1,195 @@
+package kvstore
+
+import (
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobStoreGet(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", jobPrefix+j.ID).Return(j.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rjob, err := store.Job().Get(j.ID)
+		require.Nil(t, err)
+		assert.Equal(t, j, rjob)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", jobPrefix+j.ID).Return([]byte{}, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rjob, err := store.Job().Get(j.ID)
+		assert.NotNil(t, err)
+		assert.Nil(t, rjob)
+	})
+	t.Run("Decode fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", jobPrefix+j.ID).Return([]byte{}, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rjob, err := store.Job().Get(j.ID)
+		assert.NotNil(t, err)
+		assert.Nil(t, rjob)
+	})
+}
+
+func TestJobStoreList(t *testing.T) {
+	j1 := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+	j2 := job.NewJob(job.TypeEndPoll, "pollID2", 1234567891)
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + "pollID1", jobPrefix + j1.ID, jobPrefix + j2.ID}, nil)
+		api.On("KVGet", jobPrefix+j1.ID).Return(j1.EncodeToByte(), nil)
+		api.On("KVGet", jobPrefix+j2.ID).Return(j2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		jobs, err := store.Job().List()
+		require.Nil(t, err)
+		assert.Equal(t, []*job.Job{j1, j2}, jobs)
+	})
+	t.Run("multiple pages", func(t *testing.T) {
+		keys := make([]string, listPerPage)
+		for i := range keys {
+			keys[i] = pollPrefix + model.NewId()
+		}
+		keys[0] = jobPrefix + j1.ID
+
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(keys, nil)
+		api.On("KVList", 1, listPerPage).Return([]string{jobPrefix + j2.ID}, nil)
+		api.On("KVGet", jobPrefix+j1.ID).Return(j1.EncodeToByte(), nil)
+		api.On("KVGet", jobPrefix+j2.ID).Return(j2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		jobs, err := store.Job().List()
+		require.Nil(t, err)
+		assert.Equal(t, []*job.Job{j1, j2}, jobs)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		jobs, err := store.Job().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, jobs)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{jobPrefix + j1.ID}, nil)
+		api.On("KVGet", jobPrefix+j1.ID).Return([]byte{}, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		jobs, err := store.Job().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, jobs)
+	})
+}
+
+func TestJobStoreClaim(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+	claimed := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+	claimed.ClaimedAt = 1234567890
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVCompareAndSet", jobPrefix+j.ID, j.EncodeToByte(), claimed.EncodeToByte()).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+		ok, err := store.Job().Claim(j)
+		require.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, claimed, j)
+	})
+	t.Run("job changed in the meantime", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVCompareAndSet", jobPrefix+j.ID, j.EncodeToByte(), claimed.EncodeToByte()).Return(false, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+		ok, err := store.Job().Claim(j)
+		require.Nil(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, int64(0), j.ClaimedAt)
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVCompareAndSet", jobPrefix+j.ID, j.EncodeToByte(), claimed.EncodeToByte()).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ok, err := store.Job().Claim(j)
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestJobStoreSave(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", jobPrefix+j.ID, j.EncodeToByte()).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Job().Save(j)
+		require.Nil(t, err)
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", jobPrefix+j.ID, j.EncodeToByte()).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Job().Save(j)
+		require.NotNil(t, err)
+	})
+}
+
+func TestJobStoreDelete(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", jobPrefix+j.ID).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Job().Delete(j)
+		require.Nil(t, err)
+	})
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", jobPrefix+j.ID).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Job().Delete(j)
+		require.NotNil(t, err)
+	})
+}
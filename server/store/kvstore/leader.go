@@ -0,0 +1,84 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// LeaderStore allows plugin instances in a cluster to elect the one that runs the scheduled jobs via the KV Store.
+type LeaderStore struct {
+	api plugin.API
+}
+
+// leaderKey is the key that stores the lease of the leading plugin instance
+const leaderKey = "scheduler_leader"
+
+// lease stores which plugin instance leads until when
+type lease struct {
+	InstanceID string
+	// ExpiresAt is the time in milliseconds at which another plugin instance may take over
+	ExpiresAt int64
+}
+
+// NewLeaderStore returns a Leader Store that uses the KV Store of a given plugin API.
+func NewLeaderStore(api plugin.API) *LeaderStore {
+	return &LeaderStore{api: api}
+}
+
+// Lead makes a given plugin instance the leader for a given lease and returns true. The leader extends its lease by leading again.
+// It returns false if another plugin instance leads and its lease hasn't expired yet, or if another plugin instance took over in the meantime.
+func (s *LeaderStore) Lead(instanceID string, duration time.Duration) (bool, error) {
+	current, oldValue, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	now := model.GetMillis()
+	if current != nil && current.InstanceID != instanceID && current.ExpiresAt > now {
+		return false, nil
+	}
+
+	newValue, _ := json.Marshal(&lease{InstanceID: instanceID, ExpiresAt: now + int64(duration/time.Millisecond)})
+	ok, appErr := s.api.KVCompareAndSet(leaderKey, oldValue, newValue)
+	if appErr != nil {
+		return false, appErr
+	}
+	return ok, nil
+}
+
+// Resign ends the lease of a given plugin instance if it leads, so another plugin instance can take over right away.
+func (s *LeaderStore) Resign(instanceID string) error {
+	current, oldValue, err := s.load()
+	if err != nil {
+		return err
+	}
+	if current == nil || current.InstanceID != instanceID {
+		return nil
+	}
+
+	// An expired lease lets every plugin instance take over. If another one already did, its lease is kept.
+	newValue, _ := json.Marshal(&lease{InstanceID: instanceID})
+	if _, appErr := s.api.KVCompareAndSet(leaderKey, oldValue, newValue); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// load returns the current lease together with the raw value it was decoded from. Both are nil if no plugin instance has lead yet.
+func (s *LeaderStore) load() (*lease, []byte, error) {
+	b, appErr := s.api.KVGet(leaderKey)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	if b == nil {
+		return nil, nil, nil
+	}
+	l := &lease{}
+	if err := json.Unmarshal(b, l); err != nil {
+		return nil, nil, errors.New("failed to decode scheduler lease")
+	}
+	return l, b, nil
+}
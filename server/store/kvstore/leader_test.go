@@ -0,0 +1,142 @@
+package kvstore
+
+import (
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderStoreLead(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	for name, test := range map[string]struct {
+		Current        []byte
+		ExpectedLease  []byte
+		CompareAndSet  bool
+		ExpectedLeads  bool
+		ExpectedError  bool
+		KVGetFails     bool
+		KVCompareFails bool
+	}{
+		"no leader yet": {
+			Current:       nil,
+			ExpectedLease: []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234627890}`),
+			CompareAndSet: true,
+			ExpectedLeads: true,
+		},
+		"lease gets extended": {
+			Current:       []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234567990}`),
+			ExpectedLease: []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234627890}`),
+			CompareAndSet: true,
+			ExpectedLeads: true,
+		},
+		"lease of another instance has expired": {
+			Current:       []byte(`{"InstanceID":"instanceID2","ExpiresAt":1234567890}`),
+			ExpectedLease: []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234627890}`),
+			CompareAndSet: true,
+			ExpectedLeads: true,
+		},
+		"another instance took over in the meantime": {
+			Current:       nil,
+			ExpectedLease: []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234627890}`),
+			CompareAndSet: false,
+			ExpectedLeads: false,
+		},
+		"another instance leads": {
+			Current:       []byte(`{"InstanceID":"instanceID2","ExpiresAt":1234567990}`),
+			ExpectedLeads: false,
+		},
+		"KVGet() fails": {
+			KVGetFails:    true,
+			ExpectedError: true,
+		},
+		"KVCompareAndSet() fails": {
+			Current:        nil,
+			ExpectedLease:  []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234627890}`),
+			KVCompareFails: true,
+			ExpectedError:  true,
+		},
+		"decode fails": {
+			Current:       []byte(`{`),
+			ExpectedError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			if test.KVGetFails {
+				api.On("KVGet", leaderKey).Return(nil, &model.AppError{})
+			} else {
+				api.On("KVGet", leaderKey).Return(test.Current, nil)
+			}
+			if test.ExpectedLease != nil {
+				if test.KVCompareFails {
+					api.On("KVCompareAndSet", leaderKey, test.Current, test.ExpectedLease).Return(false, &model.AppError{})
+				} else {
+					api.On("KVCompareAndSet", leaderKey, test.Current, test.ExpectedLease).Return(test.CompareAndSet, nil)
+				}
+			}
+			defer api.AssertExpectations(t)
+			store := setupTestStore(api)
+
+			leads, err := store.Leader().Lead("instanceID1", time.Minute)
+			assert.Equal(t, test.ExpectedLeads, leads)
+			if test.ExpectedError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestLeaderStoreResign(t *testing.T) {
+	t.Run("leader resigns", func(t *testing.T) {
+		current := []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234567990}`)
+		api := &plugintest.API{}
+		api.On("KVGet", leaderKey).Return(current, nil)
+		api.On("KVCompareAndSet", leaderKey, current, []byte(`{"InstanceID":"instanceID1","ExpiresAt":0}`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Leader().Resign("instanceID1"))
+	})
+	t.Run("another instance leads", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", leaderKey).Return([]byte(`{"InstanceID":"instanceID2","ExpiresAt":1234567990}`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Leader().Resign("instanceID1"))
+	})
+	t.Run("no leader yet", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", leaderKey).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Leader().Resign("instanceID1"))
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", leaderKey).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Leader().Resign("instanceID1"))
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		current := []byte(`{"InstanceID":"instanceID1","ExpiresAt":1234567990}`)
+		api := &plugintest.API{}
+		api.On("KVGet", leaderKey).Return(current, nil)
+		api.On("KVCompareAndSet", leaderKey, current, []byte(`{"InstanceID":"instanceID1","ExpiresAt":0}`)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Leader().Resign("instanceID1"))
+	})
+}
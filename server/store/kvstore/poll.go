@@ -1,10 +1,19 @@
 package kvstore
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
+	"hash/fnv"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
 )
 
 // PollStore allows to access polls in the KV Store.
@@ -12,33 +21,648 @@ type PollStore struct {
 	api plugin.API
 }
 
-const pollPrefix = "poll_"
+const (
+	pollPrefix = "poll_"
+	// archivedPollPrefix is the prefix of the keys that store archived polls, gzip compressed
+	archivedPollPrefix = "archivedpoll_"
+	// channelIndexPrefix is the prefix of the keys that store the IDs of all running polls in a channel
+	channelIndexPrefix = "channelpolls_"
+	// pollIndexPrefix is the prefix of the shards that store the creation time of all polls by their ID
+	pollIndexPrefix = "pollindex_"
+	// questionIndexPrefix is the prefix of the shards that store the questions of all polls by their ID, including archived polls
+	questionIndexPrefix = "questionindex_"
+	// tagIndexPrefix is the prefix of the keys that store the IDs of all polls with a tag, including archived polls
+	tagIndexPrefix = "tagpolls_"
 
-// Get returns the poll for a given id. Returns an error if the poll doesn't exist or a KV Store error occurred.
+	// indexShards is the number of keys the index of all polls and the index of all questions are split into,
+	// so a single key doesn't grow with the number of polls and concurrent saves rarely compete for the same key.
+	indexShards = 16
+
+	// maxUpdateAttempts is the number of times Update retries when the poll was changed concurrently.
+	maxUpdateAttempts = 10
+)
+
+// Get returns the poll for a given id. Archived polls are returned as well.
+// Returns an error if the poll doesn't exist or a KV Store error occurred.
 func (s *PollStore) Get(id string) (*poll.Poll, error) {
-	b, err := s.api.KVGet(pollPrefix + id)
+	p, _, _, err := s.load(id)
 	if err != nil {
 		return nil, err
 	}
-	poll := poll.DecodePollFromByte(b)
-	if poll == nil {
-		return nil, errors.New("failed to decode poll")
+	return p, nil
+}
+
+// List returns all polls that aren't archived.
+func (s *PollStore) List() ([]*poll.Poll, error) {
+	return s.listByPrefix(pollPrefix)
+}
+
+// ListArchived returns all archived polls.
+func (s *PollStore) ListArchived() ([]*poll.Poll, error) {
+	return s.listByPrefix(archivedPollPrefix)
+}
+
+// listByPrefix returns all polls stored under keys with a given prefix
+func (s *PollStore) listByPrefix(prefix string) ([]*poll.Poll, error) {
+	polls := []*poll.Poll{}
+	for page := 0; ; page++ {
+		keys, err := s.api.KVList(page, listPerPage)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			p, err := s.Get(strings.TrimPrefix(key, prefix))
+			if err != nil {
+				return nil, err
+			}
+			polls = append(polls, p)
+		}
+
+		if len(keys) < listPerPage {
+			return polls, nil
+		}
+	}
+}
+
+// ListByChannel returns all running polls in a given channel. This includes polls that are scheduled to get posted there.
+func (s *PollStore) ListByChannel(channelID string) ([]*poll.Poll, error) {
+	ids, _, err := s.getIndex(channelIndexPrefix + channelID)
+	if err != nil {
+		return nil, err
 	}
-	return poll, nil
+	return s.getAll(ids)
+}
+
+// ListPage returns a page of all polls, newest first, and the total number of polls. Pages start at zero.
+func (s *PollStore) ListPage(page, perPage int) ([]*poll.Poll, int, error) {
+	createdAt, err := s.getPollIndex()
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := []string{}
+	for id := range createdAt {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if createdAt[ids[i]] != createdAt[ids[j]] {
+			return createdAt[ids[i]] > createdAt[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+
+	pageIDs := []string{}
+	for i := page * perPage; i < len(ids) && len(pageIDs) < perPage; i++ {
+		pageIDs = append(pageIDs, ids[i])
+	}
+	polls, err := s.getAll(pageIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return polls, len(ids), nil
+}
+
+// Search returns all polls, including archived ones, whose question contains every word of a given text, newest first.
+// The polls are found using the index of all questions, so only matching polls get loaded.
+func (s *PollStore) Search(text string) ([]*poll.Poll, error) {
+	questions, err := s.getQuestionIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for id, question := range questions {
+		if store.QuestionMatches(question, text) {
+			ids = append(ids, id)
+		}
+	}
+	polls, err := s.getAll(ids)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(polls, func(i, j int) bool { return polls[i].CreatedAt > polls[j].CreatedAt })
+	return polls, nil
+}
+
+// ListByTag returns all polls, including archived ones, that are tagged with a given tag, newest first.
+// The polls are found using the index of the tag, so only tagged polls get loaded.
+func (s *PollStore) ListByTag(tag string) ([]*poll.Poll, error) {
+	ids, _, err := s.getIndex(tagIndexPrefix + poll.NormalizeTag(tag))
+	if err != nil {
+		return nil, err
+	}
+	polls, err := s.getAll(ids)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(polls, func(i, j int) bool { return polls[i].CreatedAt > polls[j].CreatedAt })
+	return polls, nil
 }
 
 // Save stores a poll in the KV Store. Overwrittes any existing poll with the same id.
+// Polls are added to the index of all polls, to the index of all questions and to the indexes of their tags.
+// Polls that overwrite a stored poll are removed from the indexes of the tags they no longer have.
+// Polls with a channel are also added to the index of their channel until they end.
 func (s *PollStore) Save(poll *poll.Poll) error {
+	oldTags, err := s.storedTags(poll.ID)
+	if err != nil {
+		return err
+	}
+
 	if err := s.api.KVSet(pollPrefix+poll.ID, poll.EncodeToByte()); err != nil {
 		return err
 	}
+	if err := s.updateShards(pollIndexPrefix, map[string]interface{}{poll.ID: poll.CreatedAt}); err != nil {
+		return err
+	}
+	if err := s.updateShards(questionIndexPrefix, map[string]interface{}{poll.ID: poll.Question}); err != nil {
+		return err
+	}
+	for _, tag := range poll.Settings.Tags {
+		if err := s.updateIndex(tagIndexPrefix+tag, poll.ID, true); err != nil {
+			return err
+		}
+	}
+	for _, tag := range oldTags {
+		if containsTag(poll.Settings.Tags, tag) {
+			continue
+		}
+		if err := s.updateIndex(tagIndexPrefix+tag, poll.ID, false); err != nil {
+			return err
+		}
+	}
+	if poll.ChannelID != "" {
+		if err := s.updateIndex(channelIndexPrefix+poll.ChannelID, poll.ID, !poll.IsEnded()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Delete deletes a poll from the KV Store.
+// Update atomically applies update to the poll with the given id and stores the result.
+// If the poll was changed by someone else in the meantime, the latest version is loaded and update is applied again.
+// Archived polls stay archived. Errors returned by update are passed through unchanged and nothing is stored.
+// Polls that end or get reopened are removed from or added to the index of their channel, like Save does.
+func (s *PollStore) Update(id string, update func(*poll.Poll) error) (*poll.Poll, error) {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		p, key, b, err := s.load(id)
+		if err != nil {
+			return nil, err
+		}
+
+		wasEnded := p.IsEnded()
+		if err = update(p); err != nil {
+			return nil, err
+		}
+
+		newValue := p.EncodeToByte()
+		if key == archivedPollPrefix+id {
+			if newValue, err = encodeArchivedPoll(p); err != nil {
+				return nil, err
+			}
+		}
+		ok, appErr := s.api.KVCompareAndSet(key, b, newValue)
+		if appErr != nil {
+			return nil, appErr
+		}
+		if !ok {
+			continue
+		}
+		// Archived polls aren't in the index of their channel
+		if key == pollPrefix+id && p.ChannelID != "" && p.IsEnded() != wasEnded {
+			if err := s.updateIndex(channelIndexPrefix+p.ChannelID, id, !p.IsEnded()); err != nil {
+				return nil, err
+			}
+		}
+		return p, nil
+	}
+	return nil, errors.New("too many concurrent updates")
+}
+
+// Delete deletes a poll from the KV Store, regardless of whether it's archived.
 func (s *PollStore) Delete(poll *poll.Poll) error {
 	if err := s.api.KVDelete(pollPrefix + poll.ID); err != nil {
 		return err
 	}
+	if err := s.api.KVDelete(archivedPollPrefix + poll.ID); err != nil {
+		return err
+	}
+	if err := s.updateShards(pollIndexPrefix, map[string]interface{}{poll.ID: nil}); err != nil {
+		return err
+	}
+	if err := s.updateShards(questionIndexPrefix, map[string]interface{}{poll.ID: nil}); err != nil {
+		return err
+	}
+	for _, tag := range poll.Settings.Tags {
+		if err := s.updateIndex(tagIndexPrefix+tag, poll.ID, false); err != nil {
+			return err
+		}
+	}
+	if poll.ChannelID != "" {
+		if err := s.updateIndex(channelIndexPrefix+poll.ChannelID, poll.ID, false); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// Archive moves an ended poll into the archive, where it's stored compressed, and removes it from the index of all polls
+// and the index of its channel. It stays in the index of all questions and in the indexes of its tags, so archived polls can still be found.
+// The archived poll is stored before the active one gets deleted, so a failure never loses the poll.
+func (s *PollStore) Archive(poll *poll.Poll) error {
+	if !poll.IsEnded() {
+		return errors.New("only ended polls can be archived")
+	}
+	b, err := encodeArchivedPoll(poll)
+	if err != nil {
+		return err
+	}
+
+	if appErr := s.api.KVSet(archivedPollPrefix+poll.ID, b); appErr != nil {
+		return appErr
+	}
+	if appErr := s.api.KVDelete(pollPrefix + poll.ID); appErr != nil {
+		return appErr
+	}
+	if err := s.updateShards(pollIndexPrefix, map[string]interface{}{poll.ID: nil}); err != nil {
+		return err
+	}
+	if poll.ChannelID != "" {
+		if err := s.updateIndex(channelIndexPrefix+poll.ChannelID, poll.ID, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unarchive moves an archived poll back to the active polls, e.g. because it got reopened. It's added to the index of all polls again,
+// and to the index of its channel if it's running. Polls that aren't archived are left alone.
+// The active poll is stored before the archived one gets deleted, so a failure never loses the poll.
+func (s *PollStore) Unarchive(id string) error {
+	b, appErr := s.api.KVGet(archivedPollPrefix + id)
+	if appErr != nil {
+		return appErr
+	}
+	if b == nil {
+		return nil
+	}
+	p := decodeArchivedPoll(b)
+	if p == nil {
+		return errors.New("failed to decode poll")
+	}
+
+	if err := s.Save(p); err != nil {
+		return err
+	}
+	if appErr := s.api.KVDelete(archivedPollPrefix + id); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// storedTags returns the tags of the stored version of a poll, regardless of whether it's archived.
+// Polls that aren't stored yet have no tags.
+func (s *PollStore) storedTags(id string) ([]string, error) {
+	p, _, _, err := s.find(id)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return p.Settings.Tags, nil
+}
+
+// load returns the poll with a given id together with the key and the raw value it was loaded from.
+// Returns an error if the poll doesn't exist.
+func (s *PollStore) load(id string) (*poll.Poll, string, []byte, error) {
+	p, key, b, err := s.find(id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if p == nil {
+		return nil, "", nil, errors.New("poll not found")
+	}
+	return p, key, b, nil
+}
+
+// find returns the poll with a given id together with the key and the raw value it was loaded from,
+// or nil if the poll doesn't exist. Polls that aren't stored as active polls are looked up in the archive.
+func (s *PollStore) find(id string) (*poll.Poll, string, []byte, error) {
+	key := pollPrefix + id
+	b, appErr := s.api.KVGet(key)
+	if appErr != nil {
+		return nil, "", nil, appErr
+	}
+	decode := poll.DecodePollFromByte
+	if b == nil {
+		key = archivedPollPrefix + id
+		if b, appErr = s.api.KVGet(key); appErr != nil {
+			return nil, "", nil, appErr
+		}
+		if b == nil {
+			return nil, "", nil, nil
+		}
+		decode = decodeArchivedPoll
+	}
+
+	p := decode(b)
+	if p == nil {
+		return nil, "", nil, errors.New("failed to decode poll")
+	}
+	return p, key, b, nil
+}
+
+// encodeArchivedPoll returns the gzip compressed encoding of a poll
+func encodeArchivedPoll(p *poll.Poll) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(p.EncodeToByte()); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeArchivedPoll tries to create a poll from its gzip compressed encoding
+func decodeArchivedPoll(b []byte) *poll.Poll {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	decompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+	return poll.DecodePollFromByte(decompressed)
+}
+
+// buildIndex adds all polls to the index of all polls. This covers polls that were stored before the index was introduced.
+// Polls that are already indexed are skipped.
+func (s *PollStore) buildIndex() error {
+	polls, err := s.List()
+	if err != nil {
+		return err
+	}
+	createdAt := map[string]interface{}{}
+	for _, p := range polls {
+		createdAt[p.ID] = p.CreatedAt
+	}
+	return s.updateShards(pollIndexPrefix, createdAt)
+}
+
+// buildQuestionIndex adds the questions of all polls, including archived ones, to the index of all questions.
+// This covers polls that were stored before the index was introduced. Polls that are already indexed are skipped.
+func (s *PollStore) buildQuestionIndex() error {
+	polls, err := s.List()
+	if err != nil {
+		return err
+	}
+	archived, err := s.ListArchived()
+	if err != nil {
+		return err
+	}
+	questions := map[string]interface{}{}
+	for _, p := range append(polls, archived...) {
+		questions[p.ID] = p.Question
+	}
+	return s.updateShards(questionIndexPrefix, questions)
+}
+
+// buildChannelIndexes adds all running polls to the index of their channel.
+// This covers polls that were stored before the indexes of channels were introduced. Polls that are already indexed are skipped.
+func (s *PollStore) buildChannelIndexes() error {
+	polls, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, p := range polls {
+		if p.ChannelID == "" || p.IsEnded() {
+			continue
+		}
+		if err := s.updateIndex(channelIndexPrefix+p.ChannelID, p.ID, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAll returns the polls with the given IDs in the same order.
+func (s *PollStore) getAll(ids []string) ([]*poll.Poll, error) {
+	polls := []*poll.Poll{}
+	for _, id := range ids {
+		p, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		polls = append(polls, p)
+	}
+	return polls, nil
+}
+
+// getIndex returns the poll IDs stored under a given index key and the raw value they were decoded from.
+func (s *PollStore) getIndex(key string) ([]string, []byte, error) {
+	b, appErr := s.api.KVGet(key)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	ids := []string{}
+	if b == nil {
+		return ids, nil, nil
+	}
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return nil, nil, err
+	}
+	return ids, b, nil
+}
+
+// updateIndex adds or removes a poll from the index stored under a given key.
+// The index is only written if it changes.
+func (s *PollStore) updateIndex(key, pollID string, add bool) error {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		ids, oldValue, err := s.getIndex(key)
+		if err != nil {
+			return err
+		}
+
+		newIDs := []string{}
+		found := false
+		for _, id := range ids {
+			if id == pollID {
+				found = true
+				continue
+			}
+			newIDs = append(newIDs, id)
+		}
+		if found == add {
+			return nil
+		}
+		if add {
+			newIDs = append(newIDs, pollID)
+		}
+
+		newValue, err := json.Marshal(newIDs)
+		if err != nil {
+			return err
+		}
+		ok, appErr := s.api.KVCompareAndSet(key, oldValue, newValue)
+		if appErr != nil {
+			return appErr
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errors.New("too many concurrent updates")
+}
+
+// getPollIndex returns the creation time of all polls by their ID.
+func (s *PollStore) getPollIndex() (map[string]int64, error) {
+	entries, err := s.getShards(pollIndexPrefix)
+	if err != nil {
+		return nil, err
+	}
+	createdAt := map[string]int64{}
+	for id, b := range entries {
+		var t int64
+		if err := json.Unmarshal(b, &t); err != nil {
+			return nil, err
+		}
+		createdAt[id] = t
+	}
+	return createdAt, nil
+}
+
+// getQuestionIndex returns the questions of all polls by their ID.
+func (s *PollStore) getQuestionIndex() (map[string]string, error) {
+	entries, err := s.getShards(questionIndexPrefix)
+	if err != nil {
+		return nil, err
+	}
+	questions := map[string]string{}
+	for id, b := range entries {
+		var question string
+		if err := json.Unmarshal(b, &question); err != nil {
+			return nil, err
+		}
+		questions[id] = question
+	}
+	return questions, nil
+}
+
+// indexShardKey returns the key of the shard of a sharded index that stores a given poll.
+func indexShardKey(prefix, pollID string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(pollID))
+	return prefix + strconv.Itoa(int(h.Sum32()%indexShards))
+}
+
+// getShards returns the entries of all shards of a sharded index by poll ID.
+func (s *PollStore) getShards(prefix string) (map[string]json.RawMessage, error) {
+	entries := map[string]json.RawMessage{}
+	for i := 0; i < indexShards; i++ {
+		shard, _, err := s.getShard(prefix + strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		for id, b := range shard {
+			entries[id] = b
+		}
+	}
+	return entries, nil
+}
+
+// getShard returns the entries stored in a shard of a sharded index by poll ID and the raw value they were decoded from.
+func (s *PollStore) getShard(key string) (map[string]json.RawMessage, []byte, error) {
+	b, appErr := s.api.KVGet(key)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	entries := map[string]json.RawMessage{}
+	if b == nil {
+		return entries, nil, nil
+	}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, nil, err
+	}
+	return entries, b, nil
+}
+
+// updateShards stores the given entries of a sharded index by poll ID. A nil entry removes the poll.
+// Every shard that contains one of the polls is updated once.
+func (s *PollStore) updateShards(prefix string, entries map[string]interface{}) error {
+	shards := map[string]map[string]interface{}{}
+	for id, entry := range entries {
+		key := indexShardKey(prefix, id)
+		if shards[key] == nil {
+			shards[key] = map[string]interface{}{}
+		}
+		shards[key][id] = entry
+	}
+	for key, shardEntries := range shards {
+		if err := s.updateShard(key, shardEntries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateShard stores the given entries in a shard of a sharded index by poll ID. A nil entry removes the poll.
+// The shard is only written if it changes.
+func (s *PollStore) updateShard(key string, entries map[string]interface{}) error {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		shard, oldValue, err := s.getShard(key)
+		if err != nil {
+			return err
+		}
+
+		changed := false
+		for id, entry := range entries {
+			oldEntry, found := shard[id]
+			if entry == nil {
+				if found {
+					delete(shard, id)
+					changed = true
+				}
+				continue
+			}
+			b, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if !found || !bytes.Equal(oldEntry, b) {
+				shard[id] = b
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		newValue, err := json.Marshal(shard)
+		if err != nil {
+			return err
+		}
+		ok, appErr := s.api.KVCompareAndSet(key, oldValue, newValue)
+		if appErr != nil {
+			return appErr
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errors.New("too many concurrent updates")
+}
+
+// containsTag returns true if a given tag is among the tags.
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,13 @@
 package kvstore
 
 import (
+	"encoding/json"
+	"strconv"
 	"testing"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
 	"github.com/matterpoll/matterpoll/server/utils/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,6 +24,31 @@ func TestPollStoreGet(t *testing.T) {
 		require.Nil(t, err)
 		assert.Equal(t, testutils.GetPoll(), rpoll)
 	})
+	t.Run("archived poll", func(t *testing.T) {
+		archived, err := encodeArchivedPoll(testutils.GetPoll())
+		require.Nil(t, err)
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+testutils.GetPollID()).Return(archived, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		require.Nil(t, err)
+		assert.Equal(t, testutils.GetPoll(), rpoll)
+	})
+	t.Run("poll doesn't exist", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+testutils.GetPollID()).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Get(testutils.GetPollID())
+		assert.NotNil(t, err)
+		assert.Nil(t, rpoll)
+	})
 	t.Run("KVGet() fails", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return([]byte{}, &model.AppError{})
@@ -43,31 +71,886 @@ func TestPollStoreGet(t *testing.T) {
 	})
 }
 
+func TestPollStoreList(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll2 := testutils.GetPollWithVotes()
+	poll2.ID = "pollID2"
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + poll1.ID, channelIndexPrefix + "channelID1", jobPrefix + "end_poll_pollID2", pollPrefix + "pollID2"}, nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().List()
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{poll1, poll2}, polls)
+	})
+	t.Run("multiple pages", func(t *testing.T) {
+		keys := make([]string, listPerPage)
+		for i := range keys {
+			keys[i] = jobPrefix + model.NewId()
+		}
+		keys[0] = pollPrefix + poll1.ID
+
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(keys, nil)
+		api.On("KVList", 1, listPerPage).Return([]string{pollPrefix + "pollID2"}, nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().List()
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{poll1, poll2}, polls)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + poll1.ID}, nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return([]byte{}, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+}
+
+func TestPollStoreListArchived(t *testing.T) {
+	archived := testutils.GetPollWithVotes()
+	archived.EndedAt = 1234567890
+	b, err := encodeArchivedPoll(archived)
+	require.Nil(t, err)
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + "pollID2", archivedPollPrefix + archived.ID}, nil)
+		api.On("KVGet", pollPrefix+archived.ID).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+archived.ID).Return(b, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListArchived()
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{archived}, polls)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListArchived()
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+}
+
+func TestPollStoreListByChannel(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll1.ChannelID = "channelID1"
+	poll2 := testutils.GetPollWithVotes()
+	poll2.ID = "pollID2"
+	poll2.ChannelID = "channelID1"
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["`+poll1.ID+`","pollID2"]`), nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByChannel("channelID1")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{poll1, poll2}, polls)
+	})
+	t.Run("no polls in channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByChannel("channelID1")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+	})
+	t.Run("KVGet() fails for index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByChannel("channelID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+	t.Run("Decode index fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte("{"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByChannel("channelID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+	t.Run("KVGet() fails for poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["`+poll1.ID+`"]`), nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByChannel("channelID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+}
+
+func TestPollStoreListPage(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll2 := testutils.GetPoll()
+	poll2.ID = "pollID2"
+	poll2.CreatedAt = poll1.CreatedAt + 1000
+	poll3 := testutils.GetPoll()
+	poll3.ID = "pollID3"
+	poll3.CreatedAt = poll1.CreatedAt + 2000
+	index := map[string]interface{}{poll1.ID: poll1.CreatedAt, "pollID2": poll2.CreatedAt, "pollID3": poll3.CreatedAt}
+
+	t.Run("first page", func(t *testing.T) {
+		api := &plugintest.API{}
+		mockShards(api, pollIndexPrefix, index)
+		api.On("KVGet", pollPrefix+"pollID3").Return(poll3.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, total, err := store.Poll().ListPage(0, 2)
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{poll3, poll2}, polls)
+		assert.Equal(t, 3, total)
+	})
+	t.Run("last page", func(t *testing.T) {
+		api := &plugintest.API{}
+		mockShards(api, pollIndexPrefix, index)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, total, err := store.Poll().ListPage(1, 2)
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{poll1}, polls)
+		assert.Equal(t, 3, total)
+	})
+	t.Run("page after the last one", func(t *testing.T) {
+		api := &plugintest.API{}
+		mockShards(api, pollIndexPrefix, index)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, total, err := store.Poll().ListPage(2, 2)
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+		assert.Equal(t, 3, total)
+	})
+	t.Run("KVGet() fails for index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollIndexPrefix+"0").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, total, err := store.Poll().ListPage(0, 2)
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Equal(t, 0, total)
+	})
+	t.Run("KVGet() fails for poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		mockShards(api, pollIndexPrefix, index)
+		api.On("KVGet", pollPrefix+"pollID3").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, total, err := store.Poll().ListPage(0, 2)
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Equal(t, 0, total)
+	})
+}
+
+func TestPollStoreBuildIndex(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll2 := testutils.GetPoll()
+	poll2.ID = "pollID2"
+	poll2.CreatedAt = poll1.CreatedAt - 1000
+	shards := encodeShards(pollIndexPrefix, map[string]interface{}{poll1.ID: poll1.CreatedAt, "pollID2": poll2.CreatedAt})
+
+	t.Run("index gets built", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + poll1.ID, pollPrefix + "pollID2"}, nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		for key, value := range shards {
+			api.On("KVGet", key).Return(nil, nil)
+			api.On("KVCompareAndSet", key, []byte(nil), value).Return(true, nil)
+		}
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.buildIndex()
+		require.Nil(t, err)
+	})
+	t.Run("polls are already indexed", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + poll1.ID, pollPrefix + "pollID2"}, nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		for key, value := range shards {
+			api.On("KVGet", key).Return(value, nil)
+		}
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.buildIndex()
+		require.Nil(t, err)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.buildIndex()
+		require.NotNil(t, err)
+	})
+}
+
+func TestPollStoreBuildQuestionIndex(t *testing.T) {
+	t.Run("index gets built, including archived polls", func(t *testing.T) {
+		poll2 := testutils.GetPoll()
+		poll2.ID = "pollID2"
+		poll2.Question = "Lunch?"
+		poll2.EndedAt = 1234567890
+		archived, err := encodeArchivedPoll(poll2)
+		require.Nil(t, err)
+
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + testutils.GetPollID(), archivedPollPrefix + "pollID2"}, nil)
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(testutils.GetPoll().EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+"pollID2").Return(archived, nil)
+		for key, value := range encodeShards(questionIndexPrefix, map[string]interface{}{testutils.GetPollID(): "Question", "pollID2": "Lunch?"}) {
+			api.On("KVGet", key).Return(nil, nil)
+			api.On("KVCompareAndSet", key, []byte(nil), value).Return(true, nil)
+		}
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err = store.pollStore.buildQuestionIndex()
+		require.Nil(t, err)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.buildQuestionIndex()
+		require.NotNil(t, err)
+	})
+}
+
+func TestPollStoreBuildChannelIndexes(t *testing.T) {
+	t.Run("running polls get added", func(t *testing.T) {
+		poll1 := testutils.GetPoll()
+		poll1.ChannelID = "channelID1"
+		poll2 := testutils.GetPoll()
+		poll2.ID = "pollID2"
+		poll2.ChannelID = "channelID1"
+		poll2.EndedAt = 1234567890
+		poll3 := testutils.GetPoll()
+		poll3.ID = "pollID3"
+		poll3.ChannelID = "channelID2"
+
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{versionKey, pollPrefix + poll1.ID, pollPrefix + "pollID2", pollPrefix + "pollID3"}, nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID3").Return(poll3.EncodeToByte(), nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(nil), []byte(`["`+poll1.ID+`"]`)).Return(true, nil)
+		// Polls that are already indexed are skipped
+		api.On("KVGet", channelIndexPrefix+"channelID2").Return([]byte(`["pollID3"]`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.buildChannelIndexes()
+		require.Nil(t, err)
+	})
+	t.Run("KVList() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.buildChannelIndexes()
+		require.NotNil(t, err)
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		poll1 := testutils.GetPoll()
+		poll1.ChannelID = "channelID1"
+
+		api := &plugintest.API{}
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + poll1.ID}, nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(nil), []byte(`["`+poll1.ID+`"]`)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.pollStore.buildChannelIndexes()
+		require.NotNil(t, err)
+	})
+}
+
+func TestPollStoreSearch(t *testing.T) {
+	poll1 := testutils.GetPoll()
+	poll1.Question = "Lunch on Friday?"
+	poll2 := testutils.GetPoll()
+	poll2.ID = "pollID2"
+	poll2.Question = "Friday lunch or dinner?"
+	poll2.CreatedAt = poll1.CreatedAt + 1000
+	index := map[string]interface{}{poll1.ID: "Lunch on Friday?", "pollID2": "Friday lunch or dinner?", "pollID3": "Team event?"}
+
+	t.Run("all fine, newest poll first", func(t *testing.T) {
+		api := &plugintest.API{}
+		mockShards(api, questionIndexPrefix, index)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().Search("LUNCH friday")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{poll2, poll1}, polls)
+	})
+	t.Run("no matches", func(t *testing.T) {
+		api := &plugintest.API{}
+		mockShards(api, questionIndexPrefix, index)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().Search("breakfast")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+	})
+	t.Run("KVGet() fails for index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", questionIndexPrefix+"0").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().Search("lunch")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+	t.Run("KVGet() fails for poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		mockShards(api, questionIndexPrefix, index)
+		api.On("KVGet", pollPrefix+"pollID3").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().Search("event")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+}
+
+func TestPollStoreListByTag(t *testing.T) {
+	poll1 := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro"}})
+	poll2 := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro", "team-alpha"}})
+	poll2.ID = "pollID2"
+	poll2.CreatedAt = poll1.CreatedAt + 1000
+
+	t.Run("all fine, newest poll first", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", tagIndexPrefix+"retro").Return([]byte(`["`+poll1.ID+`","pollID2"]`), nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(poll1.EncodeToByte(), nil)
+		api.On("KVGet", pollPrefix+"pollID2").Return(poll2.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByTag("#Retro")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{poll2, poll1}, polls)
+	})
+	t.Run("no polls with tag", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", tagIndexPrefix+"retro").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByTag("retro")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+	})
+	t.Run("KVGet() fails for index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", tagIndexPrefix+"retro").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByTag("retro")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+	t.Run("KVGet() fails for poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", tagIndexPrefix+"retro").Return([]byte(`["`+poll1.ID+`"]`), nil)
+		api.On("KVGet", pollPrefix+poll1.ID).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		polls, err := store.Poll().ListByTag("retro")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+	})
+}
+
 func TestPollStoreSave(t *testing.T) {
+	id := testutils.GetPollID()
+	pollShard := indexShardKey(pollIndexPrefix, id)
+	questionShard := indexShardKey(questionIndexPrefix, id)
+	indexed := func(api *plugintest.API) {
+		api.On("KVGet", pollPrefix+id).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+id).Return(nil, nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+id+`":1234567890}`), nil)
+		api.On("KVGet", questionShard).Return([]byte(`{"`+id+`":"Question"}`), nil)
+	}
+
 	t.Run("all fine", func(t *testing.T) {
 		api := &plugintest.API{}
-		api.On("KVSet", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte()).Return(nil)
+		api.On("KVGet", pollPrefix+id).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+id).Return(nil, nil)
+		api.On("KVSet", pollPrefix+id, testutils.GetPoll().EncodeToByte()).Return(nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"pollID2":1234567000}`), nil)
+		api.On("KVCompareAndSet", pollShard, []byte(`{"pollID2":1234567000}`), []byte(`{"`+id+`":1234567890,"pollID2":1234567000}`)).Return(true, nil)
+		api.On("KVGet", questionShard).Return([]byte(`{"pollID2":"Lunch?"}`), nil)
+		api.On("KVCompareAndSet", questionShard, []byte(`{"pollID2":"Lunch?"}`), []byte(`{"`+id+`":"Question","pollID2":"Lunch?"}`)).Return(true, nil)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
 		err := store.Poll().Save(testutils.GetPoll())
 		require.Nil(t, err)
 	})
+	t.Run("KVGet() fails for stored poll", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+id).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(testutils.GetPoll())
+		require.NotNil(t, err)
+	})
 	t.Run("KVSet() fails", func(t *testing.T) {
 		api := &plugintest.API{}
-		api.On("KVSet", pollPrefix+testutils.GetPollID(), testutils.GetPoll().EncodeToByte()).Return(&model.AppError{})
+		api.On("KVGet", pollPrefix+id).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+id).Return(nil, nil)
+		api.On("KVSet", pollPrefix+id, testutils.GetPoll().EncodeToByte()).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(testutils.GetPoll())
+		require.NotNil(t, err)
+	})
+	t.Run("KVCompareAndSet() fails for poll index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+id).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+id).Return(nil, nil)
+		api.On("KVSet", pollPrefix+id, testutils.GetPoll().EncodeToByte()).Return(nil)
+		api.On("KVGet", pollShard).Return(nil, nil)
+		api.On("KVCompareAndSet", pollShard, []byte(nil), []byte(`{"`+id+`":1234567890}`)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(testutils.GetPoll())
+		require.NotNil(t, err)
+	})
+	t.Run("question index changed in the meantime", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+id).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+id).Return(nil, nil)
+		api.On("KVSet", pollPrefix+id, testutils.GetPoll().EncodeToByte()).Return(nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+id+`":1234567890}`), nil)
+		api.On("KVGet", questionShard).Return(nil, nil).Once()
+		api.On("KVCompareAndSet", questionShard, []byte(nil), []byte(`{"`+id+`":"Question"}`)).Return(false, nil)
+		api.On("KVGet", questionShard).Return([]byte(`{"pollID2":"Lunch?"}`), nil).Once()
+		api.On("KVCompareAndSet", questionShard, []byte(`{"pollID2":"Lunch?"}`), []byte(`{"`+id+`":"Question","pollID2":"Lunch?"}`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(testutils.GetPoll())
+		require.Nil(t, err)
+	})
+	t.Run("KVCompareAndSet() fails for question index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+id).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+id).Return(nil, nil)
+		api.On("KVSet", pollPrefix+id, testutils.GetPoll().EncodeToByte()).Return(nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+id+`":1234567890}`), nil)
+		api.On("KVGet", questionShard).Return(nil, nil)
+		api.On("KVCompareAndSet", questionShard, []byte(nil), []byte(`{"`+id+`":"Question"}`)).Return(false, &model.AppError{})
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
 		err := store.Poll().Save(testutils.GetPoll())
 		require.NotNil(t, err)
 	})
+
+	posted := testutils.GetPoll()
+	posted.ChannelID = "channelID1"
+	ended := posted.Copy()
+	ended.EndedAt = 1234567890
+
+	t.Run("tagged poll gets added to the tag indexes", func(t *testing.T) {
+		tagged := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro", "team-alpha"}})
+
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+tagged.ID, tagged.EncodeToByte()).Return(nil)
+		api.On("KVGet", tagIndexPrefix+"retro").Return([]byte(`["pollID2"]`), nil)
+		api.On("KVCompareAndSet", tagIndexPrefix+"retro", []byte(`["pollID2"]`), []byte(`["pollID2","`+tagged.ID+`"]`)).Return(true, nil)
+		api.On("KVGet", tagIndexPrefix+"team-alpha").Return(nil, nil)
+		api.On("KVCompareAndSet", tagIndexPrefix+"team-alpha", []byte(nil), []byte(`["`+tagged.ID+`"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(tagged)
+		require.Nil(t, err)
+	})
+	t.Run("tags the poll no longer has get pruned", func(t *testing.T) {
+		stored := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro", "team-alpha"}})
+		retagged := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro"}})
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+id).Return(stored.EncodeToByte(), nil)
+		api.On("KVSet", pollPrefix+id, retagged.EncodeToByte()).Return(nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+id+`":1234567890}`), nil)
+		api.On("KVGet", questionShard).Return([]byte(`{"`+id+`":"Question"}`), nil)
+		api.On("KVGet", tagIndexPrefix+"retro").Return([]byte(`["`+id+`"]`), nil)
+		api.On("KVGet", tagIndexPrefix+"team-alpha").Return([]byte(`["pollID2","`+id+`"]`), nil)
+		api.On("KVCompareAndSet", tagIndexPrefix+"team-alpha", []byte(`["pollID2","`+id+`"]`), []byte(`["pollID2"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(retagged)
+		require.Nil(t, err)
+	})
+	t.Run("archived poll keeps its tags", func(t *testing.T) {
+		tagged := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro"}})
+		tagged.EndedAt = 1234567890
+		archived, err := encodeArchivedPoll(tagged)
+		require.Nil(t, err)
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+id).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+id).Return(archived, nil)
+		api.On("KVSet", pollPrefix+id, tagged.EncodeToByte()).Return(nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+id+`":1234567890}`), nil)
+		api.On("KVGet", questionShard).Return([]byte(`{"`+id+`":"Question"}`), nil)
+		api.On("KVGet", tagIndexPrefix+"retro").Return([]byte(`["`+id+`"]`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err = store.Poll().Save(tagged)
+		require.Nil(t, err)
+	})
+	t.Run("KVCompareAndSet() fails for tag index", func(t *testing.T) {
+		tagged := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro"}})
+
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+tagged.ID, tagged.EncodeToByte()).Return(nil)
+		api.On("KVGet", tagIndexPrefix+"retro").Return(nil, nil)
+		api.On("KVCompareAndSet", tagIndexPrefix+"retro", []byte(nil), []byte(`["`+tagged.ID+`"]`)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(tagged)
+		require.NotNil(t, err)
+	})
+	t.Run("posted poll gets added to the channel index", func(t *testing.T) {
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+posted.ID, posted.EncodeToByte()).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["pollID2"]`), nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(`["pollID2"]`), []byte(`["pollID2","`+posted.ID+`"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(posted)
+		require.Nil(t, err)
+	})
+	t.Run("first poll in channel", func(t *testing.T) {
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+posted.ID, posted.EncodeToByte()).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(nil), []byte(`["`+posted.ID+`"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(posted)
+		require.Nil(t, err)
+	})
+	t.Run("poll already in channel index", func(t *testing.T) {
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+posted.ID, posted.EncodeToByte()).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["`+posted.ID+`"]`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(posted)
+		require.Nil(t, err)
+	})
+	t.Run("ended poll gets removed from the channel index", func(t *testing.T) {
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+ended.ID, ended.EncodeToByte()).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["`+ended.ID+`","pollID2"]`), nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(`["`+ended.ID+`","pollID2"]`), []byte(`["pollID2"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(ended)
+		require.Nil(t, err)
+	})
+	t.Run("channel index changed in the meantime", func(t *testing.T) {
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+posted.ID, posted.EncodeToByte()).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil).Once()
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(nil), []byte(`["`+posted.ID+`"]`)).Return(false, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["pollID2"]`), nil).Once()
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(`["pollID2"]`), []byte(`["pollID2","`+posted.ID+`"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(posted)
+		require.Nil(t, err)
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		indexed(api)
+		api.On("KVSet", pollPrefix+posted.ID, posted.EncodeToByte()).Return(nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(nil), []byte(`["`+posted.ID+`"]`)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Save(posted)
+		require.NotNil(t, err)
+	})
+}
+
+func TestPollStoreUpdate(t *testing.T) {
+	addOption := func(p *poll.Poll) error {
+		return p.AddAnswerOption("New Option")
+	}
+	pollIn := testutils.GetPoll()
+	pollOut := testutils.GetPoll()
+	require.Nil(t, pollOut.AddAnswerOption("New Option"))
+	pollChanged := testutils.GetPollWithVotes()
+	pollChangedOut := testutils.GetPollWithVotes()
+	require.Nil(t, pollChangedOut.AddAnswerOption("New Option"))
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(pollIn.EncodeToByte(), nil)
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), pollIn.EncodeToByte(), pollOut.EncodeToByte()).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		require.Nil(t, err)
+		assert.Equal(t, pollOut, rpoll)
+	})
+	t.Run("poll changed in the meantime", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(pollIn.EncodeToByte(), nil).Once()
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), pollIn.EncodeToByte(), pollOut.EncodeToByte()).Return(false, nil)
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(pollChanged.EncodeToByte(), nil).Once()
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), pollChanged.EncodeToByte(), pollChangedOut.EncodeToByte()).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		require.Nil(t, err)
+		assert.Equal(t, pollChangedOut, rpoll)
+	})
+	t.Run("poll changed too often", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(pollIn.EncodeToByte(), nil).Times(maxUpdateAttempts)
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), pollIn.EncodeToByte(), pollOut.EncodeToByte()).Return(false, nil).Times(maxUpdateAttempts)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		assert.NotNil(t, err)
+		assert.Nil(t, rpoll)
+	})
+	t.Run("archived poll stays archived", func(t *testing.T) {
+		archivedIn, err := encodeArchivedPoll(pollIn)
+		require.Nil(t, err)
+		archivedOut, err := encodeArchivedPoll(pollOut)
+		require.Nil(t, err)
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(nil, nil)
+		api.On("KVGet", archivedPollPrefix+testutils.GetPollID()).Return(archivedIn, nil)
+		api.On("KVCompareAndSet", archivedPollPrefix+testutils.GetPollID(), archivedIn, archivedOut).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		require.Nil(t, err)
+		assert.Equal(t, pollOut, rpoll)
+	})
+	t.Run("ended poll leaves the channel index", func(t *testing.T) {
+		runningPoll := testutils.GetPoll()
+		runningPoll.ChannelID = "channelID1"
+		endedPoll := runningPoll.Copy()
+		endedPoll.EndedAt = 1234567890
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(runningPoll.EncodeToByte(), nil)
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), runningPoll.EncodeToByte(), endedPoll.EncodeToByte()).Return(true, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["pollID2","`+testutils.GetPollID()+`"]`), nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(`["pollID2","`+testutils.GetPollID()+`"]`), []byte(`["pollID2"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), func(p *poll.Poll) error {
+			p.EndedAt = 1234567890
+			return nil
+		})
+		require.Nil(t, err)
+		assert.Equal(t, endedPoll, rpoll)
+	})
+	t.Run("reopened poll rejoins the channel index", func(t *testing.T) {
+		endedPoll := testutils.GetPoll()
+		endedPoll.ChannelID = "channelID1"
+		endedPoll.EndedAt = 1234567890
+		runningPoll := endedPoll.Copy()
+		runningPoll.EndedAt = 0
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(endedPoll.EncodeToByte(), nil)
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), endedPoll.EncodeToByte(), runningPoll.EncodeToByte()).Return(true, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(nil), []byte(`["`+testutils.GetPollID()+`"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), func(p *poll.Poll) error {
+			p.EndedAt = 0
+			return nil
+		})
+		require.Nil(t, err)
+		assert.Equal(t, runningPoll, rpoll)
+	})
+	t.Run("channel index update fails", func(t *testing.T) {
+		runningPoll := testutils.GetPoll()
+		runningPoll.ChannelID = "channelID1"
+		endedPoll := runningPoll.Copy()
+		endedPoll.EndedAt = 1234567890
+
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(runningPoll.EncodeToByte(), nil)
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), runningPoll.EncodeToByte(), endedPoll.EncodeToByte()).Return(true, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), func(p *poll.Poll) error {
+			p.EndedAt = 1234567890
+			return nil
+		})
+		assert.NotNil(t, err)
+		assert.Nil(t, rpoll)
+	})
+	t.Run("update fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(pollOut.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		assert.NotNil(t, err)
+		assert.Nil(t, rpoll)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return([]byte{}, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		assert.NotNil(t, err)
+		assert.Nil(t, rpoll)
+	})
+	t.Run("Decode fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return([]byte{}, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		assert.NotNil(t, err)
+		assert.Nil(t, rpoll)
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", pollPrefix+testutils.GetPollID()).Return(pollIn.EncodeToByte(), nil)
+		api.On("KVCompareAndSet", pollPrefix+testutils.GetPollID(), pollIn.EncodeToByte(), pollOut.EncodeToByte()).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		rpoll, err := store.Poll().Update(testutils.GetPollID(), addOption)
+		assert.NotNil(t, err)
+		assert.Nil(t, rpoll)
+	})
 }
 
 func TestPollStoreDelete(t *testing.T) {
+	pollShard := indexShardKey(pollIndexPrefix, testutils.GetPollID())
+	questionShard := indexShardKey(questionIndexPrefix, testutils.GetPollID())
+
 	t.Run("all fine", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVDelete", pollPrefix+testutils.GetPollID()).Return(nil)
+		api.On("KVDelete", archivedPollPrefix+testutils.GetPollID()).Return(nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+testutils.GetPollID()+`":1234567890,"pollID2":1234567000}`), nil)
+		api.On("KVCompareAndSet", pollShard, []byte(`{"`+testutils.GetPollID()+`":1234567890,"pollID2":1234567000}`), []byte(`{"pollID2":1234567000}`)).Return(true, nil)
+		api.On("KVGet", questionShard).Return([]byte(`{"`+testutils.GetPollID()+`":"Question","pollID2":"Lunch?"}`), nil)
+		api.On("KVCompareAndSet", questionShard, []byte(`{"`+testutils.GetPollID()+`":"Question","pollID2":"Lunch?"}`), []byte(`{"pollID2":"Lunch?"}`)).Return(true, nil)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
@@ -83,4 +966,179 @@ func TestPollStoreDelete(t *testing.T) {
 		err := store.Poll().Delete(testutils.GetPoll())
 		require.NotNil(t, err)
 	})
+	t.Run("poll gets removed from the channel index", func(t *testing.T) {
+		posted := testutils.GetPoll()
+		posted.ChannelID = "channelID1"
+
+		api := &plugintest.API{}
+		api.On("KVDelete", pollPrefix+posted.ID).Return(nil)
+		api.On("KVDelete", archivedPollPrefix+posted.ID).Return(nil)
+		api.On("KVGet", pollShard).Return(nil, nil)
+		api.On("KVGet", questionShard).Return(nil, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["`+posted.ID+`"]`), nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(`["`+posted.ID+`"]`), []byte(`[]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Delete(posted)
+		require.Nil(t, err)
+	})
+	t.Run("poll gets removed from the tag indexes", func(t *testing.T) {
+		tagged := testutils.GetPollWithSettings(poll.Settings{Tags: []string{"retro"}})
+
+		api := &plugintest.API{}
+		api.On("KVDelete", pollPrefix+tagged.ID).Return(nil)
+		api.On("KVDelete", archivedPollPrefix+tagged.ID).Return(nil)
+		api.On("KVGet", pollShard).Return(nil, nil)
+		api.On("KVGet", questionShard).Return(nil, nil)
+		api.On("KVGet", tagIndexPrefix+"retro").Return([]byte(`["pollID2","`+tagged.ID+`"]`), nil)
+		api.On("KVCompareAndSet", tagIndexPrefix+"retro", []byte(`["pollID2","`+tagged.ID+`"]`), []byte(`["pollID2"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Delete(tagged)
+		require.Nil(t, err)
+	})
+}
+
+func TestPollStoreArchive(t *testing.T) {
+	ended := testutils.GetPollWithVotes()
+	ended.ChannelID = "channelID1"
+	ended.EndedAt = 1234567890
+	archived, err := encodeArchivedPoll(ended)
+	require.Nil(t, err)
+	pollShard := indexShardKey(pollIndexPrefix, ended.ID)
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", archivedPollPrefix+ended.ID, archived).Return(nil)
+		api.On("KVDelete", pollPrefix+ended.ID).Return(nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+ended.ID+`":1234567890,"pollID2":1234567000}`), nil)
+		api.On("KVCompareAndSet", pollShard, []byte(`{"`+ended.ID+`":1234567890,"pollID2":1234567000}`), []byte(`{"pollID2":1234567000}`)).Return(true, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return([]byte(`["`+ended.ID+`"]`), nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(`["`+ended.ID+`"]`), []byte(`[]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Archive(ended)
+		require.Nil(t, err)
+		assert.Equal(t, ended, decodeArchivedPoll(archived))
+	})
+	t.Run("poll is running", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Archive(testutils.GetPollWithVotes())
+		require.NotNil(t, err)
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", archivedPollPrefix+ended.ID, archived).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Archive(ended)
+		require.NotNil(t, err)
+	})
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", archivedPollPrefix+ended.ID, archived).Return(nil)
+		api.On("KVDelete", pollPrefix+ended.ID).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.Poll().Archive(ended)
+		require.NotNil(t, err)
+	})
+}
+
+func TestPollStoreUnarchive(t *testing.T) {
+	ended := testutils.GetPollWithVotes()
+	ended.ChannelID = "channelID1"
+	ended.EndedAt = 1234567890
+	archived, err := encodeArchivedPoll(ended)
+	require.Nil(t, err)
+
+	t.Run("reopen an archived poll", func(t *testing.T) {
+		reopened := ended.Copy()
+		reopened.Reopen(0)
+
+		api := &plugintest.API{}
+		api.On("KVGet", archivedPollPrefix+ended.ID).Return(archived, nil)
+		api.On("KVSet", pollPrefix+ended.ID, ended.EncodeToByte()).Return(nil)
+		api.On("KVGet", indexShardKey(pollIndexPrefix, ended.ID)).Return([]byte(`{"pollID2":1234567000}`), nil)
+		api.On("KVCompareAndSet", indexShardKey(pollIndexPrefix, ended.ID), []byte(`{"pollID2":1234567000}`), []byte(`{"`+ended.ID+`":1234567890,"pollID2":1234567000}`)).Return(true, nil)
+		api.On("KVGet", indexShardKey(questionIndexPrefix, ended.ID)).Return(nil, nil)
+		api.On("KVCompareAndSet", indexShardKey(questionIndexPrefix, ended.ID), []byte(nil), []byte(`{"`+ended.ID+`":"Question"}`)).Return(true, nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, nil)
+		api.On("KVDelete", archivedPollPrefix+ended.ID).Return(nil)
+		api.On("KVGet", pollPrefix+ended.ID).Return(ended.EncodeToByte(), nil)
+		api.On("KVCompareAndSet", pollPrefix+ended.ID, ended.EncodeToByte(), reopened.EncodeToByte()).Return(true, nil)
+		api.On("KVCompareAndSet", channelIndexPrefix+"channelID1", []byte(nil), []byte(`["`+ended.ID+`"]`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.Nil(t, store.Poll().Unarchive(ended.ID))
+		rpoll, err := store.Poll().Update(ended.ID, func(p *poll.Poll) error {
+			p.Reopen(0)
+			return nil
+		})
+		require.Nil(t, err)
+		assert.Equal(t, reopened, rpoll)
+	})
+	t.Run("poll isn't archived", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", archivedPollPrefix+ended.ID).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.Nil(t, store.Poll().Unarchive(ended.ID))
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", archivedPollPrefix+ended.ID).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		require.NotNil(t, store.Poll().Unarchive(ended.ID))
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", archivedPollPrefix+ended.ID).Return(archived, nil)
+		api.On("KVGet", pollPrefix+ended.ID).Return(nil, nil)
+		api.On("KVSet", pollPrefix+ended.ID, ended.EncodeToByte()).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		// The archived poll is kept
+		require.NotNil(t, store.Poll().Unarchive(ended.ID))
+	})
+}
+
+// mockShards mocks the shards of a sharded index that store the given entries by poll ID.
+func mockShards(api *plugintest.API, prefix string, entries map[string]interface{}) {
+	shards := encodeShards(prefix, entries)
+	for i := 0; i < indexShards; i++ {
+		key := prefix + strconv.Itoa(i)
+		api.On("KVGet", key).Return(shards[key], nil)
+	}
+}
+
+// encodeShards returns the values of the shards of a sharded index that store the given entries by poll ID.
+// Empty shards are left out.
+func encodeShards(prefix string, entries map[string]interface{}) map[string][]byte {
+	shards := map[string]map[string]interface{}{}
+	for id, entry := range entries {
+		key := indexShardKey(prefix, id)
+		if shards[key] == nil {
+			shards[key] = map[string]interface{}{}
+		}
+		shards[key][id] = entry
+	}
+	values := map[string][]byte{}
+	for key, shard := range shards {
+		values[key], _ = json.Marshal(shard)
+	}
+	return values
 }
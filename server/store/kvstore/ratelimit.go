@@ -0,0 +1,85 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// RateLimitStore allows to count actions of users in the KV Store.
+type RateLimitStore struct {
+	api plugin.API
+}
+
+const rateLimitPrefix = "ratelimit_"
+
+// rateLimitCounter is the value stored for every counter
+type rateLimitCounter struct {
+	Count int `json:"count"`
+	// Start is the time in milliseconds of the first increment of the current window
+	Start int64 `json:"start"`
+}
+
+// NewRateLimitStore returns a Rate Limit Store that uses the KV Store of a given plugin API.
+func NewRateLimitStore(api plugin.API) *RateLimitStore {
+	return &RateLimitStore{api: api}
+}
+
+// Get returns the current value of the counter of a given key.
+// It's zero if the counter doesn't exist or the given window has passed since its first increment.
+func (s *RateLimitStore) Get(key string, window time.Duration) (int, error) {
+	counter, _, err := s.get(key, window)
+	if err != nil {
+		return 0, err
+	}
+	return counter.Count, nil
+}
+
+// Increment increases the counter of a given key and returns its new value.
+// The counter starts over once the given window has passed since its first increment.
+func (s *RateLimitStore) Increment(key string, window time.Duration) (int, error) {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		counter, oldValue, err := s.get(key, window)
+		if err != nil {
+			return 0, err
+		}
+		counter.Count++
+
+		newValue, err := json.Marshal(counter)
+		if err != nil {
+			return 0, err
+		}
+		ok, appErr := s.api.KVCompareAndSet(rateLimitPrefix+key, oldValue, newValue)
+		if appErr != nil {
+			return 0, appErr
+		}
+		if ok {
+			return counter.Count, nil
+		}
+	}
+	return 0, errors.New("too many concurrent updates")
+}
+
+// get returns the counter of a given key for the current window and the raw value it was decoded from.
+// A counter whose window has passed is replaced by a new one that starts now.
+func (s *RateLimitStore) get(key string, window time.Duration) (*rateLimitCounter, []byte, error) {
+	oldValue, appErr := s.api.KVGet(rateLimitPrefix + key)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	now := model.GetMillis()
+	counter := &rateLimitCounter{}
+	if oldValue != nil {
+		if err := json.Unmarshal(oldValue, counter); err != nil {
+			return nil, nil, err
+		}
+	}
+	if now-counter.Start >= int64(window/time.Millisecond) {
+		counter = &rateLimitCounter{Start: now}
+	}
+	return counter, oldValue, nil
+}
@@ -0,0 +1,134 @@
+package kvstore
+
+import (
+	"testing"
+	"time"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitStoreGet(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	t.Run("no counter", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Get("key1", time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, 0, count)
+	})
+	t.Run("within window", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return([]byte(`{"count":2,"start":1234000000}`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Get("key1", time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, 2, count)
+	})
+	t.Run("window has passed", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return([]byte(`{"count":5,"start":1230000000}`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Get("key1", time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, 0, count)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Get("key1", time.Hour)
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestRateLimitStoreIncrement(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	t.Run("first increment", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(nil, nil)
+		api.On("KVCompareAndSet", rateLimitPrefix+"key1", []byte(nil), []byte(`{"count":1,"start":1234567890}`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Increment("key1", time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, 1, count)
+	})
+	t.Run("increment within window", func(t *testing.T) {
+		oldValue := []byte(`{"count":2,"start":1234000000}`)
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(oldValue, nil)
+		api.On("KVCompareAndSet", rateLimitPrefix+"key1", oldValue, []byte(`{"count":3,"start":1234000000}`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Increment("key1", time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, 3, count)
+	})
+	t.Run("window has passed", func(t *testing.T) {
+		oldValue := []byte(`{"count":5,"start":1230000000}`)
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(oldValue, nil)
+		api.On("KVCompareAndSet", rateLimitPrefix+"key1", oldValue, []byte(`{"count":1,"start":1234567890}`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Increment("key1", time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, 1, count)
+	})
+	t.Run("concurrent increment", func(t *testing.T) {
+		oldValue := []byte(`{"count":1,"start":1234000000}`)
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(nil, nil).Once()
+		api.On("KVCompareAndSet", rateLimitPrefix+"key1", []byte(nil), []byte(`{"count":1,"start":1234567890}`)).Return(false, nil)
+		api.On("KVGet", rateLimitPrefix+"key1").Return(oldValue, nil).Once()
+		api.On("KVCompareAndSet", rateLimitPrefix+"key1", oldValue, []byte(`{"count":2,"start":1234000000}`)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Increment("key1", time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, 2, count)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Increment("key1", time.Hour)
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, count)
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", rateLimitPrefix+"key1").Return(nil, nil)
+		api.On("KVCompareAndSet", rateLimitPrefix+"key1", []byte(nil), []byte(`{"count":1,"start":1234567890}`)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		count, err := store.RateLimit().Increment("key1", time.Hour)
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
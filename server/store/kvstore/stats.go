@@ -0,0 +1,67 @@
+package kvstore
+
+import (
+	"errors"
+
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/stats"
+)
+
+// StatsStore allows to access the aggregate statistics of all polls in the KV Store.
+type StatsStore struct {
+	api plugin.API
+}
+
+// statsKey is the key that stores the statistics of all polls
+const statsKey = "stats"
+
+// NewStatsStore returns a Stats Store that uses the KV Store of a given plugin API.
+func NewStatsStore(api plugin.API) *StatsStore {
+	return &StatsStore{api: api}
+}
+
+// Get returns the statistics. It returns nil if no statistics have been stored yet.
+func (s *StatsStore) Get() (*stats.Stats, error) {
+	st, _, err := s.load()
+	return st, err
+}
+
+// Update atomically applies update to the statistics and stores the result.
+// If the statistics were changed by someone else in the meantime, update is applied again to the latest version.
+func (s *StatsStore) Update(update func(*stats.Stats)) error {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		st, oldValue, err := s.load()
+		if err != nil {
+			return err
+		}
+		if st == nil {
+			st = stats.New()
+		}
+
+		update(st)
+		ok, appErr := s.api.KVCompareAndSet(statsKey, oldValue, st.EncodeToByte())
+		if appErr != nil {
+			return appErr
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errors.New("too many concurrent updates")
+}
+
+// load returns the statistics together with the raw value they were decoded from. Both are nil if no statistics have been stored yet.
+func (s *StatsStore) load() (*stats.Stats, []byte, error) {
+	b, appErr := s.api.KVGet(statsKey)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	if b == nil {
+		return nil, nil, nil
+	}
+	st := stats.DecodeStatsFromByte(b)
+	if st == nil {
+		return nil, nil, errors.New("failed to decode statistics")
+	}
+	return st, b, nil
+}
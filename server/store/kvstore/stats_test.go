@@ -0,0 +1,113 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsStoreGet(t *testing.T) {
+	stored := stats.New()
+	stored.PollsPerChannel["channelID1"] = 2
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(stored.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		st, err := store.Stats().Get()
+		require.Nil(t, err)
+		assert.Equal(t, stored, st)
+	})
+	t.Run("no statistics stored", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		st, err := store.Stats().Get()
+		require.Nil(t, err)
+		assert.Nil(t, st)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		st, err := store.Stats().Get()
+		assert.NotNil(t, err)
+		assert.Nil(t, st)
+	})
+	t.Run("Decode fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return([]byte{}, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		st, err := store.Stats().Get()
+		assert.NotNil(t, err)
+		assert.Nil(t, st)
+	})
+}
+
+func TestStatsStoreUpdate(t *testing.T) {
+	addPoll := func(st *stats.Stats) { st.PollsPerChannel["channelID1"]++ }
+	withPolls := func(n int) []byte {
+		st := stats.New()
+		st.PollsPerChannel["channelID1"] = n
+		return st.EncodeToByte()
+	}
+
+	t.Run("first update", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(nil, nil)
+		api.On("KVCompareAndSet", statsKey, []byte(nil), withPolls(1)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Stats().Update(addPoll))
+	})
+	t.Run("existing statistics", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(withPolls(1), nil)
+		api.On("KVCompareAndSet", statsKey, withPolls(1), withPolls(2)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Stats().Update(addPoll))
+	})
+	t.Run("concurrent update", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(withPolls(1), nil).Once()
+		api.On("KVCompareAndSet", statsKey, withPolls(1), withPolls(2)).Return(false, nil)
+		api.On("KVGet", statsKey).Return(withPolls(2), nil).Once()
+		api.On("KVCompareAndSet", statsKey, withPolls(2), withPolls(3)).Return(true, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Stats().Update(addPoll))
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Stats().Update(addPoll))
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", statsKey).Return(nil, nil)
+		api.On("KVCompareAndSet", statsKey, []byte(nil), withPolls(1)).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Stats().Update(addPoll))
+	})
+}
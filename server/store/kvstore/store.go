@@ -7,20 +7,37 @@ import (
 
 // Store is an interface to interact with the KV Store.
 type Store struct {
-	api         plugin.API
-	pollStore   PollStore
-	systemStore SystemStore
+	api            plugin.API
+	pollStore      PollStore
+	jobStore       JobStore
+	systemStore    SystemStore
+	auditStore     AuditStore
+	rateLimitStore RateLimitStore
+	channelStore   ChannelStore
+	teamStore      TeamStore
+	draftStore     DraftStore
+	voteStore      VoteStore
+	statsStore     StatsStore
+	leaderStore    LeaderStore
 }
 
 // NewStore returns a fresh store and upgrades the db from the given schema version.
 func NewStore(api plugin.API, pluginVersion string) (store.Store, error) {
 	store := Store{
-		api:         api,
-		pollStore:   PollStore{api: api},
-		systemStore: SystemStore{api: api},
+		api:            api,
+		pollStore:      PollStore{api: api},
+		jobStore:       JobStore{api: api},
+		systemStore:    SystemStore{api: api},
+		auditStore:     AuditStore{api: api},
+		rateLimitStore: RateLimitStore{api: api},
+		channelStore:   ChannelStore{api: api},
+		teamStore:      TeamStore{api: api},
+		draftStore:     DraftStore{api: api},
+		voteStore:      VoteStore{api: api},
+		statsStore:     StatsStore{api: api},
+		leaderStore:    LeaderStore{api: api},
 	}
-	err := store.UpdateDatabase(pluginVersion)
-	if err != nil {
+	if err := store.UpdateDatabase(pluginVersion); err != nil {
 		return nil, err
 	}
 
@@ -30,5 +47,32 @@ func NewStore(api plugin.API, pluginVersion string) (store.Store, error) {
 // Poll returns the Poll Store
 func (s *Store) Poll() store.PollStore { return &s.pollStore }
 
+// Job returns the Job Store
+func (s *Store) Job() store.JobStore { return &s.jobStore }
+
 // System returns the System Store
 func (s *Store) System() store.SystemStore { return &s.systemStore }
+
+// Audit returns the Audit Store
+func (s *Store) Audit() store.AuditStore { return &s.auditStore }
+
+// RateLimit returns the Rate Limit Store
+func (s *Store) RateLimit() store.RateLimitStore { return &s.rateLimitStore }
+
+// Channel returns the Channel Store
+func (s *Store) Channel() store.ChannelStore { return &s.channelStore }
+
+// Team returns the Team Store
+func (s *Store) Team() store.TeamStore { return &s.teamStore }
+
+// Draft returns the Draft Store
+func (s *Store) Draft() store.DraftStore { return &s.draftStore }
+
+// Vote returns the Vote Store
+func (s *Store) Vote() store.VoteStore { return &s.voteStore }
+
+// Stats returns the Stats Store
+func (s *Store) Stats() store.StatsStore { return &s.statsStore }
+
+// Leader returns the Leader Store
+func (s *Store) Leader() store.LeaderStore { return &s.leaderStore }
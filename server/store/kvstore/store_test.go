@@ -5,7 +5,9 @@ import (
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func setupTestStore(api *plugintest.API) *Store {
@@ -14,9 +16,36 @@ func setupTestStore(api *plugintest.API) *Store {
 		pollStore: PollStore{
 			api: api,
 		},
+		jobStore: JobStore{
+			api: api,
+		},
 		systemStore: SystemStore{
 			api: api,
 		},
+		auditStore: AuditStore{
+			api: api,
+		},
+		rateLimitStore: RateLimitStore{
+			api: api,
+		},
+		channelStore: ChannelStore{
+			api: api,
+		},
+		teamStore: TeamStore{
+			api: api,
+		},
+		draftStore: DraftStore{
+			api: api,
+		},
+		voteStore: VoteStore{
+			api: api,
+		},
+		statsStore: StatsStore{
+			api: api,
+		},
+		leaderStore: LeaderStore{
+			api: api,
+		},
 	}
 	return &store
 }
@@ -24,19 +53,80 @@ func setupTestStore(api *plugintest.API) *Store {
 func TestNewStore(t *testing.T) {
 	t.Run("all fine", func(t *testing.T) {
 		api := &plugintest.API{}
-		api.On("KVGet", versionKey).Return([]byte("1.0.0"), nil)
+		api.On("KVGet", versionKey).Return([]byte("1.2.0"), nil)
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, "1.2.0")
+		assert.Nil(t, err)
+		assert.NotNil(t, store)
+	})
+	t.Run("indexes get built on upgrade", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte("1.1.0"), nil)
+		api.On("KVList", 0, listPerPage).Return([]string{}, nil)
+		api.On("KVSet", versionKey, []byte("1.2.0")).Return(nil)
+		api.On("LogWarn", mock.AnythingOfType("string")).Return(nil)
 		defer api.AssertExpectations(t)
 
-		store, err := NewStore(api, "1.0.0")
+		store, err := NewStore(api, "1.2.0")
 		assert.Nil(t, err)
 		assert.NotNil(t, store)
 	})
+
+	posted := testutils.GetPoll()
+	posted.ChannelID = "channelID1"
+	pollShard := indexShardKey(pollIndexPrefix, posted.ID)
+	questionShard := indexShardKey(questionIndexPrefix, posted.ID)
+
+	t.Run("building poll index fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte("1.1.0"), nil)
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + posted.ID}, nil)
+		api.On("KVGet", pollPrefix+posted.ID).Return(posted.EncodeToByte(), nil)
+		api.On("KVGet", pollShard).Return(nil, &model.AppError{})
+		api.On("LogWarn", mock.AnythingOfType("string")).Return(nil)
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, "1.2.0")
+		assert.NotNil(t, err)
+		assert.Nil(t, store)
+	})
+	t.Run("building question index fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte("1.1.0"), nil)
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + posted.ID}, nil)
+		api.On("KVGet", pollPrefix+posted.ID).Return(posted.EncodeToByte(), nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+posted.ID+`":1234567890}`), nil)
+		api.On("KVGet", questionShard).Return(nil, &model.AppError{})
+		api.On("LogWarn", mock.AnythingOfType("string")).Return(nil)
+		defer api.AssertExpectations(t)
+
+		// The schema version isn't saved until all indexes are built
+		store, err := NewStore(api, "1.2.0")
+		assert.NotNil(t, err)
+		assert.Nil(t, store)
+	})
+	t.Run("building channel indexes fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte("1.1.0"), nil)
+		api.On("KVList", 0, listPerPage).Return([]string{pollPrefix + posted.ID}, nil)
+		api.On("KVGet", pollPrefix+posted.ID).Return(posted.EncodeToByte(), nil)
+		api.On("KVGet", pollShard).Return([]byte(`{"`+posted.ID+`":1234567890}`), nil)
+		api.On("KVGet", questionShard).Return([]byte(`{"`+posted.ID+`":"Question"}`), nil)
+		api.On("KVGet", channelIndexPrefix+"channelID1").Return(nil, &model.AppError{})
+		api.On("LogWarn", mock.AnythingOfType("string")).Return(nil)
+		defer api.AssertExpectations(t)
+
+		store, err := NewStore(api, "1.2.0")
+		assert.NotNil(t, err)
+		assert.Nil(t, store)
+	})
 	t.Run("UpdateDatabase() fails", func(t *testing.T) {
 		api := &plugintest.API{}
 		api.On("KVGet", versionKey).Return([]byte{}, &model.AppError{})
 		defer api.AssertExpectations(t)
 
-		store, err := NewStore(api, "1.0.0")
+		store, err := NewStore(api, "1.2.0")
 		assert.NotNil(t, err)
 		assert.Nil(t, store)
 	})
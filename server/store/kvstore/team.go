@@ -0,0 +1,52 @@
+package kvstore
+
+import (
+	"errors"
+
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/team"
+)
+
+// TeamStore allows to access the Matterpoll settings of teams in the KV Store.
+type TeamStore struct {
+	api plugin.API
+}
+
+// teamDefaultsPrefix is the prefix of the keys that store the defaults of teams
+const teamDefaultsPrefix = "teamdefaults_"
+
+// NewTeamStore returns a Team Store that uses the KV Store of a given plugin API.
+func NewTeamStore(api plugin.API) *TeamStore {
+	return &TeamStore{api: api}
+}
+
+// GetDefaults returns the defaults of a given team. It returns nil if the team doesn't override any defaults.
+func (s *TeamStore) GetDefaults(teamID string) (*team.Defaults, error) {
+	b, appErr := s.api.KVGet(teamDefaultsPrefix + teamID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if b == nil {
+		return nil, nil
+	}
+	defaults := team.DecodeDefaultsFromByte(b)
+	if defaults == nil {
+		return nil, errors.New("failed to decode team defaults")
+	}
+	return defaults, nil
+}
+
+// SaveDefaults stores the defaults of a given team.
+// Only teams that override defaults are stored, hence empty defaults remove the key of the team.
+func (s *TeamStore) SaveDefaults(teamID string, defaults *team.Defaults) error {
+	if defaults.IsEmpty() {
+		if appErr := s.api.KVDelete(teamDefaultsPrefix + teamID); appErr != nil {
+			return appErr
+		}
+		return nil
+	}
+	if appErr := s.api.KVSet(teamDefaultsPrefix+teamID, defaults.EncodeToByte()); appErr != nil {
+		return appErr
+	}
+	return nil
+}
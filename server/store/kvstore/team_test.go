@@ -0,0 +1,95 @@
+package kvstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/team"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamStoreGetDefaults(t *testing.T) {
+	anonymous := true
+	defaults := &team.Defaults{Anonymous: &anonymous}
+
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", teamDefaultsPrefix+"teamID1").Return(defaults.EncodeToByte(), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Team().GetDefaults("teamID1")
+		require.Nil(t, err)
+		assert.Equal(t, defaults, d)
+	})
+	t.Run("no defaults", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", teamDefaultsPrefix+"teamID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Team().GetDefaults("teamID1")
+		require.Nil(t, err)
+		assert.Nil(t, d)
+	})
+	t.Run("invalid defaults", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", teamDefaultsPrefix+"teamID1").Return([]byte("{"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Team().GetDefaults("teamID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, d)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", teamDefaultsPrefix+"teamID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		d, err := store.Team().GetDefaults("teamID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, d)
+	})
+}
+
+func TestTeamStoreSaveDefaults(t *testing.T) {
+	anonymous := true
+	defaults := &team.Defaults{Anonymous: &anonymous}
+
+	t.Run("save", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", teamDefaultsPrefix+"teamID1", defaults.EncodeToByte()).Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Team().SaveDefaults("teamID1", defaults))
+	})
+	t.Run("empty defaults", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", teamDefaultsPrefix+"teamID1").Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Team().SaveDefaults("teamID1", &team.Defaults{}))
+	})
+	t.Run("KVSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVSet", teamDefaultsPrefix+"teamID1", defaults.EncodeToByte()).Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Team().SaveDefaults("teamID1", defaults))
+	})
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", teamDefaultsPrefix+"teamID1").Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Team().SaveDefaults("teamID1", &team.Defaults{}))
+	})
+}
@@ -4,8 +4,35 @@ import (
 	"fmt"
 
 	"github.com/blang/semver"
+	"github.com/pkg/errors"
 )
 
+// migration transforms the data in the KV Store to the schema of a given version
+type migration struct {
+	// version is the schema version the data has once the migration is done. Patch versions aren't used.
+	version semver.Version
+	// migrate transforms the data. If it fails half way, it runs again on the next activation,
+	// hence it must skip data that has already been transformed.
+	migrate func(s *Store) error
+}
+
+// migrations lists the migrations of the KV Store in ascending order of their versions.
+// To change the format of stored data, append a migration with the version of the next plugin release.
+// Several migrations may share a version.
+var migrations = []migration{{
+	// Polls stored before the index of all polls was introduced are added to it
+	version: semver.MustParse("1.2.0"),
+	migrate: func(s *Store) error { return s.pollStore.buildIndex() },
+}, {
+	// Polls stored before the index of all questions was introduced are added to it
+	version: semver.MustParse("1.2.0"),
+	migrate: func(s *Store) error { return s.pollStore.buildQuestionIndex() },
+}, {
+	// Polls that were running before the indexes of channels were introduced are added to them
+	version: semver.MustParse("1.2.0"),
+	migrate: func(s *Store) error { return s.pollStore.buildChannelIndexes() },
+}}
+
 // UpdateDatabase upgrades the database schema from a given version to the newest version.
 func (s *Store) UpdateDatabase(pluginVersion string) error {
 	v, err := s.System().GetVersion()
@@ -22,14 +49,32 @@ func (s *Store) UpdateDatabase(pluginVersion string) error {
 		return s.System().SaveVersion(newestSchema.String())
 	}
 
-	// TODO: Uncomment following condition when version 1.1.0 is released
-	/*
-		currentSchemaVersion := semver.MustParse(v)
-		if err := s.UpgradeDatabaseToVersion11(currentSchemaVersion); err != nil {
+	currentSchemaVersion, err := semver.Parse(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse database schema version")
+	}
+	return s.runMigrations(currentSchemaVersion, migrations)
+}
+
+// runMigrations runs the given migrations whose version is newer than a given schema version in order.
+// The schema version is saved once all migrations of a version are done, so a failed migration doesn't repeat the versions before.
+func (s *Store) runMigrations(currentSchemaVersion semver.Version, migrations []migration) error {
+	for i, m := range migrations {
+		if !s.shouldPerformUpgrade(currentSchemaVersion, m.version) {
+			continue
+		}
+		if err := m.migrate(s); err != nil {
+			return errors.Wrapf(err, "failed to upgrade database schema to version %v", m.version.String())
+		}
+		if i+1 < len(migrations) && migrations[i+1].version.EQ(m.version) {
+			continue
+		}
+		if err := s.System().SaveVersion(m.version.String()); err != nil {
 			return err
 		}
-	*/
-
+		s.api.LogWarn(fmt.Sprintf("Upgraded the database schema to version %v.", m.version.String()))
+		currentSchemaVersion = m.version
+	}
 	return nil
 }
 
@@ -41,16 +86,3 @@ func (s *Store) shouldPerformUpgrade(currentSchemaVersion semver.Version, expect
 	}
 	return false
 }
-
-/*
-func (s *Store) UpgradeDatabaseToVersion11(currentSchemaVersion semver.Version) error {
-	if s.shouldPerformUpgrade(currentSchemaVersion, semver.MustParse("1.1.0")) {
-		// Do migration
-    s.api.LogWarn("Update complete")
-		if err := s.System().SaveVersion("1.1.0"); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-*/
@@ -1,6 +1,7 @@
 package kvstore
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/blang/semver"
@@ -33,11 +34,11 @@ func TestStoreShouldPerformUpgrade(t *testing.T) {
 func TestStoreUpdateDatabase(t *testing.T) {
 	t.Run("Old install", func(t *testing.T) {
 		api := &plugintest.API{}
-		api.On("KVGet", versionKey).Return([]byte("1.0.0"), nil)
+		api.On("KVGet", versionKey).Return([]byte("1.2.0"), nil)
 		defer api.AssertExpectations(t)
 		store := setupTestStore(api)
 
-		err := store.UpdateDatabase("1.0.0")
+		err := store.UpdateDatabase("1.2.0")
 		assert.Nil(t, err)
 	})
 	t.Run("Fresh install", func(t *testing.T) {
@@ -82,4 +83,92 @@ func TestStoreUpdateDatabase(t *testing.T) {
 		err := store.UpdateDatabase("1.0.0")
 		assert.NotNil(t, err)
 	})
+	t.Run("Invalid version", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", versionKey).Return([]byte("latest"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		err := store.UpdateDatabase("1.0.0")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestStoreRunMigrations(t *testing.T) {
+	makeMigrations := func(ran *[]string, failing string) []migration {
+		migrations := []migration{}
+		for _, v := range []string{"1.1.0", "1.2.0", "1.2.0", "1.3.0"} {
+			version := v
+			migrations = append(migrations, migration{
+				version: semver.MustParse(version),
+				migrate: func(_ *Store) error {
+					if version == failing {
+						return errors.New("")
+					}
+					*ran = append(*ran, version)
+					return nil
+				},
+			})
+		}
+		return migrations
+	}
+
+	for name, test := range map[string]struct {
+		SetupAPI    func(*plugintest.API) *plugintest.API
+		Current     string
+		Failing     string
+		ExpectedRan []string
+		ShouldError bool
+	}{
+		"Run newer migrations in order": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				// The version is saved once both migrations of 1.2.0 are done
+				api.On("KVSet", versionKey, []byte("1.2.0")).Return(nil).Once()
+				api.On("KVSet", versionKey, []byte("1.3.0")).Return(nil)
+				return api
+			},
+			Current:     "1.1.0",
+			ExpectedRan: []string{"1.2.0", "1.2.0", "1.3.0"},
+		},
+		"Up to date": {
+			SetupAPI:    func(api *plugintest.API) *plugintest.API { return api },
+			Current:     "1.3.0",
+			ExpectedRan: []string{},
+		},
+		"Migration fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("KVSet", versionKey, []byte("1.1.0")).Return(nil)
+				return api
+			},
+			Current:     "1.0.0",
+			Failing:     "1.2.0",
+			ExpectedRan: []string{"1.1.0"},
+			ShouldError: true,
+		},
+		"SaveVersion fails": {
+			SetupAPI: func(api *plugintest.API) *plugintest.API {
+				api.On("KVSet", versionKey, []byte("1.3.0")).Return(&model.AppError{})
+				return api
+			},
+			Current:     "1.2.0",
+			ExpectedRan: []string{"1.3.0"},
+			ShouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := test.SetupAPI(&plugintest.API{})
+			api.On("LogWarn", mock.AnythingOfType("string")).Return(nil).Maybe()
+			defer api.AssertExpectations(t)
+			store := setupTestStore(api)
+
+			ran := []string{}
+			err := store.runMigrations(semver.MustParse(test.Current), makeMigrations(&ran, test.Failing))
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.ExpectedRan, ran)
+		})
+	}
 }
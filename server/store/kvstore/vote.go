@@ -0,0 +1,88 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// VoteStore allows to access the index of the polls every user voted in in the KV Store.
+type VoteStore struct {
+	api plugin.API
+}
+
+const (
+	// userVotesPrefix is the prefix of the keys that store the IDs of the polls a user voted in, most recent first
+	userVotesPrefix = "uservotes_"
+	// maxUserVotes is the number of polls kept per user. Older ones are dropped from the index.
+	maxUserVotes = 25
+)
+
+// NewVoteStore returns a Vote Store that uses the KV Store of a given plugin API.
+func NewVoteStore(api plugin.API) *VoteStore {
+	return &VoteStore{api: api}
+}
+
+// Record moves a given poll to the front of the polls a given user voted in. Only the most recent maxUserVotes polls are kept.
+func (s *VoteStore) Record(userID, pollID string) error {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		ids, oldValue, err := s.get(userID)
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 && ids[0] == pollID {
+			return nil
+		}
+
+		newIDs := []string{pollID}
+		for _, id := range ids {
+			if id != pollID && len(newIDs) < maxUserVotes {
+				newIDs = append(newIDs, id)
+			}
+		}
+
+		newValue, err := json.Marshal(newIDs)
+		if err != nil {
+			return err
+		}
+		ok, appErr := s.api.KVCompareAndSet(userVotesPrefix+userID, oldValue, newValue)
+		if appErr != nil {
+			return appErr
+		}
+		if ok {
+			return nil
+		}
+	}
+	return errors.New("too many concurrent updates")
+}
+
+// List returns the IDs of the polls a given user voted in, most recent first.
+func (s *VoteStore) List(userID string) ([]string, error) {
+	ids, _, err := s.get(userID)
+	return ids, err
+}
+
+// Delete removes the index of a given user.
+func (s *VoteStore) Delete(userID string) error {
+	if appErr := s.api.KVDelete(userVotesPrefix + userID); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// get returns the poll IDs of the index of a given user together with its stored value
+func (s *VoteStore) get(userID string) ([]string, []byte, error) {
+	b, appErr := s.api.KVGet(userVotesPrefix + userID)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	ids := []string{}
+	if b == nil {
+		return ids, nil, nil
+	}
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return nil, nil, err
+	}
+	return ids, b, nil
+}
@@ -0,0 +1,153 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteStoreRecord(t *testing.T) {
+	encode := func(ids ...string) []byte {
+		b, _ := json.Marshal(ids)
+		return b
+	}
+	manyIDs := []string{}
+	for i := 0; i < maxUserVotes; i++ {
+		manyIDs = append(manyIDs, fmt.Sprintf("otherPollID%d", i))
+	}
+
+	for name, test := range map[string]struct {
+		OldValue    []byte
+		NewValue    []byte
+		ShouldError bool
+	}{
+		"first vote": {
+			OldValue: nil,
+			NewValue: encode("pollID1"),
+		},
+		"new poll": {
+			OldValue: encode("pollID2", "pollID3"),
+			NewValue: encode("pollID1", "pollID2", "pollID3"),
+		},
+		"poll moves to the front": {
+			OldValue: encode("pollID2", "pollID1", "pollID3"),
+			NewValue: encode("pollID1", "pollID2", "pollID3"),
+		},
+		"oldest poll is dropped": {
+			OldValue: encode(manyIDs...),
+			NewValue: encode(append([]string{"pollID1"}, manyIDs[:maxUserVotes-1]...)...),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			api := &plugintest.API{}
+			api.On("KVGet", userVotesPrefix+"userID1").Return(test.OldValue, nil)
+			api.On("KVCompareAndSet", userVotesPrefix+"userID1", test.OldValue, test.NewValue).Return(true, nil)
+			defer api.AssertExpectations(t)
+			store := setupTestStore(api)
+
+			assert.Nil(t, store.Vote().Record("userID1", "pollID1"))
+		})
+	}
+
+	t.Run("poll is already in front", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return(encode("pollID1", "pollID2"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Vote().Record("userID1", "pollID1"))
+	})
+	t.Run("concurrent update", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return(nil, nil)
+		api.On("KVCompareAndSet", userVotesPrefix+"userID1", []byte(nil), encode("pollID1")).Return(false, nil).Times(maxUpdateAttempts)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Vote().Record("userID1", "pollID1"))
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Vote().Record("userID1", "pollID1"))
+	})
+	t.Run("KVCompareAndSet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return(nil, nil)
+		api.On("KVCompareAndSet", userVotesPrefix+"userID1", []byte(nil), encode("pollID1")).Return(false, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Vote().Record("userID1", "pollID1"))
+	})
+}
+
+func TestVoteStoreList(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return([]byte(`["pollID1","pollID2"]`), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Vote().List("userID1")
+		require.Nil(t, err)
+		assert.Equal(t, []string{"pollID1", "pollID2"}, ids)
+	})
+	t.Run("no votes", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return(nil, nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Vote().List("userID1")
+		require.Nil(t, err)
+		assert.Equal(t, []string{}, ids)
+	})
+	t.Run("invalid index", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return([]byte("{"), nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Vote().List("userID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, ids)
+	})
+	t.Run("KVGet() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", userVotesPrefix+"userID1").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		ids, err := store.Vote().List("userID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, ids)
+	})
+}
+
+func TestVoteStoreDelete(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", userVotesPrefix+"userID1").Return(nil)
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.Nil(t, store.Vote().Delete("userID1"))
+	})
+	t.Run("KVDelete() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVDelete", userVotesPrefix+"userID1").Return(&model.AppError{})
+		defer api.AssertExpectations(t)
+		store := setupTestStore(api)
+
+		assert.NotNil(t, store.Vote().Delete("userID1"))
+	})
+}
@@ -0,0 +1,406 @@
+package metricsstore
+
+import (
+	"io"
+	"time"
+
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/metrics"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/team"
+)
+
+// Store wraps another store and records the latency of every operation.
+type Store struct {
+	store          store.Store
+	pollStore      PollStore
+	jobStore       JobStore
+	systemStore    SystemStore
+	auditStore     AuditStore
+	rateLimitStore RateLimitStore
+	channelStore   ChannelStore
+	teamStore      TeamStore
+	draftStore     DraftStore
+	voteStore      VoteStore
+	statsStore     StatsStore
+	leaderStore    LeaderStore
+}
+
+// NewStore returns a store that records the latency of all operations of a given store in m.
+func NewStore(s store.Store, m *metrics.Metrics) store.Store {
+	return &Store{
+		store:          s,
+		pollStore:      PollStore{store: s.Poll(), metrics: m},
+		jobStore:       JobStore{store: s.Job(), metrics: m},
+		systemStore:    SystemStore{store: s.System(), metrics: m},
+		auditStore:     AuditStore{store: s.Audit(), metrics: m},
+		rateLimitStore: RateLimitStore{store: s.RateLimit(), metrics: m},
+		channelStore:   ChannelStore{store: s.Channel(), metrics: m},
+		teamStore:      TeamStore{store: s.Team(), metrics: m},
+		draftStore:     DraftStore{store: s.Draft(), metrics: m},
+		voteStore:      VoteStore{store: s.Vote(), metrics: m},
+		statsStore:     StatsStore{store: s.Stats(), metrics: m},
+		leaderStore:    LeaderStore{store: s.Leader(), metrics: m},
+	}
+}
+
+// Poll returns the Poll Store
+func (s *Store) Poll() store.PollStore { return &s.pollStore }
+
+// Job returns the Job Store
+func (s *Store) Job() store.JobStore { return &s.jobStore }
+
+// System returns the System Store
+func (s *Store) System() store.SystemStore { return &s.systemStore }
+
+// Audit returns the Audit Store
+func (s *Store) Audit() store.AuditStore { return &s.auditStore }
+
+// RateLimit returns the Rate Limit Store
+func (s *Store) RateLimit() store.RateLimitStore { return &s.rateLimitStore }
+
+// Channel returns the Channel Store
+func (s *Store) Channel() store.ChannelStore { return &s.channelStore }
+
+// Team returns the Team Store
+func (s *Store) Team() store.TeamStore { return &s.teamStore }
+
+// Draft returns the Draft Store
+func (s *Store) Draft() store.DraftStore { return &s.draftStore }
+
+// Vote returns the Vote Store
+func (s *Store) Vote() store.VoteStore { return &s.voteStore }
+
+// Stats returns the Stats Store
+func (s *Store) Stats() store.StatsStore { return &s.statsStore }
+
+// Leader returns the Leader Store
+func (s *Store) Leader() store.LeaderStore { return &s.leaderStore }
+
+// Close closes the wrapped store, if it needs to be closed
+func (s *Store) Close() error {
+	if closer, ok := s.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// observe records the time since start for a given operation
+func observe(m *metrics.Metrics, operation string, start time.Time) {
+	m.ObserveStoreDuration(operation, time.Since(start))
+}
+
+// PollStore records the latency of all operations of a Poll Store.
+type PollStore struct {
+	store   store.PollStore
+	metrics *metrics.Metrics
+}
+
+// Get returns the poll for a given id.
+func (s *PollStore) Get(id string) (*poll.Poll, error) {
+	defer observe(s.metrics, "poll_get", time.Now())
+	return s.store.Get(id)
+}
+
+// List returns all polls.
+func (s *PollStore) List() ([]*poll.Poll, error) {
+	defer observe(s.metrics, "poll_list", time.Now())
+	return s.store.List()
+}
+
+// ListByChannel returns all polls of a given channel.
+func (s *PollStore) ListByChannel(channelID string) ([]*poll.Poll, error) {
+	defer observe(s.metrics, "poll_list_by_channel", time.Now())
+	return s.store.ListByChannel(channelID)
+}
+
+// ListPage returns a page of all polls.
+func (s *PollStore) ListPage(page, perPage int) ([]*poll.Poll, int, error) {
+	defer observe(s.metrics, "poll_list_page", time.Now())
+	return s.store.ListPage(page, perPage)
+}
+
+// Save saves a poll.
+func (s *PollStore) Save(poll *poll.Poll) error {
+	defer observe(s.metrics, "poll_save", time.Now())
+	return s.store.Save(poll)
+}
+
+// Update applies a given function to the latest version of a poll and saves the result.
+func (s *PollStore) Update(id string, update func(*poll.Poll) error) (*poll.Poll, error) {
+	defer observe(s.metrics, "poll_update", time.Now())
+	return s.store.Update(id, update)
+}
+
+// Delete deletes a poll.
+func (s *PollStore) Delete(poll *poll.Poll) error {
+	defer observe(s.metrics, "poll_delete", time.Now())
+	return s.store.Delete(poll)
+}
+
+// Archive moves an ended poll out of the active polls.
+func (s *PollStore) Archive(poll *poll.Poll) error {
+	defer observe(s.metrics, "poll_archive", time.Now())
+	return s.store.Archive(poll)
+}
+
+// Unarchive moves an archived poll back to the active polls.
+func (s *PollStore) Unarchive(id string) error {
+	defer observe(s.metrics, "poll_unarchive", time.Now())
+	return s.store.Unarchive(id)
+}
+
+// ListArchived returns all archived polls.
+func (s *PollStore) ListArchived() ([]*poll.Poll, error) {
+	defer observe(s.metrics, "poll_list_archived", time.Now())
+	return s.store.ListArchived()
+}
+
+// Search returns all polls whose question matches a given text.
+func (s *PollStore) Search(text string) ([]*poll.Poll, error) {
+	defer observe(s.metrics, "poll_search", time.Now())
+	return s.store.Search(text)
+}
+
+// ListByTag returns all polls that are tagged with a given tag.
+func (s *PollStore) ListByTag(tag string) ([]*poll.Poll, error) {
+	defer observe(s.metrics, "poll_list_by_tag", time.Now())
+	return s.store.ListByTag(tag)
+}
+
+// JobStore records the latency of all operations of a Job Store.
+type JobStore struct {
+	store   store.JobStore
+	metrics *metrics.Metrics
+}
+
+// Get returns the job for a given id.
+func (s *JobStore) Get(id string) (*job.Job, error) {
+	defer observe(s.metrics, "job_get", time.Now())
+	return s.store.Get(id)
+}
+
+// List returns all jobs.
+func (s *JobStore) List() ([]*job.Job, error) {
+	defer observe(s.metrics, "job_list", time.Now())
+	return s.store.List()
+}
+
+// Claim claims a job for this plugin instance.
+func (s *JobStore) Claim(job *job.Job) (bool, error) {
+	defer observe(s.metrics, "job_claim", time.Now())
+	return s.store.Claim(job)
+}
+
+// Save saves a job.
+func (s *JobStore) Save(job *job.Job) error {
+	defer observe(s.metrics, "job_save", time.Now())
+	return s.store.Save(job)
+}
+
+// Delete deletes a job.
+func (s *JobStore) Delete(job *job.Job) error {
+	defer observe(s.metrics, "job_delete", time.Now())
+	return s.store.Delete(job)
+}
+
+// SystemStore records the latency of all operations of a System Store.
+type SystemStore struct {
+	store   store.SystemStore
+	metrics *metrics.Metrics
+}
+
+// GetVersion returns the version of the store schema.
+func (s *SystemStore) GetVersion() (string, error) {
+	defer observe(s.metrics, "system_get_version", time.Now())
+	return s.store.GetVersion()
+}
+
+// SaveVersion saves the version of the store schema.
+func (s *SystemStore) SaveVersion(version string) error {
+	defer observe(s.metrics, "system_save_version", time.Now())
+	return s.store.SaveVersion(version)
+}
+
+// AuditStore records the latency of all operations of an Audit Store.
+type AuditStore struct {
+	store   store.AuditStore
+	metrics *metrics.Metrics
+}
+
+// List returns the entries of all polls.
+func (s *AuditStore) List() ([]*audit.Entry, error) {
+	defer observe(s.metrics, "audit_list", time.Now())
+	return s.store.List()
+}
+
+// ListByPoll returns the entries of a given poll.
+func (s *AuditStore) ListByPoll(pollID string) ([]*audit.Entry, error) {
+	defer observe(s.metrics, "audit_list_by_poll", time.Now())
+	return s.store.ListByPoll(pollID)
+}
+
+// Save saves an entry.
+func (s *AuditStore) Save(entry *audit.Entry) error {
+	defer observe(s.metrics, "audit_save", time.Now())
+	return s.store.Save(entry)
+}
+
+// AnonymizeUser removes a given user from all entries.
+func (s *AuditStore) AnonymizeUser(userID string) error {
+	defer observe(s.metrics, "audit_anonymize_user", time.Now())
+	return s.store.AnonymizeUser(userID)
+}
+
+// RateLimitStore records the latency of all operations of a Rate Limit Store.
+type RateLimitStore struct {
+	store   store.RateLimitStore
+	metrics *metrics.Metrics
+}
+
+// Get returns the counter of a given key.
+func (s *RateLimitStore) Get(key string, window time.Duration) (int, error) {
+	defer observe(s.metrics, "ratelimit_get", time.Now())
+	return s.store.Get(key, window)
+}
+
+// Increment increases the counter of a given key.
+func (s *RateLimitStore) Increment(key string, window time.Duration) (int, error) {
+	defer observe(s.metrics, "ratelimit_increment", time.Now())
+	return s.store.Increment(key, window)
+}
+
+// ChannelStore records the latency of all operations of a Channel Store.
+type ChannelStore struct {
+	store   store.ChannelStore
+	metrics *metrics.Metrics
+}
+
+// IsDisabled returns true if polls can't be created in a given channel.
+func (s *ChannelStore) IsDisabled(channelID string) (bool, error) {
+	defer observe(s.metrics, "channel_is_disabled", time.Now())
+	return s.store.IsDisabled(channelID)
+}
+
+// SetDisabled allows or disallows the creation of polls in a given channel.
+func (s *ChannelStore) SetDisabled(channelID string, disabled bool) error {
+	defer observe(s.metrics, "channel_set_disabled", time.Now())
+	return s.store.SetDisabled(channelID, disabled)
+}
+
+// GetCreators returns who may create polls in a given channel.
+func (s *ChannelStore) GetCreators(channelID string) (string, error) {
+	defer observe(s.metrics, "channel_get_creators", time.Now())
+	return s.store.GetCreators(channelID)
+}
+
+// SetCreators restricts who may create polls in a given channel.
+func (s *ChannelStore) SetCreators(channelID, creators string) error {
+	defer observe(s.metrics, "channel_set_creators", time.Now())
+	return s.store.SetCreators(channelID, creators)
+}
+
+// TeamStore records the latency of all operations of a Team Store.
+type TeamStore struct {
+	store   store.TeamStore
+	metrics *metrics.Metrics
+}
+
+// GetDefaults returns the defaults of a given team.
+func (s *TeamStore) GetDefaults(teamID string) (*team.Defaults, error) {
+	defer observe(s.metrics, "team_get_defaults", time.Now())
+	return s.store.GetDefaults(teamID)
+}
+
+// SaveDefaults stores the defaults of a given team.
+func (s *TeamStore) SaveDefaults(teamID string, defaults *team.Defaults) error {
+	defer observe(s.metrics, "team_save_defaults", time.Now())
+	return s.store.SaveDefaults(teamID, defaults)
+}
+
+// DraftStore records the latency of all operations of a Draft Store.
+type DraftStore struct {
+	store   store.DraftStore
+	metrics *metrics.Metrics
+}
+
+// Get returns the draft of a given user.
+func (s *DraftStore) Get(userID string) (*poll.Draft, error) {
+	defer observe(s.metrics, "draft_get", time.Now())
+	return s.store.Get(userID)
+}
+
+// Save stores the draft of a given user.
+func (s *DraftStore) Save(userID string, draft *poll.Draft) error {
+	defer observe(s.metrics, "draft_save", time.Now())
+	return s.store.Save(userID, draft)
+}
+
+// Delete removes the draft of a given user.
+func (s *DraftStore) Delete(userID string) error {
+	defer observe(s.metrics, "draft_delete", time.Now())
+	return s.store.Delete(userID)
+}
+
+// VoteStore records the latency of all operations of a Vote Store.
+type VoteStore struct {
+	store   store.VoteStore
+	metrics *metrics.Metrics
+}
+
+// Record moves a given poll to the front of the polls a given user voted in.
+func (s *VoteStore) Record(userID, pollID string) error {
+	defer observe(s.metrics, "vote_record", time.Now())
+	return s.store.Record(userID, pollID)
+}
+
+// List returns the IDs of the polls a given user voted in.
+func (s *VoteStore) List(userID string) ([]string, error) {
+	defer observe(s.metrics, "vote_list", time.Now())
+	return s.store.List(userID)
+}
+
+// Delete removes the index of a given user.
+func (s *VoteStore) Delete(userID string) error {
+	defer observe(s.metrics, "vote_delete", time.Now())
+	return s.store.Delete(userID)
+}
+
+// StatsStore records the latency of all operations of a Stats Store.
+type StatsStore struct {
+	store   store.StatsStore
+	metrics *metrics.Metrics
+}
+
+// Get returns the statistics.
+func (s *StatsStore) Get() (*stats.Stats, error) {
+	defer observe(s.metrics, "stats_get", time.Now())
+	return s.store.Get()
+}
+
+// Update atomically applies update to the statistics and stores the result.
+func (s *StatsStore) Update(update func(*stats.Stats)) error {
+	defer observe(s.metrics, "stats_update", time.Now())
+	return s.store.Update(update)
+}
+
+// LeaderStore records the latency of all operations of a Leader Store.
+type LeaderStore struct {
+	store   store.LeaderStore
+	metrics *metrics.Metrics
+}
+
+// Lead makes a given plugin instance the leader for a given lease.
+func (s *LeaderStore) Lead(instanceID string, lease time.Duration) (bool, error) {
+	defer observe(s.metrics, "leader_lead", time.Now())
+	return s.store.Lead(instanceID, lease)
+}
+
+// Resign ends the lease of a given plugin instance if it leads.
+func (s *LeaderStore) Resign(instanceID string) error {
+	defer observe(s.metrics, "leader_resign", time.Now())
+	return s.store.Resign(instanceID)
+}
@@ -0,0 +1,102 @@
+package metricsstore
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/metrics"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("operations are passed through and recorded", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		defer mockStore.AssertExpectations(t)
+		mockStore.PollStore.On("Get", testutils.GetPollID()).Return(testutils.GetPoll(), nil)
+		mockStore.PollStore.On("Save", testutils.GetPoll()).Return(&model.AppError{})
+		mockStore.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(testutils.GetPoll(), nil)
+		mockStore.JobStore.On("List").Return(nil, nil)
+		mockStore.SystemStore.On("GetVersion").Return("1.0.0", nil)
+		mockStore.AuditStore.On("ListByPoll", testutils.GetPollID()).Return(nil, nil)
+		mockStore.RateLimitStore.On("Increment", "polls_userID1", time.Hour).Return(2, nil)
+		mockStore.ChannelStore.On("IsDisabled", "channelID1").Return(true, nil)
+		mockStore.TeamStore.On("GetDefaults", "teamID1").Return(nil, nil)
+		mockStore.DraftStore.On("Get", "userID1").Return(poll.NewDraft(), nil)
+		mockStore.VoteStore.On("List", "userID1").Return([]string{testutils.GetPollID()}, nil)
+		mockStore.StatsStore.On("Get").Return(stats.New(), nil)
+		mockStore.LeaderStore.On("Lead", "instanceID1", time.Minute).Return(true, nil)
+		m := metrics.New()
+		s := NewStore(mockStore, m)
+
+		p, err := s.Poll().Get(testutils.GetPollID())
+		assert.Nil(t, err)
+		assert.Equal(t, testutils.GetPoll(), p)
+
+		err = s.Poll().Save(testutils.GetPoll())
+		assert.NotNil(t, err)
+
+		p, err = s.Poll().Update(testutils.GetPollID(), func(*poll.Poll) error { return nil })
+		assert.Nil(t, err)
+		assert.Equal(t, testutils.GetPoll(), p)
+
+		jobs, err := s.Job().List()
+		assert.Nil(t, err)
+		assert.Nil(t, jobs)
+
+		version, err := s.System().GetVersion()
+		assert.Nil(t, err)
+		assert.Equal(t, "1.0.0", version)
+
+		entries, err := s.Audit().ListByPoll(testutils.GetPollID())
+		assert.Nil(t, err)
+		assert.Nil(t, entries)
+
+		count, err := s.RateLimit().Increment("polls_userID1", time.Hour)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+
+		disabled, err := s.Channel().IsDisabled("channelID1")
+		assert.Nil(t, err)
+		assert.True(t, disabled)
+
+		defaults, err := s.Team().GetDefaults("teamID1")
+		assert.Nil(t, err)
+		assert.Nil(t, defaults)
+
+		draft, err := s.Draft().Get("userID1")
+		assert.Nil(t, err)
+		assert.Equal(t, poll.NewDraft(), draft)
+
+		pollIDs, err := s.Vote().List("userID1")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{testutils.GetPollID()}, pollIDs)
+
+		st, err := s.Stats().Get()
+		assert.Nil(t, err)
+		assert.Equal(t, stats.New(), st)
+
+		leads, err := s.Leader().Lead("instanceID1", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, leads)
+
+		var b bytes.Buffer
+		require.Nil(t, m.WriteTo(&b, 0))
+		for _, operation := range []string{"poll_get", "poll_save", "poll_update", "job_list", "system_get_version", "audit_list_by_poll", "ratelimit_increment", "channel_is_disabled", "team_get_defaults", "draft_get", "vote_list", "stats_get", "leader_lead"} {
+			assert.Contains(t, b.String(), "matterpoll_store_duration_seconds_count{operation=\""+operation+"\"} 1\n")
+		}
+	})
+
+	t.Run("close store without closer", func(t *testing.T) {
+		s := NewStore(&mockstore.Store{}, metrics.New())
+
+		assert.Nil(t, s.(*Store).Close())
+	})
+}
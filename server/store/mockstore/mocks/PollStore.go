@@ -12,6 +12,20 @@ type PollStore struct {
 	mock.Mock
 }
 
+// Archive provides a mock function with given fields: _a0
+func (_m *PollStore) Archive(_a0 *poll.Poll) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*poll.Poll) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: _a0
 func (_m *PollStore) Delete(_a0 *poll.Poll) error {
 	ret := _m.Called(_a0)
@@ -49,6 +63,128 @@ func (_m *PollStore) Get(id string) (*poll.Poll, error) {
 	return r0, r1
 }
 
+// List provides a mock function with given fields:
+func (_m *PollStore) List() ([]*poll.Poll, error) {
+	ret := _m.Called()
+
+	var r0 []*poll.Poll
+	if rf, ok := ret.Get(0).(func() []*poll.Poll); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*poll.Poll)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListArchived provides a mock function with given fields:
+func (_m *PollStore) ListArchived() ([]*poll.Poll, error) {
+	ret := _m.Called()
+
+	var r0 []*poll.Poll
+	if rf, ok := ret.Get(0).(func() []*poll.Poll); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*poll.Poll)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByChannel provides a mock function with given fields: channelID
+func (_m *PollStore) ListByChannel(channelID string) ([]*poll.Poll, error) {
+	ret := _m.Called(channelID)
+
+	var r0 []*poll.Poll
+	if rf, ok := ret.Get(0).(func(string) []*poll.Poll); ok {
+		r0 = rf(channelID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*poll.Poll)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(channelID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByTag provides a mock function with given fields: tag
+func (_m *PollStore) ListByTag(tag string) ([]*poll.Poll, error) {
+	ret := _m.Called(tag)
+
+	var r0 []*poll.Poll
+	if rf, ok := ret.Get(0).(func(string) []*poll.Poll); ok {
+		r0 = rf(tag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*poll.Poll)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tag)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPage provides a mock function with given fields: page, perPage
+func (_m *PollStore) ListPage(page int, perPage int) ([]*poll.Poll, int, error) {
+	ret := _m.Called(page, perPage)
+
+	var r0 []*poll.Poll
+	if rf, ok := ret.Get(0).(func(int, int) []*poll.Poll); ok {
+		r0 = rf(page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*poll.Poll)
+		}
+	}
+
+	var r1 int
+	if rf, ok := ret.Get(1).(func(int, int) int); ok {
+		r1 = rf(page, perPage)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int, int) error); ok {
+		r2 = rf(page, perPage)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Save provides a mock function with given fields: _a0
 func (_m *PollStore) Save(_a0 *poll.Poll) error {
 	ret := _m.Called(_a0)
@@ -62,3 +198,63 @@ func (_m *PollStore) Save(_a0 *poll.Poll) error {
 
 	return r0
 }
+
+// Search provides a mock function with given fields: text
+func (_m *PollStore) Search(text string) ([]*poll.Poll, error) {
+	ret := _m.Called(text)
+
+	var r0 []*poll.Poll
+	if rf, ok := ret.Get(0).(func(string) []*poll.Poll); ok {
+		r0 = rf(text)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*poll.Poll)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(text)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Unarchive provides a mock function with given fields: id
+func (_m *PollStore) Unarchive(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: id, update
+func (_m *PollStore) Update(id string, update func(*poll.Poll) error) (*poll.Poll, error) {
+	ret := _m.Called(id, update)
+
+	var r0 *poll.Poll
+	if rf, ok := ret.Get(0).(func(string, func(*poll.Poll) error) *poll.Poll); ok {
+		r0 = rf(id, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*poll.Poll)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, func(*poll.Poll) error) error); ok {
+		r1 = rf(id, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
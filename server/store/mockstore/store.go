@@ -8,18 +8,63 @@ import (
 
 // Store is a mock store
 type Store struct {
-	PollStore   mocks.PollStore
-	SystemStore mocks.SystemStore
+	PollStore      mocks.PollStore
+	JobStore       mocks.JobStore
+	SystemStore    mocks.SystemStore
+	AuditStore     mocks.AuditStore
+	RateLimitStore mocks.RateLimitStore
+	ChannelStore   mocks.ChannelStore
+	TeamStore      mocks.TeamStore
+	DraftStore     mocks.DraftStore
+	VoteStore      mocks.VoteStore
+	StatsStore     mocks.StatsStore
+	LeaderStore    mocks.LeaderStore
 }
 
 // Poll returns the Poll Store
 func (s *Store) Poll() store.PollStore { return &s.PollStore }
 
+// Job returns the Job Store
+func (s *Store) Job() store.JobStore { return &s.JobStore }
+
 // System returns the System Store
 func (s *Store) System() store.SystemStore { return &s.SystemStore }
 
+// Audit returns the Audit Store
+func (s *Store) Audit() store.AuditStore { return &s.AuditStore }
+
+// RateLimit returns the Rate Limit Store
+func (s *Store) RateLimit() store.RateLimitStore { return &s.RateLimitStore }
+
+// Channel returns the Channel Store
+func (s *Store) Channel() store.ChannelStore { return &s.ChannelStore }
+
+// Team returns the Team Store
+func (s *Store) Team() store.TeamStore { return &s.TeamStore }
+
+// Draft returns the Draft Store
+func (s *Store) Draft() store.DraftStore { return &s.DraftStore }
+
+// Vote returns the Vote Store
+func (s *Store) Vote() store.VoteStore { return &s.VoteStore }
+
+// Stats returns the Stats Store
+func (s *Store) Stats() store.StatsStore { return &s.StatsStore }
+
+// Leader returns the Leader Store
+func (s *Store) Leader() store.LeaderStore { return &s.LeaderStore }
+
 // AssertExpectations makes sure the expectations of all stores are meet
 func (s *Store) AssertExpectations(t mock.TestingT) {
 	s.PollStore.AssertExpectations(t)
+	s.JobStore.AssertExpectations(t)
 	s.SystemStore.AssertExpectations(t)
+	s.AuditStore.AssertExpectations(t)
+	s.RateLimitStore.AssertExpectations(t)
+	s.ChannelStore.AssertExpectations(t)
+	s.TeamStore.AssertExpectations(t)
+	s.DraftStore.AssertExpectations(t)
+	s.VoteStore.AssertExpectations(t)
+	s.StatsStore.AssertExpectations(t)
+	s.LeaderStore.AssertExpectations(t)
 }
@@ -0,0 +1,19 @@
+package store
+
+import "strings"
+
+// QuestionMatches returns true if a question contains every word of a given search text, ignoring case.
+// A text without any words matches no question.
+func QuestionMatches(question, text string) bool {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return false
+	}
+	question = strings.ToLower(question)
+	for _, word := range words {
+		if !strings.Contains(question, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuestionMatches(t *testing.T) {
+	for name, test := range map[string]struct {
+		Question string
+		Text     string
+		Expected bool
+	}{
+		"exact question":          {Question: "Lunch on Friday?", Text: "Lunch on Friday?", Expected: true},
+		"single word":             {Question: "Lunch on Friday?", Text: "friday", Expected: true},
+		"part of a word":          {Question: "Lunch on Friday?", Text: "fri", Expected: true},
+		"words in any order":      {Question: "Lunch on Friday?", Text: "FRIDAY lunch", Expected: true},
+		"one word missing":        {Question: "Lunch on Friday?", Text: "lunch monday", Expected: false},
+		"extra whitespace":        {Question: "Lunch on Friday?", Text: "  lunch   ", Expected: true},
+		"empty text":              {Question: "Lunch on Friday?", Text: "", Expected: false},
+		"only whitespace as text": {Question: "Lunch on Friday?", Text: " \t", Expected: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, QuestionMatches(test.Question, test.Text))
+		})
+	}
+}
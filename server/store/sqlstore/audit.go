@@ -0,0 +1,88 @@
+package sqlstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/matterpoll/matterpoll/server/audit"
+)
+
+// AuditStore allows to access the audit trail of polls in the database.
+type AuditStore struct {
+	store *Store
+}
+
+var (
+	auditColumns       = []string{"id", "poll_id", "created_at", "data"}
+	auditUpdateColumns = []string{"poll_id", "created_at", "data"}
+)
+
+// List returns the entries of all polls, oldest first.
+func (s *AuditStore) List() ([]*audit.Entry, error) {
+	return s.query(fmt.Sprintf("SELECT data FROM %s ORDER BY created_at", auditTable))
+}
+
+// ListByPoll returns the entries of a given poll, oldest first. Returns an empty list if there are none.
+func (s *AuditStore) ListByPoll(pollID string) ([]*audit.Entry, error) {
+	return s.query(fmt.Sprintf("SELECT data FROM %s WHERE poll_id = ? ORDER BY created_at", auditTable), pollID)
+}
+
+// Save stores an entry in the database. Overwrittes any existing entry with the same id.
+func (s *AuditStore) Save(e *audit.Entry) error {
+	query := s.store.upsertQuery(auditTable, auditColumns, auditUpdateColumns)
+	_, err := s.store.db.Exec(query, e.ID, e.PollID, e.CreatedAt, e.EncodeToByte())
+	return err
+}
+
+// AnonymizeUser removes a given user from all entries, so their actions show up as anonymous.
+// The user only shows up in the data column, so it gets narrowed down before the entries are checked.
+func (s *AuditStore) AnonymizeUser(userID string) error {
+	entries, err := s.query(fmt.Sprintf("SELECT data FROM %s WHERE data LIKE ?", auditTable), fmt.Sprintf(`%%"UserID":"%s"%%`, userID))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.UserID != userID {
+			continue
+		}
+		e.UserID = ""
+		if err := s.Save(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// query returns the entries stored in the data column of the rows a given query selects.
+func (s *AuditStore) query(query string, args ...interface{}) ([]*audit.Entry, error) {
+	rows, err := s.store.db.Query(s.store.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*audit.Entry{}
+	for rows.Next() {
+		var b []byte
+		if err = rows.Scan(&b); err != nil {
+			return nil, err
+		}
+		e, err := decodeEntry(b)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func decodeEntry(b []byte) (*audit.Entry, error) {
+	e := audit.DecodeEntryFromByte(b)
+	if e == nil {
+		return nil, errors.New("failed to decode audit entry")
+	}
+	return e, nil
+}
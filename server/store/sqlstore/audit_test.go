@@ -0,0 +1,153 @@
+package sqlstore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entryRows(entries ...*audit.Entry) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"data"})
+	for _, e := range entries {
+		rows.AddRow(e.EncodeToByte())
+	}
+	return rows
+}
+
+func TestAuditStoreList(t *testing.T) {
+	e1 := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567890}
+	e2 := &audit.Entry{ID: "entryID2", PollID: "pollID2", Action: audit.ActionPollEnded, CreatedAt: 1234567891}
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit ORDER BY created_at").WillReturnRows(entryRows(e1, e2))
+
+		entries, err := store.Audit().List()
+		require.Nil(t, err)
+		assert.Equal(t, []*audit.Entry{e1, e2}, entries)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Query() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit ORDER BY created_at").WillReturnError(errors.New("connection lost"))
+
+		entries, err := store.Audit().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, entries)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("decode fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit ORDER BY created_at").WillReturnRows(entryRows(e1).AddRow([]byte("{")))
+
+		entries, err := store.Audit().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, entries)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuditStoreListByPoll(t *testing.T) {
+	e1 := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567890}
+	e2 := &audit.Entry{ID: "entryID2", PollID: "pollID1", UserID: "userID2", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567891}
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit WHERE poll_id = $1 ORDER BY created_at").WithArgs("pollID1").WillReturnRows(entryRows(e1, e2))
+
+		entries, err := store.Audit().ListByPoll("pollID1")
+		require.Nil(t, err)
+		assert.Equal(t, []*audit.Entry{e1, e2}, entries)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("no entries", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit WHERE poll_id = ? ORDER BY created_at").WithArgs("pollID2").WillReturnRows(entryRows())
+
+		entries, err := store.Audit().ListByPoll("pollID2")
+		require.Nil(t, err)
+		assert.Equal(t, []*audit.Entry{}, entries)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Query() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit WHERE poll_id = ? ORDER BY created_at").WillReturnError(errors.New("connection lost"))
+
+		entries, err := store.Audit().ListByPoll("pollID1")
+		assert.NotNil(t, err)
+		assert.Nil(t, entries)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuditStoreSave(t *testing.T) {
+	e := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID1", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567890}
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectExec("INSERT INTO matterpoll_audit (id, poll_id, created_at, data) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET poll_id = EXCLUDED.poll_id, created_at = EXCLUDED.created_at, data = EXCLUDED.data").
+			WithArgs(e.ID, e.PollID, e.CreatedAt, e.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.Audit().Save(e)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Exec() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("INSERT INTO matterpoll_audit (id, poll_id, created_at, data) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE poll_id = VALUES(poll_id), created_at = VALUES(created_at), data = VALUES(data)").
+			WillReturnError(errors.New("connection lost"))
+
+		err := store.Audit().Save(e)
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuditStoreAnonymizeUser(t *testing.T) {
+	e1 := &audit.Entry{ID: "entryID1", PollID: "pollID1", UserID: "userID2", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567890}
+	anonymized := &audit.Entry{ID: "entryID1", PollID: "pollID1", Action: audit.ActionVoted, Details: "Answer 1", CreatedAt: 1234567890}
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit WHERE data LIKE $1").WithArgs(`%"UserID":"userID2"%`).WillReturnRows(entryRows(e1))
+		mock.ExpectExec("INSERT INTO matterpoll_audit (id, poll_id, created_at, data) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET poll_id = EXCLUDED.poll_id, created_at = EXCLUDED.created_at, data = EXCLUDED.data").
+			WithArgs(anonymized.ID, anonymized.PollID, anonymized.CreatedAt, anonymized.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.Audit().AnonymizeUser("userID2")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("entries of other users are left as they are", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit WHERE data LIKE ?").WithArgs(`%"UserID":"userID1"%`).WillReturnRows(entryRows(e1))
+
+		err := store.Audit().AnonymizeUser("userID1")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Query() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit WHERE data LIKE ?").WillReturnError(errors.New("connection lost"))
+
+		err := store.Audit().AnonymizeUser("userID2")
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Exec() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_audit WHERE data LIKE ?").WillReturnRows(entryRows(e1))
+		mock.ExpectExec("INSERT INTO matterpoll_audit (id, poll_id, created_at, data) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE poll_id = VALUES(poll_id), created_at = VALUES(created_at), data = VALUES(data)").
+			WillReturnError(errors.New("connection lost"))
+
+		err := store.Audit().AnonymizeUser("userID2")
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/job"
+)
+
+// JobStore allows to access scheduled jobs in the database.
+type JobStore struct {
+	store *Store
+}
+
+var (
+	jobColumns       = []string{"id", "run_at", "claimed_at", "data"}
+	jobUpdateColumns = []string{"run_at", "claimed_at", "data"}
+)
+
+// Get returns the job for a given id. Returns an error if the job doesn't exist or a database error occurred.
+func (s *JobStore) Get(id string) (*job.Job, error) {
+	var b []byte
+	query := s.store.rebind(fmt.Sprintf("SELECT data FROM %s WHERE id = ?", jobTable))
+	if err := s.store.db.QueryRow(query, id).Scan(&b); err != nil {
+		return nil, err
+	}
+	return decodeJob(b)
+}
+
+// List returns all scheduled jobs, ordered by the time they are due.
+func (s *JobStore) List() ([]*job.Job, error) {
+	rows, err := s.store.db.Query(fmt.Sprintf("SELECT data FROM %s ORDER BY run_at", jobTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*job.Job{}
+	for rows.Next() {
+		var b []byte
+		if err = rows.Scan(&b); err != nil {
+			return nil, err
+		}
+		j, err := decodeJob(b)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Claim marks a job as running to prevent other plugin instances in a cluster from running it as well.
+// Returns false if the job has been changed or claimed since it was read from the database.
+func (s *JobStore) Claim(j *job.Job) (bool, error) {
+	claimed := *j
+	claimed.ClaimedAt = model.GetMillis()
+
+	query := s.store.rebind(fmt.Sprintf("UPDATE %s SET claimed_at = ?, data = ? WHERE id = ? AND run_at = ? AND claimed_at = ?", jobTable))
+	result, err := s.store.db.Exec(query, claimed.ClaimedAt, claimed.EncodeToByte(), j.ID, j.RunAt, j.ClaimedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	j.ClaimedAt = claimed.ClaimedAt
+	return true, nil
+}
+
+// Save stores a job in the database. Overwrittes any existing job with the same id.
+func (s *JobStore) Save(j *job.Job) error {
+	query := s.store.upsertQuery(jobTable, jobColumns, jobUpdateColumns)
+	_, err := s.store.db.Exec(query, j.ID, j.RunAt, j.ClaimedAt, j.EncodeToByte())
+	return err
+}
+
+// Delete deletes a job from the database.
+func (s *JobStore) Delete(j *job.Job) error {
+	query := s.store.rebind(fmt.Sprintf("DELETE FROM %s WHERE id = ?", jobTable))
+	_, err := s.store.db.Exec(query, j.ID)
+	return err
+}
+
+func decodeJob(b []byte) (*job.Job, error) {
+	j := job.DecodeJobFromByte(b)
+	if j == nil {
+		return nil, errors.New("failed to decode job")
+	}
+	return j, nil
+}
@@ -0,0 +1,171 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobStoreGet(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT data FROM matterpoll_jobs WHERE id = $1").WithArgs(j.ID).WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(j.EncodeToByte()))
+
+		rjob, err := store.Job().Get(j.ID)
+		require.Nil(t, err)
+		assert.Equal(t, j, rjob)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("job doesn't exist", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_jobs WHERE id = ?").WithArgs(j.ID).WillReturnError(sql.ErrNoRows)
+
+		rjob, err := store.Job().Get(j.ID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, rjob)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("decode fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_jobs WHERE id = ?").WithArgs(j.ID).WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow([]byte("{")))
+
+		rjob, err := store.Job().Get(j.ID)
+		assert.NotNil(t, err)
+		assert.Nil(t, rjob)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestJobStoreList(t *testing.T) {
+	j1 := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+	j2 := job.NewJob(job.TypeEndPoll, "pollID2", 1234567891)
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_jobs ORDER BY run_at").WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(j1.EncodeToByte()).AddRow(j2.EncodeToByte()))
+
+		jobs, err := store.Job().List()
+		require.Nil(t, err)
+		assert.Equal(t, []*job.Job{j1, j2}, jobs)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Query() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_jobs ORDER BY run_at").WillReturnError(errors.New("connection lost"))
+
+		jobs, err := store.Job().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, jobs)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("decode fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_jobs ORDER BY run_at").WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(j1.EncodeToByte()).AddRow([]byte("{")))
+
+		jobs, err := store.Job().List()
+		assert.NotNil(t, err)
+		assert.Nil(t, jobs)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestJobStoreClaim(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567899 })
+	defer patch.Unpatch()
+
+	claimed := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+	claimed.ClaimedAt = 1234567899
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectExec("UPDATE matterpoll_jobs SET claimed_at = $1, data = $2 WHERE id = $3 AND run_at = $4 AND claimed_at = $5").
+			WithArgs(claimed.ClaimedAt, claimed.EncodeToByte(), claimed.ID, claimed.RunAt, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+		ok, err := store.Job().Claim(j)
+		require.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, claimed, j)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("job changed in the meantime", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("UPDATE matterpoll_jobs SET claimed_at = ?, data = ? WHERE id = ? AND run_at = ? AND claimed_at = ?").
+			WithArgs(claimed.ClaimedAt, claimed.EncodeToByte(), claimed.ID, claimed.RunAt, 0).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+		ok, err := store.Job().Claim(j)
+		require.Nil(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, int64(0), j.ClaimedAt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Exec() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("UPDATE matterpoll_jobs SET claimed_at = ?, data = ? WHERE id = ? AND run_at = ? AND claimed_at = ?").WillReturnError(errors.New("connection lost"))
+
+		j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+		ok, err := store.Job().Claim(j)
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, int64(0), j.ClaimedAt)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("RowsAffected() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("UPDATE matterpoll_jobs SET claimed_at = ?, data = ? WHERE id = ? AND run_at = ? AND claimed_at = ?").WillReturnResult(sqlmock.NewErrorResult(errors.New("not supported")))
+
+		j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+		ok, err := store.Job().Claim(j)
+		assert.NotNil(t, err)
+		assert.False(t, ok)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestJobStoreSave(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("INSERT INTO matterpoll_jobs (id, run_at, claimed_at, data) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE run_at = VALUES(run_at), claimed_at = VALUES(claimed_at), data = VALUES(data)").
+			WithArgs(j.ID, j.RunAt, j.ClaimedAt, j.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.Job().Save(j)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Exec() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("INSERT INTO matterpoll_jobs (id, run_at, claimed_at, data) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE run_at = VALUES(run_at), claimed_at = VALUES(claimed_at), data = VALUES(data)").
+			WillReturnError(errors.New("connection lost"))
+
+		err := store.Job().Save(j)
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestJobStoreDelete(t *testing.T) {
+	j := job.NewJob(job.TypeEndPoll, "pollID1", 1234567890)
+
+	store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+	mock.ExpectExec("DELETE FROM matterpoll_jobs WHERE id = $1").WithArgs(j.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := store.Job().Delete(j)
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,209 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+// PollStore allows to access polls in the database.
+type PollStore struct {
+	store *Store
+}
+
+var (
+	pollColumns       = []string{"id", "creator", "channel_id", "created_at", "ended_at", "data"}
+	pollUpdateColumns = []string{"channel_id", "ended_at", "data"}
+
+	// likeEscaper escapes the wildcards of LIKE patterns
+	likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+)
+
+// Get returns the poll for a given id. Returns an error if the poll doesn't exist or a database error occurred.
+func (s *PollStore) Get(id string) (*poll.Poll, error) {
+	var b []byte
+	query := s.store.rebind(fmt.Sprintf("SELECT data FROM %s WHERE id = ?", pollTable))
+	if err := s.store.db.QueryRow(query, id).Scan(&b); err != nil {
+		return nil, err
+	}
+	return decodePoll(b)
+}
+
+// List returns all polls, oldest first.
+func (s *PollStore) List() ([]*poll.Poll, error) {
+	return s.query(fmt.Sprintf("SELECT data FROM %s ORDER BY created_at", pollTable))
+}
+
+// ListByChannel returns all running polls in a given channel, oldest first. This includes polls that are scheduled to get posted there.
+func (s *PollStore) ListByChannel(channelID string) ([]*poll.Poll, error) {
+	return s.query(fmt.Sprintf("SELECT data FROM %s WHERE channel_id = ? AND ended_at = 0 ORDER BY created_at", pollTable), channelID)
+}
+
+// ListPage returns a page of all polls, newest first, and the total number of polls. Pages start at zero.
+func (s *PollStore) ListPage(page, perPage int) ([]*poll.Poll, int, error) {
+	var total int
+	if err := s.store.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", pollTable)).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	polls, err := s.query(fmt.Sprintf("SELECT data FROM %s ORDER BY created_at DESC LIMIT ? OFFSET ?", pollTable), perPage, page*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	return polls, total, nil
+}
+
+// Save stores a poll in the database. Overwrittes any existing poll with the same id.
+func (s *PollStore) Save(p *poll.Poll) error {
+	query := s.store.upsertQuery(pollTable, pollColumns, pollUpdateColumns)
+	_, err := s.store.db.Exec(query, p.ID, p.Creator, p.ChannelID, p.CreatedAt, p.EndedAt, p.EncodeToByte())
+	return err
+}
+
+// Update atomically applies update to the poll with the given id and stores the result.
+// The row is locked until the poll is stored, so concurrent updates are applied one after another.
+// Errors returned by update are passed through unchanged and nothing is stored.
+func (s *PollStore) Update(id string, update func(*poll.Poll) error) (*poll.Poll, error) {
+	tx, err := s.store.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var b []byte
+	query := s.store.rebind(fmt.Sprintf("SELECT data FROM %s WHERE id = ? FOR UPDATE", pollTable))
+	if err = tx.QueryRow(query, id).Scan(&b); err != nil {
+		return nil, err
+	}
+	p, err := decodePoll(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = update(p); err != nil {
+		return nil, err
+	}
+
+	query = s.store.rebind(fmt.Sprintf("UPDATE %s SET channel_id = ?, ended_at = ?, data = ? WHERE id = ?", pollTable))
+	if _, err = tx.Exec(query, p.ChannelID, p.EndedAt, p.EncodeToByte(), p.ID); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Delete deletes a poll from the database.
+func (s *PollStore) Delete(p *poll.Poll) error {
+	query := s.store.rebind(fmt.Sprintf("DELETE FROM %s WHERE id = ?", pollTable))
+	_, err := s.store.db.Exec(query, p.ID)
+	return err
+}
+
+// Archive does nothing. The database indexes polls by their channel and end time,
+// so ended polls don't slow down the queries for running polls and stay in the polls table.
+func (s *PollStore) Archive(p *poll.Poll) error {
+	return nil
+}
+
+// Unarchive does nothing, because polls never get archived in the database.
+func (s *PollStore) Unarchive(id string) error {
+	return nil
+}
+
+// ListArchived returns no polls, because polls never get archived in the database.
+func (s *PollStore) ListArchived() ([]*poll.Poll, error) {
+	return []*poll.Poll{}, nil
+}
+
+// Search returns all polls whose question contains every word of a given text, newest first.
+// The database only narrows down the polls by their encoded data, the questions are matched afterwards.
+func (s *PollStore) Search(text string) ([]*poll.Poll, error) {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return []*poll.Poll{}, nil
+	}
+
+	query := fmt.Sprintf("SELECT data FROM %s", pollTable)
+	conditions := []string{}
+	args := []interface{}{}
+	for _, word := range words {
+		// Words that look different in the encoded data can't be used to narrow down the polls
+		if encoded, err := json.Marshal(word); err != nil || string(encoded) != `"`+word+`"` {
+			continue
+		}
+		conditions = append(conditions, "LOWER(data) LIKE ?")
+		args = append(args, "%"+likeEscaper.Replace(word)+"%")
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	candidates, err := s.query(query+" ORDER BY created_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	polls := []*poll.Poll{}
+	for _, p := range candidates {
+		if store.QuestionMatches(p.Question, text) {
+			polls = append(polls, p)
+		}
+	}
+	return polls, nil
+}
+
+// ListByTag returns all polls that are tagged with a given tag, newest first.
+// The database only narrows down the polls by their encoded data, the tags are matched afterwards.
+func (s *PollStore) ListByTag(tag string) ([]*poll.Poll, error) {
+	tag = poll.NormalizeTag(tag)
+	candidates, err := s.query(fmt.Sprintf("SELECT data FROM %s WHERE LOWER(data) LIKE ? ORDER BY created_at DESC", pollTable), "%"+likeEscaper.Replace(`"`+tag+`"`)+"%")
+	if err != nil {
+		return nil, err
+	}
+
+	polls := []*poll.Poll{}
+	for _, p := range candidates {
+		if p.HasTag(tag) {
+			polls = append(polls, p)
+		}
+	}
+	return polls, nil
+}
+
+// query returns the polls stored in the data column of the rows a given query selects.
+func (s *PollStore) query(query string, args ...interface{}) ([]*poll.Poll, error) {
+	rows, err := s.store.db.Query(s.store.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	polls := []*poll.Poll{}
+	for rows.Next() {
+		var b []byte
+		if err = rows.Scan(&b); err != nil {
+			return nil, err
+		}
+		p, err := decodePoll(b)
+		if err != nil {
+			return nil, err
+		}
+		polls = append(polls, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+func decodePoll(b []byte) (*poll.Poll, error) {
+	p := poll.DecodePollFromByte(b)
+	if p == nil {
+		return nil, errors.New("failed to decode poll")
+	}
+	return p, nil
+}
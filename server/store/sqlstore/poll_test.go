@@ -0,0 +1,370 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pollRows(polls ...*poll.Poll) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"data"})
+	for _, p := range polls {
+		rows.AddRow(p.EncodeToByte())
+	}
+	return rows
+}
+
+func TestPollStoreGet(t *testing.T) {
+	p := testutils.GetPoll()
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = ?").WithArgs(p.ID).WillReturnRows(pollRows(p))
+
+		rp, err := store.Poll().Get(p.ID)
+		require.Nil(t, err)
+		assert.Equal(t, p, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = $1").WithArgs(p.ID).WillReturnRows(pollRows(p))
+
+		rp, err := store.Poll().Get(p.ID)
+		require.Nil(t, err)
+		assert.Equal(t, p, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("poll doesn't exist", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = ?").WithArgs(p.ID).WillReturnError(sql.ErrNoRows)
+
+		rp, err := store.Poll().Get(p.ID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("decode fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = ?").WithArgs(p.ID).WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow([]byte("{")))
+
+		rp, err := store.Poll().Get(p.ID)
+		assert.NotNil(t, err)
+		assert.Nil(t, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPollStoreSave(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+	p.EndedAt = 1234567891
+
+	t.Run("mysql", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("INSERT INTO matterpoll_polls (id, creator, channel_id, created_at, ended_at, data) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE channel_id = VALUES(channel_id), ended_at = VALUES(ended_at), data = VALUES(data)").
+			WithArgs(p.ID, p.Creator, p.ChannelID, p.CreatedAt, p.EndedAt, p.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.Poll().Save(p)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectExec("INSERT INTO matterpoll_polls (id, creator, channel_id, created_at, ended_at, data) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (id) DO UPDATE SET channel_id = EXCLUDED.channel_id, ended_at = EXCLUDED.ended_at, data = EXCLUDED.data").
+			WithArgs(p.ID, p.Creator, p.ChannelID, p.CreatedAt, p.EndedAt, p.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.Poll().Save(p)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Exec() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("INSERT INTO matterpoll_polls (id, creator, channel_id, created_at, ended_at, data) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE channel_id = VALUES(channel_id), ended_at = VALUES(ended_at), data = VALUES(data)").
+			WillReturnError(errors.New("connection lost"))
+
+		err := store.Poll().Save(p)
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPollStoreUpdate(t *testing.T) {
+	p := testutils.GetPoll()
+	ended := testutils.GetPoll()
+	ended.EndedAt = 1234567891
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = $1 FOR UPDATE").WithArgs(p.ID).WillReturnRows(pollRows(p))
+		mock.ExpectExec("UPDATE matterpoll_polls SET channel_id = $1, ended_at = $2, data = $3 WHERE id = $4").
+			WithArgs(ended.ChannelID, ended.EndedAt, ended.EncodeToByte(), ended.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		rp, err := store.Poll().Update(p.ID, func(p *poll.Poll) error {
+			p.EndedAt = 1234567891
+			return nil
+		})
+		require.Nil(t, err)
+		assert.Equal(t, ended, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("update fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = ? FOR UPDATE").WithArgs(p.ID).WillReturnRows(pollRows(p))
+		mock.ExpectRollback()
+
+		updateErr := errors.New("poll already ended")
+		rp, err := store.Poll().Update(p.ID, func(p *poll.Poll) error {
+			return updateErr
+		})
+		assert.Equal(t, updateErr, err)
+		assert.Nil(t, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("poll doesn't exist", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = ? FOR UPDATE").WithArgs(p.ID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		rp, err := store.Poll().Update(p.ID, func(p *poll.Poll) error {
+			assert.Fail(t, "update must not be called")
+			return nil
+		})
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Exec() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = ? FOR UPDATE").WithArgs(p.ID).WillReturnRows(pollRows(p))
+		mock.ExpectExec("UPDATE matterpoll_polls SET channel_id = ?, ended_at = ?, data = ? WHERE id = ?").WillReturnError(errors.New("connection lost"))
+		mock.ExpectRollback()
+
+		rp, err := store.Poll().Update(p.ID, func(p *poll.Poll) error { return nil })
+		assert.NotNil(t, err)
+		assert.Nil(t, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Commit() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE id = ? FOR UPDATE").WithArgs(p.ID).WillReturnRows(pollRows(p))
+		mock.ExpectExec("UPDATE matterpoll_polls SET channel_id = ?, ended_at = ?, data = ? WHERE id = ?").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit().WillReturnError(errors.New("deadlock"))
+
+		rp, err := store.Poll().Update(p.ID, func(p *poll.Poll) error { return nil })
+		assert.NotNil(t, err)
+		assert.Nil(t, rp)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPollStoreDelete(t *testing.T) {
+	p := testutils.GetPoll()
+
+	store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+	mock.ExpectExec("DELETE FROM matterpoll_polls WHERE id = $1").WithArgs(p.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := store.Poll().Delete(p)
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestPollStoreListByChannel(t *testing.T) {
+	p1 := testutils.GetPoll()
+	p2 := testutils.GetPollWithVotes()
+	p2.ID = "pollID2"
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE channel_id = $1 AND ended_at = 0 ORDER BY created_at").WithArgs(p1.ChannelID).WillReturnRows(pollRows(p1, p2))
+
+		polls, err := store.Poll().ListByChannel(p1.ChannelID)
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{p1, p2}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("no polls", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE channel_id = ? AND ended_at = 0 ORDER BY created_at").WithArgs("channelID2").WillReturnRows(pollRows())
+
+		polls, err := store.Poll().ListByChannel("channelID2")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Query() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE channel_id = ? AND ended_at = 0 ORDER BY created_at").WillReturnError(errors.New("connection lost"))
+
+		polls, err := store.Poll().ListByChannel(p1.ChannelID)
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("decode fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE channel_id = ? AND ended_at = 0 ORDER BY created_at").
+			WillReturnRows(pollRows(p1).AddRow([]byte("{")))
+
+		polls, err := store.Poll().ListByChannel(p1.ChannelID)
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPollStoreListPage(t *testing.T) {
+	p1 := testutils.GetPoll()
+	p2 := testutils.GetPollWithVotes()
+	p2.ID = "pollID2"
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT COUNT(*) FROM matterpoll_polls").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls ORDER BY created_at DESC LIMIT $1 OFFSET $2").WithArgs(5, 10).WillReturnRows(pollRows(p2, p1))
+
+		polls, total, err := store.Poll().ListPage(2, 5)
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{p2, p1}, polls)
+		assert.Equal(t, 12, total)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("counting fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT COUNT(*) FROM matterpoll_polls").WillReturnError(errors.New("connection lost"))
+
+		polls, total, err := store.Poll().ListPage(0, 5)
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Equal(t, 0, total)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("listing fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT COUNT(*) FROM matterpoll_polls").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls ORDER BY created_at DESC LIMIT ? OFFSET ?").WithArgs(5, 0).WillReturnError(errors.New("connection lost"))
+
+		polls, total, err := store.Poll().ListPage(0, 5)
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Equal(t, 0, total)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPollStoreSearch(t *testing.T) {
+	p1 := testutils.GetPoll()
+	p1.Question = "What's for lunch?"
+	p2 := testutils.GetPoll()
+	p2.ID = "pollID2"
+	// The words only appear in the options, so the database narrows the poll down but it doesn't match
+	p2.Question = "Where should we go?"
+	p2.AnswerOptions[0].Answer = "lunch at what place"
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE LOWER(data) LIKE $1 AND LOWER(data) LIKE $2 ORDER BY created_at DESC").
+			WithArgs("%lunch%", "%what%").
+			WillReturnRows(pollRows(p2, p1))
+
+		polls, err := store.Poll().Search("Lunch WHAT")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{p1}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("wildcards are escaped", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE LOWER(data) LIKE ? ORDER BY created_at DESC").
+			WithArgs(`%100\%\_\_%`).
+			WillReturnRows(pollRows())
+
+		polls, err := store.Poll().Search("100%__")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("words that are encoded differently don't narrow down", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE LOWER(data) LIKE ? ORDER BY created_at DESC").
+			WithArgs("%lunch%").
+			WillReturnRows(pollRows(p1))
+
+		polls, err := store.Poll().Search(`lunch "what's"`)
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("empty text", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+
+		polls, err := store.Poll().Search("  ")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Query() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE LOWER(data) LIKE ? ORDER BY created_at DESC").WillReturnError(errors.New("connection lost"))
+
+		polls, err := store.Poll().Search("lunch")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPollStoreListByTag(t *testing.T) {
+	p1 := testutils.GetPoll()
+	p1.Settings.Tags = []string{"retro", "team-alpha"}
+	p2 := testutils.GetPoll()
+	p2.ID = "pollID2"
+	// The tag only appears as an option, so the database narrows the poll down but it doesn't match
+	p2.AnswerOptions[0].Answer = "retro"
+
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE LOWER(data) LIKE $1 ORDER BY created_at DESC").
+			WithArgs(`%"retro"%`).
+			WillReturnRows(pollRows(p2, p1))
+
+		polls, err := store.Poll().ListByTag("#Retro")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{p1}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("wildcards are escaped", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE LOWER(data) LIKE ? ORDER BY created_at DESC").
+			WithArgs(`%"team\_alpha"%`).
+			WillReturnRows(pollRows(p1))
+
+		polls, err := store.Poll().ListByTag("team_alpha")
+		require.Nil(t, err)
+		assert.Equal(t, []*poll.Poll{}, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Query() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT data FROM matterpoll_polls WHERE LOWER(data) LIKE ? ORDER BY created_at DESC").WillReturnError(errors.New("connection lost"))
+
+		polls, err := store.Poll().ListByTag("retro")
+		assert.NotNil(t, err)
+		assert.Nil(t, polls)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,267 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	// Register the database drivers supported by Mattermost
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/kvstore"
+	"github.com/pkg/errors"
+)
+
+const (
+	driverPostgres = "postgres"
+	driverMySQL    = "mysql"
+
+	pollTable   = "matterpoll_polls"
+	jobTable    = "matterpoll_jobs"
+	systemTable = "matterpoll_system"
+	auditTable  = "matterpoll_audit"
+)
+
+// Store is an interface to interact with the tables of Matterpoll in the Mattermost database.
+type Store struct {
+	api         plugin.API
+	db          *sql.DB
+	driverName  string
+	pollStore   PollStore
+	jobStore    JobStore
+	systemStore SystemStore
+	auditStore  AuditStore
+	// rateLimitStore keeps the short-lived rate limit counters in the KV Store, so they don't need a table
+	rateLimitStore store.RateLimitStore
+	// channelStore keeps the few channel settings in the KV Store, so they don't need a table
+	channelStore store.ChannelStore
+	// teamStore keeps the few team settings in the KV Store, so they don't need a table
+	teamStore store.TeamStore
+	// draftStore keeps the short-lived drafts of polls in the KV Store, so they don't need a table
+	draftStore store.DraftStore
+	// voteStore keeps the short index of recent votes per user in the KV Store, so it doesn't need a table
+	voteStore store.VoteStore
+	// statsStore keeps the statistics in the KV Store, so the single record doesn't need a table
+	statsStore store.StatsStore
+	// leaderStore keeps the lease of the scheduler in the KV Store, so the single record doesn't need a table
+	leaderStore store.LeaderStore
+}
+
+// NewStore connects to the Mattermost database, creates the tables of Matterpoll if needed
+// and upgrades them to the given schema version. On the first start, all polls, jobs and audit entries are copied from the KV Store.
+func NewStore(api plugin.API, settings model.SqlSettings, pluginVersion string) (store.Store, error) {
+	if settings.DriverName == nil || settings.DataSource == nil {
+		return nil, errors.New("database settings are incomplete")
+	}
+	driverName := *settings.DriverName
+	if driverName != driverPostgres && driverName != driverMySQL {
+		return nil, errors.Errorf("unsupported database driver %s", driverName)
+	}
+
+	db, err := sql.Open(driverName, *settings.DataSource)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+	if settings.MaxIdleConns != nil {
+		db.SetMaxIdleConns(*settings.MaxIdleConns)
+	}
+	if settings.MaxOpenConns != nil {
+		db.SetMaxOpenConns(*settings.MaxOpenConns)
+	}
+	if settings.ConnMaxLifetimeMilliseconds != nil {
+		db.SetConnMaxLifetime(time.Duration(*settings.ConnMaxLifetimeMilliseconds) * time.Millisecond)
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to connect to database")
+	}
+
+	s := newStore(api, db, driverName)
+	if err = s.createTables(); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to create tables")
+	}
+	if err = s.UpdateDatabase(pluginVersion); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to update database")
+	}
+
+	return s, nil
+}
+
+func newStore(api plugin.API, db *sql.DB, driverName string) *Store {
+	s := &Store{
+		api:        api,
+		db:         db,
+		driverName: driverName,
+	}
+	s.pollStore = PollStore{store: s}
+	s.jobStore = JobStore{store: s}
+	s.systemStore = SystemStore{store: s}
+	s.auditStore = AuditStore{store: s}
+	s.rateLimitStore = kvstore.NewRateLimitStore(api)
+	s.channelStore = kvstore.NewChannelStore(api)
+	s.teamStore = kvstore.NewTeamStore(api)
+	s.draftStore = kvstore.NewDraftStore(api)
+	s.voteStore = kvstore.NewVoteStore(api)
+	s.statsStore = kvstore.NewStatsStore(api)
+	s.leaderStore = kvstore.NewLeaderStore(api)
+	return s
+}
+
+// Poll returns the Poll Store
+func (s *Store) Poll() store.PollStore { return &s.pollStore }
+
+// Job returns the Job Store
+func (s *Store) Job() store.JobStore { return &s.jobStore }
+
+// System returns the System Store
+func (s *Store) System() store.SystemStore { return &s.systemStore }
+
+// Audit returns the Audit Store
+func (s *Store) Audit() store.AuditStore { return &s.auditStore }
+
+// RateLimit returns the Rate Limit Store
+func (s *Store) RateLimit() store.RateLimitStore { return s.rateLimitStore }
+
+// Channel returns the Channel Store
+func (s *Store) Channel() store.ChannelStore { return s.channelStore }
+
+// Team returns the Team Store
+func (s *Store) Team() store.TeamStore { return s.teamStore }
+
+// Draft returns the Draft Store
+func (s *Store) Draft() store.DraftStore { return s.draftStore }
+
+// Vote returns the Vote Store
+func (s *Store) Vote() store.VoteStore { return s.voteStore }
+
+// Stats returns the Stats Store
+func (s *Store) Stats() store.StatsStore { return s.statsStore }
+
+// Leader returns the Leader Store
+func (s *Store) Leader() store.LeaderStore { return s.leaderStore }
+
+// Close closes the database connection.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// createTables creates the tables of Matterpoll if they don't exist yet.
+func (s *Store) createTables() error {
+	textType := "TEXT"
+	if s.driverName == driverMySQL {
+		// TEXT is limited to 64 KB in MySQL, which polls with many voters can exceed
+		textType = "MEDIUMTEXT"
+	}
+
+	queries := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id VARCHAR(26) PRIMARY KEY,
+			creator VARCHAR(26) NOT NULL,
+			channel_id VARCHAR(26) NOT NULL,
+			created_at BIGINT NOT NULL,
+			ended_at BIGINT NOT NULL,
+			data %s NOT NULL
+		)`, pollTable, textType),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id VARCHAR(64) PRIMARY KEY,
+			run_at BIGINT NOT NULL,
+			claimed_at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`, jobTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			name VARCHAR(64) PRIMARY KEY,
+			value TEXT NOT NULL
+		)`, systemTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id VARCHAR(26) PRIMARY KEY,
+			poll_id VARCHAR(26) NOT NULL,
+			created_at BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`, auditTable),
+	}
+	for _, query := range queries {
+		if _, err := s.db.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	if err := s.createIndex(pollTable, "channel_id", "created_at"); err != nil {
+		return err
+	}
+	if err := s.createIndex(pollTable, "created_at"); err != nil {
+		return err
+	}
+	return s.createIndex(auditTable, "poll_id", "created_at")
+}
+
+// createIndex creates an index on the given columns of a table if it doesn't exist yet.
+func (s *Store) createIndex(table string, columns ...string) error {
+	name := fmt.Sprintf("idx_%s_%s", table, strings.Join(columns, "_"))
+	if s.driverName == driverPostgres {
+		_, err := s.db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", name, table, strings.Join(columns, ", ")))
+		return err
+	}
+
+	// MySQL doesn't support IF NOT EXISTS for indexes
+	var count int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+		table, name,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = s.db.Exec(fmt.Sprintf("CREATE INDEX %s ON %s (%s)", name, table, strings.Join(columns, ", ")))
+	return err
+}
+
+// rebind replaces the ? placeholders in a query with the placeholders of the database driver.
+func (s *Store) rebind(query string) string {
+	if s.driverName != driverPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		fmt.Fprintf(&b, "$%d", n)
+	}
+	return b.String()
+}
+
+// upsertQuery returns a query that inserts a row into a table or updates the given columns if a row with the same primary key exists.
+// The first column must be the primary key.
+func (s *Store) upsertQuery(table string, columns []string, updateColumns []string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders)
+
+	updates := make([]string, len(updateColumns))
+	for i, column := range updateColumns {
+		if s.driverName == driverPostgres {
+			updates[i] = fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+		} else {
+			updates[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+		}
+	}
+	if s.driverName == driverPostgres {
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", columns[0], strings.Join(updates, ", "))
+	} else {
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+	return s.rebind(query)
+}
@@ -0,0 +1,59 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestStore returns a store whose database is mocked. The queries are expected exactly as written.
+func setupTestStore(t *testing.T, api *plugintest.API, driverName string) (*Store, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.Nil(t, err)
+	return newStore(api, db, driverName), mock
+}
+
+func TestNewStoreInvalidSettings(t *testing.T) {
+	for name, settings := range map[string]model.SqlSettings{
+		"no settings":        {},
+		"no data source":     {DriverName: model.NewString(driverPostgres)},
+		"unsupported driver": {DriverName: model.NewString("sqlite3"), DataSource: model.NewString(":memory:")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewStore(nil, settings, "1.1.0")
+			assert.NotNil(t, err)
+			assert.Nil(t, s)
+		})
+	}
+}
+
+func TestStoreRebind(t *testing.T) {
+	query := "SELECT data FROM matterpoll_polls WHERE channel_id = ? AND ended_at = ?"
+
+	postgres := newStore(nil, nil, driverPostgres)
+	assert.Equal(t, "SELECT data FROM matterpoll_polls WHERE channel_id = $1 AND ended_at = $2", postgres.rebind(query))
+
+	mysql := newStore(nil, nil, driverMySQL)
+	assert.Equal(t, query, mysql.rebind(query))
+}
+
+func TestStoreUpsertQuery(t *testing.T) {
+	columns := []string{"id", "run_at", "data"}
+	updateColumns := []string{"run_at", "data"}
+
+	postgres := newStore(nil, nil, driverPostgres)
+	assert.Equal(t,
+		"INSERT INTO matterpoll_jobs (id, run_at, data) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET run_at = EXCLUDED.run_at, data = EXCLUDED.data",
+		postgres.upsertQuery(jobTable, columns, updateColumns),
+	)
+
+	mysql := newStore(nil, nil, driverMySQL)
+	assert.Equal(t,
+		"INSERT INTO matterpoll_jobs (id, run_at, data) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE run_at = VALUES(run_at), data = VALUES(data)",
+		mysql.upsertQuery(jobTable, columns, updateColumns),
+	)
+}
@@ -0,0 +1,34 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SystemStore allows to access system informations in the database.
+type SystemStore struct {
+	store *Store
+}
+
+const versionKey = "version"
+
+// GetVersion returns the db schema version. Returns an empty string if no version is set.
+func (s *SystemStore) GetVersion() (string, error) {
+	var version string
+	query := s.store.rebind(fmt.Sprintf("SELECT value FROM %s WHERE name = ?", systemTable))
+	err := s.store.db.QueryRow(query, versionKey).Scan(&version)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// SaveVersion sets the db schema version.
+func (s *SystemStore) SaveVersion(version string) error {
+	query := s.store.upsertQuery(systemTable, []string{"name", "value"}, []string{"value"})
+	_, err := s.store.db.Exec(query, versionKey, version)
+	return err
+}
@@ -0,0 +1,64 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemStoreGetVersion(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = $1").WithArgs("version").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("1.1.0"))
+
+		version, err := store.System().GetVersion()
+		assert.Nil(t, err)
+		assert.Equal(t, "1.1.0", version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("no version set", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = ?").WithArgs("version").WillReturnError(sql.ErrNoRows)
+
+		version, err := store.System().GetVersion()
+		assert.Nil(t, err)
+		assert.Equal(t, "", version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("QueryRow() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = ?").WithArgs("version").WillReturnError(errors.New("connection lost"))
+
+		version, err := store.System().GetVersion()
+		assert.NotNil(t, err)
+		assert.Equal(t, "", version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSystemStoreSaveVersion(t *testing.T) {
+	t.Run("all fine", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverMySQL)
+		mock.ExpectExec("INSERT INTO matterpoll_system (name, value) VALUES (?, ?) ON DUPLICATE KEY UPDATE value = VALUES(value)").
+			WithArgs("version", "1.1.0").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.System().SaveVersion("1.1.0")
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Exec() fails", func(t *testing.T) {
+		store, mock := setupTestStore(t, &plugintest.API{}, driverPostgres)
+		mock.ExpectExec("INSERT INTO matterpoll_system (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value").
+			WithArgs("version", "1.1.0").
+			WillReturnError(errors.New("connection lost"))
+
+		err := store.System().SaveVersion("1.1.0")
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
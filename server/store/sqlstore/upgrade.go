@@ -0,0 +1,49 @@
+package sqlstore
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/kvstore"
+	"github.com/pkg/errors"
+)
+
+// UpdateDatabase upgrades the database schema from a given version to the newest version.
+func (s *Store) UpdateDatabase(pluginVersion string) error {
+	v, err := s.System().GetVersion()
+	if err != nil {
+		return err
+	}
+	// If no version is set, the tables have just been created. Move the existing data over from the KV Store.
+	if v == "" {
+		if err := s.migrateFromKVStore(pluginVersion); err != nil {
+			return errors.Wrap(err, "failed to migrate from KV Store")
+		}
+
+		newestSchema := semver.MustParse(pluginVersion)
+		// Don't store patch versions
+		newestSchema.Patch = 0
+
+		s.api.LogWarn(fmt.Sprintf("Setting database schema version to %v.", newestSchema.String()))
+		return s.System().SaveVersion(newestSchema.String())
+	}
+
+	return nil
+}
+
+// migrateFromKVStore copies all polls, jobs and audit entries from the KV Store into the database.
+// The KV Store is left untouched, so switching back to it is possible.
+func (s *Store) migrateFromKVStore(pluginVersion string) error {
+	kvStore, err := kvstore.NewStore(s.api, pluginVersion)
+	if err != nil {
+		return err
+	}
+
+	s.api.LogWarn("Copying polls from the KV Store into the database.")
+	if err := store.Copy(kvStore, s); err != nil {
+		return err
+	}
+	s.api.LogWarn("Copying polls complete.")
+	return nil
+}
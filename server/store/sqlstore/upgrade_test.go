@@ -0,0 +1,104 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateDatabase(t *testing.T) {
+	p := testutils.GetPollWithVotes()
+	j := job.NewJob(job.TypeEndPoll, p.ID, 1234567890)
+	e := &audit.Entry{ID: "entryID1", PollID: p.ID, UserID: "userID1", Action: audit.ActionPollCreated, CreatedAt: 1234567890}
+	entries, err := json.Marshal([]*audit.Entry{e})
+	require.Nil(t, err)
+
+	// setupKVStore mocks a KV Store, that is up to date, with a poll, a job and an audit entry in it
+	setupKVStore := func(api *plugintest.API) {
+		api.On("KVGet", "version").Return([]byte("1.2.0"), nil)
+		api.On("KVList", 0, 100).Return([]string{"version", "poll_" + p.ID, "job_" + j.ID, "audit_" + p.ID}, nil)
+		api.On("KVGet", "poll_"+p.ID).Return(p.EncodeToByte(), nil)
+		api.On("KVGet", "job_"+j.ID).Return(j.EncodeToByte(), nil)
+		api.On("KVGet", "audit_"+p.ID).Return(entries, nil)
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		store, sqlMock := setupTestStore(t, api, driverMySQL)
+		sqlMock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = ?").WithArgs("version").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("1.1.0"))
+
+		err := store.UpdateDatabase("1.1.0")
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+	t.Run("migrate from the KV Store", func(t *testing.T) {
+		api := &plugintest.API{}
+		setupKVStore(api)
+		api.On("LogWarn", mock.AnythingOfType("string")).Return(nil)
+		defer api.AssertExpectations(t)
+		store, sqlMock := setupTestStore(t, api, driverPostgres)
+		sqlMock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = $1").WithArgs("version").WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectExec("INSERT INTO matterpoll_polls (id, creator, channel_id, created_at, ended_at, data) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (id) DO UPDATE SET channel_id = EXCLUDED.channel_id, ended_at = EXCLUDED.ended_at, data = EXCLUDED.data").
+			WithArgs(p.ID, p.Creator, p.ChannelID, p.CreatedAt, p.EndedAt, p.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec("INSERT INTO matterpoll_jobs (id, run_at, claimed_at, data) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET run_at = EXCLUDED.run_at, claimed_at = EXCLUDED.claimed_at, data = EXCLUDED.data").
+			WithArgs(j.ID, j.RunAt, j.ClaimedAt, j.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec("INSERT INTO matterpoll_audit (id, poll_id, created_at, data) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET poll_id = EXCLUDED.poll_id, created_at = EXCLUDED.created_at, data = EXCLUDED.data").
+			WithArgs(e.ID, e.PollID, e.CreatedAt, e.EncodeToByte()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec("INSERT INTO matterpoll_system (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value").
+			WithArgs("version", "1.2.0").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.UpdateDatabase("1.2.3")
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+	t.Run("GetVersion() fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		store, sqlMock := setupTestStore(t, api, driverMySQL)
+		sqlMock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = ?").WithArgs("version").WillReturnError(errors.New("connection lost"))
+
+		err := store.UpdateDatabase("1.1.0")
+		assert.NotNil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+	t.Run("reading the KV Store fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", "version").Return(nil, &model.AppError{})
+		defer api.AssertExpectations(t)
+		store, sqlMock := setupTestStore(t, api, driverMySQL)
+		sqlMock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = ?").WithArgs("version").WillReturnError(sql.ErrNoRows)
+
+		err := store.UpdateDatabase("1.1.0")
+		assert.NotNil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+	t.Run("copying a poll fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		setupKVStore(api)
+		api.On("LogWarn", mock.AnythingOfType("string")).Return(nil)
+		store, sqlMock := setupTestStore(t, api, driverMySQL)
+		sqlMock.ExpectQuery("SELECT value FROM matterpoll_system WHERE name = ?").WithArgs("version").WillReturnError(sql.ErrNoRows)
+		sqlMock.ExpectExec("INSERT INTO matterpoll_polls (id, creator, channel_id, created_at, ended_at, data) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE channel_id = VALUES(channel_id), ended_at = VALUES(ended_at), data = VALUES(data)").
+			WillReturnError(errors.New("connection lost"))
+
+		err := store.UpdateDatabase("1.1.0")
+		assert.NotNil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
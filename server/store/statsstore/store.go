@@ -0,0 +1,120 @@
+package statsstore
+
+import (
+	"io"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+// Logger logs failures to update the statistics
+type Logger interface {
+	LogWarn(msg string, keyValuePairs ...interface{})
+}
+
+// Store wraps another store and keeps the statistics of all polls up to date whenever a poll gets saved, updated or deleted.
+// All other operations are passed through.
+type Store struct {
+	store.Store
+	pollStore PollStore
+}
+
+// NewStore returns a store that maintains the statistics of a given store.
+// The statistics are built from all stored polls if they don't exist yet, which covers polls created before the statistics were introduced.
+func NewStore(s store.Store, logger Logger) (store.Store, error) {
+	existing, err := s.Stats().Get()
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		if err = buildStats(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Store{
+		Store:     s,
+		pollStore: PollStore{PollStore: s.Poll(), stats: s.Stats(), logger: logger},
+	}, nil
+}
+
+// buildStats stores the statistics of all polls, including archived ones
+func buildStats(s store.Store) error {
+	polls, err := s.Poll().List()
+	if err != nil {
+		return err
+	}
+	archived, err := s.Poll().ListArchived()
+	if err != nil {
+		return err
+	}
+	built := stats.FromPolls(append(polls, archived...))
+	return s.Stats().Update(func(st *stats.Stats) { *st = *built })
+}
+
+// Poll returns the Poll Store
+func (s *Store) Poll() store.PollStore { return &s.pollStore }
+
+// Close closes the wrapped store, if it needs to be closed
+func (s *Store) Close() error {
+	if closer, ok := s.Store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// PollStore records every change of a poll in the statistics. Failures to update the statistics are only logged,
+// because they must not break voting or the management of polls.
+type PollStore struct {
+	store.PollStore
+	stats  store.StatsStore
+	logger Logger
+}
+
+// Save stores a poll. Polls that didn't exist before count as new polls.
+func (s *PollStore) Save(p *poll.Poll) error {
+	// A poll that can't be loaded doesn't exist yet
+	before, _ := s.PollStore.Get(p.ID)
+	if err := s.PollStore.Save(p); err != nil {
+		return err
+	}
+	s.record(before, p)
+	return nil
+}
+
+// Update atomically applies update to the poll with the given id and stores the result.
+func (s *PollStore) Update(id string, update func(*poll.Poll) error) (*poll.Poll, error) {
+	// The wrapped store may apply update several times, hence only the last attempt counts
+	var before *poll.Poll
+	after, err := s.PollStore.Update(id, func(latest *poll.Poll) error {
+		before = latest.Copy()
+		return update(latest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.record(before, after)
+	return after, nil
+}
+
+// Delete deletes a poll and removes it from the statistics.
+func (s *PollStore) Delete(p *poll.Poll) error {
+	if err := s.PollStore.Delete(p); err != nil {
+		return err
+	}
+	s.record(p, nil)
+	return nil
+}
+
+// record updates the statistics with a change of a poll
+func (s *PollStore) record(before, after *poll.Poll) {
+	if !stats.Affects(before, after) {
+		return
+	}
+	now := model.GetMillis()
+	if err := s.stats.Update(func(st *stats.Stats) { st.Record(before, after, now) }); err != nil {
+		s.logger.LogWarn("failed to update poll statistics", "error", err.Error())
+	}
+}
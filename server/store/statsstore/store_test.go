@@ -0,0 +1,200 @@
+package statsstore
+
+import (
+	"errors"
+	"testing"
+
+	"bou.ke/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// runUpdate makes a mocked Update apply its function to given statistics
+func runUpdate(st *stats.Stats) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		args.Get(0).(func(*stats.Stats))(st)
+	}
+}
+
+func getPollInChannel(votes bool) *poll.Poll {
+	p := testutils.GetPoll()
+	if votes {
+		p = testutils.GetPollWithVotes()
+	}
+	p.ChannelID = "channelID1"
+	return p
+}
+
+func TestNewStore(t *testing.T) {
+	t.Run("statistics exist", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.StatsStore.On("Get").Return(stats.New(), nil)
+		defer mockStore.AssertExpectations(t)
+
+		s, err := NewStore(mockStore, &plugintest.API{})
+		require.Nil(t, err)
+		assert.NotNil(t, s)
+	})
+	t.Run("statistics get built", func(t *testing.T) {
+		archived := getPollInChannel(true)
+		archived.ID = "pollID2"
+		built := stats.New()
+
+		mockStore := &mockstore.Store{}
+		mockStore.StatsStore.On("Get").Return(nil, nil)
+		mockStore.PollStore.On("List").Return([]*poll.Poll{getPollInChannel(false)}, nil)
+		mockStore.PollStore.On("ListArchived").Return([]*poll.Poll{archived}, nil)
+		mockStore.StatsStore.On("Update", mock.AnythingOfType("func(*stats.Stats)")).Return(nil).Run(runUpdate(built))
+		defer mockStore.AssertExpectations(t)
+
+		s, err := NewStore(mockStore, &plugintest.API{})
+		require.Nil(t, err)
+		assert.NotNil(t, s)
+		assert.Equal(t, map[string]int{"channelID1": 2}, built.PollsPerChannel)
+		assert.Equal(t, map[string]int{"channelID1": 4}, built.VotersPerChannel)
+	})
+	t.Run("StatsStore.Get fails", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.StatsStore.On("Get").Return(nil, errors.New(""))
+		defer mockStore.AssertExpectations(t)
+
+		s, err := NewStore(mockStore, &plugintest.API{})
+		assert.NotNil(t, err)
+		assert.Nil(t, s)
+	})
+	t.Run("PollStore.List fails", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.StatsStore.On("Get").Return(nil, nil)
+		mockStore.PollStore.On("List").Return(nil, errors.New(""))
+		defer mockStore.AssertExpectations(t)
+
+		s, err := NewStore(mockStore, &plugintest.API{})
+		assert.NotNil(t, err)
+		assert.Nil(t, s)
+	})
+}
+
+func TestPollStore(t *testing.T) {
+	patch := monkey.Patch(model.GetMillis, func() int64 { return 1234567890 })
+	defer patch.Unpatch()
+
+	setupStore := func(mockStore *mockstore.Store, api *plugintest.API) *Store {
+		mockStore.StatsStore.On("Get").Return(stats.New(), nil).Once()
+		s, err := NewStore(mockStore, api)
+		require.Nil(t, err)
+		return s.(*Store)
+	}
+
+	t.Run("new poll gets saved", func(t *testing.T) {
+		st := stats.New()
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Get", testutils.GetPollID()).Return(nil, errors.New(""))
+		mockStore.PollStore.On("Save", getPollInChannel(false)).Return(nil)
+		mockStore.StatsStore.On("Update", mock.AnythingOfType("func(*stats.Stats)")).Return(nil).Run(runUpdate(st))
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		assert.Nil(t, s.Poll().Save(getPollInChannel(false)))
+		assert.Equal(t, map[string]int{"channelID1": 1}, st.PollsPerChannel)
+	})
+	t.Run("existing poll gets saved", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Get", testutils.GetPollID()).Return(getPollInChannel(false), nil)
+		mockStore.PollStore.On("Save", getPollInChannel(false)).Return(nil)
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		assert.Nil(t, s.Poll().Save(getPollInChannel(false)))
+	})
+	t.Run("Save fails", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Get", testutils.GetPollID()).Return(nil, errors.New(""))
+		mockStore.PollStore.On("Save", getPollInChannel(false)).Return(errors.New(""))
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		assert.NotNil(t, s.Poll().Save(getPollInChannel(false)))
+	})
+	t.Run("vote gets cast", func(t *testing.T) {
+		st := stats.New()
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Run(func(args mock.Arguments) {
+			require.Nil(t, args.Get(1).(func(*poll.Poll) error)(getPollInChannel(false)))
+		}).Return(getPollInChannel(true), nil)
+		mockStore.StatsStore.On("Update", mock.AnythingOfType("func(*stats.Stats)")).Return(nil).Run(runUpdate(st))
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		p, err := s.Poll().Update(testutils.GetPollID(), func(*poll.Poll) error { return nil })
+		require.Nil(t, err)
+		assert.Equal(t, getPollInChannel(true), p)
+		assert.Equal(t, map[string]int{"1970-01-15": 4}, st.VotesPerDay)
+	})
+	t.Run("update doesn't affect the statistics", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Run(func(args mock.Arguments) {
+			require.Nil(t, args.Get(1).(func(*poll.Poll) error)(getPollInChannel(true)))
+		}).Return(getPollInChannel(true), nil)
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		_, err := s.Poll().Update(testutils.GetPollID(), func(*poll.Poll) error { return nil })
+		require.Nil(t, err)
+	})
+	t.Run("Update fails", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Update", testutils.GetPollID(), mock.AnythingOfType("func(*poll.Poll) error")).Return(nil, errors.New(""))
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		p, err := s.Poll().Update(testutils.GetPollID(), func(*poll.Poll) error { return nil })
+		assert.NotNil(t, err)
+		assert.Nil(t, p)
+	})
+	t.Run("poll gets deleted", func(t *testing.T) {
+		st := stats.FromPolls([]*poll.Poll{getPollInChannel(true)})
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Delete", getPollInChannel(true)).Return(nil)
+		mockStore.StatsStore.On("Update", mock.AnythingOfType("func(*stats.Stats)")).Return(nil).Run(runUpdate(st))
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		assert.Nil(t, s.Poll().Delete(getPollInChannel(true)))
+		assert.Equal(t, stats.New(), st)
+	})
+	t.Run("StatsStore.Update fails", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("LogWarn", "failed to update poll statistics", "error", "").Return()
+		defer api.AssertExpectations(t)
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Delete", getPollInChannel(true)).Return(nil)
+		mockStore.StatsStore.On("Update", mock.AnythingOfType("func(*stats.Stats)")).Return(errors.New(""))
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, api)
+
+		assert.Nil(t, s.Poll().Delete(getPollInChannel(true)))
+	})
+	t.Run("other operations are passed through", func(t *testing.T) {
+		mockStore := &mockstore.Store{}
+		mockStore.PollStore.On("Get", testutils.GetPollID()).Return(getPollInChannel(true), nil)
+		mockStore.ChannelStore.On("IsDisabled", "channelID1").Return(true, nil)
+		defer mockStore.AssertExpectations(t)
+		s := setupStore(mockStore, &plugintest.API{})
+
+		p, err := s.Poll().Get(testutils.GetPollID())
+		require.Nil(t, err)
+		assert.Equal(t, getPollInChannel(true), p)
+
+		disabled, err := s.Channel().IsDisabled("channelID1")
+		require.Nil(t, err)
+		assert.True(t, disabled)
+		assert.Nil(t, s.Close())
+	})
+}
@@ -1,18 +1,135 @@
 package store
 
-import "github.com/matterpoll/matterpoll/server/poll"
+import (
+	"time"
+
+	"github.com/matterpoll/matterpoll/server/audit"
+	"github.com/matterpoll/matterpoll/server/job"
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/stats"
+	"github.com/matterpoll/matterpoll/server/team"
+)
 
 // Store allows the interaction with some kind of store.
 type Store interface {
 	Poll() PollStore
+	Job() JobStore
 	System() SystemStore
+	Audit() AuditStore
+	RateLimit() RateLimitStore
+	Channel() ChannelStore
+	Team() TeamStore
+	Draft() DraftStore
+	Vote() VoteStore
+	Stats() StatsStore
+	Leader() LeaderStore
 }
 
 // PollStore allows the access polls in the store.
 type PollStore interface {
 	Get(id string) (*poll.Poll, error)
+	List() ([]*poll.Poll, error)
+	ListByChannel(channelID string) ([]*poll.Poll, error)
+	ListPage(page, perPage int) ([]*poll.Poll, int, error)
 	Save(poll *poll.Poll) error
+	Update(id string, update func(*poll.Poll) error) (*poll.Poll, error)
 	Delete(poll *poll.Poll) error
+	// Archive moves an ended poll out of the active polls. Archived polls can still be loaded, updated and deleted by their ID,
+	// but List, ListByChannel and ListPage leave them out.
+	Archive(poll *poll.Poll) error
+	// Unarchive moves the archived poll with a given ID back to the active polls. Polls that aren't archived are left alone.
+	Unarchive(id string) error
+	// ListArchived returns all archived polls.
+	ListArchived() ([]*poll.Poll, error)
+	// Search returns all polls, including archived ones, whose question contains every word of a given text, newest first.
+	// The search ignores case. See QuestionMatches.
+	Search(text string) ([]*poll.Poll, error)
+	// ListByTag returns all polls, including archived ones, that are tagged with a given tag, newest first. See Poll.HasTag.
+	ListByTag(tag string) ([]*poll.Poll, error)
+}
+
+// JobStore allows to access scheduled jobs in the store.
+type JobStore interface {
+	Get(id string) (*job.Job, error)
+	List() ([]*job.Job, error)
+	Claim(job *job.Job) (bool, error)
+	Save(job *job.Job) error
+	Delete(job *job.Job) error
+}
+
+// AuditStore allows to access the audit trail of polls in the store.
+type AuditStore interface {
+	List() ([]*audit.Entry, error)
+	ListByPoll(pollID string) ([]*audit.Entry, error)
+	Save(entry *audit.Entry) error
+	// AnonymizeUser removes a given user from all entries, so their actions show up as anonymous.
+	AnonymizeUser(userID string) error
+}
+
+// RateLimitStore allows to count actions of users in the store.
+type RateLimitStore interface {
+	// Get returns the current value of the counter of a given key.
+	// It's zero if the counter doesn't exist or the given window has passed since its first increment.
+	Get(key string, window time.Duration) (int, error)
+	// Increment increases the counter of a given key and returns its new value.
+	// The counter starts over once the given window has passed since its first increment.
+	Increment(key string, window time.Duration) (int, error)
+}
+
+// ChannelStore allows to access the Matterpoll settings of channels in the store.
+type ChannelStore interface {
+	// IsDisabled returns true if polls can't be created in a given channel.
+	IsDisabled(channelID string) (bool, error)
+	// SetDisabled allows or disallows the creation of polls in a given channel.
+	SetDisabled(channelID string, disabled bool) error
+	// GetCreators returns who may create polls in a given channel. It's empty if the channel doesn't restrict it.
+	GetCreators(channelID string) (string, error)
+	// SetCreators restricts who may create polls in a given channel. An empty value removes the restriction.
+	SetCreators(channelID, creators string) error
+}
+
+// TeamStore allows to access the Matterpoll settings of teams in the store.
+type TeamStore interface {
+	// GetDefaults returns the defaults of a given team. It returns nil if the team doesn't override any defaults.
+	GetDefaults(teamID string) (*team.Defaults, error)
+	// SaveDefaults stores the defaults of a given team. Empty defaults remove the overrides of the team.
+	SaveDefaults(teamID string, defaults *team.Defaults) error
+}
+
+// DraftStore allows to access the polls users are creating in a direct message with the bot in the store.
+type DraftStore interface {
+	// Get returns the draft of a given user. It returns nil if the user isn't creating a poll.
+	Get(userID string) (*poll.Draft, error)
+	Save(userID string, draft *poll.Draft) error
+	Delete(userID string) error
+}
+
+// VoteStore allows to access the index of the polls every user voted in in the store.
+type VoteStore interface {
+	// Record moves a given poll to the front of the polls a given user voted in. Only the most recent polls are kept.
+	Record(userID, pollID string) error
+	// List returns the IDs of the polls a given user voted in, most recent first.
+	List(userID string) ([]string, error)
+	// Delete removes the index of a given user.
+	Delete(userID string) error
+}
+
+// StatsStore allows to access the aggregate statistics of all polls in the store.
+type StatsStore interface {
+	// Get returns the statistics. It returns nil if no statistics have been stored yet.
+	Get() (*stats.Stats, error)
+	// Update atomically applies update to the statistics and stores the result.
+	// update gets empty statistics if no statistics have been stored yet.
+	Update(update func(*stats.Stats)) error
+}
+
+// LeaderStore allows plugin instances in a cluster to elect the one that runs the scheduled jobs.
+type LeaderStore interface {
+	// Lead makes a given plugin instance the leader for a given lease and returns true. The leader extends its lease by leading again.
+	// It returns false if another plugin instance leads and its lease hasn't expired yet.
+	Lead(instanceID string, lease time.Duration) (bool, error)
+	// Resign ends the lease of a given plugin instance if it leads, so another plugin instance can take over right away.
+	Resign(instanceID string) error
 }
 
 // SystemStore allows to access system informations in the store.
@@ -0,0 +1,102 @@
+// Package team holds the settings team admins make for the polls of their team.
+package team
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// KeyAnonymous overrides whether polls are anonymous unless they set it explicitly
+	KeyAnonymous = "anonymous"
+	// KeyProgress overrides whether polls show their progress unless they set it explicitly
+	KeyProgress = "progress"
+	// KeyMembersOnly overrides whether polls only accept votes of channel members unless they set it explicitly
+	KeyMembersOnly = "members-only"
+	// KeyMaxOptions overrides the maximum number of answer options of a poll
+	KeyMaxOptions = "max-options"
+)
+
+// Keys are all keys of the defaults in the order they are listed
+var Keys = []string{KeyAnonymous, KeyProgress, KeyMembersOnly, KeyMaxOptions}
+
+// Defaults override the default Poll Settings and the maximum number of answer options of the plugin configuration for the polls of a team.
+// Defaults that are nil aren't overridden.
+type Defaults struct {
+	Anonymous   *bool `json:",omitempty"`
+	Progress    *bool `json:",omitempty"`
+	MembersOnly *bool `json:",omitempty"`
+	// MaxAnswerOptions is the maximum number of answer options of a poll. Zero means no limit.
+	MaxAnswerOptions *int `json:",omitempty"`
+}
+
+// Set overrides the default with a given key by a given value, e.g. anonymous=true or max-options=10
+func (d *Defaults) Set(key, value string) error {
+	switch key {
+	case KeyAnonymous, KeyProgress, KeyMembersOnly:
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value %s for %s. Use true or false", value, key)
+		}
+		switch key {
+		case KeyAnonymous:
+			d.Anonymous = &enabled
+		case KeyProgress:
+			d.Progress = &enabled
+		default:
+			d.MembersOnly = &enabled
+		}
+	case KeyMaxOptions:
+		max, err := strconv.Atoi(value)
+		if err != nil || max < 0 {
+			return fmt.Errorf("Invalid value %s for %s. Use a number or 0 for no limit", value, key)
+		}
+		d.MaxAnswerOptions = &max
+	default:
+		return fmt.Errorf("Unknown team default %s. Use %s", key, strings.Join(Keys, ", "))
+	}
+	return nil
+}
+
+// IsEmpty returns true if no default is overridden
+func (d *Defaults) IsEmpty() bool {
+	return d.Anonymous == nil && d.Progress == nil && d.MembersOnly == nil && d.MaxAnswerOptions == nil
+}
+
+// String returns the overridden defaults as space separated key=value pairs, e.g. "anonymous=true max-options=10"
+func (d *Defaults) String() string {
+	pairs := []string{}
+	add := func(key string, value interface{}) {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	if d.Anonymous != nil {
+		add(KeyAnonymous, *d.Anonymous)
+	}
+	if d.Progress != nil {
+		add(KeyProgress, *d.Progress)
+	}
+	if d.MembersOnly != nil {
+		add(KeyMembersOnly, *d.MembersOnly)
+	}
+	if d.MaxAnswerOptions != nil {
+		add(KeyMaxOptions, *d.MaxAnswerOptions)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// EncodeToByte returns the defaults as a byte array
+func (d *Defaults) EncodeToByte() []byte {
+	b, _ := json.Marshal(d)
+	return b
+}
+
+// DecodeDefaultsFromByte tries to create defaults from a byte array
+func DecodeDefaultsFromByte(b []byte) *Defaults {
+	var d Defaults
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil
+	}
+	return &d
+}
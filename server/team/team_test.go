@@ -0,0 +1,61 @@
+package team_test
+
+import (
+	"testing"
+
+	"github.com/matterpoll/matterpoll/server/team"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultsSet(t *testing.T) {
+	for name, test := range map[string]struct {
+		Key              string
+		Value            string
+		ExpectedDefaults string
+		ShouldError      bool
+	}{
+		"anonymous":                 {Key: team.KeyAnonymous, Value: "true", ExpectedDefaults: "anonymous=true"},
+		"progress off":              {Key: team.KeyProgress, Value: "false", ExpectedDefaults: "progress=false"},
+		"members only":              {Key: team.KeyMembersOnly, Value: "1", ExpectedDefaults: "members-only=true"},
+		"max options":               {Key: team.KeyMaxOptions, Value: "10", ExpectedDefaults: "max-options=10"},
+		"max options without limit": {Key: team.KeyMaxOptions, Value: "0", ExpectedDefaults: "max-options=0"},
+		"invalid bool":              {Key: team.KeyAnonymous, Value: "sometimes", ShouldError: true},
+		"negative max options":      {Key: team.KeyMaxOptions, Value: "-1", ShouldError: true},
+		"invalid max options":       {Key: team.KeyMaxOptions, Value: "ten", ShouldError: true},
+		"unknown key":               {Key: "secret", Value: "true", ShouldError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := &team.Defaults{}
+			err := d.Set(test.Key, test.Value)
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+				assert.True(t, d.IsEmpty())
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.ExpectedDefaults, d.String())
+			}
+		})
+	}
+}
+
+func TestDefaultsString(t *testing.T) {
+	d := &team.Defaults{}
+	assert.Equal(t, "", d.String())
+
+	require.Nil(t, d.Set(team.KeyMaxOptions, "10"))
+	require.Nil(t, d.Set(team.KeyAnonymous, "true"))
+	require.Nil(t, d.Set(team.KeyProgress, "false"))
+	assert.Equal(t, "anonymous=true progress=false max-options=10", d.String())
+}
+
+func TestDefaultsEncodeDecode(t *testing.T) {
+	d1 := &team.Defaults{}
+	require.Nil(t, d1.Set(team.KeyMembersOnly, "true"))
+	require.Nil(t, d1.Set(team.KeyMaxOptions, "5"))
+
+	d2 := team.DecodeDefaultsFromByte(d1.EncodeToByte())
+	assert.Equal(t, d1, d2)
+	assert.Nil(t, team.DecodeDefaultsFromByte([]byte("{")))
+}
@@ -93,3 +93,99 @@ func GetPollTwoOptions() *poll.Poll {
 		},
 	}
 }
+
+// GetPollWithRankings returns a ranked Poll with three Options and rankings of four users.
+func GetPollWithRankings() *poll.Poll {
+	p := GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRanked})
+	p.Rankings = map[string][]int{
+		"userID1": {0, 1, 2},
+		"userID2": {1, 0},
+		"userID3": {2, 1},
+		"userID4": {0},
+	}
+	return p
+}
+
+// GetSchedulingPoll returns a scheduling Poll with three time slots and the availability of four users.
+func GetSchedulingPoll() *poll.Poll {
+	return &poll.Poll{
+		ID:        GetPollID(),
+		CreatedAt: 1234567890,
+		Creator:   "userID1",
+		Question:  "Team lunch",
+		AnswerOptions: []*poll.AnswerOption{
+			{Answer: "2024-06-03 12:00",
+				Voter: []string{"userID1", "userID2"}},
+			{Answer: "2024-06-04 12:00",
+				Voter: []string{"userID1", "userID2", "userID3"}},
+			{Answer: "2024-06-05",
+				Voter: []string{"userID4"}},
+		},
+		Settings: poll.Settings{VoteMode: poll.VoteModeScheduling},
+	}
+}
+
+// GetPollWithRatings returns a rating Poll with three Options and ratings of three users.
+func GetPollWithRatings() *poll.Poll {
+	p := GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeRating})
+	p.Ratings = map[string][]int{
+		"userID1": {5, 2, 0},
+		"userID2": {4, 3, 1},
+		"userID3": {3},
+	}
+	return p
+}
+
+// GetPollWithEstimates returns an estimate Poll without Options and estimates of four users.
+func GetPollWithEstimates() *poll.Poll {
+	p := GetPollWithSettings(poll.Settings{VoteMode: poll.VoteModeEstimate})
+	p.AnswerOptions = nil
+	p.Estimates = map[string]float64{
+		"userID1": 5,
+		"userID2": 3,
+		"userID3": 8,
+		"userID4": 3,
+	}
+	return p
+}
+
+// GetNPSPollWithVotes returns an anonymous NPS poll with two promoters, a passive and a detractor.
+func GetNPSPollWithVotes() *poll.Poll {
+	p := GetPollWithSettings(poll.Settings{Anonymous: true, NPS: true})
+	p.AnswerOptions = []*poll.AnswerOption{}
+	for _, score := range []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"} {
+		p.AnswerOptions = append(p.AnswerOptions, &poll.AnswerOption{Answer: score})
+	}
+	p.AnswerOptions[3].Voter = []string{"userID4"}
+	p.AnswerOptions[8].Voter = []string{"userID3"}
+	p.AnswerOptions[9].Voter = []string{"userID2"}
+	p.AnswerOptions[10].Voter = []string{"userID1"}
+	return p
+}
+
+// GetSurveyWithVotes returns a survey with two questions, some votes and no Poll Settings.
+func GetSurveyWithVotes() *poll.Poll {
+	return &poll.Poll{
+		ID:        GetPollID(),
+		CreatedAt: 1234567890,
+		Creator:   "userID1",
+		Question:  "Survey",
+		Questions: []*poll.Question{{
+			Question: "Question 1",
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Yes",
+					Voter: []string{"userID1", "userID2"}},
+				{Answer: "No",
+					Voter: []string{"userID3"}},
+			},
+		}, {
+			Question: "Question 2",
+			AnswerOptions: []*poll.AnswerOption{
+				{Answer: "Answer 1"},
+				{Answer: "Answer 2",
+					Voter: []string{"userID1"}},
+				{Answer: "Answer 3"},
+			},
+		}},
+	}
+}
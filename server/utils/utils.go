@@ -3,39 +3,136 @@ package utils
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
+// openingQuotes are the characters that may start a quoted argument. Word processors replace " with typographic quotes, which differ between languages.
+const openingQuotes = "\"“”„‟＂"
+
+// closingQuotes are the characters that may end a quoted argument.
+const closingQuotes = "\"”“‟＂"
+
+// settingPrefixes start a setting. Word processors turn -- into an en or em dash.
+var settingPrefixes = []string{"--", "—", "–"}
+
 // ParseInput pares a given input and tries to extract the poll question and poll options
+//
+// Arguments are enclosed in straight or typographic double quotes and separated by any whitespace, including line breaks.
+// Everything behind the last argument are settings. If the input contains no quotes at all,
+// the first line is the question and every following line is an answer option.
 func ParseInput(input string, trigger string) (string, []string, []string) {
+	// Remove Trigger prefix and spaces
+	in := []rune(strings.TrimSpace(strings.TrimPrefix(input, fmt.Sprintf("/%s", trigger))))
+
+	args := []string{}
+	i := skipSpace(in, 0)
+	for i < len(in) && strings.ContainsRune(openingQuotes, in[i]) {
+		var arg string
+		arg, i = readQuoted(in, i+1)
+		args = append(args, arg)
+		i = skipSpace(in, i)
+	}
+	rest := string(in[i:])
+
+	if len(args) == 0 {
+		return parseLines(rest)
+	}
+	return args[0], args[1:], ParseSettings(rest)
+}
+
+// readQuoted reads a quoted argument starting at a given position behind its opening quote.
+// It returns the unescaped argument and the position behind its closing quote.
+func readQuoted(in []rune, start int) (string, int) {
+	var b strings.Builder
+	for i := start; i < len(in); i++ {
+		r := in[i]
+		if r == '\\' && i+1 < len(in) && in[i+1] == '"' {
+			b.WriteRune('"')
+			i++
+			continue
+		}
+		if strings.ContainsRune(closingQuotes, r) && endsArgument(in, i+1) {
+			return b.String(), i + 1
+		}
+		b.WriteRune(r)
+	}
+	// Unterminated arguments run until the end of the input
+	return b.String(), len(in)
+}
+
+// endsArgument checks if a quote directly followed by a given position closes an argument.
+// That's the case if it's followed by the end of the input, a setting or whitespace and another argument.
+// Quotes within an argument, e.g. "Do you like "Matterpoll"?", therefore don't need to be escaped.
+func endsArgument(in []rune, i int) bool {
+	if i == len(in) || hasSettingPrefix(string(in[i:])) {
+		return true
+	}
+	if !unicode.IsSpace(in[i]) {
+		return false
+	}
+	next := skipSpace(in, i)
+	return next == len(in) || strings.ContainsRune(openingQuotes, in[next]) || hasSettingPrefix(string(in[next:]))
+}
+
+// parseLines parses an input without quotes. The first line is the question and every following line an answer option,
+// up to the first line that starts with a setting.
+func parseLines(in string) (string, []string, []string) {
+	question := ""
+	options := []string{}
+	lines := strings.Split(in, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if i > 0 && hasSettingPrefix(line) {
+			return question, options, ParseSettings(strings.Join(lines[i:], "\n"))
+		}
+		if line == "" {
+			continue
+		}
+		if question == "" {
+			question = line
+		} else {
+			options = append(options, line)
+		}
+	}
+	return question, options, []string{}
+}
+
+// ParseSettings splits the settings part of an input into single settings. Values of settings may be quoted, e.g. --schedule="2024-05-01 09:00".
+func ParseSettings(in string) []string {
 	settings := []string{}
+	in = strings.TrimSpace(in)
+	if in == "" {
+		return settings
+	}
+	for _, prefix := range settingPrefixes[1:] {
+		in = strings.Replace(in, prefix, settingPrefixes[0], -1)
+	}
+	for _, s := range strings.Split(strings.TrimPrefix(in, settingPrefixes[0]), settingPrefixes[0]) {
+		s = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(openingQuotes, r) {
+				return -1
+			}
+			return r
+		}, s)
+		settings = append(settings, strings.TrimSpace(s))
+	}
+	return settings
+}
 
-	// Remove Trigger prefix and spaces
-	in := strings.TrimSpace(strings.TrimPrefix(input, fmt.Sprintf("/%s", trigger)))
-	// Remove first "
-	in = strings.TrimLeft(in, `"`)
-
-	// Split between options
-	split := strings.Split(in, `" "`)
-	lastIndex := len(split) - 1
-
-	// Everything behind the last " are  Settings
-	l := strings.Split(split[lastIndex], string('"'))
-	split[lastIndex] = l[0]
-	if len(l) == 2 && l[1] != "" {
-		ops := strings.TrimPrefix(strings.TrimSpace(l[1]), "--")
-		// Split between Settings
-		opsList := strings.Split(ops, "--")
-		for i := 0; i < len(opsList); i++ {
-			s := strings.TrimSpace(opsList[i])
-			settings = append(settings, s)
+// hasSettingPrefix checks if a given string starts with a setting
+func hasSettingPrefix(s string) bool {
+	for _, prefix := range settingPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
 		}
 	}
+	return false
+}
 
-	// Unescape " in question and options
-	question := strings.Replace(split[0], `\"`, `"`, -1)
-	options := split[1:]
-	for i := 0; i < len(options); i++ {
-		options[i] = strings.Replace(options[i], `\"`, `"`, -1)
+// skipSpace returns the position of the first non-whitespace character at or behind a given position
+func skipSpace(in []rune, i int) int {
+	for i < len(in) && unicode.IsSpace(in[i]) {
+		i++
 	}
-	return question, options, settings
+	return i
 }
@@ -36,6 +36,13 @@ func TestParseInput(t *testing.T) {
 			ExpectedOptions:  []string{`"BBB`, `CCC`},
 			ExpectedSettings: []string{},
 		},
+		"With quotationmark in last option": {
+			Input:            `/poll "AAA" "BBB" "CCC\""`,
+			Trigger:          "poll",
+			ExpectedQuestion: `AAA`,
+			ExpectedOptions:  []string{`BBB`, `CCC"`},
+			ExpectedSettings: []string{},
+		},
 		"Trim whitespace": {
 			Input:            `/poll  "A" "B" "C"  `,
 			Trigger:          "poll",
@@ -78,6 +85,13 @@ func TestParseInput(t *testing.T) {
 			ExpectedOptions:  []string{"B", "C"},
 			ExpectedSettings: []string{"anonymous", "abc"},
 		},
+		"With quoted setting value": {
+			Input:            `/poll "A" "B" "C" --anonymous --schedule="2024-05-01 09:00"`,
+			Trigger:          "poll",
+			ExpectedQuestion: "A",
+			ExpectedOptions:  []string{"B", "C"},
+			ExpectedSettings: []string{"anonymous", "schedule=2024-05-01 09:00"},
+		},
 		"With two settings, dashes in question": {
 			Input:            `/poll "--A" "B" "C"--anonymous--abc`,
 			Trigger:          "poll",
@@ -85,6 +99,90 @@ func TestParseInput(t *testing.T) {
 			ExpectedOptions:  []string{"B", "C"},
 			ExpectedSettings: []string{"anonymous", "abc"},
 		},
+		"Unquoted question": {
+			Input:            `/poll Is Matterpoll great? --anonymous`,
+			Trigger:          "poll",
+			ExpectedQuestion: "Is Matterpoll great? --anonymous",
+			ExpectedOptions:  []string{},
+			ExpectedSettings: []string{},
+		},
+		"With typographic quotes": {
+			Input:            `/poll “A” “B” “C” --anonymous`,
+			Trigger:          "poll",
+			ExpectedQuestion: "A",
+			ExpectedOptions:  []string{"B", "C"},
+			ExpectedSettings: []string{"anonymous"},
+		},
+		"With german typographic quotes": {
+			Input:            `/poll „A“ „B“ „C“`,
+			Trigger:          "poll",
+			ExpectedQuestion: "A",
+			ExpectedOptions:  []string{"B", "C"},
+			ExpectedSettings: []string{},
+		},
+		"With mixed quotes": {
+			Input:            `/poll "A” “B" "C"`,
+			Trigger:          "poll",
+			ExpectedQuestion: "A",
+			ExpectedOptions:  []string{"B", "C"},
+			ExpectedSettings: []string{},
+		},
+		"With apostrophes": {
+			Input:            `/poll “What’s up?” “It’s fine” “Don't ask”`,
+			Trigger:          "poll",
+			ExpectedQuestion: "What’s up?",
+			ExpectedOptions:  []string{"It’s fine", "Don't ask"},
+			ExpectedSettings: []string{},
+		},
+		"With unescaped quotes in question": {
+			Input:            `/poll "Do you like "Matterpoll"?" "Yes" "No"`,
+			Trigger:          "poll",
+			ExpectedQuestion: `Do you like "Matterpoll"?`,
+			ExpectedOptions:  []string{"Yes", "No"},
+			ExpectedSettings: []string{},
+		},
+		"With typographic quotes in question": {
+			Input:            `/poll "Do you like “Matterpoll”" "Yes" "No"`,
+			Trigger:          "poll",
+			ExpectedQuestion: "Do you like “Matterpoll”",
+			ExpectedOptions:  []string{"Yes", "No"},
+			ExpectedSettings: []string{},
+		},
+		"Unterminated last option": {
+			Input:            `/poll "A" "B" "C`,
+			Trigger:          "poll",
+			ExpectedQuestion: "A",
+			ExpectedOptions:  []string{"B", "C"},
+			ExpectedSettings: []string{},
+		},
+		"With multiple lines": {
+			Input:            "/poll \"A\"\n\"B\"\r\n\t\"C\"\n--anonymous\n--progress",
+			Trigger:          "poll",
+			ExpectedQuestion: "A",
+			ExpectedOptions:  []string{"B", "C"},
+			ExpectedSettings: []string{"anonymous", "progress"},
+		},
+		"With multiple lines, no quotes": {
+			Input:            "/poll\nIs Matterpoll great?\n\nOf course \n In any case\n--anonymous --progress",
+			Trigger:          "poll",
+			ExpectedQuestion: "Is Matterpoll great?",
+			ExpectedOptions:  []string{"Of course", "In any case"},
+			ExpectedSettings: []string{"anonymous", "progress"},
+		},
+		"With dashes replaced by word processor": {
+			Input:            `/poll “A” “B” “C” —anonymous –votes=2 --schedule=“2024-05-01 09:00”`,
+			Trigger:          "poll",
+			ExpectedQuestion: "A",
+			ExpectedOptions:  []string{"B", "C"},
+			ExpectedSettings: []string{"anonymous", "votes=2", "schedule=2024-05-01 09:00"},
+		},
+		"Survey trigger": {
+			Input:            `/poll survey “Title” “Q1|A|B”`,
+			Trigger:          "poll survey",
+			ExpectedQuestion: "Title",
+			ExpectedOptions:  []string{"Q1|A|B"},
+			ExpectedSettings: []string{},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			assert := assert.New(t)